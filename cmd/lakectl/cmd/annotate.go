@@ -7,11 +7,14 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/treeverse/lakefs/pkg/api/apigen"
 	"github.com/treeverse/lakefs/pkg/api/apiutil"
+	"github.com/treeverse/lakefs/pkg/uri"
 )
 
 const (
 	annotateTemplate = `{{  $val := .Commit}}{{ $.Object|ljust 15}} {{ $val.Committer|ljust 20 }} {{ $val.Id | printf "%.16s"|ljust 20 }} {{if $val.CreationDate}}{{ $val.CreationDate|date}}{{end}}  {{ $.CommitMessage }}
 `
+	annotateLinesTemplate = `{{ range .Results }}{{ if .Commit }}{{ .Commit.Id | printf "%.16s" | ljust 18 }} {{ .Commit.Committer | ljust 20 }} {{ .Commit.CreationDate | date }}{{ else }}{{ "uncommitted" | ljust 18 }} {{ "" | ljust 20 }} {{ "" | ljust 29 }}{{ end }} {{ .Number | printf "%5d" }}) {{ .Content }}
+{{ end }}`
 	annotateMessageSize = 200
 	ellipsis            = "..."
 )
@@ -32,7 +35,12 @@ var annotateCmd = &cobra.Command{
 		pathURI := MustParsePathURI("path", args[0])
 		recursive := Must(cmd.Flags().GetBool("recursive"))
 		firstParent := Must(cmd.Flags().GetBool("first-parent"))
+		lines := Must(cmd.Flags().GetBool("lines"))
 		client := getClient()
+		if lines {
+			annotateLines(cmd, client, pathURI)
+			return
+		}
 		pfx := apigen.PaginationPrefix(*pathURI.Path)
 		context := cmd.Context()
 		resp, err := client.ListObjectsWithResponse(context, pathURI.Repository, pathURI.Ref, &apigen.ListObjectsParams{Prefix: &pfx})
@@ -91,6 +99,23 @@ var annotateCmd = &cobra.Command{
 	},
 }
 
+// annotateLines prints each line of the object at pathURI with the commit that introduced it
+func annotateLines(cmd *cobra.Command, client apigen.ClientWithResponsesInterface, pathURI *uri.URI) {
+	format := Must(cmd.Flags().GetString("format"))
+	params := &apigen.BlameObjectParams{
+		Path: *pathURI.Path,
+	}
+	if format != "" {
+		params.Format = &format
+	}
+	resp, err := client.BlameObjectWithResponse(cmd.Context(), pathURI.Repository, pathURI.Ref, params)
+	DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusOK)
+	if resp.JSON200 == nil {
+		Die("Bad response from server", 1)
+	}
+	Write(annotateLinesTemplate, resp.JSON200)
+}
+
 func stringTrimLen(str string, size int) string {
 	if len(str) > size && size > len(ellipsis) {
 		str = str[:size-len(ellipsis)] + ellipsis
@@ -108,4 +133,6 @@ func init() {
 
 	annotateCmd.Flags().BoolP("recursive", "r", false, "recursively annotate all entries under a given path or prefix")
 	annotateCmd.Flags().Bool("first-parent", false, "follow only the first parent commit upon seeing a merge commit")
+	annotateCmd.Flags().Bool("lines", false, "annotate each line of the object at the given path with the commit that introduced it")
+	annotateCmd.Flags().String("format", "", "with --lines, annotate lines of a text object or rows of a CSV object (text, csv; default by path extension)")
 }
@@ -6,7 +6,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
-const permissionTemplate = "Group {{ .Group }}:{{ .Permission }}\n"
+const permissionTemplate = `Group {{ .Group }}:{{ .Permission }}
+Repositories: {{ if .Repositories }}{{ join ", " .Repositories }}{{ else }}all{{ end }}
+`
 
 var authGroupsACLGetCmd = &cobra.Command{
 	Use:   "get",
@@ -18,7 +20,14 @@ var authGroupsACLGetCmd = &cobra.Command{
 		resp, err := clt.GetGroupACLWithResponse(cmd.Context(), id)
 		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusOK)
 
-		Write(permissionTemplate, struct{ Group, Permission string }{id, resp.JSON200.Permission})
+		var repositories []string
+		if resp.JSON200.Repositories != nil {
+			repositories = *resp.JSON200.Repositories
+		}
+		Write(permissionTemplate, struct {
+			Group, Permission string
+			Repositories      []string
+		}{id, resp.JSON200.Permission, repositories})
 	},
 }
 
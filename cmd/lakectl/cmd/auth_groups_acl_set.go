@@ -10,16 +10,20 @@ import (
 var authGroupsACLSet = &cobra.Command{
 	Use:   "set",
 	Short: "Set ACL of group",
-	Long:  `Set ACL of group. permission will be attached to all repositories.`,
+	Long:  `Set ACL of group. permission will be attached to all repositories, unless limited by --repo.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		id := Must(cmd.Flags().GetString("id"))
 		permission := Must(cmd.Flags().GetString("permission"))
+		repositories := Must(cmd.Flags().GetStringSlice("repo"))
 
 		clt := getClient()
 
 		acl := apigen.SetGroupACLJSONRequestBody{
 			Permission: permission,
 		}
+		if len(repositories) > 0 {
+			acl.Repositories = &repositories
+		}
 
 		resp, err := clt.SetGroupACL(cmd.Context(), id, acl)
 		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusCreated)
@@ -32,6 +36,7 @@ func init() {
 	_ = authGroupsACLSet.MarkFlagRequired("id")
 	authGroupsACLSet.Flags().String("permission", "", `Permission, typically one of "Read", "Write", "Super" or "Admin"`)
 	_ = authGroupsACLSet.MarkFlagRequired("permission")
+	authGroupsACLSet.Flags().StringSlice("repo", nil, "Repository name or wildcard pattern to limit the permission to (can be repeated, default all repositories)")
 
 	authGroupsACLCmd.AddCommand(authGroupsACLSet)
 }
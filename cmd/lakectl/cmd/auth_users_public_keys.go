@@ -0,0 +1,13 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var authUsersPublicKeys = &cobra.Command{
+	Use:   "public-keys",
+	Short: "Manage the public keys that sign user commits",
+}
+
+//nolint:gochecknoinits
+func init() {
+	authUsersCmd.AddCommand(authUsersPublicKeys)
+}
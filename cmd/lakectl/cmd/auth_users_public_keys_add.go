@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api/apigen"
+)
+
+const publicKeyAddedTemplate = `{{ "Public key added successfully." | green }}
+{{ "Fingerprint:" | ljust 12 }} {{ .Fingerprint | bold }}
+`
+
+var authUsersPublicKeysAdd = &cobra.Command{
+	Use:     "add <public key file>",
+	Short:   "Add a public key that signs the user's commits",
+	Long:    "Add an SSH public key (ed25519, ECDSA or RSA) in the authorized_keys format, e.g. ~/.ssh/id_ed25519.pub",
+	Example: "lakectl auth users public-keys add ~/.ssh/id_ed25519.pub",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id := Must(cmd.Flags().GetString("id"))
+		publicKey, err := os.ReadFile(args[0])
+		if err != nil {
+			DieErr(err)
+		}
+		clt := getClient()
+		if id == "" {
+			resp, err := clt.GetCurrentUserWithResponse(cmd.Context())
+			DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusOK)
+			if resp.JSON200 == nil {
+				Die("Bad response from server", 1)
+			}
+			id = resp.JSON200.User.Id
+		}
+
+		resp, err := clt.AddUserPublicKeyWithResponse(cmd.Context(), id, apigen.AddUserPublicKeyJSONRequestBody{
+			PublicKey: string(publicKey),
+		})
+		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusCreated)
+		if resp.JSON201 == nil {
+			Die("Bad response from server", 1)
+		}
+		Write(publicKeyAddedTemplate, resp.JSON201)
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	authUsersPublicKeysAdd.Flags().String("id", "", "Username (email for password-based users, default: current user)")
+
+	authUsersPublicKeys.AddCommand(authUsersPublicKeysAdd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api/apigen"
+)
+
+var authUsersPublicKeysDelete = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete a public key of the user",
+	Long:  "Delete a public key of the user.  Signatures by the key are no longer reported as verified.",
+	Run: func(cmd *cobra.Command, args []string) {
+		id := Must(cmd.Flags().GetString("id"))
+		fingerprint := Must(cmd.Flags().GetString("fingerprint"))
+		clt := getClient()
+		if id == "" {
+			resp, err := clt.GetCurrentUserWithResponse(cmd.Context())
+			DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusOK)
+			if resp.JSON200 == nil {
+				Die("Bad response from server", 1)
+			}
+			id = resp.JSON200.User.Id
+		}
+
+		resp, err := clt.DeleteUserPublicKeyWithResponse(cmd.Context(), id, &apigen.DeleteUserPublicKeyParams{
+			Fingerprint: fingerprint,
+		})
+		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusNoContent)
+
+		fmt.Println("Public key deleted successfully")
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	authUsersPublicKeysDelete.Flags().String("id", "", "Username (email for password-based users, default: current user)")
+	authUsersPublicKeysDelete.Flags().String("fingerprint", "", "SHA256 fingerprint of the public key to delete")
+	_ = authUsersPublicKeysDelete.MarkFlagRequired("fingerprint")
+
+	authUsersPublicKeys.AddCommand(authUsersPublicKeysDelete)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api/apigen"
+	"github.com/treeverse/lakefs/pkg/api/apiutil"
+)
+
+var authUsersPublicKeysList = &cobra.Command{
+	Use:   "list",
+	Short: "List the public keys of the user",
+	Run: func(cmd *cobra.Command, args []string) {
+		amount := Must(cmd.Flags().GetInt("amount"))
+		after := Must(cmd.Flags().GetString("after"))
+		id := Must(cmd.Flags().GetString("id"))
+
+		clt := getClient()
+		if id == "" {
+			resp, err := clt.GetCurrentUserWithResponse(cmd.Context())
+			DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusOK)
+			if resp.JSON200 == nil {
+				Die("Bad response from server", 1)
+			}
+			id = resp.JSON200.User.Id
+		}
+
+		resp, err := clt.ListUserPublicKeysWithResponse(cmd.Context(), id, &apigen.ListUserPublicKeysParams{
+			After:  apiutil.Ptr(apigen.PaginationAfter(after)),
+			Amount: apiutil.Ptr(apigen.PaginationAmount(amount)),
+		})
+		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusOK)
+		if resp.JSON200 == nil {
+			Die("Bad response from server", 1)
+		}
+
+		publicKeys := resp.JSON200.Results
+		rows := make([][]interface{}, len(publicKeys))
+		for i, k := range publicKeys {
+			ts := time.Unix(k.CreationDate, 0).String()
+			rows[i] = []interface{}{k.Fingerprint, ts}
+		}
+		pagination := resp.JSON200.Pagination
+		PrintTable(rows, []interface{}{"Fingerprint", "Creation Date"}, &pagination, amount)
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	authUsersPublicKeysList.Flags().String("id", "", "Username (email for password-based users, default: current user)")
+	addPaginationFlags(authUsersPublicKeysList)
+
+	authUsersPublicKeys.AddCommand(authUsersPublicKeysList)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/go-openapi/swag"
 	"github.com/spf13/cobra"
 	"github.com/treeverse/lakefs/pkg/api/apigen"
 )
@@ -35,9 +36,9 @@ var branchProtectListCmd = &cobra.Command{
 		}
 		patterns := make([][]interface{}, len(*resp.JSON200))
 		for i, rule := range *resp.JSON200 {
-			patterns[i] = []interface{}{rule.Pattern}
+			patterns[i] = []interface{}{rule.Pattern, swag.BoolValue(rule.RequireSignedCommits)}
 		}
-		PrintTable(patterns, []interface{}{"Branch Name Pattern"}, &apigen.Pagination{
+		PrintTable(patterns, []interface{}{"Branch Name Pattern", "Require Signed Commits"}, &apigen.Pagination{
 			HasMore: false,
 			Results: len(patterns),
 		}, len(patterns))
@@ -45,17 +46,21 @@ var branchProtectListCmd = &cobra.Command{
 }
 
 var branchProtectAddCmd = &cobra.Command{
-	Use:               "add <repo uri> <pattern>",
-	Short:             "Add a branch protection rule",
-	Long:              "Add a branch protection rule for a given branch name pattern",
-	Example:           "lakectl branch-protect add lakefs://<repository> 'stable_*'",
+	Use:   "add <repo uri> <pattern>",
+	Short: "Add a branch protection rule",
+	Long: `Add a branch protection rule for a given branch name pattern.  With --require-signed-commits, commits
+reach matching branches only by merging commits signed by their committers.`,
+	Example: `lakectl branch-protect add lakefs://<repository> 'stable_*'
+lakectl branch-protect add --require-signed-commits lakefs://<repository> main`,
 	Args:              cobra.ExactArgs(branchProtectAddCmdArgs),
 	ValidArgsFunction: ValidArgsRepository,
 	Run: func(cmd *cobra.Command, args []string) {
+		requireSignedCommits := Must(cmd.Flags().GetBool("require-signed-commits"))
 		client := getClient()
 		u := MustParseRepoURI("repository", args[0])
 		resp, err := client.CreateBranchProtectionRuleWithResponse(cmd.Context(), u.Repository, apigen.CreateBranchProtectionRuleJSONRequestBody{
-			Pattern: args[1],
+			Pattern:              args[1],
+			RequireSignedCommits: swag.Bool(requireSignedCommits),
 		})
 		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusNoContent)
 		fmt.Printf("Branch protection rule added to '%s' repository.\n", u.Repository)
@@ -83,6 +88,7 @@ var branchProtectDeleteCmd = &cobra.Command{
 //nolint:gochecknoinits
 func init() {
 	rootCmd.AddCommand(branchProtectCmd)
+	branchProtectAddCmd.Flags().Bool("require-signed-commits", false, "allow only commits signed by their committers on matching branches")
 	branchProtectCmd.AddCommand(branchProtectAddCmd)
 	branchProtectCmd.AddCommand(branchProtectListCmd)
 	branchProtectCmd.AddCommand(branchProtectDeleteCmd)
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api/apigen"
+	"github.com/treeverse/lakefs/pkg/api/apiutil"
+)
+
+var branchReflogCmd = &cobra.Command{
+	Use:               "reflog <branch uri>",
+	Short:             "List the previous heads of a branch, newest first",
+	Long:              "List the previous heads of a branch, newest first. The commit a branch pointed to N moves ago can be referenced as <branch>@{N}.",
+	Example:           "lakectl branch reflog lakefs://example-repo/example-branch",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: ValidArgsRepository,
+	Run: func(cmd *cobra.Command, args []string) {
+		amount := Must(cmd.Flags().GetInt("amount"))
+		after := Must(cmd.Flags().GetString("after"))
+		u := MustParseBranchURI("branch", args[0])
+		client := getClient()
+		resp, err := client.ListBranchReflogWithResponse(cmd.Context(), u.Repository, u.Ref, &apigen.ListBranchReflogParams{
+			After:  apiutil.Ptr(apigen.PaginationAfter(after)),
+			Amount: apiutil.Ptr(apigen.PaginationAmount(amount)),
+		})
+		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusOK)
+		if resp.JSON200 == nil {
+			Die("Bad response from server", 1)
+		}
+
+		entries := resp.JSON200.Results
+		rows := make([][]interface{}, len(entries))
+		for i, entry := range entries {
+			user := ""
+			if entry.User != nil {
+				user = *entry.User
+			}
+			ts := time.Unix(entry.CreationDate, 0).String()
+			rows[i] = []interface{}{entry.OldCommitId, entry.NewCommitId, entry.Operation, user, ts}
+		}
+
+		pagination := resp.JSON200.Pagination
+		PrintTable(rows, []interface{}{"Old Commit ID", "New Commit ID", "Operation", "User", "Time"}, &pagination, amount)
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	branchReflogCmd.Flags().Int("amount", defaultAmountArgumentValue, "number of results to return")
+	branchReflogCmd.Flags().String("after", "", "show results after this value (used for pagination)")
+
+	branchCmd.AddCommand(branchReflogCmd)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api/apigen"
+	"github.com/treeverse/lakefs/pkg/uri"
+)
+
+const branchRewriteCmdArgs = 2
+
+// lakectl branch rewrite lakefs://myrepo/main commitId
+var branchRewriteCmd = &cobra.Command{
+	Use:   "rewrite <branch uri> <commit ref to remove> [<more commits>...]",
+	Short: "Rebuild the history of a branch without the given commits",
+	Long: `Remove the given commits from the first-parent history of the branch.
+Every later commit is replayed on top of the rewritten history.  The rewrite fails if a later commit conflicts with the removal.`,
+	Example: `lakectl branch rewrite lakefs://example-repo/example-branch commitA
+	          Remove commitA and its changes from the history of example-branch`,
+	Args: cobra.MinimumNArgs(branchRewriteCmdArgs),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return validRepositoryToComplete(cmd.Context(), toComplete)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		u := MustParseBranchURI("branch", args[0])
+		fmt.Println("Branch:", u)
+		commits := strings.Join(args[1:], " ")
+		confirmation, err := Confirm(cmd.Flags(), fmt.Sprintf("Are you sure you want to remove commits %s from the branch history", commits))
+		if err != nil || !confirmation {
+			Die("Rewrite aborted", 1)
+		}
+		clt := getClient()
+		resp, err := clt.RewriteBranchWithResponse(cmd.Context(), u.Repository, u.Ref, apigen.RewriteBranchJSONRequestBody{
+			Exclude: args[1:],
+		})
+		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusCreated)
+		if resp.JSON201 == nil {
+			Die("Bad response from server", 1)
+		}
+
+		Write(commitCreateTemplate, struct {
+			Branch *uri.URI
+			Commit *apigen.Commit
+		}{Branch: u, Commit: resp.JSON201})
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	AssignAutoConfirmFlag(branchRewriteCmd.Flags())
+
+	branchCmd.AddCommand(branchRewriteCmd)
+}
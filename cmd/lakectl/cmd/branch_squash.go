@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/swag"
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api/apigen"
+	"github.com/treeverse/lakefs/pkg/uri"
+)
+
+const squashFromFlagName = "from"
+
+// lakectl branch squash lakefs://myrepo/ingest --from commitId
+var branchSquashCmd = &cobra.Command{
+	Use:   "squash <branch uri> --from <ref>",
+	Short: "Replace the commits on a branch after a given commit with a single commit",
+	Long: `Replace all commits on the first-parent history of the branch after the given commit with a single commit.
+The given ref must resolve to a commit on the first-parent history of the branch: use the commit the branch was created from rather than
+the name of a branch that may have moved on since.
+The new commit has the same content as the branch.  Unless a message is given, its message combines the messages of the squashed commits.`,
+	Example: `lakectl branch squash lakefs://example-repo/example-branch --from 2e3b6ec
+	          Squash all commits made on example-branch after commit 2e3b6ec`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: ValidArgsRepository,
+	Run: func(cmd *cobra.Command, args []string) {
+		u := MustParseBranchURI("branch", args[0])
+		fmt.Println("Branch:", u)
+		from := Must(cmd.Flags().GetString(squashFromFlagName))
+		message := Must(cmd.Flags().GetString(messageFlagName))
+		kvPairs, err := getKV(cmd, metaFlagName)
+		if err != nil {
+			DieErr(err)
+		}
+
+		confirmation, err := Confirm(cmd.Flags(), fmt.Sprintf("Are you sure you want to squash all commits after %s", from))
+		if err != nil || !confirmation {
+			Die("Squash aborted", 1)
+		}
+		body := apigen.SquashBranchJSONRequestBody{
+			Ref:      from,
+			Metadata: &apigen.SquashCreation_Metadata{AdditionalProperties: kvPairs},
+		}
+		if message != "" {
+			body.Message = swag.String(message)
+		}
+		clt := getClient()
+		resp, err := clt.SquashBranchWithResponse(cmd.Context(), u.Repository, u.Ref, body)
+		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusCreated)
+		if resp.JSON201 == nil {
+			Die("Bad response from server", 1)
+		}
+
+		Write(commitCreateTemplate, struct {
+			Branch *uri.URI
+			Commit *apigen.Commit
+		}{Branch: u, Commit: resp.JSON201})
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	AssignAutoConfirmFlag(branchSquashCmd.Flags())
+
+	branchSquashCmd.Flags().String(squashFromFlagName, "", "squash all commits after this commit")
+	_ = branchSquashCmd.MarkFlagRequired(squashFromFlagName)
+	branchSquashCmd.Flags().StringP(messageFlagName, "m", "", "squash commit message, combined from the squashed commits if empty")
+	branchSquashCmd.Flags().StringSlice(metaFlagName, []string{}, "key value pair in the form of key=value")
+
+	branchCmd.AddCommand(branchSquashCmd)
+}
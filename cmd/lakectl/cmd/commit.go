@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/treeverse/lakefs/pkg/api/apigen"
 	"github.com/treeverse/lakefs/pkg/uri"
+	"golang.org/x/crypto/ssh"
 )
 
 var errInvalidKeyValueFormat = errors.New(`invalid key/value pair - should be separated by "="`)
@@ -31,9 +32,11 @@ Parents: {{.Commit.Parents|join ", "}}
 )
 
 var commitCmd = &cobra.Command{
-	Use:               "commit <branch uri>",
-	Short:             "Commit changes on a given branch",
-	Args:              cobra.ExactArgs(1),
+	Use:   "commit <branch uri> [<branch uri>...]",
+	Short: "Commit changes on a given branch",
+	Long: `Commit changes on a given branch.
+When given multiple branches, possibly of different repositories, commits all of them atomically: either all branches are committed, or none are.`,
+	Args:              cobra.MinimumNArgs(1),
 	ValidArgsFunction: ValidArgsRepository,
 	Run: func(cmd *cobra.Command, args []string) {
 		// validate message
@@ -55,6 +58,17 @@ var commitCmd = &cobra.Command{
 			datePtr = nil
 		}
 
+		// load the signing key before committing, so that an unusable key fails the command early
+		var signer ssh.Signer
+		if keyPath := Must(cmd.Flags().GetString(signingKeyFlagName)); keyPath != "" {
+			signer = loadSigningKey(keyPath)
+		}
+
+		if len(args) > 1 {
+			commitBranches(cmd, args, message, kvPairs, datePtr, signer)
+			return
+		}
+
 		branchURI := MustParseRefURI("branch", args[0])
 		fmt.Println("Branch:", branchURI)
 
@@ -78,9 +92,49 @@ var commitCmd = &cobra.Command{
 			Branch *uri.URI
 			Commit *apigen.Commit
 		}{Branch: branchURI, Commit: commit})
+		if signer != nil {
+			signCommit(cmd.Context(), client, signer, branchURI.Repository, commit.Id)
+		}
 	},
 }
 
+// commitBranches commits all branches of args atomically, using the same message and metadata for all of them.
+func commitBranches(cmd *cobra.Command, args []string, message string, kvPairs map[string]string, date *int64, signer ssh.Signer) {
+	branchURIs := make([]*uri.URI, 0, len(args))
+	body := apigen.CommitBranchesJSONRequestBody{
+		Commits: make([]apigen.BranchCommitCreation, 0, len(args)),
+	}
+	for _, arg := range args {
+		branchURI := MustParseRefURI("branch", arg)
+		fmt.Println("Branch:", branchURI)
+		branchURIs = append(branchURIs, branchURI)
+		body.Commits = append(body.Commits, apigen.BranchCommitCreation{
+			Repository: branchURI.Repository,
+			Branch:     branchURI.Ref,
+			Message:    message,
+			Metadata:   &apigen.BranchCommitCreation_Metadata{AdditionalProperties: kvPairs},
+			Date:       date,
+		})
+	}
+
+	client := getClient()
+	resp, err := client.CommitBranchesWithResponse(cmd.Context(), body)
+	DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusCreated)
+	if resp.JSON201 == nil || len(resp.JSON201.Results) != len(branchURIs) {
+		Die("Bad response from server", 1)
+	}
+
+	for i := range resp.JSON201.Results {
+		Write(commitCreateTemplate, struct {
+			Branch *uri.URI
+			Commit *apigen.Commit
+		}{Branch: branchURIs[i], Commit: &resp.JSON201.Results[i]})
+		if signer != nil {
+			signCommit(cmd.Context(), client, signer, branchURIs[i].Repository, resp.JSON201.Results[i].Id)
+		}
+	}
+}
+
 func getKV(cmd *cobra.Command, name string) (map[string]string, error) { //nolint:unparam
 	kvList, err := cmd.Flags().GetStringSlice(name)
 	if err != nil {
@@ -111,4 +165,5 @@ func init() {
 	}
 
 	commitCmd.Flags().StringSlice(metaFlagName, []string{}, "key value pair in the form of key=value")
+	commitCmd.Flags().String(signingKeyFlagName, "", signingKeyDescription)
 }
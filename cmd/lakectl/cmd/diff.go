@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 
+	"github.com/jedib0t/go-pretty/v6/text"
 	"github.com/spf13/cobra"
 	"github.com/treeverse/lakefs/pkg/api/apigen"
 	"github.com/treeverse/lakefs/pkg/api/apiutil"
@@ -21,7 +23,8 @@ const (
 	minDiffPageSize = 50
 	maxDiffPageSize = 1000
 
-	twoWayFlagName = "two-way"
+	twoWayFlagName  = "two-way"
+	contentFlagName = "content"
 )
 
 var diffCmd = &cobra.Command{
@@ -42,7 +45,10 @@ var diffCmd = &cobra.Command{
 	Uncommitted changes are not shown.
 
 	lakectl diff --%s lakefs://example-repo/main lakefs://example-repo/dev$
-	Show changes between the tip of the main and the dev branch, including uncommitted changes on dev.`, twoWayFlagName, twoWayFlagName),
+	Show changes between the tip of the main and the dev branch, including uncommitted changes on dev.
+
+	lakectl diff --%s tables/users.csv lakefs://example-repo/main lakefs://example-repo/dev
+	Show changes to the content of tables/users.csv between the tips of the main and dev branches.`, twoWayFlagName, twoWayFlagName, contentFlagName),
 
 	Args: cobra.RangeArgs(diffCmdMinArgs, diffCmdMaxArgs),
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -53,6 +59,22 @@ var diffCmd = &cobra.Command{
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		client := getClient()
+		contentPath := Must(cmd.Flags().GetString(contentFlagName))
+		if contentPath != "" {
+			leftRefURI := MustParseRefURI("left ref", args[0])
+			rightRefURI := leftRefURI
+			if len(args) == diffCmdMinArgs {
+				// uncommitted changes: compare the branch head with the branch
+				leftRefURI = &uri.URI{Repository: rightRefURI.Repository, Ref: rightRefURI.Ref + "@"}
+			} else {
+				rightRefURI = MustParseRefURI("right ref", args[1])
+			}
+			if leftRefURI.Repository != rightRefURI.Repository {
+				Die("both references must belong to the same repository", 1)
+			}
+			printDiffObjectContent(cmd.Context(), client, leftRefURI, rightRefURI, contentPath)
+			return
+		}
 		if len(args) == diffCmdMinArgs {
 			// got one arg ref: uncommitted changes diff
 			branchURI := MustParseRefURI("ref", args[0])
@@ -123,6 +145,74 @@ func printDiffRefs(ctx context.Context, client apigen.ClientWithResponsesInterfa
 	}
 }
 
+func printDiffObjectContent(ctx context.Context, client apigen.ClientWithResponsesInterface, left, right *uri.URI, path string) {
+	resp, err := client.DiffObjectContentWithResponse(ctx, left.Repository, left.Ref, right.Ref, &apigen.DiffObjectContentParams{
+		Path: path,
+	})
+	DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusOK)
+	if resp.JSON200 == nil {
+		Die("Bad response from server", 1)
+	}
+	res := resp.JSON200
+	printContentSummary("Left", left.Ref, path, res.Left)
+	printContentSummary("Right", right.Ref, path, res.Right)
+	switch {
+	case res.UnifiedDiff != nil:
+		for _, line := range strings.SplitAfter(*res.UnifiedDiff, "\n") {
+			var colors text.Colors
+			switch {
+			case strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++"):
+				colors = text.Colors{text.FgGreen}
+			case strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---"):
+				colors = text.Colors{text.FgRed}
+			}
+			_, _ = os.Stdout.WriteString(colors.Sprint(line))
+		}
+	case res.Table != nil:
+		table := res.Table
+		fmt.Printf("Schema: %s -> %s\n", fmtTableSchema(table.LeftSchema), fmtTableSchema(table.RightSchema))
+		fmt.Printf("Rows: %d -> %d (%d added, %d removed)\n", table.LeftRowCount, table.RightRowCount, table.AddedRowCount, table.RemovedRowCount)
+		for _, row := range table.RemovedRows {
+			_, _ = os.Stdout.WriteString(text.FgRed.Sprintf("- %s\n", row))
+		}
+		if more := table.RemovedRowCount - len(table.RemovedRows); more > 0 {
+			fmt.Printf("... %d more removed rows\n", more)
+		}
+		for _, row := range table.AddedRows {
+			_, _ = os.Stdout.WriteString(text.FgGreen.Sprintf("+ %s\n", row))
+		}
+		if more := table.AddedRowCount - len(table.AddedRows); more > 0 {
+			fmt.Printf("... %d more added rows\n", more)
+		}
+	default:
+		if res.Left != nil && res.Right != nil && res.Left.Checksum == res.Right.Checksum {
+			fmt.Println("Objects are identical")
+		} else {
+			fmt.Println("Binary objects differ")
+		}
+	}
+}
+
+func printContentSummary(side, ref, path string, summary *apigen.ObjectContentSummary) {
+	if summary == nil {
+		fmt.Printf("%s: %s/%s does not exist\n", side, ref, path)
+		return
+	}
+	fmt.Printf("%s: %s/%s (%d bytes, checksum %s)\n", side, ref, path, summary.SizeBytes, summary.Checksum)
+}
+
+func fmtTableSchema(schema []apigen.TableColumn) string {
+	columns := make([]string, 0, len(schema))
+	for _, column := range schema {
+		if column.Type != nil {
+			columns = append(columns, column.Name+" "+*column.Type)
+		} else {
+			columns = append(columns, column.Name)
+		}
+	}
+	return "(" + strings.Join(columns, ", ") + ")"
+}
+
 func FmtDiff(d apigen.Diff, withDirection bool) {
 	action, color := diff.Fmt(d.Type)
 
@@ -140,6 +230,7 @@ func FmtDiff(d apigen.Diff, withDirection bool) {
 //nolint:gochecknoinits
 func init() {
 	diffCmd.Flags().Bool(twoWayFlagName, false, "Use two-way diff: show difference between the given refs, regardless of a common ancestor.")
+	diffCmd.Flags().String(contentFlagName, "", "Show changes to the content of the object at this path: a unified diff for text objects, schema and rows for CSV and Parquet objects")
 
 	rootCmd.AddCommand(diffCmd)
 }
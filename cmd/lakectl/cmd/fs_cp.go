@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api/apigen"
+	"github.com/treeverse/lakefs/pkg/api/apiutil"
+	"github.com/treeverse/lakefs/pkg/cmdutils"
+	"github.com/treeverse/lakefs/pkg/uri"
+)
+
+const (
+	fsCopyParallelismDefault = 50
+
+	// fsMoveDeleteBatchSize is the number of moved objects deleted from their source by a single request
+	fsMoveDeleteBatchSize = 1000
+)
+
+var fsCpCmd = &cobra.Command{
+	Use:   "cp <source path uri> <destination path uri>",
+	Short: "Copy object(s) on the lakeFS server",
+	Long: `Copy objects without reading their data through lakectl.  The source may be on any ref of any
+repository, the destination must be on a branch.  With --recursive, all objects under the source path
+are copied under the destination path.`,
+	Example: `lakectl fs cp lakefs://example-repo/main/file.csv lakefs://example-repo/dev/
+lakectl fs cp --recursive lakefs://example-repo/main/tables/ lakefs://other-repo/dev/tables/`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: ValidArgsRepository,
+	Run: func(cmd *cobra.Command, args []string) {
+		fsCopyObjects(cmd, args, false)
+	},
+}
+
+// progressReporter reports a fixed list of progress bars
+type progressReporter []*cmdutils.Progress
+
+func (r progressReporter) Progress() []*cmdutils.Progress {
+	return r
+}
+
+// fsCopyObjects copies the object, or with --recursive the objects under the prefix, of the source path uri to the
+// destination path uri.  If move is set, copied objects are deleted from the source branch.
+func fsCopyObjects(cmd *cobra.Command, args []string, move bool) {
+	srcURI := MustParsePathURI("source path uri", args[0])
+	dstURI := MustParsePathURI("destination path uri", args[1])
+	flagSet := cmd.Flags()
+	recursive := Must(flagSet.GetBool("recursive"))
+	parallelism := Must(flagSet.GetInt("parallelism"))
+	noProgress := Must(flagSet.GetBool("no-progress"))
+	if parallelism < 1 {
+		DieFmt("Invalid value for parallelism (%d), minimum is 1.\n", parallelism)
+	}
+
+	srcPath := srcURI.GetPath()
+	dstPath := dstURI.GetPath()
+	sameRef := srcURI.Repository == dstURI.Repository && srcURI.Ref == dstURI.Ref
+	if recursive {
+		// recursive assumes both paths are directories
+		if srcPath != "" && !strings.HasSuffix(srcPath, uri.PathSeparator) {
+			srcPath += uri.PathSeparator
+		}
+		if dstPath != "" && !strings.HasSuffix(dstPath, uri.PathSeparator) {
+			dstPath += uri.PathSeparator
+		}
+		if sameRef && strings.HasPrefix(dstPath, srcPath) {
+			DieFmt("Destination %s is under source %s", dstURI, srcURI)
+		}
+	} else {
+		if dstPath == "" || strings.HasSuffix(dstPath, uri.PathSeparator) {
+			dstPath += path.Base(srcPath)
+		}
+		if sameRef && dstPath == srcPath {
+			DieFmt("Source and destination are the same object: %s", srcURI)
+		}
+	}
+
+	ctx := cmd.Context()
+	client := getClient()
+	label := "Objects copied"
+	if move {
+		label = "Objects moved"
+	}
+	progress := cmdutils.NewActiveProgress(label, cmdutils.Bar)
+	var bar *cmdutils.MultiBar
+	if !noProgress && recursive {
+		bar = cmdutils.NewMultiBar(progressReporter{progress})
+		bar.Start()
+	}
+
+	// list objects to copy
+	copyCh := make(chan string)
+	go func() {
+		defer close(copyCh)
+		if !recursive {
+			progress.SetTotal(1)
+			copyCh <- srcPath
+			return
+		}
+		listCh := make(chan string)
+		go func() {
+			defer close(listCh)
+			listRecursiveHelper(ctx, client, srcURI.Repository, srcURI.Ref, srcPath, listCh)
+		}()
+		for p := range listCh {
+			progress.SetTotal(progress.Total() + 1)
+			copyCh <- p
+		}
+	}()
+
+	// delete moved objects from their source
+	var (
+		deleteWg   sync.WaitGroup
+		errCounter int64
+	)
+	deleteCh := make(chan string)
+	if move {
+		deleteWg.Add(1)
+		go func() {
+			defer deleteWg.Done()
+			deleteObjectsBatches(ctx, client, srcURI.Repository, srcURI.Ref, deleteCh, &errCounter)
+		}()
+	}
+
+	// copy in parallel
+	var copyWg sync.WaitGroup
+	copyWg.Add(parallelism)
+	for i := 0; i < parallelism; i++ {
+		go func() {
+			defer copyWg.Done()
+			for objPath := range copyCh {
+				objDstPath := dstPath
+				if recursive {
+					objDstPath += strings.TrimPrefix(objPath, srcPath)
+				}
+				err := copyObject(ctx, client, srcURI.Repository, srcURI.Ref, objPath, dstURI.Repository, dstURI.Ref, objDstPath)
+				if err != nil {
+					_, _ = fmt.Fprintf(os.Stderr, "Copy failed: %s to %s - %s\n", objPath, objDstPath, err)
+					atomic.AddInt64(&errCounter, 1)
+					continue
+				}
+				progress.Incr()
+				if move {
+					deleteCh <- objPath
+				}
+			}
+		}()
+	}
+	copyWg.Wait()
+	close(deleteCh)
+	deleteWg.Wait()
+	if bar != nil {
+		bar.Stop()
+	}
+
+	if atomic.LoadInt64(&errCounter) > 0 {
+		defer os.Exit(1)
+	}
+}
+
+func copyObject(ctx context.Context, client apigen.ClientWithResponsesInterface, srcRepository, srcRef, srcPath, dstRepository, dstBranch, dstPath string) error {
+	resp, err := client.CopyObjectWithResponse(ctx, dstRepository, dstBranch, &apigen.CopyObjectParams{
+		DestPath: dstPath,
+	}, apigen.CopyObjectJSONRequestBody{
+		SrcPath:       srcPath,
+		SrcRef:        apiutil.Ptr(srcRef),
+		SrcRepository: apiutil.Ptr(srcRepository),
+	})
+	return RetrieveError(resp, err)
+}
+
+// deleteObjectsBatches deletes the paths it reads from branch in batches of fsMoveDeleteBatchSize, counting the
+// paths it failed to delete in errCounter
+func deleteObjectsBatches(ctx context.Context, client apigen.ClientWithResponsesInterface, repository, branch string, paths <-chan string, errCounter *int64) {
+	batch := make([]string, 0, fsMoveDeleteBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		resp, err := client.DeleteObjectsWithResponse(ctx, repository, branch, apigen.DeleteObjectsJSONRequestBody{Paths: batch})
+		if err := RetrieveError(resp, err); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Delete failed: %d objects from %s - %s\n", len(batch), branch, err)
+			atomic.AddInt64(errCounter, int64(len(batch)))
+		} else if resp.JSON200 != nil {
+			for _, objErr := range resp.JSON200.Errors {
+				_, _ = fmt.Fprintf(os.Stderr, "Delete failed: %s - %s\n", apiutil.Value(objErr.Path), objErr.Message)
+				atomic.AddInt64(errCounter, 1)
+			}
+		}
+		batch = batch[:0]
+	}
+	for p := range paths {
+		batch = append(batch, p)
+		if len(batch) == fsMoveDeleteBatchSize {
+			flush()
+		}
+	}
+	flush()
+}
+
+//nolint:gochecknoinits
+func init() {
+	fsCpCmd.Flags().BoolP("recursive", "r", false, "recursively copy all objects under the source path")
+	fsCpCmd.Flags().IntP("parallelism", "p", fsCopyParallelismDefault, "max concurrent copy operations to send to the lakeFS server")
+	fsCpCmd.Flags().Bool("no-progress", false, "switch off the progress output")
+
+	fsCmd.AddCommand(fsCpCmd)
+}
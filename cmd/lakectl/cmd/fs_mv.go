@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var fsMvCmd = &cobra.Command{
+	Use:   "mv <source path uri> <destination path uri>",
+	Short: "Move object(s) on the lakeFS server",
+	Long: `Move objects by copying them on the server and deleting them from the source branch.  The source
+and the destination may be on different branches and repositories.  With --recursive, all objects under
+the source path are moved under the destination path.  Objects that fail to copy are not deleted.`,
+	Example: `lakectl fs mv lakefs://example-repo/main/file.csv lakefs://example-repo/main/archive/
+lakectl fs mv --recursive lakefs://example-repo/dev/tables/ lakefs://other-repo/dev/tables/`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: ValidArgsRepository,
+	Run: func(cmd *cobra.Command, args []string) {
+		fsCopyObjects(cmd, args, true)
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	fsMvCmd.Flags().BoolP("recursive", "r", false, "recursively move all objects under the source path")
+	fsMvCmd.Flags().IntP("parallelism", "p", fsCopyParallelismDefault, "max concurrent copy operations to send to the lakeFS server")
+	fsMvCmd.Flags().Bool("no-progress", false, "switch off the progress output")
+
+	fsCmd.AddCommand(fsMvCmd)
+}
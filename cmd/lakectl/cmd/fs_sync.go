@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api/apigen"
+	"github.com/treeverse/lakefs/pkg/local"
+	"github.com/treeverse/lakefs/pkg/uri"
+	"golang.org/x/sync/errgroup"
+)
+
+// fsSyncStateDir holds the state of interrupted multipart uploads
+const fsSyncStateDir = "~/.lakectl.fs-sync"
+
+const fsSyncSummaryTemplate = `
+Sync Summary:
+
+{{ if and (eq .Downloaded 0) (eq .Uploaded 0) (eq .Copied 0) (eq .Removed 0)}}No changes{{else -}}
+{{"Downloaded:" | printf|green}} {{.Downloaded|green}}
+{{"Uploaded:" | printf|yellow}} {{.Uploaded|yellow}}
+{{"Copied:" | printf|yellow}} {{.Copied|yellow}}
+{{"Removed:" | printf|red}} {{.Removed|red}}
+{{end}}
+`
+
+const fsSyncDryRunTemplate = `{{range .Changes}}{{.Operation|printf "%-10s"}} {{.Path}}
+{{end}}
+{{ if .Changes }}{{ len .Changes }} changes{{else}}No changes{{end}} (dry run)
+`
+
+// fsSyncLocation is the source or the destination of a sync: either a lakeFS prefix or a local directory
+type fsSyncLocation struct {
+	remote    *uri.URI
+	localPath string
+}
+
+func (l fsSyncLocation) String() string {
+	if l.remote != nil {
+		return l.remote.String()
+	}
+	return "local://" + l.localPath
+}
+
+type fsSyncChange struct {
+	Operation string
+	Path      string
+}
+
+var fsSyncCmd = &cobra.Command{
+	Use:   "sync <source> <destination>",
+	Short: "Synchronize a local directory or a lakeFS prefix with another",
+	Long: `Make the destination the same as the source.  Either may be a local directory or a lakeFS path uri, whose
+path is synced as a prefix; a lakeFS destination must be on a branch.  Objects are transferred when they are
+missing on the destination or differ in size or modification time, or with --checksum in size or checksum.
+Objects are copied between lakeFS prefixes on the server and always compared by checksum.  With --delete,
+destination objects missing on the source are deleted.
+
+Unlike 'lakectl local', no index is kept: an interrupted sync is resumed by running it again, which skips the
+objects that were already synced.  Files of 64 MiB or more are uploaded through the lakeFS server in multipart
+uploads, whose state is kept under ` + fsSyncStateDir + `: running the sync again resumes an interrupted upload from
+its last uploaded part.  Other transfers that were interrupted start again from their start.`,
+	Example: `lakectl fs sync ./output lakefs://example-repo/main/output/
+lakectl fs sync --delete --exclude '**.tmp' lakefs://example-repo/main/output/ ./output
+lakectl fs sync --dry-run lakefs://example-repo/main/tables/ lakefs://other-repo/main/tables/`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		flagSet := cmd.Flags()
+		deleteRemoved := Must(flagSet.GetBool("delete"))
+		dryRun := Must(flagSet.GetBool("dry-run"))
+		checksum := Must(flagSet.GetBool("checksum"))
+		include := Must(flagSet.GetStringSlice("include"))
+		exclude := Must(flagSet.GetStringSlice("exclude"))
+		filter, err := local.NewPathFilter(include, exclude)
+		if err != nil {
+			DieErr(err)
+		}
+
+		source := parseFsSyncLocation("source", args[0], true)
+		destination := parseFsSyncLocation("destination", args[1], false)
+		if source.remote == nil && destination.remote == nil {
+			Die("At least one of source and destination must be a lakeFS path uri", 1)
+		}
+
+		ctx := cmd.Context()
+		client := getClient()
+		if destination.remote != nil {
+			err, ok := branchExists(ctx, client, destination.remote.Repository, destination.remote.Ref)
+			if err != nil {
+				DieErr(err)
+			}
+			if !ok {
+				DieFmt("Invalid 'destination': %s is not a branch", destination.remote.Ref)
+			}
+		}
+		opts := local.DiffOptions{
+			Filter:   filter,
+			Checksum: checksum,
+		}
+		fmt.Printf("\nsync '%s' --> '%s'...\n", source, destination)
+		changes := fsSyncDiff(ctx, client, source, destination, opts)
+		if !deleteRemoved {
+			changes = fsSyncWithoutRemoved(changes)
+		}
+
+		if dryRun {
+			dryRunChanges := make([]fsSyncChange, len(changes))
+			for i, change := range changes {
+				dryRunChanges[i] = fsSyncChange{
+					Operation: fsSyncOperation(change, source, destination),
+					Path:      change.Path,
+				}
+			}
+			Write(fsSyncDryRunTemplate, struct{ Changes []fsSyncChange }{Changes: dryRunChanges})
+			return
+		}
+
+		remote := destination.remote
+		if remote == nil {
+			remote = source.remote
+		}
+		syncFlags := getLocalSyncFlags(cmd, client, remote.Repository)
+		c := make(chan *local.Change, filesChanSize)
+		go func() {
+			defer close(c)
+			for _, change := range changes {
+				c <- change
+			}
+		}()
+		stateDir := Must(homedir.Expand(fsSyncStateDir))
+		s := local.NewSyncManager(ctx, client, syncFlags.parallelism, syncFlags.presign).
+			WithResumableUploads(local.NewMultipartStateStore(stateDir), local.DefaultMultipartPartSize)
+		switch {
+		case source.remote != nil && destination.remote != nil:
+			err = s.CopyRemote(source.remote, destination.remote, c)
+		case source.remote != nil:
+			err = s.Apply(destination.localPath, source.remote, c)
+		default:
+			err = s.Apply(source.localPath, destination.remote, c)
+		}
+		if err != nil {
+			DieErr(err)
+		}
+
+		fmt.Printf("\nSuccessfully synced changes!\n")
+		Write(fsSyncSummaryTemplate, s.Summary())
+	},
+}
+
+// parseFsSyncLocation parses a lakeFS path uri, or a path of a local directory.  A local source must exist, a local
+// destination is created if needed.
+func parseFsSyncLocation(name, arg string, isSource bool) fsSyncLocation {
+	if strings.HasPrefix(arg, uri.LakeFSSchema+uri.LakeFSSchemaSeparator) {
+		u, err := uri.Parse(arg)
+		if err != nil {
+			DieFmt("Invalid '%s': %s", name, err)
+		}
+		if !u.IsRef() && !u.IsFullyQualified() {
+			DieFmt("Invalid '%s': %s", name, uri.ErrInvalidPathURI)
+		}
+		// the path is a prefix, synced as a directory
+		p := u.GetPath()
+		if p != "" && !strings.HasSuffix(p, uri.PathSeparator) {
+			p += uri.PathSeparator
+		}
+		u.Path = &p
+		return fsSyncLocation{remote: u}
+	}
+	localPath := Must(filepath.Abs(Must(homedir.Expand(arg))))
+	if isSource {
+		info, err := os.Stat(localPath)
+		if err != nil {
+			DieErr(err)
+		}
+		if !info.IsDir() {
+			DieFmt("Invalid '%s': %s is not a directory", name, localPath)
+		}
+	} else if err := os.MkdirAll(localPath, local.DefaultDirectoryMask); err != nil {
+		DieErr(err)
+	}
+	return fsSyncLocation{localPath: localPath}
+}
+
+// fsSyncDiff returns the changes that make destination the same as source
+func fsSyncDiff(ctx context.Context, client apigen.ClientWithResponsesInterface, source, destination fsSyncLocation, opts local.DiffOptions) local.Changes {
+	var wg errgroup.Group
+	var (
+		changes local.Changes
+		err     error
+	)
+	switch {
+	case source.remote != nil && destination.remote != nil:
+		sourceObjects := make(chan apigen.ObjectStats, maxDiffPageSize)
+		destinationObjects := make(chan apigen.ObjectStats, maxDiffPageSize)
+		wg.Go(func() error {
+			return local.ListRemote(ctx, client, source.remote, sourceObjects)
+		})
+		wg.Go(func() error {
+			return local.ListRemote(ctx, client, destination.remote, destinationObjects)
+		})
+		changes = local.DiffRemotes(sourceObjects, destinationObjects, opts)
+	case source.remote != nil:
+		sourceObjects := make(chan apigen.ObjectStats, maxDiffPageSize)
+		wg.Go(func() error {
+			return local.ListRemote(ctx, client, source.remote, sourceObjects)
+		})
+		// the diff makes the remote objects the same as the local directory, undo it for the other direction
+		changes, err = local.DiffLocalWithRemote(sourceObjects, destination.localPath, opts)
+		changes = local.Undo(changes)
+	default:
+		destinationObjects := make(chan apigen.ObjectStats, maxDiffPageSize)
+		wg.Go(func() error {
+			return local.ListRemote(ctx, client, destination.remote, destinationObjects)
+		})
+		changes, err = local.DiffLocalWithRemote(destinationObjects, source.localPath, opts)
+	}
+	if err != nil {
+		DieErr(err)
+	}
+	if err := wg.Wait(); err != nil {
+		DieErr(err)
+	}
+	return changes
+}
+
+func fsSyncWithoutRemoved(changes local.Changes) local.Changes {
+	res := make(local.Changes, 0, len(changes))
+	for _, change := range changes {
+		if change.Type != local.ChangeTypeRemoved {
+			res = append(res, change)
+		}
+	}
+	return res
+}
+
+// fsSyncOperation describes how sync applies change
+func fsSyncOperation(change *local.Change, source, destination fsSyncLocation) string {
+	switch {
+	case change.Type == local.ChangeTypeRemoved:
+		return "delete"
+	case source.remote != nil && destination.remote != nil:
+		return "copy"
+	case source.remote != nil:
+		return "download"
+	default:
+		return "upload"
+	}
+}
+
+//nolint:gochecknoinits
+func init() {
+	fsSyncCmd.Flags().Bool("delete", false, "delete destination objects that are missing on the source")
+	fsSyncCmd.Flags().Bool("dry-run", false, "list the changes a sync would apply without applying them")
+	fsSyncCmd.Flags().Bool("checksum", false, "compare objects of the same size by checksum rather than by modification time")
+	fsSyncCmd.Flags().StringSlice("include", nil, "sync only paths that match one of these glob patterns")
+	fsSyncCmd.Flags().StringSlice("exclude", nil, "do not sync paths that match one of these glob patterns")
+	withLocalSyncFlags(fsSyncCmd)
+
+	fsCmd.AddCommand(fsSyncCmd)
+}
@@ -34,6 +34,7 @@ var importCmd = &cobra.Command{
 		to := Must(flags.GetString("to"))
 		toURI := MustParsePathURI("to", to)
 		message := Must(flags.GetString("message"))
+		inventoryManifest := Must(flags.GetString("inventory"))
 		metadata, err := getKV(cmd, "meta")
 		if err != nil {
 			DieErr(err)
@@ -41,7 +42,7 @@ var importCmd = &cobra.Command{
 
 		ctx := cmd.Context()
 		client := getClient()
-		verifySourceMatchConfiguredStorage(ctx, client, from)
+		verifySourceMatchConfiguredStorage(ctx, client, toURI.Repository, from)
 
 		// verify target branch exists before we try to create and import into the associated imported branch
 		if err, ok := branchExists(ctx, client, toURI.Repository, toURI.Ref); err != nil {
@@ -64,6 +65,9 @@ var importCmd = &cobra.Command{
 				},
 			},
 		}
+		if inventoryManifest != "" {
+			body.Paths[0].InventoryManifest = &inventoryManifest
+		}
 		if len(metadata) > 0 {
 			body.Commit.Metadata = &apigen.CommitCreation_Metadata{AdditionalProperties: metadata}
 		}
@@ -163,8 +167,8 @@ func newImportProgressBar(visible bool) *progressbar.ProgressBar {
 	return bar
 }
 
-func verifySourceMatchConfiguredStorage(ctx context.Context, client *apigen.ClientWithResponses, source string) {
-	storageConfResp, err := client.GetStorageConfigWithResponse(ctx)
+func verifySourceMatchConfiguredStorage(ctx context.Context, client *apigen.ClientWithResponses, repository, source string) {
+	storageConfResp, err := client.GetStorageConfigWithResponse(ctx, &apigen.GetStorageConfigParams{Repository: &repository})
 	DieOnErrorOrUnexpectedStatusCode(storageConfResp, err, http.StatusOK)
 	storageConfig := storageConfResp.JSON200
 	if storageConfig == nil {
@@ -202,6 +206,7 @@ func init() {
 	_ = importCmd.MarkFlagRequired("from")
 	importCmd.Flags().String("to", "", "lakeFS path to load objects into (e.g. \"lakefs://repo/branch/sub/path/\")")
 	_ = importCmd.MarkFlagRequired("to")
+	importCmd.Flags().String("inventory", "", "inventory manifest to read the objects from instead of listing them (e.g. \"s3://inventory-bucket/bucket/daily/2023-01-01T01-00Z/manifest.json\")")
 	importCmd.Flags().Bool("merge", false, "merge imported branch into target branch")
 	_ = importCmd.Flags().MarkDeprecated("merge", "import is done directly into target branch")
 	importCmd.Flags().Bool("no-progress", false, "switch off the progress output")
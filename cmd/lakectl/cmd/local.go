@@ -82,11 +82,11 @@ type syncFlags struct {
 	presign     bool
 }
 
-func getLocalSyncFlags(cmd *cobra.Command, client *apigen.ClientWithResponses) syncFlags {
+func getLocalSyncFlags(cmd *cobra.Command, client *apigen.ClientWithResponses, repository string) syncFlags {
 	presign := Must(cmd.Flags().GetBool(localPresignFlagName))
 	presignFlag := cmd.Flags().Lookup(localPresignFlagName)
 	if !presignFlag.Changed {
-		resp, err := client.GetStorageConfigWithResponse(cmd.Context())
+		resp, err := client.GetStorageConfigWithResponse(cmd.Context(), &apigen.GetStorageConfigParams{Repository: &repository})
 		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusOK)
 		if resp.JSON200 == nil {
 			Die("Bad response from server", 1)
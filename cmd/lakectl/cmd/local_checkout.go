@@ -40,7 +40,6 @@ var localCheckoutCmd = &cobra.Command{
 
 func localCheckout(cmd *cobra.Command, localPath string, specifiedRef string, confirmByFlag bool) {
 	client := getClient()
-	locaSyncFlags := getLocalSyncFlags(cmd, client)
 	idx, err := local.ReadIndex(localPath)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
@@ -53,6 +52,7 @@ func localCheckout(cmd *cobra.Command, localPath string, specifiedRef string, co
 	if err != nil {
 		DieErr(err)
 	}
+	locaSyncFlags := getLocalSyncFlags(cmd, client, remote.Repository)
 
 	currentBase := remote.WithRef(idx.AtHead)
 	diffs := local.Undo(localDiff(cmd.Context(), client, currentBase, idx.LocalPath()))
@@ -20,7 +20,6 @@ var localPullCmd = &cobra.Command{
 		client := getClient()
 		_, localPath := getLocalArgs(args, false, false)
 		force := Must(cmd.Flags().GetBool(localForceFlagName))
-		syncFlags := getLocalSyncFlags(cmd, client)
 		idx, err := local.ReadIndex(localPath)
 		if err != nil {
 			DieErr(err)
@@ -30,6 +29,7 @@ var localPullCmd = &cobra.Command{
 		if err != nil {
 			DieErr(err)
 		}
+		syncFlags := getLocalSyncFlags(cmd, client, remote.Repository)
 
 		dieOnInterruptedOperation(LocalOperation(idx.ActiveOperation), force)
 
@@ -19,6 +19,8 @@ const commitsTemplate = `{{ range $val := .Commits }}
 ID:            {{ $val.Id|yellow }}{{if $val.Committer }}
 Author:        {{ $val.Committer }}{{end}}
 Date:          {{ $val.CreationDate|date }}
+{{ if $val.Signature }}Signature:     {{ if $val.Signature.Verified }}{{ "verified"|green }}{{ else }}{{ "unverified"|red }}{{ end }} {{ $val.Signature.KeyFingerprint }}
+{{ end -}}
 {{ if $.ShowMetaRangeID }}Meta Range ID: {{ $val.MetaRangeId }}
 {{ end -}}
 {{ if gt ($val.Parents|len) 1 -}}
@@ -91,6 +93,7 @@ var logCmd = &cobra.Command{
 
 		pagination := apigen.Pagination{HasMore: true}
 		showMetaRangeID := Must(cmd.Flags().GetBool("show-meta-range-id"))
+		showSignatures := Must(cmd.Flags().GetBool("show-signatures"))
 		client := getClient()
 		branchURI := MustParseRefURI("branch", args[0])
 		amountForPagination := amount
@@ -102,6 +105,7 @@ var logCmd = &cobra.Command{
 			Amount:      apiutil.Ptr(apigen.PaginationAmount(amountForPagination)),
 			Limit:       &limit,
 			FirstParent: &firstParent,
+			Signatures:  &showSignatures,
 		}
 		if len(objects) > 0 {
 			logCommitsParams.Objects = &objects
@@ -167,6 +171,7 @@ func init() {
 	logCmd.Flags().Bool("dot", false, "return results in a dotgraph format")
 	logCmd.Flags().Bool("first-parent", false, "follow only the first parent commit upon seeing a merge commit")
 	logCmd.Flags().Bool("show-meta-range-id", false, "also show meta range ID")
+	logCmd.Flags().Bool("show-signatures", false, "also show commit signatures")
 	logCmd.Flags().StringSlice("objects", nil, "show results that contains changes to at least one path in that list of objects. Use comma separator to pass all objects together")
 	logCmd.Flags().StringSlice("prefixes", nil, "show results that contains changes to at least one path in that list of prefixes. Use comma separator to pass all prefixes together")
 }
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/mount"
+)
+
+var mountCmd = &cobra.Command{
+	Use:   "mount <ref uri> <mountpoint>",
+	Short: "Mount a ref as a read-only filesystem",
+	Long: `Mount a commit, tag or branch as a read-only filesystem using FUSE, on Linux and macOS.  A branch or a tag is
+resolved to its commit when mounted, so the mounted files never change.  Directories are listed and files are
+read from lakeFS on demand, in blocks that are cached locally.  The mount is served until lakectl is
+interrupted, or the mountpoint is unmounted.`,
+	Example: `lakectl mount lakefs://example-repo/main ./example-repo
+lakectl mount --cache-dir ~/.cache/lakefs lakefs://example-repo/v1.2 /mnt/example-repo`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: ValidArgsRepository,
+	Run: func(cmd *cobra.Command, args []string) {
+		refURI := MustParseRefURI("ref uri", args[0])
+		mountpoint := args[1]
+		cacheDir := Must(cmd.Flags().GetString("cache-dir"))
+		blockSize := Must(cmd.Flags().GetInt64("block-size"))
+		ctx := cmd.Context()
+		client := getClient()
+		syncFlags := getLocalSyncFlags(cmd, client, refURI.Repository)
+
+		commitID := resolveCommitOrDie(ctx, client, refURI.Repository, refURI.Ref)
+		// DieErr exits without running deferred calls, the temporary cache directory is removed explicitly
+		removeCacheDir := func() {}
+		if cacheDir == "" {
+			tempDir, err := os.MkdirTemp("", "lakectl-mount-")
+			if err != nil {
+				DieErr(err)
+			}
+			removeCacheDir = func() { _ = os.RemoveAll(tempDir) }
+			cacheDir = tempDir
+		}
+		defer removeCacheDir()
+
+		fsys := mount.NewFS(ctx, client, refURI.Repository, commitID, mount.Options{
+			Presign:   syncFlags.presign,
+			BlockSize: blockSize,
+			CacheDir:  cacheDir,
+		})
+		server, err := mount.Mount(fsys, mountpoint)
+		if err != nil {
+			removeCacheDir()
+			DieErr(err)
+		}
+		fmt.Printf("Mounted %s (commit %s) read-only at %s, interrupt to unmount\n", refURI, commitID, mountpoint)
+
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigCh
+			if err := server.Unmount(); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "Unmount %s failed: %s\n", mountpoint, err)
+			}
+		}()
+		server.Wait()
+		fmt.Printf("Unmounted %s\n", mountpoint)
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	mountCmd.Flags().String("cache-dir", "", "directory of the block cache, kept after unmount (default: a temporary directory removed after unmount)")
+	mountCmd.Flags().Int64("block-size", mount.DefaultBlockSize, "size of the blocks in which files are read and cached")
+	withPresignFlag(mountCmd)
+
+	rootCmd.AddCommand(mountCmd)
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/treeverse/lakefs/pkg/api/apigen"
@@ -17,9 +18,10 @@ const (
 // repoCreateCmd represents the create repo command
 // lakectl create lakefs://myrepo s3://my-bucket/
 var repoCreateCmd = &cobra.Command{
-	Use:               "create <repository uri> <storage namespace>",
-	Short:             "Create a new repository",
-	Example:           "lakectl repo create lakefs://some-repo-name s3://some-bucket-name",
+	Use:   "create <repository uri> <storage namespace>",
+	Short: "Create a new repository",
+	Example: `lakectl repo create lakefs://some-repo-name s3://some-bucket-name
+lakectl repo create lakefs://some-repo-name s3://some-bucket-name/some-repo-name --from-template some-template@main`,
 	Args:              cobra.ExactArgs(repoCreateCmdArgs),
 	ValidArgsFunction: ValidArgsRepository,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -30,12 +32,30 @@ var repoCreateCmd = &cobra.Command{
 		if err != nil {
 			DieErr(err)
 		}
+		compression, err := cmd.Flags().GetString("compression")
+		if err != nil {
+			DieErr(err)
+		}
+		fromTemplate, err := cmd.Flags().GetString("from-template")
+		if err != nil {
+			DieErr(err)
+		}
+		var template *apigen.RepositoryTemplate
+		if fromTemplate != "" {
+			templateRepository, templateRef, found := strings.Cut(fromTemplate, "@")
+			if !found || templateRepository == "" || templateRef == "" {
+				DieFmt("Invalid template %q, expected <repository>@<ref>", fromTemplate)
+			}
+			template = &apigen.RepositoryTemplate{Repository: templateRepository, Ref: templateRef}
+		}
 		resp, err := clt.CreateRepositoryWithResponse(cmd.Context(),
 			&apigen.CreateRepositoryParams{},
 			apigen.CreateRepositoryJSONRequestBody{
 				Name:             u.Repository,
 				StorageNamespace: args[1],
 				DefaultBranch:    &defaultBranch,
+				Compression:      &compression,
+				Template:         template,
 			})
 		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusCreated)
 		if resp.JSON201 == nil {
@@ -49,6 +69,8 @@ var repoCreateCmd = &cobra.Command{
 //nolint:gochecknoinits
 func init() {
 	repoCreateCmd.Flags().StringP("default-branch", "d", DefaultBranch, "the default branch of this repository")
+	repoCreateCmd.Flags().String("compression", "none", "codec that compresses data written to this repository (none, gzip or zstd)")
+	repoCreateCmd.Flags().String("from-template", "", "create the repository from the content, branch protection and GC rules of <repository>@<ref>")
 
 	repoCmd.AddCommand(repoCreateCmd)
 }
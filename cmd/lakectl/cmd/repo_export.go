@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/go-openapi/swag"
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api/apigen"
+	"github.com/treeverse/lakefs/pkg/api/helpers"
+)
+
+const repoExportSuccess = `
+{{ "Repository exported successfully!" | green }}
+`
+
+var repoExportCmd = &cobra.Command{
+	Use:   "export <repository uri>",
+	Short: "Export a repository to a self-contained archive",
+	Long: `Export the commits, branches and tags of a repository, together with the metadata they reference, to a tar archive.
+
+With --include-objects the archive also holds every object referenced by a commit, and objects stored outside the
+storage namespace of the repository are rewritten to be stored relative to it.  Such an archive can be imported
+into a bare repository on any lakeFS installation and blockstore using 'lakectl repo import'.
+Uncommitted changes are not exported.`,
+	Example:           "lakectl repo export lakefs://my-repo --to my-repo.tar --include-objects",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: ValidArgsRepository,
+	Run: func(cmd *cobra.Command, args []string) {
+		u := MustParseRepoURI("repository", args[0])
+		to := Must(cmd.Flags().GetString("to"))
+		includeObjects := Must(cmd.Flags().GetBool("include-objects"))
+
+		client := getClient()
+		resp, err := client.ExportRepositoryArchive(cmd.Context(), u.Repository, &apigen.ExportRepositoryArchiveParams{
+			IncludeObjects: swag.Bool(includeObjects),
+		})
+		if err != nil {
+			DieErr(err)
+		}
+		DieOnHTTPError(resp)
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		if err := writeArchive(cmd.Context(), to, &archiveResponseReader{resp: resp}); err != nil {
+			DieErr(err)
+		}
+		if to != StdinFileName {
+			Write(repoExportSuccess, nil)
+		}
+	},
+}
+
+// writeArchive writes the archive read from r to location: a local file,
+// an object store URI or "-" for stdout.
+func writeArchive(ctx context.Context, location string, r io.Reader) error {
+	if location == StdinFileName {
+		_, err := io.Copy(os.Stdout, r)
+		return err
+	}
+	if !strings.Contains(location, "://") {
+		f, err := os.Create(location)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, r); err != nil {
+			_ = f.Close()
+			_ = os.Remove(location)
+			return err
+		}
+		return f.Close()
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", location, err)
+	}
+	adapter, err := helpers.NewAdapter(u.Scheme)
+	if err != nil {
+		return fmt.Errorf("%s: %w", location, err)
+	}
+	// uploading requires a seekable stream
+	temp, err := os.CreateTemp("", "lakectl-export")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = temp.Close()
+		_ = os.Remove(temp.Name())
+	}()
+	if _, err := io.Copy(temp, r); err != nil {
+		return err
+	}
+	if _, err := temp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err = adapter.Upload(ctx, u, temp)
+	return err
+}
+
+// archiveResponseReader reads the archive body of an export response, and
+// fails instead of ending if the server reports that the export failed.
+type archiveResponseReader struct {
+	resp *http.Response
+}
+
+func (r *archiveResponseReader) Read(p []byte) (int, error) {
+	n, err := r.resp.Body.Read(p)
+	if errors.Is(err, io.EOF) {
+		if exportErr := helpers.ArchiveTrailerAsError(r.resp); exportErr != nil {
+			return n, exportErr
+		}
+	}
+	return n, err
+}
+
+// openArchive opens the archive at location: a local file, an object store
+// URI or "-" for stdin.
+func openArchive(ctx context.Context, location string) (io.ReadCloser, error) {
+	if location == StdinFileName {
+		return io.NopCloser(os.Stdin), nil
+	}
+	if !strings.Contains(location, "://") {
+		return os.Open(location)
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", location, err)
+	}
+	adapter, err := helpers.NewAdapter(u.Scheme)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", location, err)
+	}
+	return adapter.Download(ctx, u)
+}
+
+//nolint:gochecknoinits
+func init() {
+	repoExportCmd.Flags().String("to", "", "archive destination: a local file, an S3 URI (s3://bucket/key) or \"-\" for stdout")
+	_ = repoExportCmd.MarkFlagRequired("to")
+	repoExportCmd.Flags().Bool("include-objects", false, "also export all objects referenced by commits")
+
+	repoCmd.AddCommand(repoExportCmd)
+}
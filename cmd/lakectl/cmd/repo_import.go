@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/swag"
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api/apigen"
+)
+
+const repoImportSuccess = `
+{{ "Repository imported successfully!" | green }}
+`
+
+var repoImportCmd = &cobra.Command{
+	Use:   "import <repository uri>",
+	Short: "Import a repository archive into a bare repository",
+	Long: `Import an archive created by 'lakectl repo export' into a bare repository.
+
+The files of the archive are stored in the storage namespace of the repository before its commits, branches and tags
+are loaded.  The repository must be bare (i.e. one created with 'lakectl repo create-bare'); pass --storage-namespace
+to create it.  Only archives exported with --include-objects hold the data of the repository.`,
+	Example:           "lakectl repo import lakefs://my-repo --from my-repo.tar --storage-namespace s3://my-bucket/my-repo",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: ValidArgsRepository,
+	Run: func(cmd *cobra.Command, args []string) {
+		u := MustParseRepoURI("repository", args[0])
+		fmt.Println("Repository:", u)
+		from := Must(cmd.Flags().GetString("from"))
+		storageNamespace := Must(cmd.Flags().GetString("storage-namespace"))
+		defaultBranch := Must(cmd.Flags().GetString("default-branch"))
+
+		client := getClient()
+		if storageNamespace != "" {
+			resp, err := client.CreateRepositoryWithResponse(cmd.Context(), &apigen.CreateRepositoryParams{
+				Bare: swag.Bool(true),
+			}, apigen.CreateRepositoryJSONRequestBody{
+				DefaultBranch:    &defaultBranch,
+				Name:             u.Repository,
+				StorageNamespace: storageNamespace,
+			})
+			DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusCreated)
+		}
+
+		archive, err := openArchive(cmd.Context(), from)
+		if err != nil {
+			DieErr(err)
+		}
+		defer func() {
+			_ = archive.Close()
+		}()
+		resp, err := client.ImportRepositoryArchiveWithBodyWithResponse(cmd.Context(), u.Repository, "application/octet-stream", archive)
+		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusCreated)
+		Write(repoImportSuccess, nil)
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	repoImportCmd.Flags().String("from", "", "archive source: a local file, an S3 URI (s3://bucket/key) or \"-\" for stdin")
+	_ = repoImportCmd.MarkFlagRequired("from")
+	repoImportCmd.Flags().String("storage-namespace", "", "create the repository as a bare repository on this storage namespace")
+	repoImportCmd.Flags().StringP("default-branch", "d", DefaultBranch, "the default branch name of the repository created with --storage-namespace")
+
+	repoCmd.AddCommand(repoImportCmd)
+}
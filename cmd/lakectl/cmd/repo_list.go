@@ -19,11 +19,13 @@ var repoListCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		amount := Must(cmd.Flags().GetInt("amount"))
 		after := Must(cmd.Flags().GetString("after"))
+		includeArchived := Must(cmd.Flags().GetBool("include-archived"))
 		clt := getClient()
 
 		resp, err := clt.ListRepositoriesWithResponse(cmd.Context(), &apigen.ListRepositoriesParams{
-			After:  apiutil.Ptr(apigen.PaginationAfter(after)),
-			Amount: apiutil.Ptr(apigen.PaginationAmount(amount)),
+			After:           apiutil.Ptr(apigen.PaginationAfter(after)),
+			Amount:          apiutil.Ptr(apigen.PaginationAmount(amount)),
+			IncludeArchived: apiutil.Ptr(includeArchived),
 		})
 		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusOK)
 		if resp.JSON200 == nil {
@@ -44,6 +46,7 @@ var repoListCmd = &cobra.Command{
 func init() {
 	repoListCmd.Flags().Int("amount", defaultAmountArgumentValue, "number of results to return")
 	repoListCmd.Flags().String("after", "", "show results after this value (used for pagination)")
+	repoListCmd.Flags().Bool("include-archived", false, "list archived repositories as well")
 
 	repoCmd.AddCommand(repoListCmd)
 }
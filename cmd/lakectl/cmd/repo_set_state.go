@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api/apigen"
+	"golang.org/x/exp/slices"
+)
+
+var repositoryAccessStates = []string{"active", "read-only", "archived"}
+
+// repoSetStateCmd sets the access state of a repository
+// lakectl repo set-state lakefs://myrepo read-only
+var repoSetStateCmd = &cobra.Command{
+	Use:   "set-state <repository uri> active|read-only|archived",
+	Short: "Set the access state of a repository",
+	Long: `Set the access state of a repository.
+Read-only and archived repositories reject all changes to objects, branches, tags and commits.
+Archived repositories are also hidden from repository listings and skipped by garbage collection.`,
+	Example: "lakectl repo set-state lakefs://my-repo archived",
+	Args:    cobra.ExactArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 1 {
+			return repositoryAccessStates, cobra.ShellCompDirectiveNoFileComp
+		}
+		return ValidArgsRepository(cmd, args, toComplete)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		u := MustParseRepoURI("repository", args[0])
+		state := args[1]
+		if !slices.Contains(repositoryAccessStates, state) {
+			DieFmt("Invalid state '%s', expected one of: active, read-only, archived", state)
+		}
+		clt := getClient()
+		resp, err := clt.SetRepositoryAccessStateWithResponse(cmd.Context(), u.Repository, apigen.SetRepositoryAccessStateJSONRequestBody{
+			State: state,
+		})
+		DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusNoContent)
+		fmt.Printf("Repository '%s' is %s\n", u.Repository, state)
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	repoCmd.AddCommand(repoSetStateCmd)
+}
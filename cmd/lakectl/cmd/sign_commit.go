@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/treeverse/lakefs/pkg/api/apigen"
+	"github.com/treeverse/lakefs/pkg/signature"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+const (
+	signingKeyFlagName    = "signing-key"
+	signingKeyDescription = "path of an SSH private key registered to the committer, that signs the commit"
+	commitSignedTemplate  = `Commit {{ .CommitID|yellow }} signed by key {{ .Signature.KeyFingerprint|bold }}
+`
+)
+
+var signCommitCmd = &cobra.Command{
+	Use:   "sign-commit <ref uri>",
+	Short: "Sign a commit with an SSH key",
+	Long: `Sign a commit with an SSH private key.  The matching public key must be registered to the committer using
+"lakectl auth users public-keys add".  A commit is signed at most once.`,
+	Example:           "lakectl sign-commit --signing-key ~/.ssh/id_ed25519 lakefs://example-repo/main",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: ValidArgsRepository,
+	Run: func(cmd *cobra.Command, args []string) {
+		refURI := MustParseRefURI("ref uri", args[0])
+		keyPath := Must(cmd.Flags().GetString(signingKeyFlagName))
+		signer := loadSigningKey(keyPath)
+		ctx := cmd.Context()
+		client := getClient()
+
+		commitID := resolveCommitOrDie(ctx, client, refURI.Repository, refURI.Ref)
+		signCommit(ctx, client, signer, refURI.Repository, commitID)
+	},
+}
+
+// loadSigningKey reads the SSH private key at keyPath, asking for its passphrase if it is encrypted
+func loadSigningKey(keyPath string) ssh.Signer {
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		DieErr(err)
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	var missingPassphrase *ssh.PassphraseMissingError
+	if errors.As(err, &missingPassphrase) {
+		fmt.Fprintf(os.Stderr, "Enter passphrase for %s: ", keyPath)
+		passphrase, readErr := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if readErr != nil {
+			DieErr(readErr)
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, passphrase)
+	}
+	if err != nil {
+		DieFmt("Signing key %s: %s", keyPath, err)
+	}
+	return signer
+}
+
+// signCommit signs commitID of repository with signer, and prints the signature
+func signCommit(ctx context.Context, client apigen.ClientWithResponsesInterface, signer ssh.Signer, repository, commitID string) {
+	sig, err := signature.SignCommit(signer, commitID)
+	if err != nil {
+		DieErr(err)
+	}
+	resp, err := client.SignCommitWithResponse(ctx, repository, commitID, apigen.SignCommitJSONRequestBody{
+		Signature: sig,
+	})
+	DieOnErrorOrUnexpectedStatusCode(resp, err, http.StatusCreated)
+	if resp.JSON201 == nil {
+		Die("Bad response from server", 1)
+	}
+	Write(commitSignedTemplate, struct {
+		CommitID  string
+		Signature *apigen.CommitSignatureStatus
+	}{CommitID: commitID, Signature: resp.JSON201})
+}
+
+//nolint:gochecknoinits
+func init() {
+	signCommitCmd.Flags().String(signingKeyFlagName, "", signingKeyDescription)
+	_ = signCommitCmd.MarkFlagRequired(signingKeyFlagName)
+
+	rootCmd.AddCommand(signCommitCmd)
+}
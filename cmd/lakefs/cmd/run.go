@@ -401,12 +401,11 @@ func checkRepos(ctx context.Context, logger logging.Logger, authMetadataManager
 		for hasMore {
 			var err error
 			var repos []*catalog.Repository
-			repos, hasMore, err = c.ListRepositories(ctx, -1, "", next)
+			repos, hasMore, err = c.ListRepositories(ctx, -1, "", next, true)
 			if err != nil {
 				logger.WithError(err).Fatal("Checking existing repositories failed")
 			}
 
-			adapterStorageType := blockStore.BlockstoreType()
 			for _, repo := range repos {
 				nsURL, err := url.Parse(repo.StorageNamespace)
 				if err != nil {
@@ -417,6 +416,7 @@ func checkRepos(ctx context.Context, logger logging.Logger, authMetadataManager
 					logger.WithError(err).Fatalf("Failed to parse to parse storage type '%s'", nsURL)
 				}
 
+				adapterStorageType := block.AdapterForNamespace(blockStore, repo.StorageNamespace).BlockstoreType()
 				checkForeignRepo(repoStorageType, logger, adapterStorageType, repo.Name)
 				next = repo.Name
 			}
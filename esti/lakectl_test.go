@@ -3,9 +3,14 @@ package esti
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/api/apigen"
 )
 
 var emptyVars = make(map[string]string)
@@ -450,6 +455,102 @@ func TestLakectlFsDownload(t *testing.T) {
 	})
 }
 
+func TestLakectlFsCopyMove(t *testing.T) {
+	repoName := generateUniqueRepositoryName()
+	storage := generateUniqueStorageNamespace(repoName)
+	vars := map[string]string{
+		"REPO":    repoName,
+		"STORAGE": storage,
+		"BRANCH":  mainBranch,
+	}
+	RunCmdAndVerifySuccessWithFile(t, Lakectl()+" repo create lakefs://"+repoName+" "+storage, false, "lakectl_repo_create", vars)
+
+	// upload some data
+	const totalObjects = 3
+	for i := 0; i < totalObjects; i++ {
+		vars["FILE_PATH"] = fmt.Sprintf("data/ro/ro_1k.%d", i)
+		RunCmdAndVerifySuccessWithFile(t, Lakectl()+" fs upload -s files/ro_1k lakefs://"+repoName+"/"+mainBranch+"/"+vars["FILE_PATH"], false, "lakectl_fs_upload", vars)
+	}
+	branchURI := "lakefs://" + repoName + "/" + mainBranch + "/"
+
+	t.Run("copy_single", func(t *testing.T) {
+		RunCmdAndVerifySuccess(t, Lakectl()+" fs cp "+branchURI+"data/ro/ro_1k.0 "+branchURI+"copy/", false, "", vars)
+		RunCmdAndVerifyContainsText(t, Lakectl()+" fs stat "+branchURI+"copy/ro_1k.0", false, "Path: copy/ro_1k.0", vars)
+	})
+
+	t.Run("copy_recursive", func(t *testing.T) {
+		RunCmdAndVerifySuccess(t, Lakectl()+" fs cp --recursive --no-progress "+branchURI+"data "+branchURI+"copy-all", false, "", vars)
+		for i := 0; i < totalObjects; i++ {
+			objPath := fmt.Sprintf("copy-all/ro/ro_1k.%d", i)
+			RunCmdAndVerifyContainsText(t, Lakectl()+" fs stat "+branchURI+objPath, false, "Path: "+objPath, vars)
+		}
+	})
+
+	t.Run("move_recursive", func(t *testing.T) {
+		RunCmdAndVerifySuccess(t, Lakectl()+" fs mv --recursive --no-progress "+branchURI+"copy-all/ "+branchURI+"moved/", false, "", vars)
+		for i := 0; i < totalObjects; i++ {
+			objPath := fmt.Sprintf("moved/ro/ro_1k.%d", i)
+			RunCmdAndVerifyContainsText(t, Lakectl()+" fs stat "+branchURI+objPath, false, "Path: "+objPath, vars)
+			runCmd(t, Lakectl()+" fs stat "+branchURI+fmt.Sprintf("copy-all/ro/ro_1k.%d", i), true, false, vars)
+		}
+	})
+}
+
+func TestLakectlFsSync(t *testing.T) {
+	repoName := generateUniqueRepositoryName()
+	storage := generateUniqueStorageNamespace(repoName)
+	vars := map[string]string{
+		"REPO":    repoName,
+		"STORAGE": storage,
+		"BRANCH":  mainBranch,
+	}
+	RunCmdAndVerifySuccessWithFile(t, Lakectl()+" repo create lakefs://"+repoName+" "+storage, false, "lakectl_repo_create", vars)
+
+	src := t.TempDir()
+	const totalObjects = 3
+	for i := 0; i < totalObjects; i++ {
+		err := os.MkdirAll(filepath.Join(src, "data"), os.ModePerm)
+		require.NoError(t, err)
+		err = os.WriteFile(filepath.Join(src, "data", fmt.Sprintf("file.%d", i)), []byte(fmt.Sprintf("content %d", i)), os.ModePerm)
+		require.NoError(t, err)
+	}
+	branchURI := "lakefs://" + repoName + "/" + mainBranch + "/"
+
+	t.Run("upload", func(t *testing.T) {
+		RunCmdAndVerifyContainsText(t, Lakectl()+" fs sync --dry-run "+src+" "+branchURI+"synced", false, "3 changes (dry run)", vars)
+		RunCmdAndVerifyContainsText(t, Lakectl()+" fs sync "+src+" "+branchURI+"synced", false, "Successfully synced changes!", vars)
+		RunCmdAndVerifyContainsText(t, Lakectl()+" fs sync --checksum --dry-run "+src+" "+branchURI+"synced", false, "No changes (dry run)", vars)
+	})
+
+	t.Run("destination not a branch", func(t *testing.T) {
+		out := runCmd(t, Lakectl()+" fs sync "+branchURI+"synced lakefs://"+repoName+"/"+mainBranch+"~1/copied", true, false, vars)
+		require.Contains(t, out, "is not a branch")
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		RunCmdAndVerifyContainsText(t, Lakectl()+" fs sync --exclude '**.2' "+branchURI+"synced "+branchURI+"copied", false, "Successfully synced changes!", vars)
+		RunCmdAndVerifyContainsText(t, Lakectl()+" fs stat "+branchURI+"copied/data/file.0", false, "Path: copied/data/file.0", vars)
+		runCmd(t, Lakectl()+" fs stat "+branchURI+"copied/data/file.2", true, false, vars)
+	})
+
+	t.Run("download", func(t *testing.T) {
+		dest := t.TempDir()
+		RunCmdAndVerifyContainsText(t, Lakectl()+" fs sync "+branchURI+"synced "+dest, false, "Successfully synced changes!", vars)
+		for i := 0; i < totalObjects; i++ {
+			content, err := os.ReadFile(filepath.Join(dest, "data", fmt.Sprintf("file.%d", i)))
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprintf("content %d", i), string(content))
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		err := os.Remove(filepath.Join(src, "data", "file.0"))
+		require.NoError(t, err)
+		RunCmdAndVerifyContainsText(t, Lakectl()+" fs sync --delete "+src+" "+branchURI+"synced", false, "Successfully synced changes!", vars)
+		runCmd(t, Lakectl()+" fs stat "+branchURI+"synced/data/file.0", true, false, vars)
+	})
+}
+
 func TestLakectlFsStat(t *testing.T) {
 	repoName := generateUniqueRepositoryName()
 	storage := generateUniqueStorageNamespace(repoName)
@@ -478,7 +579,7 @@ func TestLakectlFsStat(t *testing.T) {
 	})
 
 	t.Run("pre-sign", func(t *testing.T) {
-		storageResp, err := client.GetStorageConfigWithResponse(context.Background())
+		storageResp, err := client.GetStorageConfigWithResponse(context.Background(), &apigen.GetStorageConfigParams{})
 		if err != nil {
 			t.Fatalf("GetStorageConfig failed: %s", err)
 		}
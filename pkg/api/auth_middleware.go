@@ -17,6 +17,7 @@ import (
 	"github.com/treeverse/lakefs/pkg/auth"
 	"github.com/treeverse/lakefs/pkg/auth/model"
 	oidc_encoding "github.com/treeverse/lakefs/pkg/auth/oidc/encoding"
+	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/logging"
 )
 
@@ -73,6 +74,7 @@ func GenericAuthMiddleware(logger logging.Logger, authenticator auth.Authenticat
 			}
 			if user != nil {
 				ctx := logging.AddFields(r.Context(), logging.Fields{logging.UserFieldKey: user.Username})
+				ctx = graveler.WithReflogUser(ctx, user.Username)
 				r = r.WithContext(auth.WithUser(ctx, user))
 			}
 			next.ServeHTTP(w, r)
@@ -104,6 +106,7 @@ func AuthMiddleware(logger logging.Logger, swagger *openapi3.Swagger, authentica
 			}
 			if user != nil {
 				ctx := logging.AddFields(r.Context(), logging.Fields{logging.UserFieldKey: user.Username})
+				ctx = graveler.WithReflogUser(ctx, user.Username)
 				r = r.WithContext(auth.WithUser(ctx, user))
 			}
 			next.ServeHTTP(w, r)
@@ -26,12 +26,14 @@ import (
 	"github.com/treeverse/lakefs/pkg/actions"
 	"github.com/treeverse/lakefs/pkg/api/apigen"
 	"github.com/treeverse/lakefs/pkg/api/apiutil"
+	"github.com/treeverse/lakefs/pkg/api/helpers"
 	"github.com/treeverse/lakefs/pkg/auth"
 	"github.com/treeverse/lakefs/pkg/auth/acl"
 	"github.com/treeverse/lakefs/pkg/auth/email"
 	"github.com/treeverse/lakefs/pkg/auth/model"
 	"github.com/treeverse/lakefs/pkg/auth/setup"
 	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/compress"
 	"github.com/treeverse/lakefs/pkg/catalog"
 	"github.com/treeverse/lakefs/pkg/cloud"
 	"github.com/treeverse/lakefs/pkg/config"
@@ -43,6 +45,7 @@ import (
 	"github.com/treeverse/lakefs/pkg/permissions"
 	tablediff "github.com/treeverse/lakefs/pkg/plugins/diff"
 	"github.com/treeverse/lakefs/pkg/samplerepo"
+	"github.com/treeverse/lakefs/pkg/signature"
 	"github.com/treeverse/lakefs/pkg/stats"
 	"github.com/treeverse/lakefs/pkg/templater"
 	"github.com/treeverse/lakefs/pkg/upload"
@@ -320,12 +323,20 @@ func (c *Controller) GetPhysicalAddress(w http.ResponseWriter, r *http.Request,
 	}
 
 	if swag.BoolValue(params.Presign) {
+		codec, err := c.Catalog.GetRepositoryCompression(ctx, repo.Name)
+		if c.handleAPIError(ctx, w, r, err) {
+			return
+		}
 		// generate a pre-signed PUT url for the given request
-		preSignedURL, expiry, err := c.BlockAdapter.GetPreSignedURL(ctx, block.ObjectPointer{
+		preSignedURL, expiry, err := c.BlockAdapter.GetPreSignedURL(compress.WithCodec(ctx, codec), block.ObjectPointer{
 			StorageNamespace: repo.StorageNamespace,
 			Identifier:       address,
 			IdentifierType:   block.IdentifierTypeRelative,
 		}, block.PreSignModeWrite)
+		if errors.Is(err, block.ErrOperationNotSupported) {
+			writeError(w, r, http.StatusBadRequest, err)
+			return
+		}
 		if err != nil {
 			writeError(w, r, http.StatusInternalServerError, err)
 			return
@@ -368,7 +379,7 @@ func (c *Controller) LinkPhysicalAddress(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	blockStoreType := c.BlockAdapter.BlockstoreType()
+	blockStoreType := block.AdapterForNamespace(c.BlockAdapter, repo.StorageNamespace).BlockstoreType()
 	expectedType := qk.GetStorageType().BlockstoreType()
 	if expectedType != blockStoreType {
 		c.Logger.WithContext(ctx).WithFields(logging.Fields{
@@ -425,6 +436,233 @@ func (c *Controller) LinkPhysicalAddress(w http.ResponseWriter, r *http.Request,
 	writeResponse(w, r, http.StatusOK, response)
 }
 
+func (c *Controller) CreateMultipartUpload(w http.ResponseWriter, r *http.Request, repository, branch string, params apigen.CreateMultipartUploadParams) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.WriteObjectAction,
+			Resource: permissions.ObjectArn(repository, params.Path),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "create_multipart_upload", r, repository, branch, "")
+
+	repo, err := c.Catalog.GetRepository(ctx, repository)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	// fail early if the branch does not exist
+	_, err = c.Catalog.GetStagingToken(ctx, repository, branch)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+
+	address := c.PathProvider.NewPath()
+	qk, err := c.BlockAdapter.ResolveNamespace(repo.StorageNamespace, address, block.IdentifierTypeRelative)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	err = c.Catalog.SetLinkAddress(ctx, repository, address)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	codec, err := c.Catalog.GetRepositoryCompression(ctx, repo.Name)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	ctx = compress.WithCodec(ctx, codec)
+	resp, err := c.BlockAdapter.CreateMultiPartUpload(ctx, block.ObjectPointer{
+		StorageNamespace: repo.StorageNamespace,
+		Identifier:       address,
+		IdentifierType:   block.IdentifierTypeRelative,
+	}, r, block.CreateMultiPartUploadOpts{})
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusCreated, apigen.MultipartUpload{
+		UploadId:        resp.UploadID,
+		PhysicalAddress: qk.Format(),
+	})
+}
+
+// multipartUploadObject returns the repository of a multipart upload to physicalAddress, and a pointer to the object
+// it uploads.  Only addresses in the storage namespace of the repository, as returned by CreateMultipartUpload, are
+// accepted.
+func (c *Controller) multipartUploadObject(ctx context.Context, repository, physicalAddress string) (*catalog.Repository, block.ObjectPointer, error) {
+	repo, err := c.Catalog.GetRepository(ctx, repository)
+	if err != nil {
+		return nil, block.ObjectPointer{}, err
+	}
+	address, addressType := normalizePhysicalAddress(repo.StorageNamespace, physicalAddress)
+	if addressType != catalog.AddressTypeRelative {
+		return nil, block.ObjectPointer{}, fmt.Errorf("physical address outside the storage namespace: %w", block.ErrInvalidAddress)
+	}
+	return repo, block.ObjectPointer{
+		StorageNamespace: repo.StorageNamespace,
+		Identifier:       address,
+		IdentifierType:   block.IdentifierTypeRelative,
+	}, nil
+}
+
+func (c *Controller) UploadMultipartPart(w http.ResponseWriter, r *http.Request, repository, branch, uploadID string, partNumber int, params apigen.UploadMultipartPartParams) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.WriteObjectAction,
+			Resource: permissions.ObjectArn(repository, params.Path),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "upload_multipart_part", r, repository, branch, "")
+
+	repo, obj, err := c.multipartUploadObject(ctx, repository, params.PhysicalAddress)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	codec, err := c.Catalog.GetRepositoryCompression(ctx, repo.Name)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	ctx = compress.WithCodec(ctx, codec)
+	resp, err := c.BlockAdapter.UploadPart(ctx, obj, r.ContentLength, r.Body, uploadID, partNumber)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusOK, apigen.MultipartUploadPart{
+		PartNumber: partNumber,
+		Etag:       resp.ETag,
+	})
+}
+
+func (c *Controller) ListMultipartUploadParts(w http.ResponseWriter, r *http.Request, repository, branch, uploadID string, params apigen.ListMultipartUploadPartsParams) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.WriteObjectAction,
+			Resource: permissions.ObjectArn(repository, params.Path),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "list_multipart_upload_parts", r, repository, branch, "")
+
+	_, obj, err := c.multipartUploadObject(ctx, repository, params.PhysicalAddress)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	parts, err := c.BlockAdapter.ListParts(ctx, obj, uploadID)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	response := apigen.MultipartUploadPartList{
+		Parts: make([]apigen.MultipartUploadPart, 0, len(parts)),
+	}
+	for _, part := range parts {
+		response.Parts = append(response.Parts, apigen.MultipartUploadPart{
+			PartNumber: part.PartNumber,
+			Etag:       part.ETag,
+		})
+	}
+	writeResponse(w, r, http.StatusOK, response)
+}
+
+func (c *Controller) CompleteMultipartUpload(w http.ResponseWriter, r *http.Request, body apigen.CompleteMultipartUploadJSONRequestBody, repository, branch, uploadID string, params apigen.CompleteMultipartUploadParams) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.WriteObjectAction,
+			Resource: permissions.ObjectArn(repository, params.Path),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "complete_multipart_upload", r, repository, branch, "")
+
+	repo, obj, err := c.multipartUploadObject(ctx, repository, params.PhysicalAddress)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	// validate token
+	err = c.Catalog.VerifyLinkAddress(ctx, repository, obj.Identifier)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	multipartList := &block.MultipartUploadCompletion{
+		Part: make([]block.MultipartPart, 0, len(body.Parts)),
+	}
+	for _, part := range body.Parts {
+		multipartList.Part = append(multipartList.Part, block.MultipartPart{
+			ETag:       part.Etag,
+			PartNumber: part.PartNumber,
+		})
+	}
+	resp, err := c.BlockAdapter.CompleteMultiPartUpload(ctx, obj, uploadID, multipartList)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	codec, err := c.Catalog.GetRepositoryCompression(ctx, repo.Name)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	var userMetadata map[string]string
+	if body.UserMetadata != nil {
+		userMetadata = body.UserMetadata.AdditionalProperties
+	}
+	entry := catalog.NewDBEntryBuilder().
+		CommonLevel(false).
+		Path(params.Path).
+		PhysicalAddress(obj.Identifier).
+		AddressType(catalog.AddressTypeRelative).
+		CreationDate(time.Now()).
+		Size(resp.ContentLength).
+		Checksum(strings.Split(resp.ETag, "-")[0]).
+		ContentType(apiutil.Value(body.ContentType)).
+		Metadata(compress.MultipartEntryMetadata(compress.WithCodec(ctx, codec), c.BlockAdapter, uploadID, userMetadata, resp.ContentLength)).
+		Build()
+	err = c.Catalog.CreateEntry(ctx, repo.Name, branch, entry)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+
+	metadata := apigen.ObjectUserMetadata{AdditionalProperties: entry.Metadata}
+	writeResponse(w, r, http.StatusOK, apigen.ObjectStats{
+		Checksum:        entry.Checksum,
+		ContentType:     &entry.ContentType,
+		Metadata:        &metadata,
+		Mtime:           entry.CreationDate.Unix(),
+		Path:            entry.Path,
+		PathType:        entryTypeObject,
+		PhysicalAddress: entry.PhysicalAddress,
+		SizeBytes:       apiutil.Ptr(entry.Size),
+	})
+}
+
+func (c *Controller) AbortMultipartUpload(w http.ResponseWriter, r *http.Request, repository, branch, uploadID string, params apigen.AbortMultipartUploadParams) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.WriteObjectAction,
+			Resource: permissions.ObjectArn(repository, params.Path),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "abort_multipart_upload", r, repository, branch, "")
+
+	_, obj, err := c.multipartUploadObject(ctx, repository, params.PhysicalAddress)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	err = c.BlockAdapter.AbortMultiPartUpload(ctx, obj, uploadID)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusNoContent, nil)
+}
+
 // normalizePhysicalAddress return relative address based on storage namespace if possible. If address doesn't match
 // the storage namespace prefix, the return address type is full.
 func normalizePhysicalAddress(storageNamespace, physicalAddress string) (string, catalog.AddressType) {
@@ -629,6 +867,9 @@ func (c *Controller) GetGroupACL(w http.ResponseWriter, r *http.Request, groupID
 	response := apigen.ACL{
 		Permission: string(groupACL.Permission),
 	}
+	if len(groupACL.Repositories) > 0 {
+		response.Repositories = &groupACL.Repositories
+	}
 
 	writeResponse(w, r, http.StatusOK, response)
 }
@@ -667,6 +908,9 @@ func (c *Controller) SetGroupACL(w http.ResponseWriter, r *http.Request, body ap
 	newACL := model.ACL{
 		Permission: model.ACLPermission(body.Permission),
 	}
+	if body.Repositories != nil {
+		newACL.Repositories = *body.Repositories
+	}
 
 	err := acl.WriteGroupACL(ctx, c.Auth, groupID, newACL, time.Now(), true)
 	if c.handleAPIError(ctx, w, r, err) {
@@ -1300,6 +1544,93 @@ func (c *Controller) GetCredentials(w http.ResponseWriter, r *http.Request, user
 	writeResponse(w, r, http.StatusOK, response)
 }
 
+func (c *Controller) ListUserPublicKeys(w http.ResponseWriter, r *http.Request, userID string, params apigen.ListUserPublicKeysParams) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.ListCredentialsAction,
+			Resource: permissions.UserArn(userID),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "list_user_public_keys", r, "", "", "")
+	publicKeys, paginator, err := c.Auth.ListUserPublicKeys(ctx, userID, &model.PaginationParams{
+		After:  paginationAfter(params.After),
+		Prefix: paginationPrefix(params.Prefix),
+		Amount: paginationAmount(params.Amount),
+	})
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+
+	response := apigen.PublicKeyList{
+		Results: make([]apigen.PublicKey, 0, len(publicKeys)),
+		Pagination: apigen.Pagination{
+			HasMore:    paginator.NextPageToken != "",
+			NextOffset: paginator.NextPageToken,
+			Results:    paginator.Amount,
+		},
+	}
+	for _, k := range publicKeys {
+		response.Results = append(response.Results, apigen.PublicKey{
+			Fingerprint:  k.Fingerprint,
+			PublicKey:    k.PublicKey,
+			CreationDate: k.CreatedAt.Unix(),
+		})
+	}
+	writeResponse(w, r, http.StatusOK, response)
+}
+
+func (c *Controller) AddUserPublicKey(w http.ResponseWriter, r *http.Request, body apigen.AddUserPublicKeyJSONRequestBody, userID string) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.CreateCredentialsAction,
+			Resource: permissions.UserArn(userID),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "add_user_public_key", r, "", "", "")
+	publicKey, err := c.Auth.AddUserPublicKey(ctx, userID, body.PublicKey)
+	if errors.Is(err, auth.ErrInvalidRequest) {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	response := apigen.PublicKey{
+		Fingerprint:  publicKey.Fingerprint,
+		PublicKey:    publicKey.PublicKey,
+		CreationDate: publicKey.CreatedAt.Unix(),
+	}
+	writeResponse(w, r, http.StatusCreated, response)
+}
+
+func (c *Controller) DeleteUserPublicKey(w http.ResponseWriter, r *http.Request, userID string, params apigen.DeleteUserPublicKeyParams) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.DeleteCredentialsAction,
+			Resource: permissions.UserArn(userID),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "delete_user_public_key", r, "", "", "")
+	err := c.Auth.DeleteUserPublicKey(ctx, userID, params.Fingerprint)
+	if errors.Is(err, auth.ErrNotFound) {
+		writeError(w, r, http.StatusNotFound, "public key not found")
+		return
+	}
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusNoContent, nil)
+}
+
 func (c *Controller) ListUserGroups(w http.ResponseWriter, r *http.Request, userID string, params apigen.ListUserGroupsParams) {
 	if !c.authorize(w, r, permissions.Node{
 		Permission: permissions.Permission{
@@ -1428,22 +1759,58 @@ func (c *Controller) AttachPolicyToUser(w http.ResponseWriter, r *http.Request,
 	writeResponse(w, r, http.StatusCreated, nil)
 }
 
-func (c *Controller) GetStorageConfig(w http.ResponseWriter, r *http.Request) {
-	if !c.authorize(w, r, permissions.Node{
+func (c *Controller) GetStorageConfig(w http.ResponseWriter, r *http.Request, params apigen.GetStorageConfigParams) {
+	node := permissions.Node{
 		Permission: permissions.Permission{
 			Action:   permissions.ReadConfigAction,
 			Resource: permissions.All,
 		},
-	}) {
+	}
+	if params.Repository != nil {
+		node = permissions.Node{
+			Type: permissions.NodeTypeAnd,
+			Nodes: []permissions.Node{
+				node,
+				{
+					Permission: permissions.Permission{
+						Action:   permissions.ReadRepositoryAction,
+						Resource: permissions.RepoArn(*params.Repository),
+					},
+				},
+			},
+		}
+	}
+	if !c.authorize(w, r, node) {
 		return
 	}
-	info := c.BlockAdapter.GetStorageNamespaceInfo()
+	ctx := r.Context()
+	adapter := c.BlockAdapter
+	blockstoreType := c.Config.Blockstore.Type
+	codec := compress.CodecNone
+	if params.Repository != nil {
+		repo, err := c.Catalog.GetRepository(ctx, *params.Repository)
+		if c.handleAPIError(ctx, w, r, err) {
+			return
+		}
+		adapter = block.AdapterForNamespace(c.BlockAdapter, repo.StorageNamespace)
+		blockstoreType = adapter.BlockstoreType()
+		codec, err = c.Catalog.GetRepositoryCompression(ctx, repo.Name)
+		if c.handleAPIError(ctx, w, r, err) {
+			return
+		}
+	}
+	info := adapter.GetStorageNamespaceInfo()
+	if codec != compress.CodecNone {
+		// clients would read and write compressed data through pre-signed URLs
+		info.PreSignSupport = false
+		info.PreSignSupportUI = false
+	}
 	defaultNamespacePrefix := swag.String(info.DefaultNamespacePrefix)
 	if c.Config.Blockstore.DefaultNamespacePrefix != nil {
 		defaultNamespacePrefix = c.Config.Blockstore.DefaultNamespacePrefix
 	}
 	response := apigen.StorageConfig{
-		BlockstoreType:                   c.Config.Blockstore.Type,
+		BlockstoreType:                   blockstoreType,
 		BlockstoreNamespaceValidityRegex: info.ValidityRegex,
 		BlockstoreNamespaceExample:       info.Example,
 		DefaultNamespacePrefix:           defaultNamespacePrefix,
@@ -1471,7 +1838,18 @@ func (c *Controller) ListRepositories(w http.ResponseWriter, r *http.Request, pa
 	ctx := r.Context()
 	c.LogAction(ctx, "list_repos", r, "", "", "")
 
-	repos, hasMore, err := c.Catalog.ListRepositories(ctx, paginationAmount(params.Amount), paginationPrefix(params.Prefix), paginationAfter(params.After))
+	var (
+		repos   []*catalog.Repository
+		hasMore bool
+		err     error
+	)
+	includeArchived := swag.BoolValue(params.IncludeArchived)
+	if c.Config.IsAuthUISimplified() {
+		// ACLs may be limited to some repositories: hide the others
+		repos, hasMore, err = c.listReadableRepositories(ctx, paginationAmount(params.Amount), paginationPrefix(params.Prefix), paginationAfter(params.After), includeArchived)
+	} else {
+		repos, hasMore, err = c.Catalog.ListRepositories(ctx, paginationAmount(params.Amount), paginationPrefix(params.Prefix), paginationAfter(params.After), includeArchived)
+	}
 	if c.handleAPIError(ctx, w, r, err) {
 		return
 	}
@@ -1493,23 +1871,96 @@ func (c *Controller) ListRepositories(w http.ResponseWriter, r *http.Request, pa
 	writeResponse(w, r, http.StatusOK, repositoryList)
 }
 
+// listReadableRepositories returns a page of up to limit repositories
+// that the current user may read, and whether more readable repositories
+// follow.
+func (c *Controller) listReadableRepositories(ctx context.Context, limit int, prefix, after string, includeArchived bool) ([]*catalog.Repository, bool, error) {
+	user, err := auth.GetUser(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	// collect limit+1 readable repositories to tell whether more follow
+	readable := make([]*catalog.Repository, 0, limit+1)
+	for {
+		repos, hasMore, err := c.Catalog.ListRepositories(ctx, limit, prefix, after, includeArchived)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, repo := range repos {
+			resp, err := c.Auth.Authorize(ctx, &auth.AuthorizationRequest{
+				Username: user.Username,
+				RequiredPermissions: permissions.Node{
+					Permission: permissions.Permission{
+						Action:   permissions.ReadRepositoryAction,
+						Resource: permissions.RepoArn(repo.Name),
+					},
+				},
+			})
+			if err != nil {
+				return nil, false, err
+			}
+			if !resp.Allowed {
+				continue
+			}
+			readable = append(readable, repo)
+			if len(readable) > limit {
+				return readable[:limit], true, nil
+			}
+		}
+		if !hasMore || len(repos) == 0 {
+			return readable, false, nil
+		}
+		after = repos[len(repos)-1].Name
+	}
+}
+
 func (c *Controller) CreateRepository(w http.ResponseWriter, r *http.Request, body apigen.CreateRepositoryJSONRequestBody, params apigen.CreateRepositoryParams) {
-	if !c.authorize(w, r, permissions.Node{
-		Type: permissions.NodeTypeAnd,
-		Nodes: []permissions.Node{
-			{
+	nodes := []permissions.Node{
+		{
+			Permission: permissions.Permission{
+				Action:   permissions.CreateRepositoryAction,
+				Resource: permissions.RepoArn(body.Name),
+			},
+		},
+		{
+			Permission: permissions.Permission{
+				Action:   permissions.AttachStorageNamespaceAction,
+				Resource: permissions.StorageNamespace(body.StorageNamespace),
+			},
+		},
+	}
+	if body.Template != nil {
+		nodes = append(nodes,
+			permissions.Node{
 				Permission: permissions.Permission{
-					Action:   permissions.CreateRepositoryAction,
-					Resource: permissions.RepoArn(body.Name),
+					Action:   permissions.ListObjectsAction,
+					Resource: permissions.RepoArn(body.Template.Repository),
 				},
 			},
-			{
+			// the new repository holds or shares all objects of the template
+			permissions.Node{
 				Permission: permissions.Permission{
-					Action:   permissions.AttachStorageNamespaceAction,
-					Resource: permissions.StorageNamespace(body.StorageNamespace),
+					Action:   permissions.ReadObjectAction,
+					Resource: permissions.ObjectArn(body.Template.Repository, "*"),
 				},
 			},
-		},
+			permissions.Node{
+				Permission: permissions.Permission{
+					Action:   permissions.GetBranchProtectionRulesAction,
+					Resource: permissions.RepoArn(body.Template.Repository),
+				},
+			},
+			permissions.Node{
+				Permission: permissions.Permission{
+					Action:   permissions.GetGarbageCollectionRulesAction,
+					Resource: permissions.RepoArn(body.Template.Repository),
+				},
+			},
+		)
+	}
+	if !c.authorize(w, r, permissions.Node{
+		Type:  permissions.NodeTypeAnd,
+		Nodes: nodes,
 	}) {
 		return
 	}
@@ -1519,6 +1970,13 @@ func (c *Controller) CreateRepository(w http.ResponseWriter, r *http.Request, bo
 	if sampleData {
 		c.LogAction(ctx, "repo_sample_data", r, body.Name, "", "")
 	}
+	if body.Template != nil {
+		c.LogAction(ctx, "repo_from_template", r, body.Name, "", "")
+		if sampleData || swag.BoolValue(params.Bare) {
+			writeError(w, r, http.StatusBadRequest, "template cannot be combined with sample data or a bare repository")
+			return
+		}
+	}
 
 	if err := c.validateStorageNamespace(body.StorageNamespace); err != nil {
 		writeError(w, r, http.StatusBadRequest, err)
@@ -1530,6 +1988,12 @@ func (c *Controller) CreateRepository(w http.ResponseWriter, r *http.Request, bo
 		defaultBranch = "main"
 	}
 
+	codec, err := compress.ParseCodec(apiutil.Value(body.Compression))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
 	if swag.BoolValue(params.Bare) {
 		// create a bare repository. This is useful in conjunction with refs-restore to create a copy
 		// of another repository by e.g. copying the _lakefs/ directory and restoring its refs
@@ -1537,6 +2001,12 @@ func (c *Controller) CreateRepository(w http.ResponseWriter, r *http.Request, bo
 		if c.handleAPIError(ctx, w, r, err) {
 			return
 		}
+		if codec != compress.CodecNone {
+			err = c.Catalog.SetRepositoryCompression(ctx, repo.Name, codec)
+			if c.handleAPIError(ctx, w, r, err) {
+				return
+			}
+		}
 		response := apigen.Repository{
 			CreationDate:     repo.CreationDate.Unix(),
 			DefaultBranch:    repo.DefaultBranch,
@@ -1558,7 +2028,7 @@ func (c *Controller) CreateRepository(w http.ResponseWriter, r *http.Request, bo
 			retErr = err
 			reason = "bad_url"
 		case errors.Is(err, block.ErrInvalidAddress):
-			retErr = fmt.Errorf("%w, must match: %s", err, c.BlockAdapter.BlockstoreType())
+			retErr = fmt.Errorf("%w, must match: %s", err, block.AdapterForNamespace(c.BlockAdapter, body.StorageNamespace).BlockstoreType())
 			reason = "invalid_namespace"
 		case errors.Is(err, ErrStorageNamespaceInUse):
 			retErr = err
@@ -1576,10 +2046,31 @@ func (c *Controller) CreateRepository(w http.ResponseWriter, r *http.Request, bo
 		return
 	}
 
-	newRepo, err := c.Catalog.CreateRepository(ctx, body.Name, body.StorageNamespace, defaultBranch)
-	if err != nil {
-		c.handleAPIError(ctx, w, r, fmt.Errorf("error creating repository: %w", err))
-		return
+	var newRepo *catalog.Repository
+	if body.Template != nil {
+		user, err := auth.GetUser(ctx)
+		if err != nil {
+			writeError(w, r, http.StatusUnauthorized, "missing user")
+			return
+		}
+		newRepo, err = c.Catalog.CreateRepositoryFromTemplate(ctx, body.Name, body.StorageNamespace, defaultBranch, body.Template.Repository, body.Template.Ref, user.Username)
+		if err != nil {
+			c.handleAPIError(ctx, w, r, fmt.Errorf("error creating repository from template: %w", err))
+			return
+		}
+	} else {
+		newRepo, err = c.Catalog.CreateRepository(ctx, body.Name, body.StorageNamespace, defaultBranch)
+		if err != nil {
+			c.handleAPIError(ctx, w, r, fmt.Errorf("error creating repository: %w", err))
+			return
+		}
+	}
+	if codec != compress.CodecNone {
+		if err := c.Catalog.SetRepositoryCompression(ctx, newRepo.Name, codec); err != nil {
+			c.handleAPIError(ctx, w, r, fmt.Errorf("error setting repository compression: %w", err))
+			return
+		}
+		ctx = compress.WithCodec(ctx, codec)
 	}
 
 	if sampleData {
@@ -1613,7 +2104,7 @@ func (c *Controller) CreateRepository(w http.ResponseWriter, r *http.Request, bo
 }
 
 func (c *Controller) validateStorageNamespace(storageNamespace string) error {
-	validRegex := c.BlockAdapter.GetStorageNamespaceInfo().ValidityRegex
+	validRegex := block.AdapterForNamespace(c.BlockAdapter, storageNamespace).GetStorageNamespaceInfo().ValidityRegex
 	storagePrefixRegex, err := regexp.Compile(validRegex)
 	if err != nil {
 		return fmt.Errorf("failed to compile validity regex %s: %w", validRegex, block.ErrInvalidNamespace)
@@ -1744,6 +2235,46 @@ func (c *Controller) GetRepositoryMetadata(w http.ResponseWriter, r *http.Reques
 	writeResponse(w, r, http.StatusOK, apigen.RepositoryMetadata{AdditionalProperties: metadata})
 }
 
+func (c *Controller) GetRepositoryAccessState(w http.ResponseWriter, r *http.Request, repository string) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.ReadRepositoryAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "get_repo_access_state", r, repository, "", "")
+	state, err := c.Catalog.GetRepositoryAccessState(ctx, repository)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusOK, apigen.RepositoryAccessState{State: state.String()})
+}
+
+func (c *Controller) SetRepositoryAccessState(w http.ResponseWriter, r *http.Request, body apigen.SetRepositoryAccessStateJSONRequestBody, repository string) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.UpdateRepositoryStateAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "set_repo_access_state", r, repository, "", "")
+	state, err := graveler.ParseRepositoryAccessState(string(body.State))
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	err = c.Catalog.SetRepositoryAccessState(ctx, repository, state)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusNoContent, nil)
+}
+
 func (c *Controller) ListRepositoryRuns(w http.ResponseWriter, r *http.Request, repository string, params apigen.ListRepositoryRunsParams) {
 	if !c.authorize(w, r, permissions.Node{
 		Permission: permissions.Permission{
@@ -1996,6 +2527,44 @@ func (c *Controller) ListBranches(w http.ResponseWriter, r *http.Request, reposi
 	writeResponse(w, r, http.StatusOK, response)
 }
 
+func (c *Controller) ListBranchReflog(w http.ResponseWriter, r *http.Request, repository, branch string, params apigen.ListBranchReflogParams) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.ReadBranchAction,
+			Resource: permissions.BranchArn(repository, branch),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "list_branch_reflog", r, repository, branch, "")
+
+	res, hasMore, err := c.Catalog.ListBranchReflog(ctx, repository, branch, paginationAmount(params.Amount), paginationAfter(params.After))
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+
+	entries := make([]apigen.ReflogEntry, 0, len(res))
+	for _, entry := range res {
+		reflogEntry := apigen.ReflogEntry{
+			Id:           entry.ID,
+			OldCommitId:  entry.OldCommitID,
+			NewCommitId:  entry.NewCommitID,
+			Operation:    entry.Operation,
+			CreationDate: entry.CreationDate.Unix(),
+		}
+		if entry.User != "" {
+			reflogEntry.User = swag.String(entry.User)
+		}
+		entries = append(entries, reflogEntry)
+	}
+	response := apigen.ReflogEntryList{
+		Results:    entries,
+		Pagination: paginationFor(hasMore, entries, "Id"),
+	}
+	writeResponse(w, r, http.StatusOK, response)
+}
+
 func (c *Controller) CreateBranch(w http.ResponseWriter, r *http.Request, body apigen.CreateBranchJSONRequestBody, repository string) {
 	if !c.authorize(w, r, permissions.Node{
 		Permission: permissions.Permission{
@@ -2082,7 +2651,8 @@ func (c *Controller) handleAPIErrorCallback(ctx context.Context, w http.Response
 		cb(w, r, http.StatusNotFound, err)
 
 	case errors.Is(err, block.ErrForbidden),
-		errors.Is(err, graveler.ErrProtectedBranch):
+		errors.Is(err, graveler.ErrProtectedBranch),
+		errors.Is(err, graveler.ErrReadOnlyRepository):
 		cb(w, r, http.StatusForbidden, err)
 
 	case errors.Is(err, graveler.ErrDirtyBranch),
@@ -2100,6 +2670,7 @@ func (c *Controller) handleAPIErrorCallback(ctx context.Context, w http.Response
 		errors.Is(err, graveler.ErrParentOutOfRange),
 		errors.Is(err, graveler.ErrCherryPickMergeNoParent),
 		errors.Is(err, graveler.ErrInvalidMergeStrategy),
+		errors.Is(err, graveler.ErrNotAncestor),
 		errors.Is(err, block.ErrInvalidAddress),
 		errors.Is(err, block.ErrOperationNotSupported):
 		log.Debug("Bad request")
@@ -2107,7 +2678,8 @@ func (c *Controller) handleAPIErrorCallback(ctx context.Context, w http.Response
 
 	case errors.Is(err, graveler.ErrNotUnique),
 		errors.Is(err, graveler.ErrConflictFound),
-		errors.Is(err, graveler.ErrRevertMergeNoParent):
+		errors.Is(err, graveler.ErrRevertMergeNoParent),
+		errors.Is(err, graveler.ErrTransactionAborted):
 		log.Debug("Conflict")
 		cb(w, r, http.StatusConflict, err)
 
@@ -2123,6 +2695,9 @@ func (c *Controller) handleAPIErrorCallback(ctx context.Context, w http.Response
 		log.Debug("Already exists")
 		cb(w, r, http.StatusConflict, "Already exists")
 
+	case errors.Is(err, auth.ErrNotImplemented):
+		cb(w, r, http.StatusNotImplemented, "Not implemented")
+
 	case errors.Is(err, graveler.ErrTooManyTries):
 		log.Debug("Retried too many times")
 		cb(w, r, http.StatusLocked, "Too many attempts, try again later")
@@ -2207,6 +2782,12 @@ func (c *Controller) ImportStart(w http.ResponseWriter, r *http.Request, body ap
 				Action:   permissions.WriteObjectAction,
 				Resource: permissions.ObjectArn(repository, source.Destination),
 			}})
+		if source.InventoryManifest != nil {
+			perm.Nodes = append(perm.Nodes, permissions.Node{Permission: permissions.Permission{
+				Action:   permissions.ImportFromStorageAction,
+				Resource: permissions.StorageNamespace(*source.InventoryManifest),
+			}})
+		}
 	}
 	if !c.authorize(w, r, perm) {
 		return
@@ -2230,9 +2811,10 @@ func (c *Controller) ImportStart(w http.ResponseWriter, r *http.Request, body ap
 			return
 		}
 		paths = append(paths, catalog.ImportPath{
-			Destination: p.Destination,
-			Path:        p.Path,
-			Type:        pathType,
+			Destination:       p.Destination,
+			Path:              p.Path,
+			Type:              pathType,
+			InventoryManifest: swag.StringValue(p.InventoryManifest),
 		})
 	}
 
@@ -2464,19 +3046,81 @@ func (c *Controller) Commit(w http.ResponseWriter, r *http.Request, body apigen.
 	commitResponse(w, r, newCommit)
 }
 
-func commitResponse(w http.ResponseWriter, r *http.Request, newCommit *catalog.CommitLog) {
-	response := apigen.Commit{
-		Committer:    newCommit.Committer,
-		CreationDate: newCommit.CreationDate.Unix(),
-		Id:           newCommit.Reference,
-		Message:      newCommit.Message,
-		MetaRangeId:  newCommit.MetaRangeID,
-		Metadata:     &apigen.Commit_Metadata{AdditionalProperties: newCommit.Metadata},
-		Parents:      newCommit.Parents,
+func (c *Controller) CommitBranches(w http.ResponseWriter, r *http.Request, body apigen.CommitBranchesJSONRequestBody) {
+	nodes := make([]permissions.Node, 0, len(body.Commits))
+	for _, bc := range body.Commits {
+		nodes = append(nodes, permissions.Node{
+			Permission: permissions.Permission{
+				Action:   permissions.CreateCommitAction,
+				Resource: permissions.BranchArn(bc.Repository, bc.Branch),
+			},
+		})
+	}
+	if !c.authorize(w, r, permissions.Node{
+		Type:  permissions.NodeTypeAnd,
+		Nodes: nodes,
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "create_commits", r, "", "", "")
+	user, err := auth.GetUser(ctx)
+	if err != nil {
+		writeError(w, r, http.StatusUnauthorized, "missing user")
+		return
+	}
+	commits := make([]catalog.BranchCommit, 0, len(body.Commits))
+	for _, bc := range body.Commits {
+		var metadata map[string]string
+		if bc.Metadata != nil {
+			metadata = bc.Metadata.AdditionalProperties
+		}
+		commits = append(commits, catalog.BranchCommit{
+			Repository: bc.Repository,
+			Branch:     bc.Branch,
+			Message:    bc.Message,
+			Metadata:   metadata,
+			Date:       bc.Date,
+		})
+	}
+	newCommits, err := c.Catalog.CommitBranches(ctx, user.Username, commits)
+	var hookAbortErr *graveler.HookAbortError
+	if errors.As(err, &hookAbortErr) {
+		c.Logger.
+			WithError(err).
+			WithField("run_id", hookAbortErr.RunID).
+			Warn("aborted by hooks")
+		writeError(w, r, http.StatusPreconditionFailed, err)
+		return
+	}
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	response := apigen.BranchesCommitResult{
+		Results: make([]apigen.Commit, 0, len(newCommits)),
+	}
+	for _, newCommit := range newCommits {
+		response.Results = append(response.Results, commitFromCommitLog(newCommit))
 	}
 	writeResponse(w, r, http.StatusCreated, response)
 }
 
+func commitFromCommitLog(commit *catalog.CommitLog) apigen.Commit {
+	return apigen.Commit{
+		Committer:    commit.Committer,
+		CreationDate: commit.CreationDate.Unix(),
+		Id:           commit.Reference,
+		Message:      commit.Message,
+		MetaRangeId:  commit.MetaRangeID,
+		Metadata:     &apigen.Commit_Metadata{AdditionalProperties: commit.Metadata},
+		Parents:      commit.Parents,
+	}
+}
+
+func commitResponse(w http.ResponseWriter, r *http.Request, newCommit *catalog.CommitLog) {
+	writeResponse(w, r, http.StatusCreated, commitFromCommitLog(newCommit))
+}
+
 func (c *Controller) DiffBranch(w http.ResponseWriter, r *http.Request, repository, branch string, params apigen.DiffBranchParams) {
 	if !c.authorize(w, r, permissions.Node{
 		Permission: permissions.Permission{
@@ -2625,6 +3269,12 @@ func (c *Controller) UploadObject(w http.ResponseWriter, r *http.Request, reposi
 		return
 	}
 
+	codec, err := c.Catalog.GetRepositoryCompression(ctx, repo.Name)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	ctx = compress.WithCodec(ctx, codec)
+
 	reader := multipart.NewReader(r.Body, boundary)
 	var (
 		contentUploaded bool
@@ -2675,7 +3325,7 @@ func (c *Controller) UploadObject(w http.ResponseWriter, r *http.Request, reposi
 	} else {
 		entryBuilder.AddressType(catalog.AddressTypeFull)
 	}
-	meta := extractLakeFSMetadata(r.Header)
+	meta := compress.EntryMetadata(ctx, c.BlockAdapter, extractLakeFSMetadata(r.Header), blob.Size)
 	if len(meta) > 0 {
 		entryBuilder.Metadata(meta)
 	}
@@ -2736,10 +3386,11 @@ func (c *Controller) StageObject(w http.ResponseWriter, r *http.Request, body ap
 	}
 
 	// see what storage type this is and whether it fits our configuration
-	uriRegex := c.BlockAdapter.GetStorageNamespaceInfo().ValidityRegex
+	adapter := block.AdapterForNamespace(c.BlockAdapter, body.PhysicalAddress)
+	uriRegex := adapter.GetStorageNamespaceInfo().ValidityRegex
 	if match, err := regexp.MatchString(uriRegex, body.PhysicalAddress); err != nil || !match {
 		writeError(w, r, http.StatusBadRequest, fmt.Sprintf("physical address is not valid for block adapter: %s",
-			c.BlockAdapter.BlockstoreType(),
+			adapter.BlockstoreType(),
 		))
 		return
 	}
@@ -2785,13 +3436,18 @@ func (c *Controller) StageObject(w http.ResponseWriter, r *http.Request, body ap
 func (c *Controller) CopyObject(w http.ResponseWriter, r *http.Request, body apigen.CopyObjectJSONRequestBody, repository, branch string, params apigen.CopyObjectParams) {
 	srcPath := body.SrcPath
 	destPath := params.DestPath
+	// use destination repository as source if not specified
+	srcRepository := swag.StringValue(body.SrcRepository)
+	if srcRepository == "" {
+		srcRepository = repository
+	}
 	if !c.authorize(w, r, permissions.Node{
 		Type: permissions.NodeTypeAnd,
 		Nodes: []permissions.Node{
 			{
 				Permission: permissions.Permission{
-					Action:   permissions.ReadActionsAction,
-					Resource: permissions.ObjectArn(repository, srcPath),
+					Action:   permissions.ReadObjectAction,
+					Resource: permissions.ObjectArn(srcRepository, srcPath),
 				},
 			},
 			{
@@ -2830,7 +3486,7 @@ func (c *Controller) CopyObject(w http.ResponseWriter, r *http.Request, body api
 	}
 
 	// copy entry
-	entry, err := c.Catalog.CopyEntry(ctx, repository, srcRef, srcPath, repository, branch, destPath)
+	entry, err := c.Catalog.CopyEntry(ctx, srcRepository, srcRef, srcPath, repository, branch, destPath)
 	if c.handleAPIError(ctx, w, r, err) {
 		return
 	}
@@ -2921,6 +3577,88 @@ func (c *Controller) CherryPick(w http.ResponseWriter, r *http.Request, body api
 	commitResponse(w, r, newCommit)
 }
 
+func (c *Controller) SquashBranch(w http.ResponseWriter, r *http.Request, body apigen.SquashBranchJSONRequestBody, repository string, branch string) {
+	if !c.authorize(w, r, permissions.Node{
+		Type: permissions.NodeTypeAnd,
+		Nodes: []permissions.Node{
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.CreateCommitAction,
+					Resource: permissions.BranchArn(repository, branch),
+				},
+			},
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.ReadCommitAction,
+					Resource: permissions.RepoArn(repository),
+				},
+			},
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "squash_branch", r, repository, branch, body.Ref)
+	user, err := auth.GetUser(ctx)
+	if err != nil {
+		writeError(w, r, http.StatusUnauthorized, "user not found")
+		return
+	}
+	var metadata map[string]string
+	if body.Metadata != nil {
+		metadata = body.Metadata.AdditionalProperties
+	}
+	newCommit, err := c.Catalog.Squash(ctx, repository, branch, catalog.SquashParams{
+		Reference: body.Ref,
+		Message:   swag.StringValue(body.Message),
+		Metadata:  metadata,
+		Committer: user.Username,
+	})
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+
+	commitResponse(w, r, newCommit)
+}
+
+func (c *Controller) RewriteBranch(w http.ResponseWriter, r *http.Request, body apigen.RewriteBranchJSONRequestBody, repository string, branch string) {
+	if !c.authorize(w, r, permissions.Node{
+		Type: permissions.NodeTypeAnd,
+		Nodes: []permissions.Node{
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.CreateCommitAction,
+					Resource: permissions.BranchArn(repository, branch),
+				},
+			},
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.ReadCommitAction,
+					Resource: permissions.RepoArn(repository),
+				},
+			},
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "rewrite_branch", r, repository, branch, "")
+	user, err := auth.GetUser(ctx)
+	if err != nil {
+		writeError(w, r, http.StatusUnauthorized, "user not found")
+		return
+	}
+	newCommit, err := c.Catalog.Rewrite(ctx, repository, branch, catalog.RewriteParams{
+		Exclude:   body.Exclude,
+		Committer: user.Username,
+	})
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+
+	commitResponse(w, r, newCommit)
+}
+
 func (c *Controller) GetCommit(w http.ResponseWriter, r *http.Request, repository, commitID string) {
 	if !c.authorize(w, r, permissions.Node{
 		Permission: permissions.Permission{
@@ -2945,6 +3683,10 @@ func (c *Controller) GetCommit(w http.ResponseWriter, r *http.Request, repositor
 		writeError(w, r, http.StatusInternalServerError, err)
 		return
 	}
+	signatureStatus, err := c.commitSignatureStatus(ctx, repository, commit, publicKeyCache{})
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
 	response := apigen.Commit{
 		Id:           commit.Reference,
 		Committer:    commit.Committer,
@@ -2953,10 +3695,113 @@ func (c *Controller) GetCommit(w http.ResponseWriter, r *http.Request, repositor
 		MetaRangeId:  commit.MetaRangeID,
 		Metadata:     &apigen.Commit_Metadata{AdditionalProperties: commit.Metadata},
 		Parents:      commit.Parents,
+		Signature:    signatureStatus,
 	}
 	writeResponse(w, r, http.StatusOK, response)
 }
 
+func (c *Controller) SignCommit(w http.ResponseWriter, r *http.Request, body apigen.SignCommitJSONRequestBody, repository, commitID string) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.CreateCommitAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "sign_commit", r, repository, commitID, "")
+	commit, err := c.Catalog.GetCommit(ctx, repository, commitID)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+
+	// accept the signature if it verifies with any of the committer's keys
+	var fingerprint string
+	params := &model.PaginationParams{Amount: -1}
+	for fingerprint == "" {
+		publicKeys, paginator, err := c.Auth.ListUserPublicKeys(ctx, commit.Committer, params)
+		if errors.Is(err, auth.ErrNotFound) {
+			break
+		}
+		if c.handleAPIError(ctx, w, r, err) {
+			return
+		}
+		for _, k := range publicKeys {
+			if c.verifyCommitSignature(k, commit.Reference, body.Signature) == nil {
+				fingerprint = k.Fingerprint
+				break
+			}
+		}
+		if paginator.NextPageToken == "" {
+			break
+		}
+		params.After = paginator.NextPageToken
+	}
+	if fingerprint == "" {
+		writeError(w, r, http.StatusBadRequest, fmt.Sprintf("signature does not verify with any public key of committer %s", commit.Committer))
+		return
+	}
+
+	commitSignature := catalog.CommitSignature{
+		KeyFingerprint: fingerprint,
+		Signature:      body.Signature,
+		CreationDate:   time.Now(),
+	}
+	err = c.Catalog.SignCommit(ctx, repository, commit.Reference, commitSignature)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	response := apigen.CommitSignatureStatus{
+		KeyFingerprint: fingerprint,
+		Verified:       true,
+		CreationDate:   commitSignature.CreationDate.Unix(),
+	}
+	writeResponse(w, r, http.StatusCreated, response)
+}
+
+// publicKeyCache holds the public keys already fetched while serving a single request, keyed by committer and key
+// fingerprint.  A nil entry marks a key that is not registered.
+type publicKeyCache map[[2]string]*model.PublicKey
+
+// commitSignatureStatus returns the signature status of commit, or nil if it is not signed.  A signature is verified
+// if the key that signed it is still registered to the committer.
+func (c *Controller) commitSignatureStatus(ctx context.Context, repository string, commit *catalog.CommitLog, publicKeys publicKeyCache) (*apigen.CommitSignatureStatus, error) {
+	commitSignature, err := c.Catalog.GetCommitSignature(ctx, repository, commit.Reference)
+	if errors.Is(err, graveler.ErrCommitSignatureNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cacheKey := [2]string{commit.Committer, commitSignature.KeyFingerprint}
+	publicKey, ok := publicKeys[cacheKey]
+	if !ok {
+		publicKey, err = c.Auth.GetUserPublicKey(ctx, commit.Committer, commitSignature.KeyFingerprint)
+		if err != nil && !errors.Is(err, auth.ErrNotFound) && !errors.Is(err, auth.ErrNotImplemented) {
+			return nil, err
+		}
+		publicKeys[cacheKey] = publicKey
+	}
+	verified := false
+	if publicKey != nil {
+		verified = c.verifyCommitSignature(publicKey, commit.Reference, commitSignature.Signature) == nil
+	}
+	return &apigen.CommitSignatureStatus{
+		KeyFingerprint: commitSignature.KeyFingerprint,
+		Verified:       verified,
+		CreationDate:   commitSignature.CreationDate.Unix(),
+	}, nil
+}
+
+func (c *Controller) verifyCommitSignature(publicKey *model.PublicKey, commitID string, commitSignature []byte) error {
+	key, err := signature.ParsePublicKey(publicKey.PublicKey)
+	if err != nil {
+		return err
+	}
+	return signature.VerifyCommit(key, commitID, commitSignature)
+}
+
 func (c *Controller) GetGarbageCollectionRules(w http.ResponseWriter, r *http.Request, repository string) {
 	if !c.authorize(w, r, permissions.Node{
 		Permission: permissions.Permission{
@@ -3081,10 +3926,16 @@ func (c *Controller) GetBranchProtectionRules(w http.ResponseWriter, r *http.Req
 		return
 	}
 	resp := make([]*apigen.BranchProtectionRule, 0, len(rules.BranchPatternToBlockedActions))
-	for pattern := range rules.BranchPatternToBlockedActions {
-		resp = append(resp, &apigen.BranchProtectionRule{
+	for pattern, blockedActions := range rules.BranchPatternToBlockedActions {
+		rule := &apigen.BranchProtectionRule{
 			Pattern: pattern,
-		})
+		}
+		for _, action := range blockedActions.GetValue() {
+			if action == graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT {
+				rule.RequireSignedCommits = swag.Bool(true)
+			}
+		}
+		resp = append(resp, rule)
 	}
 	writeResponse(w, r, http.StatusOK, resp)
 }
@@ -3138,6 +3989,9 @@ func (c *Controller) CreateBranchProtectionRule(w http.ResponseWriter, r *http.R
 
 	// For now, all protected branches use the same default set of blocked actions. In the future this set will be user configurable.
 	blockedActions := []graveler.BranchProtectionBlockedAction{graveler.BranchProtectionBlockedAction_STAGING_WRITE, graveler.BranchProtectionBlockedAction_COMMIT}
+	if swag.BoolValue(body.RequireSignedCommits) {
+		blockedActions = append(blockedActions, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT)
+	}
 	err := c.Catalog.CreateBranchProtectionRule(ctx, repository, body.Pattern, blockedActions)
 	if c.handleAPIError(ctx, w, r, err) {
 		return
@@ -3349,6 +4203,149 @@ func (c *Controller) RestoreRefs(w http.ResponseWriter, r *http.Request, body ap
 	}
 }
 
+func (c *Controller) ExportRepositoryArchive(w http.ResponseWriter, r *http.Request, repository string, params apigen.ExportRepositoryArchiveParams) {
+	permission := permissions.Node{
+		Type: permissions.NodeTypeAnd,
+		Nodes: []permissions.Node{
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.ListTagsAction,
+					Resource: permissions.RepoArn(repository),
+				},
+			},
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.ListBranchesAction,
+					Resource: permissions.RepoArn(repository),
+				},
+			},
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.ListCommitsAction,
+					Resource: permissions.RepoArn(repository),
+				},
+			},
+		},
+	}
+	includeObjects := swag.BoolValue(params.IncludeObjects)
+	if includeObjects {
+		permission.Nodes = append(permission.Nodes, permissions.Node{
+			Permission: permissions.Permission{
+				Action:   permissions.ReadObjectAction,
+				Resource: permissions.ObjectArn(repository, "*"),
+			},
+		})
+	}
+	if !c.authorize(w, r, permission) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "export_repository_archive", r, repository, "", "")
+
+	_, err := c.Catalog.GetRepository(ctx, repository)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.tar\"", repository))
+	w.Header().Set("Trailer", helpers.ArchiveErrorTrailer)
+	err = c.Catalog.ExportRepositoryArchive(ctx, repository, includeObjects, w)
+	if err != nil {
+		// the response status was already sent with the start of the archive:
+		// report the failure in the trailer, after the truncated archive
+		c.Logger.WithContext(ctx).WithError(err).WithField("repository", repository).Error("Failed to export repository archive")
+		w.Header().Set(helpers.ArchiveErrorTrailer, err.Error())
+	}
+}
+
+func (c *Controller) ImportRepositoryArchive(w http.ResponseWriter, r *http.Request, repository string) {
+	if !c.authorize(w, r, permissions.Node{
+		Type: permissions.NodeTypeAnd,
+		Nodes: []permissions.Node{
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.CreateTagAction,
+					Resource: permissions.RepoArn(repository),
+				},
+			},
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.CreateBranchAction,
+					Resource: permissions.RepoArn(repository),
+				},
+			},
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.CreateCommitAction,
+					Resource: permissions.RepoArn(repository),
+				},
+			},
+			{
+				Permission: permissions.Permission{
+					Action:   permissions.WriteObjectAction,
+					Resource: permissions.ObjectArn(repository, "*"),
+				},
+			},
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "import_repository_archive", r, repository, "", "")
+
+	manifest, err := c.Catalog.ImportRepositoryArchive(ctx, repository, r.Body)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	writeResponse(w, r, http.StatusCreated, apigen.RefsDump{
+		BranchesMetaRangeId: manifest.BranchesMetaRangeID,
+		CommitsMetaRangeId:  manifest.CommitsMetaRangeID,
+		TagsMetaRangeId:     manifest.TagsMetaRangeID,
+	})
+}
+
+func (c *Controller) GetReplicationStatus(w http.ResponseWriter, r *http.Request, repository string) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.ListBranchesAction,
+			Resource: permissions.RepoArn(repository),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "get_replication_status", r, repository, "", "")
+
+	statuses, err := c.Catalog.GetReplicationStatus(ctx, repository)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+	response := apigen.ReplicationStatusList{
+		Results: make([]apigen.ReplicationStatus, 0, len(statuses)),
+	}
+	for _, status := range statuses {
+		s := apigen.ReplicationStatus{
+			Branch:           status.Branch,
+			TargetRepository: status.TargetRepository,
+			SourceCommitId:   status.SourceCommitID,
+			PendingCommits:   status.PendingCommits,
+			LagSeconds:       int64(status.Lag.Seconds()),
+		}
+		if status.TargetCommitID != "" {
+			s.TargetCommitId = swag.String(status.TargetCommitID)
+		}
+		if status.LastReplicatedAt != nil {
+			s.LastReplicatedAt = swag.Int64(status.LastReplicatedAt.Unix())
+		}
+		if status.LastError != "" {
+			s.LastError = swag.String(status.LastError)
+		}
+		response.Results = append(response.Results, s)
+	}
+	writeResponse(w, r, http.StatusOK, response)
+}
+
 func (c *Controller) CreateSymlinkFile(w http.ResponseWriter, r *http.Request, repository, branch string, params apigen.CreateSymlinkFileParams) {
 	if !c.authorize(w, r, permissions.Node{
 		Permission: permissions.Permission{
@@ -3438,6 +4435,68 @@ func writeSymlink(ctx context.Context, repo *catalog.Repository, branch, path st
 	return err
 }
 
+func (c *Controller) DiffObjectContent(w http.ResponseWriter, r *http.Request, repository, leftRef, rightRef string, params apigen.DiffObjectContentParams) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.ReadObjectAction,
+			Resource: permissions.ObjectArn(repository, params.Path),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "diff_object_content", r, repository, rightRef, leftRef)
+
+	res, err := c.Catalog.DiffObjectContent(ctx, repository, leftRef, rightRef, params.Path)
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+
+	response := apigen.ObjectContentDiff{
+		Type:  res.Type,
+		Left:  objectContentSummaryResponse(res.Left),
+		Right: objectContentSummaryResponse(res.Right),
+	}
+	if res.Type == catalog.ContentDiffTypeText {
+		response.UnifiedDiff = swag.String(res.UnifiedDiff)
+	}
+	if res.Table != nil {
+		response.Table = &apigen.TableContentDiff{
+			LeftSchema:      tableSchemaResponse(res.Table.LeftSchema),
+			RightSchema:     tableSchemaResponse(res.Table.RightSchema),
+			LeftRowCount:    res.Table.LeftRowCount,
+			RightRowCount:   res.Table.RightRowCount,
+			AddedRowCount:   res.Table.AddedRowCount,
+			RemovedRowCount: res.Table.RemovedRowCount,
+			AddedRows:       append([]string{}, res.Table.AddedRows...),
+			RemovedRows:     append([]string{}, res.Table.RemovedRows...),
+		}
+	}
+	writeResponse(w, r, http.StatusOK, response)
+}
+
+func objectContentSummaryResponse(summary *catalog.ObjectContentSummary) *apigen.ObjectContentSummary {
+	if summary == nil {
+		return nil
+	}
+	return &apigen.ObjectContentSummary{
+		SizeBytes: summary.Size,
+		Checksum:  summary.Checksum,
+	}
+}
+
+func tableSchemaResponse(schema []catalog.TableColumn) []apigen.TableColumn {
+	res := make([]apigen.TableColumn, 0, len(schema))
+	for _, column := range schema {
+		col := apigen.TableColumn{Name: column.Name}
+		if column.Type != "" {
+			col.Type = swag.String(column.Type)
+		}
+		res = append(res, col)
+	}
+	return res
+}
+
 func (c *Controller) DiffRefs(w http.ResponseWriter, r *http.Request, repository, leftRef, rightRef string, params apigen.DiffRefsParams) {
 	if !c.authorize(w, r, permissions.Node{
 		Permission: permissions.Permission{
@@ -3498,6 +4557,7 @@ func (c *Controller) LogCommits(w http.ResponseWriter, r *http.Request, reposito
 	}
 	ctx := r.Context()
 	c.LogAction(ctx, "get_branch_commit_log", r, repository, ref, "")
+	ref = refAsOf(ref, params.AsOf)
 
 	// get commit log
 	commitLog, hasMore, err := c.Catalog.ListCommits(ctx, repository, ref, catalog.LogParams{
@@ -3511,11 +4571,21 @@ func (c *Controller) LogCommits(w http.ResponseWriter, r *http.Request, reposito
 		return
 	}
 
+	// signatures cost a lookup per commit, so they are returned only on request
+	withSignatures := swag.BoolValue(params.Signatures)
+	publicKeys := publicKeyCache{}
 	serializedCommits := make([]apigen.Commit, 0, len(commitLog))
 	for _, commit := range commitLog {
 		metadata := apigen.Commit_Metadata{
 			AdditionalProperties: commit.Metadata,
 		}
+		var signatureStatus *apigen.CommitSignatureStatus
+		if withSignatures {
+			signatureStatus, err = c.commitSignatureStatus(ctx, repository, commit, publicKeys)
+			if c.handleAPIError(ctx, w, r, err) {
+				return
+			}
+		}
 		serializedCommits = append(serializedCommits, apigen.Commit{
 			Committer:    commit.Committer,
 			CreationDate: commit.CreationDate.Unix(),
@@ -3524,6 +4594,7 @@ func (c *Controller) LogCommits(w http.ResponseWriter, r *http.Request, reposito
 			Metadata:     &metadata,
 			MetaRangeId:  commit.MetaRangeID,
 			Parents:      commit.Parents,
+			Signature:    signatureStatus,
 		})
 	}
 
@@ -3547,6 +4618,7 @@ func (c *Controller) HeadObject(w http.ResponseWriter, r *http.Request, reposito
 	}
 	ctx := r.Context()
 	c.LogAction(ctx, "head_object", r, repository, ref, "")
+	ref = refAsOf(ref, params.AsOf)
 
 	// read the FS entry
 	entry, err := c.Catalog.GetEntry(ctx, repository, ref, params.Path, catalog.GetEntryParams{})
@@ -3597,6 +4669,7 @@ func (c *Controller) GetObject(w http.ResponseWriter, r *http.Request, repositor
 	}
 	ctx := r.Context()
 	c.LogAction(ctx, "get_object", r, repository, ref, "")
+	ref = refAsOf(ref, params.AsOf)
 
 	repo, err := c.Catalog.GetRepository(ctx, repository)
 	if c.handleAPIError(ctx, w, r, err) {
@@ -3620,6 +4693,7 @@ func (c *Controller) GetObject(w http.ResponseWriter, r *http.Request, repositor
 		IdentifierType:   entry.AddressType.ToIdentifierType(),
 		Identifier:       entry.PhysicalAddress,
 	}
+	ctx = compress.WithEntry(ctx, entry.Metadata)
 	if swag.BoolValue(params.Presign) {
 		location, _, err := c.BlockAdapter.GetPreSignedURL(ctx, pointer, block.PreSignModeRead)
 		if c.handleAPIError(ctx, w, r, err) {
@@ -3696,6 +4770,7 @@ func (c *Controller) ListObjects(w http.ResponseWriter, r *http.Request, reposit
 	ctx := r.Context()
 	user, _ := auth.GetUser(ctx)
 	c.LogAction(ctx, "list_objects", r, repository, ref, "")
+	ref = refAsOf(ref, params.AsOf)
 
 	repo, err := c.Catalog.GetRepository(ctx, repository)
 	if c.handleAPIError(ctx, w, r, err) {
@@ -3761,7 +4836,7 @@ func (c *Controller) ListObjects(w http.ResponseWriter, r *http.Request, reposit
 				}
 				if authResponse.Allowed {
 					var expiry time.Time
-					objStat.PhysicalAddress, expiry, err = c.BlockAdapter.GetPreSignedURL(ctx, block.ObjectPointer{
+					objStat.PhysicalAddress, expiry, err = c.BlockAdapter.GetPreSignedURL(compress.WithEntry(ctx, entry.Metadata), block.ObjectPointer{
 						StorageNamespace: repo.StorageNamespace,
 						IdentifierType:   entry.AddressType.ToIdentifierType(),
 						Identifier:       entry.PhysicalAddress,
@@ -3803,6 +4878,7 @@ func (c *Controller) StatObject(w http.ResponseWriter, r *http.Request, reposito
 	}
 	ctx := r.Context()
 	c.LogAction(ctx, "stat_object", r, repository, ref, "")
+	ref = refAsOf(ref, params.AsOf)
 
 	repo, err := c.Catalog.GetRepository(ctx, repository)
 	if c.handleAPIError(ctx, w, r, err) {
@@ -3836,7 +4912,7 @@ func (c *Controller) StatObject(w http.ResponseWriter, r *http.Request, reposito
 		code = http.StatusGone
 	} else if swag.BoolValue(params.Presign) {
 		// need to pre-sign the physical address
-		preSignedURL, expiry, err := c.BlockAdapter.GetPreSignedURL(ctx, block.ObjectPointer{
+		preSignedURL, expiry, err := c.BlockAdapter.GetPreSignedURL(compress.WithEntry(ctx, entry.Metadata), block.ObjectPointer{
 			StorageNamespace: repo.StorageNamespace,
 			IdentifierType:   entry.AddressType.ToIdentifierType(),
 			Identifier:       entry.PhysicalAddress,
@@ -3852,6 +4928,46 @@ func (c *Controller) StatObject(w http.ResponseWriter, r *http.Request, reposito
 	writeResponse(w, r, code, objStat)
 }
 
+func (c *Controller) BlameObject(w http.ResponseWriter, r *http.Request, repository, ref string, params apigen.BlameObjectParams) {
+	if !c.authorize(w, r, permissions.Node{
+		Permission: permissions.Permission{
+			Action:   permissions.ReadObjectAction,
+			Resource: permissions.ObjectArn(repository, params.Path),
+		},
+	}) {
+		return
+	}
+	ctx := r.Context()
+	c.LogAction(ctx, "blame_object", r, repository, ref, "")
+	ref = refAsOf(ref, params.AsOf)
+
+	lines, err := c.Catalog.BlameObject(ctx, repository, ref, params.Path, swag.StringValue(params.Format))
+	if c.handleAPIError(ctx, w, r, err) {
+		return
+	}
+
+	response := apigen.BlameLineList{
+		Results: make([]apigen.BlameLine, 0, len(lines)),
+	}
+	commits := make(map[string]*apigen.Commit)
+	for _, line := range lines {
+		blameLine := apigen.BlameLine{
+			Number:  line.Number,
+			Content: line.Content,
+		}
+		if line.Commit != nil {
+			commit, ok := commits[line.Commit.Reference]
+			if !ok {
+				commit = apiutil.Ptr(commitFromCommitLog(line.Commit))
+				commits[line.Commit.Reference] = commit
+			}
+			blameLine.Commit = commit
+		}
+		response.Results = append(response.Results, blameLine)
+	}
+	writeResponse(w, r, http.StatusOK, response)
+}
+
 func (c *Controller) GetUnderlyingProperties(w http.ResponseWriter, r *http.Request, repository, ref string, params apigen.GetUnderlyingPropertiesParams) {
 	if !c.authorize(w, r, permissions.Node{
 		Permission: permissions.Permission{
@@ -3863,6 +4979,7 @@ func (c *Controller) GetUnderlyingProperties(w http.ResponseWriter, r *http.Requ
 	}
 	ctx := r.Context()
 	c.LogAction(ctx, "object_underlying_properties", r, repository, ref, "")
+	ref = refAsOf(ref, params.AsOf)
 
 	// read repo
 	repo, err := c.Catalog.GetRepository(ctx, repository)
@@ -3876,7 +4993,7 @@ func (c *Controller) GetUnderlyingProperties(w http.ResponseWriter, r *http.Requ
 	}
 
 	// read object properties from underlying storage
-	properties, err := c.BlockAdapter.GetProperties(ctx, block.ObjectPointer{
+	properties, err := c.BlockAdapter.GetProperties(compress.WithEntry(ctx, entry.Metadata), block.ObjectPointer{
 		StorageNamespace: repo.StorageNamespace,
 		IdentifierType:   entry.AddressType.ToIdentifierType(),
 		Identifier:       entry.PhysicalAddress,
@@ -4453,29 +5570,6 @@ func (c *Controller) OtfDiff(w http.ResponseWriter, r *http.Request, repository,
 	ctx := r.Context()
 	user, _ := auth.GetUser(ctx)
 	c.LogAction(ctx, fmt.Sprintf("table_format_%s_diff", params.Type), r, repository, rightRef, leftRef)
-	credentials, _, err := c.Auth.ListUserCredentials(ctx, user.Username, &model.PaginationParams{
-		Prefix: "",
-		After:  "",
-		Amount: 1,
-	})
-	if c.handleAPIError(ctx, w, r, err) {
-		return
-	}
-	if len(credentials) == 0 {
-		writeError(w, r, http.StatusPreconditionFailed, "no programmatic credentials")
-		return
-	}
-
-	baseCredential, err := c.Auth.GetCredentials(ctx, credentials[0].AccessKeyID)
-	if c.handleAPIError(ctx, w, r, err) {
-		return
-	}
-
-	listenAddress := c.Config.ListenAddress
-	if strings.HasPrefix(listenAddress, ":") {
-		// workaround in case we listen on all interfaces without specifying ip
-		listenAddress = fmt.Sprintf("localhost%s", listenAddress)
-	}
 
 	tdp := tablediff.Params{
 		// TODO(jonathan): add base RefPath
@@ -4489,12 +5583,60 @@ func (c *Controller) OtfDiff(w http.ResponseWriter, r *http.Request, repository,
 				Path: params.TablePath,
 			},
 		},
-		S3Creds: tablediff.S3Creds{
+		Repo: repository,
+	}
+	if c.otfDiffService.IsBuiltin(params.Type) {
+		// built-in diffs read the table directly, with the permissions of the user
+		if !c.authorize(w, r, permissions.Node{
+			Type: permissions.NodeTypeAnd,
+			Nodes: []permissions.Node{
+				{
+					Permission: permissions.Permission{
+						Action:   permissions.ListObjectsAction,
+						Resource: permissions.RepoArn(repository),
+					},
+				},
+				{
+					Permission: permissions.Permission{
+						Action:   permissions.ReadObjectAction,
+						Resource: permissions.ObjectArn(repository, params.TablePath),
+					},
+				},
+			},
+		}) {
+			return
+		}
+		tdp.Reader = &catalogTableReader{catalog: c.Catalog, adapter: c.BlockAdapter}
+	} else {
+		// diff plugins read the table through the S3 gateway, with the credentials of the user
+		credentials, _, err := c.Auth.ListUserCredentials(ctx, user.Username, &model.PaginationParams{
+			Prefix: "",
+			After:  "",
+			Amount: 1,
+		})
+		if c.handleAPIError(ctx, w, r, err) {
+			return
+		}
+		if len(credentials) == 0 {
+			writeError(w, r, http.StatusPreconditionFailed, "no programmatic credentials")
+			return
+		}
+
+		baseCredential, err := c.Auth.GetCredentials(ctx, credentials[0].AccessKeyID)
+		if c.handleAPIError(ctx, w, r, err) {
+			return
+		}
+
+		listenAddress := c.Config.ListenAddress
+		if strings.HasPrefix(listenAddress, ":") {
+			// workaround in case we listen on all interfaces without specifying ip
+			listenAddress = fmt.Sprintf("localhost%s", listenAddress)
+		}
+		tdp.S3Creds = tablediff.S3Creds{
 			Key:      config.SecureString(baseCredential.AccessKeyID),
 			Secret:   config.SecureString(baseCredential.SecretAccessKey),
 			Endpoint: "http://" + listenAddress,
-		},
-		Repo: repository,
+		}
 	}
 
 	entries, err := c.otfDiffService.RunDiff(ctx, params.Type, tdp)
@@ -4771,6 +5913,15 @@ func (c *Controller) authorizeCallback(w http.ResponseWriter, r *http.Request, p
 	return true
 }
 
+// refAsOf returns the ref expression resolving to ref as it was at asOf, or ref itself if asOf is not set.  The
+// generated parameter binding sets a zero time when the parameter is missing.
+func refAsOf(ref string, asOf *apigen.RefAsOf) string {
+	if asOf == nil || time.Time(*asOf).IsZero() {
+		return ref
+	}
+	return ref + "@{" + time.Time(*asOf).UTC().Format(time.RFC3339Nano) + "}"
+}
+
 func (c *Controller) authorize(w http.ResponseWriter, r *http.Request, perms permissions.Node) bool {
 	return c.authorizeCallback(w, r, perms, writeError)
 }
@@ -3,6 +3,8 @@ package api_test
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +14,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"reflect"
 	"regexp"
@@ -36,6 +39,7 @@ import (
 	"github.com/treeverse/lakefs/pkg/api/apiutil"
 	"github.com/treeverse/lakefs/pkg/auth"
 	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/compress"
 	"github.com/treeverse/lakefs/pkg/catalog"
 	"github.com/treeverse/lakefs/pkg/catalog/testutils"
 	"github.com/treeverse/lakefs/pkg/config"
@@ -43,9 +47,11 @@ import (
 	"github.com/treeverse/lakefs/pkg/httputil"
 	"github.com/treeverse/lakefs/pkg/ingest/store"
 	tablediff "github.com/treeverse/lakefs/pkg/plugins/diff"
+	"github.com/treeverse/lakefs/pkg/signature"
 	"github.com/treeverse/lakefs/pkg/stats"
 	"github.com/treeverse/lakefs/pkg/testutil"
 	"github.com/treeverse/lakefs/pkg/upload"
+	"golang.org/x/crypto/ssh"
 	"golang.org/x/exp/slices"
 )
 
@@ -990,6 +996,36 @@ func TestController_CreateRepositoryHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("create repo with compression", func(t *testing.T) {
+		repoName := testUniqueRepoName()
+		resp, err := clt.CreateRepositoryWithResponse(ctx, &apigen.CreateRepositoryParams{}, apigen.CreateRepositoryJSONRequestBody{
+			DefaultBranch:    apiutil.Ptr("main"),
+			Name:             repoName,
+			StorageNamespace: onBlock(deps, "foo-bucket-compressed"),
+			Compression:      apiutil.Ptr("zstd"),
+		})
+		verifyResponseOK(t, resp, err)
+
+		codec, err := deps.catalog.GetRepositoryCompression(ctx, repoName)
+		testutil.Must(t, err)
+		if codec != compress.CodecZstd {
+			t.Fatalf("GetRepositoryCompression=%s, expected=%s", codec, compress.CodecZstd)
+		}
+	})
+
+	t.Run("create repo bad compression", func(t *testing.T) {
+		resp, err := clt.CreateRepositoryWithResponse(ctx, &apigen.CreateRepositoryParams{}, apigen.CreateRepositoryJSONRequestBody{
+			DefaultBranch:    apiutil.Ptr("main"),
+			Name:             testUniqueRepoName(),
+			StorageNamespace: onBlock(deps, "foo-bucket-bad-compression"),
+			Compression:      apiutil.Ptr("lz4"),
+		})
+		testutil.Must(t, err)
+		if resp.StatusCode() != http.StatusBadRequest {
+			t.Fatalf("CreateRepository with bad compression status code %d, expected %d", resp.StatusCode(), http.StatusBadRequest)
+		}
+	})
+
 	t.Run("create repo duplicate", func(t *testing.T) {
 		repo := testUniqueRepoName()
 		_, err := deps.catalog.CreateRepository(ctx, repo, onBlock(deps, "foo1"), "main")
@@ -1169,6 +1205,81 @@ func TestController_GetRepositoryMetadataHandler(t *testing.T) {
 	})
 }
 
+func TestController_RepositoryAccessStateHandler(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t)
+	ctx := context.Background()
+
+	repoName := testUniqueRepoName()
+	_, err := deps.catalog.CreateRepository(ctx, repoName, onBlock(deps, repoName), "main")
+	testutil.Must(t, err)
+	uploadResp, err := uploadObjectHelper(t, ctx, clt, "foo/bar", strings.NewReader("hello"), repoName, "main")
+	verifyResponseOK(t, uploadResp, err)
+
+	setState := func(t *testing.T, state string) {
+		t.Helper()
+		resp, err := clt.SetRepositoryAccessStateWithResponse(ctx, repoName, apigen.SetRepositoryAccessStateJSONRequestBody{State: state})
+		verifyResponseOK(t, resp, err)
+		getResp, err := clt.GetRepositoryAccessStateWithResponse(ctx, repoName)
+		verifyResponseOK(t, getResp, err)
+		require.Equal(t, state, getResp.JSON200.State)
+	}
+
+	t.Run("default active", func(t *testing.T) {
+		resp, err := clt.GetRepositoryAccessStateWithResponse(ctx, repoName)
+		verifyResponseOK(t, resp, err)
+		require.Equal(t, "active", resp.JSON200.State)
+	})
+
+	t.Run("invalid state", func(t *testing.T) {
+		resp, err := clt.SetRepositoryAccessStateWithResponse(ctx, repoName, apigen.SetRepositoryAccessStateJSONRequestBody{State: "frozen"})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		setState(t, "read-only")
+
+		uploadResp, err := uploadObjectHelper(t, ctx, clt, "foo/baz", strings.NewReader("world"), repoName, "main")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusForbidden, uploadResp.StatusCode())
+		commitResp, err := clt.CommitWithResponse(ctx, repoName, "main", &apigen.CommitParams{}, apigen.CommitJSONRequestBody{Message: "frozen"})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusForbidden, commitResp.StatusCode())
+		branchResp, err := clt.CreateBranchWithResponse(ctx, repoName, apigen.CreateBranchJSONRequestBody{Name: "feature", Source: "main"})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusForbidden, branchResp.StatusCode())
+		ruleResp, err := clt.CreateBranchProtectionRuleWithResponse(ctx, repoName, apigen.CreateBranchProtectionRuleJSONRequestBody{Pattern: "main"})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusForbidden, ruleResp.StatusCode())
+
+		// reads are allowed
+		statResp, err := clt.StatObjectWithResponse(ctx, repoName, "main", &apigen.StatObjectParams{Path: "foo/bar"})
+		verifyResponseOK(t, statResp, err)
+	})
+
+	t.Run("archived", func(t *testing.T) {
+		setState(t, "archived")
+
+		resp, err := clt.ListRepositoriesWithResponse(ctx, &apigen.ListRepositoriesParams{Prefix: apiutil.Ptr(apigen.PaginationPrefix(repoName))})
+		verifyResponseOK(t, resp, err)
+		require.Empty(t, resp.JSON200.Results)
+		resp, err = clt.ListRepositoriesWithResponse(ctx, &apigen.ListRepositoriesParams{Prefix: apiutil.Ptr(apigen.PaginationPrefix(repoName)), IncludeArchived: swag.Bool(true)})
+		verifyResponseOK(t, resp, err)
+		require.Len(t, resp.JSON200.Results, 1)
+
+		uploadResp, err := uploadObjectHelper(t, ctx, clt, "foo/baz", strings.NewReader("world"), repoName, "main")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusForbidden, uploadResp.StatusCode())
+	})
+
+	t.Run("active", func(t *testing.T) {
+		setState(t, "active")
+
+		uploadResp, err := uploadObjectHelper(t, ctx, clt, "foo/baz", strings.NewReader("world"), repoName, "main")
+		verifyResponseOK(t, uploadResp, err)
+	})
+}
+
 func TestController_ListBranchesHandler(t *testing.T) {
 	clt, deps := setupClientWithAdmin(t)
 	ctx := context.Background()
@@ -1326,6 +1437,61 @@ func TestController_ListTagsHandler(t *testing.T) {
 	})
 }
 
+func TestController_ListBranchReflogHandler(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t)
+	ctx := context.Background()
+
+	t.Run("missing branch", func(t *testing.T) {
+		repo := testUniqueRepoName()
+		_, err := deps.catalog.CreateRepository(ctx, repo, onBlock(deps, repo), "main")
+		testutil.Must(t, err)
+		resp, err := clt.ListBranchReflogWithResponse(ctx, repo, "missing", &apigen.ListBranchReflogParams{})
+		testutil.Must(t, err)
+		if resp.JSON404 == nil {
+			t.Fatalf("ListBranchReflog expected not found, got status %d", resp.StatusCode())
+		}
+	})
+
+	t.Run("commits", func(t *testing.T) {
+		repo := testUniqueRepoName()
+		_, err := deps.catalog.CreateRepository(ctx, repo, onBlock(deps, repo), "main")
+		testutil.Must(t, err)
+		initial, err := deps.catalog.GetBranchReference(ctx, repo, "main")
+		testutil.Must(t, err)
+
+		commitIDs := []string{initial}
+		for i := 0; i < 2; i++ {
+			testutil.Must(t, deps.catalog.CreateEntry(ctx, repo, "main", catalog.DBEntry{Path: fmt.Sprintf("foo/bar%d", i), PhysicalAddress: "pa", CreationDate: time.Now(), Size: 666, Checksum: "cs"}))
+			resp, err := clt.CommitWithResponse(ctx, repo, "main", &apigen.CommitParams{}, apigen.CommitJSONRequestBody{
+				Message: fmt.Sprintf("commit %d", i),
+			})
+			verifyResponseOK(t, resp, err)
+			commitIDs = append(commitIDs, resp.JSON201.Id)
+		}
+
+		resp, err := clt.ListBranchReflogWithResponse(ctx, repo, "main", &apigen.ListBranchReflogParams{
+			Amount: apiutil.Ptr(apigen.PaginationAmount(1)),
+		})
+		verifyResponseOK(t, resp, err)
+		require.Len(t, resp.JSON200.Results, 1)
+		require.True(t, resp.JSON200.Pagination.HasMore)
+		latest := resp.JSON200.Results[0]
+		require.Equal(t, commitIDs[1], latest.OldCommitId)
+		require.Equal(t, commitIDs[2], latest.NewCommitId)
+		require.Equal(t, "commit", latest.Operation)
+		require.Equal(t, "admin", swag.StringValue(latest.User))
+
+		resp, err = clt.ListBranchReflogWithResponse(ctx, repo, "main", &apigen.ListBranchReflogParams{
+			After: apiutil.Ptr(apigen.PaginationAfter(resp.JSON200.Pagination.NextOffset)),
+		})
+		verifyResponseOK(t, resp, err)
+		require.Len(t, resp.JSON200.Results, 1)
+		require.False(t, resp.JSON200.Pagination.HasMore)
+		require.Equal(t, commitIDs[0], resp.JSON200.Results[0].OldCommitId)
+		require.Equal(t, commitIDs[1], resp.JSON200.Results[0].NewCommitId)
+	})
+}
+
 func TestController_GetBranchHandler(t *testing.T) {
 	clt, deps := setupClientWithAdmin(t)
 	ctx := context.Background()
@@ -2284,6 +2450,190 @@ func TestController_ObjectsHeadObjectHandler(t *testing.T) {
 	})
 }
 
+func TestController_ObjectsBlameObjectHandler(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t)
+	ctx := context.Background()
+
+	repo := testUniqueRepoName()
+	_, err := deps.catalog.CreateRepository(ctx, repo, onBlock(deps, repo), "main")
+	testutil.Must(t, err)
+
+	const path = "tables/reference.csv"
+	versions := []string{
+		"id,name\n1,one\n2,two\n",
+		"id,name\n1,one\n2,TWO\n3,three\n",
+	}
+	var commitIDs []string
+	for i, content := range versions {
+		uploadResp, err := uploadObjectHelper(t, ctx, clt, path, strings.NewReader(content), repo, "main")
+		verifyResponseOK(t, uploadResp, err)
+		commitResp, err := clt.CommitWithResponse(ctx, repo, "main", &apigen.CommitParams{}, apigen.CommitJSONRequestBody{
+			Message: fmt.Sprintf("version %d", i),
+		})
+		verifyResponseOK(t, commitResp, err)
+		commitIDs = append(commitIDs, commitResp.JSON201.Id)
+	}
+	uploadResp, err := uploadObjectHelper(t, ctx, clt, path, strings.NewReader("id,name\n1,one\n2,TWO\n3,three\n4,four\n"), repo, "main")
+	verifyResponseOK(t, uploadResp, err)
+
+	t.Run("branch", func(t *testing.T) {
+		resp, err := clt.BlameObjectWithResponse(ctx, repo, "main", &apigen.BlameObjectParams{Path: path})
+		verifyResponseOK(t, resp, err)
+		expectedCommits := []string{commitIDs[0], commitIDs[0], commitIDs[1], commitIDs[1], ""}
+		require.Len(t, resp.JSON200.Results, len(expectedCommits))
+		for i, line := range resp.JSON200.Results {
+			require.Equal(t, i+1, line.Number)
+			commitID := ""
+			if line.Commit != nil {
+				commitID = line.Commit.Id
+			}
+			require.Equal(t, expectedCommits[i], commitID, "commit of line %d (%s)", line.Number, line.Content)
+		}
+		require.Equal(t, "2,TWO", resp.JSON200.Results[2].Content)
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		resp, err := clt.BlameObjectWithResponse(ctx, repo, commitIDs[0], &apigen.BlameObjectParams{Path: path, Format: swag.String("text")})
+		verifyResponseOK(t, resp, err)
+		require.Len(t, resp.JSON200.Results, 3)
+		for _, line := range resp.JSON200.Results {
+			require.NotNil(t, line.Commit)
+			require.Equal(t, commitIDs[0], line.Commit.Id)
+		}
+	})
+
+	t.Run("missing object", func(t *testing.T) {
+		resp, err := clt.BlameObjectWithResponse(ctx, repo, "main", &apigen.BlameObjectParams{Path: "missing.csv"})
+		testutil.Must(t, err)
+		if resp.JSON404 == nil {
+			t.Fatalf("BlameObject expected not found, got status %d", resp.StatusCode())
+		}
+	})
+}
+
+func TestController_DiffObjectContentHandler(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t)
+	ctx := context.Background()
+
+	repo := testUniqueRepoName()
+	_, err := deps.catalog.CreateRepository(ctx, repo, onBlock(deps, repo), "main")
+	testutil.Must(t, err)
+
+	upload := func(path, content string) {
+		t.Helper()
+		resp, err := uploadObjectHelper(t, ctx, clt, path, strings.NewReader(content), repo, "main")
+		verifyResponseOK(t, resp, err)
+	}
+	upload("config.yaml", "a: 1\nb: 2\n")
+	upload("table.csv", "id,name\n1,one\n2,two\n")
+	upload("data.bin", "\x00\x01\x02")
+	commitResp, err := clt.CommitWithResponse(ctx, repo, "main", &apigen.CommitParams{}, apigen.CommitJSONRequestBody{Message: "first"})
+	verifyResponseOK(t, commitResp, err)
+	upload("config.yaml", "a: 1\nb: 3\n")
+	upload("table.csv", "id,name\n1,one\n2,TWO\n3,three\n")
+	upload("data.bin", "\x00\x01\x03")
+	upload("new.txt", "hello\n")
+
+	t.Run("text", func(t *testing.T) {
+		resp, err := clt.DiffObjectContentWithResponse(ctx, repo, "main@", "main", &apigen.DiffObjectContentParams{Path: "config.yaml"})
+		verifyResponseOK(t, resp, err)
+		require.Equal(t, "text", resp.JSON200.Type)
+		require.NotNil(t, resp.JSON200.UnifiedDiff)
+		require.Contains(t, *resp.JSON200.UnifiedDiff, "-b: 2\n+b: 3\n")
+		require.Equal(t, int64(10), resp.JSON200.Left.SizeBytes)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		resp, err := clt.DiffObjectContentWithResponse(ctx, repo, "main@", "main", &apigen.DiffObjectContentParams{Path: "table.csv"})
+		verifyResponseOK(t, resp, err)
+		require.Equal(t, "csv", resp.JSON200.Type)
+		table := resp.JSON200.Table
+		require.NotNil(t, table)
+		require.Equal(t, int64(2), table.LeftRowCount)
+		require.Equal(t, int64(3), table.RightRowCount)
+		require.Equal(t, []string{"2,two"}, table.RemovedRows)
+		require.Equal(t, []string{"2,TWO", "3,three"}, table.AddedRows)
+		require.Equal(t, "name", table.RightSchema[1].Name)
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		resp, err := clt.DiffObjectContentWithResponse(ctx, repo, "main@", "main", &apigen.DiffObjectContentParams{Path: "data.bin"})
+		verifyResponseOK(t, resp, err)
+		require.Equal(t, "binary", resp.JSON200.Type)
+		require.NotEqual(t, resp.JSON200.Left.Checksum, resp.JSON200.Right.Checksum)
+		require.Nil(t, resp.JSON200.UnifiedDiff)
+		require.Nil(t, resp.JSON200.Table)
+	})
+
+	t.Run("new object", func(t *testing.T) {
+		resp, err := clt.DiffObjectContentWithResponse(ctx, repo, "main@", "main", &apigen.DiffObjectContentParams{Path: "new.txt"})
+		verifyResponseOK(t, resp, err)
+		require.Nil(t, resp.JSON200.Left)
+		require.NotNil(t, resp.JSON200.Right)
+		require.Contains(t, *resp.JSON200.UnifiedDiff, "+hello\n")
+	})
+
+	t.Run("missing object", func(t *testing.T) {
+		resp, err := clt.DiffObjectContentWithResponse(ctx, repo, "main@", "main", &apigen.DiffObjectContentParams{Path: "missing.txt"})
+		testutil.Must(t, err)
+		if resp.JSON404 == nil {
+			t.Fatalf("DiffObjectContent expected not found, got status %d", resp.StatusCode())
+		}
+	})
+}
+
+func TestController_ObjectsAsOf(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t)
+	ctx := context.Background()
+
+	repo := testUniqueRepoName()
+	_, err := deps.catalog.CreateRepository(ctx, repo, onBlock(deps, repo), "main")
+	testutil.Must(t, err)
+	for _, version := range []struct {
+		content string
+		date    time.Time
+	}{
+		{content: "v1", date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{content: "v2", date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		resp, err := uploadObjectHelper(t, ctx, clt, "data/file", strings.NewReader(version.content), repo, "main")
+		verifyResponseOK(t, resp, err)
+		_, err = deps.catalog.Commit(ctx, repo, "main", "write "+version.content, "tester", nil, swag.Int64(version.date.Unix()), nil)
+		testutil.Must(t, err)
+	}
+
+	t.Run("as_of parameter", func(t *testing.T) {
+		asOf := apigen.RefAsOf(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+		resp, err := clt.GetObjectWithResponse(ctx, repo, "main", &apigen.GetObjectParams{Path: "data/file", AsOf: &asOf})
+		testutil.Must(t, err)
+		if resp.HTTPResponse.StatusCode != http.StatusOK || string(resp.Body) != "v1" {
+			t.Fatalf("GetObject() as of %s = %d %q, expected v1", time.Time(asOf), resp.HTTPResponse.StatusCode, resp.Body)
+		}
+		logResp, err := clt.LogCommitsWithResponse(ctx, repo, "main", &apigen.LogCommitsParams{AsOf: &asOf})
+		testutil.Must(t, err)
+		if logResp.JSON200 == nil || len(logResp.JSON200.Results) == 0 || logResp.JSON200.Results[0].Message != "write v1" {
+			t.Fatalf("LogCommits() as of %s = %s, expected to start at the v1 commit", time.Time(asOf), logResp.Body)
+		}
+	})
+
+	t.Run("ref expression", func(t *testing.T) {
+		resp, err := clt.GetObjectWithResponse(ctx, repo, "main@{2024-02-01T00:00:00Z}", &apigen.GetObjectParams{Path: "data/file"})
+		testutil.Must(t, err)
+		if resp.HTTPResponse.StatusCode != http.StatusOK || string(resp.Body) != "v2" {
+			t.Fatalf("GetObject() at the v2 commit time = %d %q, expected v2", resp.HTTPResponse.StatusCode, resp.Body)
+		}
+	})
+
+	t.Run("before history", func(t *testing.T) {
+		asOf := apigen.RefAsOf(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+		resp, err := clt.StatObjectWithResponse(ctx, repo, "main", &apigen.StatObjectParams{Path: "data/file", AsOf: &asOf})
+		testutil.Must(t, err)
+		if resp.JSON404 == nil {
+			t.Fatalf("StatObject() before the first commit status code %d, expected 404", resp.HTTPResponse.StatusCode)
+		}
+	})
+}
+
 func TestController_ObjectsGetObjectHandler(t *testing.T) {
 	clt, deps := setupClientWithAdmin(t)
 	ctx := context.Background()
@@ -2621,6 +2971,108 @@ func TestController_LinkPhysicalAddressHandler(t *testing.T) {
 	})
 }
 
+func TestController_MultipartUpload(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t)
+	ctx := context.Background()
+
+	repo := testUniqueRepoName()
+	_, err := deps.catalog.CreateRepository(ctx, repo, onBlock(deps, "bucket/prefix"), "main")
+	testutil.Must(t, err)
+
+	uploadPart := func(t *testing.T, upload *apigen.MultipartUpload, path string, partNumber int, content string) apigen.MultipartUploadPart {
+		t.Helper()
+		resp, err := clt.UploadMultipartPartWithBodyWithResponse(ctx, repo, "main", upload.UploadId, partNumber, &apigen.UploadMultipartPartParams{
+			Path:            path,
+			PhysicalAddress: upload.PhysicalAddress,
+		}, "application/octet-stream", strings.NewReader(content))
+		verifyResponseOK(t, resp, err)
+		require.Equal(t, partNumber, resp.JSON200.PartNumber)
+		return *resp.JSON200
+	}
+
+	t.Run("upload", func(t *testing.T) {
+		const path = "multipart/object"
+		createResp, err := clt.CreateMultipartUploadWithResponse(ctx, repo, "main", &apigen.CreateMultipartUploadParams{Path: path})
+		verifyResponseOK(t, createResp, err)
+		upload := createResp.JSON201
+		parts := []apigen.MultipartUploadPart{
+			uploadPart(t, upload, path, 1, "first part, "),
+			uploadPart(t, upload, path, 2, "second part"),
+		}
+
+		listResp, err := clt.ListMultipartUploadPartsWithResponse(ctx, repo, "main", upload.UploadId, &apigen.ListMultipartUploadPartsParams{
+			Path:            path,
+			PhysicalAddress: upload.PhysicalAddress,
+		})
+		verifyResponseOK(t, listResp, err)
+		require.Equal(t, parts, listResp.JSON200.Parts)
+
+		completeResp, err := clt.CompleteMultipartUploadWithResponse(ctx, repo, "main", upload.UploadId, &apigen.CompleteMultipartUploadParams{
+			Path:            path,
+			PhysicalAddress: upload.PhysicalAddress,
+		}, apigen.CompleteMultipartUploadJSONRequestBody{
+			Parts:        parts,
+			UserMetadata: &apigen.MultipartUploadCompletion_UserMetadata{AdditionalProperties: map[string]string{"key": "value"}},
+		})
+		verifyResponseOK(t, completeResp, err)
+		require.Equal(t, path, completeResp.JSON200.Path)
+		require.Equal(t, int64(len("first part, second part")), apiutil.Value(completeResp.JSON200.SizeBytes))
+
+		objResp, err := clt.GetObjectWithResponse(ctx, repo, "main", &apigen.GetObjectParams{Path: path})
+		verifyResponseOK(t, objResp, err)
+		require.Equal(t, "first part, second part", string(objResp.Body))
+		statResp, err := clt.StatObjectWithResponse(ctx, repo, "main", &apigen.StatObjectParams{Path: path, UserMetadata: swag.Bool(true)})
+		verifyResponseOK(t, statResp, err)
+		require.Equal(t, "value", statResp.JSON200.Metadata.AdditionalProperties["key"])
+
+		// the physical address is linked once
+		completeResp, err = clt.CompleteMultipartUploadWithResponse(ctx, repo, "main", upload.UploadId, &apigen.CompleteMultipartUploadParams{
+			Path:            path,
+			PhysicalAddress: upload.PhysicalAddress,
+		}, apigen.CompleteMultipartUploadJSONRequestBody{Parts: parts})
+		testutil.Must(t, err)
+		require.Equal(t, http.StatusBadRequest, completeResp.StatusCode())
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		const path = "multipart/aborted"
+		createResp, err := clt.CreateMultipartUploadWithResponse(ctx, repo, "main", &apigen.CreateMultipartUploadParams{Path: path})
+		verifyResponseOK(t, createResp, err)
+		upload := createResp.JSON201
+		uploadPart(t, upload, path, 1, "content")
+
+		abortResp, err := clt.AbortMultipartUploadWithResponse(ctx, repo, "main", upload.UploadId, &apigen.AbortMultipartUploadParams{
+			Path:            path,
+			PhysicalAddress: upload.PhysicalAddress,
+		})
+		verifyResponseOK(t, abortResp, err)
+		listResp, err := clt.ListMultipartUploadPartsWithResponse(ctx, repo, "main", upload.UploadId, &apigen.ListMultipartUploadPartsParams{
+			Path:            path,
+			PhysicalAddress: upload.PhysicalAddress,
+		})
+		testutil.Must(t, err)
+		require.Nil(t, listResp.JSON200)
+	})
+
+	t.Run("address outside the storage namespace", func(t *testing.T) {
+		const path = "multipart/outside"
+		createResp, err := clt.CreateMultipartUploadWithResponse(ctx, repo, "main", &apigen.CreateMultipartUploadParams{Path: path})
+		verifyResponseOK(t, createResp, err)
+		resp, err := clt.UploadMultipartPartWithBodyWithResponse(ctx, repo, "main", createResp.JSON201.UploadId, 1, &apigen.UploadMultipartPartParams{
+			Path:            path,
+			PhysicalAddress: onBlock(deps, "other-bucket/object"),
+		}, "application/octet-stream", strings.NewReader("content"))
+		testutil.Must(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	})
+
+	t.Run("missing branch", func(t *testing.T) {
+		resp, err := clt.CreateMultipartUploadWithResponse(ctx, repo, "no-such-branch", &apigen.CreateMultipartUploadParams{Path: "object"})
+		testutil.Must(t, err)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode())
+	})
+}
+
 func TestController_ObjectsDeleteObjectHandler(t *testing.T) {
 	clt, deps := setupClientWithAdmin(t)
 	ctx := context.Background()
@@ -2890,7 +3342,7 @@ func TestController_ConfigHandlers(t *testing.T) {
 
 	t.Run("Get storage config", func(t *testing.T) {
 		ExpectedExample := onBlock(deps, "example-bucket/")
-		resp, err := clt.GetStorageConfigWithResponse(ctx)
+		resp, err := clt.GetStorageConfigWithResponse(ctx, &apigen.GetStorageConfigParams{})
 		verifyResponseOK(t, resp, err)
 
 		example := resp.JSON200.BlockstoreNamespaceExample
@@ -4432,6 +4884,35 @@ func TestController_CopyObjectHandler(t *testing.T) {
 		require.Nil(t, deep.Equal(statResp.JSON200, copyStat))
 	})
 
+	t.Run("different_repository", func(t *testing.T) {
+		const (
+			srcPath  = "foo/bar4"
+			destPath = "foo/bar-from-repository"
+		)
+		srcRepo := testUniqueRepoName()
+		_, err := deps.catalog.CreateRepository(ctx, srcRepo, onBlock(deps, "bucket/other-prefix"), "main")
+		require.NoError(t, err)
+		objStat := uploadContent(t, srcRepo, "main", srcPath)
+		copyResp, err := clt.CopyObjectWithResponse(ctx, repo, "alt", &apigen.CopyObjectParams{
+			DestPath: destPath,
+		}, apigen.CopyObjectJSONRequestBody{
+			SrcPath:       srcPath,
+			SrcRef:        apiutil.Ptr("main"),
+			SrcRepository: apiutil.Ptr(srcRepo),
+		})
+		verifyResponseOK(t, copyResp, err)
+
+		copyStat := copyResp.JSON201
+		require.NotNil(t, copyStat)
+		require.NotEqual(t, objStat.PhysicalAddress, copyStat.PhysicalAddress)
+		require.Equal(t, destPath, copyStat.Path)
+		require.Equal(t, objStat.Checksum, copyStat.Checksum)
+
+		getResp, err := clt.GetObjectWithResponse(ctx, repo, "alt", &apigen.GetObjectParams{Path: destPath})
+		verifyResponseOK(t, getResp, err)
+		require.Equal(t, "hello world this is my awesome content", string(getResp.Body))
+	})
+
 	t.Run("not_found", func(t *testing.T) {
 		resp, err := clt.CopyObjectWithResponse(ctx, repo, "main", &apigen.CopyObjectParams{
 			DestPath: "bar/foo",
@@ -4658,6 +5139,180 @@ func TestController_BranchProtectionRules(t *testing.T) {
 	}
 }
 
+func TestController_CommitSignatures(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t)
+	ctx := context.Background()
+	const committer = "admin"
+
+	newSigner := func() ssh.Signer {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		testutil.Must(t, err)
+		signer, err := ssh.NewSignerFromKey(key)
+		testutil.Must(t, err)
+		return signer
+	}
+	signer := newSigner()
+	otherSigner := newSigner()
+
+	// register the committer's public key
+	addResp, err := clt.AddUserPublicKeyWithResponse(ctx, committer, apigen.AddUserPublicKeyJSONRequestBody{
+		PublicKey: string(ssh.MarshalAuthorizedKey(signer.PublicKey())),
+	})
+	testutil.Must(t, err)
+	require.Equal(t, http.StatusCreated, addResp.StatusCode())
+	fingerprint := ssh.FingerprintSHA256(signer.PublicKey())
+	require.Equal(t, fingerprint, addResp.JSON201.Fingerprint)
+
+	addResp, err = clt.AddUserPublicKeyWithResponse(ctx, committer, apigen.AddUserPublicKeyJSONRequestBody{
+		PublicKey: string(ssh.MarshalAuthorizedKey(signer.PublicKey())),
+	})
+	testutil.Must(t, err)
+	require.Equal(t, http.StatusConflict, addResp.StatusCode())
+
+	addResp, err = clt.AddUserPublicKeyWithResponse(ctx, committer, apigen.AddUserPublicKeyJSONRequestBody{PublicKey: "not a key"})
+	testutil.Must(t, err)
+	require.Equal(t, http.StatusBadRequest, addResp.StatusCode())
+
+	listResp, err := clt.ListUserPublicKeysWithResponse(ctx, committer, &apigen.ListUserPublicKeysParams{})
+	testutil.Must(t, err)
+	require.Equal(t, http.StatusOK, listResp.StatusCode())
+	require.Len(t, listResp.JSON200.Results, 1)
+	require.Equal(t, fingerprint, listResp.JSON200.Results[0].Fingerprint)
+
+	repo := testUniqueRepoName()
+	_, err = deps.catalog.CreateRepository(ctx, repo, onBlock(deps, repo), "main")
+	testutil.Must(t, err)
+	commitOnBranch := func(branch string) string {
+		_, err := deps.catalog.CreateBranch(ctx, repo, branch, "main")
+		testutil.Must(t, err)
+		testutil.MustDo(t, "create entry", deps.catalog.CreateEntry(ctx, repo, branch, catalog.DBEntry{Path: branch + "/obj", PhysicalAddress: branch + "addr", CreationDate: time.Now(), Size: 1, Checksum: "cksum"}))
+		commitLog, err := deps.catalog.Commit(ctx, repo, branch, "commit on "+branch, committer, nil, nil, nil)
+		testutil.Must(t, err)
+		return commitLog.Reference
+	}
+	signedCommitID := commitOnBranch("signed")
+	unsignedCommitID := commitOnBranch("unsigned")
+
+	t.Run("unsigned commit", func(t *testing.T) {
+		resp, err := clt.GetCommitWithResponse(ctx, repo, unsignedCommitID)
+		verifyResponseOK(t, resp, err)
+		require.Nil(t, resp.JSON200.Signature)
+	})
+
+	t.Run("sign with unregistered key", func(t *testing.T) {
+		sig, err := signature.SignCommit(otherSigner, signedCommitID)
+		testutil.Must(t, err)
+		resp, err := clt.SignCommitWithResponse(ctx, repo, signedCommitID, apigen.SignCommitJSONRequestBody{Signature: sig})
+		testutil.Must(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	})
+
+	t.Run("sign another commit ID", func(t *testing.T) {
+		sig, err := signature.SignCommit(signer, unsignedCommitID)
+		testutil.Must(t, err)
+		resp, err := clt.SignCommitWithResponse(ctx, repo, signedCommitID, apigen.SignCommitJSONRequestBody{Signature: sig})
+		testutil.Must(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	})
+
+	t.Run("sign", func(t *testing.T) {
+		sig, err := signature.SignCommit(signer, signedCommitID)
+		testutil.Must(t, err)
+		resp, err := clt.SignCommitWithResponse(ctx, repo, signedCommitID, apigen.SignCommitJSONRequestBody{Signature: sig})
+		testutil.Must(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode())
+		require.Equal(t, fingerprint, resp.JSON201.KeyFingerprint)
+		require.True(t, resp.JSON201.Verified)
+
+		// a commit is signed once
+		resp, err = clt.SignCommitWithResponse(ctx, repo, signedCommitID, apigen.SignCommitJSONRequestBody{Signature: sig})
+		testutil.Must(t, err)
+		require.Equal(t, http.StatusConflict, resp.StatusCode())
+
+		getResp, err := clt.GetCommitWithResponse(ctx, repo, signedCommitID)
+		verifyResponseOK(t, getResp, err)
+		require.NotNil(t, getResp.JSON200.Signature)
+		require.Equal(t, fingerprint, getResp.JSON200.Signature.KeyFingerprint)
+		require.True(t, getResp.JSON200.Signature.Verified)
+
+		logResp, err := clt.LogCommitsWithResponse(ctx, repo, "signed", &apigen.LogCommitsParams{Amount: apiutil.Ptr(apigen.PaginationAmount(1))})
+		verifyResponseOK(t, logResp, err)
+		require.Len(t, logResp.JSON200.Results, 1)
+		require.Nil(t, logResp.JSON200.Results[0].Signature)
+
+		logResp, err = clt.LogCommitsWithResponse(ctx, repo, "signed", &apigen.LogCommitsParams{Amount: apiutil.Ptr(apigen.PaginationAmount(1)), Signatures: swag.Bool(true)})
+		verifyResponseOK(t, logResp, err)
+		require.Len(t, logResp.JSON200.Results, 1)
+		require.NotNil(t, logResp.JSON200.Results[0].Signature)
+		require.True(t, logResp.JSON200.Results[0].Signature.Verified)
+	})
+
+	t.Run("require signed commits", func(t *testing.T) {
+		resp, err := clt.CreateBranchProtectionRuleWithResponse(ctx, repo, apigen.CreateBranchProtectionRuleJSONRequestBody{
+			Pattern:              "main",
+			RequireSignedCommits: swag.Bool(true),
+		})
+		testutil.Must(t, err)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode())
+		rulesResp, err := clt.GetBranchProtectionRulesWithResponse(ctx, repo)
+		verifyResponseOK(t, rulesResp, err)
+		require.Len(t, *rulesResp.JSON200, 1)
+		require.True(t, swag.BoolValue((*rulesResp.JSON200)[0].RequireSignedCommits))
+
+		mergeResp, err := clt.MergeIntoBranchWithResponse(ctx, repo, "unsigned", "main", apigen.MergeIntoBranchJSONRequestBody{})
+		testutil.Must(t, err)
+		require.Equal(t, http.StatusForbidden, mergeResp.StatusCode())
+
+		// main is fast-forwarded to the signed commit
+		mergeResp, err = clt.MergeIntoBranchWithResponse(ctx, repo, "signed", "main", apigen.MergeIntoBranchJSONRequestBody{})
+		verifyResponseOK(t, mergeResp, err)
+		require.Equal(t, signedCommitID, mergeResp.JSON200.Reference)
+		branchResp, err := clt.GetBranchWithResponse(ctx, repo, "main")
+		verifyResponseOK(t, branchResp, err)
+		require.Equal(t, signedCommitID, branchResp.JSON200.CommitId)
+
+		signCommit := func(commitID string) {
+			sig, err := signature.SignCommit(signer, commitID)
+			testutil.Must(t, err)
+			resp, err := clt.SignCommitWithResponse(ctx, repo, commitID, apigen.SignCommitJSONRequestBody{Signature: sig})
+			testutil.Must(t, err)
+			require.Equal(t, http.StatusCreated, resp.StatusCode())
+		}
+
+		// every commit that main gains must be signed, not only the source head
+		commitOnBranch("unsigned-parent")
+		testutil.MustDo(t, "create entry", deps.catalog.CreateEntry(ctx, repo, "unsigned-parent", catalog.DBEntry{Path: "unsigned-parent/child", PhysicalAddress: "childaddr", CreationDate: time.Now(), Size: 1, Checksum: "cksum"}))
+		childLog, err := deps.catalog.Commit(ctx, repo, "unsigned-parent", "child of an unsigned commit", committer, nil, nil, nil)
+		testutil.Must(t, err)
+		signCommit(childLog.Reference)
+		mergeResp, err = clt.MergeIntoBranchWithResponse(ctx, repo, "unsigned-parent", "main", apigen.MergeIntoBranchJSONRequestBody{})
+		testutil.Must(t, err)
+		require.Equal(t, http.StatusForbidden, mergeResp.StatusCode())
+
+		// a diverged source would need an unsigned merge commit
+		signCommit(commitOnBranch("diverged"))
+		aheadCommitID := commitOnBranch("ahead")
+		signCommit(aheadCommitID)
+		mergeResp, err = clt.MergeIntoBranchWithResponse(ctx, repo, "ahead", "main", apigen.MergeIntoBranchJSONRequestBody{})
+		verifyResponseOK(t, mergeResp, err)
+		require.Equal(t, aheadCommitID, mergeResp.JSON200.Reference)
+		mergeResp, err = clt.MergeIntoBranchWithResponse(ctx, repo, "diverged", "main", apigen.MergeIntoBranchJSONRequestBody{})
+		testutil.Must(t, err)
+		require.Equal(t, http.StatusForbidden, mergeResp.StatusCode())
+	})
+
+	t.Run("deleted key", func(t *testing.T) {
+		resp, err := clt.DeleteUserPublicKeyWithResponse(ctx, committer, &apigen.DeleteUserPublicKeyParams{Fingerprint: fingerprint})
+		testutil.Must(t, err)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode())
+
+		getResp, err := clt.GetCommitWithResponse(ctx, repo, signedCommitID)
+		verifyResponseOK(t, getResp, err)
+		require.NotNil(t, getResp.JSON200.Signature)
+		require.False(t, getResp.JSON200.Signature.Verified)
+	})
+}
+
 func TestController_GarbageCollectionRules(t *testing.T) {
 	adminClt, deps := setupClientWithAdmin(t)
 	creds := createUserWithDefaultGroup(t, adminClt)
@@ -4716,3 +5371,342 @@ func TestController_GarbageCollectionRules(t *testing.T) {
 		})
 	}
 }
+
+func TestController_GetReplicationStatus(t *testing.T) {
+	ctx := context.Background()
+	sourceRepo := testUniqueRepoName()
+	targetRepo := testUniqueRepoName()
+	viper.Set("replication.interval", 50*time.Millisecond)
+	viper.Set("replication.rules", []map[string]interface{}{
+		{"source_repository": sourceRepo, "branches": []string{"main"}, "target_repository": targetRepo},
+	})
+	t.Cleanup(func() {
+		viper.Set("replication.rules", nil)
+	})
+	viper.Set(config.BlockstoreTypeKey, block.BlockstoreTypeLocal)
+	viper.Set("blockstore.local.path", t.TempDir())
+	clt, deps := setupClientWithAdmin(t)
+
+	_, err := deps.catalog.CreateRepository(ctx, sourceRepo, onBlock(deps, sourceRepo), "main")
+	require.NoError(t, err)
+	_, err = deps.catalog.CreateBranch(ctx, sourceRepo, "feature", "main")
+	require.NoError(t, err)
+	_, err = deps.catalog.CreateBareRepository(ctx, targetRepo, onBlock(deps, targetRepo), "main")
+	require.NoError(t, err)
+
+	t.Run("not_configured", func(t *testing.T) {
+		resp, err := clt.GetReplicationStatusWithResponse(ctx, targetRepo)
+		require.NoError(t, err)
+		require.NotNil(t, resp.JSON404)
+	})
+
+	t.Run("replicate", func(t *testing.T) {
+		const content = "replicated content"
+		uploadResp, err := uploadObjectHelper(t, ctx, clt, "foo/bar", strings.NewReader(content), sourceRepo, "main")
+		verifyResponseOK(t, uploadResp, err)
+		commit, err := deps.catalog.Commit(ctx, sourceRepo, "main", "add foo/bar", "tester", nil, nil, nil)
+		require.NoError(t, err)
+		_, err = deps.catalog.CreateTag(ctx, sourceRepo, "v1", commit.Reference)
+		require.NoError(t, err)
+
+		var status apigen.ReplicationStatus
+		require.Eventually(t, func() bool {
+			resp, err := clt.GetReplicationStatusWithResponse(ctx, sourceRepo)
+			require.NoError(t, err)
+			require.NotNil(t, resp.JSON200)
+			require.Len(t, resp.JSON200.Results, 1)
+			status = resp.JSON200.Results[0]
+			return status.PendingCommits == 0 && apiutil.Value(status.TargetCommitId) == commit.Reference
+		}, 10*time.Second, 50*time.Millisecond)
+		require.Equal(t, "main", status.Branch)
+		require.Equal(t, targetRepo, status.TargetRepository)
+		require.Equal(t, commit.Reference, status.SourceCommitId)
+		require.Nil(t, status.LastError)
+
+		objResp, err := clt.GetObjectWithResponse(ctx, targetRepo, "main", &apigen.GetObjectParams{Path: "foo/bar"})
+		verifyResponseOK(t, objResp, err)
+		require.Equal(t, content, string(objResp.Body))
+
+		require.Eventually(t, func() bool {
+			tagCommit, err := deps.catalog.GetTag(ctx, targetRepo, "v1")
+			return err == nil && tagCommit == commit.Reference
+		}, 10*time.Second, 50*time.Millisecond)
+
+		exists, err := deps.catalog.BranchExists(ctx, targetRepo, "feature")
+		require.NoError(t, err)
+		require.False(t, exists, "branch not matching replication rule replicated")
+	})
+}
+
+func TestController_RepositoryArchive(t *testing.T) {
+	ctx := context.Background()
+	viper.Set(config.BlockstoreTypeKey, block.BlockstoreTypeLocal)
+	localPath := t.TempDir()
+	viper.Set("blockstore.local.path", localPath)
+	clt, deps := setupClientWithAdmin(t)
+
+	sourceRepo := testUniqueRepoName()
+	_, err := deps.catalog.CreateRepository(ctx, sourceRepo, onBlock(deps, sourceRepo), "main")
+	require.NoError(t, err)
+
+	const content = "archived content"
+	uploadResp, err := uploadObjectHelper(t, ctx, clt, "foo/bar", strings.NewReader(content), sourceRepo, "main")
+	verifyResponseOK(t, uploadResp, err)
+
+	// an object outside the storage namespace, addressed by its full address
+	const externalContent = "external content"
+	externalName := testUniqueRepoName()
+	externalNamespace := onBlock(deps, externalName)
+	externalObj := block.ObjectPointer{StorageNamespace: externalNamespace, Identifier: "obj", IdentifierType: block.IdentifierTypeRelative}
+	require.NoError(t, deps.blocks.Put(ctx, externalObj, int64(len(externalContent)), strings.NewReader(externalContent), block.PutOpts{}))
+	err = deps.catalog.CreateEntry(ctx, sourceRepo, "main", catalog.DBEntry{
+		Path:            "external",
+		PhysicalAddress: onBlock(deps, filepath.Join(localPath, externalName, "obj")),
+		Size:            int64(len(externalContent)),
+		Checksum:        "cafe",
+		AddressType:     catalog.AddressTypeFull,
+	})
+	require.NoError(t, err)
+	commit, err := deps.catalog.Commit(ctx, sourceRepo, "main", "add objects", "tester", nil, nil, nil)
+	require.NoError(t, err)
+	_, err = deps.catalog.CreateTag(ctx, sourceRepo, "v1", commit.Reference)
+	require.NoError(t, err)
+
+	exportResp, err := clt.ExportRepositoryArchiveWithResponse(ctx, sourceRepo, &apigen.ExportRepositoryArchiveParams{
+		IncludeObjects: swag.Bool(true),
+	})
+	verifyResponseOK(t, exportResp, err)
+	archive := exportResp.Body
+	// the archive must not depend on the external object
+	require.NoError(t, deps.blocks.Remove(ctx, externalObj))
+
+	t.Run("import", func(t *testing.T) {
+		targetRepo := testUniqueRepoName()
+		_, err := deps.catalog.CreateBareRepository(ctx, targetRepo, onBlock(deps, targetRepo), "main")
+		require.NoError(t, err)
+
+		importResp, err := clt.ImportRepositoryArchiveWithBodyWithResponse(ctx, targetRepo, "application/octet-stream", bytes.NewReader(archive))
+		verifyResponseOK(t, importResp, err)
+
+		for objPath, expected := range map[string]string{"foo/bar": content, "external": externalContent} {
+			objResp, err := clt.GetObjectWithResponse(ctx, targetRepo, "v1", &apigen.GetObjectParams{Path: objPath})
+			verifyResponseOK(t, objResp, err)
+			require.Equal(t, expected, string(objResp.Body), "object %s", objPath)
+		}
+		mainCommit, err := deps.catalog.GetBranchReference(ctx, targetRepo, "main")
+		require.NoError(t, err)
+		tagCommit, err := deps.catalog.GetTag(ctx, targetRepo, "v1")
+		require.NoError(t, err)
+		require.Equal(t, mainCommit, tagCommit)
+	})
+
+	t.Run("not_bare", func(t *testing.T) {
+		importResp, err := clt.ImportRepositoryArchiveWithBodyWithResponse(ctx, sourceRepo, "application/octet-stream", bytes.NewReader(archive))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, importResp.StatusCode())
+	})
+
+	t.Run("invalid_archive", func(t *testing.T) {
+		targetRepo := testUniqueRepoName()
+		_, err := deps.catalog.CreateBareRepository(ctx, targetRepo, onBlock(deps, targetRepo), "main")
+		require.NoError(t, err)
+		importResp, err := clt.ImportRepositoryArchiveWithBodyWithResponse(ctx, targetRepo, "application/octet-stream", strings.NewReader("not a tar"))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, importResp.StatusCode())
+	})
+}
+
+func TestController_ContinuousImport(t *testing.T) {
+	ctx := context.Background()
+	repo := testUniqueRepoName()
+	eventsDir := t.TempDir()
+	localPath := t.TempDir()
+	externalName := testUniqueRepoName()
+	sourceKey := strings.TrimPrefix(filepath.Join(localPath, externalName), "/") + "/"
+	viper.Set("continuous_import.interval", 50*time.Millisecond)
+	viper.Set("continuous_import.feeds", []map[string]interface{}{
+		{
+			"repository":  repo,
+			"branch":      "ingest",
+			"events_dir":  eventsDir,
+			"source":      "local:///" + sourceKey,
+			"destination": "imported/",
+		},
+	})
+	t.Cleanup(func() {
+		viper.Set("continuous_import.feeds", nil)
+	})
+	viper.Set(config.BlockstoreTypeKey, block.BlockstoreTypeLocal)
+	viper.Set("blockstore.local.path", localPath)
+	clt, deps := setupClientWithAdmin(t)
+
+	_, err := deps.catalog.CreateRepository(ctx, repo, onBlock(deps, repo), "main")
+	require.NoError(t, err)
+	_, err = deps.catalog.CreateBranch(ctx, repo, "ingest", "main")
+	require.NoError(t, err)
+
+	putExternal := func(key, content string) {
+		obj := block.ObjectPointer{StorageNamespace: onBlock(deps, externalName), Identifier: key, IdentifierType: block.IdentifierTypeRelative}
+		require.NoError(t, deps.blocks.Put(ctx, obj, int64(len(content)), strings.NewReader(content), block.PutOpts{}))
+	}
+	writeEvents := func(name string, events ...[2]string) {
+		records := make([]string, 0, len(events))
+		for _, event := range events {
+			records = append(records, fmt.Sprintf(`{"eventName": %q, "s3": {"bucket": {"name": ""}, "object": {"key": %q, "size": 1}}}`, event[0], sourceKey+event[1]))
+		}
+		data := `{"Records": [` + strings.Join(records, ",") + `]}`
+		require.NoError(t, os.WriteFile(filepath.Join(eventsDir, "."+name), []byte(data), 0o600))
+		require.NoError(t, os.Rename(filepath.Join(eventsDir, "."+name), filepath.Join(eventsDir, name)))
+	}
+	objectContent := func(path string) (string, int) {
+		resp, err := clt.GetObjectWithResponse(ctx, repo, "ingest", &apigen.GetObjectParams{Path: path})
+		require.NoError(t, err)
+		return string(resp.Body), resp.StatusCode()
+	}
+
+	putExternal("a", "1")
+	putExternal("ab", "2")
+	putExternal("b", "3")
+	writeEvents("001.json", [2]string{"ObjectCreated:Put", "a"}, [2]string{"ObjectCreated:Put", "ab"}, [2]string{"ObjectCreated:Put", "b"})
+	require.Eventually(t, func() bool {
+		_, status := objectContent("imported/b")
+		return status == http.StatusOK
+	}, 10*time.Second, 50*time.Millisecond)
+	for path, expected := range map[string]string{"imported/a": "1", "imported/ab": "2", "imported/b": "3"} {
+		content, status := objectContent(path)
+		require.Equal(t, http.StatusOK, status, path)
+		require.Equal(t, expected, content, path)
+	}
+
+	putExternal("c", "4")
+	writeEvents("002.json", [2]string{"ObjectRemoved:Delete", "a"}, [2]string{"ObjectCreated:Put", "c"})
+	require.Eventually(t, func() bool {
+		_, status := objectContent("imported/c")
+		return status == http.StatusOK
+	}, 10*time.Second, 50*time.Millisecond)
+	_, status := objectContent("imported/a")
+	require.Equal(t, http.StatusNotFound, status)
+	content, status := objectContent("imported/ab")
+	require.Equal(t, http.StatusOK, status, "object whose path extends the removed path should be kept")
+	require.Equal(t, "2", content)
+
+	commits, _, err := deps.catalog.ListCommits(ctx, repo, "ingest", catalog.LogParams{Amount: 1})
+	require.NoError(t, err)
+	require.Equal(t, catalog.ContinuousImportCommitter, commits[0].Committer)
+	require.Equal(t, "002.json", commits[0].Metadata[catalog.ContinuousImportCursorMetadataKey])
+}
+
+func TestController_CreateRepositoryFromTemplate(t *testing.T) {
+	clt, deps := setupClientWithAdmin(t)
+	ctx := context.Background()
+
+	template := testUniqueRepoName()
+	_, err := deps.catalog.CreateRepository(ctx, template, onBlock(deps, template), "main")
+	testutil.Must(t, err)
+	const content = "seed data"
+	uploadResp, err := uploadObjectHelper(t, ctx, clt, "seed/data.txt", strings.NewReader(content), template, "main")
+	verifyResponseOK(t, uploadResp, err)
+	importedAddress := onBlock(deps, "external") + "/imported/data.txt"
+	testutil.MustDo(t, "create imported entry", deps.catalog.CreateEntry(ctx, template, "main", catalog.DBEntry{Path: "imported/data.txt", PhysicalAddress: importedAddress, AddressType: catalog.AddressTypeFull, CreationDate: time.Now(), Size: 1, Checksum: "cksum"}))
+	commit, err := deps.catalog.Commit(ctx, template, "main", "seed", "some_user", nil, nil, nil)
+	testutil.Must(t, err)
+	testutil.Must(t, deps.catalog.CreateBranchProtectionRule(ctx, template, "main", []graveler.BranchProtectionBlockedAction{graveler.BranchProtectionBlockedAction_STAGING_WRITE}))
+	testutil.Must(t, deps.catalog.SetGarbageCollectionRules(ctx, template, &graveler.GarbageCollectionRules{DefaultRetentionDays: 7}))
+
+	t.Run("create", func(t *testing.T) {
+		repo := testUniqueRepoName()
+		resp, err := clt.CreateRepositoryWithResponse(ctx, &apigen.CreateRepositoryParams{}, apigen.CreateRepositoryJSONRequestBody{
+			Name:             repo,
+			StorageNamespace: onBlock(deps, repo),
+			Template:         &apigen.RepositoryTemplate{Repository: template, Ref: "main"},
+		})
+		verifyResponseOK(t, resp, err)
+		require.Equal(t, "main", resp.JSON201.DefaultBranch)
+
+		// objects of the template are copied into the new storage namespace
+		templateEntry, err := deps.catalog.GetEntry(ctx, template, "main", "seed/data.txt", catalog.GetEntryParams{})
+		testutil.Must(t, err)
+		entry, err := deps.catalog.GetEntry(ctx, repo, "main", "seed/data.txt", catalog.GetEntryParams{})
+		testutil.Must(t, err)
+		require.Equal(t, catalog.AddressTypeRelative, entry.AddressType)
+		require.NotEqual(t, templateEntry.PhysicalAddress, entry.PhysicalAddress)
+		require.Equal(t, int64(len(content)), entry.Size)
+		objResp, err := clt.GetObjectWithResponse(ctx, repo, "main", &apigen.GetObjectParams{Path: "seed/data.txt"})
+		verifyResponseOK(t, objResp, err)
+		require.Equal(t, content, string(objResp.Body))
+
+		// objects outside the template storage namespace are referenced in place
+		importedEntry, err := deps.catalog.GetEntry(ctx, repo, "main", "imported/data.txt", catalog.GetEntryParams{})
+		testutil.Must(t, err)
+		require.Equal(t, catalog.AddressTypeFull, importedEntry.AddressType)
+		require.Equal(t, importedAddress, importedEntry.PhysicalAddress)
+
+		commitResp, err := clt.GetCommitWithResponse(ctx, repo, "main")
+		verifyResponseOK(t, commitResp, err)
+		require.Empty(t, commitResp.JSON200.Parents)
+		require.Equal(t, template, commitResp.JSON200.Metadata.AdditionalProperties[catalog.TemplateRepositoryMetadataKey])
+		require.Equal(t, commit.Reference, commitResp.JSON200.Metadata.AdditionalProperties[catalog.TemplateCommitMetadataKey])
+
+		rulesResp, err := clt.GetBranchProtectionRulesWithResponse(ctx, repo)
+		verifyResponseOK(t, rulesResp, err)
+		require.Len(t, *rulesResp.JSON200, 1)
+		require.Equal(t, "main", (*rulesResp.JSON200)[0].Pattern)
+
+		gcResp, err := clt.GetGarbageCollectionRulesWithResponse(ctx, repo)
+		verifyResponseOK(t, gcResp, err)
+		require.Equal(t, 7, gcResp.JSON200.DefaultRetentionDays)
+	})
+
+	t.Run("missing template", func(t *testing.T) {
+		repo := testUniqueRepoName()
+		resp, err := clt.CreateRepositoryWithResponse(ctx, &apigen.CreateRepositoryParams{}, apigen.CreateRepositoryJSONRequestBody{
+			Name:             repo,
+			StorageNamespace: onBlock(deps, repo),
+			Template:         &apigen.RepositoryTemplate{Repository: testUniqueRepoName(), Ref: "main"},
+		})
+		testutil.Must(t, err)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode())
+	})
+
+	t.Run("with sample data", func(t *testing.T) {
+		repo := testUniqueRepoName()
+		resp, err := clt.CreateRepositoryWithResponse(ctx, &apigen.CreateRepositoryParams{}, apigen.CreateRepositoryJSONRequestBody{
+			Name:             repo,
+			StorageNamespace: onBlock(deps, repo),
+			SampleData:       swag.Bool(true),
+			Template:         &apigen.RepositoryTemplate{Repository: template, Ref: "main"},
+		})
+		testutil.Must(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	})
+
+	t.Run("without read permission", func(t *testing.T) {
+		const username = "template-lister"
+		userResp, err := clt.CreateUserWithResponse(ctx, apigen.CreateUserJSONRequestBody{Id: username})
+		verifyResponseOK(t, userResp, err)
+		// the user may do everything with repositories but read their objects
+		policyResp, err := clt.CreatePolicyWithResponse(ctx, apigen.CreatePolicyJSONRequestBody{
+			Id: "TemplateNoRead",
+			Statement: []apigen.Statement{
+				{Action: []string{"fs:*", "branches:*", "retention:*"}, Effect: "allow", Resource: "*"},
+				{Action: []string{"fs:ReadObject"}, Effect: "deny", Resource: "*"},
+			},
+		})
+		verifyResponseOK(t, policyResp, err)
+		attachResp, err := clt.AttachPolicyToUserWithResponse(ctx, username, "TemplateNoRead")
+		verifyResponseOK(t, attachResp, err)
+		userClt, err := apigen.NewClientWithResponses(deps.server.URL+apiutil.BaseURL, apigen.WithRequestEditorFn(generateJWTToken(deps.authService, username).Intercept))
+		testutil.Must(t, err)
+
+		repo := testUniqueRepoName()
+		resp, err := userClt.CreateRepositoryWithResponse(ctx, &apigen.CreateRepositoryParams{}, apigen.CreateRepositoryJSONRequestBody{
+			Name:             repo,
+			StorageNamespace: onBlock(deps, repo),
+			Template:         &apigen.RepositoryTemplate{Repository: template, Ref: "main"},
+		})
+		testutil.Must(t, err)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+		_, err = deps.catalog.GetRepository(ctx, repo)
+		require.ErrorIs(t, err, graveler.ErrNotFound)
+	})
+}
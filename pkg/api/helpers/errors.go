@@ -25,6 +25,14 @@ var (
 
 const minHTTPErrorStatusCode = 400
 
+// ArchiveErrorTrailer is the HTTP trailer that reports a failure to export
+// a repository archive after the archive started streaming.
+const ArchiveErrorTrailer = "X-Lakefs-Archive-Error"
+
+// ErrArchiveExport is returned for a repository archive whose export failed
+// after it started streaming.
+var ErrArchiveExport = errors.New("archive export failed")
+
 // isOK returns true if statusCode is an OK HTTP status code: 0-399.
 func isOK(statusCode int) bool {
 	return statusCode < minHTTPErrorStatusCode
@@ -176,3 +184,13 @@ func HTTPResponseAsError(httpResponse *http.Response) error {
 		},
 	}
 }
+
+// ArchiveTrailerAsError returns the export failure reported by the trailer
+// of a fully read repository archive response, or nil.
+func ArchiveTrailerAsError(httpResponse *http.Response) error {
+	message := httpResponse.Trailer.Get(ArchiveErrorTrailer)
+	if message == "" {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrArchiveExport, message)
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
+	"github.com/treeverse/lakefs/pkg/auth"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/catalog"
+	"github.com/treeverse/lakefs/pkg/config"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/httputil"
+	"github.com/treeverse/lakefs/pkg/iceberg"
+	"github.com/treeverse/lakefs/pkg/logging"
+	"github.com/treeverse/lakefs/pkg/upload"
+)
+
+// IcebergBaseURL is the prefix of the Iceberg REST catalog served by lakeFS
+const IcebergBaseURL = "/iceberg/api"
+
+// icebergSecurityRequirements are the ways Iceberg clients authenticate: with a token from the OAuth tokens
+// endpoint, or with basic auth of an access key and secret
+var icebergSecurityRequirements = openapi3.SecurityRequirements{
+	{"jwt_token": []string{}},
+	{"basic_auth": []string{}},
+}
+
+// icebergTokenResponse is the OAuth2 token response the Iceberg REST catalog clients expect
+type icebergTokenResponse struct {
+	AccessToken     string `json:"access_token"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int64  `json:"expires_in"`
+	IssuedTokenType string `json:"issued_token_type"`
+}
+
+type icebergOAuthError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func NewIcebergHandler(cfg *config.Config, c catalog.Interface, authenticator auth.Authenticator, authService auth.Service, adapter block.Adapter, pathProvider upload.PathProvider, logger logging.Logger) http.Handler {
+	r := chi.NewRouter()
+	r.Use(httputil.LoggingMiddleware(
+		RequestIDHeaderName,
+		logging.Fields{logging.ServiceNameFieldKey: "iceberg"},
+		cfg.Logging.AuditLogLevel,
+		cfg.Logging.TraceRequestHeaders))
+	r.Post("/v1/oauth/tokens", icebergTokensHandler(cfg, authenticator, authService, logger))
+	r.With(icebergAuthMiddleware(authenticator, authService, logger)).
+		Mount("/", iceberg.NewHandler(c, adapter, pathProvider, authService, logger))
+	return r
+}
+
+func icebergAuthMiddleware(authenticator auth.Authenticator, authService auth.Service, logger logging.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := checkSecurityRequirements(r, icebergSecurityRequirements, logger, authenticator, authService, nil, nil, nil)
+			if err == nil && user == nil {
+				err = ErrAuthenticatingRequest
+			}
+			if err != nil {
+				iceberg.WriteError(w, fmt.Errorf("%w: %s", iceberg.ErrNotAuthorized, err))
+				return
+			}
+			ctx := logging.AddFields(r.Context(), logging.Fields{logging.UserFieldKey: user.Username})
+			ctx = graveler.WithReflogUser(ctx, user.Username)
+			next.ServeHTTP(w, r.WithContext(auth.WithUser(ctx, user)))
+		})
+	}
+}
+
+// icebergTokensHandler exchanges the access key and secret of a user, passed as OAuth2 client credentials, for a
+// login token
+func icebergTokensHandler(cfg *config.Config, authenticator auth.Authenticator, authService auth.Service, logger logging.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			writeResponse(w, r, http.StatusBadRequest, icebergOAuthError{Error: "invalid_request", ErrorDescription: err.Error()})
+			return
+		}
+		if grantType := r.PostForm.Get("grant_type"); grantType != "client_credentials" {
+			writeResponse(w, r, http.StatusBadRequest, icebergOAuthError{
+				Error:            "unsupported_grant_type",
+				ErrorDescription: "unsupported grant type " + grantType,
+			})
+			return
+		}
+		ctx := r.Context()
+		user, err := userByAuth(ctx, logger, authenticator, authService, r.PostForm.Get("client_id"), r.PostForm.Get("client_secret"))
+		if errors.Is(err, ErrAuthenticatingRequest) {
+			writeResponse(w, r, http.StatusUnauthorized, icebergOAuthError{Error: "invalid_client", ErrorDescription: err.Error()})
+			return
+		}
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		issuedAt := time.Now()
+		duration := cfg.Auth.LoginDuration
+		token, err := GenerateJWTLogin(authService.SecretStore().SharedSecret(), user.Username, issuedAt, issuedAt.Add(duration))
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		writeResponse(w, r, http.StatusOK, icebergTokenResponse{
+			AccessToken:     token,
+			TokenType:       "bearer",
+			ExpiresIn:       int64(duration.Seconds()),
+			IssuedTokenType: "urn:ietf:params:oauth:token-type:access_token",
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/treeverse/lakefs/pkg/api"
+	"github.com/treeverse/lakefs/pkg/catalog"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/testutil"
+)
+
+type icebergClient struct {
+	url   string
+	token string
+}
+
+func (c *icebergClient) do(t *testing.T, method, path string, body interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		testutil.Must(t, err)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, c.url+api.IcebergBaseURL+path, reader)
+	testutil.Must(t, err)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	testutil.Must(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	var res map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&res)
+	return resp.StatusCode, res
+}
+
+func (c *icebergClient) mustDo(t *testing.T, method, path string, body interface{}, expectedCode int) map[string]interface{} {
+	t.Helper()
+	code, res := c.do(t, method, path, body)
+	if code != expectedCode {
+		t.Fatalf("%s %s: status code %d, expected %d: %v", method, path, code, expectedCode, res)
+	}
+	return res
+}
+
+func icebergNamespace(levels ...string) string {
+	return url.PathEscape(strings.Join(levels, "\x1f"))
+}
+
+func TestIcebergCatalog(t *testing.T) {
+	handler, deps := setupHandler(t)
+	server := setupServer(t, handler)
+	clt := setupClientByEndpoint(t, server.URL, "", "")
+	cred := createDefaultAdminUser(t, clt)
+	ctx := context.Background()
+	_, err := deps.catalog.CreateRepository(ctx, "iceberg-repo", onBlock(deps, "iceberg-repo"), "main")
+	testutil.Must(t, err)
+
+	c := &icebergClient{url: server.URL}
+	c.mustDo(t, http.MethodGet, "/v1/namespaces", nil, http.StatusUnauthorized)
+
+	// get a token with the access key and secret as OAuth client credentials
+	tokenResp, err := http.PostForm(server.URL+api.IcebergBaseURL+"/v1/oauth/tokens", url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {cred.AccessKeyID},
+		"client_secret": {"wrong"},
+	})
+	testutil.Must(t, err)
+	_ = tokenResp.Body.Close()
+	if tokenResp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("tokens with wrong secret: status code %d, expected %d", tokenResp.StatusCode, http.StatusUnauthorized)
+	}
+	tokenResp, err = http.PostForm(server.URL+api.IcebergBaseURL+"/v1/oauth/tokens", url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {cred.AccessKeyID},
+		"client_secret": {cred.SecretAccessKey},
+	})
+	testutil.Must(t, err)
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	testutil.Must(t, json.NewDecoder(tokenResp.Body).Decode(&token))
+	_ = tokenResp.Body.Close()
+	c.token = token.AccessToken
+
+	t.Run("namespaces", func(t *testing.T) {
+		res := c.mustDo(t, http.MethodGet, "/v1/namespaces?parent="+url.QueryEscape("iceberg-repo"), nil, http.StatusOK)
+		if ns, _ := json.Marshal(res["namespaces"]); string(ns) != `[["iceberg-repo","main"]]` {
+			t.Fatalf("namespaces of repository = %s", ns)
+		}
+		c.mustDo(t, http.MethodPost, "/v1/namespaces", map[string]interface{}{
+			"namespace": []string{"iceberg-repo", "main", "db"},
+		}, http.StatusOK)
+		c.mustDo(t, http.MethodPost, "/v1/namespaces", map[string]interface{}{
+			"namespace": []string{"iceberg-repo", "main", "db"},
+		}, http.StatusConflict)
+		c.mustDo(t, http.MethodPost, "/v1/namespaces", map[string]interface{}{
+			"namespace": []string{"iceberg-repo", "main", "missing", "child"},
+		}, http.StatusNotFound)
+		res = c.mustDo(t, http.MethodGet, "/v1/namespaces?parent="+url.QueryEscape("iceberg-repo\x1fmain"), nil, http.StatusOK)
+		if ns, _ := json.Marshal(res["namespaces"]); string(ns) != `[["iceberg-repo","main","db"]]` {
+			t.Fatalf("namespaces of branch = %s", ns)
+		}
+		res = c.mustDo(t, http.MethodPost, "/v1/namespaces/"+icebergNamespace("iceberg-repo", "main", "db")+"/properties", map[string]interface{}{
+			"updates":  map[string]string{"owner": "data"},
+			"removals": []string{"missing"},
+		}, http.StatusOK)
+		if updated, _ := json.Marshal(res["updated"]); string(updated) != `["owner"]` {
+			t.Fatalf("updated properties = %s", updated)
+		}
+		res = c.mustDo(t, http.MethodGet, "/v1/namespaces/"+icebergNamespace("iceberg-repo", "main", "db"), nil, http.StatusOK)
+		if properties, _ := json.Marshal(res["properties"]); string(properties) != `{"owner":"data"}` {
+			t.Fatalf("namespace properties = %s", properties)
+		}
+	})
+
+	tablePath := "/v1/namespaces/" + icebergNamespace("iceberg-repo", "main", "db") + "/tables"
+	t.Run("create table", func(t *testing.T) {
+		res := c.mustDo(t, http.MethodPost, tablePath, map[string]interface{}{
+			"name": "events",
+			"schema": map[string]interface{}{
+				"type": "struct",
+				"fields": []interface{}{
+					map[string]interface{}{"id": 1, "name": "id", "type": "long", "required": true},
+				},
+			},
+		}, http.StatusOK)
+		if res["metadata-location"] != "s3://iceberg-repo/main/db/events/metadata/v1.metadata.json" {
+			t.Fatalf("metadata location = %v", res["metadata-location"])
+		}
+		c.mustDo(t, http.MethodPost, tablePath, map[string]interface{}{
+			"name":   "events",
+			"schema": map[string]interface{}{"type": "struct", "fields": []interface{}{}},
+		}, http.StatusConflict)
+		res = c.mustDo(t, http.MethodGet, tablePath, nil, http.StatusOK)
+		if ids, _ := json.Marshal(res["identifiers"]); string(ids) != `[{"name":"events","namespace":["iceberg-repo","main","db"]}]` {
+			t.Fatalf("tables = %s", ids)
+		}
+	})
+
+	t.Run("commit table", func(t *testing.T) {
+		commit := map[string]interface{}{
+			"requirements": []interface{}{
+				map[string]interface{}{"type": "assert-ref-snapshot-id", "ref": "main", "snapshot-id": nil},
+			},
+			"updates": []interface{}{
+				map[string]interface{}{"action": "add-snapshot", "snapshot": map[string]interface{}{
+					"snapshot-id": 1, "sequence-number": 1, "timestamp-ms": 1672531200000,
+					"summary": map[string]string{"operation": "append"}, "manifest-list": "s3://iceberg-repo/main/db/events/metadata/snap-1.avro",
+				}},
+				map[string]interface{}{"action": "set-snapshot-ref", "ref-name": "main", "type": "branch", "snapshot-id": 1},
+			},
+		}
+		res := c.mustDo(t, http.MethodPost, tablePath+"/events", commit, http.StatusOK)
+		if res["metadata-location"] != "s3://iceberg-repo/main/db/events/metadata/v2.metadata.json" {
+			t.Fatalf("metadata location = %v", res["metadata-location"])
+		}
+		// the requirement no longer holds
+		c.mustDo(t, http.MethodPost, tablePath+"/events", commit, http.StatusConflict)
+
+		log, _, err := deps.catalog.ListCommits(ctx, "iceberg-repo", "main", catalog.LogParams{Amount: 1})
+		testutil.Must(t, err)
+		if len(log) == 0 || log[0].Metadata["iceberg_identifier"] != "iceberg-repo.main.db.events" {
+			t.Fatalf("last commit = %+v, expected an Iceberg commit", log)
+		}
+	})
+
+	t.Run("commit table changes only", func(t *testing.T) {
+		testutil.Must(t, deps.catalog.CreateEntry(ctx, "iceberg-repo", "main", catalog.DBEntry{
+			Path:            "staged/object",
+			PhysicalAddress: "staged-object",
+			Checksum:        "checksum",
+		}))
+		setProperty := map[string]interface{}{
+			"updates": []interface{}{
+				map[string]interface{}{"action": "set-properties", "updates": map[string]string{"staged": "false"}},
+			},
+		}
+		res := c.mustDo(t, http.MethodPost, tablePath+"/events", setProperty, http.StatusOK)
+		if res["metadata-location"] != "s3://iceberg-repo/main/db/events/metadata/v3.metadata.json" {
+			t.Fatalf("metadata location = %v", res["metadata-location"])
+		}
+		// the object staged by another user is neither committed nor unstaged
+		head, err := deps.catalog.GetBranchReference(ctx, "iceberg-repo", "main")
+		testutil.Must(t, err)
+		if _, err := deps.catalog.GetEntry(ctx, "iceberg-repo", head, "staged/object", catalog.GetEntryParams{}); !errors.Is(err, graveler.ErrNotFound) {
+			t.Fatalf("staged object on the Iceberg commit: err = %v, expected %v", err, graveler.ErrNotFound)
+		}
+		testutil.Must(t, deps.catalog.ResetEntry(ctx, "iceberg-repo", "main", "staged/object"))
+	})
+
+	t.Run("failed table commit", func(t *testing.T) {
+		testutil.Must(t, deps.catalog.CreateBranchProtectionRule(ctx, "iceberg-repo", "main", []graveler.BranchProtectionBlockedAction{graveler.BranchProtectionBlockedAction_COMMIT}))
+		commit := map[string]interface{}{
+			"updates": []interface{}{
+				map[string]interface{}{"action": "set-properties", "updates": map[string]string{"failed": "false"}},
+			},
+		}
+		if code, res := c.do(t, http.MethodPost, tablePath+"/events", commit); code == http.StatusOK {
+			t.Fatalf("commit to a protected branch succeeded: %v", res)
+		}
+		testutil.Must(t, deps.catalog.DeleteBranchProtectionRule(ctx, "iceberg-repo", "main"))
+		// nothing was left staged by the failed commit, the next commit writes the same version
+		diff, _, err := deps.catalog.DiffUncommitted(ctx, "iceberg-repo", "main", "", "", 1, "")
+		testutil.Must(t, err)
+		if len(diff) != 0 {
+			t.Fatalf("uncommitted changes after a failed commit: %+v", diff)
+		}
+		res := c.mustDo(t, http.MethodPost, tablePath+"/events", commit, http.StatusOK)
+		if res["metadata-location"] != "s3://iceberg-repo/main/db/events/metadata/v4.metadata.json" {
+			t.Fatalf("metadata location = %v", res["metadata-location"])
+		}
+	})
+
+	t.Run("concurrent table commits", func(t *testing.T) {
+		const commits = 3
+		var wg sync.WaitGroup
+		codes := make([]int, commits)
+		for i := 0; i < commits; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				codes[i], _ = c.do(t, http.MethodPost, tablePath+"/events", map[string]interface{}{
+					"updates": []interface{}{
+						map[string]interface{}{"action": "set-properties", "updates": map[string]string{"writer" + strconv.Itoa(i): "true"}},
+					},
+				})
+			}(i)
+		}
+		wg.Wait()
+		for i, code := range codes {
+			if code != http.StatusOK {
+				t.Fatalf("concurrent commit %d: status code %d, expected %d", i, code, http.StatusOK)
+			}
+		}
+		// every commit applied its update on top of the others
+		res := c.mustDo(t, http.MethodGet, tablePath+"/events", nil, http.StatusOK)
+		if res["metadata-location"] != "s3://iceberg-repo/main/db/events/metadata/v7.metadata.json" {
+			t.Fatalf("metadata location = %v", res["metadata-location"])
+		}
+		metadata, _ := res["metadata"].(map[string]interface{})
+		properties, _ := metadata["properties"].(map[string]interface{})
+		for i := 0; i < commits; i++ {
+			if properties["writer"+strconv.Itoa(i)] != "true" {
+				t.Fatalf("properties = %v, missing the update of commit %d", properties, i)
+			}
+		}
+	})
+
+	t.Run("commit staged table objects", func(t *testing.T) {
+		// the engine writes a data file through the S3 gateway before it commits the table
+		testutil.Must(t, deps.catalog.CreateEntry(ctx, "iceberg-repo", "main", catalog.DBEntry{
+			Path:            "db/events/data/00000-0.parquet",
+			PhysicalAddress: "data-file",
+			Checksum:        "checksum",
+			Size:            10,
+		}))
+		c.mustDo(t, http.MethodPost, tablePath+"/events", map[string]interface{}{
+			"updates": []interface{}{
+				map[string]interface{}{"action": "set-properties", "updates": map[string]string{"data": "true"}},
+			},
+		}, http.StatusOK)
+		head, err := deps.catalog.GetBranchReference(ctx, "iceberg-repo", "main")
+		testutil.Must(t, err)
+		diff, _, err := deps.catalog.DiffUncommitted(ctx, "iceberg-repo", "main", "", "", 1, "")
+		testutil.Must(t, err)
+		if len(diff) != 0 {
+			t.Fatalf("uncommitted changes after a table commit: %+v", diff)
+		}
+
+		// the table and its data file are read by commit ID
+		commitPath := "/v1/namespaces/" + icebergNamespace("iceberg-repo", head, "db") + "/tables/events"
+		res := c.mustDo(t, http.MethodGet, commitPath, nil, http.StatusOK)
+		metadata, _ := res["metadata"].(map[string]interface{})
+		properties, _ := metadata["properties"].(map[string]interface{})
+		if properties["data"] != "true" {
+			t.Fatalf("properties at commit = %v", properties)
+		}
+		if _, err := deps.catalog.GetEntry(ctx, "iceberg-repo", head, "db/events/data/00000-0.parquet", catalog.GetEntryParams{}); err != nil {
+			t.Fatalf("data file at commit %s: %s", head, err)
+		}
+	})
+
+	t.Run("branch", func(t *testing.T) {
+		_, err := deps.catalog.CreateBranch(ctx, "iceberg-repo", "dev", "main")
+		testutil.Must(t, err)
+		devPath := "/v1/namespaces/" + icebergNamespace("iceberg-repo", "dev", "db") + "/tables/events"
+		res := c.mustDo(t, http.MethodGet, devPath, nil, http.StatusOK)
+		metadata, _ := res["metadata"].(map[string]interface{})
+		if metadata["location"] != "s3://iceberg-repo/dev/db/events" {
+			t.Fatalf("location on branch = %v", metadata["location"])
+		}
+		if metadata["current-snapshot-id"] != float64(1) {
+			t.Fatalf("current snapshot on branch = %v", metadata["current-snapshot-id"])
+		}
+		c.mustDo(t, http.MethodDelete, devPath, nil, http.StatusNoContent)
+		c.mustDo(t, http.MethodGet, devPath, nil, http.StatusNotFound)
+		// dropping the table on the branch leaves it on main
+		c.mustDo(t, http.MethodGet, tablePath+"/events", nil, http.StatusOK)
+	})
+
+	t.Run("rename table", func(t *testing.T) {
+		c.mustDo(t, http.MethodPost, "/v1/tables/rename", map[string]interface{}{
+			"source":      map[string]interface{}{"namespace": []string{"iceberg-repo", "main", "db"}, "name": "events"},
+			"destination": map[string]interface{}{"namespace": []string{"iceberg-repo", "main", "db"}, "name": "clicks"},
+		}, http.StatusNoContent)
+		c.mustDo(t, http.MethodGet, tablePath+"/events", nil, http.StatusNotFound)
+		res := c.mustDo(t, http.MethodGet, tablePath+"/clicks", nil, http.StatusOK)
+		if res["metadata-location"] != "s3://iceberg-repo/main/db/clicks/metadata/v8.metadata.json" {
+			t.Fatalf("metadata location = %v", res["metadata-location"])
+		}
+		c.mustDo(t, http.MethodDelete, "/v1/namespaces/"+icebergNamespace("iceberg-repo", "main", "db"), nil, http.StatusConflict)
+	})
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"io"
+
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/catalog"
+)
+
+// catalogTableReader reads table files for the built-in table diffs directly from the catalog and the blockstore
+type catalogTableReader struct {
+	catalog catalog.Interface
+	adapter block.Adapter
+}
+
+func (t *catalogTableReader) ListObjects(ctx context.Context, repository, ref, prefix string) ([]string, error) {
+	var (
+		paths []string
+		after string
+	)
+	for {
+		entries, hasMore, err := t.catalog.ListEntries(ctx, repository, ref, prefix, after, "", catalog.ListEntriesLimitMax)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			paths = append(paths, entry.Path)
+		}
+		if !hasMore || len(entries) == 0 {
+			return paths, nil
+		}
+		after = entries[len(entries)-1].Path
+	}
+}
+
+func (t *catalogTableReader) GetObject(ctx context.Context, repository, ref, path string) (io.ReadCloser, error) {
+	repo, err := t.catalog.GetRepository(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := t.catalog.GetEntry(ctx, repository, ref, path, catalog.GetEntryParams{})
+	if err != nil {
+		return nil, err
+	}
+	return t.adapter.Get(ctx, block.ObjectPointer{
+		StorageNamespace: repo.StorageNamespace,
+		IdentifierType:   entry.AddressType.ToIdentifierType(),
+		Identifier:       entry.PhysicalAddress,
+	}, entry.Size)
+}
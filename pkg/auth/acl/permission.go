@@ -2,6 +2,7 @@ package acl
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/treeverse/lakefs/pkg/auth"
 	"github.com/treeverse/lakefs/pkg/auth/model"
@@ -30,18 +31,70 @@ var (
 	ownUserARN = []string{permissions.UserArn("${user}")}
 	all        = []string{permissions.All}
 
-	ErrBadACLPermission = fmt.Errorf("%w: Bad ACL permission", model.ErrValidationError)
+	ErrBadACLPermission   = fmt.Errorf("%w: Bad ACL permission", model.ErrValidationError)
+	ErrBadACLRepositories = fmt.Errorf("%w: Bad ACL repositories", model.ErrValidationError)
 )
 
+// repositoriesResources returns the resources covering repositories and
+// everything inside them.  It returns all resources if repositories is
+// empty.
+func repositoriesResources(repositories []string) ([]string, error) {
+	if len(repositories) == 0 {
+		return all, nil
+	}
+	resources := make([]string, 0, 2*len(repositories)) //nolint:gomnd
+	for _, repository := range repositories {
+		if repository == "" || strings.Contains(repository, "/") {
+			return nil, fmt.Errorf("%w \"%s\"", ErrBadACLRepositories, repository)
+		}
+		resources = append(resources,
+			permissions.RepoArn(repository),
+			permissions.RepoArn(repository)+"/*",
+		)
+	}
+	return resources, nil
+}
+
+// repositoriesScopedStatements returns statements required for using an
+// ACL with permission scoped to some repositories: these allow listing
+// repositories and reading configuration, which are installation-wide
+// resources.  ACLSuper also allows attaching storage namespaces, so that
+// repositories can be created.
+func repositoriesScopedStatements(permission model.ACLPermission) (model.Statements, error) {
+	policyTypes := []string{"FSListRepositories"}
+	switch permission {
+	case ACLWrite:
+		policyTypes = append(policyTypes, "FSReadConfig")
+	case ACLSuper:
+		policyTypes = append(policyTypes, "FSReadConfig", "FSAttachStorageNamespace")
+	}
+	var statements model.Statements
+	for _, policyType := range policyTypes {
+		statement, err := auth.MakeStatementForPolicyType(policyType, all)
+		if err != nil {
+			return nil, fmt.Errorf("get %s: %w", policyType, err)
+		}
+		statements = append(statements, statement...)
+	}
+	return statements, nil
+}
+
+// ACLToStatement returns the statements of a policy that implements acl.
+// Statements on repository data are limited to acl.Repositories, if any.
 func ACLToStatement(acl model.ACL) (model.Statements, error) {
 	var (
 		statements model.Statements
 		err        error
 	)
 
+	resources, err := repositoriesResources(acl.Repositories)
+	if err != nil {
+		return nil, err
+	}
+
 	switch acl.Permission {
 	case ACLRead:
-		statements, err = auth.MakeStatementForPolicyType("FSRead", all)
+		statements, err = auth.MakeStatementForPolicyType("FSRead", resources)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", acl.Permission, ErrBadACLPermission)
 		}
@@ -56,7 +109,7 @@ func ACLToStatement(acl model.ACL) (model.Statements, error) {
 		}
 		statements = append(append(statements, readConfigStatement...), ownCredentialsStatement...)
 	case ACLWrite:
-		statements, err = auth.MakeStatementForPolicyType("FSReadWrite", all)
+		statements, err = auth.MakeStatementForPolicyType("FSReadWrite", resources)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", acl.Permission, ErrBadACLPermission)
 		}
@@ -66,14 +119,14 @@ func ACLToStatement(acl model.ACL) (model.Statements, error) {
 			return nil, err
 		}
 
-		ciStatement, err := auth.MakeStatementForPolicyType("RepoManagementRead", all)
+		ciStatement, err := auth.MakeStatementForPolicyType("RepoManagementRead", resources)
 		if err != nil {
 			return nil, fmt.Errorf("%s: get RepoManagementRead: %w", acl.Permission, ErrBadACLPermission)
 		}
 
 		statements = append(statements, append(ownCredentialsStatement, ciStatement...)...)
 	case ACLSuper:
-		statements, err = auth.MakeStatementForPolicyType("FSFullAccess", all)
+		statements, err = auth.MakeStatementForPolicyType("FSFullAccess", resources)
 		if err != nil {
 			return nil, fmt.Errorf("%s: get FSFullAccess: %w", acl.Permission, ErrBadACLPermission)
 		}
@@ -83,13 +136,16 @@ func ACLToStatement(acl model.ACL) (model.Statements, error) {
 			return nil, fmt.Errorf("%s: get AuthManageOwnCredentials: %w", acl.Permission, ErrBadACLPermission)
 		}
 
-		ciStatement, err := auth.MakeStatementForPolicyType("RepoManagementRead", all)
+		ciStatement, err := auth.MakeStatementForPolicyType("RepoManagementRead", resources)
 		if err != nil {
 			return nil, fmt.Errorf("%s: get RepoManagementRead: %w", acl.Permission, ErrBadACLPermission)
 		}
 
 		statements = append(statements, append(ownCredentialsStatement, ciStatement...)...)
 	case ACLAdmin:
+		if len(acl.Repositories) > 0 {
+			return nil, fmt.Errorf("%s applies to all repositories: %w", acl.Permission, ErrBadACLRepositories)
+		}
 		statements, err = auth.MakeStatementForPolicyType("AllAccess", []string{permissions.All})
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", acl.Permission, ErrBadACLPermission)
@@ -98,5 +154,13 @@ func ACLToStatement(acl model.ACL) (model.Statements, error) {
 		return nil, fmt.Errorf("%w \"%s\"", ErrBadACLPermission, acl.Permission)
 	}
 
+	if len(acl.Repositories) > 0 {
+		scopedStatements, err := repositoriesScopedStatements(acl.Permission)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", acl.Permission, err)
+		}
+		statements = append(statements, scopedStatements...)
+	}
+
 	return statements, nil
 }
@@ -0,0 +1,116 @@
+package acl_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/treeverse/lakefs/pkg/auth/acl"
+	"github.com/treeverse/lakefs/pkg/auth/model"
+	"github.com/treeverse/lakefs/pkg/auth/wildcard"
+	"github.com/treeverse/lakefs/pkg/permissions"
+)
+
+// allows returns true if some statement allows action on resource.
+func allows(statements model.Statements, action, resource string) bool {
+	for _, s := range statements {
+		if s.Effect != model.StatementEffectAllow || !wildcard.Match(s.Resource, resource) {
+			continue
+		}
+		for _, a := range s.Action {
+			if wildcard.Match(a, action) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestACLToStatement_Repositories(t *testing.T) {
+	type check struct {
+		Action   string
+		Resource string
+		Allowed  bool
+	}
+	cases := []struct {
+		Name   string
+		ACL    model.ACL
+		Checks []check
+	}{
+		{
+			Name: "read all",
+			ACL:  model.ACL{Permission: acl.ACLRead},
+			Checks: []check{
+				{Action: permissions.ListRepositoriesAction, Resource: permissions.All, Allowed: true},
+				{Action: permissions.ReadObjectAction, Resource: permissions.ObjectArn("any", "a/b"), Allowed: true},
+				{Action: permissions.WriteObjectAction, Resource: permissions.ObjectArn("any", "a/b"), Allowed: false},
+			},
+		},
+		{
+			Name: "read some",
+			ACL:  model.ACL{Permission: acl.ACLRead, Repositories: []string{"team-a-*", "shared"}},
+			Checks: []check{
+				{Action: permissions.ListRepositoriesAction, Resource: permissions.All, Allowed: true},
+				{Action: permissions.ReadConfigAction, Resource: permissions.All, Allowed: true},
+				{Action: permissions.ReadRepositoryAction, Resource: permissions.RepoArn("team-a-data"), Allowed: true},
+				{Action: permissions.ReadObjectAction, Resource: permissions.ObjectArn("team-a-data", "a/b"), Allowed: true},
+				{Action: permissions.ReadBranchAction, Resource: permissions.BranchArn("shared", "main"), Allowed: true},
+				{Action: permissions.ReadRepositoryAction, Resource: permissions.RepoArn("team-b-data"), Allowed: false},
+				{Action: permissions.ReadObjectAction, Resource: permissions.ObjectArn("shared-not", "a/b"), Allowed: false},
+			},
+		},
+		{
+			Name: "write some",
+			ACL:  model.ACL{Permission: acl.ACLWrite, Repositories: []string{"team-a-*"}},
+			Checks: []check{
+				{Action: permissions.ReadConfigAction, Resource: permissions.All, Allowed: true},
+				{Action: permissions.WriteObjectAction, Resource: permissions.ObjectArn("team-a-data", "a/b"), Allowed: true},
+				{Action: permissions.CreateCommitAction, Resource: permissions.BranchArn("team-a-data", "main"), Allowed: true},
+				{Action: permissions.WriteObjectAction, Resource: permissions.ObjectArn("team-b-data", "a/b"), Allowed: false},
+				{Action: permissions.CreateRepositoryAction, Resource: permissions.RepoArn("team-a-new"), Allowed: false},
+			},
+		},
+		{
+			Name: "super some",
+			ACL:  model.ACL{Permission: acl.ACLSuper, Repositories: []string{"team-a-*"}},
+			Checks: []check{
+				{Action: permissions.CreateRepositoryAction, Resource: permissions.RepoArn("team-a-new"), Allowed: true},
+				{Action: permissions.AttachStorageNamespaceAction, Resource: permissions.StorageNamespace("s3://bucket/a"), Allowed: true},
+				{Action: permissions.DeleteRepositoryAction, Resource: permissions.RepoArn("team-b-data"), Allowed: false},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			statements, err := acl.ACLToStatement(tc.ACL)
+			if err != nil {
+				t.Fatalf("ACLToStatement(%+v): %s", tc.ACL, err)
+			}
+			for _, c := range tc.Checks {
+				if allowed := allows(statements, c.Action, c.Resource); allowed != c.Allowed {
+					t.Errorf("%s on %s: got allowed %t, expected %t", c.Action, c.Resource, allowed, c.Allowed)
+				}
+			}
+		})
+	}
+}
+
+func TestACLToStatement_BadRepositories(t *testing.T) {
+	cases := []struct {
+		Name string
+		ACL  model.ACL
+	}{
+		{Name: "admin", ACL: model.ACL{Permission: acl.ACLAdmin, Repositories: []string{"repo"}}},
+		{Name: "empty", ACL: model.ACL{Permission: acl.ACLRead, Repositories: []string{""}}},
+		{Name: "slash", ACL: model.ACL{Permission: acl.ACLWrite, Repositories: []string{"repo/branch"}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := acl.ACLToStatement(tc.ACL)
+			if !errors.Is(err, acl.ErrBadACLRepositories) {
+				t.Errorf("ACLToStatement(%+v): got error %v, expected %s", tc.ACL, err, acl.ErrBadACLRepositories)
+			}
+		})
+	}
+}
@@ -47,6 +47,18 @@ var statementByName = map[string]model.Statement{
 		},
 		Effect: model.StatementEffectAllow,
 	},
+	"FSListRepositories": {
+		Action: []string{
+			permissions.ListRepositoriesAction,
+		},
+		Effect: model.StatementEffectAllow,
+	},
+	"FSAttachStorageNamespace": {
+		Action: []string{
+			permissions.AttachStorageNamespaceAction,
+		},
+		Effect: model.StatementEffectAllow,
+	},
 	"FSRead": {
 		Action: []string{
 			"fs:List*",
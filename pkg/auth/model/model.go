@@ -30,6 +30,8 @@ const (
 	usersPoliciesPrefix    = "uPolicies"
 	usersCredentialsPrefix = "uCredentials" // #nosec G101 -- False positive: this is only a kv key prefix
 	credentialsPrefix      = "credentials"
+	usersPublicKeysPrefix  = "uPublicKeys"
+	publicKeysPrefix       = "publicKeys"
 	expiredTokensPrefix    = "expiredTokens"
 	metadataPrefix         = "installation_metadata"
 )
@@ -40,6 +42,7 @@ func init() {
 	kv.MustRegisterType("auth", "policies", (&PolicyData{}).ProtoReflect().Type())
 	kv.MustRegisterType("auth", "groups", (&GroupData{}).ProtoReflect().Type())
 	kv.MustRegisterType("auth", kv.FormatPath("uCredentials", "*", "credentials"), (&CredentialData{}).ProtoReflect().Type())
+	kv.MustRegisterType("auth", kv.FormatPath("uPublicKeys", "*", "publicKeys"), (&PublicKeyData{}).ProtoReflect().Type())
 	kv.MustRegisterType("auth", kv.FormatPath("gUsers", "*", "users"), (&kv.SecondaryIndex{}).ProtoReflect().Type())
 	kv.MustRegisterType("auth", kv.FormatPath("gPolicies", "*", "policies"), (&kv.SecondaryIndex{}).ProtoReflect().Type())
 	kv.MustRegisterType("auth", kv.FormatPath("uPolicies", "*", "policies"), (&kv.SecondaryIndex{}).ProtoReflect().Type())
@@ -63,6 +66,10 @@ func CredentialPath(userName string, accessKeyID string) []byte {
 	return []byte(kv.FormatPath(usersCredentialsPrefix, userName, credentialsPrefix, accessKeyID))
 }
 
+func PublicKeyPath(userName string, fingerprint string) []byte {
+	return []byte(kv.FormatPath(usersPublicKeysPrefix, userName, publicKeysPrefix, fingerprint))
+}
+
 func GroupUserPath(groupDisplayName string, userName string) []byte {
 	return []byte(kv.FormatPath(groupsUsersPrefix, groupDisplayName, usersPrefix, userName))
 }
@@ -147,6 +154,10 @@ type ACLPermission string
 
 type ACL struct {
 	Permission ACLPermission `json:"permission"`
+	// Repositories holds names or wildcard patterns of the repositories
+	// to which Permission applies.  An empty list applies Permission to
+	// all repositories.
+	Repositories []string `json:"repositories,omitempty"`
 }
 
 type Policy struct {
@@ -186,6 +197,15 @@ type DBCredential struct {
 	BaseCredential
 }
 
+// PublicKey is an SSH public key of a user, used to verify the signatures of the user's commits
+type PublicKey struct {
+	Username    string
+	Fingerprint string
+	// PublicKey is the key in the OpenSSH authorized_keys format
+	PublicKey string
+	CreatedAt time.Time
+}
+
 // CredentialKeys - For JSON serialization:
 type CredentialKeys struct {
 	AccessKeyID     string `json:"access_key_id"`
@@ -275,7 +295,8 @@ func PolicyFromProto(pb *PolicyData) *Policy {
 	}
 	if pb.Acl != nil {
 		policy.ACL = ACL{
-			Permission: ACLPermission(pb.Acl.Permission),
+			Permission:   ACLPermission(pb.Acl.Permission),
+			Repositories: pb.Acl.RepositoryPatterns,
 		}
 	}
 	return policy
@@ -287,7 +308,8 @@ func ProtoFromPolicy(p *Policy) *PolicyData {
 		DisplayName: p.DisplayName,
 		Statements:  protoFromStatements(&p.Statement),
 		Acl: &ACLData{
-			Permission: string(p.ACL.Permission),
+			Permission:         string(p.ACL.Permission),
+			RepositoryPatterns: p.ACL.Repositories,
 		},
 	}
 }
@@ -317,6 +339,24 @@ func ProtoFromCredential(c *Credential) *CredentialData {
 	}
 }
 
+func PublicKeyFromProto(pb *PublicKeyData) *PublicKey {
+	return &PublicKey{
+		Username:    pb.Username,
+		Fingerprint: pb.Fingerprint,
+		PublicKey:   pb.PublicKey,
+		CreatedAt:   pb.CreatedAt.AsTime(),
+	}
+}
+
+func ProtoFromPublicKey(k *PublicKey) *PublicKeyData {
+	return &PublicKeyData{
+		Fingerprint: k.Fingerprint,
+		PublicKey:   k.PublicKey,
+		CreatedAt:   timestamppb.New(k.CreatedAt),
+		Username:    k.Username,
+	}
+}
+
 func statementFromProto(pb *StatementData) *Statement {
 	return &Statement{
 		Effect:   pb.Effect,
@@ -418,6 +458,14 @@ func ConvertCredDataList(s crypt.SecretStore, creds []proto.Message) ([]*Credent
 	return res, nil
 }
 
+func ConvertPublicKeyDataList(keys []proto.Message) []*PublicKey {
+	res := make([]*PublicKey, 0, len(keys))
+	for _, k := range keys {
+		res = append(res, PublicKeyFromProto(k.(*PublicKeyData)))
+	}
+	return res
+}
+
 func DecryptSecret(s crypt.SecretStore, value []byte) (string, error) {
 	decrypted, err := s.Decrypt(value)
 	if err != nil {
@@ -28,7 +28,9 @@ import (
 	"github.com/treeverse/lakefs/pkg/kv"
 	"github.com/treeverse/lakefs/pkg/logging"
 	"github.com/treeverse/lakefs/pkg/permissions"
+	"github.com/treeverse/lakefs/pkg/signature"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/ssh"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -116,6 +118,12 @@ type Service interface {
 	ListUserCredentials(ctx context.Context, username string, params *model.PaginationParams) ([]*model.Credential, *model.Paginator, error)
 	HashAndUpdatePassword(ctx context.Context, username string, password string) error
 
+	// public keys
+	AddUserPublicKey(ctx context.Context, username, publicKey string) (*model.PublicKey, error)
+	DeleteUserPublicKey(ctx context.Context, username, fingerprint string) error
+	GetUserPublicKey(ctx context.Context, username, fingerprint string) (*model.PublicKey, error)
+	ListUserPublicKeys(ctx context.Context, username string, params *model.PaginationParams) ([]*model.PublicKey, *model.Paginator, error)
+
 	// policy<->user attachments
 	AttachPolicyToUser(ctx context.Context, policyDisplayName, username string) error
 	DetachPolicyFromUser(ctx context.Context, policyDisplayName, username string) error
@@ -269,6 +277,23 @@ func (s *AuthService) DeleteUser(ctx context.Context, username string) error {
 		return err
 	}
 
+	// delete public keys of user, so that a new user of the same name does not verify its commits
+	publicKeysKey := model.PublicKeyPath(username, "")
+	keysItr, err := kv.NewPrimaryIterator(ctx, s.store, (&model.PublicKeyData{}).ProtoReflect().Type(), model.PartitionKey, publicKeysKey, kv.IteratorOptionsAfter([]byte("")))
+	if err != nil {
+		return err
+	}
+	defer keysItr.Close()
+	for keysItr.Next() {
+		entry := keysItr.Entry()
+		if err = s.store.Delete(ctx, []byte(model.PartitionKey), entry.Key); err != nil {
+			return fmt.Errorf("delete public key (key %s): %w", entry.Key, err)
+		}
+	}
+	if err = keysItr.Err(); err != nil {
+		return err
+	}
+
 	// delete user
 	err = s.store.Delete(ctx, []byte(model.PartitionKey), userPath)
 	if err != nil {
@@ -988,6 +1013,73 @@ func (s *AuthService) GetCredentials(ctx context.Context, accessKeyID string) (*
 	})
 }
 
+func (s *AuthService) AddUserPublicKey(ctx context.Context, username, publicKey string) (*model.PublicKey, error) {
+	key, err := signature.ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRequest, err)
+	}
+	user, err := s.GetUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	k := &model.PublicKey{
+		Username:    user.Username,
+		Fingerprint: signature.Fingerprint(key),
+		PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))),
+		CreatedAt:   time.Now(),
+	}
+	publicKeyKey := model.PublicKeyPath(user.Username, k.Fingerprint)
+	err = kv.SetMsgIf(ctx, s.store, model.PartitionKey, publicKeyKey, model.ProtoFromPublicKey(k), nil)
+	if err != nil {
+		if errors.Is(err, kv.ErrPredicateFailed) {
+			err = ErrAlreadyExists
+		}
+		return nil, fmt.Errorf("save public key (publicKeyKey %s): %w", publicKeyKey, err)
+	}
+	return k, nil
+}
+
+func (s *AuthService) DeleteUserPublicKey(ctx context.Context, username, fingerprint string) error {
+	if _, err := s.GetUserPublicKey(ctx, username, fingerprint); err != nil {
+		return err
+	}
+	publicKeyKey := model.PublicKeyPath(username, fingerprint)
+	err := s.store.Delete(ctx, []byte(model.PartitionKey), publicKeyKey)
+	if err != nil {
+		return fmt.Errorf("delete public key (publicKeyKey %s): %w", publicKeyKey, err)
+	}
+	return nil
+}
+
+func (s *AuthService) GetUserPublicKey(ctx context.Context, username, fingerprint string) (*model.PublicKey, error) {
+	if _, err := s.GetUser(ctx, username); err != nil {
+		return nil, err
+	}
+	publicKeyKey := model.PublicKeyPath(username, fingerprint)
+	m := model.PublicKeyData{}
+	_, err := kv.GetMsg(ctx, s.store, model.PartitionKey, publicKeyKey, &m)
+	if err != nil {
+		if errors.Is(err, kv.ErrNotFound) {
+			err = ErrNotFound
+		}
+		return nil, err
+	}
+	return model.PublicKeyFromProto(&m), nil
+}
+
+func (s *AuthService) ListUserPublicKeys(ctx context.Context, username string, params *model.PaginationParams) ([]*model.PublicKey, *model.Paginator, error) {
+	if _, err := s.GetUser(ctx, username); err != nil {
+		return nil, nil, err
+	}
+	var publicKey model.PublicKeyData
+	publicKeysKey := model.PublicKeyPath(username, params.Prefix)
+	msgs, paginator, err := s.ListKVPaged(ctx, (&publicKey).ProtoReflect().Type(), params, publicKeysKey, false)
+	if msgs == nil {
+		return nil, paginator, err
+	}
+	return model.ConvertPublicKeyDataList(msgs), paginator, err
+}
+
 func (s *AuthService) HashAndUpdatePassword(ctx context.Context, username string, password string) error {
 	user, err := s.GetUser(ctx, username)
 	if err != nil {
@@ -1762,6 +1854,26 @@ func (a *APIAuthService) ListUserCredentials(ctx context.Context, username strin
 	return credentials, toPagination(resp.JSON200.Pagination), nil
 }
 
+// AddUserPublicKey is not supported by the remote authorization API
+func (a *APIAuthService) AddUserPublicKey(_ context.Context, _, _ string) (*model.PublicKey, error) {
+	return nil, ErrNotImplemented
+}
+
+// DeleteUserPublicKey is not supported by the remote authorization API
+func (a *APIAuthService) DeleteUserPublicKey(_ context.Context, _, _ string) error {
+	return ErrNotImplemented
+}
+
+// GetUserPublicKey is not supported by the remote authorization API
+func (a *APIAuthService) GetUserPublicKey(_ context.Context, _, _ string) (*model.PublicKey, error) {
+	return nil, ErrNotImplemented
+}
+
+// ListUserPublicKeys is not supported by the remote authorization API
+func (a *APIAuthService) ListUserPublicKeys(_ context.Context, _ string, _ *model.PaginationParams) ([]*model.PublicKey, *model.Paginator, error) {
+	return nil, nil, ErrNotImplemented
+}
+
 func (a *APIAuthService) AttachPolicyToUser(ctx context.Context, policyDisplayName, username string) error {
 	resp, err := a.apiClient.AttachPolicyToUserWithResponse(ctx, username, policyDisplayName)
 	if err != nil {
@@ -3,6 +3,8 @@ package auth_test
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"net/http"
@@ -27,6 +29,7 @@ import (
 	"github.com/treeverse/lakefs/pkg/logging"
 	"github.com/treeverse/lakefs/pkg/permissions"
 	"github.com/treeverse/lakefs/pkg/testutil"
+	"golang.org/x/crypto/ssh"
 )
 
 const creationDate = 12345678
@@ -274,6 +277,57 @@ func TestAuthService_DeleteUserWithRelations(t *testing.T) {
 	}
 }
 
+func TestAuthService_UserPublicKeys(t *testing.T) {
+	ctx := context.Background()
+	authService, _ := auth_testutil.SetupService(t, ctx, someSecret)
+	const username = "signer"
+	_, err := authService.CreateUser(ctx, &model.User{Username: username})
+	require.NoError(t, err)
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	require.NoError(t, err)
+	authorizedKey := string(ssh.MarshalAuthorizedKey(signer.PublicKey()))
+
+	key, err := authService.AddUserPublicKey(ctx, username, authorizedKey+" user@host")
+	require.NoError(t, err)
+	require.Equal(t, ssh.FingerprintSHA256(signer.PublicKey()), key.Fingerprint)
+	require.Equal(t, strings.TrimSpace(authorizedKey), key.PublicKey)
+
+	_, err = authService.AddUserPublicKey(ctx, username, authorizedKey)
+	require.ErrorIs(t, err, auth.ErrAlreadyExists)
+	_, err = authService.AddUserPublicKey(ctx, username, "ssh-ed25519 not-a-key")
+	require.ErrorIs(t, err, auth.ErrInvalidRequest)
+	_, err = authService.AddUserPublicKey(ctx, "no-such-user", authorizedKey)
+	require.ErrorIs(t, err, auth.ErrNotFound)
+
+	got, err := authService.GetUserPublicKey(ctx, username, key.Fingerprint)
+	require.NoError(t, err)
+	require.Equal(t, key.PublicKey, got.PublicKey)
+	require.Equal(t, username, got.Username)
+
+	keys, _, err := authService.ListUserPublicKeys(ctx, username, &model.PaginationParams{Amount: 100})
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Equal(t, key.Fingerprint, keys[0].Fingerprint)
+
+	require.NoError(t, authService.DeleteUserPublicKey(ctx, username, key.Fingerprint))
+	_, err = authService.GetUserPublicKey(ctx, username, key.Fingerprint)
+	require.ErrorIs(t, err, auth.ErrNotFound)
+	require.ErrorIs(t, authService.DeleteUserPublicKey(ctx, username, key.Fingerprint), auth.ErrNotFound)
+
+	// keys of a deleted user are not kept for a new user of the same name
+	_, err = authService.AddUserPublicKey(ctx, username, authorizedKey)
+	require.NoError(t, err)
+	require.NoError(t, authService.DeleteUser(ctx, username))
+	_, err = authService.CreateUser(ctx, &model.User{Username: username})
+	require.NoError(t, err)
+	keys, _, err = authService.ListUserPublicKeys(ctx, username, &model.PaginationParams{Amount: 100})
+	require.NoError(t, err)
+	require.Empty(t, keys)
+}
+
 func TestAuthService_DeleteGroupWithRelations(t *testing.T) {
 	userNames := []string{"first", "second", "third"}
 	groupNames := []string{"groupA", "groupB", "groupC"}
@@ -127,6 +127,8 @@ type CreateMultiPartUploadOpts struct {
 // actually reported.
 type Properties struct {
 	StorageClass *string
+	// Size is the size of the stored object.
+	Size int64
 }
 
 type Adapter interface {
@@ -149,9 +151,41 @@ type Adapter interface {
 	UploadCopyPart(ctx context.Context, sourceObj, destinationObj ObjectPointer, uploadID string, partNumber int) (*UploadPartResponse, error)
 	UploadCopyPartRange(ctx context.Context, sourceObj, destinationObj ObjectPointer, uploadID string, partNumber int, startPosition, endPosition int64) (*UploadPartResponse, error)
 	AbortMultiPartUpload(ctx context.Context, obj ObjectPointer, uploadID string) error
+	// ListParts returns the parts uploaded so far to multipart upload uploadID of obj, ordered by part number.
+	ListParts(ctx context.Context, obj ObjectPointer, uploadID string) ([]MultipartPart, error)
 	CompleteMultiPartUpload(ctx context.Context, obj ObjectPointer, uploadID string, multipartList *MultipartUploadCompletion) (*CompleteMultiPartUploadResponse, error)
 	BlockstoreType() string
 	GetStorageNamespaceInfo() StorageNamespaceInfo
 	ResolveNamespace(storageNamespace, key string, identifierType IdentifierType) (QualifiedKey, error)
 	RuntimeStats() map[string]string
 }
+
+// NamespaceAdapter is an Adapter that passes each request to one of several
+// adapters, selected by the storage namespace of its object.
+type NamespaceAdapter interface {
+	Adapter
+	// AdapterFor returns the adapter that serves storageNamespace.
+	AdapterFor(storageNamespace string) Adapter
+	// BlockstoreName returns the name of the blockstore that serves
+	// storageNamespace, or an empty name for the default blockstore.
+	BlockstoreName(storageNamespace string) string
+}
+
+// AdapterForNamespace returns the adapter that serves storageNamespace:
+// adapter itself, unless it is a NamespaceAdapter.
+func AdapterForNamespace(adapter Adapter, storageNamespace string) Adapter {
+	if namespaceAdapter, ok := adapter.(NamespaceAdapter); ok {
+		return namespaceAdapter.AdapterFor(storageNamespace)
+	}
+	return adapter
+}
+
+// BlockstoreName returns the name of the blockstore of adapter that serves
+// storageNamespace: an empty name for the default blockstore, or for every
+// storage namespace unless adapter is a NamespaceAdapter.
+func BlockstoreName(adapter Adapter, storageNamespace string) string {
+	if namespaceAdapter, ok := adapter.(NamespaceAdapter); ok {
+		return namespaceAdapter.BlockstoreName(storageNamespace)
+	}
+	return ""
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
 	"github.com/treeverse/lakefs/pkg/block"
 	"github.com/treeverse/lakefs/pkg/block/params"
+	"github.com/treeverse/lakefs/pkg/ingest/inventory"
 	"github.com/treeverse/lakefs/pkg/logging"
 )
 
@@ -143,8 +144,14 @@ func resolveBlobURLInfo(obj block.ObjectPointer) (BlobURLInfo, error) {
 	return ResolveBlobURLInfoFromURL(parsedKey)
 }
 
-func (a *Adapter) GenerateInventory(_ context.Context, _ logging.Logger, _ string, _ bool, _ []string) (block.Inventory, error) {
-	return nil, fmt.Errorf("inventory %w", ErrNotImplemented)
+// GenerateInventory reads the inventory report whose manifest is at
+// manifestURL.  Reports are read in their order, they cannot be sorted or
+// filtered by prefixes.
+func (a *Adapter) GenerateInventory(ctx context.Context, _ logging.Logger, manifestURL string, shouldSort bool, prefixes []string) (block.Inventory, error) {
+	if shouldSort || len(prefixes) > 0 {
+		return nil, fmt.Errorf("sorted inventory %w", ErrNotImplemented)
+	}
+	return inventory.Load(ctx, a, inventory.KindAzure, manifestURL)
 }
 
 func (a *Adapter) translatePutOpts(ctx context.Context, opts block.PutOpts) azblob.UploadStreamOptions {
@@ -352,7 +359,11 @@ func (a *Adapter) GetProperties(ctx context.Context, obj block.ObjectPointer) (b
 	if err != nil {
 		return block.Properties{}, err
 	}
-	return block.Properties{StorageClass: props.AccessTier}, nil
+	var size int64
+	if props.ContentLength != nil {
+		size = *props.ContentLength
+	}
+	return block.Properties{StorageClass: props.AccessTier, Size: size}, nil
 }
 
 func (a *Adapter) Remove(ctx context.Context, obj block.ObjectPointer) error {
@@ -549,6 +560,11 @@ func (a *Adapter) AbortMultiPartUpload(_ context.Context, _ block.ObjectPointer,
 	return nil
 }
 
+func (a *Adapter) ListParts(_ context.Context, _ block.ObjectPointer, _ string) ([]block.MultipartPart, error) {
+	// Azure parts are staged blocks of the target blob, not numbered parts
+	return nil, block.ErrOperationNotSupported
+}
+
 func (a *Adapter) BlockstoreType() string {
 	return block.BlockstoreTypeAzure
 }
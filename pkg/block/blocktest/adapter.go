@@ -3,6 +3,7 @@ package blocktest
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/url"
@@ -231,6 +232,13 @@ func testAdapterMultipartUpload(t *testing.T, adapter block.Adapter, storageName
 				multiParts[i].PartNumber = partNumber
 				multiParts[i].ETag = partResp.ETag
 			}
+
+			listedParts, err := adapter.ListParts(ctx, obj, resp.UploadID)
+			if !errors.Is(err, block.ErrOperationNotSupported) {
+				require.NoError(t, err)
+				require.Equal(t, multiParts, listedParts)
+			}
+
 			_, err = adapter.CompleteMultiPartUpload(ctx, obj, resp.UploadID, &block.MultipartUploadCompletion{
 				Part: multiParts,
 			})
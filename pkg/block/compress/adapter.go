@@ -0,0 +1,766 @@
+package compress
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+// MinPartSize is the minimal size of a stored part that is not last.  A
+// part that compresses to less is stored uncompressed, so that multipart
+// uploads complete on object stores that limit the size of parts.
+const MinPartSize = 5 * 1024 * 1024
+
+// Entry metadata keys that record the codec that compressed the data of
+// an entry, and the uncompressed size of that data.
+const (
+	MetadataKeyCodec            = "::lakefs::compression::codec"
+	MetadataKeyUncompressedSize = "::lakefs::compression::uncompressed_size"
+)
+
+// codecIdentity is the codec metadata of an entry whose data is stored as
+// it is.  The data of an entry that records a codec name is stored in
+// segments, also when the codec is CodecNone: parts of multipart uploads
+// created before their codec was recorded are always segments.
+const codecIdentity = "identity"
+
+// uploadIDSeparator separates the codec of a multipart upload from the
+// upload ID of the underlying adapter in upload IDs of an Adapter.
+const uploadIDSeparator = ":"
+
+type contextKey string
+
+const (
+	codecContextKey contextKey = "compress-codec"
+	entryContextKey contextKey = "compress-entry"
+)
+
+// entryFormat is how the data of an entry is stored.
+type entryFormat struct {
+	codec     Codec
+	segmented bool
+}
+
+// WithCodec returns a context whose writes through an Adapter are
+// compressed by codec.
+func WithCodec(ctx context.Context, codec Codec) context.Context {
+	return context.WithValue(ctx, codecContextKey, codec)
+}
+
+// WithEntry returns a context whose reads through an Adapter decode data
+// as the codec metadata of an entry with metadata records, and whose
+// writes store data the same way.  Data of entries that record no codec,
+// written before codecs were recorded, is recognized by its prefix.
+func WithEntry(ctx context.Context, metadata map[string]string) context.Context {
+	name, ok := metadata[MetadataKeyCodec]
+	if !ok {
+		return ctx
+	}
+	if name == codecIdentity {
+		return context.WithValue(ctx, entryContextKey, entryFormat{codec: CodecNone})
+	}
+	codec, err := ParseCodec(name)
+	if err != nil || name == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, entryContextKey, entryFormat{codec: codec, segmented: true})
+}
+
+// entryFormatFromContext returns the format set by WithEntry, if any.
+func entryFormatFromContext(ctx context.Context) (entryFormat, bool) {
+	format, ok := ctx.Value(entryContextKey).(entryFormat)
+	return format, ok
+}
+
+// CodecFromContext returns the codec set by WithEntry or WithCodec, or
+// CodecNone.
+func CodecFromContext(ctx context.Context) Codec {
+	if format, ok := entryFormatFromContext(ctx); ok {
+		return format.codec
+	}
+	codec, ok := ctx.Value(codecContextKey).(Codec)
+	if !ok {
+		return CodecNone
+	}
+	return codec
+}
+
+// writeFormat returns how writes with ctx store data that is not a part.
+func writeFormat(ctx context.Context) entryFormat {
+	if format, ok := entryFormatFromContext(ctx); ok {
+		return format
+	}
+	codec := CodecFromContext(ctx)
+	return entryFormat{codec: codec, segmented: codec != CodecNone}
+}
+
+// EntryMetadata returns metadata with the codec of ctx and the uncompressed
+// size of the data of an entry recorded on it, if adapter compresses data
+// written with ctx.  Otherwise it returns metadata as it is.
+func EntryMetadata(ctx context.Context, adapter block.Adapter, metadata map[string]string, size int64) map[string]string {
+	return entryMetadata(adapter, metadata, writeFormat(ctx), size)
+}
+
+// MultipartEntryMetadata is EntryMetadata for the data of a completed
+// multipart upload with uploadID, whose parts were uploaded with ctx.
+func MultipartEntryMetadata(ctx context.Context, adapter block.Adapter, uploadID string, metadata map[string]string, size int64) map[string]string {
+	format, _ := parseUploadID(ctx, uploadID)
+	return entryMetadata(adapter, metadata, format, size)
+}
+
+// parseUploadID returns how the parts of the multipart upload with uploadID
+// are stored, and the upload ID of the underlying adapter.  Parts of
+// uploads whose IDs record no codec are segments compressed by the codec
+// of ctx.
+func parseUploadID(ctx context.Context, uploadID string) (entryFormat, string) {
+	if name, id, ok := strings.Cut(uploadID, uploadIDSeparator); ok && name != "" {
+		if codec, err := ParseCodec(name); err == nil {
+			return entryFormat{codec: codec, segmented: codec != CodecNone}, id
+		}
+	}
+	return entryFormat{codec: CodecFromContext(ctx), segmented: true}, uploadID
+}
+
+func entryMetadata(adapter block.Adapter, metadata map[string]string, format entryFormat, size int64) map[string]string {
+	if _, ok := adapter.(*Adapter); !ok {
+		return metadata
+	}
+	result := make(map[string]string, len(metadata)+2)
+	for k, v := range metadata {
+		result[k] = v
+	}
+	if !format.segmented {
+		result[MetadataKeyCodec] = codecIdentity
+		delete(result, MetadataKeyUncompressedSize)
+		return result
+	}
+	result[MetadataKeyCodec] = format.codec.String()
+	result[MetadataKeyUncompressedSize] = strconv.FormatInt(size, 10)
+	return result
+}
+
+// Adapter compresses the data of objects before passing it to an underlying
+// adapter, and decompresses it when reading it back.  Writes use the codec
+// of their context.  Reads decode data as recorded on the entry of their
+// context, and recognize compressed data of other objects by its prefix.
+type Adapter struct {
+	adapter block.Adapter
+}
+
+func NewAdapter(adapter block.Adapter) *Adapter {
+	return &Adapter{adapter: adapter}
+}
+
+// AdapterFor returns a compressing adapter over the adapter that serves
+// storageNamespace.
+func (a *Adapter) AdapterFor(storageNamespace string) block.Adapter {
+	return &Adapter{adapter: block.AdapterForNamespace(a.adapter, storageNamespace)}
+}
+
+// BlockstoreName returns the name of the blockstore of the underlying
+// adapter that serves storageNamespace.
+func (a *Adapter) BlockstoreName(storageNamespace string) string {
+	return block.BlockstoreName(a.adapter, storageNamespace)
+}
+
+func (a *Adapter) GenerateInventory(ctx context.Context, logger logging.Logger, inventoryURL string, shouldSort bool, prefixes []string) (block.Inventory, error) {
+	return a.adapter.GenerateInventory(ctx, logger, inventoryURL, shouldSort, prefixes)
+}
+
+// Put stores the object as a single segment, or as it is if the context
+// holds no codec or an entry stored as it is.  The stored size is known only after compression, so the
+// segment is spilled to a temporary file and then stored with its size.
+func (a *Adapter) Put(ctx context.Context, obj block.ObjectPointer, sizeBytes int64, reader io.Reader, opts block.PutOpts) error {
+	format := writeFormat(ctx)
+	if !format.segmented {
+		return a.adapter.Put(ctx, obj, sizeBytes, reader, opts)
+	}
+	stored, err := spill(newCompressReader(format.codec, reader, sizeBytes))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = stored.Close()
+	}()
+	return a.adapter.Put(ctx, obj, stored.size, stored, opts)
+}
+
+// Get decodes the object as recorded on the entry of the context.  Objects
+// of entries that record no codec are decompressed if they start with the
+// segment magic.
+func (a *Adapter) Get(ctx context.Context, obj block.ObjectPointer, _ int64) (io.ReadCloser, error) {
+	// the stored size differs from the expected size
+	reader, err := a.adapter.Get(ctx, obj, -1)
+	if err != nil {
+		return nil, err
+	}
+	source := bufio.NewReader(reader)
+	format, ok := entryFormatFromContext(ctx)
+	if !ok {
+		prefix, err := source.Peek(len(magic))
+		if err != nil && !errors.Is(err, io.EOF) {
+			_ = reader.Close()
+			return nil, err
+		}
+		format.segmented = hasMagic(prefix)
+	}
+	if !format.segmented {
+		return &struct {
+			io.Reader
+			io.Closer
+		}{
+			Reader: source,
+			Closer: reader,
+		}, nil
+	}
+	return newDecompressReader(source, reader), nil
+}
+
+func (a *Adapter) GetWalker(uri *url.URL) (block.Walker, error) {
+	return a.adapter.GetWalker(uri)
+}
+
+// GetPreSignedURL returns a pre-signed URL of the underlying adapter for
+// data stored as it is: writes with a context that holds no codec, and
+// reads of objects stored as they are.  Clients would read or write
+// compressed data through any other URL, so it is not supported.
+func (a *Adapter) GetPreSignedURL(ctx context.Context, obj block.ObjectPointer, mode block.PreSignMode) (string, time.Time, error) {
+	storedAsIs, err := a.storedAsIs(ctx, obj, mode)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if !storedAsIs {
+		return "", time.Time{}, fmt.Errorf("compressed block adapter presigned URL: %w", block.ErrOperationNotSupported)
+	}
+	return a.adapter.GetPreSignedURL(ctx, obj, mode)
+}
+
+// storedAsIs returns whether the data that a pre-signed URL with mode
+// reads or writes is stored as it is: as the codec of ctx writes it, as
+// recorded on the entry of ctx, or as recognized by the lack of the segment
+// magic.
+func (a *Adapter) storedAsIs(ctx context.Context, obj block.ObjectPointer, mode block.PreSignMode) (bool, error) {
+	if mode == block.PreSignModeWrite {
+		return !writeFormat(ctx).segmented, nil
+	}
+	if format, ok := entryFormatFromContext(ctx); ok {
+		return !format.segmented, nil
+	}
+	props, err := a.adapter.GetProperties(ctx, obj)
+	if err != nil {
+		return false, err
+	}
+	if props.Size < int64(len(magic)) {
+		return true, nil
+	}
+	reader, err := a.adapter.GetRange(ctx, obj, 0, int64(len(magic))-1)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	prefix, err := io.ReadAll(reader)
+	if err != nil {
+		return false, err
+	}
+	return !hasMagic(prefix), nil
+}
+
+func (a *Adapter) Exists(ctx context.Context, obj block.ObjectPointer) (bool, error) {
+	return a.adapter.Exists(ctx, obj)
+}
+
+// segment is a stored segment of an object.
+type segment struct {
+	footer *footer
+	// offset is the offset of the segment in the stored object.
+	offset int64
+	// start is the offset of the data of the segment in the object.
+	start int64
+}
+
+// segments returns the segments of obj, whose stored size is size, from
+// their footers.  It returns nil if obj is stored as it is, as recorded on
+// the entry of the context or recognized by the lack of a footer.
+func (a *Adapter) segments(ctx context.Context, obj block.ObjectPointer, size int64) ([]segment, error) {
+	format, ok := entryFormatFromContext(ctx)
+	if ok && !format.segmented {
+		return nil, nil
+	}
+	segments, err := a.readSegments(ctx, obj, size)
+	if ok && err == nil && segments == nil {
+		return nil, fmt.Errorf("%w: missing segment footer", ErrBadFormat)
+	}
+	return segments, err
+}
+
+// readSegments returns the segments of obj from their footers, or nil if
+// obj does not end with a footer.
+func (a *Adapter) readSegments(ctx context.Context, obj block.ObjectPointer, size int64) ([]segment, error) {
+	var segments []segment
+	for end := size; end > 0; {
+		if end < int64(footerSize) {
+			if end == size {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("%w: missing segment footer", ErrBadFormat)
+		}
+		reader, err := a.adapter.GetRange(ctx, obj, end-int64(footerSize), end-1)
+		if err != nil {
+			return nil, err
+		}
+		buf, err := io.ReadAll(reader)
+		_ = reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		f, err := unmarshalFooter(buf)
+		if err != nil {
+			return nil, err
+		}
+		if f == nil {
+			if end == size {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("%w: missing segment footer", ErrBadFormat)
+		}
+		if f.size > end {
+			return nil, fmt.Errorf("%w: bad segment size", ErrBadFormat)
+		}
+		end -= f.size
+		segments = append(segments, segment{footer: f, offset: end})
+	}
+	// segments were found from the end of the object
+	var start int64
+	for i := len(segments) - 1; i >= 0; i-- {
+		segments[i].start = start
+		start += segments[i].footer.length
+	}
+	for i, j := 0, len(segments)-1; i < j; i, j = i+1, j-1 {
+		segments[i], segments[j] = segments[j], segments[i]
+	}
+	return segments, nil
+}
+
+// storedSegments returns the segments of obj, or nil if it is not
+// compressed.
+func (a *Adapter) storedSegments(ctx context.Context, obj block.ObjectPointer) ([]segment, error) {
+	props, err := a.adapter.GetProperties(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	return a.segments(ctx, obj, props.Size)
+}
+
+// GetRange reads the data between startPosition and endPosition.  It reads
+// the footers of the segments of obj, and then the index and the frames
+// that hold the range of each segment that overlaps it.
+func (a *Adapter) GetRange(ctx context.Context, obj block.ObjectPointer, startPosition int64, endPosition int64) (io.ReadCloser, error) {
+	if startPosition < 0 || endPosition < startPosition {
+		return nil, block.ErrBadIndex
+	}
+	segments, err := a.storedSegments(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	if segments == nil {
+		return a.adapter.GetRange(ctx, obj, startPosition, endPosition)
+	}
+	readers := make([]*lazyRangeReader, 0, len(segments))
+	for _, s := range segments {
+		s := s
+		length := s.footer.length
+		start := startPosition - s.start
+		if start < 0 {
+			start = 0
+		}
+		end := endPosition - s.start
+		if end >= length {
+			end = length - 1
+		}
+		if start > end {
+			continue
+		}
+		readers = append(readers, &lazyRangeReader{
+			open: func() (io.ReadCloser, error) {
+				return a.readSegmentRange(ctx, obj, s, start, end)
+			},
+		})
+	}
+	multiReaders := make([]io.Reader, len(readers))
+	for i, r := range readers {
+		multiReaders[i] = r
+	}
+	return &multiReadCloser{
+		Reader:  io.MultiReader(multiReaders...),
+		readers: readers,
+	}, nil
+}
+
+// readSegmentRange reads offsets start to end of the data of segment s.
+func (a *Adapter) readSegmentRange(ctx context.Context, obj block.ObjectPointer, s segment, start, end int64) (io.ReadCloser, error) {
+	f := s.footer
+	indexOffset := s.offset + f.indexOffset()
+	reader, err := a.adapter.GetRange(ctx, obj, indexOffset, indexOffset+int64(f.frames*indexEntrySize)-1)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := io.ReadAll(reader)
+	_ = reader.Close()
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) != f.frames*indexEntrySize {
+		return nil, fmt.Errorf("%w: truncated index", ErrBadFormat)
+	}
+	codec, err := a.segmentCodec(ctx, obj, s)
+	if err != nil {
+		return nil, err
+	}
+
+	// find the stored offsets of the frames that hold the range
+	var (
+		offset     = s.offset + int64(headerSize)
+		dataOffset int64
+		first      int64 = -1
+		skip       int64
+		last       int64
+	)
+	for _, entry := range unmarshalIndex(buf) {
+		frameEnd := dataOffset + int64(entry.uncompressed)
+		storedEnd := offset + frameHeaderSize + int64(entry.compressed)
+		if first < 0 && frameEnd > start {
+			first = offset
+			skip = start - dataOffset
+		}
+		if first >= 0 {
+			last = storedEnd
+			if frameEnd > end {
+				break
+			}
+		}
+		offset = storedEnd
+		dataOffset = frameEnd
+	}
+	if first < 0 {
+		return nil, fmt.Errorf("%w: range beyond index", ErrBadFormat)
+	}
+	source, err := a.adapter.GetRange(ctx, obj, first, last-1)
+	if err != nil {
+		return nil, err
+	}
+	return &rangeReader{
+		codec:     codec,
+		source:    source,
+		skip:      skip,
+		remaining: end - start + 1,
+	}, nil
+}
+
+// segmentCodec reads the codec of segment s from its header.
+func (a *Adapter) segmentCodec(ctx context.Context, obj block.ObjectPointer, s segment) (Codec, error) {
+	reader, err := a.adapter.GetRange(ctx, obj, s.offset, s.offset+int64(headerSize)-1)
+	if err != nil {
+		return CodecNone, err
+	}
+	buf, err := io.ReadAll(reader)
+	_ = reader.Close()
+	if err != nil {
+		return CodecNone, err
+	}
+	if len(buf) != headerSize || !hasMagic(buf) {
+		return CodecNone, fmt.Errorf("%w: bad segment header", ErrBadFormat)
+	}
+	return Codec(buf[len(magic)]), nil
+}
+
+// lazyRangeReader opens its reader on first read.
+type lazyRangeReader struct {
+	open   func() (io.ReadCloser, error)
+	reader io.ReadCloser
+}
+
+func (r *lazyRangeReader) Read(p []byte) (int, error) {
+	if r.reader == nil {
+		reader, err := r.open()
+		if err != nil {
+			return 0, err
+		}
+		r.reader = reader
+	}
+	return r.reader.Read(p)
+}
+
+func (r *lazyRangeReader) Close() error {
+	if r.reader == nil {
+		return nil
+	}
+	return r.reader.Close()
+}
+
+type multiReadCloser struct {
+	io.Reader
+	readers []*lazyRangeReader
+}
+
+func (m *multiReadCloser) Close() error {
+	var err error
+	for _, r := range m.readers {
+		if closeErr := r.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// GetProperties returns the properties of the stored object, with the
+// uncompressed size.
+func (a *Adapter) GetProperties(ctx context.Context, obj block.ObjectPointer) (block.Properties, error) {
+	props, err := a.adapter.GetProperties(ctx, obj)
+	if err != nil {
+		return props, err
+	}
+	props.Size, err = a.uncompressedSize(ctx, obj, props.Size)
+	return props, err
+}
+
+// uncompressedSize returns the uncompressed length of obj, whose stored
+// size is size.
+func (a *Adapter) uncompressedSize(ctx context.Context, obj block.ObjectPointer, size int64) (int64, error) {
+	segments, err := a.segments(ctx, obj, size)
+	if err != nil {
+		return 0, err
+	}
+	if segments == nil {
+		return size, nil
+	}
+	last := segments[len(segments)-1]
+	return last.start + last.footer.length, nil
+}
+
+func (a *Adapter) Remove(ctx context.Context, obj block.ObjectPointer) error {
+	return a.adapter.Remove(ctx, obj)
+}
+
+// Copy copies the stored data: segments are self-contained.
+func (a *Adapter) Copy(ctx context.Context, sourceObj, destinationObj block.ObjectPointer) error {
+	return a.adapter.Copy(ctx, sourceObj, destinationObj)
+}
+
+// CreateMultiPartUpload creates an upload whose parts are stored as the
+// codec of the context writes them.  The codec is recorded on the upload
+// ID, so that all parts of the upload are stored the same way: parts of
+// an upload without a codec are stored as they are.
+func (a *Adapter) CreateMultiPartUpload(ctx context.Context, obj block.ObjectPointer, r *http.Request, opts block.CreateMultiPartUploadOpts) (*block.CreateMultiPartUploadResponse, error) {
+	resp, err := a.adapter.CreateMultiPartUpload(ctx, obj, r, opts)
+	if err != nil {
+		return nil, err
+	}
+	resp.UploadID = CodecFromContext(ctx).String() + uploadIDSeparator + resp.UploadID
+	return resp, nil
+}
+
+// UploadPart stores the part as the upload records: as it is, or as a
+// segment compressed by the codec of the upload.  Parts of compressed
+// uploads are segments also when they do not compress, so that parts of
+// an upload may be compressed differently.
+func (a *Adapter) UploadPart(ctx context.Context, obj block.ObjectPointer, sizeBytes int64, reader io.Reader, uploadID string, partNumber int) (*block.UploadPartResponse, error) {
+	format, id := parseUploadID(ctx, uploadID)
+	if !format.segmented {
+		return a.adapter.UploadPart(ctx, obj, sizeBytes, reader, id, partNumber)
+	}
+	return a.uploadSegment(ctx, obj, format.codec, sizeBytes, reader, id, partNumber)
+}
+
+// uploadSegment stores a part of the upload of the underlying adapter with
+// uploadID as a segment compressed by codec.  The segment is spilled to a
+// temporary file: it is stored uncompressed if it compresses to less than
+// MinPartSize.
+func (a *Adapter) uploadSegment(ctx context.Context, obj block.ObjectPointer, codec Codec, sizeBytes int64, reader io.Reader, uploadID string, partNumber int) (*block.UploadPartResponse, error) {
+	stored, err := spill(newCompressReader(codec, reader, sizeBytes))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = stored.Close()
+	}()
+	if codec != CodecNone && stored.size < MinPartSize {
+		uncompressed, err := spill(newCompressReader(CodecNone, newDecompressReader(bufio.NewReader(stored), stored), -1))
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = uncompressed.Close()
+		}()
+		stored = uncompressed
+	}
+	return a.adapter.UploadPart(ctx, obj, stored.size, stored, uploadID, partNumber)
+}
+
+// UploadCopyPart copies the stored data of a source that is stored as the
+// parts of the upload: the segments of a compressed source are segments of
+// the part.  Any other source is read and uploaded as a part.
+func (a *Adapter) UploadCopyPart(ctx context.Context, sourceObj, destinationObj block.ObjectPointer, uploadID string, partNumber int) (*block.UploadPartResponse, error) {
+	format, id := parseUploadID(ctx, uploadID)
+	props, err := a.adapter.GetProperties(ctx, sourceObj)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := a.segments(ctx, sourceObj, props.Size)
+	if err != nil {
+		return nil, err
+	}
+	if (segments != nil) == format.segmented {
+		return a.adapter.UploadCopyPart(ctx, sourceObj, destinationObj, id, partNumber)
+	}
+	if segments == nil {
+		reader, err := a.adapter.Get(ctx, sourceObj, props.Size)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = reader.Close()
+		}()
+		return a.uploadSegment(ctx, destinationObj, format.codec, props.Size, reader, id, partNumber)
+	}
+	last := segments[len(segments)-1]
+	return a.uploadRange(ctx, sourceObj, destinationObj, 0, last.start+last.footer.length-1, id, partNumber)
+}
+
+// UploadCopyPartRange copies the range of a source stored as it is to an
+// upload stored as it is.  Otherwise it reads the range and uploads it as a
+// part.
+func (a *Adapter) UploadCopyPartRange(ctx context.Context, sourceObj, destinationObj block.ObjectPointer, uploadID string, partNumber int, startPosition, endPosition int64) (*block.UploadPartResponse, error) {
+	format, id := parseUploadID(ctx, uploadID)
+	if !format.segmented {
+		props, err := a.adapter.GetProperties(ctx, sourceObj)
+		if err != nil {
+			return nil, err
+		}
+		segments, err := a.segments(ctx, sourceObj, props.Size)
+		if err != nil {
+			return nil, err
+		}
+		if segments == nil {
+			return a.adapter.UploadCopyPartRange(ctx, sourceObj, destinationObj, id, partNumber, startPosition, endPosition)
+		}
+		return a.uploadRange(ctx, sourceObj, destinationObj, startPosition, endPosition, id, partNumber)
+	}
+	reader, err := a.GetRange(ctx, sourceObj, startPosition, endPosition)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	return a.uploadSegment(ctx, destinationObj, format.codec, -1, reader, id, partNumber)
+}
+
+// uploadRange reads the range of sourceObj and stores it as it is as a part
+// of the upload of the underlying adapter with uploadID.  The range is
+// spilled to a temporary file: the size of a range is known only after it
+// is read.
+func (a *Adapter) uploadRange(ctx context.Context, sourceObj, destinationObj block.ObjectPointer, startPosition, endPosition int64, uploadID string, partNumber int) (*block.UploadPartResponse, error) {
+	reader, err := a.GetRange(ctx, sourceObj, startPosition, endPosition)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := spill(reader)
+	_ = reader.Close()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = stored.Close()
+	}()
+	return a.adapter.UploadPart(ctx, destinationObj, stored.size, stored, uploadID, partNumber)
+}
+
+func (a *Adapter) AbortMultiPartUpload(ctx context.Context, obj block.ObjectPointer, uploadID string) error {
+	_, id := parseUploadID(ctx, uploadID)
+	return a.adapter.AbortMultiPartUpload(ctx, obj, id)
+}
+
+// ListParts lists the stored parts, whose ETags are those UploadPart returned.
+func (a *Adapter) ListParts(ctx context.Context, obj block.ObjectPointer, uploadID string) ([]block.MultipartPart, error) {
+	_, id := parseUploadID(ctx, uploadID)
+	return a.adapter.ListParts(ctx, obj, id)
+}
+
+// CompleteMultiPartUpload completes the upload, and reports the
+// uncompressed length of the object.
+func (a *Adapter) CompleteMultiPartUpload(ctx context.Context, obj block.ObjectPointer, uploadID string, multipartList *block.MultipartUploadCompletion) (*block.CompleteMultiPartUploadResponse, error) {
+	format, id := parseUploadID(ctx, uploadID)
+	resp, err := a.adapter.CompleteMultiPartUpload(ctx, obj, id, multipartList)
+	if err != nil || !format.segmented {
+		return resp, err
+	}
+	// the parts are stored as the upload records, whatever entry the context holds
+	resp.ContentLength, err = a.uncompressedSize(context.WithValue(ctx, entryContextKey, format), obj, resp.ContentLength)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// spillFile is a temporary file holding a stored segment.  Closing it
+// removes it.
+type spillFile struct {
+	*os.File
+	size int64
+}
+
+// spill writes reader to a temporary file, and returns it positioned at
+// its start.
+func spill(reader io.Reader) (*spillFile, error) {
+	f, err := os.CreateTemp("", "lakefs-compress-")
+	if err != nil {
+		return nil, err
+	}
+	s := &spillFile{File: f}
+	s.size, err = io.Copy(f, reader)
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *spillFile) Close() error {
+	err := s.File.Close()
+	_ = os.Remove(s.Name())
+	return err
+}
+
+func (a *Adapter) BlockstoreType() string {
+	return a.adapter.BlockstoreType()
+}
+
+// GetStorageNamespaceInfo returns the information of the underlying
+// adapter.  Pre-signed URLs are supported only for data stored as it is.
+func (a *Adapter) GetStorageNamespaceInfo() block.StorageNamespaceInfo {
+	return a.adapter.GetStorageNamespaceInfo()
+}
+
+func (a *Adapter) ResolveNamespace(storageNamespace, key string, identifierType block.IdentifierType) (block.QualifiedKey, error) {
+	return a.adapter.ResolveNamespace(storageNamespace, key, identifierType)
+}
+
+func (a *Adapter) RuntimeStats() map[string]string {
+	return a.adapter.RuntimeStats()
+}
@@ -0,0 +1,480 @@
+package compress_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thanhpk/randstr"
+	"github.com/treeverse/lakefs/pkg/auth/crypt"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/blocktest"
+	"github.com/treeverse/lakefs/pkg/block/compress"
+	"github.com/treeverse/lakefs/pkg/block/encrypt"
+	"github.com/treeverse/lakefs/pkg/block/local"
+	"github.com/treeverse/lakefs/pkg/block/mem"
+)
+
+const testStorageNamespace = "mem://test"
+
+func newTestAdapter() (*compress.Adapter, *mem.Adapter) {
+	underlying := mem.New(context.Background())
+	return compress.NewAdapter(underlying), underlying
+}
+
+func TestLocalAdapter(t *testing.T) {
+	for _, codec := range []compress.Codec{compress.CodecNone, compress.CodecGzip, compress.CodecZstd} {
+		t.Run(codec.String(), func(t *testing.T) {
+			tmpDir := t.TempDir()
+			localPath := path.Join(tmpDir, "lakefs")
+			externalPath := block.BlockstoreTypeLocal + "://" + path.Join(tmpDir, "lakefs", "external")
+			underlying, err := local.NewAdapter(localPath, local.WithRemoveEmptyDir(false))
+			require.NoError(t, err)
+			adapter := &codecAdapter{Adapter: compress.NewAdapter(underlying), codec: codec}
+			blocktest.AdapterTest(t, adapter, "local://test", externalPath)
+		})
+	}
+}
+
+// codecAdapter writes with codec, as the API and the S3 gateway do for
+// repositories that set it.
+type codecAdapter struct {
+	*compress.Adapter
+	codec compress.Codec
+}
+
+func (a *codecAdapter) Put(ctx context.Context, obj block.ObjectPointer, sizeBytes int64, reader io.Reader, opts block.PutOpts) error {
+	return a.Adapter.Put(compress.WithCodec(ctx, a.codec), obj, sizeBytes, reader, opts)
+}
+
+func (a *codecAdapter) CreateMultiPartUpload(ctx context.Context, obj block.ObjectPointer, r *http.Request, opts block.CreateMultiPartUploadOpts) (*block.CreateMultiPartUploadResponse, error) {
+	return a.Adapter.CreateMultiPartUpload(compress.WithCodec(ctx, a.codec), obj, r, opts)
+}
+
+func TestParseCodec(t *testing.T) {
+	for _, codec := range []compress.Codec{compress.CodecNone, compress.CodecGzip, compress.CodecZstd} {
+		parsed, err := compress.ParseCodec(codec.String())
+		require.NoError(t, err)
+		require.Equal(t, codec, parsed)
+	}
+	parsed, err := compress.ParseCodec("")
+	require.NoError(t, err)
+	require.Equal(t, compress.CodecNone, parsed)
+	_, err = compress.ParseCodec("lz4")
+	require.ErrorIs(t, err, compress.ErrUnknownCodec)
+}
+
+// compressible returns size bytes of repetitive text.
+func compressible(size int) []byte {
+	var buf bytes.Buffer
+	for i := 0; buf.Len() < size; i++ {
+		fmt.Fprintf(&buf, "%d,event,%s\n", i, randstr.Hex(4))
+	}
+	return append([]byte{}, buf.Bytes()[:size]...)
+}
+
+func TestEntryMetadata(t *testing.T) {
+	ctx := context.Background()
+	adapter, underlying := newTestAdapter()
+	metadata := map[string]string{"key": "value"}
+
+	require.Equal(t, map[string]string{
+		"key":                     "value",
+		compress.MetadataKeyCodec: "identity",
+	}, compress.EntryMetadata(ctx, adapter, metadata, 10))
+	// uploads record their codec on their IDs, older uploads always store segments
+	require.Equal(t, map[string]string{
+		"key":                     "value",
+		compress.MetadataKeyCodec: "identity",
+	}, compress.MultipartEntryMetadata(ctx, adapter, "none:upload", metadata, 10))
+	require.Equal(t, map[string]string{
+		"key":                                "value",
+		compress.MetadataKeyCodec:            "gzip",
+		compress.MetadataKeyUncompressedSize: "10",
+	}, compress.MultipartEntryMetadata(ctx, adapter, "gzip:upload", metadata, 10))
+	require.Equal(t, map[string]string{
+		"key":                                "value",
+		compress.MetadataKeyCodec:            "none",
+		compress.MetadataKeyUncompressedSize: "10",
+	}, compress.MultipartEntryMetadata(ctx, adapter, "upload", metadata, 10))
+	zstdCtx := compress.WithCodec(ctx, compress.CodecZstd)
+	require.Equal(t, metadata, compress.EntryMetadata(zstdCtx, underlying, metadata, 10))
+	require.Equal(t, map[string]string{
+		"key":                                "value",
+		compress.MetadataKeyCodec:            "zstd",
+		compress.MetadataKeyUncompressedSize: "10",
+	}, compress.EntryMetadata(zstdCtx, adapter, metadata, 10))
+	require.Equal(t, map[string]string{"key": "value"}, metadata)
+}
+
+// TestAdapter_EntryCodec reads objects as their entries record, also
+// uncompressed objects that start with the segment magic.
+func TestAdapter_EntryCodec(t *testing.T) {
+	adapter, underlying := newTestAdapter()
+	ctx := context.Background()
+	contents := append([]byte("LKFSCMP1"), compressible(2*compress.FrameSize)...)
+
+	rawObj := block.ObjectPointer{StorageNamespace: testStorageNamespace, Identifier: "raw", IdentifierType: block.IdentifierTypeRelative}
+	require.NoError(t, adapter.Put(ctx, rawObj, int64(len(contents)), bytes.NewReader(contents), block.PutOpts{}))
+	rawCtx := compress.WithEntry(ctx, compress.EntryMetadata(ctx, adapter, nil, int64(len(contents))))
+	data, err := readAll(adapter.Get(rawCtx, rawObj, int64(len(contents))))
+	require.NoError(t, err)
+	require.Equal(t, contents, data)
+	data, err = readAll(adapter.GetRange(rawCtx, rawObj, 4, 11))
+	require.NoError(t, err)
+	require.Equal(t, contents[4:12], data)
+	props, err := adapter.GetProperties(rawCtx, rawObj)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(contents)), props.Size)
+
+	// without the entry, the magic is taken for a compressed object
+	_, err = readAll(adapter.Get(ctx, rawObj, int64(len(contents))))
+	require.Error(t, err)
+
+	zstdCtx := compress.WithCodec(ctx, compress.CodecZstd)
+	compressedObj := block.ObjectPointer{StorageNamespace: testStorageNamespace, Identifier: "compressed", IdentifierType: block.IdentifierTypeRelative}
+	require.NoError(t, adapter.Put(zstdCtx, compressedObj, int64(len(contents)), bytes.NewReader(contents), block.PutOpts{}))
+	compressedCtx := compress.WithEntry(ctx, compress.EntryMetadata(zstdCtx, adapter, nil, int64(len(contents))))
+	data, err = readAll(adapter.Get(compressedCtx, compressedObj, int64(len(contents))))
+	require.NoError(t, err)
+	require.Equal(t, contents, data)
+
+	// writes with the entry store data as the entry records
+	copiedObj := block.ObjectPointer{StorageNamespace: testStorageNamespace, Identifier: "copied", IdentifierType: block.IdentifierTypeRelative}
+	require.NoError(t, adapter.Put(compressedCtx, copiedObj, int64(len(contents)), bytes.NewReader(contents), block.PutOpts{}))
+	stored, err := readAll(underlying.Get(ctx, copiedObj, -1))
+	require.NoError(t, err)
+	require.Less(t, len(stored), len(contents)/2, "object not compressed")
+	require.NoError(t, adapter.Put(rawCtx, copiedObj, int64(len(contents)), bytes.NewReader(contents), block.PutOpts{}))
+	stored, err = readAll(underlying.Get(ctx, copiedObj, -1))
+	require.NoError(t, err)
+	require.Equal(t, contents, stored)
+
+	// an entry that records a codec holds a compressed object
+	_, err = readAll(adapter.GetRange(compressedCtx, rawObj, 0, 1))
+	require.ErrorIs(t, err, compress.ErrBadFormat)
+}
+
+func TestAdapter_PutGet(t *testing.T) {
+	adapter, underlying := newTestAdapter()
+
+	sizes := []int{0, 1, compress.FrameSize - 1, compress.FrameSize, compress.FrameSize + 1, 3*compress.FrameSize + 17}
+	for _, codec := range []compress.Codec{compress.CodecGzip, compress.CodecZstd} {
+		ctx := compress.WithCodec(context.Background(), codec)
+		for _, size := range sizes {
+			contents := compressible(size)
+			for _, knownSize := range []bool{true, false} {
+				t.Run(fmt.Sprintf("%s_size_%d_known_%t", codec, size, knownSize), func(t *testing.T) {
+					obj := block.ObjectPointer{
+						StorageNamespace: testStorageNamespace,
+						Identifier:       fmt.Sprintf("obj_%s_%d_%t", codec, size, knownSize),
+						IdentifierType:   block.IdentifierTypeRelative,
+					}
+					sizeBytes := int64(size)
+					if !knownSize {
+						sizeBytes = -1
+					}
+					require.NoError(t, adapter.Put(ctx, obj, sizeBytes, bytes.NewReader(contents), block.PutOpts{}))
+
+					stored, err := readAll(underlying.Get(ctx, obj, -1))
+					require.NoError(t, err)
+					if size > compress.FrameSize {
+						require.Less(t, len(stored), size/2, "object not compressed")
+					}
+
+					data, err := readAll(adapter.Get(ctx, obj, int64(size)))
+					require.NoError(t, err)
+					require.Equal(t, contents, data)
+
+					props, err := adapter.GetProperties(ctx, obj)
+					require.NoError(t, err)
+					require.Equal(t, int64(size), props.Size)
+				})
+			}
+		}
+	}
+}
+
+func TestAdapter_PutSizeMismatch(t *testing.T) {
+	ctx := compress.WithCodec(context.Background(), compress.CodecZstd)
+	adapter, _ := newTestAdapter()
+	obj := block.ObjectPointer{
+		StorageNamespace: testStorageNamespace,
+		Identifier:       "mismatch",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}
+	err := adapter.Put(ctx, obj, 10, bytes.NewReader([]byte("short")), block.PutOpts{})
+	require.ErrorIs(t, err, compress.ErrSizeMismatch)
+}
+
+func TestAdapter_GetRange(t *testing.T) {
+	ctx := context.Background()
+	adapter, _ := newTestAdapter()
+
+	// a part that is stored compressed, a small part that is stored
+	// uncompressed and a last part
+	parts := [][]byte{
+		randstr.Bytes(compress.MinPartSize + 100),
+		compressible(1000),
+		compressible(compress.FrameSize + 5),
+	}
+	contents := bytes.Join(parts, nil)
+	objects := map[string]block.ObjectPointer{}
+	for _, name := range []string{"plain", "gzip", "zstd", "multipart"} {
+		objects[name] = block.ObjectPointer{
+			StorageNamespace: testStorageNamespace,
+			Identifier:       name,
+			IdentifierType:   block.IdentifierTypeRelative,
+		}
+	}
+	require.NoError(t, adapter.Put(ctx, objects["plain"], int64(len(contents)), bytes.NewReader(contents), block.PutOpts{}))
+	require.NoError(t, adapter.Put(compress.WithCodec(ctx, compress.CodecGzip), objects["gzip"], -1, bytes.NewReader(contents), block.PutOpts{}))
+	require.NoError(t, adapter.Put(compress.WithCodec(ctx, compress.CodecZstd), objects["zstd"], int64(len(contents)), bytes.NewReader(contents), block.PutOpts{}))
+	multipartObj := objects["multipart"]
+	upload, err := adapter.CreateMultiPartUpload(compress.WithCodec(ctx, compress.CodecZstd), multipartObj, nil, block.CreateMultiPartUploadOpts{})
+	require.NoError(t, err)
+	completion := &block.MultipartUploadCompletion{}
+	for i, part := range parts {
+		resp, err := adapter.UploadPart(ctx, multipartObj, int64(len(part)), bytes.NewReader(part), upload.UploadID, i+1)
+		require.NoError(t, err)
+		completion.Part = append(completion.Part, block.MultipartPart{ETag: resp.ETag, PartNumber: i + 1})
+	}
+	completeResp, err := adapter.CompleteMultiPartUpload(ctx, multipartObj, upload.UploadID, completion)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(contents)), completeResp.ContentLength)
+
+	size := int64(len(contents))
+	ranges := []struct {
+		name       string
+		start, end int64
+	}{
+		{"first_byte", 0, 0},
+		{"first_frame", 0, compress.FrameSize - 1},
+		{"across_frames", compress.FrameSize - 10, compress.FrameSize + 10},
+		{"across_segments", compress.MinPartSize + 50, compress.MinPartSize + 2000},
+		{"middle", 12345, 3 * compress.FrameSize},
+		{"last_byte", size - 1, size - 1},
+		{"all", 0, size - 1},
+		{"out_of_bounds", 100, size + 1000},
+	}
+	for name, obj := range objects {
+		for _, tt := range ranges {
+			t.Run(name+"_"+tt.name, func(t *testing.T) {
+				expected := contents[tt.start:]
+				if tt.end < size {
+					expected = contents[tt.start : tt.end+1]
+				}
+				data, err := readAll(adapter.GetRange(ctx, obj, tt.start, tt.end))
+				require.NoError(t, err)
+				require.Equal(t, expected, data)
+			})
+		}
+		t.Run(name+"_properties", func(t *testing.T) {
+			props, err := adapter.GetProperties(ctx, obj)
+			require.NoError(t, err)
+			require.Equal(t, size, props.Size)
+		})
+		t.Run(name+"_get", func(t *testing.T) {
+			data, err := readAll(adapter.Get(ctx, obj, size))
+			require.NoError(t, err)
+			require.Equal(t, contents, data)
+		})
+	}
+}
+
+func TestAdapter_UploadCopyPart(t *testing.T) {
+	ctx := compress.WithCodec(context.Background(), compress.CodecGzip)
+	adapter, underlying := newTestAdapter()
+	const contents = "uncompressed contents"
+	plainObj := block.ObjectPointer{
+		StorageNamespace: testStorageNamespace,
+		Identifier:       "plain",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}
+	require.NoError(t, underlying.Put(ctx, plainObj, int64(len(contents)), strings.NewReader(contents), block.PutOpts{}))
+	compressedObj := block.ObjectPointer{
+		StorageNamespace: testStorageNamespace,
+		Identifier:       "compressed",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}
+	require.NoError(t, adapter.Put(ctx, compressedObj, int64(len(contents)), strings.NewReader(contents), block.PutOpts{}))
+
+	// parts copied from compressed and uncompressed objects form one object,
+	// stored as the codec of the upload
+	for _, codec := range []compress.Codec{compress.CodecNone, compress.CodecGzip} {
+		t.Run(codec.String(), func(t *testing.T) {
+			obj := block.ObjectPointer{
+				StorageNamespace: testStorageNamespace,
+				Identifier:       "copy_" + codec.String(),
+				IdentifierType:   block.IdentifierTypeRelative,
+			}
+			upload, err := adapter.CreateMultiPartUpload(compress.WithCodec(ctx, codec), obj, nil, block.CreateMultiPartUploadOpts{})
+			require.NoError(t, err)
+			completion := &block.MultipartUploadCompletion{}
+			for i, source := range []block.ObjectPointer{plainObj, compressedObj} {
+				resp, err := adapter.UploadCopyPart(ctx, source, obj, upload.UploadID, i+1)
+				require.NoError(t, err)
+				completion.Part = append(completion.Part, block.MultipartPart{ETag: resp.ETag, PartNumber: i + 1})
+			}
+			for i, source := range []block.ObjectPointer{plainObj, compressedObj} {
+				resp, err := adapter.UploadCopyPartRange(ctx, source, obj, upload.UploadID, i+3, 2, 5)
+				require.NoError(t, err)
+				completion.Part = append(completion.Part, block.MultipartPart{ETag: resp.ETag, PartNumber: i + 3})
+			}
+			parts, err := adapter.ListParts(ctx, obj, upload.UploadID)
+			require.NoError(t, err)
+			require.Len(t, parts, 4)
+			completeResp, err := adapter.CompleteMultiPartUpload(ctx, obj, upload.UploadID, completion)
+			require.NoError(t, err)
+			expected := contents + contents + contents[2:6] + contents[2:6]
+			require.Equal(t, int64(len(expected)), completeResp.ContentLength)
+
+			entryCtx := compress.WithEntry(ctx, compress.MultipartEntryMetadata(ctx, adapter, upload.UploadID, nil, completeResp.ContentLength))
+			data, err := readAll(adapter.Get(entryCtx, obj, -1))
+			require.NoError(t, err)
+			require.Equal(t, expected, string(data))
+			stored, err := readAll(underlying.Get(ctx, obj, -1))
+			require.NoError(t, err)
+			if codec == compress.CodecNone {
+				require.Equal(t, expected, string(stored), "parts of an upload without a codec are stored as they are")
+			} else {
+				require.NotEqual(t, expected, string(stored))
+			}
+		})
+	}
+}
+
+// presignAdapter pre-signs URLs of any object.
+type presignAdapter struct {
+	block.Adapter
+}
+
+func (a *presignAdapter) GetPreSignedURL(_ context.Context, obj block.ObjectPointer, _ block.PreSignMode) (string, time.Time, error) {
+	return "https://presigned/" + obj.Identifier, time.Time{}, nil
+}
+
+func TestAdapter_GetPreSignedURL(t *testing.T) {
+	ctx := context.Background()
+	underlying := mem.New(ctx)
+	adapter := compress.NewAdapter(&presignAdapter{Adapter: underlying})
+	zstdCtx := compress.WithCodec(ctx, compress.CodecZstd)
+	contents := compressible(1000)
+	objects := map[string]block.ObjectPointer{}
+	for _, name := range []string{"raw", "compressed", "empty"} {
+		objects[name] = block.ObjectPointer{StorageNamespace: testStorageNamespace, Identifier: name, IdentifierType: block.IdentifierTypeRelative}
+	}
+	require.NoError(t, adapter.Put(ctx, objects["raw"], int64(len(contents)), bytes.NewReader(contents), block.PutOpts{}))
+	require.NoError(t, adapter.Put(zstdCtx, objects["compressed"], int64(len(contents)), bytes.NewReader(contents), block.PutOpts{}))
+	require.NoError(t, adapter.Put(ctx, objects["empty"], 0, bytes.NewReader(nil), block.PutOpts{}))
+	rawCtx := compress.WithEntry(ctx, compress.EntryMetadata(ctx, adapter, nil, int64(len(contents))))
+	compressedCtx := compress.WithEntry(ctx, compress.EntryMetadata(zstdCtx, adapter, nil, int64(len(contents))))
+
+	cases := []struct {
+		name      string
+		ctx       context.Context
+		object    string
+		mode      block.PreSignMode
+		supported bool
+	}{
+		{name: "write without codec", ctx: ctx, object: "raw", mode: block.PreSignModeWrite, supported: true},
+		{name: "write with codec", ctx: zstdCtx, object: "raw", mode: block.PreSignModeWrite},
+		{name: "read raw entry", ctx: rawCtx, object: "raw", mode: block.PreSignModeRead, supported: true},
+		{name: "read compressed entry", ctx: compressedCtx, object: "compressed", mode: block.PreSignModeRead},
+		{name: "read raw without entry", ctx: ctx, object: "raw", mode: block.PreSignModeRead, supported: true},
+		{name: "read compressed without entry", ctx: ctx, object: "compressed", mode: block.PreSignModeRead},
+		{name: "read empty without entry", ctx: ctx, object: "empty", mode: block.PreSignModeRead, supported: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			url, _, err := adapter.GetPreSignedURL(tt.ctx, objects[tt.object], tt.mode)
+			if !tt.supported {
+				require.ErrorIs(t, err, block.ErrOperationNotSupported)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "https://presigned/"+tt.object, url)
+		})
+	}
+}
+
+func TestAdapter_Encrypted(t *testing.T) {
+	ctx := compress.WithCodec(context.Background(), compress.CodecZstd)
+	// compressed data is encrypted, as built by the block adapter factory
+	adapter := compress.NewAdapter(encrypt.NewAdapter(mem.New(ctx), crypt.NewSecretStore([]byte("some secret"))))
+	contents := strings.Repeat("compressible line of a log file\n", 10000)
+	tail := randstr.String(50)
+
+	// written with unknown size, as by the API
+	source := block.ObjectPointer{
+		StorageNamespace: testStorageNamespace,
+		Identifier:       "source",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}
+	require.NoError(t, adapter.Put(ctx, source, -1, strings.NewReader(contents), block.PutOpts{}))
+	props, err := adapter.GetProperties(ctx, source)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(contents)), props.Size)
+
+	obj := block.ObjectPointer{
+		StorageNamespace: testStorageNamespace,
+		Identifier:       "obj",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}
+	upload, err := adapter.CreateMultiPartUpload(ctx, obj, nil, block.CreateMultiPartUploadOpts{})
+	require.NoError(t, err)
+	copyResp, err := adapter.UploadCopyPart(ctx, source, obj, upload.UploadID, 1)
+	require.NoError(t, err)
+	tailResp, err := adapter.UploadPart(ctx, obj, int64(len(tail)), strings.NewReader(tail), upload.UploadID, 2)
+	require.NoError(t, err)
+	completeResp, err := adapter.CompleteMultiPartUpload(ctx, obj, upload.UploadID, &block.MultipartUploadCompletion{
+		Part: []block.MultipartPart{{ETag: copyResp.ETag, PartNumber: 1}, {ETag: tailResp.ETag, PartNumber: 2}},
+	})
+	require.NoError(t, err)
+
+	expected := contents + tail
+	require.Equal(t, int64(len(expected)), completeResp.ContentLength)
+	data, err := readAll(adapter.Get(ctx, obj, -1))
+	require.NoError(t, err)
+	require.Equal(t, expected, string(data))
+	start := int64(len(contents) - 10)
+	data, err = readAll(adapter.GetRange(ctx, obj, start, start+19))
+	require.NoError(t, err)
+	require.Equal(t, expected[start:start+20], string(data))
+}
+
+func TestAdapter_Corrupted(t *testing.T) {
+	ctx := compress.WithCodec(context.Background(), compress.CodecZstd)
+	adapter, underlying := newTestAdapter()
+	contents := compressible(2*compress.FrameSize + 10)
+	obj := block.ObjectPointer{
+		StorageNamespace: testStorageNamespace,
+		Identifier:       "obj",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}
+	require.NoError(t, adapter.Put(ctx, obj, int64(len(contents)), bytes.NewReader(contents), block.PutOpts{}))
+	stored, err := readAll(underlying.Get(ctx, obj, -1))
+	require.NoError(t, err)
+
+	// drop the middle of the stored object, keeping its footer
+	modified := append(append([]byte(nil), stored[:len(stored)/2]...), stored[len(stored)-100:]...)
+	require.NoError(t, underlying.Put(ctx, obj, int64(len(modified)), bytes.NewReader(modified), block.PutOpts{}))
+	_, err = readAll(adapter.Get(ctx, obj, -1))
+	require.ErrorIs(t, err, compress.ErrBadFormat)
+	_, err = readAll(adapter.GetRange(ctx, obj, 0, 10))
+	require.ErrorIs(t, err, compress.ErrBadFormat)
+}
+
+func readAll(reader io.ReadCloser, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,454 @@
+package compress
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// A compressed object is a sequence of segments.  Put writes a single
+// segment, and each part of a multipart upload is a segment.  A segment is
+// a header, frames, a frame index and a footer:
+//
+//	magic (8) | codec (1)
+//	frame 0 | frame 1 | ... | frame n-1 | terminator
+//	index: n * (compressed length (4) | uncompressed length (4))
+//	n (4) | uncompressed length (8) | segment size (8) | magic (8)
+//
+// Each frame is a compressed length (4), an uncompressed length (4) and
+// FrameSize bytes of data, compressed independently by the codec of the
+// segment; the last frame may be shorter.  The terminator is a frame
+// header of zero lengths, so that the segment can be read as a stream.
+// The footer ends the segment and holds its size, so that the segments of
+// an object can be found from its end, and the index allows reading only
+// the frames that hold a range.
+
+const (
+	magic = "LKFSCMP1"
+
+	// FrameSize is the size of the uncompressed data in each frame.
+	FrameSize = 1024 * 1024
+
+	headerSize      = len(magic) + 1
+	frameHeaderSize = 8
+	indexEntrySize  = 8
+	footerSize      = 4 + 8 + 8 + len(magic)
+
+	// maxFrameSize bounds the lengths read from frame headers, allowing
+	// for data that does not compress.
+	maxFrameSize = 2 * FrameSize
+)
+
+var (
+	ErrBadFormat    = errors.New("bad compressed object format")
+	ErrUnknownCodec = errors.New("unknown compression codec")
+	ErrSizeMismatch = errors.New("object size does not match the declared size")
+)
+
+// Codec is a compression codec.
+type Codec byte
+
+const (
+	CodecNone Codec = iota
+	CodecGzip
+	CodecZstd
+)
+
+var codecNames = map[Codec]string{
+	CodecNone: "none",
+	CodecGzip: "gzip",
+	CodecZstd: "zstd",
+}
+
+func (c Codec) String() string {
+	if name, ok := codecNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Codec(%d)", byte(c))
+}
+
+// ParseCodec returns the codec named name.  An empty name is CodecNone.
+func ParseCodec(name string) (Codec, error) {
+	if name == "" {
+		return CodecNone, nil
+	}
+	for c, n := range codecNames {
+		if n == name {
+			return c, nil
+		}
+	}
+	return CodecNone, fmt.Errorf("%w: %s", ErrUnknownCodec, name)
+}
+
+var (
+	// zstd encoders and decoders are safe for concurrent EncodeAll and
+	// DecodeAll calls.
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+func compressFrame(codec Codec, data []byte) ([]byte, error) {
+	switch codec {
+	case CodecNone:
+		return data, nil
+	case CodecGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case CodecZstd:
+		return zstdEncoder.EncodeAll(data, nil), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCodec, codec)
+	}
+}
+
+func decompressFrame(codec Codec, data []byte, length int) ([]byte, error) {
+	var (
+		out []byte
+		err error
+	)
+	switch codec {
+	case CodecNone:
+		out = data
+	case CodecGzip:
+		var r *gzip.Reader
+		r, err = gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrBadFormat, err)
+		}
+		out = make([]byte, 0, length)
+		out, err = readAllInto(out, r)
+	case CodecZstd:
+		out, err = zstdDecoder.DecodeAll(data, make([]byte, 0, length))
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCodec, codec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrBadFormat, err)
+	}
+	if len(out) != length {
+		return nil, fmt.Errorf("%w: frame of %d bytes, expected %d", ErrBadFormat, len(out), length)
+	}
+	return out, nil
+}
+
+func readAllInto(buf []byte, r io.Reader) ([]byte, error) {
+	w := bytes.NewBuffer(buf)
+	_, err := io.Copy(w, r)
+	return w.Bytes(), err
+}
+
+func hasMagic(buf []byte) bool {
+	return len(buf) >= len(magic) && string(buf[:len(magic)]) == magic
+}
+
+type indexEntry struct {
+	compressed   uint32
+	uncompressed uint32
+}
+
+type footer struct {
+	frames int
+	// length is the uncompressed length of the segment.
+	length int64
+	// size is the stored size of the segment.
+	size int64
+}
+
+func (f *footer) marshal() []byte {
+	buf := make([]byte, footerSize)
+	binary.BigEndian.PutUint32(buf, uint32(f.frames))
+	binary.BigEndian.PutUint64(buf[4:], uint64(f.length))
+	binary.BigEndian.PutUint64(buf[12:], uint64(f.size))
+	copy(buf[20:], magic)
+	return buf
+}
+
+// unmarshalFooter returns the footer in buf, or nil if buf does not end
+// with a footer.
+func unmarshalFooter(buf []byte) (*footer, error) {
+	if len(buf) != footerSize || !hasMagic(buf[20:]) {
+		return nil, nil
+	}
+	f := &footer{
+		frames: int(binary.BigEndian.Uint32(buf)),
+		length: int64(binary.BigEndian.Uint64(buf[4:])),
+		size:   int64(binary.BigEndian.Uint64(buf[12:])),
+	}
+	if f.length < 0 || f.size < int64(headerSize+frameHeaderSize+footerSize)+int64(f.frames)*indexEntrySize {
+		return nil, fmt.Errorf("%w: bad footer", ErrBadFormat)
+	}
+	return f, nil
+}
+
+// indexOffset returns the offset of the frame index in the segment.
+func (f *footer) indexOffset() int64 {
+	return f.size - int64(footerSize) - int64(f.frames)*indexEntrySize
+}
+
+func unmarshalIndex(buf []byte) []indexEntry {
+	index := make([]indexEntry, len(buf)/indexEntrySize)
+	for i := range index {
+		index[i] = indexEntry{
+			compressed:   binary.BigEndian.Uint32(buf[i*indexEntrySize:]),
+			uncompressed: binary.BigEndian.Uint32(buf[i*indexEntrySize+4:]),
+		}
+	}
+	return index
+}
+
+// compressReader reads a segment that compresses the data of source.
+type compressReader struct {
+	codec  Codec
+	source io.Reader
+	// expected is the declared length of the data, or -1 if unknown.
+	expected int64
+	length   int64
+	size     int64
+	index    []indexEntry
+	buf      []byte
+	pending  []byte
+	done     bool
+}
+
+func newCompressReader(codec Codec, source io.Reader, expected int64) *compressReader {
+	r := &compressReader{
+		codec:    codec,
+		source:   source,
+		expected: expected,
+		buf:      make([]byte, FrameSize),
+	}
+	r.pending = append([]byte(magic), byte(codec))
+	return r
+}
+
+func (r *compressReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	r.size += int64(n)
+	return n, nil
+}
+
+// fill sets pending to the next frame, or to the trailer of the segment
+// after the last frame.
+func (r *compressReader) fill() error {
+	n, err := io.ReadFull(r.source, r.buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	if n > 0 {
+		data, err := compressFrame(r.codec, r.buf[:n])
+		if err != nil {
+			return err
+		}
+		if len(data) > math.MaxUint32 {
+			return fmt.Errorf("%w: frame too large", ErrBadFormat)
+		}
+		entry := indexEntry{compressed: uint32(len(data)), uncompressed: uint32(n)}
+		r.index = append(r.index, entry)
+		r.length += int64(n)
+		r.pending = append(frameHeader(entry), data...)
+		return nil
+	}
+	if r.expected >= 0 && r.length != r.expected {
+		return fmt.Errorf("%w: read %d bytes, expected %d", ErrSizeMismatch, r.length, r.expected)
+	}
+	trailer := make([]byte, frameHeaderSize, frameHeaderSize+len(r.index)*indexEntrySize+footerSize)
+	for _, entry := range r.index {
+		trailer = append(trailer, frameHeader(entry)...)
+	}
+	f := footer{
+		frames: len(r.index),
+		length: r.length,
+		size:   r.size + int64(len(trailer)) + int64(footerSize),
+	}
+	r.pending = append(trailer, f.marshal()...)
+	r.done = true
+	return nil
+}
+
+func frameHeader(entry indexEntry) []byte {
+	buf := make([]byte, frameHeaderSize)
+	binary.BigEndian.PutUint32(buf, entry.compressed)
+	binary.BigEndian.PutUint32(buf[4:], entry.uncompressed)
+	return buf
+}
+
+func readFrameHeader(r io.Reader) (indexEntry, error) {
+	var buf [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return indexEntry{}, truncated(err)
+	}
+	entry := indexEntry{
+		compressed:   binary.BigEndian.Uint32(buf[:]),
+		uncompressed: binary.BigEndian.Uint32(buf[4:]),
+	}
+	if entry.compressed > maxFrameSize || entry.uncompressed > maxFrameSize {
+		return indexEntry{}, fmt.Errorf("%w: bad frame header", ErrBadFormat)
+	}
+	return entry, nil
+}
+
+// readFrame reads and decompresses the frame that follows entry.
+func readFrame(r io.Reader, codec Codec, entry indexEntry) ([]byte, error) {
+	data := make([]byte, entry.compressed)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, truncated(err)
+	}
+	return decompressFrame(codec, data, int(entry.uncompressed))
+}
+
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: truncated data", ErrBadFormat)
+	}
+	return err
+}
+
+// decompressReader reads the data of a stored object of one or more
+// segments.
+type decompressReader struct {
+	source *bufio.Reader
+	closer io.Closer
+	codec  Codec
+	// frames is the number of frames read in the current segment, or -1
+	// between segments.
+	frames  int
+	pending []byte
+	done    bool
+}
+
+func newDecompressReader(source *bufio.Reader, closer io.Closer) *decompressReader {
+	return &decompressReader{
+		source: source,
+		closer: closer,
+		frames: -1,
+	}
+}
+
+func (r *decompressReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// next reads the next frame into pending.
+func (r *decompressReader) next() error {
+	if r.frames < 0 {
+		var h [headerSize]byte
+		if _, err := io.ReadFull(r.source, h[:]); err != nil {
+			return truncated(err)
+		}
+		if !hasMagic(h[:]) {
+			return fmt.Errorf("%w: bad segment header", ErrBadFormat)
+		}
+		r.codec = Codec(h[len(magic)])
+		r.frames = 0
+	}
+	entry, err := readFrameHeader(r.source)
+	if err != nil {
+		return err
+	}
+	if entry.compressed != 0 || entry.uncompressed != 0 {
+		r.pending, err = readFrame(r.source, r.codec, entry)
+		r.frames++
+		return err
+	}
+	// end of frames: skip the index and check the footer
+	if _, err := r.source.Discard(r.frames * indexEntrySize); err != nil {
+		return truncated(err)
+	}
+	buf := make([]byte, footerSize)
+	if _, err := io.ReadFull(r.source, buf); err != nil {
+		return truncated(err)
+	}
+	f, err := unmarshalFooter(buf)
+	if err != nil {
+		return err
+	}
+	if f == nil || f.frames != r.frames {
+		return fmt.Errorf("%w: bad footer", ErrBadFormat)
+	}
+	r.frames = -1
+	if _, err := r.source.Peek(1); errors.Is(err, io.EOF) {
+		r.done = true
+	}
+	return nil
+}
+
+func (r *decompressReader) Close() error {
+	return r.closer.Close()
+}
+
+// rangeReader reads decompressed data from frames of a segment, starting
+// at a frame boundary.
+type rangeReader struct {
+	codec     Codec
+	source    io.ReadCloser
+	skip      int64
+	remaining int64
+	pending   []byte
+}
+
+func (r *rangeReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.remaining <= 0 {
+			return 0, io.EOF
+		}
+		entry, err := readFrameHeader(r.source)
+		if err != nil {
+			return 0, err
+		}
+		data, err := readFrame(r.source, r.codec, entry)
+		if err != nil {
+			return 0, err
+		}
+		if r.skip >= int64(len(data)) {
+			r.skip -= int64(len(data))
+			continue
+		}
+		data = data[r.skip:]
+		r.skip = 0
+		if int64(len(data)) > r.remaining {
+			data = data[:r.remaining]
+		}
+		r.remaining -= int64(len(data))
+		r.pending = data
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func (r *rangeReader) Close() error {
+	return r.source.Close()
+}
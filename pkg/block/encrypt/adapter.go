@@ -0,0 +1,439 @@
+package encrypt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/auth/crypt"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+// Adapter encrypts the data of objects before passing it to an underlying
+// adapter, and decrypts it when reading it back.  Each stored segment holds
+// its own data key, wrapped by keys.
+type Adapter struct {
+	adapter         block.Adapter
+	keys            crypt.SecretStore
+	readUnencrypted bool
+}
+
+func WithReadUnencrypted(v bool) func(a *Adapter) {
+	return func(a *Adapter) {
+		a.readUnencrypted = v
+	}
+}
+
+func NewAdapter(adapter block.Adapter, keys crypt.SecretStore, opts ...func(a *Adapter)) *Adapter {
+	a := &Adapter{
+		adapter: adapter,
+		keys:    keys,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// AdapterFor returns an encrypting adapter over the adapter that serves
+// storageNamespace.
+func (a *Adapter) AdapterFor(storageNamespace string) block.Adapter {
+	return &Adapter{
+		adapter:         block.AdapterForNamespace(a.adapter, storageNamespace),
+		keys:            a.keys,
+		readUnencrypted: a.readUnencrypted,
+	}
+}
+
+// BlockstoreName returns the name of the blockstore of the underlying
+// adapter that serves storageNamespace.
+func (a *Adapter) BlockstoreName(storageNamespace string) string {
+	return block.BlockstoreName(a.adapter, storageNamespace)
+}
+
+func (a *Adapter) GenerateInventory(ctx context.Context, logger logging.Logger, inventoryURL string, shouldSort bool, prefixes []string) (block.Inventory, error) {
+	return a.adapter.GenerateInventory(ctx, logger, inventoryURL, shouldSort, prefixes)
+}
+
+func (a *Adapter) Put(ctx context.Context, obj block.ObjectPointer, sizeBytes int64, reader io.Reader, opts block.PutOpts) error {
+	r, err := newEncryptReader(a.keys, reader, sizeBytes)
+	if err != nil {
+		return err
+	}
+	return a.adapter.Put(ctx, obj, EncryptedSize(sizeBytes), r, opts)
+}
+
+func (a *Adapter) Get(ctx context.Context, obj block.ObjectPointer, _ int64) (io.ReadCloser, error) {
+	// the stored size differs from the expected plaintext size
+	reader, err := a.adapter.Get(ctx, obj, -1)
+	if err != nil {
+		return nil, err
+	}
+	source := bufio.NewReader(reader)
+	prefix, err := source.Peek(len(magic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		_ = reader.Close()
+		return nil, err
+	}
+	if !hasMagic(prefix) {
+		if !a.readUnencrypted {
+			_ = reader.Close()
+			return nil, ErrNotEncrypted
+		}
+		return &struct {
+			io.Reader
+			io.Closer
+		}{
+			Reader: source,
+			Closer: reader,
+		}, nil
+	}
+	return newDecryptReader(a.keys, source, reader), nil
+}
+
+func (a *Adapter) GetWalker(uri *url.URL) (block.Walker, error) {
+	return a.adapter.GetWalker(uri)
+}
+
+// GetPreSignedURL is not supported: clients would access encrypted data.
+func (a *Adapter) GetPreSignedURL(_ context.Context, _ block.ObjectPointer, _ block.PreSignMode) (string, time.Time, error) {
+	return "", time.Time{}, fmt.Errorf("encrypted block adapter presigned URL: %w", block.ErrOperationNotSupported)
+}
+
+func (a *Adapter) Exists(ctx context.Context, obj block.ObjectPointer) (bool, error) {
+	return a.adapter.Exists(ctx, obj)
+}
+
+// segment is a stored segment of an object.
+type segment struct {
+	header *header
+	// offset is the offset of the segment in the stored object.
+	offset int64
+	// start is the offset of the plaintext of the segment in the object.
+	start int64
+}
+
+// segments returns the segments of obj that hold plaintext up to offset
+// end.  It returns nil if obj is not encrypted.
+func (a *Adapter) segments(ctx context.Context, obj block.ObjectPointer, end int64) ([]segment, error) {
+	var (
+		segments []segment
+		offset   int64
+		start    int64
+	)
+	for start <= end {
+		reader, err := a.adapter.GetRange(ctx, obj, offset, offset+int64(headerSize)-1)
+		if err != nil {
+			return nil, err
+		}
+		buf, err := io.ReadAll(reader)
+		_ = reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		if offset == 0 && !hasMagic(buf) {
+			return nil, nil
+		}
+		if len(buf) == 0 {
+			// end of object
+			break
+		}
+		h, err := unmarshalHeader(a.keys, buf)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment{header: h, offset: offset, start: start})
+		if h.length < 0 {
+			break
+		}
+		offset += h.size()
+		start += h.length
+	}
+	return segments, nil
+}
+
+// GetRange reads the plaintext between startPosition and endPosition.  It
+// reads the header of each segment of obj up to endPosition, and then only
+// the chunks that hold the range.
+func (a *Adapter) GetRange(ctx context.Context, obj block.ObjectPointer, startPosition int64, endPosition int64) (io.ReadCloser, error) {
+	if startPosition < 0 || endPosition < startPosition {
+		return nil, block.ErrBadIndex
+	}
+	segments, err := a.segments(ctx, obj, endPosition)
+	if err != nil {
+		return nil, err
+	}
+	if segments == nil {
+		if !a.readUnencrypted {
+			return nil, ErrNotEncrypted
+		}
+		return a.adapter.GetRange(ctx, obj, startPosition, endPosition)
+	}
+	readers := make([]*lazyRangeReader, 0, len(segments))
+	for _, s := range segments {
+		s := s
+		h := s.header
+		start := startPosition - s.start
+		if start < 0 {
+			start = 0
+		}
+		end := endPosition - s.start
+		if h.length >= 0 {
+			if start >= h.length {
+				continue
+			}
+			if end >= h.length {
+				end = h.length - 1
+			}
+		}
+		readers = append(readers, &lazyRangeReader{
+			open: func() (io.ReadCloser, error) {
+				return a.readSegmentRange(ctx, obj, s, start, end)
+			},
+		})
+	}
+	multiReaders := make([]io.Reader, len(readers))
+	for i, r := range readers {
+		multiReaders[i] = r
+	}
+	return &multiReadCloser{
+		Reader:  io.MultiReader(multiReaders...),
+		readers: readers,
+	}, nil
+}
+
+// readSegmentRange reads plaintext offsets start to end of segment s.
+func (a *Adapter) readSegmentRange(ctx context.Context, obj block.ObjectPointer, s segment, start, end int64) (io.ReadCloser, error) {
+	h := s.header
+	first := start / h.chunkSize
+	last := end / h.chunkSize
+	source, err := a.adapter.GetRange(ctx, obj, s.offset+h.chunkOffset(first), s.offset+h.chunkOffset(last+1)-1)
+	if err != nil {
+		return nil, err
+	}
+	return &rangeReader{
+		header:    h,
+		source:    source,
+		index:     first,
+		skip:      start - first*h.chunkSize,
+		remaining: end - start + 1,
+	}, nil
+}
+
+// lazyRangeReader opens its reader on first read.
+type lazyRangeReader struct {
+	open   func() (io.ReadCloser, error)
+	reader io.ReadCloser
+}
+
+func (r *lazyRangeReader) Read(p []byte) (int, error) {
+	if r.reader == nil {
+		reader, err := r.open()
+		if err != nil {
+			return 0, err
+		}
+		r.reader = reader
+	}
+	return r.reader.Read(p)
+}
+
+func (r *lazyRangeReader) Close() error {
+	if r.reader == nil {
+		return nil
+	}
+	return r.reader.Close()
+}
+
+type multiReadCloser struct {
+	io.Reader
+	readers []*lazyRangeReader
+}
+
+func (m *multiReadCloser) Close() error {
+	var err error
+	for _, r := range m.readers {
+		if closeErr := r.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// GetProperties returns the properties of the stored object, with the
+// plaintext size.
+func (a *Adapter) GetProperties(ctx context.Context, obj block.ObjectPointer) (block.Properties, error) {
+	props, err := a.adapter.GetProperties(ctx, obj)
+	if err != nil {
+		return props, err
+	}
+	props.Size, err = a.plaintextSize(ctx, obj, props.Size)
+	return props, err
+}
+
+func (a *Adapter) Remove(ctx context.Context, obj block.ObjectPointer) error {
+	return a.adapter.Remove(ctx, obj)
+}
+
+// Copy copies the stored data: each segment holds its own wrapped data key.
+func (a *Adapter) Copy(ctx context.Context, sourceObj, destinationObj block.ObjectPointer) error {
+	return a.adapter.Copy(ctx, sourceObj, destinationObj)
+}
+
+func (a *Adapter) CreateMultiPartUpload(ctx context.Context, obj block.ObjectPointer, r *http.Request, opts block.CreateMultiPartUploadOpts) (*block.CreateMultiPartUploadResponse, error) {
+	return a.adapter.CreateMultiPartUpload(ctx, obj, r, opts)
+}
+
+// UploadPart stores the part as a segment.  A segment that is not last
+// must hold its length, so a part of unknown size is read into memory.
+func (a *Adapter) UploadPart(ctx context.Context, obj block.ObjectPointer, sizeBytes int64, reader io.Reader, uploadID string, partNumber int) (*block.UploadPartResponse, error) {
+	if sizeBytes < 0 {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		sizeBytes = int64(len(data))
+		reader = bytes.NewReader(data)
+	}
+	r, err := newEncryptReader(a.keys, reader, sizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	return a.adapter.UploadPart(ctx, obj, EncryptedSize(sizeBytes), r, uploadID, partNumber)
+}
+
+// UploadCopyPart copies the stored data if every segment of the source
+// object holds its length, so its segments can be segments of the part.
+// Otherwise, as for objects written with unknown size, the last segment
+// must stay last: the part is decrypted and encrypted again.
+func (a *Adapter) UploadCopyPart(ctx context.Context, sourceObj, destinationObj block.ObjectPointer, uploadID string, partNumber int) (*block.UploadPartResponse, error) {
+	props, err := a.adapter.GetProperties(ctx, sourceObj)
+	if err != nil {
+		return nil, err
+	}
+	length, sealed, err := a.scanSegments(ctx, sourceObj, props.Size)
+	if err != nil {
+		return nil, err
+	}
+	if sealed {
+		return a.adapter.UploadCopyPart(ctx, sourceObj, destinationObj, uploadID, partNumber)
+	}
+	reader, err := a.Get(ctx, sourceObj, length)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	return a.UploadPart(ctx, destinationObj, length, reader, uploadID, partNumber)
+}
+
+// UploadCopyPartRange reads the plaintext range and uploads it as a part.
+func (a *Adapter) UploadCopyPartRange(ctx context.Context, sourceObj, destinationObj block.ObjectPointer, uploadID string, partNumber int, startPosition, endPosition int64) (*block.UploadPartResponse, error) {
+	reader, err := a.GetRange(ctx, sourceObj, startPosition, endPosition)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	return a.UploadPart(ctx, destinationObj, endPosition-startPosition+1, reader, uploadID, partNumber)
+}
+
+func (a *Adapter) AbortMultiPartUpload(ctx context.Context, obj block.ObjectPointer, uploadID string) error {
+	return a.adapter.AbortMultiPartUpload(ctx, obj, uploadID)
+}
+
+// ListParts lists the stored parts, whose ETags are those UploadPart returned.
+func (a *Adapter) ListParts(ctx context.Context, obj block.ObjectPointer, uploadID string) ([]block.MultipartPart, error) {
+	return a.adapter.ListParts(ctx, obj, uploadID)
+}
+
+// CompleteMultiPartUpload completes the upload, and reports the plaintext
+// length of the object.
+func (a *Adapter) CompleteMultiPartUpload(ctx context.Context, obj block.ObjectPointer, uploadID string, multipartList *block.MultipartUploadCompletion) (*block.CompleteMultiPartUploadResponse, error) {
+	resp, err := a.adapter.CompleteMultiPartUpload(ctx, obj, uploadID, multipartList)
+	if err != nil {
+		return nil, err
+	}
+	resp.ContentLength, err = a.plaintextSize(ctx, obj, resp.ContentLength)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// plaintextSize returns the plaintext length of obj, whose stored size is
+// size, from the headers of its segments.
+func (a *Adapter) plaintextSize(ctx context.Context, obj block.ObjectPointer, size int64) (int64, error) {
+	length, _, err := a.scanSegments(ctx, obj, size)
+	return length, err
+}
+
+// scanSegments returns the plaintext length of obj, whose stored size is
+// size, from the headers of its segments.  It also returns whether every
+// segment holds its length: false if the last one does not, or if obj is
+// not encrypted.
+func (a *Adapter) scanSegments(ctx context.Context, obj block.ObjectPointer, size int64) (int64, bool, error) {
+	var (
+		offset int64
+		length int64
+	)
+	for offset < size {
+		reader, err := a.adapter.GetRange(ctx, obj, offset, offset+int64(headerSize)-1)
+		if err != nil {
+			return 0, false, err
+		}
+		buf, err := io.ReadAll(reader)
+		_ = reader.Close()
+		if err != nil {
+			return 0, false, err
+		}
+		if offset == 0 && !hasMagic(buf) {
+			if !a.readUnencrypted {
+				return 0, false, ErrNotEncrypted
+			}
+			return size, false, nil
+		}
+		h, err := unmarshalHeader(a.keys, buf)
+		if err != nil {
+			return 0, false, err
+		}
+		if h.length < 0 {
+			// the last segment: its size is what remains
+			return length + plaintextLength(h, size-offset), false, nil
+		}
+		offset += h.size()
+		length += h.length
+	}
+	return length, true, nil
+}
+
+func (a *Adapter) BlockstoreType() string {
+	return a.adapter.BlockstoreType()
+}
+
+// GetStorageNamespaceInfo returns the information of the underlying
+// adapter, without pre-signed URLs.  Imported objects are not encrypted, so
+// import is supported only when unencrypted objects may be read.
+func (a *Adapter) GetStorageNamespaceInfo() block.StorageNamespaceInfo {
+	info := a.adapter.GetStorageNamespaceInfo()
+	info.PreSignSupport = false
+	info.PreSignSupportUI = false
+	info.ImportSupport = info.ImportSupport && a.readUnencrypted
+	return info
+}
+
+func (a *Adapter) ResolveNamespace(storageNamespace, key string, identifierType block.IdentifierType) (block.QualifiedKey, error) {
+	return a.adapter.ResolveNamespace(storageNamespace, key, identifierType)
+}
+
+func (a *Adapter) RuntimeStats() map[string]string {
+	return a.adapter.RuntimeStats()
+}
@@ -0,0 +1,280 @@
+package encrypt_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thanhpk/randstr"
+	"github.com/treeverse/lakefs/pkg/auth/crypt"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/blocktest"
+	"github.com/treeverse/lakefs/pkg/block/encrypt"
+	"github.com/treeverse/lakefs/pkg/block/local"
+	"github.com/treeverse/lakefs/pkg/block/mem"
+)
+
+const testStorageNamespace = "mem://test"
+
+func newTestAdapter(opts ...func(a *encrypt.Adapter)) (*encrypt.Adapter, *mem.Adapter) {
+	underlying := mem.New(context.Background())
+	return encrypt.NewAdapter(underlying, crypt.NewSecretStore([]byte("some secret")), opts...), underlying
+}
+
+func TestLocalAdapter(t *testing.T) {
+	tmpDir := t.TempDir()
+	localPath := path.Join(tmpDir, "lakefs")
+	externalPath := block.BlockstoreTypeLocal + "://" + path.Join(tmpDir, "lakefs", "external")
+	underlying, err := local.NewAdapter(localPath, local.WithRemoveEmptyDir(false))
+	require.NoError(t, err)
+	adapter := encrypt.NewAdapter(underlying, crypt.NewSecretStore([]byte("some secret")))
+	blocktest.AdapterTest(t, adapter, "local://test", externalPath)
+}
+
+func TestAdapter_PutGet(t *testing.T) {
+	ctx := context.Background()
+	adapter, underlying := newTestAdapter()
+
+	sizes := []int{0, 1, encrypt.ChunkSize - 1, encrypt.ChunkSize, encrypt.ChunkSize + 1, 3*encrypt.ChunkSize + 17}
+	for _, size := range sizes {
+		contents := randstr.Bytes(size)
+		for _, knownSize := range []bool{true, false} {
+			t.Run(fmt.Sprintf("size_%d_known_%t", size, knownSize), func(t *testing.T) {
+				obj := block.ObjectPointer{
+					StorageNamespace: testStorageNamespace,
+					Identifier:       fmt.Sprintf("obj_%d_%t", size, knownSize),
+					IdentifierType:   block.IdentifierTypeRelative,
+				}
+				sizeBytes := int64(size)
+				if !knownSize {
+					sizeBytes = -1
+				}
+				require.NoError(t, adapter.Put(ctx, obj, sizeBytes, bytes.NewReader(contents), block.PutOpts{}))
+
+				stored, err := readAll(underlying.Get(ctx, obj, -1))
+				require.NoError(t, err)
+				if knownSize {
+					require.Equal(t, encrypt.EncryptedSize(int64(size)), int64(len(stored)))
+				}
+				if size >= 16 {
+					require.False(t, bytes.Contains(stored, contents), "plaintext found in stored object")
+				}
+
+				data, err := readAll(adapter.Get(ctx, obj, int64(size)))
+				require.NoError(t, err)
+				require.Equal(t, contents, data)
+			})
+		}
+	}
+}
+
+func TestAdapter_PutSizeMismatch(t *testing.T) {
+	ctx := context.Background()
+	adapter, _ := newTestAdapter()
+	obj := block.ObjectPointer{
+		StorageNamespace: testStorageNamespace,
+		Identifier:       "mismatch",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}
+	err := adapter.Put(ctx, obj, 10, bytes.NewReader([]byte("short")), block.PutOpts{})
+	require.ErrorIs(t, err, encrypt.ErrSizeMismatch)
+}
+
+func TestAdapter_GetRange(t *testing.T) {
+	ctx := context.Background()
+	adapter, _ := newTestAdapter()
+
+	// a single segment of known size, one of unknown size and a multipart
+	// object of several segments
+	parts := [][]byte{
+		randstr.Bytes(2*encrypt.ChunkSize + 100),
+		randstr.Bytes(encrypt.ChunkSize),
+		randstr.Bytes(1000),
+	}
+	contents := bytes.Join(parts, nil)
+	objects := map[string]block.ObjectPointer{}
+	for _, name := range []string{"known", "unknown", "multipart"} {
+		objects[name] = block.ObjectPointer{
+			StorageNamespace: testStorageNamespace,
+			Identifier:       name,
+			IdentifierType:   block.IdentifierTypeRelative,
+		}
+	}
+	require.NoError(t, adapter.Put(ctx, objects["known"], int64(len(contents)), bytes.NewReader(contents), block.PutOpts{}))
+	require.NoError(t, adapter.Put(ctx, objects["unknown"], -1, bytes.NewReader(contents), block.PutOpts{}))
+	multipartObj := objects["multipart"]
+	upload, err := adapter.CreateMultiPartUpload(ctx, multipartObj, nil, block.CreateMultiPartUploadOpts{})
+	require.NoError(t, err)
+	completion := &block.MultipartUploadCompletion{}
+	for i, part := range parts {
+		resp, err := adapter.UploadPart(ctx, multipartObj, int64(len(part)), bytes.NewReader(part), upload.UploadID, i+1)
+		require.NoError(t, err)
+		completion.Part = append(completion.Part, block.MultipartPart{ETag: resp.ETag, PartNumber: i + 1})
+	}
+	completeResp, err := adapter.CompleteMultiPartUpload(ctx, multipartObj, upload.UploadID, completion)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(contents)), completeResp.ContentLength)
+
+	size := int64(len(contents))
+	ranges := []struct {
+		name       string
+		start, end int64
+	}{
+		{"first_byte", 0, 0},
+		{"first_chunk", 0, encrypt.ChunkSize - 1},
+		{"across_chunks", encrypt.ChunkSize - 10, encrypt.ChunkSize + 10},
+		{"across_segments", 2*encrypt.ChunkSize + 50, 3*encrypt.ChunkSize + 200},
+		{"middle", 12345, 3 * encrypt.ChunkSize},
+		{"last_byte", size - 1, size - 1},
+		{"all", 0, size - 1},
+		{"out_of_bounds", 100, size + 1000},
+	}
+	for name, obj := range objects {
+		for _, tt := range ranges {
+			t.Run(name+"_"+tt.name, func(t *testing.T) {
+				expected := contents[tt.start:]
+				if tt.end < size {
+					expected = contents[tt.start : tt.end+1]
+				}
+				data, err := readAll(adapter.GetRange(ctx, obj, tt.start, tt.end))
+				require.NoError(t, err)
+				require.Equal(t, expected, data)
+			})
+		}
+		t.Run(name+"_properties", func(t *testing.T) {
+			props, err := adapter.GetProperties(ctx, obj)
+			require.NoError(t, err)
+			require.Equal(t, size, props.Size)
+		})
+		t.Run(name+"_get", func(t *testing.T) {
+			data, err := readAll(adapter.Get(ctx, obj, size))
+			require.NoError(t, err)
+			require.Equal(t, contents, data)
+		})
+	}
+}
+
+func TestAdapter_UploadCopyPart(t *testing.T) {
+	ctx := context.Background()
+	adapter, _ := newTestAdapter()
+	source := randstr.Bytes(100)
+	tail := randstr.Bytes(50)
+
+	// the last segment of an object written with unknown size holds no
+	// length, so it cannot be copied as is into a part that is not last
+	for _, knownSize := range []bool{true, false} {
+		t.Run(fmt.Sprintf("known_%t", knownSize), func(t *testing.T) {
+			sourceObj := block.ObjectPointer{
+				StorageNamespace: testStorageNamespace,
+				Identifier:       fmt.Sprintf("source_%t", knownSize),
+				IdentifierType:   block.IdentifierTypeRelative,
+			}
+			sizeBytes := int64(len(source))
+			if !knownSize {
+				sizeBytes = -1
+			}
+			require.NoError(t, adapter.Put(ctx, sourceObj, sizeBytes, bytes.NewReader(source), block.PutOpts{}))
+
+			obj := block.ObjectPointer{
+				StorageNamespace: testStorageNamespace,
+				Identifier:       fmt.Sprintf("copy_%t", knownSize),
+				IdentifierType:   block.IdentifierTypeRelative,
+			}
+			upload, err := adapter.CreateMultiPartUpload(ctx, obj, nil, block.CreateMultiPartUploadOpts{})
+			require.NoError(t, err)
+			copyResp, err := adapter.UploadCopyPart(ctx, sourceObj, obj, upload.UploadID, 1)
+			require.NoError(t, err)
+			tailResp, err := adapter.UploadPart(ctx, obj, int64(len(tail)), bytes.NewReader(tail), upload.UploadID, 2)
+			require.NoError(t, err)
+			completeResp, err := adapter.CompleteMultiPartUpload(ctx, obj, upload.UploadID, &block.MultipartUploadCompletion{
+				Part: []block.MultipartPart{{ETag: copyResp.ETag, PartNumber: 1}, {ETag: tailResp.ETag, PartNumber: 2}},
+			})
+			require.NoError(t, err)
+
+			contents := append(append([]byte(nil), source...), tail...)
+			require.Equal(t, int64(len(contents)), completeResp.ContentLength)
+			data, err := readAll(adapter.Get(ctx, obj, -1))
+			require.NoError(t, err)
+			require.Equal(t, contents, data)
+			data, err = readAll(adapter.GetRange(ctx, obj, 90, 109))
+			require.NoError(t, err)
+			require.Equal(t, contents[90:110], data)
+		})
+	}
+}
+
+func TestAdapter_Tampering(t *testing.T) {
+	ctx := context.Background()
+	adapter, underlying := newTestAdapter()
+	contents := randstr.Bytes(2*encrypt.ChunkSize + 10)
+	obj := block.ObjectPointer{
+		StorageNamespace: testStorageNamespace,
+		Identifier:       "obj",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}
+	require.NoError(t, adapter.Put(ctx, obj, int64(len(contents)), bytes.NewReader(contents), block.PutOpts{}))
+	stored, err := readAll(underlying.Get(ctx, obj, -1))
+	require.NoError(t, err)
+
+	cases := []struct {
+		name   string
+		modify func([]byte) []byte
+	}{
+		{"flip_bit", func(b []byte) []byte { b[len(b)/2] ^= 1; return b }},
+		{"truncate", func(b []byte) []byte { return b[:len(b)-100] }},
+		{"drop_last_chunk", func(b []byte) []byte { return b[:encrypt.EncryptedSize(2*encrypt.ChunkSize)-16] }},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			modified := tt.modify(append([]byte(nil), stored...))
+			require.NoError(t, underlying.Put(ctx, obj, int64(len(modified)), bytes.NewReader(modified), block.PutOpts{}))
+			_, err := readAll(adapter.Get(ctx, obj, -1))
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("wrong_key", func(t *testing.T) {
+		require.NoError(t, underlying.Put(ctx, obj, int64(len(stored)), bytes.NewReader(stored), block.PutOpts{}))
+		other := encrypt.NewAdapter(underlying, crypt.NewSecretStore([]byte("another secret")))
+		_, err := other.GetRange(ctx, obj, 0, 10)
+		require.ErrorIs(t, err, crypt.ErrFailDecrypt)
+	})
+}
+
+func TestAdapter_ReadUnencrypted(t *testing.T) {
+	ctx := context.Background()
+	const contents = "plaintext contents"
+	obj := block.ObjectPointer{
+		StorageNamespace: testStorageNamespace,
+		Identifier:       "plain",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}
+
+	adapter, underlying := newTestAdapter()
+	require.NoError(t, underlying.Put(ctx, obj, int64(len(contents)), bytes.NewReader([]byte(contents)), block.PutOpts{}))
+	_, err := adapter.Get(ctx, obj, -1)
+	require.True(t, errors.Is(err, encrypt.ErrNotEncrypted), "Get returned %v", err)
+	_, err = adapter.GetRange(ctx, obj, 0, 5)
+	require.True(t, errors.Is(err, encrypt.ErrNotEncrypted), "GetRange returned %v", err)
+
+	readAdapter := encrypt.NewAdapter(underlying, crypt.NewSecretStore([]byte("some secret")), encrypt.WithReadUnencrypted(true))
+	data, err := readAll(readAdapter.Get(ctx, obj, -1))
+	require.NoError(t, err)
+	require.Equal(t, contents, string(data))
+	data, err = readAll(readAdapter.GetRange(ctx, obj, 2, 5))
+	require.NoError(t, err)
+	require.Equal(t, contents[2:6], string(data))
+}
+
+func readAll(reader io.ReadCloser, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+	return io.ReadAll(reader)
+}
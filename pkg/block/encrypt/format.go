@@ -0,0 +1,483 @@
+package encrypt
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/treeverse/lakefs/pkg/auth/crypt"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// An encrypted object is a sequence of segments.  Put writes a single
+// segment, and each part of a multipart upload is a segment.  A segment is
+// a header followed by chunks:
+//
+//	magic (8) | chunk size (4) | plaintext length (8) | wrapped data key (80)
+//	chunk 0 | chunk 1 | ... | chunk n-1
+//
+// The data key is random for each segment, and is wrapped (encrypted) by
+// the key store.  Each chunk seals ChunkSize bytes of plaintext, except for
+// the last chunk which may be shorter, using secretbox with the data key.
+// The nonce of a chunk is its index, with the top bit set on the last
+// chunk so that truncation is detected.  Chunks can be opened
+// independently, which allows ranged reads.
+//
+// The plaintext length is unknownLength if the segment was written without
+// knowing its size; such a segment ends the object.
+
+const (
+	magic = "LKFSENC1"
+
+	// ChunkSize is the size of the plaintext sealed in each chunk.
+	ChunkSize = 64 * 1024
+
+	wrappedKeySize = crypt.KeySaltBytes + crypt.NonceSizeBytes + crypt.KeySizeBytes + secretbox.Overhead
+	headerSize     = len(magic) + 4 + 8 + wrappedKeySize
+
+	unknownLength  = math.MaxUint64
+	finalChunkFlag = 1 << 63
+)
+
+var (
+	ErrNotEncrypted  = errors.New("object is not encrypted")
+	ErrBadFormat     = errors.New("bad encrypted object format")
+	ErrSizeMismatch  = errors.New("object size does not match the declared size")
+	ErrTruncatedData = fmt.Errorf("%w: truncated data", ErrBadFormat)
+)
+
+type header struct {
+	chunkSize int64
+	// length is the plaintext length of the segment, or -1 if the segment
+	// extends to the end of the object.
+	length int64
+	key    [crypt.KeySizeBytes]byte
+}
+
+func newHeader(length int64) (*header, error) {
+	h := &header{
+		chunkSize: ChunkSize,
+		length:    length,
+	}
+	if _, err := io.ReadFull(rand.Reader, h.key[:]); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *header) marshal(keys crypt.SecretStore) ([]byte, error) {
+	wrappedKey, err := keys.Encrypt(h.key[:])
+	if err != nil {
+		return nil, err
+	}
+	if len(wrappedKey) != wrappedKeySize {
+		return nil, fmt.Errorf("%w: wrapped key of %d bytes", ErrBadFormat, len(wrappedKey))
+	}
+	buf := make([]byte, 0, headerSize)
+	buf = append(buf, magic...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(h.chunkSize))
+	length := uint64(unknownLength)
+	if h.length >= 0 {
+		length = uint64(h.length)
+	}
+	buf = binary.BigEndian.AppendUint64(buf, length)
+	return append(buf, wrappedKey...), nil
+}
+
+func hasMagic(buf []byte) bool {
+	return bytes.HasPrefix(buf, []byte(magic))
+}
+
+func unmarshalHeader(keys crypt.SecretStore, buf []byte) (*header, error) {
+	if len(buf) != headerSize || !hasMagic(buf) {
+		return nil, ErrBadFormat
+	}
+	buf = buf[len(magic):]
+	h := &header{
+		chunkSize: int64(binary.BigEndian.Uint32(buf)),
+		length:    -1,
+	}
+	if length := binary.BigEndian.Uint64(buf[4:]); length != unknownLength {
+		if length > math.MaxInt64 {
+			return nil, ErrBadFormat
+		}
+		h.length = int64(length)
+	}
+	if h.chunkSize <= 0 {
+		return nil, ErrBadFormat
+	}
+	key, err := keys.Decrypt(buf[12:])
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != crypt.KeySizeBytes {
+		return nil, ErrBadFormat
+	}
+	copy(h.key[:], key)
+	return h, nil
+}
+
+// readHeader reads and unwraps a segment header from r.
+func readHeader(keys crypt.SecretStore, r io.Reader) (*header, error) {
+	buf := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrTruncatedData
+		}
+		return nil, err
+	}
+	return unmarshalHeader(keys, buf)
+}
+
+// numChunks returns the number of chunks in the segment.  It is valid only
+// if the length of the segment is known.
+func (h *header) numChunks() int64 {
+	if h.length == 0 {
+		return 1
+	}
+	return (h.length + h.chunkSize - 1) / h.chunkSize
+}
+
+// size returns the size of the stored segment.  It is valid only if the
+// length of the segment is known.
+func (h *header) size() int64 {
+	return int64(headerSize) + h.length + h.numChunks()*secretbox.Overhead
+}
+
+// chunkOffset returns the offset of chunk index from the start of the
+// segment.
+func (h *header) chunkOffset(index int64) int64 {
+	return int64(headerSize) + index*(h.chunkSize+secretbox.Overhead)
+}
+
+// plaintextLength returns the plaintext length of segment h stored in size
+// bytes.
+func plaintextLength(h *header, size int64) int64 {
+	sealedSize := h.chunkSize + secretbox.Overhead
+	chunksSize := size - int64(headerSize)
+	chunks := (chunksSize + sealedSize - 1) / sealedSize
+	return chunksSize - chunks*secretbox.Overhead
+}
+
+// EncryptedSize returns the stored size of an object of size bytes written
+// by Put, or -1 if size is unknown.
+func EncryptedSize(size int64) int64 {
+	if size < 0 {
+		return -1
+	}
+	h := header{chunkSize: ChunkSize, length: size}
+	return h.size()
+}
+
+func chunkNonce(index int64, final bool) *[crypt.NonceSizeBytes]byte {
+	var nonce [crypt.NonceSizeBytes]byte
+	n := uint64(index)
+	if final {
+		n |= finalChunkFlag
+	}
+	binary.BigEndian.PutUint64(nonce[crypt.NonceSizeBytes-8:], n)
+	return &nonce
+}
+
+func (h *header) seal(index int64, final bool, plaintext []byte) []byte {
+	return secretbox.Seal(nil, plaintext, chunkNonce(index, final), &h.key)
+}
+
+func (h *header) open(index int64, final bool, sealed []byte) ([]byte, error) {
+	plaintext, ok := secretbox.Open(nil, sealed, chunkNonce(index, final), &h.key)
+	if !ok {
+		return nil, crypt.ErrFailDecrypt
+	}
+	return plaintext, nil
+}
+
+// encryptReader reads a single encrypted segment holding the plaintext of
+// source.
+type encryptReader struct {
+	header *header
+	source *bufio.Reader
+	plain  []byte
+	buf    []byte
+	index  int64
+	read   int64
+	done   bool
+}
+
+// newEncryptReader returns a reader of the segment encrypting length bytes
+// of source, or all of source if length is negative.
+func newEncryptReader(keys crypt.SecretStore, source io.Reader, length int64) (*encryptReader, error) {
+	if length < 0 {
+		length = -1
+	}
+	h, err := newHeader(length)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := h.marshal(keys)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptReader{
+		header: h,
+		source: bufio.NewReader(source),
+		plain:  make([]byte, h.chunkSize),
+		buf:    buf,
+	}, nil
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.nextChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *encryptReader) nextChunk() error {
+	n, err := io.ReadFull(r.source, r.plain)
+	final := false
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		final = true
+	case err != nil:
+		return err
+	default:
+		_, err := r.source.Peek(1)
+		if errors.Is(err, io.EOF) {
+			final = true
+		} else if err != nil {
+			return err
+		}
+	}
+	r.read += int64(n)
+	if r.header.length >= 0 && (r.read > r.header.length || final && r.read != r.header.length) {
+		return fmt.Errorf("%w: read %d bytes, expected %d", ErrSizeMismatch, r.read, r.header.length)
+	}
+	r.buf = r.header.seal(r.index, final, r.plain[:n])
+	r.index++
+	r.done = final
+	return nil
+}
+
+// decryptReader reads the plaintext of all segments of an object.
+type decryptReader struct {
+	keys   crypt.SecretStore
+	source *bufio.Reader
+	closer io.Closer
+	// header is the header of the current segment, or nil between
+	// segments.
+	header    *header
+	segments  int
+	index     int64
+	remaining int64
+	sealed    []byte
+	buf       []byte
+}
+
+func newDecryptReader(keys crypt.SecretStore, source *bufio.Reader, closer io.Closer) *decryptReader {
+	return &decryptReader{
+		keys:   keys,
+		source: source,
+		closer: closer,
+	}
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.header == nil {
+			if _, err := r.source.Peek(1); errors.Is(err, io.EOF) && r.segments > 0 {
+				return 0, io.EOF
+			}
+			h, err := readHeader(r.keys, r.source)
+			if err != nil {
+				return 0, err
+			}
+			r.header = h
+			r.segments++
+			r.index = 0
+			r.remaining = h.length
+		}
+		if err := r.nextChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *decryptReader) nextChunk() error {
+	h := r.header
+	sealedSize := h.chunkSize + secretbox.Overhead
+	if int64(cap(r.sealed)) < sealedSize {
+		r.sealed = make([]byte, sealedSize)
+	}
+	var (
+		sealed []byte
+		final  bool
+	)
+	if h.length >= 0 {
+		plainSize := h.chunkSize
+		if r.remaining < plainSize {
+			plainSize = r.remaining
+		}
+		sealed = r.sealed[:plainSize+secretbox.Overhead]
+		if _, err := io.ReadFull(r.source, sealed); err != nil {
+			return truncated(err)
+		}
+		r.remaining -= plainSize
+		final = r.index == h.numChunks()-1
+	} else {
+		n, err := io.ReadFull(r.source, r.sealed[:sealedSize])
+		switch {
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			final = true
+		case err != nil:
+			return truncated(err)
+		default:
+			_, err := r.source.Peek(1)
+			if errors.Is(err, io.EOF) {
+				final = true
+			} else if err != nil {
+				return err
+			}
+		}
+		sealed = r.sealed[:n]
+	}
+	plaintext, err := h.open(r.index, final, sealed)
+	if err != nil {
+		return err
+	}
+	r.buf = plaintext
+	r.index++
+	if final {
+		if h.length < 0 {
+			if _, err := r.source.Peek(1); !errors.Is(err, io.EOF) {
+				return fmt.Errorf("%w: data after the last segment", ErrBadFormat)
+			}
+		}
+		r.header = nil
+	}
+	return nil
+}
+
+func (r *decryptReader) Close() error {
+	return r.closer.Close()
+}
+
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrTruncatedData
+	}
+	return err
+}
+
+// rangeReader reads plaintext from the chunks of a segment, starting at
+// chunk index of the segment.  source reads the stored chunks starting at
+// that chunk.
+type rangeReader struct {
+	header    *header
+	source    io.ReadCloser
+	index     int64
+	skip      int64
+	remaining int64
+	sealed    []byte
+	buf       []byte
+}
+
+func (r *rangeReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.remaining == 0 {
+			return 0, io.EOF
+		}
+		if err := r.nextChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *rangeReader) nextChunk() error {
+	h := r.header
+	sealedSize := h.chunkSize + secretbox.Overhead
+	if r.sealed == nil {
+		r.sealed = make([]byte, sealedSize)
+	}
+	var (
+		plaintext []byte
+		last      bool
+	)
+	if h.length >= 0 {
+		plainSize := h.length - r.index*h.chunkSize
+		if plainSize > h.chunkSize {
+			plainSize = h.chunkSize
+		}
+		sealed := r.sealed[:plainSize+secretbox.Overhead]
+		if _, err := io.ReadFull(r.source, sealed); err != nil {
+			return truncated(err)
+		}
+		var err error
+		plaintext, err = h.open(r.index, r.index == h.numChunks()-1, sealed)
+		if err != nil {
+			return err
+		}
+	} else {
+		n, err := io.ReadFull(r.source, r.sealed)
+		switch {
+		case errors.Is(err, io.EOF):
+			// the range starts after the end of the object
+			r.remaining = 0
+			return nil
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			plaintext, err = h.open(r.index, true, r.sealed[:n])
+		case err != nil:
+			return err
+		default:
+			// a full chunk may or may not be the last one
+			plaintext, err = h.open(r.index, false, r.sealed)
+			if err != nil {
+				plaintext, err = h.open(r.index, true, r.sealed)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		// a short chunk is the last chunk of the object
+		last = int64(len(plaintext)) < h.chunkSize
+	}
+	r.index++
+	if r.skip > 0 {
+		if r.skip >= int64(len(plaintext)) {
+			r.skip -= int64(len(plaintext))
+			return nil
+		}
+		plaintext = plaintext[r.skip:]
+		r.skip = 0
+	}
+	if int64(len(plaintext)) > r.remaining {
+		plaintext = plaintext[:r.remaining]
+	}
+	r.remaining -= int64(len(plaintext))
+	if last {
+		r.remaining = 0
+	}
+	r.buf = plaintext
+	return nil
+}
+
+func (r *rangeReader) Close() error {
+	return r.source.Close()
+}
@@ -11,11 +11,15 @@ import (
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/treeverse/lakefs/pkg/auth/crypt"
 	"github.com/treeverse/lakefs/pkg/block"
 	"github.com/treeverse/lakefs/pkg/block/azure"
+	"github.com/treeverse/lakefs/pkg/block/compress"
+	"github.com/treeverse/lakefs/pkg/block/encrypt"
 	"github.com/treeverse/lakefs/pkg/block/gs"
 	"github.com/treeverse/lakefs/pkg/block/local"
 	"github.com/treeverse/lakefs/pkg/block/mem"
+	"github.com/treeverse/lakefs/pkg/block/multi"
 	"github.com/treeverse/lakefs/pkg/block/params"
 	s3a "github.com/treeverse/lakefs/pkg/block/s3"
 	"github.com/treeverse/lakefs/pkg/block/transient"
@@ -30,7 +34,67 @@ const (
 	googleAuthCloudPlatform = "https://www.googleapis.com/auth/cloud-platform"
 )
 
+// BuildBlockAdapter returns the adapter configured by c.  If c configures
+// additional named adapters, it returns an adapter that passes each request
+// to the adapter of its storage namespace.  If c configures encryption, the
+// returned adapter encrypts object data for all of them.  If c configures
+// compression, data is compressed before it is encrypted.
 func BuildBlockAdapter(ctx context.Context, statsCollector stats.Collector, c params.AdapterConfig) (block.Adapter, error) {
+	adapter, err := buildMultiAdapter(ctx, statsCollector, c)
+	if err != nil {
+		return nil, err
+	}
+	encryptionParams, err := c.BlockstoreEncryptionParams()
+	if err != nil {
+		return nil, err
+	}
+	if encryptionParams.Enabled {
+		logging.FromContext(ctx).
+			WithField("read_unencrypted", encryptionParams.ReadUnencrypted).
+			Info("initialized blockstore encryption")
+		adapter = encrypt.NewAdapter(adapter, crypt.NewSecretStore(encryptionParams.Key),
+			encrypt.WithReadUnencrypted(encryptionParams.ReadUnencrypted),
+		)
+	}
+	if c.BlockstoreCompressionParams().Enabled {
+		logging.FromContext(ctx).Info("initialized blockstore compression")
+		adapter = compress.NewAdapter(adapter)
+	}
+	return adapter, nil
+}
+
+func buildMultiAdapter(ctx context.Context, statsCollector stats.Collector, c params.AdapterConfig) (block.Adapter, error) {
+	adapter, err := buildAdapter(ctx, statsCollector, c)
+	if err != nil {
+		return nil, err
+	}
+	namedConfigs, err := c.BlockstoreAdapters()
+	if err != nil {
+		return nil, err
+	}
+	if len(namedConfigs) == 0 {
+		return adapter, nil
+	}
+	namedAdapters := make([]multi.NamedAdapter, 0, len(namedConfigs))
+	for _, n := range namedConfigs {
+		namedAdapter, err := buildAdapter(ctx, statsCollector, n.Config)
+		if err != nil {
+			return nil, fmt.Errorf("blockstore adapter %s: %w", n.Name, err)
+		}
+		logging.FromContext(ctx).WithFields(logging.Fields{
+			"name":               n.Name,
+			"namespace_prefixes": n.NamespacePrefixes,
+		}).Info("initialized named blockstore adapter")
+		namedAdapters = append(namedAdapters, multi.NamedAdapter{
+			Name:              n.Name,
+			NamespacePrefixes: n.NamespacePrefixes,
+			Adapter:           namedAdapter,
+		})
+	}
+	return multi.NewAdapter(adapter, namedAdapters), nil
+}
+
+func buildAdapter(ctx context.Context, statsCollector stats.Collector, c params.AdapterConfig) (block.Adapter, error) {
 	blockstore := c.BlockstoreType()
 	logging.FromContext(ctx).
 		WithField("type", blockstore).
@@ -116,8 +180,49 @@ func BuildS3Client(awsConfig *aws.Config, webIdentity *params.S3WebIdentity, ski
 	return sess, nil
 }
 
-func buildS3Adapter(ctx context.Context, statsCollector stats.Collector, params params.S3) (*s3a.Adapter, error) {
-	sess, err := BuildS3Client(params.AwsConfig, params.WebIdentity, params.SkipVerifyCertificateTestOnly)
+func buildS3Adapter(ctx context.Context, statsCollector stats.Collector, params params.S3) (block.Adapter, error) {
+	adapter, err := newS3Adapter(statsCollector, params, params.AwsConfig, params.Compat)
+	if err != nil {
+		return nil, err
+	}
+	if len(params.Endpoints) == 0 {
+		logging.FromContext(ctx).WithField("type", "s3").Info("initialized blockstore adapter")
+		return adapter, nil
+	}
+	endpoints := make([]multi.NamedAdapter, 0, len(params.Endpoints))
+	for _, e := range params.Endpoints {
+		endpointAdapter, err := newS3Adapter(statsCollector, params, e.AwsConfig, e.Compat)
+		if err != nil {
+			return nil, fmt.Errorf("endpoint for %s: %w", e.NamespacePrefix, err)
+		}
+		endpoints = append(endpoints, multi.NamedAdapter{
+			Name:              e.NamespacePrefix,
+			NamespacePrefixes: []string{e.NamespacePrefix},
+			Adapter:           endpointAdapter,
+		})
+	}
+	logging.FromContext(ctx).WithFields(logging.Fields{
+		"type":      "s3",
+		"endpoints": len(endpoints) + 1,
+	}).Info("initialized blockstore adapter")
+	return multi.NewAdapter(adapter, endpoints), nil
+}
+
+// newS3Adapter returns an adapter for the S3-compatible store compat at the
+// endpoint of awsConfig, using the other settings of params.
+func newS3Adapter(statsCollector stats.Collector, params params.S3, awsConfig *aws.Config, compat string) (*s3a.Adapter, error) {
+	c, err := s3a.ParseCompat(compat)
+	if err != nil {
+		return nil, err
+	}
+	profile := c.Profile()
+	if !profile.ServerSideEncryption && (params.ServerSideEncryption != "" || params.ServerSideEncryptionKmsKeyID != "") {
+		return nil, fmt.Errorf("%w: server side encryption on %s", block.ErrOperationNotSupported, c)
+	}
+	if profile.ForcePathStyle {
+		awsConfig = awsConfig.Copy().WithS3ForcePathStyle(true)
+	}
+	sess, err := BuildS3Client(awsConfig, params.WebIdentity, params.SkipVerifyCertificateTestOnly)
 	if err != nil {
 		return nil, err
 	}
@@ -129,6 +234,7 @@ func buildS3Adapter(ctx context.Context, statsCollector stats.Collector, params
 		s3a.WithPreSignedExpiry(params.PreSignedExpiry),
 		s3a.WithDisablePreSigned(params.DisablePreSigned),
 		s3a.WithDisablePreSignedUI(params.DisablePreSignedUI),
+		s3a.WithCompat(c),
 	}
 	if params.ServerSideEncryption != "" {
 		opts = append(opts, s3a.WithServerSideEncryption(params.ServerSideEncryption))
@@ -139,9 +245,7 @@ func buildS3Adapter(ctx context.Context, statsCollector stats.Collector, params
 	if params.WebIdentity != nil && params.WebIdentity.SessionExpiryWindow > 0 {
 		opts = append(opts, s3a.WithPreSignedRefreshWindow(params.WebIdentity.SessionExpiryWindow))
 	}
-	adapter := s3a.NewAdapter(sess, opts...)
-	logging.FromContext(ctx).WithField("type", "s3").Info("initialized blockstore adapter")
-	return adapter, nil
+	return s3a.NewAdapter(sess, opts...), nil
 }
 
 func BuildGSClient(ctx context.Context, params params.GS) (*storage.Client, error) {
@@ -8,11 +8,13 @@ import (
 	"net/http"
 	"net/url"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"cloud.google.com/go/storage"
 	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/ingest/inventory"
 	"github.com/treeverse/lakefs/pkg/logging"
 	"google.golang.org/api/iterator"
 )
@@ -207,10 +209,11 @@ func (a *Adapter) GetProperties(ctx context.Context, obj block.ObjectPointer) (b
 	if err != nil {
 		return props, err
 	}
-	_, err = a.client.Bucket(bucket).Object(key).Attrs(ctx)
+	attrs, err := a.client.Bucket(bucket).Object(key).Attrs(ctx)
 	if err != nil {
 		return props, err
 	}
+	props.Size = attrs.Size
 	return props, nil
 }
 
@@ -398,6 +401,32 @@ func (a *Adapter) AbortMultiPartUpload(ctx context.Context, obj block.ObjectPoin
 	return nil
 }
 
+func (a *Adapter) ListParts(ctx context.Context, obj block.ObjectPointer, uploadID string) ([]block.MultipartPart, error) {
+	var err error
+	defer reportMetrics("ListParts", time.Now(), nil, &err)
+	bucketName, _, err := a.extractParamsFromObj(obj)
+	if err != nil {
+		return nil, err
+	}
+	bucketParts, err := a.listMultipartUploadParts(ctx, bucketName, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]block.MultipartPart, 0, len(bucketParts))
+	prefix := uploadID + partSuffix
+	for _, attrs := range bucketParts {
+		partNumber, err := strconv.Atoi(strings.TrimPrefix(attrs.Name, prefix))
+		if err != nil {
+			return nil, fmt.Errorf("part %s: %w", attrs.Name, ErrMismatchPartName)
+		}
+		parts = append(parts, block.MultipartPart{
+			ETag:       attrs.Etag,
+			PartNumber: partNumber,
+		})
+	}
+	return parts, nil
+}
+
 func (a *Adapter) CompleteMultiPartUpload(ctx context.Context, obj block.ObjectPointer, uploadID string, multipartList *block.MultipartUploadCompletion) (*block.CompleteMultiPartUploadResponse, error) {
 	var err error
 	defer reportMetrics("CompleteMultiPartUpload", time.Now(), nil, &err)
@@ -529,8 +558,14 @@ func (a *Adapter) composeMultipartUploadParts(ctx context.Context, bucketName st
 	return targetAttrs, nil
 }
 
-func (a *Adapter) GenerateInventory(_ context.Context, _ logging.Logger, _ string, _ bool, _ []string) (block.Inventory, error) {
-	return nil, fmt.Errorf("inventory %w", ErrNotImplemented)
+// GenerateInventory reads the inventory report whose manifest is at
+// manifestURL.  Reports are read in their order, they cannot be sorted or
+// filtered by prefixes.
+func (a *Adapter) GenerateInventory(ctx context.Context, _ logging.Logger, manifestURL string, shouldSort bool, prefixes []string) (block.Inventory, error) {
+	if shouldSort || len(prefixes) > 0 {
+		return nil, fmt.Errorf("sorted inventory %w", ErrNotImplemented)
+	}
+	return inventory.Load(ctx, a, inventory.KindGCS, manifestURL)
 }
 
 func (a *Adapter) Close() error {
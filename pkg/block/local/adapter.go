@@ -350,12 +350,11 @@ func (l *Adapter) GetProperties(_ context.Context, obj block.ObjectPointer) (blo
 	if err != nil {
 		return block.Properties{}, err
 	}
-	_, err = os.Stat(p)
+	info, err := os.Stat(p)
 	if err != nil {
 		return block.Properties{}, err
 	}
-	// No properties, just return that it exists
-	return block.Properties{}, nil
+	return block.Properties{Size: info.Size()}, nil
 }
 
 // isDirectoryWritable tests that pth, which must not be controllable by user input, is a
@@ -418,6 +417,51 @@ func (l *Adapter) AbortMultiPartUpload(_ context.Context, obj block.ObjectPointe
 	return nil
 }
 
+func (l *Adapter) ListParts(_ context.Context, obj block.ObjectPointer, uploadID string) ([]block.MultipartPart, error) {
+	if err := isValidUploadID(uploadID); err != nil {
+		return nil, err
+	}
+	partFiles, err := l.getPartFiles(uploadID, obj)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]block.MultipartPart, 0, len(partFiles))
+	for _, name := range partFiles {
+		partNumber, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(name), uploadID+"-"))
+		if err != nil {
+			return nil, fmt.Errorf("part file %s: %w", name, err)
+		}
+		etag, err := l.fileETag(name)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, block.MultipartPart{
+			ETag:       etag,
+			PartNumber: partNumber,
+		})
+	}
+	return parts, nil
+}
+
+// fileETag returns the ETag UploadPart returned for the part file name
+func (l *Adapter) fileETag(name string) (string, error) {
+	if err := l.verifyRelPath(name); err != nil {
+		return "", err
+	}
+	f, err := os.Open(filepath.Clean(name))
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	h := md5.New() //nolint:gosec
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func (l *Adapter) CompleteMultiPartUpload(_ context.Context, obj block.ObjectPointer, uploadID string, multipartList *block.MultipartUploadCompletion) (*block.CompleteMultiPartUploadResponse, error) {
 	if err := isValidUploadID(uploadID); err != nil {
 		return nil, err
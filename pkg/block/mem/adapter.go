@@ -43,7 +43,10 @@ func newMPU() *mpu {
 
 func (m *mpu) get() []byte {
 	buf := bytes.NewBuffer(nil)
-	keys := make([]int, len(m.parts))
+	keys := make([]int, 0, len(m.parts))
+	for part := range m.parts {
+		keys = append(keys, part)
+	}
 	sort.Slice(keys, func(i, j int) bool {
 		return keys[i] < keys[j]
 	})
@@ -93,7 +96,7 @@ func (a *Adapter) Put(_ context.Context, obj block.ObjectPointer, _ int64, reade
 	}
 	key := getKey(obj)
 	a.data[key] = data
-	a.properties[key] = block.Properties(opts)
+	a.properties[key] = block.Properties{StorageClass: opts.StorageClass}
 	return nil
 }
 
@@ -163,10 +166,13 @@ func (a *Adapter) GetProperties(_ context.Context, obj block.ObjectPointer) (blo
 	}
 	a.mutex.RLock()
 	defer a.mutex.RUnlock()
-	props, ok := a.properties[getKey(obj)]
+	key := getKey(obj)
+	data, ok := a.data[key]
 	if !ok {
 		return block.Properties{}, ErrNoPropertiesForKey
 	}
+	props := a.properties[key]
+	props.Size = int64(len(data))
 	return props, nil
 }
 
@@ -196,7 +202,7 @@ func (a *Adapter) Copy(_ context.Context, sourceObj, destinationObj block.Object
 	return nil
 }
 
-func (a *Adapter) UploadCopyPart(ctx context.Context, sourceObj, _ block.ObjectPointer, uploadID string, partNumber int) (*block.UploadPartResponse, error) {
+func (a *Adapter) UploadCopyPart(_ context.Context, sourceObj, _ block.ObjectPointer, uploadID string, partNumber int) (*block.UploadPartResponse, error) {
 	if err := verifyObjectPointer(sourceObj); err != nil {
 		return nil, err
 	}
@@ -206,16 +212,12 @@ func (a *Adapter) UploadCopyPart(ctx context.Context, sourceObj, _ block.ObjectP
 	if !ok {
 		return nil, ErrMultiPartNotFound
 	}
-	entry, err := a.Get(ctx, sourceObj, 0)
-	if err != nil {
-		return nil, err
-	}
-	data, err := io.ReadAll(entry)
-	if err != nil {
-		return nil, err
+	data, ok := a.data[getKey(sourceObj)]
+	if !ok {
+		return nil, ErrNoDataForKey
 	}
 	h := sha256.New()
-	_, err = h.Write(data)
+	_, err := h.Write(data)
 	if err != nil {
 		return nil, err
 	}
@@ -313,6 +315,29 @@ func (a *Adapter) AbortMultiPartUpload(_ context.Context, obj block.ObjectPointe
 	return nil
 }
 
+func (a *Adapter) ListParts(_ context.Context, obj block.ObjectPointer, uploadID string) ([]block.MultipartPart, error) {
+	if err := verifyObjectPointer(obj); err != nil {
+		return nil, err
+	}
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	mpu, ok := a.mpu[uploadID]
+	if !ok {
+		return nil, ErrMultiPartNotFound
+	}
+	parts := make([]block.MultipartPart, 0, len(mpu.parts))
+	for partNumber, data := range mpu.parts {
+		parts = append(parts, block.MultipartPart{
+			ETag:       fmt.Sprintf("%x", sha256.Sum256(data)),
+			PartNumber: partNumber,
+		})
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].PartNumber < parts[j].PartNumber
+	})
+	return parts, nil
+}
+
 func (a *Adapter) CompleteMultiPartUpload(_ context.Context, obj block.ObjectPointer, uploadID string, _ *block.MultipartUploadCompletion) (*block.CompleteMultiPartUploadResponse, error) {
 	if err := verifyObjectPointer(obj); err != nil {
 		return nil, err
@@ -0,0 +1,245 @@
+package multi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+// NamedAdapter is an adapter used for the storage namespaces under any of
+// NamespacePrefixes.
+type NamedAdapter struct {
+	Name              string
+	NamespacePrefixes []string
+	Adapter           block.Adapter
+}
+
+// Adapter passes each request to the adapter whose namespace prefix is the
+// longest prefix of the address of its object, or to a default adapter if
+// none matches.  The address of an object is its full identifier if it has
+// one, otherwise its storage namespace.
+type Adapter struct {
+	defaultAdapter block.Adapter
+	adapters       []NamedAdapter
+	routes         block.NamespaceRoutes[block.Adapter]
+}
+
+func NewAdapter(defaultAdapter block.Adapter, adapters []NamedAdapter) *Adapter {
+	var routes block.NamespaceRoutes[block.Adapter]
+	for _, a := range adapters {
+		for _, prefix := range a.NamespacePrefixes {
+			routes.Add(prefix, a.Adapter)
+		}
+	}
+	return &Adapter{
+		defaultAdapter: defaultAdapter,
+		adapters:       adapters,
+		routes:         routes,
+	}
+}
+
+// AdapterFor returns the adapter that serves address, a storage namespace
+// or a full object address.
+func (a *Adapter) AdapterFor(address string) block.Adapter {
+	if adapter, ok := a.routes.Route(address); ok {
+		return adapter
+	}
+	return a.defaultAdapter
+}
+
+// BlockstoreName returns the name of the named adapter that serves address,
+// or an empty name if the default adapter serves it.
+func (a *Adapter) BlockstoreName(address string) string {
+	adapter, ok := a.routes.Route(address)
+	if !ok {
+		return ""
+	}
+	for _, n := range a.adapters {
+		if n.Adapter == adapter {
+			return n.Name
+		}
+	}
+	return ""
+}
+
+// isFullAddress returns true if identifier of identifierType is a full
+// address, rather than relative to its storage namespace.
+func isFullAddress(identifier string, identifierType block.IdentifierType) bool {
+	switch identifierType {
+	case block.IdentifierTypeFull:
+		return true
+	case block.IdentifierTypeUnknownDeprecated:
+		return strings.Contains(identifier, "://")
+	default:
+		return false
+	}
+}
+
+func (a *Adapter) adapterForObj(obj block.ObjectPointer) block.Adapter {
+	if isFullAddress(obj.Identifier, obj.IdentifierType) {
+		return a.AdapterFor(obj.Identifier)
+	}
+	return a.AdapterFor(obj.StorageNamespace)
+}
+
+func (a *Adapter) GenerateInventory(ctx context.Context, logger logging.Logger, inventoryURL string, shouldSort bool, prefixes []string) (block.Inventory, error) {
+	return a.AdapterFor(inventoryURL).GenerateInventory(ctx, logger, inventoryURL, shouldSort, prefixes)
+}
+
+func (a *Adapter) Put(ctx context.Context, obj block.ObjectPointer, sizeBytes int64, reader io.Reader, opts block.PutOpts) error {
+	return a.adapterForObj(obj).Put(ctx, obj, sizeBytes, reader, opts)
+}
+
+func (a *Adapter) Get(ctx context.Context, obj block.ObjectPointer, expectedSize int64) (io.ReadCloser, error) {
+	return a.adapterForObj(obj).Get(ctx, obj, expectedSize)
+}
+
+func (a *Adapter) GetWalker(uri *url.URL) (block.Walker, error) {
+	return a.AdapterFor(uri.String()).GetWalker(uri)
+}
+
+func (a *Adapter) GetPreSignedURL(ctx context.Context, obj block.ObjectPointer, mode block.PreSignMode) (string, time.Time, error) {
+	return a.adapterForObj(obj).GetPreSignedURL(ctx, obj, mode)
+}
+
+func (a *Adapter) Exists(ctx context.Context, obj block.ObjectPointer) (bool, error) {
+	return a.adapterForObj(obj).Exists(ctx, obj)
+}
+
+func (a *Adapter) GetRange(ctx context.Context, obj block.ObjectPointer, startPosition int64, endPosition int64) (io.ReadCloser, error) {
+	return a.adapterForObj(obj).GetRange(ctx, obj, startPosition, endPosition)
+}
+
+func (a *Adapter) GetProperties(ctx context.Context, obj block.ObjectPointer) (block.Properties, error) {
+	return a.adapterForObj(obj).GetProperties(ctx, obj)
+}
+
+func (a *Adapter) Remove(ctx context.Context, obj block.ObjectPointer) error {
+	return a.adapterForObj(obj).Remove(ctx, obj)
+}
+
+// Copy copies within an adapter using that adapter, and between adapters
+// by reading the source and writing the destination.
+func (a *Adapter) Copy(ctx context.Context, sourceObj, destinationObj block.ObjectPointer) error {
+	source := a.adapterForObj(sourceObj)
+	destination := a.adapterForObj(destinationObj)
+	if source == destination {
+		return destination.Copy(ctx, sourceObj, destinationObj)
+	}
+	properties, err := source.GetProperties(ctx, sourceObj)
+	if err != nil {
+		return err
+	}
+	reader, err := source.Get(ctx, sourceObj, properties.Size)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	return destination.Put(ctx, destinationObj, properties.Size, reader, block.PutOpts{})
+}
+
+func (a *Adapter) CreateMultiPartUpload(ctx context.Context, obj block.ObjectPointer, r *http.Request, opts block.CreateMultiPartUploadOpts) (*block.CreateMultiPartUploadResponse, error) {
+	return a.adapterForObj(obj).CreateMultiPartUpload(ctx, obj, r, opts)
+}
+
+func (a *Adapter) UploadPart(ctx context.Context, obj block.ObjectPointer, sizeBytes int64, reader io.Reader, uploadID string, partNumber int) (*block.UploadPartResponse, error) {
+	return a.adapterForObj(obj).UploadPart(ctx, obj, sizeBytes, reader, uploadID, partNumber)
+}
+
+// UploadCopyPart copies within an adapter using that adapter, and between
+// adapters by reading the source and uploading it.
+func (a *Adapter) UploadCopyPart(ctx context.Context, sourceObj, destinationObj block.ObjectPointer, uploadID string, partNumber int) (*block.UploadPartResponse, error) {
+	source := a.adapterForObj(sourceObj)
+	destination := a.adapterForObj(destinationObj)
+	if source == destination {
+		return destination.UploadCopyPart(ctx, sourceObj, destinationObj, uploadID, partNumber)
+	}
+	properties, err := source.GetProperties(ctx, sourceObj)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := source.Get(ctx, sourceObj, properties.Size)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	return destination.UploadPart(ctx, destinationObj, properties.Size, reader, uploadID, partNumber)
+}
+
+// UploadCopyPartRange copies within an adapter using that adapter, and
+// between adapters by reading the range and uploading it.
+func (a *Adapter) UploadCopyPartRange(ctx context.Context, sourceObj, destinationObj block.ObjectPointer, uploadID string, partNumber int, startPosition, endPosition int64) (*block.UploadPartResponse, error) {
+	source := a.adapterForObj(sourceObj)
+	destination := a.adapterForObj(destinationObj)
+	if source == destination {
+		return destination.UploadCopyPartRange(ctx, sourceObj, destinationObj, uploadID, partNumber, startPosition, endPosition)
+	}
+	reader, err := source.GetRange(ctx, sourceObj, startPosition, endPosition)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	return destination.UploadPart(ctx, destinationObj, endPosition-startPosition+1, reader, uploadID, partNumber)
+}
+
+func (a *Adapter) AbortMultiPartUpload(ctx context.Context, obj block.ObjectPointer, uploadID string) error {
+	return a.adapterForObj(obj).AbortMultiPartUpload(ctx, obj, uploadID)
+}
+
+func (a *Adapter) ListParts(ctx context.Context, obj block.ObjectPointer, uploadID string) ([]block.MultipartPart, error) {
+	return a.adapterForObj(obj).ListParts(ctx, obj, uploadID)
+}
+
+func (a *Adapter) CompleteMultiPartUpload(ctx context.Context, obj block.ObjectPointer, uploadID string, multipartList *block.MultipartUploadCompletion) (*block.CompleteMultiPartUploadResponse, error) {
+	return a.adapterForObj(obj).CompleteMultiPartUpload(ctx, obj, uploadID, multipartList)
+}
+
+// BlockstoreType returns the type of the default adapter.  Use
+// block.AdapterForNamespace to get the type of the adapter of a storage
+// namespace.
+func (a *Adapter) BlockstoreType() string {
+	return a.defaultAdapter.BlockstoreType()
+}
+
+// GetStorageNamespaceInfo returns the information of the default adapter.
+func (a *Adapter) GetStorageNamespaceInfo() block.StorageNamespaceInfo {
+	return a.defaultAdapter.GetStorageNamespaceInfo()
+}
+
+func (a *Adapter) ResolveNamespace(storageNamespace, key string, identifierType block.IdentifierType) (block.QualifiedKey, error) {
+	adapter := a.AdapterFor(storageNamespace)
+	if isFullAddress(key, identifierType) {
+		adapter = a.AdapterFor(key)
+	}
+	return adapter.ResolveNamespace(storageNamespace, key, identifierType)
+}
+
+// RuntimeStats returns the stats of the default adapter, and the stats of
+// each named adapter prefixed by its name.
+func (a *Adapter) RuntimeStats() map[string]string {
+	stats := make(map[string]string)
+	for k, v := range a.defaultAdapter.RuntimeStats() {
+		stats[k] = v
+	}
+	for _, n := range a.adapters {
+		for k, v := range n.Adapter.RuntimeStats() {
+			stats[n.Name+"."+k] = v
+		}
+	}
+	if len(stats) == 0 {
+		return nil
+	}
+	return stats
+}
@@ -0,0 +1,137 @@
+package multi_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/local"
+	"github.com/treeverse/lakefs/pkg/block/mem"
+	"github.com/treeverse/lakefs/pkg/block/multi"
+	"github.com/treeverse/lakefs/pkg/testutil"
+)
+
+func TestAdapter_AdapterFor(t *testing.T) {
+	ctx := context.Background()
+	defaultAdapter := mem.New(ctx)
+	archive := mem.New(ctx)
+	coldArchive := mem.New(ctx)
+	adapter := multi.NewAdapter(defaultAdapter, []multi.NamedAdapter{
+		{Name: "archive", NamespacePrefixes: []string{"mem://archive"}, Adapter: archive},
+		{Name: "cold", NamespacePrefixes: []string{"mem://archive/cold", "mem://frozen"}, Adapter: coldArchive},
+	})
+
+	tests := []struct {
+		address      string
+		expected     block.Adapter
+		expectedName string
+	}{
+		{address: "mem://data/repo", expected: defaultAdapter},
+		{address: "mem://archive", expected: archive, expectedName: "archive"},
+		{address: "mem://archive/repo", expected: archive, expectedName: "archive"},
+		{address: "mem://archive2/repo", expected: defaultAdapter},
+		{address: "mem://archive/cold/repo", expected: coldArchive, expectedName: "cold"},
+		{address: "mem://frozen/repo", expected: coldArchive, expectedName: "cold"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			if got := adapter.AdapterFor(tt.address); got != tt.expected {
+				t.Errorf("AdapterFor(%s) returned the wrong adapter", tt.address)
+			}
+			if got := block.AdapterForNamespace(adapter, tt.address); got != tt.expected {
+				t.Errorf("AdapterForNamespace(%s) returned the wrong adapter", tt.address)
+			}
+			if got := block.BlockstoreName(adapter, tt.address); got != tt.expectedName {
+				t.Errorf("BlockstoreName(%s) = %q, expected %q", tt.address, got, tt.expectedName)
+			}
+		})
+	}
+}
+
+// putSizeAdapter records the sizes passed to Put.
+type putSizeAdapter struct {
+	block.Adapter
+	sizes []int64
+}
+
+func (a *putSizeAdapter) Put(ctx context.Context, obj block.ObjectPointer, sizeBytes int64, reader io.Reader, opts block.PutOpts) error {
+	a.sizes = append(a.sizes, sizeBytes)
+	return a.Adapter.Put(ctx, obj, sizeBytes, reader, opts)
+}
+
+func TestAdapter_Routing(t *testing.T) {
+	ctx := context.Background()
+	defaultAdapter := &putSizeAdapter{Adapter: mem.New(ctx)}
+	localAdapter, err := local.NewAdapter(t.TempDir())
+	testutil.Must(t, err)
+	adapter := multi.NewAdapter(defaultAdapter, []multi.NamedAdapter{
+		{Name: "disk", NamespacePrefixes: []string{"local://disk"}, Adapter: localAdapter},
+	})
+
+	const contents = "hello world"
+	memObj := block.ObjectPointer{
+		StorageNamespace: "mem://data/repo",
+		Identifier:       "obj",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}
+	localObj := block.ObjectPointer{
+		StorageNamespace: "local://disk/repo",
+		Identifier:       "obj",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}
+
+	testutil.Must(t, adapter.Put(ctx, localObj, int64(len(contents)), strings.NewReader(contents), block.PutOpts{}))
+	if exists, err := localAdapter.Exists(ctx, localObj); err != nil || !exists {
+		t.Fatalf("object not written to the local adapter: exists=%t, err=%v", exists, err)
+	}
+	if _, err := defaultAdapter.Get(ctx, localObj, -1); err == nil {
+		t.Fatal("object written to the default adapter")
+	}
+
+	// copy between adapters
+	testutil.Must(t, adapter.Copy(ctx, localObj, memObj))
+	if len(defaultAdapter.sizes) != 1 || defaultAdapter.sizes[0] != int64(len(contents)) {
+		t.Errorf("copy put sizes %v, expected [%d]", defaultAdapter.sizes, len(contents))
+	}
+	reader, err := defaultAdapter.Get(ctx, memObj, -1)
+	testutil.Must(t, err)
+	data, err := io.ReadAll(reader)
+	testutil.Must(t, err)
+	if string(data) != contents {
+		t.Errorf("copied %q, expected %q", data, contents)
+	}
+
+	// full addresses route by the address rather than the namespace
+	fullObj := block.ObjectPointer{
+		Identifier:     "mem://data/other/obj",
+		IdentifierType: block.IdentifierTypeFull,
+	}
+	testutil.Must(t, adapter.Put(ctx, fullObj, int64(len(contents)), strings.NewReader(contents), block.PutOpts{}))
+	if _, err := defaultAdapter.Get(ctx, fullObj, -1); err != nil {
+		t.Fatalf("object not written to the default adapter: %s", err)
+	}
+
+	// multipart copies between adapters read the source and upload it
+	partsObj := block.ObjectPointer{
+		StorageNamespace: "mem://data/repo",
+		Identifier:       "parts",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}
+	mpu, err := adapter.CreateMultiPartUpload(ctx, partsObj, nil, block.CreateMultiPartUploadOpts{})
+	testutil.Must(t, err)
+	part, err := adapter.UploadCopyPart(ctx, localObj, partsObj, mpu.UploadID, 1)
+	testutil.Must(t, err)
+	_, err = adapter.CompleteMultiPartUpload(ctx, partsObj, mpu.UploadID, &block.MultipartUploadCompletion{
+		Part: []block.MultipartPart{{ETag: part.ETag, PartNumber: 1}},
+	})
+	testutil.Must(t, err)
+	reader, err = defaultAdapter.Get(ctx, partsObj, -1)
+	testutil.Must(t, err)
+	data, err = io.ReadAll(reader)
+	testutil.Must(t, err)
+	if string(data) != contents {
+		t.Errorf("copied part %q, expected %q", data, contents)
+	}
+}
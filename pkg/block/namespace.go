@@ -3,6 +3,7 @@ package block
 import (
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 )
 
@@ -113,6 +114,50 @@ func ValidateStorageType(uri *url.URL, expectedStorage StorageType) error {
 	return nil
 }
 
+// HasNamespacePrefix returns true if address is under prefix.  Prefixes
+// match whole path elements: "s3://bucket" matches "s3://bucket/key" but
+// not "s3://bucket2/key".
+func HasNamespacePrefix(address, prefix string) bool {
+	if !strings.HasPrefix(address, prefix) {
+		return false
+	}
+	return len(address) == len(prefix) || strings.HasSuffix(prefix, "/") || address[len(prefix)] == '/'
+}
+
+// NamespaceRoutes maps namespace prefixes to values.  An address is routed
+// to the value of the longest prefix it is under.
+type NamespaceRoutes[T any] struct {
+	routes []namespaceRoute[T]
+}
+
+type namespaceRoute[T any] struct {
+	prefix string
+	value  T
+}
+
+// Add routes addresses under prefix to value.  Of equally long prefixes the
+// one added first wins.
+func (r *NamespaceRoutes[T]) Add(prefix string, value T) {
+	i := sort.Search(len(r.routes), func(i int) bool {
+		return len(r.routes[i].prefix) < len(prefix)
+	})
+	r.routes = append(r.routes, namespaceRoute[T]{})
+	copy(r.routes[i+1:], r.routes[i:])
+	r.routes[i] = namespaceRoute[T]{prefix: prefix, value: value}
+}
+
+// Route returns the value of the longest prefix address is under, or false
+// if it is under none.
+func (r *NamespaceRoutes[T]) Route(address string) (T, bool) {
+	for _, route := range r.routes {
+		if HasNamespacePrefix(address, route.prefix) {
+			return route.value, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
 func formatPathWithNamespace(namespacePath, keyPath string) string {
 	namespacePath = strings.Trim(namespacePath, "/")
 	if len(namespacePath) == 0 {
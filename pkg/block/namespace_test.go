@@ -234,3 +234,56 @@ func TestFormatQualifiedKey(t *testing.T) {
 		})
 	}
 }
+
+func TestHasNamespacePrefix(t *testing.T) {
+	cases := []struct {
+		Address  string
+		Prefix   string
+		Expected bool
+	}{
+		{Address: "s3://bucket/path/to/file", Prefix: "s3://bucket", Expected: true},
+		{Address: "s3://bucket/path/to/file", Prefix: "s3://bucket/", Expected: true},
+		{Address: "s3://bucket/path/to/file", Prefix: "s3://bucket/path", Expected: true},
+		{Address: "s3://bucket", Prefix: "s3://bucket", Expected: true},
+		{Address: "s3://bucket2/path/to/file", Prefix: "s3://bucket", Expected: false},
+		{Address: "s3://bucket/path2/file", Prefix: "s3://bucket/path", Expected: false},
+		{Address: "gs://bucket/path/to/file", Prefix: "s3://bucket", Expected: false},
+	}
+	for _, cas := range cases {
+		t.Run(cas.Address+"_"+cas.Prefix, func(t *testing.T) {
+			if got := block.HasNamespacePrefix(cas.Address, cas.Prefix); got != cas.Expected {
+				t.Fatalf("HasNamespacePrefix(%s, %s) got %t, expected %t", cas.Address, cas.Prefix, got, cas.Expected)
+			}
+		})
+	}
+}
+
+func TestNamespaceRoutes(t *testing.T) {
+	var routes block.NamespaceRoutes[string]
+	routes.Add("s3://bucket", "bucket")
+	routes.Add("s3://bucket/cold/archive", "archive")
+	routes.Add("s3://bucket/cold", "cold")
+	routes.Add("s3://other", "other")
+	routes.Add("s3://bucket", "shadowed")
+
+	cases := []struct {
+		Address  string
+		Expected string
+		Found    bool
+	}{
+		{Address: "s3://bucket/path", Expected: "bucket", Found: true},
+		{Address: "s3://bucket/cold/path", Expected: "cold", Found: true},
+		{Address: "s3://bucket/cold/archive/path", Expected: "archive", Found: true},
+		{Address: "s3://bucket/colder/path", Expected: "bucket", Found: true},
+		{Address: "s3://other", Expected: "other", Found: true},
+		{Address: "s3://bucket2/path", Found: false},
+	}
+	for _, cas := range cases {
+		t.Run(cas.Address, func(t *testing.T) {
+			got, found := routes.Route(cas.Address)
+			if got != cas.Expected || found != cas.Found {
+				t.Fatalf("Route(%s) got (%s, %t), expected (%s, %t)", cas.Address, got, found, cas.Expected, cas.Found)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/treeverse/lakefs/pkg/block"
 )
 
 // AdapterConfig configures a block adapter.
@@ -13,6 +14,33 @@ type AdapterConfig interface {
 	BlockstoreS3Params() (S3, error)
 	BlockstoreGSParams() (GS, error)
 	BlockstoreAzureParams() (Azure, error)
+	// BlockstoreAdapters returns additional adapters, each used for the
+	// storage namespaces under its prefixes.
+	BlockstoreAdapters() ([]NamedAdapter, error)
+	BlockstoreEncryptionParams() (Encryption, error)
+	BlockstoreCompressionParams() Compression
+}
+
+// NamedAdapter configures an additional adapter, used for storage
+// namespaces under any of NamespacePrefixes.
+type NamedAdapter struct {
+	Name              string
+	NamespacePrefixes []string
+	Config            AdapterConfig
+}
+
+// Encryption configures client-side encryption of object data.  The data
+// key of each object is wrapped by Key.
+type Encryption struct {
+	Enabled         bool
+	Key             []byte
+	ReadUnencrypted bool
+}
+
+// Compression configures compression of object data, by the codec that
+// each repository sets.
+type Compression struct {
+	Enabled bool
 }
 
 type Mem struct{}
@@ -48,7 +76,33 @@ type S3 struct {
 	PreSignedExpiry               time.Duration
 	DisablePreSigned              bool
 	DisablePreSignedUI            bool
-	WebIdentity                   *S3WebIdentity
+	// Compat names the S3-compatible store of the endpoint, e.g. "minio".
+	Compat string
+	// Endpoints are additional endpoints, each used for the storage
+	// namespaces under its NamespacePrefix.
+	Endpoints   []S3Endpoint
+	WebIdentity *S3WebIdentity
+}
+
+// S3Endpoint configures an additional S3 endpoint.
+type S3Endpoint struct {
+	NamespacePrefix string
+	AwsConfig       *aws.Config
+	Compat          string
+}
+
+// AwsConfigFor returns the AWS configuration of the endpoint used for
+// address: that of the endpoint with the longest NamespacePrefix of address,
+// or the default one.
+func (p S3) AwsConfigFor(address string) *aws.Config {
+	var routes block.NamespaceRoutes[*aws.Config]
+	for _, e := range p.Endpoints {
+		routes.Add(e.NamespacePrefix, e.AwsConfig)
+	}
+	if cfg, ok := routes.Route(address); ok {
+		return cfg
+	}
+	return p.AwsConfig
 }
 
 type GS struct {
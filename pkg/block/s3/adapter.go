@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -49,6 +50,7 @@ type Adapter struct {
 	preSignedRefreshWindow       time.Duration
 	disablePreSigned             bool
 	disablePreSignedUI           bool
+	profile                      Profile
 }
 
 func WithStreamingChunkSize(sz int) func(a *Adapter) {
@@ -115,6 +117,14 @@ func WithServerSideEncryptionKmsKeyID(s string) func(a *Adapter) {
 	}
 }
 
+// WithCompat sets the behaviours of the adapter to those of the object
+// store c.
+func WithCompat(c Compat) func(a *Adapter) {
+	return func(a *Adapter) {
+		a.profile = c.Profile()
+	}
+}
+
 type AdapterOption func(a *Adapter)
 
 func NewAdapter(awsSession *session.Session, opts ...AdapterOption) *Adapter {
@@ -124,10 +134,14 @@ func NewAdapter(awsSession *session.Session, opts ...AdapterOption) *Adapter {
 		streamingChunkSize:    DefaultStreamingChunkSize,
 		streamingChunkTimeout: DefaultStreamingChunkTimeout,
 		preSignedExpiry:       block.DefaultPreSignExpiryDuration,
+		profile:               CompatAWS.Profile(),
 	}
 	for _, opt := range opts {
 		opt(a)
 	}
+	if !a.profile.DiscoverBucketRegion {
+		a.clients.DiscoverBucketRegion(false)
+	}
 	return a
 }
 
@@ -141,7 +155,8 @@ func (a *Adapter) Put(ctx context.Context, obj block.ObjectPointer, sizeBytes in
 
 	// for unknown size, we assume we like to stream content, will use s3manager to perform the request.
 	// we assume the caller may not have 1:1 request to s3 put object in this case as it may perform multipart upload
-	if sizeBytes == -1 {
+	// stores that cannot accept chunked uploads use s3manager as well.
+	if sizeBytes == -1 || !a.profile.StreamingUpload {
 		return a.managerUpload(ctx, obj, reader, opts)
 	}
 
@@ -190,8 +205,13 @@ func (a *Adapter) UploadPart(ctx context.Context, obj block.ObjectPointer, sizeB
 		UploadId:   aws.String(uploadID),
 	}
 	client := a.clients.Get(ctx, qualifiedKey.GetStorageNamespace())
-	sdkRequest, _ := client.UploadPartRequest(&uploadPartObject)
-	headers, err := a.streamToS3(ctx, sdkRequest, sizeBytes, reader)
+	var headers http.Header
+	if a.profile.StreamingUpload {
+		sdkRequest, _ := client.UploadPartRequest(&uploadPartObject)
+		headers, err = a.streamToS3(ctx, sdkRequest, sizeBytes, reader)
+	} else {
+		headers, err = uploadPartBuffered(ctx, client, &uploadPartObject, reader)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -205,6 +225,33 @@ func (a *Adapter) UploadPart(ctx context.Context, obj block.ObjectPointer, sizeB
 	}, nil
 }
 
+// uploadPartBuffered spills the part to a temporary file, so it can be
+// signed as a whole by stores that do not accept chunked uploads without
+// holding parts of up to 5 GB in memory.
+func uploadPartBuffered(ctx context.Context, client S3APIWithExpirer, input *s3.UploadPartInput, reader io.Reader) (http.Header, error) {
+	f, err := os.CreateTemp("", "lakefs-s3-part-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	if _, err := io.Copy(f, reader); err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	input.Body = f
+	req, _ := client.UploadPartRequest(input)
+	req.SetContext(ctx)
+	if err := req.Send(); err != nil {
+		return nil, err
+	}
+	return req.HTTPResponse.Header, nil
+}
+
 func (a *Adapter) streamToS3(ctx context.Context, sdkRequest *request.Request, sizeBytes int64, reader io.Reader) (http.Header, error) {
 	sigTime := time.Now()
 	log := a.log(ctx).WithField("operation", "PutObject")
@@ -512,7 +559,10 @@ func (a *Adapter) GetProperties(ctx context.Context, obj block.ObjectPointer) (b
 	if err != nil {
 		return block.Properties{}, err
 	}
-	return block.Properties{StorageClass: s3Props.StorageClass}, nil
+	return block.Properties{
+		StorageClass: s3Props.StorageClass,
+		Size:         aws.Int64Value(s3Props.ContentLength),
+	}, nil
 }
 
 func (a *Adapter) Remove(ctx context.Context, obj block.ObjectPointer) error {
@@ -532,6 +582,9 @@ func (a *Adapter) Remove(ctx context.Context, obj block.ObjectPointer) error {
 		a.log(ctx).WithError(err).Error("failed to delete S3 object")
 		return err
 	}
+	if !a.profile.WaitAfterRemove {
+		return nil
+	}
 	err = svc.WaitUntilObjectNotExistsWithContext(ctx, &s3.HeadObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
@@ -593,11 +646,27 @@ func (a *Adapter) UploadCopyPart(ctx context.Context, sourceObj, destinationObj
 func (a *Adapter) UploadCopyPartRange(ctx context.Context, sourceObj, destinationObj block.ObjectPointer, uploadID string, partNumber int, startPosition, endPosition int64) (*block.UploadPartResponse, error) {
 	var err error
 	defer reportMetrics("UploadCopyPartRange", time.Now(), nil, &err)
+	if !a.profile.CopyPartRange {
+		return a.uploadRange(ctx, sourceObj, destinationObj, uploadID, partNumber, startPosition, endPosition)
+	}
 	return a.copyPart(ctx,
 		sourceObj, destinationObj, uploadID, partNumber,
 		aws.String(fmt.Sprintf("bytes=%d-%d", startPosition, endPosition)))
 }
 
+// uploadRange copies a range of sourceObj to a part by reading and uploading
+// it, for stores that do not support ranged part copies.
+func (a *Adapter) uploadRange(ctx context.Context, sourceObj, destinationObj block.ObjectPointer, uploadID string, partNumber int, startPosition, endPosition int64) (*block.UploadPartResponse, error) {
+	reader, err := a.GetRange(ctx, sourceObj, startPosition, endPosition)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	return a.UploadPart(ctx, destinationObj, endPosition-startPosition+1, reader, uploadID, partNumber)
+}
+
 func (a *Adapter) Copy(ctx context.Context, sourceObj, destinationObj block.ObjectPointer) error {
 	var err error
 	defer reportMetrics("Copy", time.Now(), nil, &err)
@@ -698,6 +767,35 @@ func (a *Adapter) AbortMultiPartUpload(ctx context.Context, obj block.ObjectPoin
 	return nil
 }
 
+func (a *Adapter) ListParts(ctx context.Context, obj block.ObjectPointer, uploadID string) ([]block.MultipartPart, error) {
+	var err error
+	defer reportMetrics("ListParts", time.Now(), nil, &err)
+	bucket, key, qualifiedKey, err := a.extractParamsFromObj(obj)
+	if err != nil {
+		return nil, err
+	}
+	input := &s3.ListPartsInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	}
+	var parts []block.MultipartPart
+	client := a.clients.Get(ctx, qualifiedKey.GetStorageNamespace())
+	err = client.ListPartsPagesWithContext(ctx, input, func(page *s3.ListPartsOutput, _ bool) bool {
+		for _, part := range page.Parts {
+			parts = append(parts, block.MultipartPart{
+				ETag:       strings.Trim(aws.StringValue(part.ETag), `"`),
+				PartNumber: int(aws.Int64Value(part.PartNumber)),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parts, nil
+}
+
 func convertFromBlockMultipartUploadCompletion(multipartList *block.MultipartUploadCompletion) *s3.CompletedMultipartUpload {
 	parts := make([]*s3.CompletedPart, len(multipartList.Part))
 	for i, p := range multipartList.Part {
@@ -743,7 +841,11 @@ func (a *Adapter) CompleteMultiPartUpload(ctx context.Context, obj block.ObjectP
 		return nil, err
 	}
 
-	etag := strings.Trim(aws.StringValue(resp.ETag), `"`)
+	etag := aws.StringValue(resp.ETag)
+	if a.profile.MultipartETagFromHead || etag == "" {
+		etag = aws.StringValue(headResp.ETag)
+	}
+	etag = strings.Trim(etag, `"`)
 	contentLength := aws.Int64Value(headResp.ContentLength)
 	return &block.CompleteMultiPartUploadResponse{
 		ETag:             etag,
@@ -0,0 +1,94 @@
+package s3
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Compat names an object store implementing the S3 API, whose behaviour
+// may differ from that of AWS S3.
+type Compat string
+
+const (
+	CompatAWS   Compat = "aws"
+	CompatMinIO Compat = "minio"
+	CompatCeph  Compat = "ceph"
+	CompatR2    Compat = "r2"
+)
+
+var ErrUnknownCompat = fmt.Errorf("%w: unknown compat", ErrS3)
+
+// Profile holds the behaviours of the adapter that depend on the object
+// store it talks to.
+type Profile struct {
+	// DiscoverBucketRegion is true if the store reports bucket regions,
+	// so a client per bucket region can be used.
+	DiscoverBucketRegion bool
+	// ForcePathStyle is true if buckets must be addressed in the URL path
+	// rather than in the host name.
+	ForcePathStyle bool
+	// StreamingUpload is true if the store accepts uploads signed in
+	// chunks (aws-chunked).  Otherwise parts are buffered and signed as a
+	// whole after spilling them to a temporary file, and objects are uploaded using multipart uploads.
+	StreamingUpload bool
+	// CopyPartRange is true if the store supports a source range on
+	// UploadPartCopy.  Otherwise ranged part copies read the range and
+	// upload it.
+	CopyPartRange bool
+	// MultipartETagFromHead is true if the ETag returned by
+	// CompleteMultipartUpload may differ from the ETag of the object
+	// later returned by the store, so the ETag is read from the object.
+	MultipartETagFromHead bool
+	// WaitAfterRemove is true if removed objects may still be visible for
+	// a while, so Remove waits until they are gone.
+	WaitAfterRemove bool
+	// ServerSideEncryption is true if the store accepts S3 server-side
+	// encryption parameters.
+	ServerSideEncryption bool
+}
+
+var profiles = map[Compat]Profile{
+	CompatAWS: {
+		DiscoverBucketRegion: true,
+		StreamingUpload:      true,
+		CopyPartRange:        true,
+		WaitAfterRemove:      true,
+		ServerSideEncryption: true,
+	},
+	CompatMinIO: {
+		ForcePathStyle:        true,
+		StreamingUpload:       true,
+		CopyPartRange:         true,
+		MultipartETagFromHead: true,
+		ServerSideEncryption:  true,
+	},
+	CompatCeph: {
+		ForcePathStyle:        true,
+		StreamingUpload:       true,
+		MultipartETagFromHead: true,
+		ServerSideEncryption:  true,
+	},
+	CompatR2: {
+		CopyPartRange: true,
+	},
+}
+
+// ParseCompat returns the Compat named by s.  An empty s is AWS S3.
+func ParseCompat(s string) (Compat, error) {
+	if s == "" {
+		return CompatAWS, nil
+	}
+	c := Compat(strings.ToLower(s))
+	if _, ok := profiles[c]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownCompat, s)
+	}
+	return c, nil
+}
+
+// Profile returns the behaviours of c.  Unknown values behave as AWS S3.
+func (c Compat) Profile() Profile {
+	if p, ok := profiles[c]; ok {
+		return p
+	}
+	return profiles[CompatAWS]
+}
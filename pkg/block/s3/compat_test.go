@@ -0,0 +1,52 @@
+package s3_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/treeverse/lakefs/pkg/block/s3"
+)
+
+func TestParseCompat(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    s3.Compat
+		expectedErr error
+	}{
+		{value: "", expected: s3.CompatAWS},
+		{value: "aws", expected: s3.CompatAWS},
+		{value: "minio", expected: s3.CompatMinIO},
+		{value: "MinIO", expected: s3.CompatMinIO},
+		{value: "ceph", expected: s3.CompatCeph},
+		{value: "r2", expected: s3.CompatR2},
+		{value: "gcs", expectedErr: s3.ErrUnknownCompat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			c, err := s3.ParseCompat(tt.value)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("ParseCompat(%s) error %v, expected %v", tt.value, err, tt.expectedErr)
+			}
+			if c != tt.expected {
+				t.Errorf("ParseCompat(%s) = %s, expected %s", tt.value, c, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompatProfile(t *testing.T) {
+	if !s3.CompatAWS.Profile().DiscoverBucketRegion {
+		t.Error("AWS should discover bucket regions")
+	}
+	for _, c := range []s3.Compat{s3.CompatMinIO, s3.CompatCeph, s3.CompatR2} {
+		if c.Profile().DiscoverBucketRegion {
+			t.Errorf("%s should not discover bucket regions", c)
+		}
+	}
+	if s3.CompatCeph.Profile().CopyPartRange {
+		t.Error("Ceph should not copy part ranges")
+	}
+	if s3.Compat("unknown").Profile() != s3.CompatAWS.Profile() {
+		t.Error("unknown compat should behave as AWS")
+	}
+}
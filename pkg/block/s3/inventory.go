@@ -1,11 +1,16 @@
 package s3
 
 import (
+	"bytes"
 	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
+	"path"
 	"sort"
 	"strings"
 
@@ -16,7 +21,10 @@ import (
 	"github.com/treeverse/lakefs/pkg/logging"
 )
 
-var ErrInventoryFilesRangesOverlap = errors.New("got s3 inventory with files covering overlapping ranges")
+var (
+	ErrInventoryFilesRangesOverlap = errors.New("got s3 inventory with files covering overlapping ranges")
+	ErrInventoryChecksumMismatch   = errors.New("s3 inventory manifest checksum mismatch")
+)
 
 type Manifest struct {
 	URL                string          `json:"-"`
@@ -24,6 +32,7 @@ type Manifest struct {
 	SourceBucket       string          `json:"sourceBucket"`
 	Files              []InventoryFile `json:"files"` // inventory list files, each contains a list of objects
 	Format             string          `json:"fileFormat"`
+	FileSchema         string          `json:"fileSchema"` // the columns of CSV inventory files
 	CreationTimestamp  string          `json:"creationTimestamp"`
 	inventoryBucket    string
 }
@@ -40,7 +49,7 @@ func (a *Adapter) GenerateInventory(ctx context.Context, logger logging.Logger,
 		return nil, err
 	}
 	svc := a.clients.Get(ctx, m.inventoryBucket)
-	return GenerateInventory(ctx, logger, m, s3inventory.NewReader(ctx, svc, logger), shouldSort, prefixes)
+	return GenerateInventory(ctx, logger, m, s3inventory.NewReader(ctx, svc, logger, m.FileSchema), shouldSort, prefixes)
 }
 
 func GenerateInventory(ctx context.Context, logger logging.Logger, m *Manifest, inventoryReader s3inventory.IReader, shouldSort bool, prefixes []string) (block.Inventory, error) {
@@ -85,16 +94,37 @@ func (a *Adapter) loadManifest(ctx context.Context, manifestURL string) (*Manife
 	if err != nil {
 		return nil, err
 	}
-	output, err := a.clients.Get(ctx, u.Host).GetObject(&s3.GetObjectInput{Bucket: &u.Host, Key: &u.Path})
+	svc := a.clients.Get(ctx, u.Host)
+	output, err := svc.GetObjectWithContext(ctx, &s3.GetObjectInput{Bucket: &u.Host, Key: &u.Path})
 	if err != nil {
 		return nil, fmt.Errorf("%w: failed to read manifest.json from %s", err, manifestURL)
 	}
+	data, err := io.ReadAll(output.Body)
+	_ = output.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read manifest.json from %s", err, manifestURL)
+	}
+	// S3 Inventory writes the MD5 checksum of the manifest next to it
+	checksumKey := strings.TrimSuffix(u.Path, path.Ext(u.Path)) + ".checksum"
+	output, err = svc.GetObjectWithContext(ctx, &s3.GetObjectInput{Bucket: &u.Host, Key: &checksumKey})
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read manifest checksum of %s", err, manifestURL)
+	}
+	checksum, err := io.ReadAll(output.Body)
+	_ = output.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read manifest checksum of %s", err, manifestURL)
+	}
+	sum := md5.Sum(data) //nolint:gosec
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), string(bytes.TrimSpace(checksum))) {
+		return nil, fmt.Errorf("%w: %s", ErrInventoryChecksumMismatch, manifestURL)
+	}
 	var m Manifest
-	err = json.NewDecoder(output.Body).Decode(&m)
+	err = json.Unmarshal(data, &m)
 	if err != nil {
 		return nil, err
 	}
-	if m.Format != s3inventory.OrcFormatName && m.Format != s3inventory.ParquetFormatName {
+	if m.Format != s3inventory.OrcFormatName && m.Format != s3inventory.ParquetFormatName && m.Format != s3inventory.CSVFormatName {
 		return nil, fmt.Errorf("%w. got format: %s", s3inventory.ErrUnsupportedInventoryFormat, m.Format)
 	}
 	m.URL = manifestURL
@@ -123,6 +123,10 @@ func (a *Adapter) AbortMultiPartUpload(context.Context, block.ObjectPointer, str
 	return nil
 }
 
+func (a *Adapter) ListParts(context.Context, block.ObjectPointer, string) ([]block.MultipartPart, error) {
+	return nil, nil
+}
+
 func (a *Adapter) CompleteMultiPartUpload(context.Context, block.ObjectPointer, string, *block.MultipartUploadCompletion) (*block.CompleteMultiPartUploadResponse, error) {
 	const dataSize = 1024
 	data := make([]byte, dataSize)
@@ -0,0 +1,699 @@
+package catalog
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/compress"
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+const (
+	// ArchiveManifestName is the name of the manifest entry of a repository archive
+	ArchiveManifestName = "lakefs_archive.json"
+	// ArchiveEndName is the name of the empty entry that ends a complete repository archive
+	ArchiveEndName = "lakefs_archive.end"
+	// ArchiveVersion is the version of the repository archive format written by ExportRepositoryArchive
+	ArchiveVersion = 1
+
+	archiveMetadataPrefix = "metadata/"
+	archiveDataPrefix     = "data/"
+	// archiveAddressPrefix prefixes the relative addresses given to objects
+	// that were stored by a full address when they were exported
+	archiveAddressPrefix = "archive/"
+	archiveListBatchSize = 1000
+)
+
+// errArchiveRewrite stops walking the changes of a commit once an entry that must be rewritten is found
+var errArchiveRewrite = errors.New("rewrite")
+
+// ArchiveManifest describes the content of a repository archive.  Each
+// metarange ID is that of a dump in Graveler format, as created by
+// DumpCommits, DumpBranches and DumpTags.
+type ArchiveManifest struct {
+	Version             int       `json:"version"`
+	Repository          string    `json:"repository"`
+	DefaultBranch       string    `json:"default_branch"`
+	StorageNamespace    string    `json:"storage_namespace"`
+	CommitsMetaRangeID  string    `json:"commits_meta_range_id"`
+	BranchesMetaRangeID string    `json:"branches_meta_range_id"`
+	TagsMetaRangeID     string    `json:"tags_meta_range_id"`
+	IncludesObjects     bool      `json:"includes_objects"`
+	CreationDate        time.Time `json:"creation_date"`
+}
+
+// archiveWriter writes the files of a single repository archive
+type archiveWriter struct {
+	catalog    *Catalog
+	repository *graveler.RepositoryRecord
+	tw         *tar.Writer
+	// written holds the names of the archive files already written
+	written map[string]struct{}
+	// metaRanges maps the metarange of each exported commit to the metarange it is exported as
+	metaRanges map[graveler.MetaRangeID]graveler.MetaRangeID
+}
+
+// ExportRepositoryArchive writes to w a tar archive of repositoryID that
+// holds its commits, branches and tags together with all the metaranges
+// and ranges they reference.  If includeObjects is set, the archive also
+// holds every object referenced by a commit, and entries that address
+// objects by their full address are rewritten to address the copy held by
+// the archive, so that the archive is self-contained.  Uncommitted changes
+// are not exported.
+//
+// The manifest is the first file of the archive and ArchiveEndName is its
+// last file, so that readers can validate an archive before storing its
+// files and detect a truncated archive.
+func (c *Catalog) ExportRepositoryArchive(ctx context.Context, repositoryID string, includeObjects bool, w io.Writer) error {
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return err
+	}
+	aw := &archiveWriter{
+		catalog:    c,
+		repository: repository,
+		tw:         tar.NewWriter(w),
+		written:    make(map[string]struct{}),
+		metaRanges: make(map[graveler.MetaRangeID]graveler.MetaRangeID),
+	}
+
+	branches, err := aw.listBranches(ctx)
+	if err != nil {
+		return err
+	}
+	tags, err := aw.listTags(ctx)
+	if err != nil {
+		return err
+	}
+	heads := make([]graveler.CommitID, 0, len(branches)+len(tags))
+	for _, b := range branches {
+		heads = append(heads, b.CommitID)
+	}
+	for _, t := range tags {
+		heads = append(heads, t.CommitID)
+	}
+	commits, err := aw.reachableCommits(ctx, heads)
+	if err != nil {
+		return err
+	}
+	commitsByID := make(map[graveler.CommitID]*graveler.Commit, len(commits))
+	for _, commit := range commits {
+		commitsByID[commit.CommitID] = commit.Commit
+	}
+
+	// commit IDs change when a commit or one of its ancestors is exported
+	// with a rewritten metarange
+	commitIDs := make(map[graveler.CommitID]graveler.CommitID, len(commits))
+	exportedCommits := make([]*graveler.CommitRecord, 0, len(commits))
+	for _, commit := range commits {
+		exported := *commit.Commit
+		if includeObjects && exported.MetaRangeID != "" {
+			exported.MetaRangeID, err = aw.exportMetaRange(ctx, commit, baseCommit(commit, commitsByID))
+			if err != nil {
+				return fmt.Errorf("export metarange of commit %s: %w", commit.CommitID, err)
+			}
+		}
+		exported.Parents = make(graveler.CommitParents, len(commit.Parents))
+		for i, parent := range commit.Parents {
+			exported.Parents[i] = commitIDs[parent]
+		}
+		commitID := commit.CommitID
+		if exported.MetaRangeID != commit.MetaRangeID || !parentsEqual(exported.Parents, commit.Parents) {
+			commitID = graveler.CommitID(c.addressProvider.ContentAddress(exported))
+		}
+		commitIDs[commit.CommitID] = commitID
+		exportedCommits = append(exportedCommits, &graveler.CommitRecord{CommitID: commitID, Commit: &exported})
+	}
+	for _, b := range branches {
+		b.Branch = &graveler.Branch{CommitID: commitIDs[b.CommitID]}
+	}
+	for _, t := range tags {
+		t.CommitID = commitIDs[t.CommitID]
+	}
+
+	commitsMetaRangeID, err := c.Store.DumpCommitRecords(ctx, repository, exportedCommits)
+	if err != nil {
+		return fmt.Errorf("dump commits: %w", err)
+	}
+	branchesMetaRangeID, err := c.Store.DumpBranchRecords(ctx, repository, branches)
+	if err != nil {
+		return fmt.Errorf("dump branches: %w", err)
+	}
+	tagsMetaRangeID, err := c.Store.DumpTagRecords(ctx, repository, tags)
+	if err != nil {
+		return fmt.Errorf("dump tags: %w", err)
+	}
+
+	manifest, err := json.MarshalIndent(&ArchiveManifest{
+		Version:             ArchiveVersion,
+		Repository:          repository.RepositoryID.String(),
+		DefaultBranch:       repository.DefaultBranchID.String(),
+		StorageNamespace:    repository.StorageNamespace.String(),
+		CommitsMetaRangeID:  commitsMetaRangeID.String(),
+		BranchesMetaRangeID: branchesMetaRangeID.String(),
+		TagsMetaRangeID:     tagsMetaRangeID.String(),
+		IncludesObjects:     includeObjects,
+		CreationDate:        time.Now().UTC(),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := aw.writeFile(ArchiveManifestName, int64(len(manifest)), bytes.NewReader(manifest)); err != nil {
+		return err
+	}
+
+	metaRangeIDs := []graveler.MetaRangeID{*commitsMetaRangeID, *branchesMetaRangeID, *tagsMetaRangeID}
+	for _, commit := range exportedCommits {
+		if commit.MetaRangeID != "" {
+			metaRangeIDs = append(metaRangeIDs, commit.MetaRangeID)
+		}
+	}
+	for _, metaRangeID := range metaRangeIDs {
+		if err := aw.writeMetaRange(ctx, metaRangeID); err != nil {
+			return fmt.Errorf("metarange %s: %w", metaRangeID, err)
+		}
+	}
+
+	if includeObjects {
+		// every object of a commit is either an object of its base commit,
+		// or an object changed from it
+		objectsWritten := make(map[graveler.MetaRangeID]struct{})
+		for _, commit := range commits {
+			if commit.MetaRangeID == "" {
+				continue
+			}
+			if _, ok := objectsWritten[commit.MetaRangeID]; ok {
+				continue
+			}
+			err := aw.walkChanges(ctx, commit, baseCommit(commit, commitsByID), func(_ graveler.Key, entry *Entry) error {
+				if entry == nil {
+					return nil
+				}
+				return aw.writeObject(ctx, entry)
+			})
+			if err != nil {
+				return fmt.Errorf("export objects of commit %s: %w", commit.CommitID, err)
+			}
+			objectsWritten[commit.MetaRangeID] = struct{}{}
+		}
+	}
+
+	if err := aw.writeFile(ArchiveEndName, 0, bytes.NewReader(nil)); err != nil {
+		return err
+	}
+	return aw.tw.Close()
+}
+
+func parentsEqual(a, b graveler.CommitParents) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// baseCommit returns the first parent of commit that has a metarange, or
+// nil if commit has no such parent.  The contents of commit are exported
+// as changes from its base commit.
+func baseCommit(commit *graveler.CommitRecord, commits map[graveler.CommitID]*graveler.Commit) *graveler.CommitRecord {
+	if len(commit.Parents) == 0 {
+		return nil
+	}
+	parentID := commit.Parents[0]
+	parent, ok := commits[parentID]
+	if !ok || parent.MetaRangeID == "" {
+		return nil
+	}
+	return &graveler.CommitRecord{CommitID: parentID, Commit: parent}
+}
+
+func (aw *archiveWriter) listBranches(ctx context.Context) ([]*graveler.BranchRecord, error) {
+	it, err := aw.catalog.Store.ListBranches(ctx, aw.repository)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var branches []*graveler.BranchRecord
+	for it.Next() {
+		b := it.Value()
+		branches = append(branches, &graveler.BranchRecord{BranchID: b.BranchID, Branch: &graveler.Branch{CommitID: b.CommitID}})
+	}
+	return branches, it.Err()
+}
+
+func (aw *archiveWriter) listTags(ctx context.Context) ([]*graveler.TagRecord, error) {
+	it, err := aw.catalog.Store.ListTags(ctx, aw.repository)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var tags []*graveler.TagRecord
+	for it.Next() {
+		t := it.Value()
+		tags = append(tags, &graveler.TagRecord{TagID: t.TagID, CommitID: t.CommitID})
+	}
+	return tags, it.Err()
+}
+
+// reachableCommits returns all the commits reachable from heads, parents
+// before their children.
+func (aw *archiveWriter) reachableCommits(ctx context.Context, heads []graveler.CommitID) ([]*graveler.CommitRecord, error) {
+	var commits []*graveler.CommitRecord
+	visited := make(map[graveler.CommitID]struct{})
+	queue := heads
+	for len(queue) > 0 {
+		commitID := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[commitID]; ok {
+			continue
+		}
+		visited[commitID] = struct{}{}
+		commit, err := aw.catalog.Store.GetCommit(ctx, aw.repository, commitID)
+		if err != nil {
+			return nil, fmt.Errorf("commit %s: %w", commitID, err)
+		}
+		commits = append(commits, &graveler.CommitRecord{CommitID: commitID, Commit: commit})
+		queue = append(queue, commit.Parents...)
+	}
+	sort.SliceStable(commits, func(i, j int) bool {
+		if commits[i].Generation != commits[j].Generation {
+			return commits[i].Generation < commits[j].Generation
+		}
+		return commits[i].CommitID < commits[j].CommitID
+	})
+	return commits, nil
+}
+
+// walkChanges calls fn with every entry of commit that differs from base,
+// and with a nil entry for every key removed from base.  Without a base,
+// fn is called with every entry of commit.
+func (aw *archiveWriter) walkChanges(ctx context.Context, commit, base *graveler.CommitRecord, fn func(key graveler.Key, entry *Entry) error) error {
+	if base == nil {
+		it, err := aw.catalog.Store.List(ctx, aw.repository, graveler.Ref(commit.CommitID), archiveListBatchSize)
+		if err != nil {
+			return err
+		}
+		defer it.Close()
+		for it.Next() {
+			record := it.Value()
+			entry, err := ValueToEntry(record.Value)
+			if err != nil {
+				return err
+			}
+			if err := fn(record.Key, entry); err != nil {
+				return err
+			}
+		}
+		return it.Err()
+	}
+	it, err := aw.catalog.Store.Diff(ctx, aw.repository, graveler.Ref(base.CommitID), graveler.Ref(commit.CommitID))
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for it.Next() {
+		diff := it.Value()
+		var entry *Entry
+		if diff.Type != graveler.DiffTypeRemoved {
+			entry, err = ValueToEntry(diff.Value)
+			if err != nil {
+				return err
+			}
+		}
+		if err := fn(diff.Key, entry); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// exportMetaRange returns the ID of the metarange to export in place of
+// the metarange of commit: the same metarange, or a new one if any of its
+// entries has to be rewritten to a relative address.  base must already
+// have been exported.  Only the changes of commit from base are read: the
+// new metarange is the exported metarange of base with these changes
+// applied.
+func (aw *archiveWriter) exportMetaRange(ctx context.Context, commit, base *graveler.CommitRecord) (graveler.MetaRangeID, error) {
+	metaRangeID := commit.MetaRangeID
+	if exported, ok := aw.metaRanges[metaRangeID]; ok {
+		return exported, nil
+	}
+	rewrite := false
+	if base != nil && aw.metaRanges[base.MetaRangeID] != base.MetaRangeID {
+		// the changes of commit apply to the rewritten metarange of base
+		rewrite = true
+	} else {
+		err := aw.walkChanges(ctx, commit, base, func(_ graveler.Key, entry *Entry) error {
+			if entry != nil && entry.AddressType != Entry_RELATIVE {
+				rewrite = true
+				return errArchiveRewrite
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errArchiveRewrite) {
+			return "", err
+		}
+	}
+
+	exported := metaRangeID
+	if rewrite {
+		newMetaRangeID, err := aw.writeRelativeMetaRange(ctx, commit, base)
+		if err != nil {
+			return "", err
+		}
+		exported = *newMetaRangeID
+	}
+	aw.metaRanges[metaRangeID] = exported
+	return exported, nil
+}
+
+// writeRelativeMetaRange writes the metarange of commit with all entries
+// rewritten to relative addresses.
+func (aw *archiveWriter) writeRelativeMetaRange(ctx context.Context, commit, base *graveler.CommitRecord) (*graveler.MetaRangeID, error) {
+	if base == nil {
+		it, err := aw.catalog.Store.List(ctx, aw.repository, graveler.Ref(commit.CommitID), archiveListBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		return aw.catalog.Store.WriteMetaRangeByIterator(ctx, aw.repository, &relativeAddressIterator{ValueIterator: it})
+	}
+	it, err := aw.catalog.Store.Diff(ctx, aw.repository, graveler.Ref(base.CommitID), graveler.Ref(commit.CommitID))
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	return aw.catalog.Store.ApplyMetaRangeChanges(ctx, aw.repository, aw.metaRanges[base.MetaRangeID], &relativeChangesIterator{DiffIterator: it})
+}
+
+// archiveAddress returns the relative address under which the archive
+// holds the object of entry.
+func archiveAddress(entry *Entry) string {
+	if entry.AddressType == Entry_RELATIVE {
+		return entry.Address
+	}
+	h := sha256.Sum256([]byte(entry.Address))
+	return archiveAddressPrefix + hex.EncodeToString(h[:])
+}
+
+func (aw *archiveWriter) writeObject(ctx context.Context, entry *Entry) error {
+	name := archiveDataPrefix + archiveAddress(entry)
+	if _, ok := aw.written[name]; ok {
+		return nil
+	}
+	reader, err := aw.catalog.BlockAdapter.Get(compress.WithEntry(ctx, entry.Metadata), block.ObjectPointer{
+		StorageNamespace: aw.repository.StorageNamespace.String(),
+		Identifier:       entry.Address,
+		IdentifierType:   AddressType(entry.AddressType).ToIdentifierType(),
+	}, entry.Size)
+	if err != nil {
+		return fmt.Errorf("object %s: %w", entry.Address, err)
+	}
+	defer func() { _ = reader.Close() }()
+	return aw.writeFile(name, entry.Size, reader)
+}
+
+// writeMetaRange writes metaRangeID and its ranges to the archive
+func (aw *archiveWriter) writeMetaRange(ctx context.Context, metaRangeID graveler.MetaRangeID) error {
+	rangeIDs, err := aw.catalog.Store.ListRanges(ctx, aw.repository, metaRangeID)
+	if err != nil {
+		return err
+	}
+	for _, rangeID := range rangeIDs {
+		address, err := aw.catalog.Store.GetRange(ctx, aw.repository, rangeID)
+		if err != nil {
+			return err
+		}
+		if err := aw.writeMetadataFile(ctx, string(address)); err != nil {
+			return err
+		}
+	}
+	address, err := aw.catalog.Store.GetMetaRange(ctx, aw.repository, metaRangeID)
+	if err != nil {
+		return err
+	}
+	return aw.writeMetadataFile(ctx, string(address))
+}
+
+// writeMetadataFile writes the range or metarange file stored at the
+// relative address to the archive.  These files are small, so they are
+// read into memory to learn their size.
+func (aw *archiveWriter) writeMetadataFile(ctx context.Context, address string) error {
+	name := archiveMetadataPrefix + address
+	if _, ok := aw.written[name]; ok {
+		return nil
+	}
+	reader, err := aw.catalog.BlockAdapter.Get(ctx, block.ObjectPointer{
+		StorageNamespace: aw.repository.StorageNamespace.String(),
+		Identifier:       address,
+		IdentifierType:   block.IdentifierTypeRelative,
+	}, -1)
+	if err != nil {
+		return fmt.Errorf("%s: %w", address, err)
+	}
+	defer func() { _ = reader.Close() }()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("%s: %w", address, err)
+	}
+	return aw.writeFile(name, int64(len(data)), bytes.NewReader(data))
+}
+
+func (aw *archiveWriter) writeFile(name string, size int64, r io.Reader) error {
+	err := aw.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0o644, //nolint:gomnd
+		ModTime:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(aw.tw, r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if n != size {
+		return fmt.Errorf("%s: wrote %d bytes, expected %d: %w", name, n, size, io.ErrUnexpectedEOF)
+	}
+	aw.written[name] = struct{}{}
+	return nil
+}
+
+// relativeAddressIterator rewrites the entries of a ValueIterator to the
+// relative addresses under which the archive holds their objects.
+type relativeAddressIterator struct {
+	graveler.ValueIterator
+	value *graveler.ValueRecord
+	err   error
+}
+
+func (it *relativeAddressIterator) Next() bool {
+	if it.err != nil || !it.ValueIterator.Next() {
+		return false
+	}
+	record := it.ValueIterator.Value()
+	value, err := relativeValue(record.Value)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.value = &graveler.ValueRecord{Key: record.Key, Value: value}
+	return true
+}
+
+func (it *relativeAddressIterator) Value() *graveler.ValueRecord {
+	return it.value
+}
+
+func (it *relativeAddressIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.ValueIterator.Err()
+}
+
+// relativeChangesIterator iterates over the changes of a DiffIterator, as
+// values rewritten to relative addresses, and as tombstones for removed
+// keys.
+type relativeChangesIterator struct {
+	graveler.DiffIterator
+	value *graveler.ValueRecord
+	err   error
+}
+
+func (it *relativeChangesIterator) Next() bool {
+	if it.err != nil || !it.DiffIterator.Next() {
+		return false
+	}
+	diff := it.DiffIterator.Value()
+	it.value = &graveler.ValueRecord{Key: diff.Key}
+	if diff.Type != graveler.DiffTypeRemoved {
+		value, err := relativeValue(diff.Value)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.value.Value = value
+	}
+	return true
+}
+
+func (it *relativeChangesIterator) Value() *graveler.ValueRecord {
+	return it.value
+}
+
+func (it *relativeChangesIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.DiffIterator.Err()
+}
+
+// relativeValue returns value rewritten to the relative address under
+// which the archive holds its object.
+func relativeValue(value *graveler.Value) (*graveler.Value, error) {
+	entry, err := ValueToEntry(value)
+	if err != nil {
+		return nil, err
+	}
+	if entry.AddressType == Entry_RELATIVE {
+		return value, nil
+	}
+	entry.Address = archiveAddress(entry)
+	entry.AddressType = Entry_RELATIVE
+	return EntryToValue(entry)
+}
+
+// ImportRepositoryArchive loads into repositoryID the repository archive
+// read from r, as written by ExportRepositoryArchive.  The files of the
+// archive are stored in the storage namespace of repositoryID, which must
+// be a bare repository, before its commits, branches and tags are loaded.
+// The manifest is validated before any file is stored, and the refs are
+// loaded only from a complete archive.
+func (c *Catalog) ImportRepositoryArchive(ctx context.Context, repositoryID string, r io.Reader) (*ArchiveManifest, error) {
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkWritable(ctx, repository); err != nil {
+		return nil, err
+	}
+	it, err := c.Store.ListBranches(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	hasBranches := it.Next()
+	err = it.Err()
+	it.Close()
+	if err != nil {
+		return nil, err
+	}
+	if hasBranches {
+		return nil, ErrRepositoryNotBare
+	}
+
+	tr := tar.NewReader(r)
+	manifest, err := readArchiveManifest(tr)
+	if err != nil {
+		return nil, err
+	}
+	complete := false
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+		}
+		if complete {
+			return nil, fmt.Errorf("%w: file %s after %s", ErrInvalidArchive, hdr.Name, ArchiveEndName)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := hdr.Name
+		if name == ArchiveEndName {
+			complete = true
+			continue
+		}
+		var address string
+		switch {
+		case strings.HasPrefix(name, archiveMetadataPrefix):
+			address = strings.TrimPrefix(name, archiveMetadataPrefix)
+		case strings.HasPrefix(name, archiveDataPrefix):
+			address = strings.TrimPrefix(name, archiveDataPrefix)
+		default:
+			return nil, fmt.Errorf("%w: unexpected file %s", ErrInvalidArchive, name)
+		}
+		if address == "" || path.IsAbs(address) || path.Clean(address) != address || strings.HasPrefix(address, "../") {
+			return nil, fmt.Errorf("%w: bad file name %s", ErrInvalidArchive, name)
+		}
+		err = c.BlockAdapter.Put(ctx, block.ObjectPointer{
+			StorageNamespace: repository.StorageNamespace.String(),
+			Identifier:       address,
+			IdentifierType:   block.IdentifierTypeRelative,
+		}, hdr.Size, tr, block.PutOpts{})
+		if err != nil {
+			return nil, fmt.Errorf("store %s: %w", name, err)
+		}
+	}
+	if !complete {
+		return nil, fmt.Errorf("%w: truncated archive, missing %s", ErrInvalidArchive, ArchiveEndName)
+	}
+
+	if err := c.Store.LoadCommits(ctx, repository, graveler.MetaRangeID(manifest.CommitsMetaRangeID)); err != nil {
+		return nil, fmt.Errorf("load commits: %w", err)
+	}
+	if err := c.Store.LoadBranches(ctx, repository, graveler.MetaRangeID(manifest.BranchesMetaRangeID)); err != nil {
+		return nil, fmt.Errorf("load branches: %w", err)
+	}
+	if err := c.Store.LoadTags(ctx, repository, graveler.MetaRangeID(manifest.TagsMetaRangeID)); err != nil {
+		return nil, fmt.Errorf("load tags: %w", err)
+	}
+	return manifest, nil
+}
+
+// readArchiveManifest reads and validates the manifest, which must be the
+// first file of the archive read by tr.
+func readArchiveManifest(tr *tar.Reader) (*ArchiveManifest, error) {
+	hdr, err := tr.Next()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: empty archive", ErrInvalidArchive)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+	if hdr.Name != ArchiveManifestName {
+		return nil, fmt.Errorf("%w: first file is %s, expected %s", ErrInvalidArchive, hdr.Name, ArchiveManifestName)
+	}
+	manifest := &ArchiveManifest{}
+	if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("%w: manifest: %s", ErrInvalidArchive, err)
+	}
+	if manifest.Version != ArchiveVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidArchive, manifest.Version)
+	}
+	if manifest.CommitsMetaRangeID == "" || manifest.BranchesMetaRangeID == "" || manifest.TagsMetaRangeID == "" {
+		return nil, fmt.Errorf("%w: manifest is missing refs dumps", ErrInvalidArchive)
+	}
+	return manifest, nil
+}
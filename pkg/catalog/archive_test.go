@@ -0,0 +1,134 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+// exportTestArchive exports the source repository of a replication test
+// catalog, after committing to it a relative object on main, an object
+// stored by its full address on a branch, and tagging main.
+func exportTestArchive(t *testing.T, c *Catalog) []byte {
+	t.Helper()
+	ctx := context.Background()
+	commitObject(t, c, "main", "relative")
+	_, err := c.CreateBranch(ctx, replicationSourceRepository, "full", "main")
+	require.NoError(t, err)
+	const fullAddress = "mem://elsewhere/object"
+	contents := "contents of full"
+	err = c.BlockAdapter.Put(ctx, block.ObjectPointer{
+		Identifier:     fullAddress,
+		IdentifierType: block.IdentifierTypeFull,
+	}, int64(len(contents)), strings.NewReader(contents), block.PutOpts{})
+	require.NoError(t, err)
+	err = c.CreateEntry(ctx, replicationSourceRepository, "full", DBEntry{
+		Path:            "full",
+		PhysicalAddress: fullAddress,
+		AddressType:     AddressTypeFull,
+		Size:            int64(len(contents)),
+		Checksum:        "checksum-full",
+	})
+	require.NoError(t, err)
+	_, err = c.Commit(ctx, replicationSourceRepository, "full", "add full", "tester", nil, nil, nil)
+	require.NoError(t, err)
+	commitObject(t, c, "full", "after-full")
+	head := commitObject(t, c, "main", "last")
+	_, err = c.CreateTag(ctx, replicationSourceRepository, "v1", head)
+	require.NoError(t, err)
+
+	var archive bytes.Buffer
+	require.NoError(t, c.ExportRepositoryArchive(ctx, replicationSourceRepository, true, &archive))
+	return archive.Bytes()
+}
+
+func readTargetObject(t *testing.T, c *Catalog, ref, path string) string {
+	t.Helper()
+	ctx := context.Background()
+	entry, err := c.GetEntry(ctx, replicationTargetRepository, ref, path, GetEntryParams{})
+	require.NoError(t, err)
+	require.Equal(t, AddressTypeRelative, entry.AddressType, "address type of %s", path)
+	reader, err := c.BlockAdapter.Get(ctx, block.ObjectPointer{
+		StorageNamespace: "local://replica/target",
+		Identifier:       entry.PhysicalAddress,
+		IdentifierType:   block.IdentifierTypeRelative,
+	}, entry.Size)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestCatalog_RepositoryArchiveRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	c := newReplicationTestCatalog(t)
+	archive := exportTestArchive(t, c)
+
+	manifest, err := c.ImportRepositoryArchive(ctx, replicationTargetRepository, bytes.NewReader(archive))
+	require.NoError(t, err)
+	require.True(t, manifest.IncludesObjects)
+	require.Equal(t, replicationSourceRepository, manifest.Repository)
+
+	// commits whose metarange holds no full address keep their ID
+	sourceMain, err := c.GetBranchReference(ctx, replicationSourceRepository, "main")
+	require.NoError(t, err)
+	targetMain, err := c.GetBranchReference(ctx, replicationTargetRepository, "main")
+	require.NoError(t, err)
+	require.Equal(t, sourceMain, targetMain)
+	tag, err := c.GetTag(ctx, replicationTargetRepository, "v1")
+	require.NoError(t, err)
+	require.Equal(t, targetMain, tag)
+
+	// commits that hold a full address, and their descendants, are rewritten
+	sourceFull, err := c.GetBranchReference(ctx, replicationSourceRepository, "full")
+	require.NoError(t, err)
+	targetFull, err := c.GetBranchReference(ctx, replicationTargetRepository, "full")
+	require.NoError(t, err)
+	require.NotEqual(t, sourceFull, targetFull)
+
+	for ref, paths := range map[string][]string{
+		"main": {"relative", "last"},
+		"full": {"relative", "full", "after-full"},
+	} {
+		for _, path := range paths {
+			require.Equal(t, "contents of "+path, readTargetObject(t, c, ref, path), "%s on %s", path, ref)
+		}
+	}
+	_, err = c.GetEntry(ctx, replicationTargetRepository, "main", "full", GetEntryParams{})
+	require.ErrorIs(t, err, graveler.ErrNotFound)
+}
+
+func TestCatalog_ImportRepositoryArchiveInvalid(t *testing.T) {
+	ctx := context.Background()
+	c := newReplicationTestCatalog(t)
+	archive := exportTestArchive(t, c)
+
+	tests := []struct {
+		name    string
+		archive []byte
+	}{
+		{name: "empty", archive: nil},
+		{name: "not_a_tar", archive: []byte("not a tar")},
+		// the end marker is an empty file: its header and the end of the archive take 3 blocks
+		{name: "truncated", archive: archive[:len(archive)-3*512]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.ImportRepositoryArchive(ctx, replicationTargetRepository, bytes.NewReader(tt.archive))
+			if !errors.Is(err, ErrInvalidArchive) {
+				t.Fatalf("ImportRepositoryArchive() err = %v, expected %v", err, ErrInvalidArchive)
+			}
+			// nothing was loaded, the target is still bare
+			_, err = c.GetBranchReference(ctx, replicationTargetRepository, "main")
+			require.ErrorIs(t, err, graveler.ErrNotFound)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/compress"
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+const (
+	// ObjectContentMaxSize is the size of the largest object version whose content is read by BlameObject and
+	// DiffObjectContent
+	ObjectContentMaxSize = 10 * 1024 * 1024
+
+	BlameFormatText = "text"
+	BlameFormatCSV  = "csv"
+
+	blameLogAmount = 100
+)
+
+// BlameLine is a line of a text object, or a row of a CSV object, with the commit that introduced it
+type BlameLine struct {
+	// Number is the 1-based number of the line or the row
+	Number  int
+	Content string
+	// Commit is the commit that introduced the line, nil if it is not committed yet
+	Commit *CommitLog
+}
+
+// BlameObject attributes each line of the object at path on reference to the commit that introduced it.  The
+// history of the object is the log of commits that changed it, and each line is attributed to the newest commit
+// whose previous version of the object did not contain it.  format is BlameFormatText or BlameFormatCSV, which
+// attributes CSV rows rather than lines; an empty format is chosen by the extension of path.
+func (c *Catalog) BlameObject(ctx context.Context, repositoryID, reference, path, format string) ([]*BlameLine, error) {
+	if format == "" {
+		format = blameFormatOf(path)
+	}
+	if format != BlameFormatText && format != BlameFormatCSV {
+		return nil, fmt.Errorf("blame format %s: %w", format, graveler.ErrInvalidValue)
+	}
+	content, err := c.readBlameVersion(ctx, repositoryID, reference, path)
+	if err != nil {
+		return nil, err
+	}
+	units, err := splitBlameUnits(content, format)
+	if err != nil {
+		return nil, err
+	}
+	blame := newUnitBlame(units)
+
+	var commits []*CommitLog
+	after := ""
+	for !blame.done() {
+		logs, hasMore, err := c.ListCommits(ctx, repositoryID, reference, LogParams{
+			PathList:      []PathRecord{{Path: Path(path)}},
+			FromReference: after,
+			Amount:        blameLogAmount,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, commit := range logs {
+			// the version of the object the commit created - the previous version of the one blamed last
+			content, err := c.readBlameVersion(ctx, repositoryID, commit.Reference, path)
+			if errors.Is(err, graveler.ErrNotFound) {
+				content = nil
+			} else if err != nil {
+				return nil, err
+			}
+			previous, err := splitBlameUnits(content, format)
+			if err != nil {
+				return nil, err
+			}
+			blame.step(previous)
+			commits = append(commits, commit)
+			if blame.done() {
+				break
+			}
+		}
+		if !hasMore || len(logs) == 0 {
+			break
+		}
+		after = logs[len(logs)-1].Reference
+	}
+	// whatever the oldest commit contains was introduced by it
+	blame.step(nil)
+
+	res := make([]*BlameLine, len(units))
+	for i, unit := range units {
+		line := &BlameLine{
+			Number:  i + 1,
+			Content: unit,
+		}
+		// version 0 is the object on reference, version i is the object before commit i-1
+		if version := blame.origins[i]; version > 0 {
+			line.Commit = commits[version-1]
+		}
+		res[i] = line
+	}
+	return res, nil
+}
+
+func blameFormatOf(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return BlameFormatCSV
+	}
+	return BlameFormatText
+}
+
+// readBlameVersion returns the content of the object at path on reference
+func (c *Catalog) readBlameVersion(ctx context.Context, repositoryID, reference, path string) ([]byte, error) {
+	entry, err := c.GetEntry(ctx, repositoryID, reference, path, GetEntryParams{})
+	if err != nil {
+		return nil, err
+	}
+	if entry.Size > ObjectContentMaxSize {
+		return nil, fmt.Errorf("%s on %s is %d bytes, more than %d: %w", path, reference, entry.Size, ObjectContentMaxSize, ErrObjectTooLarge)
+	}
+	return c.readObjectContent(ctx, repositoryID, entry)
+}
+
+// readObjectContent returns the content of entry, up to ObjectContentMaxSize bytes
+func (c *Catalog) readObjectContent(ctx context.Context, repositoryID string, entry *DBEntry) ([]byte, error) {
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := c.BlockAdapter.Get(compress.WithEntry(ctx, entry.Metadata), block.ObjectPointer{
+		StorageNamespace: repository.StorageNamespace.String(),
+		IdentifierType:   entry.AddressType.ToIdentifierType(),
+		Identifier:       entry.PhysicalAddress,
+	}, entry.Size)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+	return io.ReadAll(io.LimitReader(reader, ObjectContentMaxSize))
+}
+
+// splitBlameUnits splits content into the lines or the CSV rows that are blamed
+func splitBlameUnits(content []byte, format string) ([]string, error) {
+	if len(content) == 0 {
+		return nil, nil
+	}
+	if format != BlameFormatCSV {
+		return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n"), nil
+	}
+	r := csv.NewReader(bytes.NewReader(content))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	var rows []string
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv: %s: %w", err, graveler.ErrInvalidValue)
+		}
+		var buf strings.Builder
+		w := csv.NewWriter(&buf)
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+		w.Flush()
+		rows = append(rows, strings.TrimSuffix(buf.String(), "\n"))
+	}
+}
+
+// unitBlame attributes the units of a version to the versions that introduced them, by following them through
+// older and older versions
+type unitBlame struct {
+	// current holds the units of the version being examined
+	current []string
+	// version is the index of the version being examined
+	version int
+	// pending maps the units of current that are not attributed yet to their index in the blamed version
+	pending map[int]int
+	// origins holds the version that introduced each unit of the blamed version
+	origins []int
+}
+
+func newUnitBlame(units []string) *unitBlame {
+	pending := make(map[int]int, len(units))
+	for i := range units {
+		pending[i] = i
+	}
+	return &unitBlame{
+		current: units,
+		pending: pending,
+		origins: make([]int, len(units)),
+	}
+}
+
+func (b *unitBlame) done() bool {
+	return len(b.pending) == 0
+}
+
+// step attributes the pending units that previous, the version preceding the current one, does not contain to the
+// current version, and moves on to previous
+func (b *unitBlame) step(previous []string) {
+	matched := make(map[int]int)
+	matcher := difflib.NewMatcherWithJunk(b.current, previous, false, nil)
+	for _, m := range matcher.GetMatchingBlocks() {
+		for i := 0; i < m.Size; i++ {
+			matched[m.A+i] = m.B + i
+		}
+	}
+	pending := make(map[int]int, len(b.pending))
+	for unit, origin := range b.pending {
+		if prev, ok := matched[unit]; ok {
+			pending[prev] = origin
+		} else {
+			b.origins[origin] = b.version
+		}
+	}
+	b.current = previous
+	b.version++
+	b.pending = pending
+}
@@ -0,0 +1,65 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestUnitBlame(t *testing.T) {
+	// versions of an object, newest first
+	versions := [][]string{
+		{"a", "b2", "c", "d", "e"},
+		{"a", "b", "c", "d", "e"},
+		{"a", "b", "d"},
+		{"a", "d"},
+	}
+	blame := newUnitBlame(versions[0])
+	for _, version := range versions[1:] {
+		blame.step(version)
+	}
+	blame.step(nil)
+	// b2 is introduced by version 0, c and e by version 1, b by version 2 (not blamed), a and d by version 3
+	expected := []int{3, 0, 1, 3, 1}
+	if diff := deep.Equal(blame.origins, expected); diff != nil {
+		t.Fatalf("unexpected origins: %v", diff)
+	}
+}
+
+func TestUnitBlame_RepeatedLines(t *testing.T) {
+	blame := newUnitBlame([]string{"x", "x", "x"})
+	blame.step([]string{"x", "x"})
+	blame.step(nil)
+	if !blame.done() {
+		t.Fatal("expected all units to be blamed")
+	}
+	expected := []int{1, 1, 0}
+	if diff := deep.Equal(blame.origins, expected); diff != nil {
+		t.Fatalf("unexpected origins: %v", diff)
+	}
+}
+
+func TestSplitBlameUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		format   string
+		expected []string
+	}{
+		{name: "empty", content: "", format: BlameFormatText, expected: nil},
+		{name: "text", content: "a\nb\n", format: BlameFormatText, expected: []string{"a", "b"}},
+		{name: "text_no_final_newline", content: "a\nb", format: BlameFormatText, expected: []string{"a", "b"}},
+		{name: "csv", content: "id,name\n1,\"multi\nline\"\n2,x\n", format: BlameFormatCSV, expected: []string{"id,name", "1,\"multi\nline\"", "2,x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			units, err := splitBlameUnits([]byte(tt.content), tt.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := deep.Equal(units, tt.expected); diff != nil {
+				t.Fatalf("unexpected units: %v", diff)
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"io"
 	"net/url"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/rs/xid"
 	"github.com/treeverse/lakefs/pkg/batch"
 	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/compress"
 	"github.com/treeverse/lakefs/pkg/block/factory"
 	"github.com/treeverse/lakefs/pkg/config"
 	"github.com/treeverse/lakefs/pkg/graveler"
@@ -63,6 +65,12 @@ const (
 	// Total per entry ~52 bytes
 	// Deviation with gcPeriodicCheckSize = 100000 will be around 5 MB
 	gcPeriodicCheckSize = 100000
+
+	// copyObjectMaxSize is the size of the largest object copied by a single request to the object store, the
+	// limit of S3 CopyObject
+	copyObjectMaxSize = 5 * 1024 * 1024 * 1024
+	// copyObjectPartSize is the size of the parts in which larger objects are copied
+	copyObjectPartSize = 1024 * 1024 * 1024
 )
 
 type Path string
@@ -125,6 +133,13 @@ const (
 	MetaRangeFSName = "meta-range"
 )
 
+const (
+	// TemplateRepositoryMetadataKey and TemplateCommitMetadataKey record the source of the first commit of a repository
+	// created from a template
+	TemplateRepositoryMetadataKey = ".lakefs.template.repository"
+	TemplateCommitMetadataKey     = ".lakefs.template.commit"
+)
+
 type Config struct {
 	Config                *config.Config
 	KVStore               kv.Store
@@ -146,12 +161,15 @@ type Catalog struct {
 	addressProvider       *ident.HexAddressProvider
 	UGCPrepareMaxFileSize int64
 	UGCPrepareInterval    time.Duration
+	replicator            *replicator
+	continuousImporter    *continuousImporter
 }
 
 const (
 	ListRepositoriesLimitMax = 1000
 	ListBranchesLimitMax     = 1000
 	ListTagsLimitMax         = 1000
+	ListReflogLimitMax       = 1000
 	DiffLimitMax             = 1000
 	ListEntriesLimitMax      = 10000
 	sharedWorkers            = 30
@@ -170,6 +188,9 @@ type ImportPath struct {
 	Path        string
 	Destination string
 	Type        ImportPathType
+	// InventoryManifest is the location of an inventory manifest to read
+	// the objects under Path from, instead of listing them.
+	InventoryManifest string
 }
 
 func GetImportPathType(t string) (ImportPathType, error) {
@@ -288,11 +309,18 @@ func New(ctx context.Context, cfg Config) (*Catalog, error) {
 	protectedBranchesManager := branch.NewProtectionManager(settingManager)
 	stagingManager := staging.NewManager(ctx, cfg.KVStore, storeLimiter, cfg.Config.Graveler.BatchDBIOTransactionMarkers, executor)
 	gStore := graveler.NewGraveler(committedManager, stagingManager, refManager, gcManager, protectedBranchesManager)
+	if compactionCfg := cfg.Config.Graveler.Compaction; compactionCfg.Enabled {
+		compactor := staging.NewCompactor(refManager, stagingManager, staging.CompactorParams{
+			Interval:              compactionCfg.Interval,
+			SealedTokensThreshold: compactionCfg.SealedTokensThreshold,
+		})
+		go compactor.Run(ctx)
+	}
 
 	// The size of the workPool is determined by the number of workers and the number of desired pending tasks for each worker.
 	workPool := pond.New(sharedWorkers, sharedWorkers*pendingTasksPerWorker, pond.Context(ctx))
 
-	return &Catalog{
+	c := &Catalog{
 		BlockAdapter:          tierFSParams.Adapter,
 		Store:                 gStore,
 		UGCPrepareMaxFileSize: cfg.Config.UGC.PrepareMaxFileSize,
@@ -305,7 +333,45 @@ func New(ctx context.Context, cfg Config) (*Catalog, error) {
 		managers:              []io.Closer{sstableManager, sstableMetaManager, &ctxCloser{cancelFn}},
 		KVStoreLimited:        storeLimiter,
 		addressProvider:       addressProvider,
-	}, nil
+	}
+
+	if replicationCfg := cfg.Config.Replication; len(replicationCfg.Rules) > 0 {
+		rules := make([]ReplicationRule, 0, len(replicationCfg.Rules))
+		for _, rule := range replicationCfg.Rules {
+			rules = append(rules, ReplicationRule{
+				SourceRepository: rule.SourceRepository,
+				Branches:         rule.Branches,
+				TargetRepository: rule.TargetRepository,
+			})
+		}
+		c.replicator, err = newReplicator(c, rules, replicationCfg.Interval)
+		if err != nil {
+			cancelFn()
+			return nil, fmt.Errorf("replication: %w", err)
+		}
+		c.SetHooksHandler(&graveler.HooksNoOp{})
+		go c.replicator.Run(ctx)
+	}
+
+	if importCfg := cfg.Config.ContinuousImport; len(importCfg.Feeds) > 0 {
+		feeds := make([]ContinuousImportFeed, 0, len(importCfg.Feeds))
+		for _, feed := range importCfg.Feeds {
+			feeds = append(feeds, ContinuousImportFeed{
+				Repository:  feed.Repository,
+				Branch:      feed.Branch,
+				Source:      feed.Source,
+				Destination: feed.Destination,
+				Events:      NewDirectoryEventSource(feed.EventsDir),
+			})
+		}
+		c.continuousImporter, err = newContinuousImporter(c, feeds, importCfg.Interval, importCfg.MaxEventsPerCommit)
+		if err != nil {
+			cancelFn()
+			return nil, fmt.Errorf("continuous import: %w", err)
+		}
+		go c.continuousImporter.Run(ctx)
+	}
+	return c, nil
 }
 
 func newLimiter(rateLimit int) ratelimit.Limiter {
@@ -319,6 +385,9 @@ func newLimiter(rateLimit int) ratelimit.Limiter {
 }
 
 func (c *Catalog) SetHooksHandler(hooks graveler.HooksHandler) {
+	if c.replicator != nil {
+		hooks = &replicationHooks{HooksHandler: hooks, replicator: c.replicator}
+	}
 	c.Store.SetHooksHandler(hooks)
 }
 
@@ -374,6 +443,89 @@ func (c *Catalog) CreateBareRepository(ctx context.Context, repository string, s
 	return catalogRepo, nil
 }
 
+// CreateRepositoryFromTemplate creates a new repository pointing to 'storageNamespace' whose default branch starts at the content of
+// 'templateRef' in 'templateRepository'.  The objects of the template are copied into 'storageNamespace' before the repository is
+// stored, so the new repository owns its objects and a failed copy leaves no repository behind.
+func (c *Catalog) CreateRepositoryFromTemplate(ctx context.Context, repository string, storageNamespace string, branch string, templateRepository string, templateRef string, committer string) (*Repository, error) {
+	repositoryID := graveler.RepositoryID(repository)
+	storageNS := graveler.StorageNamespace(storageNamespace)
+	branchID := graveler.BranchID(branch)
+	templateRepositoryID := graveler.RepositoryID(templateRepository)
+	ref := graveler.Ref(templateRef)
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "name", Value: repositoryID, Fn: graveler.ValidateRepositoryID},
+		{Name: "storageNamespace", Value: storageNS, Fn: graveler.ValidateStorageNamespace},
+		{Name: "branch", Value: branchID, Fn: graveler.ValidateBranchID},
+		{Name: "templateRepository", Value: templateRepositoryID, Fn: graveler.ValidateRepositoryID},
+		{Name: "templateRef", Value: ref, Fn: graveler.ValidateRef},
+	}); err != nil {
+		return nil, err
+	}
+	template, err := c.getRepository(ctx, templateRepository)
+	if err != nil {
+		return nil, err
+	}
+	commitID, err := c.dereferenceCommitID(ctx, template, ref)
+	if err != nil {
+		return nil, err
+	}
+	params := graveler.CommitParams{
+		Committer: committer,
+		Message:   fmt.Sprintf("Create repository from template %s@%s", templateRepository, templateRef),
+		Metadata: graveler.Metadata{
+			TemplateRepositoryMetadataKey: templateRepository,
+			TemplateCommitMetadataKey:     commitID.String(),
+		},
+	}
+	repo, err := c.Store.CreateRepositoryFromTemplate(ctx, repositoryID, storageNS, branchID, template, commitID, params, c.templateObjectCopier(ctx, template.StorageNamespace, storageNS))
+	if err != nil {
+		return nil, err
+	}
+	catalogRepo := &Repository{
+		Name:             repositoryID.String(),
+		StorageNamespace: storageNS.String(),
+		DefaultBranch:    branchID.String(),
+		CreationDate:     repo.CreationDate,
+	}
+	return catalogRepo, nil
+}
+
+// templateObjectCopier returns a graveler.ValueRewriteFunc that copies each object stored in the template 'templateNamespace' to a
+// new relative address in 'storageNamespace'.  Entries with full addresses outside the template storage namespace, such as
+// imported objects, are not managed by either repository and are kept unchanged.
+func (c *Catalog) templateObjectCopier(ctx context.Context, templateNamespace, storageNamespace graveler.StorageNamespace) graveler.ValueRewriteFunc {
+	normalizedTemplateNamespace := templateNamespace.String()
+	if !strings.HasSuffix(normalizedTemplateNamespace, DefaultPathDelimiter) {
+		normalizedTemplateNamespace += DefaultPathDelimiter
+	}
+	return func(record *graveler.ValueRecord) (*graveler.Value, error) {
+		entry, err := ValueToEntry(record.Value)
+		if err != nil {
+			return nil, fmt.Errorf("entry %s: %w", record.Key, err)
+		}
+		if entry.AddressType != Entry_RELATIVE && !strings.HasPrefix(entry.Address, normalizedTemplateNamespace) {
+			return nil, nil
+		}
+		srcObject := block.ObjectPointer{
+			StorageNamespace: templateNamespace.String(),
+			IdentifierType:   AddressType(entry.AddressType).ToIdentifierType(),
+			Identifier:       entry.Address,
+		}
+		entry.Address = c.PathProvider.NewPath()
+		entry.AddressType = Entry_RELATIVE
+		destObject := block.ObjectPointer{
+			StorageNamespace: storageNamespace.String(),
+			IdentifierType:   block.IdentifierTypeRelative,
+			Identifier:       entry.Address,
+		}
+		entry.Metadata, err = copyBlockObject(ctx, c.BlockAdapter, srcObject, destObject, entry.Metadata, entry.Size, copyObjectMaxSize, copyObjectPartSize)
+		if err != nil {
+			return nil, fmt.Errorf("copy object of %s: %w", record.Key, err)
+		}
+		return EntryToValue(entry)
+	}
+}
+
 func (c *Catalog) getRepository(ctx context.Context, repository string) (*graveler.RepositoryRecord, error) {
 	repositoryID := graveler.RepositoryID(repository)
 	return c.Store.GetRepository(ctx, repositoryID)
@@ -422,9 +574,86 @@ func (c *Catalog) GetRepositoryMetadata(ctx context.Context, repository string)
 	return c.Store.GetRepositoryMetadata(ctx, repositoryID)
 }
 
+// SetRepositoryCompression sets the codec that compresses data written to
+// repository.  It applies only when the block adapter compresses data.
+func (c *Catalog) SetRepositoryCompression(ctx context.Context, repository string, codec compress.Codec) error {
+	repositoryID := graveler.RepositoryID(repository)
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "repository", Value: repositoryID, Fn: graveler.ValidateRepositoryID},
+	}); err != nil {
+		return err
+	}
+	repo, err := c.getRepository(ctx, repository)
+	if err != nil {
+		return err
+	}
+	if err := c.checkWritable(ctx, repo); err != nil {
+		return err
+	}
+	return c.Store.SetRepositoryMetadata(ctx, repo, func(metadata graveler.RepositoryMetadata) (graveler.RepositoryMetadata, error) {
+		if codec == compress.CodecNone {
+			delete(metadata, graveler.MetadataKeyCompression)
+		} else {
+			metadata[graveler.MetadataKeyCompression] = codec.String()
+		}
+		return metadata, nil
+	})
+}
+
+// GetRepositoryCompression returns the codec that compresses data written
+// to repository.
+func (c *Catalog) GetRepositoryCompression(ctx context.Context, repository string) (compress.Codec, error) {
+	metadata, err := c.GetRepositoryMetadata(ctx, repository)
+	if err != nil {
+		return compress.CodecNone, err
+	}
+	return compress.ParseCodec(metadata[graveler.MetadataKeyCompression])
+}
+
+// SetRepositoryAccessState sets the access state of repository.  Read-only
+// and archived repositories reject all changes.
+func (c *Catalog) SetRepositoryAccessState(ctx context.Context, repository string, state graveler.RepositoryAccessState) error {
+	repositoryID := graveler.RepositoryID(repository)
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "repository", Value: repositoryID, Fn: graveler.ValidateRepositoryID},
+	}); err != nil {
+		return err
+	}
+	if _, err := graveler.ParseRepositoryAccessState(state.String()); err != nil {
+		return err
+	}
+	repo, err := c.getRepository(ctx, repository)
+	if err != nil {
+		return err
+	}
+	return c.Store.SetRepositoryMetadata(ctx, repo, func(metadata graveler.RepositoryMetadata) (graveler.RepositoryMetadata, error) {
+		if state == graveler.RepositoryAccessStateActive {
+			delete(metadata, graveler.MetadataKeyAccessState)
+		} else {
+			metadata[graveler.MetadataKeyAccessState] = state.String()
+		}
+		return metadata, nil
+	})
+}
+
+// GetRepositoryAccessState returns the access state of repository.
+func (c *Catalog) GetRepositoryAccessState(ctx context.Context, repository string) (graveler.RepositoryAccessState, error) {
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "repository", Value: graveler.RepositoryID(repository), Fn: graveler.ValidateRepositoryID},
+	}); err != nil {
+		return "", err
+	}
+	repo, err := c.getRepository(ctx, repository)
+	if err != nil {
+		return "", err
+	}
+	return c.Store.GetRepositoryAccessState(ctx, repo)
+}
+
 // ListRepositories list repository information, the bool returned is true when more repositories can be listed.
-// In this case, pass the last repository name as 'after' on the next call to ListRepositories
-func (c *Catalog) ListRepositories(ctx context.Context, limit int, prefix, after string) ([]*Repository, bool, error) {
+// In this case, pass the last repository name as 'after' on the next call to ListRepositories.  Archived
+// repositories are listed only if includeArchived.
+func (c *Catalog) ListRepositories(ctx context.Context, limit int, prefix, after string, includeArchived bool) ([]*Repository, bool, error) {
 	// normalize limit
 	if limit < 0 || limit > ListRepositoriesLimitMax {
 		limit = ListRepositoriesLimitMax
@@ -457,6 +686,15 @@ func (c *Catalog) ListRepositories(ctx context.Context, limit int, prefix, after
 		if record.RepositoryID == afterRepositoryID {
 			continue
 		}
+		if !includeArchived {
+			archived, err := c.isArchived(ctx, record)
+			if err != nil {
+				return nil, false, err
+			}
+			if archived {
+				continue
+			}
+		}
 		repos = append(repos, &Repository{
 			Name:             record.RepositoryID.String(),
 			StorageNamespace: record.StorageNamespace.String(),
@@ -624,6 +862,65 @@ func (c *Catalog) ListBranches(ctx context.Context, repositoryID string, prefix
 	return branches, hasMore, nil
 }
 
+// ListBranchReflog lists the movements of the head of branch, newest first
+func (c *Catalog) ListBranchReflog(ctx context.Context, repositoryID string, branch string, limit int, after string) ([]*ReflogEntry, bool, error) {
+	branchID := graveler.BranchID(branch)
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "repository", Value: repositoryID, Fn: graveler.ValidateRepositoryID},
+		{Name: "branch", Value: branchID, Fn: graveler.ValidateBranchID},
+	}); err != nil {
+		return nil, false, err
+	}
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, false, err
+	}
+	if _, err := c.Store.GetBranch(ctx, repository, branchID); err != nil {
+		return nil, false, err
+	}
+
+	// normalize limit
+	if limit < 0 || limit > ListReflogLimitMax {
+		limit = ListReflogLimitMax
+	}
+	it, err := c.Store.ListBranchReflog(ctx, repository, branchID)
+	if err != nil {
+		return nil, false, err
+	}
+	defer it.Close()
+	if after != "" {
+		it.SeekGE(after)
+	}
+	var entries []*ReflogEntry
+	for it.Next() {
+		v := it.Value()
+		if v.ID == after {
+			continue
+		}
+		entries = append(entries, &ReflogEntry{
+			ID:           v.ID,
+			OldCommitID:  v.OldCommitID.String(),
+			NewCommitID:  v.NewCommitID.String(),
+			Operation:    v.Operation,
+			User:         v.User,
+			CreationDate: v.CreationDate,
+		})
+		if len(entries) >= limit+1 {
+			break
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, false, err
+	}
+	// return results (optionally trimmed) and hasMore
+	hasMore := false
+	if len(entries) > limit {
+		hasMore = true
+		entries = entries[:limit]
+	}
+	return entries, hasMore, nil
+}
+
 func (c *Catalog) BranchExists(ctx context.Context, repositoryID string, branch string) (bool, error) {
 	branchID := graveler.BranchID(branch)
 	if err := validator.Validate([]validator.ValidateArg{
@@ -1073,6 +1370,101 @@ func (c *Catalog) Commit(ctx context.Context, repositoryID, branch, message, com
 	return catalogCommitLog, nil
 }
 
+func (c *Catalog) CommitChanges(ctx context.Context, repositoryID, branch, parent string, changes []EntryChange, message, committer string, metadata Metadata) (*CommitLog, error) {
+	branchID := graveler.BranchID(branch)
+	parentID := graveler.CommitID(parent)
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "repository", Value: repositoryID, Fn: graveler.ValidateRepositoryID},
+		{Name: "branch", Value: branchID, Fn: graveler.ValidateBranchID},
+		{Name: "parent", Value: parent, Fn: validator.ValidateRequiredString},
+	}); err != nil {
+		return nil, err
+	}
+	records := make([]*graveler.ValueRecord, 0, len(changes))
+	for _, change := range changes {
+		if err := ValidatePath(Path(change.Path)); err != nil {
+			return nil, err
+		}
+		record := &graveler.ValueRecord{Key: graveler.Key(change.Path)}
+		if change.Entry != nil {
+			value, err := EntryToValue(newEntryFromCatalogEntry(*change.Entry))
+			if err != nil {
+				return nil, err
+			}
+			record.Value = value
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return bytes.Compare(records[i].Key, records[j].Key) < 0
+	})
+	for i := 1; i < len(records); i++ {
+		if bytes.Equal(records[i-1].Key, records[i].Key) {
+			return nil, fmt.Errorf("path %s changed more than once: %w", records[i].Key, graveler.ErrInvalidValue)
+		}
+	}
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	commitID, err := c.Store.CommitChanges(ctx, repository, branchID, parentID, &valueRecordsIterator{records: records}, graveler.CommitParams{
+		Committer: committer,
+		Message:   message,
+		Metadata:  map[string]string(metadata),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.GetCommit(ctx, repositoryID, commitID.String())
+}
+
+func (c *Catalog) CommitBranches(ctx context.Context, committer string, commits []BranchCommit) ([]*CommitLog, error) {
+	repositories := make(map[string]*graveler.RepositoryRecord)
+	branchCommits := make([]graveler.BranchCommit, 0, len(commits))
+	for _, bc := range commits {
+		branchID := graveler.BranchID(bc.Branch)
+		if err := validator.Validate([]validator.ValidateArg{
+			{Name: "repository", Value: bc.Repository, Fn: graveler.ValidateRepositoryID},
+			{Name: "branch", Value: branchID, Fn: graveler.ValidateBranchID},
+		}); err != nil {
+			return nil, err
+		}
+		repository, ok := repositories[bc.Repository]
+		if !ok {
+			var err error
+			repository, err = c.getRepository(ctx, bc.Repository)
+			if err != nil {
+				return nil, err
+			}
+			repositories[bc.Repository] = repository
+		}
+		branchCommits = append(branchCommits, graveler.BranchCommit{
+			Repository: repository,
+			BranchID:   branchID,
+			Params: graveler.CommitParams{
+				Committer: committer,
+				Message:   bc.Message,
+				Date:      bc.Date,
+				Metadata:  map[string]string(bc.Metadata),
+			},
+		})
+	}
+
+	commitIDs, err := c.Store.CommitBranches(ctx, branchCommits)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]*CommitLog, 0, len(commitIDs))
+	for i, commitID := range commitIDs {
+		commit, err := c.Store.GetCommit(ctx, branchCommits[i].Repository, commitID)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, CommitRecordToLog(&graveler.CommitRecord{CommitID: commitID, Commit: commit}))
+	}
+	return logs, nil
+}
+
 func (c *Catalog) GetCommit(ctx context.Context, repositoryID string, reference string) (*CommitLog, error) {
 	if err := validator.Validate([]validator.ValidateArg{
 		{Name: "repository", Value: repositoryID, Fn: graveler.ValidateRepositoryID},
@@ -1106,11 +1498,52 @@ func (c *Catalog) GetCommit(ctx context.Context, repositoryID string, reference
 	return catalogCommitLog, nil
 }
 
+func (c *Catalog) SignCommit(ctx context.Context, repositoryID, commitID string, signature CommitSignature) error {
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "repository", Value: repositoryID, Fn: graveler.ValidateRepositoryID},
+		{Name: "commitID", Value: commitID, Fn: validator.ValidateRequiredString},
+	}); err != nil {
+		return err
+	}
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return err
+	}
+	return c.Store.SignCommit(ctx, repository, graveler.CommitSignature{
+		CommitID:       graveler.CommitID(commitID),
+		KeyFingerprint: signature.KeyFingerprint,
+		Signature:      signature.Signature,
+		CreationDate:   signature.CreationDate,
+	})
+}
+
+func (c *Catalog) GetCommitSignature(ctx context.Context, repositoryID, commitID string) (*CommitSignature, error) {
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "repository", Value: repositoryID, Fn: graveler.ValidateRepositoryID},
+		{Name: "commitID", Value: commitID, Fn: validator.ValidateRequiredString},
+	}); err != nil {
+		return nil, err
+	}
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := c.Store.GetCommitSignature(ctx, repository, graveler.CommitID(commitID))
+	if err != nil {
+		return nil, err
+	}
+	return &CommitSignature{
+		KeyFingerprint: signature.KeyFingerprint,
+		Signature:      signature.Signature,
+		CreationDate:   signature.CreationDate,
+	}, nil
+}
+
 func (c *Catalog) ListCommits(ctx context.Context, repositoryID string, branch string, params LogParams) ([]*CommitLog, bool, error) {
-	branchRef := graveler.BranchID(branch)
+	branchRef := graveler.Ref(branch)
 	if err := validator.Validate([]validator.ValidateArg{
 		{Name: "repository", Value: repositoryID, Fn: graveler.ValidateRepositoryID},
-		{Name: "branch", Value: branchRef, Fn: graveler.ValidateBranchID},
+		{Name: "branch", Value: branchRef, Fn: graveler.ValidateRef},
 	}); err != nil {
 		return nil, false, err
 	}
@@ -1122,7 +1555,7 @@ func (c *Catalog) ListCommits(ctx context.Context, repositoryID string, branch s
 		return nil, false, err
 	}
 
-	commitID, err := c.dereferenceCommitID(ctx, repository, graveler.Ref(branchRef))
+	commitID, err := c.dereferenceCommitID(ctx, repository, branchRef)
 	if err != nil {
 		return nil, false, fmt.Errorf("branch ref: %w", err)
 	}
@@ -1527,6 +1960,73 @@ func (c *Catalog) CherryPick(ctx context.Context, repositoryID string, branch st
 	return catalogCommitLog, nil
 }
 
+func (c *Catalog) Squash(ctx context.Context, repositoryID string, branch string, params SquashParams) (*CommitLog, error) {
+	branchID := graveler.BranchID(branch)
+	reference := graveler.Ref(params.Reference)
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "repository", Value: repositoryID, Fn: graveler.ValidateRepositoryID},
+		{Name: "branch", Value: branchID, Fn: graveler.ValidateBranchID},
+		{Name: "ref", Value: reference, Fn: graveler.ValidateRef},
+		{Name: "committer", Value: params.Committer, Fn: validator.ValidateRequiredString},
+	}); err != nil {
+		return nil, err
+	}
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	commitID, err := c.Store.Squash(ctx, repository, branchID, reference, graveler.CommitParams{
+		Committer: params.Committer,
+		Message:   params.Message,
+		Metadata:  map[string]string(params.Metadata),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.commitLog(ctx, repository, commitID)
+}
+
+func (c *Catalog) Rewrite(ctx context.Context, repositoryID string, branch string, params RewriteParams) (*CommitLog, error) {
+	branchID := graveler.BranchID(branch)
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "repository", Value: repositoryID, Fn: graveler.ValidateRepositoryID},
+		{Name: "branch", Value: branchID, Fn: graveler.ValidateBranchID},
+		{Name: "committer", Value: params.Committer, Fn: validator.ValidateRequiredString},
+	}); err != nil {
+		return nil, err
+	}
+	exclude := make([]graveler.Ref, 0, len(params.Exclude))
+	for _, ref := range params.Exclude {
+		reference := graveler.Ref(ref)
+		if err := validator.Validate([]validator.ValidateArg{
+			{Name: "exclude", Value: reference, Fn: graveler.ValidateRef},
+		}); err != nil {
+			return nil, err
+		}
+		exclude = append(exclude, reference)
+	}
+	repository, err := c.getRepository(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	commitID, err := c.Store.Rewrite(ctx, repository, branchID, exclude, params.Committer)
+	if err != nil {
+		return nil, err
+	}
+	return c.commitLog(ctx, repository, commitID)
+}
+
+// commitLog returns the commit log of commitID
+func (c *Catalog) commitLog(ctx context.Context, repository *graveler.RepositoryRecord, commitID graveler.CommitID) (*CommitLog, error) {
+	commit, err := c.Store.GetCommit(ctx, repository, commitID)
+	if err != nil {
+		return nil, graveler.ErrCommitNotFound
+	}
+	return CommitRecordToLog(&graveler.CommitRecord{CommitID: commitID, Commit: commit}), nil
+}
+
 func (c *Catalog) Diff(ctx context.Context, repositoryID string, leftReference string, rightReference string, params DiffParams) (Differences, bool, error) {
 	left := graveler.Ref(leftReference)
 	right := graveler.Ref(rightReference)
@@ -1792,6 +2292,9 @@ func (c *Catalog) LoadCommits(ctx context.Context, repositoryID, commitsMetaRang
 	if err != nil {
 		return err
 	}
+	if err := c.checkWritable(ctx, repository); err != nil {
+		return err
+	}
 	return c.Store.LoadCommits(ctx, repository, graveler.MetaRangeID(commitsMetaRangeID))
 }
 
@@ -1800,6 +2303,9 @@ func (c *Catalog) LoadBranches(ctx context.Context, repositoryID, branchesMetaRa
 	if err != nil {
 		return err
 	}
+	if err := c.checkWritable(ctx, repository); err != nil {
+		return err
+	}
 	return c.Store.LoadBranches(ctx, repository, graveler.MetaRangeID(branchesMetaRangeID))
 }
 
@@ -1808,9 +2314,24 @@ func (c *Catalog) LoadTags(ctx context.Context, repositoryID, tagsMetaRangeID st
 	if err != nil {
 		return err
 	}
+	if err := c.checkWritable(ctx, repository); err != nil {
+		return err
+	}
 	return c.Store.LoadTags(ctx, repository, graveler.MetaRangeID(tagsMetaRangeID))
 }
 
+func (c *Catalog) GetReplicationStatus(ctx context.Context, repositoryID string) ([]*ReplicationStatus, error) {
+	if err := validator.Validate([]validator.ValidateArg{
+		{Name: "repository", Value: graveler.RepositoryID(repositoryID), Fn: graveler.ValidateRepositoryID},
+	}); err != nil {
+		return nil, err
+	}
+	if c.replicator == nil {
+		return nil, ErrReplicationNotConfigured
+	}
+	return c.replicator.Status(ctx, repositoryID)
+}
+
 func (c *Catalog) GetMetaRange(ctx context.Context, repositoryID, metaRangeID string) (graveler.MetaRangeAddress, error) {
 	repository, err := c.getRepository(ctx, repositoryID)
 	if err != nil {
@@ -1841,6 +2362,9 @@ func (c *Catalog) importAsync(repository *graveler.RepositoryRecord, branchID, i
 	for _, source := range params.Paths {
 		src := source // Pinning
 		wg.Submit(func() error {
+			if src.InventoryManifest != "" {
+				return c.ingestInventory(wgCtx, importManager, src, logger)
+			}
 			// TODO (niro): Need to handle this at some point (use adapter GetWalker)
 			walker, err := c.walkerFactory.GetWalker(wgCtx, store.WalkerOptions{StorageURI: src.Path})
 			if err != nil {
@@ -1931,16 +2455,40 @@ func (c *Catalog) importAsync(repository *graveler.RepositoryRecord, branchID, i
 	return nil
 }
 
+// ingestInventory ingests the objects under an import path from its inventory
+func (c *Catalog) ingestInventory(ctx context.Context, importManager *Import, src ImportPath, logger logging.Logger) error {
+	inv, err := c.BlockAdapter.GenerateInventory(ctx, logger, src.InventoryManifest, false, nil)
+	if err != nil {
+		return fmt.Errorf("loading inventory manifest %s: %w", src.InventoryManifest, err)
+	}
+	it := newInventoryEntryIterator(inv.Iterator(), src.Path, src.Destination)
+	defer it.Close()
+	logger.WithFields(logging.Fields{
+		"source":    src.Path,
+		"inventory": src.InventoryManifest,
+	}).Debug("Ingest source inventory")
+	return importManager.Ingest(it)
+}
+
 func (c *Catalog) Import(ctx context.Context, repositoryID, branchID string, params ImportRequest) (string, error) {
 	repository, err := c.getRepository(ctx, repositoryID)
 	if err != nil {
 		return "", err
 	}
+	for _, p := range params.Paths {
+		if p.InventoryManifest != "" && p.Type != ImportPathTypePrefix {
+			return "", fmt.Errorf("inventory manifest of %s import path: %w", p.Type, graveler.ErrInvalidValue)
+		}
+	}
 
 	_, err = c.Store.GetBranch(ctx, repository, graveler.BranchID(branchID))
 	if err != nil {
 		return "", err
 	}
+	// fail early, the import itself runs in the background
+	if err := c.checkWritable(ctx, repository); err != nil {
+		return "", err
+	}
 
 	id := xid.New().String()
 	// Run import
@@ -2179,6 +2727,13 @@ func (c *Catalog) PrepareGCUncommitted(ctx context.Context, repositoryID string,
 	if err != nil {
 		return nil, err
 	}
+	archived, err := c.isArchived(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	if archived {
+		return nil, graveler.ErrArchivedRepository
+	}
 
 	var runID string
 	if mark == nil {
@@ -2270,7 +2825,7 @@ func (c *Catalog) CopyEntry(ctx context.Context, srcRepository, srcRef, srcPath,
 		IdentifierType:   dstEntry.AddressType.ToIdentifierType(),
 		Identifier:       dstEntry.PhysicalAddress,
 	}
-	err = c.BlockAdapter.Copy(ctx, srcObject, destObj)
+	dstEntry.Metadata, err = copyBlockObject(ctx, c.BlockAdapter, srcObject, destObj, srcEntry.Metadata, srcEntry.Size, copyObjectMaxSize, copyObjectPartSize)
 	if err != nil {
 		return nil, err
 	}
@@ -2283,6 +2838,42 @@ func (c *Catalog) CopyEntry(ctx context.Context, srcRepository, srcRef, srcPath,
 	return &dstEntry, nil
 }
 
+// copyBlockObject copies srcObject, the object of an entry with metadata, to destObject on the underlying storage.
+// Objects larger than maxSize, which an object store may not copy in a single request, are copied as a multipart
+// upload of partSize parts.  It returns the metadata of the copy, which records how its data is stored.
+func copyBlockObject(ctx context.Context, adapter block.Adapter, srcObject, destObject block.ObjectPointer, metadata map[string]string, size, maxSize, partSize int64) (map[string]string, error) {
+	ctx = compress.WithEntry(ctx, metadata)
+	if size <= maxSize {
+		return metadata, adapter.Copy(ctx, srcObject, destObject)
+	}
+	mpu, err := adapter.CreateMultiPartUpload(ctx, destObject, nil, block.CreateMultiPartUploadOpts{})
+	if err != nil {
+		return nil, err
+	}
+	completion := &block.MultipartUploadCompletion{}
+	for start, partNumber := int64(0), 1; start < size; start, partNumber = start+partSize, partNumber+1 {
+		end := start + partSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		part, err := adapter.UploadCopyPartRange(ctx, srcObject, destObject, mpu.UploadID, partNumber, start, end)
+		if err != nil {
+			if abortErr := adapter.AbortMultiPartUpload(ctx, destObject, mpu.UploadID); abortErr != nil {
+				logging.FromContext(ctx).WithError(abortErr).WithField("upload_id", mpu.UploadID).Warn("Failed to abort multipart copy")
+			}
+			return nil, err
+		}
+		completion.Part = append(completion.Part, block.MultipartPart{
+			ETag:       part.ETag,
+			PartNumber: partNumber,
+		})
+	}
+	if _, err := adapter.CompleteMultiPartUpload(ctx, destObject, mpu.UploadID, completion); err != nil {
+		return nil, err
+	}
+	return compress.MultipartEntryMetadata(ctx, adapter, mpu.UploadID, metadata, size), nil
+}
+
 func (c *Catalog) SetLinkAddress(ctx context.Context, repository, token string) error {
 	repo, err := c.getRepository(ctx, repository)
 	if err != nil {
@@ -2329,6 +2920,24 @@ func (c *Catalog) DeleteExpiredImports(ctx context.Context) {
 	}
 }
 
+func (c *Catalog) isArchived(ctx context.Context, repository *graveler.RepositoryRecord) (bool, error) {
+	state, err := c.Store.GetRepositoryAccessState(ctx, repository)
+	if err != nil {
+		return false, err
+	}
+	return state == graveler.RepositoryAccessStateArchived, nil
+}
+
+// checkWritable returns an error if repository is read-only or archived.  Store checks it on its own writes; this
+// covers writes that Store does not check, such as loading refs.
+func (c *Catalog) checkWritable(ctx context.Context, repository *graveler.RepositoryRecord) error {
+	state, err := c.Store.GetRepositoryAccessState(ctx, repository)
+	if err != nil {
+		return err
+	}
+	return state.WriteError()
+}
+
 func (c *Catalog) listRepositoriesHelper(ctx context.Context) ([]*graveler.RepositoryRecord, error) {
 	it, err := c.Store.ListRepositories(ctx)
 	if err != nil {
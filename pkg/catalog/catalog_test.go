@@ -76,9 +76,13 @@ func TestCatalog_ListRepositories(t *testing.T) {
 		{RepositoryID: "repo2", Repository: &graveler.Repository{StorageNamespace: "storage2", CreationDate: now, DefaultBranchID: "main2"}},
 		{RepositoryID: "repo3", Repository: &graveler.Repository{StorageNamespace: "storage3", CreationDate: now, DefaultBranchID: "main3"}},
 	}
+	archivedData := map[graveler.RepositoryID]graveler.RepositoryMetadata{
+		"repo2": {graveler.MetadataKeyAccessState: graveler.RepositoryAccessStateArchived.String()},
+	}
 	type args struct {
-		limit int
-		after string
+		limit           int
+		after           string
+		includeArchived bool
 	}
 	tests := []struct {
 		name        string
@@ -90,8 +94,9 @@ func TestCatalog_ListRepositories(t *testing.T) {
 		{
 			name: "all",
 			args: args{
-				limit: -1,
-				after: "",
+				limit:           -1,
+				after:           "",
+				includeArchived: true,
 			},
 			want: []*catalog.Repository{
 				{Name: "repo1", StorageNamespace: "storage1", DefaultBranch: "main1", CreationDate: now},
@@ -104,8 +109,9 @@ func TestCatalog_ListRepositories(t *testing.T) {
 		{
 			name: "first",
 			args: args{
-				limit: 1,
-				after: "",
+				limit:           1,
+				after:           "",
+				includeArchived: true,
 			},
 			want: []*catalog.Repository{
 				{Name: "repo1", StorageNamespace: "storage1", DefaultBranch: "main1", CreationDate: now},
@@ -116,8 +122,9 @@ func TestCatalog_ListRepositories(t *testing.T) {
 		{
 			name: "second",
 			args: args{
-				limit: 1,
-				after: "repo1",
+				limit:           1,
+				after:           "repo1",
+				includeArchived: true,
 			},
 			want: []*catalog.Repository{
 				{Name: "repo2", StorageNamespace: "storage2", DefaultBranch: "main2", CreationDate: now},
@@ -128,8 +135,9 @@ func TestCatalog_ListRepositories(t *testing.T) {
 		{
 			name: "last2",
 			args: args{
-				limit: 10,
-				after: "repo1",
+				limit:           10,
+				after:           "repo1",
+				includeArchived: true,
 			},
 			want: []*catalog.Repository{
 				{Name: "repo2", StorageNamespace: "storage2", DefaultBranch: "main2", CreationDate: now},
@@ -138,6 +146,31 @@ func TestCatalog_ListRepositories(t *testing.T) {
 			wantHasMore: false,
 			wantErr:     false,
 		},
+		{
+			name: "exclude archived",
+			args: args{
+				limit: -1,
+				after: "",
+			},
+			want: []*catalog.Repository{
+				{Name: "repo1", StorageNamespace: "storage1", DefaultBranch: "main1", CreationDate: now},
+				{Name: "repo3", StorageNamespace: "storage3", DefaultBranch: "main3", CreationDate: now},
+			},
+			wantHasMore: false,
+			wantErr:     false,
+		},
+		{
+			name: "exclude archived second",
+			args: args{
+				limit: 1,
+				after: "repo1",
+			},
+			want: []*catalog.Repository{
+				{Name: "repo3", StorageNamespace: "storage3", DefaultBranch: "main3", CreationDate: now},
+			},
+			wantHasMore: false,
+			wantErr:     false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -145,13 +178,14 @@ func TestCatalog_ListRepositories(t *testing.T) {
 			// setup Catalog
 			gravelerMock := &catalog.FakeGraveler{
 				RepositoryIteratorFactory: catalog.NewFakeRepositoryIteratorFactory(gravelerData),
+				RepositoryMetadata:        archivedData,
 			}
 			c := &catalog.Catalog{
 				Store: gravelerMock,
 			}
 			// test method
 			ctx := context.Background()
-			got, hasMore, err := c.ListRepositories(ctx, tt.args.limit, "", tt.args.after)
+			got, hasMore, err := c.ListRepositories(ctx, tt.args.limit, "", tt.args.after, tt.args.includeArchived)
 			if tt.wantErr && err == nil {
 				t.Fatal("ListRepositories err nil, expected error")
 			}
@@ -0,0 +1,308 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+const (
+	ContentDiffTypeText    = "text"
+	ContentDiffTypeCSV     = "csv"
+	ContentDiffTypeParquet = "parquet"
+	ContentDiffTypeBinary  = "binary"
+
+	// ContentDiffMaxRows is the number of added and of removed rows that a table content diff lists
+	ContentDiffMaxRows = 100
+
+	contentDiffContextLines = 3
+	parquetReadBatchSize    = 1000
+)
+
+// ObjectContentSummary describes one side of an object content diff
+type ObjectContentSummary struct {
+	Size     int64
+	Checksum string
+}
+
+// TableColumn is a column of the schema of a CSV or a Parquet object
+type TableColumn struct {
+	Name string
+	Type string
+}
+
+// TableContentDiff summarizes the difference between two versions of a CSV or a Parquet object.  A changed row
+// is listed as removed and added.
+type TableContentDiff struct {
+	LeftSchema      []TableColumn
+	RightSchema     []TableColumn
+	LeftRowCount    int64
+	RightRowCount   int64
+	AddedRowCount   int
+	RemovedRowCount int
+	// AddedRows and RemovedRows hold up to ContentDiffMaxRows rows each
+	AddedRows   []string
+	RemovedRows []string
+}
+
+// ObjectContentDiff is the difference between the contents of an object on two refs
+type ObjectContentDiff struct {
+	Type string
+	// Left and Right are nil when the object does not exist on their ref
+	Left  *ObjectContentSummary
+	Right *ObjectContentSummary
+	// UnifiedDiff is set for text objects
+	UnifiedDiff string
+	// Table is set for CSV and Parquet objects
+	Table *TableContentDiff
+}
+
+// DiffObjectContent compares the contents of the object at path on leftReference and on rightReference.  Text
+// objects are compared as a unified diff, CSV and Parquet objects by schema and rows, and binary objects, or
+// objects larger than ObjectContentMaxSize, by size and checksum only.
+func (c *Catalog) DiffObjectContent(ctx context.Context, repositoryID, leftReference, rightReference, path string) (*ObjectContentDiff, error) {
+	left, leftContent, err := c.readContentDiffSide(ctx, repositoryID, leftReference, path)
+	if err != nil {
+		return nil, err
+	}
+	right, rightContent, err := c.readContentDiffSide(ctx, repositoryID, rightReference, path)
+	if err != nil {
+		return nil, err
+	}
+	if left == nil && right == nil {
+		return nil, fmt.Errorf("%s on %s and on %s: %w", path, leftReference, rightReference, graveler.ErrNotFound)
+	}
+	res := &ObjectContentDiff{
+		Type:  contentDiffTypeOf(path, left, right, leftContent, rightContent),
+		Left:  left,
+		Right: right,
+	}
+	switch res.Type {
+	case ContentDiffTypeText:
+		res.UnifiedDiff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(leftContent)),
+			B:        difflib.SplitLines(string(rightContent)),
+			FromFile: leftReference + "/" + path,
+			ToFile:   rightReference + "/" + path,
+			Context:  contentDiffContextLines,
+		})
+	case ContentDiffTypeCSV:
+		res.Table, err = diffTables(leftContent, rightContent, readCSVTable)
+	case ContentDiffTypeParquet:
+		res.Table, err = diffTables(leftContent, rightContent, readParquetTable)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// readContentDiffSide returns the summary and the content of the object at path on reference, a nil summary if it
+// does not exist, or a nil content if it is too large to read
+func (c *Catalog) readContentDiffSide(ctx context.Context, repositoryID, reference, path string) (*ObjectContentSummary, []byte, error) {
+	entry, err := c.GetEntry(ctx, repositoryID, reference, path, GetEntryParams{})
+	if errors.Is(err, graveler.ErrNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	summary := &ObjectContentSummary{
+		Size:     entry.Size,
+		Checksum: entry.Checksum,
+	}
+	if entry.Size > ObjectContentMaxSize {
+		return summary, nil, nil
+	}
+	content, err := c.readObjectContent(ctx, repositoryID, entry)
+	if err != nil {
+		return nil, nil, err
+	}
+	return summary, content, nil
+}
+
+func contentDiffTypeOf(path string, left, right *ObjectContentSummary, leftContent, rightContent []byte) string {
+	if (left != nil && leftContent == nil && left.Size > 0) || (right != nil && rightContent == nil && right.Size > 0) {
+		// too large to compare
+		return ContentDiffTypeBinary
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ContentDiffTypeCSV
+	case ".parquet":
+		return ContentDiffTypeParquet
+	}
+	if isText(leftContent) && isText(rightContent) {
+		return ContentDiffTypeText
+	}
+	return ContentDiffTypeBinary
+}
+
+func isText(content []byte) bool {
+	return utf8.Valid(content) && bytes.IndexByte(content, 0) == -1
+}
+
+// table is a CSV or a Parquet object read for a content diff.  Each row is encoded as a string.
+type table struct {
+	schema   []TableColumn
+	rowCount int64
+	rows     []string
+}
+
+type tableReaderFunc func(content []byte) (*table, error)
+
+func diffTables(leftContent, rightContent []byte, readTable tableReaderFunc) (*TableContentDiff, error) {
+	left, err := readTable(leftContent)
+	if err != nil {
+		return nil, err
+	}
+	right, err := readTable(rightContent)
+	if err != nil {
+		return nil, err
+	}
+	res := &TableContentDiff{
+		LeftSchema:    left.schema,
+		RightSchema:   right.schema,
+		LeftRowCount:  left.rowCount,
+		RightRowCount: right.rowCount,
+	}
+	matcher := difflib.NewMatcherWithJunk(left.rows, right.rows, false, nil)
+	for _, op := range matcher.GetOpCodes() {
+		if op.Tag == 'e' {
+			continue
+		}
+		for _, row := range left.rows[op.I1:op.I2] {
+			res.RemovedRowCount++
+			if len(res.RemovedRows) < ContentDiffMaxRows {
+				res.RemovedRows = append(res.RemovedRows, row)
+			}
+		}
+		for _, row := range right.rows[op.J1:op.J2] {
+			res.AddedRowCount++
+			if len(res.AddedRows) < ContentDiffMaxRows {
+				res.AddedRows = append(res.AddedRows, row)
+			}
+		}
+	}
+	return res, nil
+}
+
+// readCSVTable reads a CSV object whose first row is its header.  CSV columns have no types.
+func readCSVTable(content []byte) (*table, error) {
+	rows, err := splitBlameUnits(content, BlameFormatCSV)
+	if err != nil {
+		return nil, err
+	}
+	res := &table{}
+	if len(rows) == 0 {
+		return res, nil
+	}
+	header, err := csv.NewReader(strings.NewReader(rows[0])).Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %s: %w", err, graveler.ErrInvalidValue)
+	}
+	for _, name := range header {
+		res.schema = append(res.schema, TableColumn{Name: name})
+	}
+	res.rows = rows[1:]
+	res.rowCount = int64(len(res.rows))
+	return res, nil
+}
+
+// readParquetTable reads the schema and the rows of a Parquet object, each row encoded as JSON
+func readParquetTable(content []byte) (*table, error) {
+	res := &table{}
+	if len(content) == 0 {
+		return res, nil
+	}
+	fp, err := buffer.NewBufferFile(content)
+	if err != nil {
+		return nil, err
+	}
+	r, err := reader.NewParquetReader(fp, nil, 1)
+	if err != nil {
+		return nil, fmt.Errorf("read parquet: %s: %w", err, graveler.ErrInvalidValue)
+	}
+	defer r.ReadStop()
+	for i, elem := range r.Footer.GetSchema() {
+		if i == 0 {
+			continue // root element
+		}
+		// the reader renames the schema to Go names, the original names are kept by its schema handler
+		column := TableColumn{Name: r.SchemaHandler.Infos[i].ExName, Type: "N/A"}
+		if elem.Type != nil {
+			column.Type = elem.Type.String()
+		}
+		res.schema = append(res.schema, column)
+	}
+	// rows are read into structs with the Go names of the columns
+	columnNames := make(map[string]string, len(r.SchemaHandler.Infos))
+	for _, info := range r.SchemaHandler.Infos {
+		columnNames[info.InName] = info.ExName
+	}
+	res.rowCount = r.GetNumRows()
+	for remaining := res.rowCount; remaining > 0; remaining -= parquetReadBatchSize {
+		n := parquetReadBatchSize
+		if remaining < parquetReadBatchSize {
+			n = int(remaining)
+		}
+		rows, err := r.ReadByNumber(n)
+		if err != nil {
+			return nil, fmt.Errorf("read parquet rows: %s: %w", err, graveler.ErrInvalidValue)
+		}
+		for _, row := range rows {
+			encoded, err := encodeParquetRow(row, columnNames)
+			if err != nil {
+				return nil, err
+			}
+			res.rows = append(res.rows, encoded)
+		}
+	}
+	return res, nil
+}
+
+// encodeParquetRow encodes a row read by the Parquet reader as a JSON object keyed by the original column names,
+// in schema order
+func encodeParquetRow(row interface{}, columnNames map[string]string) (string, error) {
+	v := reflect.ValueOf(row)
+	if v.Kind() != reflect.Struct {
+		encoded, err := json.Marshal(row)
+		return string(encoded), err
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		if exName, ok := columnNames[name]; ok {
+			name = exName
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return "", err
+		}
+		value, err := json.Marshal(v.Field(i).Interface())
+		if err != nil {
+			return "", err
+		}
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.String(), nil
+}
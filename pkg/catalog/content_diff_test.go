@@ -0,0 +1,96 @@
+package catalog
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDiffTables_CSV(t *testing.T) {
+	left := []byte("id,name\n1,one\n2,two\n3,three\n")
+	right := []byte("id,name,extra\n1,one\n2,TWO\n3,three\n4,four\n")
+	res, err := diffTables(left, right, readCSVTable)
+	if err != nil {
+		t.Fatalf("diff tables: %v", err)
+	}
+	expected := &TableContentDiff{
+		LeftSchema:      []TableColumn{{Name: "id"}, {Name: "name"}},
+		RightSchema:     []TableColumn{{Name: "id"}, {Name: "name"}, {Name: "extra"}},
+		LeftRowCount:    3,
+		RightRowCount:   4,
+		AddedRowCount:   2,
+		RemovedRowCount: 1,
+		AddedRows:       []string{"2,TWO", "4,four"},
+		RemovedRows:     []string{"2,two"},
+	}
+	if diff := deep.Equal(res, expected); diff != nil {
+		t.Fatalf("unexpected table diff: %v", diff)
+	}
+}
+
+func TestDiffTables_Parquet(t *testing.T) {
+	content, err := os.ReadFile("../actions/lua/encoding/parquet/testdata/000.snappy.parquet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a new object: all rows are added
+	res, err := diffTables(nil, content, readParquetTable)
+	if err != nil {
+		t.Fatalf("diff tables: %v", err)
+	}
+	if len(res.LeftSchema) != 0 || res.LeftRowCount != 0 {
+		t.Fatalf("expected an empty left table, got schema %v and %d rows", res.LeftSchema, res.LeftRowCount)
+	}
+	const expectedColumns = 13
+	if len(res.RightSchema) != expectedColumns {
+		t.Fatalf("got %d columns, expected %d", len(res.RightSchema), expectedColumns)
+	}
+	if res.RightSchema[8].Name != "population" || res.RightSchema[8].Type != "INT32" {
+		t.Fatalf("unexpected column %+v", res.RightSchema[8])
+	}
+	if !strings.Contains(res.AddedRows[0], `"population":`) {
+		t.Fatalf("expected rows keyed by column names, got %s", res.AddedRows[0])
+	}
+	if res.RightRowCount == 0 || int64(res.AddedRowCount) != res.RightRowCount || res.RemovedRowCount != 0 {
+		t.Fatalf("expected all %d rows to be added, got %d added and %d removed", res.RightRowCount, res.AddedRowCount, res.RemovedRowCount)
+	}
+
+	// the same object: nothing changed
+	res, err = diffTables(content, content, readParquetTable)
+	if err != nil {
+		t.Fatalf("diff tables: %v", err)
+	}
+	if res.AddedRowCount != 0 || res.RemovedRowCount != 0 {
+		t.Fatalf("expected no changed rows, got %d added and %d removed", res.AddedRowCount, res.RemovedRowCount)
+	}
+}
+
+func TestContentDiffTypeOf(t *testing.T) {
+	small := &ObjectContentSummary{Size: 3}
+	large := &ObjectContentSummary{Size: ObjectContentMaxSize + 1}
+	tests := []struct {
+		name         string
+		path         string
+		left, right  *ObjectContentSummary
+		leftContent  []byte
+		rightContent []byte
+		expected     string
+	}{
+		{name: "text", path: "a.txt", left: small, right: small, leftContent: []byte("abc"), rightContent: []byte("abd"), expected: ContentDiffTypeText},
+		{name: "new_text", path: "a.json", right: small, rightContent: []byte("abc"), expected: ContentDiffTypeText},
+		{name: "csv", path: "a.CSV", left: small, right: small, leftContent: []byte("a,b"), rightContent: []byte("a,c"), expected: ContentDiffTypeCSV},
+		{name: "parquet", path: "a.parquet", left: small, leftContent: []byte("PAR"), expected: ContentDiffTypeParquet},
+		{name: "binary", path: "a.bin", left: small, right: small, leftContent: []byte{0, 1, 2}, rightContent: []byte("abc"), expected: ContentDiffTypeBinary},
+		{name: "too_large", path: "a.csv", left: small, right: large, leftContent: []byte("a,b"), expected: ContentDiffTypeBinary},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := contentDiffTypeOf(tt.path, tt.left, tt.right, tt.leftContent, tt.rightContent)
+			if got != tt.expected {
+				t.Fatalf("got type %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,487 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/logging"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+var continuousImportEventsCounter = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "catalog_continuous_import_events_total",
+		Help: "Number of object store events committed by continuous import.",
+	},
+	[]string{"repository", "branch"},
+)
+
+const (
+	continuousImportsPrefix = "continuous-imports"
+
+	// ContinuousImportCommitter is the committer of continuous import commits
+	ContinuousImportCommitter = "lakefs-continuous-import"
+	// ContinuousImportCursorMetadataKey and ContinuousImportSourceMetadataKey
+	// are set on every continuous import commit, so that a cursor that was
+	// committed but not saved can be recovered from the branch
+	ContinuousImportCursorMetadataKey = "::lakefs::continuous_import::cursor"
+	ContinuousImportSourceMetadataKey = "::lakefs::continuous_import::source"
+
+	continuousImportListBatchSize = 1000
+	// continuousImportCursorSearchDepth is the number of branch commits searched for a committed cursor
+	continuousImportCursorSearchDepth = 100
+)
+
+// ImportEventType is the type of change an ImportEvent reports
+type ImportEventType int
+
+const (
+	ImportEventCreated ImportEventType = iota
+	ImportEventRemoved
+)
+
+// ImportEvent reports an object created on or removed from the object store
+type ImportEvent struct {
+	Type   ImportEventType
+	Bucket string
+	Key    string
+	Size   int64
+	ETag   string
+	Time   time.Time
+}
+
+// ImportEventSource reads object store change events in the order they occurred
+type ImportEventSource interface {
+	// ReadEvents returns the events that follow cursor, and the cursor that
+	// follows them.  It reads at least one batch of events if there are any,
+	// and stops reading once it has read limit events.  It returns cursor
+	// itself if there are no new events.
+	ReadEvents(ctx context.Context, cursor string, limit int) ([]ImportEvent, string, error)
+}
+
+// DirectoryEventSource reads events from the files of a directory, in the
+// lexicographic order of their names.  Each file holds a notification in
+// the format of S3 event notifications, and its name serves as the cursor.
+// Names starting with "." are ignored, so writers should write each file
+// under such a name and then rename it.
+type DirectoryEventSource struct {
+	Dir string
+}
+
+func NewDirectoryEventSource(dir string) *DirectoryEventSource {
+	return &DirectoryEventSource{Dir: dir}
+}
+
+func (s *DirectoryEventSource) ReadEvents(_ context.Context, cursor string, limit int) ([]ImportEvent, string, error) {
+	dirEntries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, cursor, err
+	}
+	var events []ImportEvent
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if name <= cursor || strings.HasPrefix(name, ".") || !dirEntry.Type().IsRegular() {
+			continue
+		}
+		if len(events) >= limit {
+			break
+		}
+		data, err := os.ReadFile(filepath.Join(s.Dir, name))
+		if err != nil {
+			return nil, cursor, err
+		}
+		fileEvents, err := ParseS3Events(data)
+		if err != nil {
+			return nil, cursor, fmt.Errorf("%s: %w", name, err)
+		}
+		events = append(events, fileEvents...)
+		cursor = name
+	}
+	return events, cursor, nil
+}
+
+// s3EventNotification is the part of an S3 event notification read by ParseS3Events
+type s3EventNotification struct {
+	Records []struct {
+		EventName string    `json:"eventName"`
+		EventTime time.Time `json:"eventTime"`
+		S3        struct {
+			Bucket struct {
+				Name string `json:"name"`
+			} `json:"bucket"`
+			Object struct {
+				Key  string `json:"key"`
+				Size int64  `json:"size"`
+				ETag string `json:"eTag"`
+			} `json:"object"`
+		} `json:"s3"`
+	} `json:"Records"`
+}
+
+// ParseS3Events returns the object created and removed events of an S3
+// event notification, in their order.  Other events are skipped.
+func ParseS3Events(data []byte) ([]ImportEvent, error) {
+	var notification s3EventNotification
+	if err := json.Unmarshal(data, &notification); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidImportEvent, err)
+	}
+	events := make([]ImportEvent, 0, len(notification.Records))
+	for _, record := range notification.Records {
+		var eventType ImportEventType
+		switch {
+		case strings.HasPrefix(record.EventName, "ObjectCreated:"):
+			eventType = ImportEventCreated
+		case strings.HasPrefix(record.EventName, "ObjectRemoved:"):
+			eventType = ImportEventRemoved
+		default:
+			continue
+		}
+		// object keys are URL encoded in event notifications
+		key, err := url.QueryUnescape(record.S3.Object.Key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %s: %s", ErrInvalidImportEvent, record.S3.Object.Key, err)
+		}
+		events = append(events, ImportEvent{
+			Type:   eventType,
+			Bucket: record.S3.Bucket.Name,
+			Key:    key,
+			Size:   record.S3.Object.Size,
+			ETag:   record.S3.Object.ETag,
+			Time:   record.EventTime,
+		})
+	}
+	return events, nil
+}
+
+// ContinuousImportFeed imports the objects created and removed under
+// Source, as reported by Events, into Branch of Repository under
+// Destination.
+type ContinuousImportFeed struct {
+	Repository  string
+	Branch      string
+	Source      string
+	Destination string
+	Events      ImportEventSource
+}
+
+type continuousImportFeed struct {
+	ContinuousImportFeed
+	scheme string
+	bucket string
+	prefix string
+}
+
+// address returns the full address of the object of event
+func (f *continuousImportFeed) address(event ImportEvent) string {
+	return fmt.Sprintf("%s://%s/%s", f.scheme, event.Bucket, event.Key)
+}
+
+// path returns the path of the object of event on the branch, and false
+// if event is not under the source of the feed.
+func (f *continuousImportFeed) path(event ImportEvent) (string, bool) {
+	if event.Bucket != f.bucket || !strings.HasPrefix(event.Key, f.prefix) {
+		return "", false
+	}
+	return f.Destination + strings.TrimPrefix(event.Key, f.prefix), true
+}
+
+func (f *continuousImportFeed) cursorPath() []byte {
+	return []byte(kv.FormatPath(continuousImportsPrefix, f.Branch, f.Destination))
+}
+
+// continuousImporter periodically commits the events of each feed to its branch
+type continuousImporter struct {
+	catalog   *Catalog
+	feeds     []*continuousImportFeed
+	interval  time.Duration
+	maxEvents int
+}
+
+func newContinuousImporter(c *Catalog, feeds []ContinuousImportFeed, interval time.Duration, maxEvents int) (*continuousImporter, error) {
+	if maxEvents <= 0 {
+		return nil, fmt.Errorf("max events per commit %d: %w", maxEvents, graveler.ErrInvalidValue)
+	}
+	ci := &continuousImporter{
+		catalog:   c,
+		interval:  interval,
+		maxEvents: maxEvents,
+	}
+	for _, feed := range feeds {
+		if feed.Repository == "" || feed.Branch == "" || feed.Source == "" || feed.Events == nil {
+			return nil, fmt.Errorf("continuous import feed: %w", graveler.ErrRequiredValue)
+		}
+		u, err := url.Parse(feed.Source)
+		if err != nil || u.Scheme == "" {
+			return nil, fmt.Errorf("continuous import source %s: %w", feed.Source, graveler.ErrInvalidValue)
+		}
+		ci.feeds = append(ci.feeds, &continuousImportFeed{
+			ContinuousImportFeed: feed,
+			scheme:               u.Scheme,
+			bucket:               u.Host,
+			prefix:               strings.TrimPrefix(u.Path, "/"),
+		})
+	}
+	return ci, nil
+}
+
+func (ci *continuousImporter) log(ctx context.Context) logging.Logger {
+	return logging.FromContext(ctx).WithField("service_name", "continuous_import")
+}
+
+// Run imports the events of all feeds every interval, until ctx is done.
+func (ci *continuousImporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(ci.interval)
+	defer ticker.Stop()
+	for {
+		for _, feed := range ci.feeds {
+			if err := ci.importFeed(ctx, feed); err != nil && ctx.Err() == nil {
+				ci.log(ctx).WithError(err).WithFields(logging.Fields{
+					"repository": feed.Repository,
+					"branch":     feed.Branch,
+					"source":     feed.Source,
+				}).Error("Continuous import failed")
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// importFeed commits all the events of feed that follow its cursor, up to
+// maxEvents events per commit.
+func (ci *continuousImporter) importFeed(ctx context.Context, feed *continuousImportFeed) error {
+	repository, err := ci.catalog.getRepository(ctx, feed.Repository)
+	if err != nil {
+		return err
+	}
+	cursor, err := ci.loadCursor(ctx, repository, feed)
+	if err != nil {
+		return fmt.Errorf("load cursor: %w", err)
+	}
+	for {
+		events, next, err := feed.Events.ReadEvents(ctx, cursor, ci.maxEvents)
+		if err != nil {
+			return fmt.Errorf("read events: %w", err)
+		}
+		if next == cursor {
+			return nil
+		}
+		if err := ci.commitEvents(ctx, repository, feed, events, next); err != nil {
+			return err
+		}
+		if err := ci.saveCursor(ctx, repository, feed, next); err != nil {
+			return fmt.Errorf("save cursor: %w", err)
+		}
+		cursor = next
+	}
+}
+
+// loadCursor returns the cursor of feed.  A cursor committed to the branch
+// that is newer than the saved one, e.g. when lakeFS stopped after
+// committing events but before saving their cursor, is used and saved.
+// Other commits may follow that commit, so the committed cursor is that of
+// the latest commit of feed among the continuousImportCursorSearchDepth
+// latest first-parent commits of the branch.
+func (ci *continuousImporter) loadCursor(ctx context.Context, repository *graveler.RepositoryRecord, feed *continuousImportFeed) (string, error) {
+	var cursor string
+	data := &wrapperspb.StringValue{}
+	_, err := kv.GetMsg(ctx, ci.catalog.KVStore, graveler.RepoPartition(repository), feed.cursorPath(), data)
+	switch {
+	case err == nil:
+		cursor = data.GetValue()
+	case !errors.Is(err, kv.ErrNotFound):
+		return "", err
+	}
+
+	committed, err := ci.committedCursor(ctx, repository, feed)
+	if err != nil {
+		return "", err
+	}
+	if committed > cursor {
+		cursor = committed
+		if err := ci.saveCursor(ctx, repository, feed, cursor); err != nil {
+			return "", err
+		}
+	}
+	return cursor, nil
+}
+
+// committedCursor returns the cursor of the latest commit of feed on its
+// branch, or "" if there is none among the latest commits.
+func (ci *continuousImporter) committedCursor(ctx context.Context, repository *graveler.RepositoryRecord, feed *continuousImportFeed) (string, error) {
+	branch, err := ci.catalog.Store.GetBranch(ctx, repository, graveler.BranchID(feed.Branch))
+	if err != nil {
+		return "", err
+	}
+	it, err := ci.catalog.Store.Log(ctx, repository, branch.CommitID, true)
+	if err != nil {
+		return "", err
+	}
+	defer it.Close()
+	for i := 0; i < continuousImportCursorSearchDepth && it.Next(); i++ {
+		commit := it.Value()
+		if commit.Metadata[ContinuousImportSourceMetadataKey] == feed.Source {
+			return commit.Metadata[ContinuousImportCursorMetadataKey], nil
+		}
+	}
+	return "", it.Err()
+}
+
+func (ci *continuousImporter) saveCursor(ctx context.Context, repository *graveler.RepositoryRecord, feed *continuousImportFeed, cursor string) error {
+	return kv.SetMsg(ctx, ci.catalog.KVStore, graveler.RepoPartition(repository), feed.cursorPath(), wrapperspb.String(cursor))
+}
+
+// commitEvents imports events to the branch of feed, scoped to the paths
+// of their objects.  Objects on the branch whose paths extend those paths
+// are kept.
+func (ci *continuousImporter) commitEvents(ctx context.Context, repository *graveler.RepositoryRecord, feed *continuousImportFeed, events []ImportEvent, cursor string) error {
+	// the last event of each path wins
+	latest := make(map[string]ImportEvent)
+	for _, event := range events {
+		p, ok := feed.path(event)
+		if !ok || p == "" {
+			continue
+		}
+		latest[p] = event
+	}
+	if len(latest) == 0 {
+		return nil
+	}
+	paths := make([]string, 0, len(latest))
+	for p := range latest {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	// scope the import to the shortest paths, each of which covers all paths it prefixes
+	var prefixes []graveler.Prefix
+	for _, p := range paths {
+		if len(prefixes) > 0 && strings.HasPrefix(p, string(prefixes[len(prefixes)-1])) {
+			continue
+		}
+		prefixes = append(prefixes, graveler.Prefix(p))
+	}
+
+	branch, err := ci.catalog.Store.GetBranch(ctx, repository, graveler.BranchID(feed.Branch))
+	if err != nil {
+		return err
+	}
+	records, err := ci.keptRecords(ctx, repository, branch.CommitID, prefixes, latest)
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		event := latest[p]
+		if event.Type != ImportEventCreated {
+			continue
+		}
+		value, err := EntryToValue(&Entry{
+			Address:      feed.address(event),
+			AddressType:  Entry_FULL,
+			LastModified: timestamppb.New(event.Time),
+			Size:         event.Size,
+			ETag:         event.ETag,
+		})
+		if err != nil {
+			return err
+		}
+		records = append(records, &graveler.ValueRecord{Key: graveler.Key(p), Value: value})
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Key.String() < records[j].Key.String()
+	})
+
+	metaRangeID, err := ci.catalog.Store.WriteMetaRangeByIterator(ctx, repository, &valueRecordsIterator{records: records})
+	if err != nil {
+		return fmt.Errorf("write metarange: %w", err)
+	}
+	_, err = ci.catalog.Store.Import(ctx, repository, graveler.BranchID(feed.Branch), *metaRangeID, graveler.CommitParams{
+		Committer: ContinuousImportCommitter,
+		Message:   fmt.Sprintf("Continuous import of %d objects from %s", len(latest), feed.Source),
+		Metadata: map[string]string{
+			ContinuousImportSourceMetadataKey: feed.Source,
+			ContinuousImportCursorMetadataKey: cursor,
+		},
+	}, prefixes)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	continuousImportEventsCounter.WithLabelValues(feed.Repository, feed.Branch).Add(float64(len(latest)))
+	return nil
+}
+
+// keptRecords returns the records of commitID under prefixes that have no event in latest
+func (ci *continuousImporter) keptRecords(ctx context.Context, repository *graveler.RepositoryRecord, commitID graveler.CommitID, prefixes []graveler.Prefix, latest map[string]ImportEvent) ([]*graveler.ValueRecord, error) {
+	it, err := ci.catalog.Store.List(ctx, repository, graveler.Ref(commitID), continuousImportListBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var records []*graveler.ValueRecord
+	for _, prefix := range prefixes {
+		it.SeekGE(graveler.Key(prefix))
+		for it.Next() {
+			record := it.Value()
+			key := record.Key.String()
+			if !strings.HasPrefix(key, string(prefix)) {
+				break
+			}
+			if _, ok := latest[key]; ok {
+				continue
+			}
+			records = append(records, &graveler.ValueRecord{Key: record.Key.Copy(), Value: record.Value})
+		}
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// valueRecordsIterator iterates over sorted value records
+type valueRecordsIterator struct {
+	records []*graveler.ValueRecord
+	idx     int
+}
+
+func (it *valueRecordsIterator) Next() bool {
+	if it.idx >= len(it.records) {
+		return false
+	}
+	it.idx++
+	return true
+}
+
+func (it *valueRecordsIterator) SeekGE(id graveler.Key) {
+	it.idx = sort.Search(len(it.records), func(i int) bool {
+		return it.records[i].Key.String() >= id.String()
+	})
+}
+
+func (it *valueRecordsIterator) Value() *graveler.ValueRecord {
+	if it.idx == 0 || it.idx > len(it.records) {
+		return nil
+	}
+	return it.records[it.idx-1]
+}
+
+func (it *valueRecordsIterator) Err() error {
+	return nil
+}
+
+func (it *valueRecordsIterator) Close() {}
@@ -0,0 +1,136 @@
+package catalog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const testS3Events = `{
+  "Records": [
+    {
+      "eventName": "ObjectCreated:Put",
+      "eventTime": "2023-01-02T03:04:05.000Z",
+      "s3": {"bucket": {"name": "bucket"}, "object": {"key": "prefix/a+file%3D1", "size": 10, "eTag": "abc"}}
+    },
+    {
+      "eventName": "s3:TestEvent"
+    },
+    {
+      "eventName": "ObjectRemoved:Delete",
+      "eventTime": "2023-01-02T03:04:06.000Z",
+      "s3": {"bucket": {"name": "bucket"}, "object": {"key": "prefix/b"}}
+    }
+  ]
+}`
+
+func TestParseS3Events(t *testing.T) {
+	events, err := ParseS3Events([]byte(testS3Events))
+	require.NoError(t, err)
+	require.Equal(t, []ImportEvent{
+		{
+			Type:   ImportEventCreated,
+			Bucket: "bucket",
+			Key:    "prefix/a file=1",
+			Size:   10,
+			ETag:   "abc",
+			Time:   time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			Type:   ImportEventRemoved,
+			Bucket: "bucket",
+			Key:    "prefix/b",
+			Time:   time.Date(2023, 1, 2, 3, 4, 6, 0, time.UTC),
+		},
+	}, events)
+
+	_, err = ParseS3Events([]byte("not json"))
+	require.ErrorIs(t, err, ErrInvalidImportEvent)
+}
+
+func TestDirectoryEventSource(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	for _, name := range []string{"001.json", "002.json", "003.json", ".004.json"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(testS3Events), 0o600))
+	}
+	source := NewDirectoryEventSource(dir)
+
+	events, cursor, err := source.ReadEvents(ctx, "", 3)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	require.Equal(t, "002.json", cursor)
+
+	events, cursor, err = source.ReadEvents(ctx, cursor, 3)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, "003.json", cursor)
+
+	events, cursor, err = source.ReadEvents(ctx, cursor, 3)
+	require.NoError(t, err)
+	require.Empty(t, events)
+	require.Equal(t, "003.json", cursor, "hidden files should not be read")
+}
+
+// cursorEventSource records the cursors it reads events after, and has no events
+type cursorEventSource struct {
+	cursors []string
+}
+
+func (s *cursorEventSource) ReadEvents(_ context.Context, cursor string, _ int) ([]ImportEvent, string, error) {
+	s.cursors = append(s.cursors, cursor)
+	return nil, cursor, nil
+}
+
+func TestContinuousImporter_RecoverCommittedCursor(t *testing.T) {
+	ctx := context.Background()
+	c := newReplicationTestCatalog(t)
+	events := &cursorEventSource{}
+	ci, err := newContinuousImporter(c, []ContinuousImportFeed{{
+		Repository:  replicationSourceRepository,
+		Branch:      "main",
+		Source:      "s3://bucket/prefix/",
+		Destination: "imported/",
+		Events:      events,
+	}}, time.Minute, 10)
+	require.NoError(t, err)
+	feed := ci.feeds[0]
+	repository, err := c.getRepository(ctx, replicationSourceRepository)
+	require.NoError(t, err)
+
+	cursor, err := ci.loadCursor(ctx, repository, feed)
+	require.NoError(t, err)
+	require.Empty(t, cursor)
+
+	// lakeFS stops after committing events but before saving their cursor,
+	// and then the branch is committed to
+	err = ci.commitEvents(ctx, repository, feed, []ImportEvent{
+		{Type: ImportEventCreated, Bucket: "bucket", Key: "prefix/a", Size: 1, Time: time.Now()},
+	}, "002.json")
+	require.NoError(t, err)
+	commitObject(t, c, "main", "other")
+
+	// the next import resumes after the committed cursor, and saves it
+	require.NoError(t, ci.importFeed(ctx, feed))
+	require.Equal(t, []string{"002.json"}, events.cursors)
+	saved := &wrapperspb.StringValue{}
+	_, err = kv.GetMsg(ctx, c.KVStore, graveler.RepoPartition(repository), feed.cursorPath(), saved)
+	require.NoError(t, err)
+	require.Equal(t, "002.json", saved.GetValue())
+	entry, err := c.GetEntry(ctx, replicationSourceRepository, "main", "imported/a", GetEntryParams{})
+	require.NoError(t, err)
+	require.Equal(t, "s3://bucket/prefix/a", entry.PhysicalAddress)
+
+	// a saved cursor newer than the committed one is kept
+	require.NoError(t, ci.saveCursor(ctx, repository, feed, "003.json"))
+	cursor, err = ci.loadCursor(ctx, repository, feed)
+	require.NoError(t, err)
+	require.Equal(t, "003.json", cursor)
+}
@@ -0,0 +1,89 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/compress"
+	"github.com/treeverse/lakefs/pkg/block/mem"
+)
+
+func TestCopyBlockObject(t *testing.T) {
+	const content = "0123456789abcdefghijklmnopqrstuvwxyz"
+	cases := []struct {
+		name     string
+		maxSize  int64
+		partSize int64
+	}{
+		{name: "single", maxSize: 100, partSize: 10},
+		{name: "parts", maxSize: 10, partSize: 10},
+		{name: "exact_parts", maxSize: 10, partSize: 12},
+		{name: "one_part", maxSize: 10, partSize: 100},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			adapter := mem.New(ctx)
+			src := block.ObjectPointer{
+				StorageNamespace: "mem://bucket",
+				Identifier:       "src",
+				IdentifierType:   block.IdentifierTypeRelative,
+			}
+			dst := block.ObjectPointer{
+				StorageNamespace: "mem://bucket",
+				Identifier:       "dst",
+				IdentifierType:   block.IdentifierTypeRelative,
+			}
+			err := adapter.Put(ctx, src, int64(len(content)), strings.NewReader(content), block.PutOpts{})
+			require.NoError(t, err)
+
+			metadata, err := copyBlockObject(ctx, adapter, src, dst, Metadata{"key": "value"}, int64(len(content)), tt.maxSize, tt.partSize)
+			require.NoError(t, err)
+			require.Equal(t, map[string]string{"key": "value"}, metadata)
+
+			reader, err := adapter.Get(ctx, dst, int64(len(content)))
+			require.NoError(t, err)
+			defer func() { _ = reader.Close() }()
+			copied, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			require.Equal(t, content, string(copied))
+		})
+	}
+}
+
+func TestCopyBlockObject_Compressed(t *testing.T) {
+	// uncompressed content that starts with the segment magic
+	const content = "LKFSCMP1 0123456789abcdefghijklmnopqrstuvwxyz"
+	ctx := context.Background()
+	adapter := compress.NewAdapter(mem.New(ctx))
+	src := block.ObjectPointer{
+		StorageNamespace: "mem://bucket",
+		Identifier:       "src",
+		IdentifierType:   block.IdentifierTypeRelative,
+	}
+	err := adapter.Put(ctx, src, int64(len(content)), strings.NewReader(content), block.PutOpts{})
+	require.NoError(t, err)
+	srcMetadata := compress.EntryMetadata(ctx, adapter, nil, int64(len(content)))
+
+	for _, maxSize := range []int64{100, 10} {
+		dst := block.ObjectPointer{
+			StorageNamespace: "mem://bucket",
+			Identifier:       fmt.Sprintf("dst_%d", maxSize),
+			IdentifierType:   block.IdentifierTypeRelative,
+		}
+		metadata, err := copyBlockObject(ctx, adapter, src, dst, srcMetadata, int64(len(content)), maxSize, 10)
+		require.NoError(t, err)
+
+		reader, err := adapter.Get(compress.WithEntry(ctx, metadata), dst, int64(len(content)))
+		require.NoError(t, err)
+		copied, err := io.ReadAll(reader)
+		_ = reader.Close()
+		require.NoError(t, err)
+		require.Equal(t, content, string(copied))
+	}
+}
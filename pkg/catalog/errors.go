@@ -17,4 +17,13 @@ var (
 	ErrItClosed = errors.New("iterator closed")
 
 	ErrFeatureNotSupported = errors.New("feature not supported")
+
+	ErrReplicationNotConfigured = fmt.Errorf("replication %w", graveler.ErrNotFound)
+
+	ErrRepositoryNotBare = fmt.Errorf("repository is not bare: %w", graveler.ErrInvalidValue)
+	ErrInvalidArchive    = fmt.Errorf("invalid repository archive: %w", graveler.ErrInvalidValue)
+
+	ErrInvalidImportEvent = errors.New("invalid import event")
+
+	ErrObjectTooLarge = fmt.Errorf("object too large: %w", graveler.ErrInvalidValue)
 )
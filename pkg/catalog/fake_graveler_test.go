@@ -19,6 +19,7 @@ type FakeGraveler struct {
 	BranchIteratorFactory       func() graveler.BranchIterator
 	TagIteratorFactory          func() graveler.TagIterator
 	AddressTokenIteratorFactory func() graveler.AddressTokenIterator
+	RepositoryMetadata          map[graveler.RepositoryID]graveler.RepositoryMetadata
 	hooks                       graveler.HooksHandler
 }
 
@@ -60,6 +61,10 @@ func (g *FakeGraveler) CreateBareRepository(_ context.Context, _ graveler.Reposi
 	panic("implement me")
 }
 
+func (g *FakeGraveler) CreateRepositoryFromTemplate(_ context.Context, _ graveler.RepositoryID, _ graveler.StorageNamespace, _ graveler.BranchID, _ *graveler.RepositoryRecord, _ graveler.CommitID, _ graveler.CommitParams, _ graveler.ValueRewriteFunc) (*graveler.RepositoryRecord, error) {
+	panic("implement me")
+}
+
 func (g *FakeGraveler) LoadCommits(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.MetaRangeID) error {
 	panic("implement me")
 }
@@ -72,18 +77,34 @@ func (g *FakeGraveler) LoadTags(_ context.Context, _ *graveler.RepositoryRecord,
 	panic("implement me")
 }
 
+func (g *FakeGraveler) UnloadTag(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.TagID) error {
+	panic("implement me")
+}
+
 func (g *FakeGraveler) DumpCommits(_ context.Context, _ *graveler.RepositoryRecord) (*graveler.MetaRangeID, error) {
 	panic("implement me")
 }
 
+func (g *FakeGraveler) DumpCommitRecords(_ context.Context, _ *graveler.RepositoryRecord, _ []*graveler.CommitRecord) (*graveler.MetaRangeID, error) {
+	panic("implement me")
+}
+
 func (g *FakeGraveler) DumpBranches(_ context.Context, _ *graveler.RepositoryRecord) (*graveler.MetaRangeID, error) {
 	panic("implement me")
 }
 
+func (g *FakeGraveler) DumpBranchRecords(_ context.Context, _ *graveler.RepositoryRecord, _ []*graveler.BranchRecord) (*graveler.MetaRangeID, error) {
+	panic("implement me")
+}
+
 func (g *FakeGraveler) DumpTags(_ context.Context, _ *graveler.RepositoryRecord) (*graveler.MetaRangeID, error) {
 	panic("implement me")
 }
 
+func (g *FakeGraveler) DumpTagRecords(_ context.Context, _ *graveler.RepositoryRecord, _ []*graveler.TagRecord) (*graveler.MetaRangeID, error) {
+	panic("implement me")
+}
+
 func (g *FakeGraveler) GetMetaRange(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.MetaRangeID) (graveler.MetaRangeAddress, error) {
 	panic("implement me")
 }
@@ -92,6 +113,10 @@ func (g *FakeGraveler) GetRange(_ context.Context, _ *graveler.RepositoryRecord,
 	panic("implement me")
 }
 
+func (g *FakeGraveler) ListRanges(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.MetaRangeID) ([]graveler.RangeID, error) {
+	panic("implement me")
+}
+
 func fakeGravelerBuildKey(repositoryID graveler.RepositoryID, ref graveler.Ref, key graveler.Key) string {
 	return strings.Join([]string{repositoryID.String(), ref.String(), key.String()}, "/")
 }
@@ -147,6 +172,14 @@ func (g *FakeGraveler) GetRepository(ctx context.Context, repositoryID graveler.
 	return &graveler.RepositoryRecord{RepositoryID: repositoryID}, nil
 }
 
+func (g *FakeGraveler) GetRepositoryMetadata(_ context.Context, repositoryID graveler.RepositoryID) (graveler.RepositoryMetadata, error) {
+	return g.RepositoryMetadata[repositoryID], nil
+}
+
+func (g *FakeGraveler) GetRepositoryAccessState(_ context.Context, repository *graveler.RepositoryRecord) (graveler.RepositoryAccessState, error) {
+	return graveler.AccessStateFromMetadata(g.RepositoryMetadata[repository.RepositoryID]), nil
+}
+
 func (g *FakeGraveler) CreateRepository(ctx context.Context, repositoryID graveler.RepositoryID, storageNamespace graveler.StorageNamespace, branchID graveler.BranchID) (*graveler.RepositoryRecord, error) {
 	panic("implement me")
 }
@@ -228,6 +261,14 @@ func (g *FakeGraveler) Commit(ctx context.Context, repository *graveler.Reposito
 	panic("implement me")
 }
 
+func (g *FakeGraveler) CommitChanges(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ graveler.CommitID, _ graveler.ValueIterator, _ graveler.CommitParams) (graveler.CommitID, error) {
+	panic("implement me")
+}
+
+func (g *FakeGraveler) CommitBranches(_ context.Context, _ []graveler.BranchCommit) ([]graveler.CommitID, error) {
+	panic("implement me")
+}
+
 func (g *FakeGraveler) WriteRange(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.ValueIterator) (*graveler.RangeInfo, error) {
 	panic("implement me")
 }
@@ -305,6 +346,10 @@ func (g *FakeGraveler) WriteMetaRangeByIterator(_ context.Context, _ *graveler.R
 	panic("implement me")
 }
 
+func (g *FakeGraveler) ApplyMetaRangeChanges(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.MetaRangeID, _ graveler.ValueIterator) (*graveler.MetaRangeID, error) {
+	panic("implement me")
+}
+
 func (g *FakeGraveler) GetStagingToken(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID) (*graveler.StagingToken, error) {
 	panic("implement me")
 }
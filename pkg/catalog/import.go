@@ -91,7 +91,7 @@ func (i *Import) set(record EntryRecord) error {
 	})
 }
 
-func (i *Import) Ingest(it *walkEntryIterator) error {
+func (i *Import) Ingest(it EntryIterator) error {
 	if i.Closed() {
 		return ErrImportClosed
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 
+	"github.com/treeverse/lakefs/pkg/block/compress"
 	"github.com/treeverse/lakefs/pkg/graveler"
 )
 
@@ -31,6 +32,18 @@ type CherryPickParams struct {
 	Committer    string
 }
 
+type SquashParams struct {
+	Reference string // the commit after which commits are squashed
+	Message   string // message of the squash commit, combined from the squashed commits if empty
+	Metadata  Metadata
+	Committer string
+}
+
+type RewriteParams struct {
+	Exclude   []string // the commits to remove from the branch history
+	Committer string
+}
+
 type PathRecord struct {
 	Path     Path
 	IsPrefix bool
@@ -83,6 +96,12 @@ type Interface interface {
 	// defaultBranchID will point to a non-existent branch on creation, it is up to the caller to eventually create it.
 	CreateBareRepository(ctx context.Context, repository string, storageNamespace string, defaultBranchID string) (*Repository, error)
 
+	// CreateRepositoryFromTemplate create a new repository pointing to 'storageNamespace' whose default branch 'branch' starts
+	// at a single commit holding the content of 'templateRef' in 'templateRepository', and copies its branch protection and
+	// garbage collection rules. Objects in the template storage namespace are copied into 'storageNamespace' before the
+	// repository is created.
+	CreateRepositoryFromTemplate(ctx context.Context, repository string, storageNamespace string, branch string, templateRepository string, templateRef string, committer string) (*Repository, error)
+
 	// GetRepository get repository information
 	GetRepository(ctx context.Context, repository string) (*Repository, error)
 
@@ -92,9 +111,21 @@ type Interface interface {
 	// GetRepositoryMetadata get repository metadata
 	GetRepositoryMetadata(ctx context.Context, repository string) (graveler.RepositoryMetadata, error)
 
+	// SetRepositoryCompression sets the codec that compresses data written to repository
+	SetRepositoryCompression(ctx context.Context, repository string, codec compress.Codec) error
+
+	// GetRepositoryCompression returns the codec that compresses data written to repository
+	GetRepositoryCompression(ctx context.Context, repository string) (compress.Codec, error)
+
+	// SetRepositoryAccessState sets the access state of repository: read-only and archived repositories reject all changes
+	SetRepositoryAccessState(ctx context.Context, repository string, state graveler.RepositoryAccessState) error
+
+	// GetRepositoryAccessState returns the access state of repository
+	GetRepositoryAccessState(ctx context.Context, repository string) (graveler.RepositoryAccessState, error)
+
 	// ListRepositories list repository information, the bool returned is true when more repositories can be listed.
 	// In this case pass the last repository name as 'after' on the next call to ListRepositories
-	ListRepositories(ctx context.Context, limit int, prefix, after string) ([]*Repository, bool, error)
+	ListRepositories(ctx context.Context, limit int, prefix, after string, includeArchived bool) ([]*Repository, bool, error)
 
 	GetStagingToken(ctx context.Context, repository string, branch string) (*string, error)
 
@@ -104,6 +135,7 @@ type Interface interface {
 	BranchExists(ctx context.Context, repository string, branch string) (bool, error)
 	GetBranchReference(ctx context.Context, repository, branch string) (string, error)
 	ResetBranch(ctx context.Context, repository, branch string) error
+	ListBranchReflog(ctx context.Context, repository, branch string, limit int, after string) ([]*ReflogEntry, bool, error)
 
 	CreateTag(ctx context.Context, repository, tagID string, ref string) (string, error)
 	DeleteTag(ctx context.Context, repository, tagID string) error
@@ -122,8 +154,21 @@ type Interface interface {
 	CopyEntry(ctx context.Context, srcRepository, srcRef, srcPath, destRepository, destBranch, destPath string) (*DBEntry, error)
 
 	Commit(ctx context.Context, repository, branch, message, committer string, metadata Metadata, date *int64, sourceMetarange *string) (*CommitLog, error)
+	// CommitChanges commits changes on top of commit parent, which must be the head of branch.  Changes staged on
+	// the branch are committed only if they are included in changes, and are never changed.  It fails with graveler.ErrBranchHeadMoved if the head of the
+	// branch is no longer parent.
+	CommitChanges(ctx context.Context, repository, branch, parent string, changes []EntryChange, message, committer string, metadata Metadata) (*CommitLog, error)
+	// CommitBranches commits multiple branches, possibly of different repositories, atomically.  It returns the
+	// commit logs in the same order as commits.
+	CommitBranches(ctx context.Context, committer string, commits []BranchCommit) ([]*CommitLog, error)
 	GetCommit(ctx context.Context, repository, reference string) (*CommitLog, error)
 	ListCommits(ctx context.Context, repository, branch string, params LogParams) ([]*CommitLog, bool, error)
+	// SignCommit stores a signature of commitID.  The caller verifies the signature against the committer's keys.
+	SignCommit(ctx context.Context, repository, commitID string, signature CommitSignature) error
+	// GetCommitSignature returns the signature of commitID, or graveler.ErrCommitSignatureNotFound
+	GetCommitSignature(ctx context.Context, repository, commitID string) (*CommitSignature, error)
+	// BlameObject attributes each line (or CSV row) of the object at path on reference to the commit that introduced it
+	BlameObject(ctx context.Context, repository, reference, path, format string) ([]*BlameLine, error)
 
 	// Revert creates a reverse patch to the given commit, and applies it as a new commit on the given branch.
 	Revert(ctx context.Context, repository, branch string, params RevertParams) error
@@ -131,9 +176,17 @@ type Interface interface {
 	// CherryPick creates a patch to the given commit, and applies it as a new commit on the given branch.
 	CherryPick(ctx context.Context, repository, branch string, params CherryPickParams) (*CommitLog, error)
 
+	// Squash replaces the commits on the given branch after the given commit with a single commit.
+	Squash(ctx context.Context, repository, branch string, params SquashParams) (*CommitLog, error)
+
+	// Rewrite rebuilds the history of the given branch without the given commits.
+	Rewrite(ctx context.Context, repository, branch string, params RewriteParams) (*CommitLog, error)
+
 	Diff(ctx context.Context, repository, leftReference string, rightReference string, params DiffParams) (Differences, bool, error)
 	Compare(ctx context.Context, repository, leftReference string, rightReference string, params DiffParams) (Differences, bool, error)
 	DiffUncommitted(ctx context.Context, repository, branch, prefix, delimiter string, limit int, after string) (Differences, bool, error)
+	// DiffObjectContent compares the contents of the object at path on two references
+	DiffObjectContent(ctx context.Context, repository, leftReference, rightReference, path string) (*ObjectContentDiff, error)
 
 	Merge(ctx context.Context, repository, destinationBranch, sourceRef, committer, message string, metadata Metadata, strategy string) (string, error)
 	FindMergeBase(ctx context.Context, repositoryID string, destinationRef string, sourceRef string) (string, string, string, error)
@@ -146,6 +199,14 @@ type Interface interface {
 	LoadBranches(ctx context.Context, repositoryID, branchesMetaRangeID string) error
 	LoadTags(ctx context.Context, repositoryID, tagsMetaRangeID string) error
 
+	// ExportRepositoryArchive writes a self-contained tar archive of repositoryID to w
+	ExportRepositoryArchive(ctx context.Context, repositoryID string, includeObjects bool, w io.Writer) error
+	// ImportRepositoryArchive loads a tar archive written by ExportRepositoryArchive into the bare repositoryID
+	ImportRepositoryArchive(ctx context.Context, repositoryID string, r io.Reader) (*ArchiveManifest, error)
+
+	// GetReplicationStatus returns the replication status of the replicated branches of repositoryID
+	GetReplicationStatus(ctx context.Context, repositoryID string) ([]*ReplicationStatus, error)
+
 	// forward metadata for thick clients
 	GetMetaRange(ctx context.Context, repositoryID, metaRangeID string) (graveler.MetaRangeAddress, error)
 	GetRange(ctx context.Context, repositoryID, rangeID string) (graveler.RangeAddress, error)
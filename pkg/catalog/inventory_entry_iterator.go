@@ -0,0 +1,75 @@
+package catalog
+
+import (
+	"strings"
+
+	"github.com/treeverse/lakefs/pkg/block"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// inventoryEntryIterator iterates over the objects of an inventory under a
+// source path, as entries under a destination path.
+type inventoryEntryIterator struct {
+	it      block.InventoryIterator
+	source  string
+	prepend string
+	value   *EntryRecord
+	err     error
+}
+
+func newInventoryEntryIterator(it block.InventoryIterator, source, destination string) *inventoryEntryIterator {
+	prepend := destination
+	if prepend != "" && !strings.HasSuffix(prepend, "/") {
+		prepend += "/"
+	}
+	return &inventoryEntryIterator{
+		it:      it,
+		source:  source,
+		prepend: prepend,
+	}
+}
+
+func (i *inventoryEntryIterator) Next() bool {
+	if i.err != nil {
+		return false
+	}
+	for i.it.Next() {
+		obj := i.it.Get()
+		if !strings.HasPrefix(obj.PhysicalAddress, i.source) {
+			continue
+		}
+		entry := &Entry{
+			Address:     obj.PhysicalAddress,
+			Size:        obj.Size,
+			ETag:        obj.Checksum,
+			AddressType: Entry_FULL,
+		}
+		if obj.LastModified != nil {
+			entry.LastModified = timestamppb.New(*obj.LastModified)
+		}
+		i.value = &EntryRecord{
+			Path:  Path(i.prepend + strings.TrimPrefix(obj.PhysicalAddress, i.source)),
+			Entry: entry,
+		}
+		return true
+	}
+	i.value = nil
+	return false
+}
+
+func (i *inventoryEntryIterator) SeekGE(Path) {
+	i.err = ErrFeatureNotSupported
+}
+
+func (i *inventoryEntryIterator) Value() *EntryRecord {
+	return i.value
+}
+
+func (i *inventoryEntryIterator) Err() error {
+	if i.err != nil {
+		return i.err
+	}
+	return i.it.Err()
+}
+
+func (i *inventoryEntryIterator) Close() {}
@@ -45,11 +45,43 @@ type CommitLog struct {
 	Parents      []string
 }
 
+// CommitSignature is a signature of a commit by one of its committer's public keys
+type CommitSignature struct {
+	KeyFingerprint string
+	Signature      []byte
+	CreationDate   time.Time
+}
+
+// BranchCommit is the commit of a single branch in a multi-branch commit
+type BranchCommit struct {
+	Repository string
+	Branch     string
+	Message    string
+	Metadata   Metadata
+	Date       *int64
+}
+
+// EntryChange is a change committed by CommitChanges: Entry is written to Path, or Path is deleted if Entry is nil
+type EntryChange struct {
+	Path  string
+	Entry *DBEntry
+}
+
 type Branch struct {
 	Name      string
 	Reference string
 }
 
+// ReflogEntry is a movement of the head of a branch
+type ReflogEntry struct {
+	ID           string
+	OldCommitID  string
+	NewCommitID  string
+	Operation    string
+	User         string
+	CreationDate time.Time
+}
+
 type Tag struct {
 	ID       string
 	CommitID string
@@ -0,0 +1,635 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gobwas/glob"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/compress"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+var replicationPendingCommitsGauge = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "catalog_replication_pending_commits",
+		Help: "Number of commits of a replicated branch not yet replicated to its target repository, as last seen by the replicator.",
+	},
+	[]string{"repository", "branch", "target_repository"},
+)
+
+const (
+	replicationQueueSize = 1000
+	replicationBatchSize = 1000
+)
+
+// ReplicationRule replicates the branches of SourceRepository that match
+// any of Branches into TargetRepository.
+type ReplicationRule struct {
+	SourceRepository string
+	Branches         []string
+	TargetRepository string
+}
+
+// ReplicationStatus is the replication state of a single branch.
+type ReplicationStatus struct {
+	Branch           string
+	TargetRepository string
+	// SourceCommitID is the head of the branch on the source repository.
+	SourceCommitID string
+	// TargetCommitID is the head of the branch on the target repository, empty if not replicated yet.
+	TargetCommitID string
+	// PendingCommits is the number of commits reachable from SourceCommitID missing on the target repository.
+	PendingCommits int
+	// Lag is the time since the oldest pending commit was created, zero when nothing is pending.
+	Lag              time.Duration
+	LastReplicatedAt *time.Time
+	LastError        string
+}
+
+type replicationRule struct {
+	ReplicationRule
+	branches []glob.Glob
+}
+
+func (r *replicationRule) matchBranch(branchID string) bool {
+	for _, g := range r.branches {
+		if g.Match(branchID) {
+			return true
+		}
+	}
+	return false
+}
+
+type replicationTask struct {
+	rule     *replicationRule
+	branchID graveler.BranchID
+}
+
+type replicationState struct {
+	lastReplicatedAt *time.Time
+	lastError        string
+}
+
+// replicator copies commits, branches and tags of source repositories, and
+// the objects they reference, into target repositories.  Target
+// repositories should be bare repositories, possibly on another
+// blockstore, that are written only by the replicator.  The replicator
+// loads refs without checking the access state, so making a target
+// read-only keeps everything else from writing to it.
+//
+// Branches are replicated after every commit and merge on them, and all
+// replicated branches are resynchronized every interval to catch up after
+// failures and restarts.  A single worker replicates one branch at a time.
+type replicator struct {
+	catalog  *Catalog
+	rules    []*replicationRule
+	interval time.Duration
+	queue    chan replicationTask
+
+	mu      sync.Mutex
+	pending map[replicationTask]struct{}
+	states  map[replicationTask]*replicationState
+}
+
+func newReplicator(c *Catalog, rules []ReplicationRule, interval time.Duration) (*replicator, error) {
+	r := &replicator{
+		catalog:  c,
+		interval: interval,
+		queue:    make(chan replicationTask, replicationQueueSize),
+		pending:  make(map[replicationTask]struct{}),
+		states:   make(map[replicationTask]*replicationState),
+	}
+	for _, rule := range rules {
+		if rule.SourceRepository == "" || rule.TargetRepository == "" || len(rule.Branches) == 0 {
+			return nil, fmt.Errorf("replication rule: %w", graveler.ErrRequiredValue)
+		}
+		if rule.SourceRepository == rule.TargetRepository {
+			return nil, fmt.Errorf("replication of %s into itself: %w", rule.SourceRepository, graveler.ErrInvalidValue)
+		}
+		rr := &replicationRule{ReplicationRule: rule}
+		for _, pattern := range rule.Branches {
+			g, err := glob.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("replication branch pattern %s: %w", pattern, err)
+			}
+			rr.branches = append(rr.branches, g)
+		}
+		r.rules = append(r.rules, rr)
+	}
+	return r, nil
+}
+
+func (r *replicator) log(ctx context.Context) logging.Logger {
+	return logging.FromContext(ctx).WithField("service_name", "replicator")
+}
+
+// Run replicates queued branches, and all replicated branches every interval, until ctx is done.
+func (r *replicator) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	r.enqueueAll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.enqueueAll(ctx)
+		case task := <-r.queue:
+			r.mu.Lock()
+			delete(r.pending, task)
+			r.mu.Unlock()
+			r.run(ctx, task)
+		}
+	}
+}
+
+// enqueue schedules replication of branchID of every rule of repositoryID
+// that matches it.  A task that is already pending is not scheduled again.
+func (r *replicator) enqueue(repositoryID graveler.RepositoryID, branchID graveler.BranchID) {
+	for _, rule := range r.rules {
+		if rule.SourceRepository != repositoryID.String() || !rule.matchBranch(branchID.String()) {
+			continue
+		}
+		task := replicationTask{rule: rule, branchID: branchID}
+		r.mu.Lock()
+		if _, ok := r.pending[task]; ok {
+			r.mu.Unlock()
+			continue
+		}
+		select {
+		case r.queue <- task:
+			r.pending[task] = struct{}{}
+		default:
+			// queue is full - the next periodic run will catch up
+		}
+		r.mu.Unlock()
+	}
+}
+
+func (r *replicator) enqueueAll(ctx context.Context) {
+	for _, rule := range r.rules {
+		repository, err := r.catalog.getRepository(ctx, rule.SourceRepository)
+		if err != nil {
+			r.log(ctx).WithError(err).WithField("repository", rule.SourceRepository).Error("Replication failed to get source repository")
+			continue
+		}
+		branches, err := r.catalog.Store.ListBranches(ctx, repository)
+		if err != nil {
+			r.log(ctx).WithError(err).WithField("repository", rule.SourceRepository).Error("Replication failed to list branches")
+			continue
+		}
+		for branches.Next() {
+			r.enqueue(repository.RepositoryID, branches.Value().BranchID)
+		}
+		if err := branches.Err(); err != nil {
+			r.log(ctx).WithError(err).WithField("repository", rule.SourceRepository).Error("Replication failed to list branches")
+		}
+		branches.Close()
+	}
+}
+
+func (r *replicator) run(ctx context.Context, task replicationTask) {
+	log := r.log(ctx).WithFields(logging.Fields{
+		"repository":        task.rule.SourceRepository,
+		"branch":            task.branchID,
+		"target_repository": task.rule.TargetRepository,
+	})
+	err := r.replicateBranch(ctx, task.rule, task.branchID)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.states[task]
+	if !ok {
+		state = &replicationState{}
+		r.states[task] = state
+	}
+	switch {
+	case errors.Is(err, graveler.ErrBranchNotFound):
+		// branch deleted on the source - nothing left to replicate
+		delete(r.states, task)
+	case err != nil:
+		state.lastError = err.Error()
+		log.WithError(err).Error("Replication failed")
+	default:
+		now := time.Now()
+		state.lastReplicatedAt = &now
+		state.lastError = ""
+	}
+}
+
+// replicateBranch copies the head of branchID on the source repository of
+// rule, together with all its missing ancestors, tags and objects, into the
+// target repository of rule.
+func (r *replicator) replicateBranch(ctx context.Context, rule *replicationRule, branchID graveler.BranchID) error {
+	source, err := r.catalog.getRepository(ctx, rule.SourceRepository)
+	if err != nil {
+		return fmt.Errorf("source repository: %w", err)
+	}
+	target, err := r.catalog.getRepository(ctx, rule.TargetRepository)
+	if err != nil {
+		return fmt.Errorf("target repository: %w", err)
+	}
+
+	branch, err := r.catalog.Store.GetBranch(ctx, source, branchID)
+	if err != nil {
+		return err
+	}
+	targetBranch, err := r.catalog.Store.GetBranch(ctx, target, branchID)
+	if err != nil && !errors.Is(err, graveler.ErrBranchNotFound) {
+		return fmt.Errorf("target branch: %w", err)
+	}
+	if targetBranch != nil && targetBranch.CommitID == branch.CommitID {
+		replicationPendingCommitsGauge.WithLabelValues(rule.SourceRepository, branchID.String(), rule.TargetRepository).Set(0)
+		return r.replicateTags(ctx, source, target)
+	}
+
+	commits, err := r.missingCommits(ctx, source, target, branch.CommitID)
+	if err != nil {
+		return err
+	}
+	replicationPendingCommitsGauge.WithLabelValues(rule.SourceRepository, branchID.String(), rule.TargetRepository).Set(float64(len(commits)))
+	if len(commits) > 0 {
+		for _, commit := range commits {
+			if err := r.copyCommitData(ctx, source, target, commit); err != nil {
+				return fmt.Errorf("commit %s: %w", commit.CommitID, err)
+			}
+		}
+		commitsMetaRangeID, err := r.catalog.Store.DumpCommitRecords(ctx, source, commits)
+		if err != nil {
+			return fmt.Errorf("dump commits: %w", err)
+		}
+		if err := r.copyMetaRange(ctx, source, target, *commitsMetaRangeID); err != nil {
+			return fmt.Errorf("copy commits: %w", err)
+		}
+		if err := r.catalog.Store.LoadCommits(ctx, target, *commitsMetaRangeID); err != nil {
+			return fmt.Errorf("load commits: %w", err)
+		}
+	}
+
+	branchesMetaRangeID, err := r.catalog.Store.DumpBranchRecords(ctx, source, []*graveler.BranchRecord{
+		{BranchID: branchID, Branch: &graveler.Branch{CommitID: branch.CommitID}},
+	})
+	if err != nil {
+		return fmt.Errorf("dump branch: %w", err)
+	}
+	if err := r.copyMetaRange(ctx, source, target, *branchesMetaRangeID); err != nil {
+		return fmt.Errorf("copy branch: %w", err)
+	}
+	if err := r.catalog.Store.LoadBranches(ctx, target, *branchesMetaRangeID); err != nil {
+		return fmt.Errorf("load branch: %w", err)
+	}
+	replicationPendingCommitsGauge.WithLabelValues(rule.SourceRepository, branchID.String(), rule.TargetRepository).Set(0)
+
+	if err := r.replicateTags(ctx, source, target); err != nil {
+		return fmt.Errorf("tags: %w", err)
+	}
+	return nil
+}
+
+// missingCommits returns the commits reachable from commitID on source that
+// are missing on target, parents before their children.
+func (r *replicator) missingCommits(ctx context.Context, source, target *graveler.RepositoryRecord, commitID graveler.CommitID) ([]*graveler.CommitRecord, error) {
+	var commits []*graveler.CommitRecord
+	visited := map[graveler.CommitID]struct{}{commitID: {}}
+	queue := []graveler.CommitID{commitID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		_, err := r.catalog.Store.GetCommit(ctx, target, id)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, graveler.ErrCommitNotFound) {
+			return nil, fmt.Errorf("get target commit %s: %w", id, err)
+		}
+		commit, err := r.catalog.Store.GetCommit(ctx, source, id)
+		if err != nil {
+			return nil, fmt.Errorf("get commit %s: %w", id, err)
+		}
+		commits = append(commits, &graveler.CommitRecord{CommitID: id, Commit: commit})
+		for _, parent := range commit.Parents {
+			if _, ok := visited[parent]; !ok {
+				visited[parent] = struct{}{}
+				queue = append(queue, parent)
+			}
+		}
+	}
+	// a commit generation is greater than the generations of its parents
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].Generation < commits[j].Generation
+	})
+	return commits, nil
+}
+
+// copyCommitData copies the objects added by commit relative to its first
+// parent, and then its metarange, into the storage namespace of target.
+// Objects with full addresses are not copied: the replica reads them from
+// their original location.
+func (r *replicator) copyCommitData(ctx context.Context, source, target *graveler.RepositoryRecord, commit *graveler.CommitRecord) error {
+	if commit.MetaRangeID == "" {
+		// empty commit, e.g. the initial commit of a repository
+		return nil
+	}
+	exists, err := r.storageExists(ctx, target, graveler.MetaRangeID(commit.MetaRangeID))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	copyValue := func(value *graveler.Value) error {
+		entry, err := ValueToEntry(value)
+		if err != nil {
+			return err
+		}
+		if entry.AddressType != Entry_RELATIVE {
+			return nil
+		}
+		// objects read and written across blockstores are stored as their entries record
+		return r.copyObject(compress.WithEntry(ctx, entry.Metadata),
+			block.ObjectPointer{StorageNamespace: source.StorageNamespace.String(), Identifier: entry.Address, IdentifierType: block.IdentifierTypeRelative},
+			block.ObjectPointer{StorageNamespace: target.StorageNamespace.String(), Identifier: entry.Address, IdentifierType: block.IdentifierTypeRelative},
+			entry.Size)
+	}
+
+	if len(commit.Parents) == 0 {
+		it, err := r.catalog.Store.List(ctx, source, graveler.Ref(commit.CommitID), replicationBatchSize)
+		if err != nil {
+			return err
+		}
+		defer it.Close()
+		for it.Next() {
+			if err := copyValue(it.Value().Value); err != nil {
+				return err
+			}
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+	} else {
+		it, err := r.catalog.Store.Diff(ctx, source, graveler.Ref(commit.Parents[0]), graveler.Ref(commit.CommitID))
+		if err != nil {
+			return err
+		}
+		defer it.Close()
+		for it.Next() {
+			diff := it.Value()
+			if diff.Type == graveler.DiffTypeRemoved {
+				continue
+			}
+			if err := copyValue(diff.Value); err != nil {
+				return err
+			}
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+	}
+	return r.copyMetaRange(ctx, source, target, commit.MetaRangeID)
+}
+
+// replicateTags creates on target the tags of source whose commits were
+// replicated, and deletes from target the tags deleted from source.
+func (r *replicator) replicateTags(ctx context.Context, source, target *graveler.RepositoryRecord) error {
+	sourceTags, err := r.listTags(ctx, source)
+	if err != nil {
+		return err
+	}
+	targetTags, err := r.listTags(ctx, target)
+	if err != nil {
+		return err
+	}
+	for tagID, commitID := range targetTags {
+		if sourceTags[tagID] == commitID {
+			continue
+		}
+		if err := r.catalog.Store.UnloadTag(ctx, target, tagID); err != nil && !errors.Is(err, graveler.ErrTagNotFound) {
+			return fmt.Errorf("delete tag %s: %w", tagID, err)
+		}
+	}
+	var tags []*graveler.TagRecord
+	for tagID, commitID := range sourceTags {
+		if targetTags[tagID] == commitID {
+			continue
+		}
+		_, err := r.catalog.Store.GetCommit(ctx, target, commitID)
+		if errors.Is(err, graveler.ErrCommitNotFound) {
+			// tagged commit is not on a replicated branch (yet)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		tags = append(tags, &graveler.TagRecord{TagID: tagID, CommitID: commitID})
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	tagsMetaRangeID, err := r.catalog.Store.DumpTagRecords(ctx, source, tags)
+	if err != nil {
+		return fmt.Errorf("dump tags: %w", err)
+	}
+	if err := r.copyMetaRange(ctx, source, target, *tagsMetaRangeID); err != nil {
+		return err
+	}
+	return r.catalog.Store.LoadTags(ctx, target, *tagsMetaRangeID)
+}
+
+func (r *replicator) listTags(ctx context.Context, repository *graveler.RepositoryRecord) (map[graveler.TagID]graveler.CommitID, error) {
+	it, err := r.catalog.Store.ListTags(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	tags := make(map[graveler.TagID]graveler.CommitID)
+	for it.Next() {
+		tag := it.Value()
+		tags[tag.TagID] = tag.CommitID
+	}
+	return tags, it.Err()
+}
+
+// storageExists returns true if the metarange metaRangeID is stored in the storage namespace of repository.
+func (r *replicator) storageExists(ctx context.Context, repository *graveler.RepositoryRecord, metaRangeID graveler.MetaRangeID) (bool, error) {
+	address, err := r.catalog.Store.GetMetaRange(ctx, repository, metaRangeID)
+	if err != nil {
+		return false, err
+	}
+	return r.catalog.BlockAdapter.Exists(ctx, block.ObjectPointer{
+		StorageNamespace: repository.StorageNamespace.String(),
+		Identifier:       string(address),
+		IdentifierType:   block.IdentifierTypeRelative,
+	})
+}
+
+// copyMetaRange copies metarange metaRangeID and its ranges from the
+// storage namespace of source to that of target, skipping files that
+// target already holds.  The metarange is copied last, so that a metarange
+// on target always has all its ranges.
+func (r *replicator) copyMetaRange(ctx context.Context, source, target *graveler.RepositoryRecord, metaRangeID graveler.MetaRangeID) error {
+	rangeIDs, err := r.catalog.Store.ListRanges(ctx, source, metaRangeID)
+	if err != nil {
+		return err
+	}
+	for _, rangeID := range rangeIDs {
+		address, err := r.catalog.Store.GetRange(ctx, source, rangeID)
+		if err != nil {
+			return err
+		}
+		if err := r.copyMissingFile(ctx, source, target, string(address)); err != nil {
+			return fmt.Errorf("range %s: %w", rangeID, err)
+		}
+	}
+	address, err := r.catalog.Store.GetMetaRange(ctx, source, metaRangeID)
+	if err != nil {
+		return err
+	}
+	if err := r.copyMissingFile(ctx, source, target, string(address)); err != nil {
+		return fmt.Errorf("metarange %s: %w", metaRangeID, err)
+	}
+	return nil
+}
+
+func (r *replicator) copyMissingFile(ctx context.Context, source, target *graveler.RepositoryRecord, address string) error {
+	srcObj := block.ObjectPointer{StorageNamespace: source.StorageNamespace.String(), Identifier: address, IdentifierType: block.IdentifierTypeRelative}
+	dstObj := block.ObjectPointer{StorageNamespace: target.StorageNamespace.String(), Identifier: address, IdentifierType: block.IdentifierTypeRelative}
+	exists, err := r.catalog.BlockAdapter.Exists(ctx, dstObj)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	return r.copyObject(ctx, srcObj, dstObj, -1)
+}
+
+// copyObject copies srcObj to dstObj, reading and writing it when the
+// adapter cannot copy between them, e.g. across blockstores.  A negative
+// size is read from the properties of srcObj.
+func (r *replicator) copyObject(ctx context.Context, srcObj, dstObj block.ObjectPointer, size int64) error {
+	err := r.catalog.BlockAdapter.Copy(ctx, srcObj, dstObj)
+	if !errors.Is(err, block.ErrOperationNotSupported) {
+		return err
+	}
+	if size < 0 {
+		props, err := r.catalog.BlockAdapter.GetProperties(ctx, srcObj)
+		if err != nil {
+			return err
+		}
+		size = props.Size
+	}
+	reader, err := r.catalog.BlockAdapter.Get(ctx, srcObj, size)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+	return r.catalog.BlockAdapter.Put(ctx, dstObj, size, reader, block.PutOpts{})
+}
+
+// Status returns the replication status of all replicated branches of repositoryID.
+func (r *replicator) Status(ctx context.Context, repositoryID string) ([]*ReplicationStatus, error) {
+	var statuses []*ReplicationStatus
+	found := false
+	for _, rule := range r.rules {
+		if rule.SourceRepository != repositoryID {
+			continue
+		}
+		found = true
+		source, err := r.catalog.getRepository(ctx, rule.SourceRepository)
+		if err != nil {
+			return nil, err
+		}
+		target, err := r.catalog.getRepository(ctx, rule.TargetRepository)
+		if err != nil {
+			return nil, fmt.Errorf("target repository: %w", err)
+		}
+		it, err := r.catalog.Store.ListBranches(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		for it.Next() {
+			b := it.Value()
+			if !rule.matchBranch(b.BranchID.String()) {
+				continue
+			}
+			status, err := r.branchStatus(ctx, rule, source, target, b)
+			if err != nil {
+				it.Close()
+				return nil, fmt.Errorf("branch %s: %w", b.BranchID, err)
+			}
+			statuses = append(statuses, status)
+		}
+		err = it.Err()
+		it.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !found {
+		return nil, ErrReplicationNotConfigured
+	}
+	return statuses, nil
+}
+
+func (r *replicator) branchStatus(ctx context.Context, rule *replicationRule, source, target *graveler.RepositoryRecord, b *graveler.BranchRecord) (*ReplicationStatus, error) {
+	status := &ReplicationStatus{
+		Branch:           b.BranchID.String(),
+		TargetRepository: rule.TargetRepository,
+		SourceCommitID:   b.CommitID.String(),
+	}
+	targetBranch, err := r.catalog.Store.GetBranch(ctx, target, b.BranchID)
+	switch {
+	case err == nil:
+		status.TargetCommitID = targetBranch.CommitID.String()
+	case !errors.Is(err, graveler.ErrBranchNotFound):
+		return nil, err
+	}
+	commits, err := r.missingCommits(ctx, source, target, b.CommitID)
+	if err != nil {
+		return nil, err
+	}
+	status.PendingCommits = len(commits)
+	if len(commits) > 0 {
+		oldest := commits[0].CreationDate
+		for _, commit := range commits[1:] {
+			if commit.CreationDate.Before(oldest) {
+				oldest = commit.CreationDate
+			}
+		}
+		status.Lag = time.Since(oldest)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if state, ok := r.states[replicationTask{rule: rule, branchID: b.BranchID}]; ok {
+		status.LastReplicatedAt = state.lastReplicatedAt
+		status.LastError = state.lastError
+	}
+	return status, nil
+}
+
+// replicationHooks passes all hooks to its wrapped handler, and schedules
+// replication of branches after commits and merges.
+type replicationHooks struct {
+	graveler.HooksHandler
+	replicator *replicator
+}
+
+func (h *replicationHooks) PostCommitHook(ctx context.Context, record graveler.HookRecord) error {
+	h.replicator.enqueue(record.RepositoryID, record.BranchID)
+	return h.HooksHandler.PostCommitHook(ctx, record)
+}
+
+func (h *replicationHooks) PostMergeHook(ctx context.Context, record graveler.HookRecord) error {
+	h.replicator.enqueue(record.RepositoryID, record.BranchID)
+	return h.HooksHandler.PostMergeHook(ctx, record)
+}
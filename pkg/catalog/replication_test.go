@@ -0,0 +1,259 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/cache"
+	"github.com/treeverse/lakefs/pkg/config"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/graveler/settings"
+	"github.com/treeverse/lakefs/pkg/kv/kvtest"
+	kvmem "github.com/treeverse/lakefs/pkg/kv/mem"
+	"github.com/treeverse/lakefs/pkg/upload"
+)
+
+const (
+	replicationSourceRepository = "source"
+	replicationTargetRepository = "target"
+)
+
+// newReplicationTestCatalog returns a catalog whose source repository is on
+// the default mem blockstore, and whose bare target repository is on a
+// local blockstore: the replicator copies between adapters.
+func newReplicationTestCatalog(t *testing.T) *Catalog {
+	t.Helper()
+	ctx := context.Background()
+	viper.Set(config.BlockstoreTypeKey, block.BlockstoreTypeMem)
+	viper.Set("database.type", kvmem.DriverName)
+	viper.Set("blockstore.adapters", []map[string]interface{}{{
+		"name":               "replica",
+		"type":               block.BlockstoreTypeLocal,
+		"namespace_prefixes": []string{"local://replica"},
+		"local":              map[string]interface{}{"path": t.TempDir()},
+	}})
+	t.Cleanup(func() {
+		viper.Set("blockstore.adapters", nil)
+	})
+	cfg, err := config.NewConfig("")
+	require.NoError(t, err)
+	c, err := New(ctx, Config{
+		Config:                cfg,
+		KVStore:               kvtest.GetStore(ctx, t),
+		SettingsManagerOption: settings.WithCache(cache.NoCache),
+		PathProvider:          upload.DefaultPathProvider,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = c.Close()
+	})
+
+	_, err = c.CreateRepository(ctx, replicationSourceRepository, "mem://source", "main")
+	require.NoError(t, err)
+	_, err = c.CreateBareRepository(ctx, replicationTargetRepository, "local://replica/target", "main")
+	require.NoError(t, err)
+	return c
+}
+
+func newTestReplicator(t *testing.T, c *Catalog, branches ...string) *replicator {
+	t.Helper()
+	r, err := newReplicator(c, []ReplicationRule{{
+		SourceRepository: replicationSourceRepository,
+		Branches:         branches,
+		TargetRepository: replicationTargetRepository,
+	}}, 0)
+	require.NoError(t, err)
+	return r
+}
+
+// commitObject uploads an object to branch of the source repository, and commits it.
+func commitObject(t *testing.T, c *Catalog, branch, path string) string {
+	t.Helper()
+	ctx := context.Background()
+	address := upload.DefaultPathProvider.NewPath()
+	contents := "contents of " + path
+	err := c.BlockAdapter.Put(ctx, block.ObjectPointer{
+		StorageNamespace: "mem://source",
+		Identifier:       address,
+		IdentifierType:   block.IdentifierTypeRelative,
+	}, int64(len(contents)), strings.NewReader(contents), block.PutOpts{})
+	require.NoError(t, err)
+	err = c.CreateEntry(ctx, replicationSourceRepository, branch, DBEntry{
+		Path:            path,
+		PhysicalAddress: address,
+		AddressType:     AddressTypeRelative,
+		Size:            int64(len(contents)),
+		Checksum:        "checksum-" + path,
+	})
+	require.NoError(t, err)
+	commit, err := c.Commit(ctx, replicationSourceRepository, branch, "add "+path, "tester", nil, nil, nil)
+	require.NoError(t, err)
+	return commit.Reference
+}
+
+func getRepositories(t *testing.T, c *Catalog) (*graveler.RepositoryRecord, *graveler.RepositoryRecord) {
+	t.Helper()
+	ctx := context.Background()
+	source, err := c.getRepository(ctx, replicationSourceRepository)
+	require.NoError(t, err)
+	target, err := c.getRepository(ctx, replicationTargetRepository)
+	require.NoError(t, err)
+	return source, target
+}
+
+func commitIDs(commits []*graveler.CommitRecord) []string {
+	ids := make([]string, len(commits))
+	for i, commit := range commits {
+		ids[i] = commit.CommitID.String()
+	}
+	return ids
+}
+
+func TestReplicator_MissingCommits(t *testing.T) {
+	ctx := context.Background()
+	c := newReplicationTestCatalog(t)
+	r := newTestReplicator(t, c, "main")
+	source, target := getRepositories(t, c)
+
+	initial, err := c.GetBranchReference(ctx, replicationSourceRepository, "main")
+	require.NoError(t, err)
+	first := commitObject(t, c, "main", "a")
+	_, err = c.CreateBranch(ctx, replicationSourceRepository, "feature", "main")
+	require.NoError(t, err)
+	second := commitObject(t, c, "main", "b")
+	feature := commitObject(t, c, "feature", "c")
+	merge, err := c.Merge(ctx, replicationSourceRepository, "main", "feature", "tester", "merge", nil, "")
+	require.NoError(t, err)
+
+	commits, err := r.missingCommits(ctx, source, target, graveler.CommitID(merge))
+	require.NoError(t, err)
+	ids := commitIDs(commits)
+	require.ElementsMatch(t, []string{initial, first, second, feature, merge}, ids)
+	// parents before their children
+	position := make(map[string]int, len(ids))
+	for i, id := range ids {
+		position[id] = i
+	}
+	for _, commit := range commits {
+		for _, parent := range commit.Parents {
+			require.Less(t, position[parent.String()], position[commit.CommitID.String()], "parent %s after commit %s", parent, commit.CommitID)
+		}
+	}
+
+	// only commits after the replicated head are missing
+	require.NoError(t, r.replicateBranch(ctx, r.rules[0], "main"))
+	commits, err = r.missingCommits(ctx, source, target, graveler.CommitID(merge))
+	require.NoError(t, err)
+	require.Empty(t, commits)
+	next := commitObject(t, c, "main", "d")
+	commits, err = r.missingCommits(ctx, source, target, graveler.CommitID(next))
+	require.NoError(t, err)
+	require.Equal(t, []string{next}, commitIDs(commits))
+}
+
+func TestReplicator_ReplicateBranch(t *testing.T) {
+	ctx := context.Background()
+	c := newReplicationTestCatalog(t)
+	r := newTestReplicator(t, c, "main")
+
+	for i := 0; i < 3; i++ {
+		commitObject(t, c, "main", fmt.Sprintf("obj%d", i))
+	}
+	require.NoError(t, r.replicateBranch(ctx, r.rules[0], "main"))
+
+	head, err := c.GetBranchReference(ctx, replicationSourceRepository, "main")
+	require.NoError(t, err)
+	targetHead, err := c.GetBranchReference(ctx, replicationTargetRepository, "main")
+	require.NoError(t, err)
+	require.Equal(t, head, targetHead)
+
+	// objects were copied from the mem blockstore to the local one
+	for i := 0; i < 3; i++ {
+		path := fmt.Sprintf("obj%d", i)
+		entry, err := c.GetEntry(ctx, replicationTargetRepository, "main", path, GetEntryParams{})
+		require.NoError(t, err)
+		reader, err := c.BlockAdapter.Get(ctx, block.ObjectPointer{
+			StorageNamespace: "local://replica/target",
+			Identifier:       entry.PhysicalAddress,
+			IdentifierType:   block.IdentifierTypeRelative,
+		}, entry.Size)
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		_ = reader.Close()
+		require.NoError(t, err)
+		require.Equal(t, "contents of "+path, string(data))
+	}
+}
+
+func TestReplicator_ReplicateTags(t *testing.T) {
+	ctx := context.Background()
+	c := newReplicationTestCatalog(t)
+	r := newTestReplicator(t, c, "main")
+	source, target := getRepositories(t, c)
+
+	replicated := commitObject(t, c, "main", "a")
+	_, err := c.CreateBranch(ctx, replicationSourceRepository, "unreplicated", "main")
+	require.NoError(t, err)
+	unreplicated := commitObject(t, c, "unreplicated", "b")
+	require.NoError(t, r.replicateBranch(ctx, r.rules[0], "main"))
+
+	_, err = c.CreateTag(ctx, replicationSourceRepository, "v1", replicated)
+	require.NoError(t, err)
+	_, err = c.CreateTag(ctx, replicationSourceRepository, "v2", replicated)
+	require.NoError(t, err)
+	_, err = c.CreateTag(ctx, replicationSourceRepository, "other", unreplicated)
+	require.NoError(t, err)
+	require.NoError(t, r.replicateTags(ctx, source, target))
+
+	// tags of commits that were not replicated are skipped
+	tags, err := r.listTags(ctx, target)
+	require.NoError(t, err)
+	require.Equal(t, map[graveler.TagID]graveler.CommitID{
+		"v1": graveler.CommitID(replicated),
+		"v2": graveler.CommitID(replicated),
+	}, tags)
+
+	// tags deleted from the source are deleted from the target
+	require.NoError(t, c.DeleteTag(ctx, replicationSourceRepository, "v1"))
+	require.NoError(t, r.replicateTags(ctx, source, target))
+	tags, err = r.listTags(ctx, target)
+	require.NoError(t, err)
+	require.Equal(t, map[graveler.TagID]graveler.CommitID{
+		"v2": graveler.CommitID(replicated),
+	}, tags)
+}
+
+func TestReplicator_ReadOnlyTarget(t *testing.T) {
+	ctx := context.Background()
+	c := newReplicationTestCatalog(t)
+	r := newTestReplicator(t, c, "main")
+	source, target := getRepositories(t, c)
+	require.NoError(t, c.SetRepositoryAccessState(ctx, replicationTargetRepository, graveler.RepositoryAccessStateReadOnly))
+
+	head := commitObject(t, c, "main", "a")
+	_, err := c.CreateTag(ctx, replicationSourceRepository, "v1", head)
+	require.NoError(t, err)
+	require.NoError(t, r.replicateBranch(ctx, r.rules[0], "main"))
+	require.NoError(t, r.replicateTags(ctx, source, target))
+	require.NoError(t, c.DeleteTag(ctx, replicationSourceRepository, "v1"))
+	require.NoError(t, r.replicateTags(ctx, source, target))
+
+	targetHead, err := c.GetBranchReference(ctx, replicationTargetRepository, "main")
+	require.NoError(t, err)
+	require.Equal(t, head, targetHead)
+	tags, err := r.listTags(ctx, target)
+	require.NoError(t, err)
+	require.Empty(t, tags)
+
+	// loading refs on behalf of users is still rejected
+	commitsMetaRangeID, err := c.Store.DumpCommits(ctx, source)
+	require.NoError(t, err)
+	err = c.LoadCommits(ctx, replicationTargetRepository, commitsMetaRangeID.String())
+	require.ErrorIs(t, err, graveler.ErrReadOnlyRepository)
+}
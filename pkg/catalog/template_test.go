@@ -0,0 +1,147 @@
+package catalog
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/cache"
+	"github.com/treeverse/lakefs/pkg/config"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/graveler/settings"
+	"github.com/treeverse/lakefs/pkg/kv/kvtest"
+	kvmem "github.com/treeverse/lakefs/pkg/kv/mem"
+	"github.com/treeverse/lakefs/pkg/upload"
+)
+
+const (
+	templateTestRepository = "template"
+	templateTestNamespace  = "local://template"
+)
+
+// newTemplateTestCatalog returns a catalog with a template repository on the
+// default local blockstore, and a second local blockstore for namespaces
+// under local://other.
+func newTemplateTestCatalog(t *testing.T) *Catalog {
+	t.Helper()
+	ctx := context.Background()
+	viper.Set(config.BlockstoreTypeKey, block.BlockstoreTypeLocal)
+	viper.Set("blockstore.local.path", t.TempDir())
+	viper.Set("database.type", kvmem.DriverName)
+	viper.Set("blockstore.adapters", []map[string]interface{}{{
+		"name":               "other",
+		"type":               block.BlockstoreTypeLocal,
+		"namespace_prefixes": []string{"local://other"},
+		"local":              map[string]interface{}{"path": t.TempDir()},
+	}})
+	t.Cleanup(func() {
+		viper.Set(config.BlockstoreTypeKey, block.BlockstoreTypeMem)
+		viper.Set("blockstore.adapters", nil)
+	})
+	cfg, err := config.NewConfig("")
+	require.NoError(t, err)
+	c, err := New(ctx, Config{
+		Config:                cfg,
+		KVStore:               kvtest.GetStore(ctx, t),
+		SettingsManagerOption: settings.WithCache(cache.NoCache),
+		PathProvider:          upload.DefaultPathProvider,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = c.Close()
+	})
+
+	_, err = c.CreateRepository(ctx, templateTestRepository, templateTestNamespace, "main")
+	require.NoError(t, err)
+	return c
+}
+
+func readEntry(t *testing.T, c *Catalog, storageNamespace string, entry *DBEntry) string {
+	t.Helper()
+	identifierType := block.IdentifierTypeRelative
+	if entry.AddressType == AddressTypeFull {
+		identifierType = block.IdentifierTypeFull
+	}
+	reader, err := c.BlockAdapter.Get(context.Background(), block.ObjectPointer{
+		StorageNamespace: storageNamespace,
+		Identifier:       entry.PhysicalAddress,
+		IdentifierType:   identifierType,
+	}, entry.Size)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestCatalog_CreateRepositoryFromTemplate(t *testing.T) {
+	ctx := context.Background()
+	c := newTemplateTestCatalog(t)
+	address := upload.DefaultPathProvider.NewPath()
+	const contents = "contents of a"
+	err := c.BlockAdapter.Put(ctx, block.ObjectPointer{
+		StorageNamespace: templateTestNamespace,
+		Identifier:       address,
+		IdentifierType:   block.IdentifierTypeRelative,
+	}, int64(len(contents)), strings.NewReader(contents), block.PutOpts{})
+	require.NoError(t, err)
+	err = c.CreateEntry(ctx, templateTestRepository, "main", DBEntry{
+		Path:            "a",
+		PhysicalAddress: address,
+		AddressType:     AddressTypeRelative,
+		Size:            int64(len(contents)),
+		Checksum:        "checksum-a",
+	})
+	require.NoError(t, err)
+	commitID, err := c.Commit(ctx, templateTestRepository, "main", "add a", "tester", nil, nil, nil)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name             string
+		storageNamespace string
+	}{
+		{name: "same blockstore", storageNamespace: "local://copied"},
+		{name: "other blockstore", storageNamespace: "local://other/copied"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := strings.ReplaceAll(tt.name, " ", "-")
+			_, err := c.CreateRepositoryFromTemplate(ctx, repository, tt.storageNamespace, "main", templateTestRepository, "main", "tester")
+			require.NoError(t, err)
+
+			// the object of the template was copied into the new storage namespace
+			entry, err := c.GetEntry(ctx, repository, "main", "a", GetEntryParams{})
+			require.NoError(t, err)
+			require.Equal(t, AddressTypeRelative, entry.AddressType)
+			require.NotEqual(t, address, entry.PhysicalAddress)
+			require.Equal(t, contents, readEntry(t, c, tt.storageNamespace, entry))
+
+			commit, err := c.GetCommit(ctx, repository, "main")
+			require.NoError(t, err)
+			require.Equal(t, templateTestRepository, commit.Metadata[TemplateRepositoryMetadataKey])
+			require.Equal(t, commitID.Reference, commit.Metadata[TemplateCommitMetadataKey])
+		})
+	}
+
+	t.Run("failed copy", func(t *testing.T) {
+		err := c.CreateEntry(ctx, templateTestRepository, "main", DBEntry{
+			Path:            "missing",
+			PhysicalAddress: upload.DefaultPathProvider.NewPath(),
+			AddressType:     AddressTypeRelative,
+			Size:            1,
+			Checksum:        "checksum-missing",
+		})
+		require.NoError(t, err)
+		_, err = c.Commit(ctx, templateTestRepository, "main", "add missing", "tester", nil, nil, nil)
+		require.NoError(t, err)
+
+		_, err = c.CreateRepositoryFromTemplate(ctx, "failed", "local://failed", "main", templateTestRepository, "main", "tester")
+		require.Error(t, err)
+		_, err = c.GetRepository(ctx, "failed")
+		require.ErrorIs(t, err, graveler.ErrNotFound)
+	})
+}
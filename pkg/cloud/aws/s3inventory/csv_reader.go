@@ -0,0 +1,166 @@
+package s3inventory
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// csvColumns maps the column names of a CSV inventory file schema to inventory fields
+var csvColumns = map[string]string{
+	"Bucket":           bucketFieldName,
+	"Key":              keyFieldName,
+	"Size":             sizeFieldName,
+	"LastModifiedDate": lastModifiedDateFieldName,
+	"ETag":             eTagFieldName,
+	"IsDeleteMarker":   isDeleteMarkerFieldName,
+	"IsLatest":         isLatestFieldName,
+}
+
+var ErrInvalidCSVValue = errors.New("invalid value in CSV inventory")
+
+// CSVInventoryFileReader reads a gzip compressed CSV inventory file.  CSV
+// files have no header row: their columns are listed in the fileSchema of
+// the manifest.
+type CSVInventoryFileReader struct {
+	objects []*InventoryObject
+	nextRow int
+}
+
+// getCSVFields returns the inventory field of each column of a CSV file schema,
+// or an empty string for columns that are not read.
+func getCSVFields(fileSchema string) ([]string, error) {
+	var fields []string
+	found := make(map[string]bool)
+	for _, column := range strings.Split(fileSchema, ",") {
+		field := csvColumns[strings.TrimSpace(column)]
+		fields = append(fields, field)
+		found[field] = true
+	}
+	for _, required := range requiredFields {
+		if !found[required] {
+			return nil, fmt.Errorf("%w: %s", ErrRequiredFieldNotFound, required)
+		}
+	}
+	return fields, nil
+}
+
+func (o *Reader) getCSVReader(bucket string, key string) (FileReader, error) {
+	fields, err := getCSVFields(o.fileSchema)
+	if err != nil {
+		return nil, err
+	}
+	output, err := o.svc.GetObjectWithContext(o.ctx, &s3.GetObjectInput{Bucket: &bucket, Key: &key})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CSV inventory file %s: %w", key, err)
+	}
+	defer func() {
+		_ = output.Body.Close()
+	}()
+	br := bufio.NewReader(output.Body)
+	var r io.Reader = br
+	// S3 inventory CSV files are gzip compressed, accept uncompressed files as well
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV inventory file %s: %w", key, err)
+		}
+		defer func() {
+			_ = gz.Close()
+		}()
+		r = gz
+	}
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = len(fields)
+	res := &CSVInventoryFileReader{}
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV inventory file %s: %w", key, err)
+		}
+		obj, err := inventoryObjectFromRecord(fields, record)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		res.objects = append(res.objects, obj)
+	}
+	return res, nil
+}
+
+func inventoryObjectFromRecord(fields []string, record []string) (*InventoryObject, error) {
+	obj := NewInventoryObject()
+	for i, field := range fields {
+		value := record[i]
+		if value == "" {
+			continue
+		}
+		var err error
+		switch field {
+		case bucketFieldName:
+			obj.Bucket = value
+		case keyFieldName:
+			// object keys are URL encoded
+			obj.Key, err = url.QueryUnescape(value)
+		case sizeFieldName:
+			obj.Size, err = strconv.ParseInt(value, 10, 64)
+		case lastModifiedDateFieldName:
+			var lastModified time.Time
+			lastModified, err = time.Parse(time.RFC3339Nano, value)
+			obj.LastModified = &lastModified
+		case eTagFieldName:
+			obj.Checksum = value
+		case isDeleteMarkerFieldName:
+			obj.IsDeleteMarker, err = strconv.ParseBool(value)
+		case isLatestFieldName:
+			obj.IsLatest, err = strconv.ParseBool(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %s", ErrInvalidCSVValue, field, value)
+		}
+	}
+	return obj, nil
+}
+
+func (r *CSVInventoryFileReader) Read(n int) ([]*InventoryObject, error) {
+	end := r.nextRow + n
+	if end > len(r.objects) {
+		end = len(r.objects)
+	}
+	res := r.objects[r.nextRow:end]
+	r.nextRow = end
+	return res, nil
+}
+
+func (r *CSVInventoryFileReader) GetNumRows() int64 {
+	return int64(len(r.objects))
+}
+
+func (r *CSVInventoryFileReader) FirstObjectKey() string {
+	if len(r.objects) == 0 {
+		return ""
+	}
+	return r.objects[0].Key
+}
+
+func (r *CSVInventoryFileReader) LastObjectKey() string {
+	if len(r.objects) == 0 {
+		return ""
+	}
+	return r.objects[len(r.objects)-1].Key
+}
+
+func (r *CSVInventoryFileReader) Close() error {
+	return nil
+}
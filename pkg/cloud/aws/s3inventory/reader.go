@@ -38,10 +38,11 @@ var requiredFields = []string{bucketFieldName, keyFieldName}
 const (
 	OrcFormatName     = "ORC"
 	ParquetFormatName = "Parquet"
+	CSVFormatName     = "CSV"
 )
 
 var (
-	ErrUnsupportedInventoryFormat = errors.New("unsupported inventory type. supported types: parquet, orc")
+	ErrUnsupportedInventoryFormat = errors.New("unsupported inventory type. supported types: parquet, orc, csv")
 	ErrRequiredFieldNotFound      = errors.New("required field not found in inventory")
 	ErrUnknownField               = errors.New("unknown field")
 )
@@ -70,9 +71,10 @@ func (o *InventoryObject) GetPhysicalAddress() string {
 }
 
 type Reader struct {
-	ctx    context.Context
-	svc    s3iface.S3API
-	logger logging.Logger
+	ctx        context.Context
+	svc        s3iface.S3API
+	logger     logging.Logger
+	fileSchema string
 }
 
 type MetadataReader interface {
@@ -87,8 +89,10 @@ type FileReader interface {
 	Read(n int) ([]*InventoryObject, error)
 }
 
-func NewReader(ctx context.Context, svc s3iface.S3API, logger logging.Logger) IReader {
-	return &Reader{ctx: ctx, svc: svc, logger: logger}
+// NewReader returns a reader of inventory files.  fileSchema is the fileSchema
+// of the inventory manifest, which lists the columns of CSV inventory files.
+func NewReader(ctx context.Context, svc s3iface.S3API, logger logging.Logger, fileSchema string) IReader {
+	return &Reader{ctx: ctx, svc: svc, logger: logger, fileSchema: fileSchema}
 }
 
 func (o *Reader) GetFileReader(format string, bucket string, key string) (FileReader, error) {
@@ -97,6 +101,8 @@ func (o *Reader) GetFileReader(format string, bucket string, key string) (FileRe
 		return o.getOrcReader(bucket, key, false)
 	case ParquetFormatName:
 		return o.getParquetReader(bucket, key)
+	case CSVFormatName:
+		return o.getCSVReader(bucket, key)
 	default:
 		return nil, ErrUnsupportedInventoryFormat
 	}
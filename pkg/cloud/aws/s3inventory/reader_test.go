@@ -1,9 +1,13 @@
 package s3inventory
 
 import (
+	"compress/gzip"
 	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
@@ -181,6 +185,58 @@ func generateOrc(t *testing.T, objs <-chan *TestObject, fieldToRemove string) *o
 	return f
 }
 
+// csvSchema returns the fileSchema of a CSV inventory manifest
+func csvSchema(fieldToRemove string) string {
+	columns := make(map[string]string, len(csvColumns))
+	for column, field := range csvColumns {
+		columns[field] = column
+	}
+	var res []string
+	for _, field := range inventoryFields {
+		if fieldToRemove != field {
+			res = append(res, columns[field])
+		}
+	}
+	return strings.Join(res, ", ")
+}
+
+func generateCSV(t *testing.T, objs <-chan *TestObject, fieldToRemove string) *os.File {
+	f, err := os.CreateTemp("", "csvtest")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	gz := gzip.NewWriter(f)
+	w := csv.NewWriter(gz)
+	for o := range objs {
+		var record []string
+		for _, v := range getOrcValues(o, fieldToRemove) {
+			switch value := v.(type) {
+			case time.Time:
+				record = append(record, value.UTC().Format("2006-01-02T15:04:05.000Z"))
+			case string:
+				record = append(record, url.QueryEscape(value))
+			default:
+				record = append(record, fmt.Sprint(value))
+			}
+		}
+		if err := w.Write(record); err != nil {
+			t.Fatalf("failed to write object to csv: %v", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("failed to close gzip writer: %v", err)
+	}
+	_, _ = f.Seek(0, 0)
+	return f
+}
+
 func TestReaders(t *testing.T) {
 	svc, testServer := getS3Fake(t)
 	defer testServer.Close()
@@ -244,7 +300,7 @@ func TestReaders(t *testing.T) {
 			ExcludeField:        "e_tag",
 		},
 	}
-	for _, format := range []string{"ORC", "Parquet"} {
+	for _, format := range []string{"ORC", "Parquet", "CSV"} {
 		for testName, test := range testdata {
 			t.Run(fmt.Sprintf("%s %s", strings.ToLower(format), testName), func(t *testing.T) {
 				now := time.Now().Truncate(time.Millisecond)
@@ -254,9 +310,11 @@ func TestReaders(t *testing.T) {
 					localFile = generateOrc(t, objs(test.ObjectNum, lastModified), test.ExcludeField)
 				} else if format == "Parquet" {
 					localFile = generateParquet(t, objs(test.ObjectNum, lastModified), test.ExcludeField)
+				} else if format == "CSV" {
+					localFile = generateCSV(t, objs(test.ObjectNum, lastModified), test.ExcludeField)
 				}
 				uploadFile(t, svc, inventoryBucketName, "myFile.inv", localFile)
-				reader := NewReader(context.Background(), svc, logging.ContextUnavailable())
+				reader := NewReader(context.Background(), svc, logging.ContextUnavailable(), csvSchema(test.ExcludeField))
 				fileReader, err := reader.GetFileReader(format, inventoryBucketName, "myFile.inv")
 				if err != nil {
 					t.Fatalf("failed to create file reader: %v", err)
@@ -316,3 +374,27 @@ func TestReaders(t *testing.T) {
 		}
 	}
 }
+
+func TestCSVInventoryObjectFromRecord(t *testing.T) {
+	fields, err := getCSVFields(csvSchema(""))
+	if err != nil {
+		t.Fatalf("failed to get CSV fields: %v", err)
+	}
+	obj, err := inventoryObjectFromRecord(fields, []string{"bucket", "a/with+space%3D1", "3", "2023-01-02T03:04:05.000Z", "etag", "", "true"})
+	if err != nil {
+		t.Fatalf("failed to read CSV record: %v", err)
+	}
+	lastModified := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	verifyObject(t, obj, &InventoryObject{
+		Bucket:       "bucket",
+		Key:          "a/with space=1",
+		IsLatest:     true,
+		Size:         3,
+		LastModified: &lastModified,
+		Checksum:     "etag",
+	}, 0, 0, 0)
+
+	if _, err := getCSVFields("Bucket, Size"); !errors.Is(err, ErrRequiredFieldNotFound) {
+		t.Fatalf("getCSVFields() without key err = %v, expected %v", err, ErrRequiredFieldNotFound)
+	}
+}
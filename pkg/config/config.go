@@ -1,8 +1,10 @@
 package config
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"os"
 	"reflect"
 	"sort"
 	"strings"
@@ -72,6 +74,18 @@ type S3AuthInfo struct {
 	}
 }
 
+// S3Endpoint configures an additional S3 endpoint, used for storage
+// namespaces under NamespacePrefix.  Credentials default to those of the
+// blockstore.
+type S3Endpoint struct {
+	S3AuthInfo      `mapstructure:",squash"`
+	NamespacePrefix string `mapstructure:"namespace_prefix"`
+	Region          string `mapstructure:"region"`
+	Endpoint        string `mapstructure:"endpoint"`
+	ForcePathStyle  bool   `mapstructure:"force_path_style"`
+	Compat          string `mapstructure:"compat"`
+}
+
 // PluginProps struct holds the properties needed to run a plugin
 type PluginProps struct {
 	Path    string `mapstructure:"path"`
@@ -89,9 +103,114 @@ type DeltaDiffPlugin struct {
 	PluginName string `mapstructure:"plugin"`
 }
 
+// TableDiffPlugin includes properties for a diff plugin that replaces a built-in table format differ
+type TableDiffPlugin struct {
+	PluginName string `mapstructure:"plugin"`
+}
+
 // DiffProps struct holds the properties that define the details necessary to run a diff.
 type DiffProps struct {
-	Delta DeltaDiffPlugin `mapstructure:"delta"`
+	Delta   DeltaDiffPlugin `mapstructure:"delta"`
+	Iceberg TableDiffPlugin `mapstructure:"iceberg"`
+	Hudi    TableDiffPlugin `mapstructure:"hudi"`
+}
+
+// BlockstoreAdapter configures a block adapter.  It implements
+// params.AdapterConfig.
+type BlockstoreAdapter struct {
+	Type  string `mapstructure:"type" validate:"required"`
+	Local *struct {
+		Path                    string   `mapstructure:"path"`
+		ImportEnabled           bool     `mapstructure:"import_enabled"`
+		ImportHidden            bool     `mapstructure:"import_hidden"`
+		AllowedExternalPrefixes []string `mapstructure:"allowed_external_prefixes"`
+	} `mapstructure:"local"`
+	S3 *struct {
+		S3AuthInfo                    `mapstructure:",squash"`
+		Region                        string        `mapstructure:"region"`
+		Endpoint                      string        `mapstructure:"endpoint"`
+		StreamingChunkSize            int           `mapstructure:"streaming_chunk_size"`
+		StreamingChunkTimeout         time.Duration `mapstructure:"streaming_chunk_timeout"`
+		MaxRetries                    int           `mapstructure:"max_retries"`
+		ForcePathStyle                bool          `mapstructure:"force_path_style"`
+		DiscoverBucketRegion          bool          `mapstructure:"discover_bucket_region"`
+		SkipVerifyCertificateTestOnly bool          `mapstructure:"skip_verify_certificate_test_only"`
+		ServerSideEncryption          string        `mapstructure:"server_side_encryption"`
+		ServerSideEncryptionKmsKeyID  string        `mapstructure:"server_side_encryption_kms_key_id"`
+		PreSignedExpiry               time.Duration `mapstructure:"pre_signed_expiry"`
+		DisablePreSigned              bool          `mapstructure:"disable_pre_signed"`
+		DisablePreSignedUI            bool          `mapstructure:"disable_pre_signed_ui"`
+		Compat                        string        `mapstructure:"compat"`
+		Endpoints                     []S3Endpoint  `mapstructure:"endpoints"`
+		WebIdentity                   *struct {
+			SessionDuration     time.Duration `mapstructure:"session_duration"`
+			SessionExpiryWindow time.Duration `mapstructure:"session_expiry_window"`
+		} `mapstructure:"web_identity"`
+	} `mapstructure:"s3"`
+	Azure *struct {
+		TryTimeout       time.Duration `mapstructure:"try_timeout"`
+		StorageAccount   string        `mapstructure:"storage_account"`
+		StorageAccessKey string        `mapstructure:"storage_access_key"`
+		// Deprecated: Value ignored
+		AuthMethod         string        `mapstructure:"auth_method"`
+		PreSignedExpiry    time.Duration `mapstructure:"pre_signed_expiry"`
+		DisablePreSigned   bool          `mapstructure:"disable_pre_signed"`
+		DisablePreSignedUI bool          `mapstructure:"disable_pre_signed_ui"`
+		// TestEndpointURL for testing purposes
+		TestEndpointURL string `mapstructure:"test_endpoint_url"`
+	} `mapstructure:"azure"`
+	GS *struct {
+		S3Endpoint         string        `mapstructure:"s3_endpoint"`
+		CredentialsFile    string        `mapstructure:"credentials_file"`
+		CredentialsJSON    string        `mapstructure:"credentials_json"`
+		PreSignedExpiry    time.Duration `mapstructure:"pre_signed_expiry"`
+		DisablePreSigned   bool          `mapstructure:"disable_pre_signed"`
+		DisablePreSignedUI bool          `mapstructure:"disable_pre_signed_ui"`
+	} `mapstructure:"gs"`
+}
+
+// NamedBlockstoreAdapter configures an additional block adapter, used for
+// storage namespaces under any of NamespacePrefixes.
+type NamedBlockstoreAdapter struct {
+	BlockstoreAdapter `mapstructure:",squash"`
+	Name              string   `mapstructure:"name"`
+	NamespacePrefixes []string `mapstructure:"namespace_prefixes"`
+}
+
+// Blockstore configures the default block adapter, and any additional
+// adapters.
+type Blockstore struct {
+	BlockstoreAdapter      `mapstructure:",squash"`
+	DefaultNamespacePrefix *string                  `mapstructure:"default_namespace_prefix"`
+	Adapters               []NamedBlockstoreAdapter `mapstructure:"adapters"`
+	Encryption             struct {
+		Enabled         bool         `mapstructure:"enabled"`
+		SecretKey       SecureString `mapstructure:"secret_key"`
+		KeyFile         string       `mapstructure:"key_file"`
+		ReadUnencrypted bool         `mapstructure:"read_unencrypted"`
+	} `mapstructure:"encryption"`
+	Compression struct {
+		Enabled bool `mapstructure:"enabled"`
+	} `mapstructure:"compression"`
+}
+
+// ReplicationRule replicates the branches of SourceRepository that match
+// any of the Branches patterns into TargetRepository.
+type ReplicationRule struct {
+	SourceRepository string   `mapstructure:"source_repository" validate:"required"`
+	Branches         []string `mapstructure:"branches" validate:"required"`
+	TargetRepository string   `mapstructure:"target_repository" validate:"required"`
+}
+
+// ContinuousImportFeed imports the objects created and removed under
+// Source, as reported by the S3 event notification files written to
+// EventsDir, into Branch of Repository under Destination.
+type ContinuousImportFeed struct {
+	Repository  string `mapstructure:"repository" validate:"required"`
+	Branch      string `mapstructure:"branch" validate:"required"`
+	EventsDir   string `mapstructure:"events_dir" validate:"required"`
+	Source      string `mapstructure:"source" validate:"required"`
+	Destination string `mapstructure:"destination"`
 }
 
 // Config - Output struct of configuration, used to validate.  If you read a key using a viper accessor
@@ -227,57 +346,8 @@ type Config struct {
 			LogoutURL          string   `mapstructure:"logout_url"`
 		} `mapstructure:"ui_config"`
 	} `mapstructure:"auth"`
-	Blockstore struct {
-		Type                   string  `mapstructure:"type" validate:"required"`
-		DefaultNamespacePrefix *string `mapstructure:"default_namespace_prefix"`
-		Local                  *struct {
-			Path                    string   `mapstructure:"path"`
-			ImportEnabled           bool     `mapstructure:"import_enabled"`
-			ImportHidden            bool     `mapstructure:"import_hidden"`
-			AllowedExternalPrefixes []string `mapstructure:"allowed_external_prefixes"`
-		} `mapstructure:"local"`
-		S3 *struct {
-			S3AuthInfo                    `mapstructure:",squash"`
-			Region                        string        `mapstructure:"region"`
-			Endpoint                      string        `mapstructure:"endpoint"`
-			StreamingChunkSize            int           `mapstructure:"streaming_chunk_size"`
-			StreamingChunkTimeout         time.Duration `mapstructure:"streaming_chunk_timeout"`
-			MaxRetries                    int           `mapstructure:"max_retries"`
-			ForcePathStyle                bool          `mapstructure:"force_path_style"`
-			DiscoverBucketRegion          bool          `mapstructure:"discover_bucket_region"`
-			SkipVerifyCertificateTestOnly bool          `mapstructure:"skip_verify_certificate_test_only"`
-			ServerSideEncryption          string        `mapstructure:"server_side_encryption"`
-			ServerSideEncryptionKmsKeyID  string        `mapstructure:"server_side_encryption_kms_key_id"`
-			PreSignedExpiry               time.Duration `mapstructure:"pre_signed_expiry"`
-			DisablePreSigned              bool          `mapstructure:"disable_pre_signed"`
-			DisablePreSignedUI            bool          `mapstructure:"disable_pre_signed_ui"`
-			WebIdentity                   *struct {
-				SessionDuration     time.Duration `mapstructure:"session_duration"`
-				SessionExpiryWindow time.Duration `mapstructure:"session_expiry_window"`
-			} `mapstructure:"web_identity"`
-		} `mapstructure:"s3"`
-		Azure *struct {
-			TryTimeout       time.Duration `mapstructure:"try_timeout"`
-			StorageAccount   string        `mapstructure:"storage_account"`
-			StorageAccessKey string        `mapstructure:"storage_access_key"`
-			// Deprecated: Value ignored
-			AuthMethod         string        `mapstructure:"auth_method"`
-			PreSignedExpiry    time.Duration `mapstructure:"pre_signed_expiry"`
-			DisablePreSigned   bool          `mapstructure:"disable_pre_signed"`
-			DisablePreSignedUI bool          `mapstructure:"disable_pre_signed_ui"`
-			// TestEndpointURL for testing purposes
-			TestEndpointURL string `mapstructure:"test_endpoint_url"`
-		} `mapstructure:"azure"`
-		GS *struct {
-			S3Endpoint         string        `mapstructure:"s3_endpoint"`
-			CredentialsFile    string        `mapstructure:"credentials_file"`
-			CredentialsJSON    string        `mapstructure:"credentials_json"`
-			PreSignedExpiry    time.Duration `mapstructure:"pre_signed_expiry"`
-			DisablePreSigned   bool          `mapstructure:"disable_pre_signed"`
-			DisablePreSignedUI bool          `mapstructure:"disable_pre_signed_ui"`
-		} `mapstructure:"gs"`
-	} `mapstructure:"blockstore"`
-	Committed struct {
+	Blockstore Blockstore `mapstructure:"blockstore"`
+	Committed  struct {
 		LocalCache struct {
 			SizeBytes             int64   `mapstructure:"size_bytes"`
 			Dir                   string  `mapstructure:"dir"`
@@ -317,7 +387,21 @@ type Config struct {
 		Background struct {
 			RateLimit int `mapstructure:"rate_limit"`
 		} `mapstructure:"background"`
+		Compaction struct {
+			Enabled               bool          `mapstructure:"enabled"`
+			Interval              time.Duration `mapstructure:"interval"`
+			SealedTokensThreshold int           `mapstructure:"sealed_tokens_threshold"`
+		} `mapstructure:"compaction"`
 	} `mapstructure:"graveler"`
+	Replication struct {
+		Interval time.Duration     `mapstructure:"interval"`
+		Rules    []ReplicationRule `mapstructure:"rules"`
+	} `mapstructure:"replication"`
+	ContinuousImport struct {
+		Interval           time.Duration          `mapstructure:"interval"`
+		MaxEventsPerCommit int                    `mapstructure:"max_events_per_commit"`
+		Feeds              []ContinuousImportFeed `mapstructure:"feeds"`
+	} `mapstructure:"continuous_import"`
 	Gateways struct {
 		S3 struct {
 			DomainNames Strings `mapstructure:"domain_name"`
@@ -447,114 +531,304 @@ func (c *Config) Validate() error {
 	return nil
 }
 
-func (c *Config) GetAwsConfig() *aws.Config {
-	logger := logging.ContextUnavailable().WithField("sdk", "aws")
-	cfg := &aws.Config{
-		Logger: &logging.AWSAdapter{Logger: logger},
-	}
-	if c.Blockstore.S3.Region != "" {
-		cfg.Region = aws.String(c.Blockstore.S3.Region)
-	}
-	level := strings.ToLower(logging.Level())
-	if level == "trace" {
-		cfg.LogLevel = aws.LogLevel(aws.LogDebugWithRequestRetries | aws.LogDebugWithRequestErrors)
-	}
-	if c.Blockstore.S3.Profile != "" || c.Blockstore.S3.CredentialsFile != "" {
-		cfg.Credentials = credentials.NewSharedCredentials(
-			c.Blockstore.S3.CredentialsFile,
-			c.Blockstore.S3.Profile,
+// getCredentials returns the credentials configured by auth, or nil if it
+// configures none.
+func (auth S3AuthInfo) getCredentials() *credentials.Credentials {
+	var creds *credentials.Credentials
+	if auth.Profile != "" || auth.CredentialsFile != "" {
+		creds = credentials.NewSharedCredentials(
+			auth.CredentialsFile,
+			auth.Profile,
 		)
 	}
-	if c.Blockstore.S3.Credentials != nil {
-		secretAccessKey := c.Blockstore.S3.Credentials.SecretAccessKey
+	if auth.Credentials != nil {
+		secretAccessKey := auth.Credentials.SecretAccessKey
 		if secretAccessKey == "" {
 			logging.ContextUnavailable().Warn("blockstore.s3.credentials.access_secret_key is deprecated. Use instead: blockstore.s3.credentials.secret_access_key.")
-			secretAccessKey = c.Blockstore.S3.Credentials.AccessSecretKey
+			secretAccessKey = auth.Credentials.AccessSecretKey
 		}
-		cfg.Credentials = credentials.NewStaticCredentials(
-			c.Blockstore.S3.Credentials.AccessKeyID.SecureValue(),
+		creds = credentials.NewStaticCredentials(
+			auth.Credentials.AccessKeyID.SecureValue(),
 			secretAccessKey.SecureValue(),
-			c.Blockstore.S3.Credentials.SessionToken.SecureValue(),
+			auth.Credentials.SessionToken.SecureValue(),
 		)
 	}
+	return creds
+}
 
-	s3Endpoint := c.Blockstore.S3.Endpoint
-	if len(s3Endpoint) > 0 {
-		cfg = cfg.WithEndpoint(s3Endpoint)
+func (b *BlockstoreAdapter) newAwsConfig(region, endpoint string, forcePathStyle bool, creds *credentials.Credentials) *aws.Config {
+	logger := logging.ContextUnavailable().WithField("sdk", "aws")
+	cfg := &aws.Config{
+		Logger:      &logging.AWSAdapter{Logger: logger},
+		Credentials: creds,
+	}
+	if region != "" {
+		cfg.Region = aws.String(region)
 	}
-	s3ForcePathStyle := c.Blockstore.S3.ForcePathStyle
-	if s3ForcePathStyle {
+	level := strings.ToLower(logging.Level())
+	if level == "trace" {
+		cfg.LogLevel = aws.LogLevel(aws.LogDebugWithRequestRetries | aws.LogDebugWithRequestErrors)
+	}
+	if len(endpoint) > 0 {
+		cfg = cfg.WithEndpoint(endpoint)
+	}
+	if forcePathStyle {
 		cfg = cfg.WithS3ForcePathStyle(true)
 	}
-	cfg = cfg.WithMaxRetries(c.Blockstore.S3.MaxRetries)
+	cfg = cfg.WithMaxRetries(b.S3.MaxRetries)
 	return cfg
 }
 
+func (c *Config) GetAwsConfig() *aws.Config {
+	return c.Blockstore.GetAwsConfig()
+}
+
+func (b *BlockstoreAdapter) GetAwsConfig() *aws.Config {
+	return b.newAwsConfig(b.S3.Region, b.S3.Endpoint, b.S3.ForcePathStyle, b.S3.getCredentials())
+}
+
 func (c *Config) BlockstoreType() string {
 	return c.Blockstore.Type
 }
 
 func (c *Config) BlockstoreS3Params() (blockparams.S3, error) {
+	return c.Blockstore.BlockstoreS3Params()
+}
+
+func (c *Config) BlockstoreLocalParams() (blockparams.Local, error) {
+	return c.Blockstore.BlockstoreLocalParams()
+}
+
+func (c *Config) BlockstoreGSParams() (blockparams.GS, error) {
+	return c.Blockstore.BlockstoreGSParams()
+}
+
+func (c *Config) BlockstoreAzureParams() (blockparams.Azure, error) {
+	return c.Blockstore.BlockstoreAzureParams()
+}
+
+// BlockstoreAdapters returns the additional named adapters.
+func (c *Config) BlockstoreAdapters() ([]blockparams.NamedAdapter, error) {
+	adapters := make([]blockparams.NamedAdapter, 0, len(c.Blockstore.Adapters))
+	for i := range c.Blockstore.Adapters {
+		a := &c.Blockstore.Adapters[i]
+		if a.Name == "" || a.Type == "" || len(a.NamespacePrefixes) == 0 {
+			return nil, fmt.Errorf("blockstore.adapters[%d]: %w: name, type and namespace_prefixes", i, ErrMissingRequiredKeys)
+		}
+		adapter, err := a.withDefaults(&c.Blockstore.BlockstoreAdapter)
+		if err != nil {
+			return nil, fmt.Errorf("blockstore.adapters[%d] %s: %w", i, a.Name, err)
+		}
+		adapters = append(adapters, blockparams.NamedAdapter{
+			Name:              a.Name,
+			NamespacePrefixes: a.NamespacePrefixes,
+			Config:            adapter,
+		})
+	}
+	return adapters, nil
+}
+
+func (c *Config) BlockstoreCompressionParams() blockparams.Compression {
+	return blockparams.Compression{Enabled: c.Blockstore.Compression.Enabled}
+}
+
+// BlockstoreEncryptionParams returns the client-side encryption settings,
+// reading the key from blockstore.encryption.key_file if it is set.
+func (c *Config) BlockstoreEncryptionParams() (blockparams.Encryption, error) {
+	e := c.Blockstore.Encryption
+	if !e.Enabled {
+		return blockparams.Encryption{}, nil
+	}
+	var key []byte
+	switch {
+	case e.SecretKey != "" && e.KeyFile != "":
+		return blockparams.Encryption{}, fmt.Errorf("%w: set only one of blockstore.encryption.secret_key and blockstore.encryption.key_file", ErrBadConfiguration)
+	case e.SecretKey != "":
+		key = []byte(e.SecretKey.SecureValue())
+	case e.KeyFile != "":
+		keyPath, err := homedir.Expand(e.KeyFile)
+		if err != nil {
+			return blockparams.Encryption{}, fmt.Errorf("parse encryption key file path %s: %w", e.KeyFile, err)
+		}
+		data, err := os.ReadFile(keyPath)
+		if err != nil {
+			return blockparams.Encryption{}, fmt.Errorf("read encryption key file: %w", err)
+		}
+		key = bytes.TrimSpace(data)
+	}
+	if len(key) == 0 {
+		return blockparams.Encryption{}, fmt.Errorf("%w: blockstore.encryption.secret_key or blockstore.encryption.key_file", ErrMissingRequiredKeys)
+	}
+	return blockparams.Encryption{
+		Enabled:         true,
+		Key:             key,
+		ReadUnencrypted: e.ReadUnencrypted,
+	}, nil
+}
+
+// withDefaults returns the configuration of a, taking unset sizes,
+// durations and regions from defaults.  Other settings are not inherited.
+func (a *NamedBlockstoreAdapter) withDefaults(defaults *BlockstoreAdapter) (*BlockstoreAdapter, error) {
+	b := a.BlockstoreAdapter
+	switch b.Type {
+	case "local":
+		if b.Local == nil || b.Local.Path == "" {
+			return nil, fmt.Errorf("%w: local.path", ErrMissingRequiredKeys)
+		}
+	case "s3":
+		if b.S3 == nil {
+			return nil, fmt.Errorf("%w: s3", ErrMissingRequiredKeys)
+		}
+		s3 := *b.S3
+		if s3.Region == "" {
+			s3.Region = defaults.S3.Region
+		}
+		if s3.StreamingChunkSize == 0 {
+			s3.StreamingChunkSize = defaults.S3.StreamingChunkSize
+		}
+		if s3.StreamingChunkTimeout == 0 {
+			s3.StreamingChunkTimeout = defaults.S3.StreamingChunkTimeout
+		}
+		if s3.MaxRetries == 0 {
+			s3.MaxRetries = defaults.S3.MaxRetries
+		}
+		if s3.PreSignedExpiry == 0 {
+			s3.PreSignedExpiry = defaults.S3.PreSignedExpiry
+		}
+		b.S3 = &s3
+	case "gs":
+		if b.GS == nil {
+			return nil, fmt.Errorf("%w: gs", ErrMissingRequiredKeys)
+		}
+		gs := *b.GS
+		if gs.S3Endpoint == "" {
+			gs.S3Endpoint = defaults.GS.S3Endpoint
+		}
+		if gs.PreSignedExpiry == 0 {
+			gs.PreSignedExpiry = defaults.GS.PreSignedExpiry
+		}
+		b.GS = &gs
+	case "azure":
+		if b.Azure == nil {
+			return nil, fmt.Errorf("%w: azure", ErrMissingRequiredKeys)
+		}
+		azure := *b.Azure
+		if azure.TryTimeout == 0 {
+			azure.TryTimeout = defaults.Azure.TryTimeout
+		}
+		if azure.PreSignedExpiry == 0 {
+			azure.PreSignedExpiry = defaults.Azure.PreSignedExpiry
+		}
+		b.Azure = &azure
+	}
+	return &b, nil
+}
+
+func (b *BlockstoreAdapter) BlockstoreType() string {
+	return b.Type
+}
+
+// BlockstoreAdapters returns no adapters: only the top-level blockstore
+// configures additional adapters.
+func (b *BlockstoreAdapter) BlockstoreAdapters() ([]blockparams.NamedAdapter, error) {
+	return nil, nil
+}
+
+// BlockstoreCompressionParams returns no compression: like encryption, it
+// is configured on Config.
+func (b *BlockstoreAdapter) BlockstoreCompressionParams() blockparams.Compression {
+	return blockparams.Compression{}
+}
+
+// BlockstoreEncryptionParams returns no encryption: encryption wraps the
+// adapters of all namespaces, and is configured on Config.
+func (b *BlockstoreAdapter) BlockstoreEncryptionParams() (blockparams.Encryption, error) {
+	return blockparams.Encryption{}, nil
+}
+
+func (b *BlockstoreAdapter) BlockstoreS3Params() (blockparams.S3, error) {
 	var webIdentity *blockparams.S3WebIdentity
-	if c.Blockstore.S3.WebIdentity != nil {
+	if b.S3.WebIdentity != nil {
 		webIdentity = &blockparams.S3WebIdentity{
-			SessionDuration:     c.Blockstore.S3.WebIdentity.SessionDuration,
-			SessionExpiryWindow: c.Blockstore.S3.WebIdentity.SessionExpiryWindow,
+			SessionDuration:     b.S3.WebIdentity.SessionDuration,
+			SessionExpiryWindow: b.S3.WebIdentity.SessionExpiryWindow,
+		}
+	}
+	defaultCredentials := b.S3.getCredentials()
+	endpoints := make([]blockparams.S3Endpoint, 0, len(b.S3.Endpoints))
+	for _, e := range b.S3.Endpoints {
+		if e.NamespacePrefix == "" {
+			return blockparams.S3{}, fmt.Errorf("blockstore.s3.endpoints %s: %w: namespace_prefix", e.Endpoint, ErrMissingRequiredKeys)
 		}
+		creds := e.getCredentials()
+		if creds == nil {
+			creds = defaultCredentials
+		}
+		region := e.Region
+		if region == "" {
+			region = b.S3.Region
+		}
+		endpoints = append(endpoints, blockparams.S3Endpoint{
+			NamespacePrefix: e.NamespacePrefix,
+			AwsConfig:       b.newAwsConfig(region, e.Endpoint, e.ForcePathStyle, creds),
+			Compat:          e.Compat,
+		})
 	}
 	return blockparams.S3{
-		AwsConfig:                     c.GetAwsConfig(),
-		StreamingChunkSize:            c.Blockstore.S3.StreamingChunkSize,
-		StreamingChunkTimeout:         c.Blockstore.S3.StreamingChunkTimeout,
-		DiscoverBucketRegion:          c.Blockstore.S3.DiscoverBucketRegion,
-		SkipVerifyCertificateTestOnly: c.Blockstore.S3.SkipVerifyCertificateTestOnly,
-		ServerSideEncryption:          c.Blockstore.S3.ServerSideEncryption,
-		ServerSideEncryptionKmsKeyID:  c.Blockstore.S3.ServerSideEncryptionKmsKeyID,
-		PreSignedExpiry:               c.Blockstore.S3.PreSignedExpiry,
-		DisablePreSigned:              c.Blockstore.S3.DisablePreSigned,
-		DisablePreSignedUI:            c.Blockstore.S3.DisablePreSignedUI,
+		AwsConfig:                     b.GetAwsConfig(),
+		StreamingChunkSize:            b.S3.StreamingChunkSize,
+		StreamingChunkTimeout:         b.S3.StreamingChunkTimeout,
+		DiscoverBucketRegion:          b.S3.DiscoverBucketRegion,
+		SkipVerifyCertificateTestOnly: b.S3.SkipVerifyCertificateTestOnly,
+		ServerSideEncryption:          b.S3.ServerSideEncryption,
+		ServerSideEncryptionKmsKeyID:  b.S3.ServerSideEncryptionKmsKeyID,
+		PreSignedExpiry:               b.S3.PreSignedExpiry,
+		DisablePreSigned:              b.S3.DisablePreSigned,
+		DisablePreSignedUI:            b.S3.DisablePreSignedUI,
+		Compat:                        b.S3.Compat,
+		Endpoints:                     endpoints,
 		WebIdentity:                   webIdentity,
 	}, nil
 }
 
-func (c *Config) BlockstoreLocalParams() (blockparams.Local, error) {
-	localPath := c.Blockstore.Local.Path
+func (b *BlockstoreAdapter) BlockstoreLocalParams() (blockparams.Local, error) {
+	localPath := b.Local.Path
 	path, err := homedir.Expand(localPath)
 	if err != nil {
 		return blockparams.Local{}, fmt.Errorf("parse blockstore location URI %s: %w", localPath, err)
 	}
 
-	params := blockparams.Local(*c.Blockstore.Local)
+	params := blockparams.Local(*b.Local)
 	params.Path = path
 	return params, nil
 }
 
-func (c *Config) BlockstoreGSParams() (blockparams.GS, error) {
-	credPath, err := homedir.Expand(c.Blockstore.GS.CredentialsFile)
+func (b *BlockstoreAdapter) BlockstoreGSParams() (blockparams.GS, error) {
+	credPath, err := homedir.Expand(b.GS.CredentialsFile)
 	if err != nil {
-		return blockparams.GS{}, fmt.Errorf("parse GS credentials path '%s': %w", c.Blockstore.GS.CredentialsFile, err)
+		return blockparams.GS{}, fmt.Errorf("parse GS credentials path '%s': %w", b.GS.CredentialsFile, err)
 	}
 	return blockparams.GS{
 		CredentialsFile:    credPath,
-		CredentialsJSON:    c.Blockstore.GS.CredentialsJSON,
-		PreSignedExpiry:    c.Blockstore.GS.PreSignedExpiry,
-		DisablePreSigned:   c.Blockstore.GS.DisablePreSigned,
-		DisablePreSignedUI: c.Blockstore.GS.DisablePreSignedUI,
+		CredentialsJSON:    b.GS.CredentialsJSON,
+		PreSignedExpiry:    b.GS.PreSignedExpiry,
+		DisablePreSigned:   b.GS.DisablePreSigned,
+		DisablePreSignedUI: b.GS.DisablePreSignedUI,
 	}, nil
 }
 
-func (c *Config) BlockstoreAzureParams() (blockparams.Azure, error) {
-	if c.Blockstore.Azure.AuthMethod != "" {
+func (b *BlockstoreAdapter) BlockstoreAzureParams() (blockparams.Azure, error) {
+	if b.Azure.AuthMethod != "" {
 		logging.ContextUnavailable().Warn("blockstore.azure.auth_method is deprecated. Value is no longer used.")
 	}
 	return blockparams.Azure{
-		StorageAccount:     c.Blockstore.Azure.StorageAccount,
-		StorageAccessKey:   c.Blockstore.Azure.StorageAccessKey,
-		TryTimeout:         c.Blockstore.Azure.TryTimeout,
-		PreSignedExpiry:    c.Blockstore.Azure.PreSignedExpiry,
-		TestEndpointURL:    c.Blockstore.Azure.TestEndpointURL,
-		DisablePreSigned:   c.Blockstore.Azure.DisablePreSigned,
-		DisablePreSignedUI: c.Blockstore.Azure.DisablePreSignedUI,
+		StorageAccount:     b.Azure.StorageAccount,
+		StorageAccessKey:   b.Azure.StorageAccessKey,
+		TryTimeout:         b.Azure.TryTimeout,
+		PreSignedExpiry:    b.Azure.PreSignedExpiry,
+		TestEndpointURL:    b.Azure.TestEndpointURL,
+		DisablePreSigned:   b.Azure.DisablePreSigned,
+		DisablePreSignedUI: b.Azure.DisablePreSignedUI,
 	}, nil
 }
 
@@ -6,14 +6,18 @@ import (
 	"encoding/json"
 	"errors"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/go-test/deep"
 	"github.com/spf13/viper"
+	"github.com/treeverse/lakefs/pkg/block/encrypt"
 	"github.com/treeverse/lakefs/pkg/block/factory"
 	"github.com/treeverse/lakefs/pkg/block/gs"
 	"github.com/treeverse/lakefs/pkg/block/local"
+	"github.com/treeverse/lakefs/pkg/block/multi"
 	s3a "github.com/treeverse/lakefs/pkg/block/s3"
 	"github.com/treeverse/lakefs/pkg/config"
 	"github.com/treeverse/lakefs/pkg/kv/kvparams"
@@ -128,6 +132,36 @@ func TestConfig_BuildBlockAdapter(t *testing.T) {
 		}
 	})
 
+	t.Run("s3 block adapter with endpoints", func(t *testing.T) {
+		c, err := newConfigFromFile("testdata/valid_s3_endpoints_adapter_config.yaml")
+		testutil.Must(t, err)
+		adapter, err := factory.BuildBlockAdapter(ctx, nil, c)
+		testutil.Must(t, err)
+		if _, ok := adapter.(*multi.Adapter); !ok {
+			t.Fatalf("expected a multi block adapter over the s3 endpoints, got %T instead", adapter)
+		}
+	})
+
+	t.Run("named block adapters", func(t *testing.T) {
+		c, err := newConfigFromFile("testdata/valid_multi_blockstore_config.yaml")
+		testutil.Must(t, err)
+		adapter, err := factory.BuildBlockAdapter(ctx, nil, c)
+		testutil.Must(t, err)
+		if _, ok := adapter.(*multi.Adapter); !ok {
+			t.Fatalf("expected a multi block adapter, got %T instead", adapter)
+		}
+	})
+
+	t.Run("encrypted block adapter", func(t *testing.T) {
+		c, err := newConfigFromFile("testdata/valid_encrypted_blockstore_config.yaml")
+		testutil.Must(t, err)
+		adapter, err := factory.BuildBlockAdapter(ctx, nil, c)
+		testutil.Must(t, err)
+		if _, ok := adapter.(*encrypt.Adapter); !ok {
+			t.Fatalf("expected an encrypting block adapter, got %T instead", adapter)
+		}
+	})
+
 	t.Run("gs block adapter", func(t *testing.T) {
 		c, err := newConfigFromFile("testdata/valid_gs_adapter_config.yaml")
 		testutil.Must(t, err)
@@ -139,6 +173,128 @@ func TestConfig_BuildBlockAdapter(t *testing.T) {
 	})
 }
 
+func TestConfig_S3Endpoints(t *testing.T) {
+	c, err := newConfigFromFile("testdata/valid_s3_endpoints_adapter_config.yaml")
+	testutil.Must(t, err)
+	s3Params, err := c.BlockstoreS3Params()
+	testutil.Must(t, err)
+
+	tests := []struct {
+		address          string
+		expectedEndpoint string
+		expectedRegion   string
+		expectedKeyID    string
+	}{
+		{address: "s3://aws-bucket/repo", expectedEndpoint: "", expectedRegion: "us-west-2", expectedKeyID: "my-key-id"},
+		{address: "s3://minio-bucket/repo", expectedEndpoint: "http://minio.example.com:9000", expectedRegion: "us-west-2", expectedKeyID: "my-key-id"},
+		{address: "s3://ceph-bucket/repo", expectedEndpoint: "http://rgw.example.com", expectedRegion: "default", expectedKeyID: "ceph-key-id"},
+		{address: "s3://ceph-bucket2/repo", expectedEndpoint: "", expectedRegion: "us-west-2", expectedKeyID: "my-key-id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			cfg := s3Params.AwsConfigFor(tt.address)
+			if endpoint := aws.StringValue(cfg.Endpoint); endpoint != tt.expectedEndpoint {
+				t.Errorf("got endpoint %s, expected %s", endpoint, tt.expectedEndpoint)
+			}
+			if region := aws.StringValue(cfg.Region); region != tt.expectedRegion {
+				t.Errorf("got region %s, expected %s", region, tt.expectedRegion)
+			}
+			creds, err := cfg.Credentials.Get()
+			testutil.Must(t, err)
+			if creds.AccessKeyID != tt.expectedKeyID {
+				t.Errorf("got access key ID %s, expected %s", creds.AccessKeyID, tt.expectedKeyID)
+			}
+		})
+	}
+}
+
+func TestConfig_BlockstoreAdapters(t *testing.T) {
+	c, err := newConfigFromFile("testdata/valid_multi_blockstore_config.yaml")
+	testutil.Must(t, err)
+	adapters, err := c.BlockstoreAdapters()
+	testutil.Must(t, err)
+	if len(adapters) != 2 {
+		t.Fatalf("got %d named adapters, expected 2", len(adapters))
+	}
+
+	archive := adapters[0]
+	if archive.Name != "archive" || archive.Config.BlockstoreType() != "s3" {
+		t.Errorf("got adapter %s of type %s, expected archive of type s3", archive.Name, archive.Config.BlockstoreType())
+	}
+	if diff := deep.Equal(archive.NamespacePrefixes, []string{"s3://archive-bucket", "s3://cold-bucket"}); diff != nil {
+		t.Errorf("namespace prefixes: %s", diff)
+	}
+	s3Params, err := archive.Config.BlockstoreS3Params()
+	testutil.Must(t, err)
+	if endpoint := aws.StringValue(s3Params.AwsConfig.Endpoint); endpoint != "http://minio.example.com:9000" {
+		t.Errorf("got endpoint %s, expected http://minio.example.com:9000", endpoint)
+	}
+	// region and sizes are inherited from the default adapter
+	if region := aws.StringValue(s3Params.AwsConfig.Region); region != "us-west-2" {
+		t.Errorf("got region %s, expected us-west-2", region)
+	}
+	defaultS3Params, err := c.BlockstoreS3Params()
+	testutil.Must(t, err)
+	if s3Params.StreamingChunkSize != defaultS3Params.StreamingChunkSize {
+		t.Errorf("got streaming chunk size %d, expected %d", s3Params.StreamingChunkSize, defaultS3Params.StreamingChunkSize)
+	}
+	creds, err := s3Params.AwsConfig.Credentials.Get()
+	testutil.Must(t, err)
+	if creds.AccessKeyID != "archive-key-id" {
+		t.Errorf("got access key ID %s, expected archive-key-id", creds.AccessKeyID)
+	}
+
+	scratch := adapters[1]
+	localParams, err := scratch.Config.BlockstoreLocalParams()
+	testutil.Must(t, err)
+	if localParams.Path != "/tmp/lakefs-scratch" {
+		t.Errorf("got local path %s, expected /tmp/lakefs-scratch", localParams.Path)
+	}
+}
+
+func TestConfig_BlockstoreEncryption(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key")
+	testutil.Must(t, os.WriteFile(keyFile, []byte("key from file\n"), 0o600))
+
+	tests := []struct {
+		name        string
+		enabled     bool
+		secretKey   string
+		keyFile     string
+		expectedKey string
+		expectedErr error
+	}{
+		{name: "disabled", enabled: false, secretKey: "ignored"},
+		{name: "secret_key", enabled: true, secretKey: "secret key", expectedKey: "secret key"},
+		{name: "key_file", enabled: true, keyFile: keyFile, expectedKey: "key from file"},
+		{name: "missing_key", enabled: true, expectedErr: config.ErrMissingRequiredKeys},
+		{name: "both_keys", enabled: true, secretKey: "secret key", keyFile: keyFile, expectedErr: config.ErrBadConfiguration},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := newConfigFromFile("testdata/valid_encrypted_blockstore_config.yaml")
+			testutil.Must(t, err)
+			c.Blockstore.Encryption.Enabled = tt.enabled
+			c.Blockstore.Encryption.SecretKey = config.SecureString(tt.secretKey)
+			c.Blockstore.Encryption.KeyFile = tt.keyFile
+
+			p, err := c.BlockstoreEncryptionParams()
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("got error %v, expected %v", err, tt.expectedErr)
+			}
+			if err != nil {
+				return
+			}
+			if p.Enabled != tt.enabled {
+				t.Errorf("got enabled %t, expected %t", p.Enabled, tt.enabled)
+			}
+			if string(p.Key) != tt.expectedKey {
+				t.Errorf("got key %q, expected %q", p.Key, tt.expectedKey)
+			}
+		})
+	}
+}
+
 func TestConfig_JSONLogger(t *testing.T) {
 	logfile := "/tmp/lakefs_json_logger_test.log"
 	_ = os.Remove(logfile)
@@ -129,6 +129,14 @@ func setDefaults(cfgType string) {
 	viper.SetDefault("graveler.commit_cache.size", 50_000)
 	viper.SetDefault("graveler.commit_cache.expiry", 10*time.Minute)
 	viper.SetDefault("graveler.commit_cache.jitter", 2*time.Second)
+	viper.SetDefault("graveler.compaction.enabled", false)
+	viper.SetDefault("graveler.compaction.interval", time.Minute)
+	viper.SetDefault("graveler.compaction.sealed_tokens_threshold", 10)
+
+	viper.SetDefault("replication.interval", 5*time.Minute)
+
+	viper.SetDefault("continuous_import.interval", time.Minute)
+	viper.SetDefault("continuous_import.max_events_per_commit", 100_000)
 
 	viper.SetDefault("plugins.default_path", "~/.lakefs/plugins")
 
@@ -171,6 +171,7 @@ const (
 	ERRLakeFSNotSupported
 	ERRLakeFSWrongEndpoint
 	ErrWriteToProtectedBranch
+	ErrWriteToReadOnlyRepository
 )
 
 type errorCodeMap map[APIErrorCode]APIError
@@ -758,4 +759,9 @@ var Codes = errorCodeMap{
 		Description:    "Attempted to write to a protected branch",
 		HTTPStatusCode: http.StatusForbidden,
 	},
+	ErrWriteToReadOnlyRepository: {
+		Code:           "ErrWriteToReadOnlyRepository",
+		Description:    "Attempted to write to a read-only or archived repository",
+		HTTPStatusCode: http.StatusForbidden,
+	},
 }
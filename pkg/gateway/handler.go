@@ -178,6 +178,9 @@ func RepoOperationHandler(sc *ServerContext, handler operations.RepoOperationHan
 		if authOp == nil {
 			return
 		}
+		if !checkRepositoryWritable(w, req, sc, o, repo.Name) {
+			return
+		}
 		repoOperation := &operations.RepoOperation{
 			AuthorizedOperation: authOp,
 			Repository:          repo,
@@ -213,6 +216,9 @@ func PathOperationHandler(sc *ServerContext, handler operations.PathOperationHan
 		if authOp == nil {
 			return
 		}
+		if !checkRepositoryWritable(w, req, sc, o, repo.Name) {
+			return
+		}
 
 		// run callback
 		operation := &operations.PathOperation{
@@ -236,6 +242,27 @@ func PathOperationHandler(sc *ServerContext, handler operations.PathOperationHan
 	})
 }
 
+// checkRepositoryWritable rejects requests that may change a read-only or
+// archived repository, before any data is uploaded.  It returns false if
+// it rejected the request.
+func checkRepositoryWritable(w http.ResponseWriter, req *http.Request, sc *ServerContext, o *operations.Operation, repository string) bool {
+	switch req.Method {
+	case http.MethodPut, http.MethodPost, http.MethodDelete:
+	default:
+		return true
+	}
+	state, err := sc.catalog.GetRepositoryAccessState(req.Context(), repository)
+	if err != nil {
+		_ = o.EncodeError(w, req, err, gatewayerrors.Codes.ToAPIErr(gatewayerrors.ErrInternalError))
+		return false
+	}
+	if err := state.WriteError(); err != nil {
+		_ = o.EncodeError(w, req, err, gatewayerrors.Codes.ToAPIErr(gatewayerrors.ErrWriteToReadOnlyRepository))
+		return false
+	}
+	return true
+}
+
 func authorize(w http.ResponseWriter, req *http.Request, authService auth.GatewayService, perms permissions.Node) *operations.AuthorizedOperation {
 	ctx := req.Context()
 	o := ctx.Value(ContextKeyOperation).(*operations.Operation)
@@ -28,6 +28,7 @@ func AuthenticationHandler(authService auth.GatewayService, next http.Handler) h
 		user, err := auth.GetUser(ctx)
 		if err == nil {
 			ctx = logging.AddFields(ctx, logging.Fields{logging.UserFieldKey: user.Username})
+			ctx = graveler.WithReflogUser(ctx, user.Username)
 			req = req.WithContext(auth.WithUser(ctx, user))
 			next.ServeHTTP(w, req)
 			return
@@ -70,6 +71,7 @@ func AuthenticationHandler(authService auth.GatewayService, next http.Handler) h
 			return
 		}
 		ctx = logging.AddFields(ctx, logging.Fields{logging.UserFieldKey: user.Username})
+		ctx = graveler.WithReflogUser(ctx, user.Username)
 		ctx = auth.WithUser(ctx, user)
 		ctx = context.WithValue(ctx, ContextKeyAuthContext, authContext)
 		req = req.WithContext(ctx)
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/compress"
 	"github.com/treeverse/lakefs/pkg/catalog"
 	gatewayerrors "github.com/treeverse/lakefs/pkg/gateway/errors"
 	"github.com/treeverse/lakefs/pkg/gateway/serde"
@@ -87,7 +88,7 @@ func (controller *GetObject) Handle(w http.ResponseWriter, req *http.Request, o
 		// assemble a response body (range-less query)
 		o.SetHeader(w, "Content-Type", entry.ContentType)
 		o.SetHeader(w, "Content-Length", fmt.Sprintf("%d", entry.Size))
-		data, err = o.BlockStore.Get(req.Context(), block.ObjectPointer{
+		data, err = o.BlockStore.Get(compress.WithEntry(req.Context(), entry.Metadata), block.ObjectPointer{
 			StorageNamespace: o.Repository.StorageNamespace,
 			IdentifierType:   entry.AddressType.ToIdentifierType(),
 			Identifier:       entry.PhysicalAddress,
@@ -100,7 +101,7 @@ func (controller *GetObject) Handle(w http.ResponseWriter, req *http.Request, o
 		contentRange := fmt.Sprintf("bytes %d-%d/%d", rng.StartOffset, rng.EndOffset, entry.Size)
 		o.SetHeader(w, "Content-Range", contentRange)
 		o.SetHeader(w, "Content-Length", fmt.Sprintf("%d", rng.Size()))
-		data, err = o.BlockStore.GetRange(req.Context(), block.ObjectPointer{
+		data, err = o.BlockStore.GetRange(compress.WithEntry(req.Context(), entry.Metadata), block.ObjectPointer{
 			StorageNamespace: o.Repository.StorageNamespace,
 			IdentifierType:   entry.AddressType.ToIdentifierType(),
 			Identifier:       entry.PhysicalAddress,
@@ -84,6 +84,10 @@ func (a *mockAdapter) AbortMultiPartUpload(_ context.Context, _ block.ObjectPoin
 	panic("try to abort multipart in mock adapter")
 }
 
+func (a *mockAdapter) ListParts(_ context.Context, _ block.ObjectPointer, _ string) ([]block.MultipartPart, error) {
+	panic("try to list parts in mock adapter")
+}
+
 func (a *mockAdapter) CompleteMultiPartUpload(_ context.Context, _ block.ObjectPointer, _ string, _ *block.MultipartUploadCompletion) (*block.CompleteMultiPartUploadResponse, error) {
 	panic("try to complete multipart in mock adapter")
 }
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/treeverse/lakefs/pkg/block/compress"
 	"github.com/treeverse/lakefs/pkg/catalog"
 	"github.com/treeverse/lakefs/pkg/logging"
 )
@@ -32,6 +33,16 @@ func amzMetaWriteHeaders(w http.ResponseWriter, metadata catalog.Metadata) {
 	}
 }
 
+// withRepositoryCodec returns req with a context that compresses data
+// written to the block adapter by the codec of the repository.
+func (o *RepoOperation) withRepositoryCodec(req *http.Request) (*http.Request, error) {
+	codec, err := o.Catalog.GetRepositoryCompression(req.Context(), o.Repository.Name)
+	if err != nil {
+		return nil, err
+	}
+	return req.WithContext(compress.WithCodec(req.Context(), codec)), nil
+}
+
 func (o *PathOperation) finishUpload(req *http.Request, checksum, physicalAddress string, size int64, relative bool, metadata map[string]string, contentType string) error {
 	// write metadata
 	writeTime := time.Now()
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/compress"
 	gatewayErrors "github.com/treeverse/lakefs/pkg/gateway/errors"
 	"github.com/treeverse/lakefs/pkg/gateway/multipart"
 	"github.com/treeverse/lakefs/pkg/gateway/path"
@@ -52,7 +53,13 @@ func (controller *PostObject) HandleCreateMultipartUpload(w http.ResponseWriter,
 	address := o.PathProvider.NewPath()
 	storageClass := StorageClassFromHeader(req.Header)
 	opts := block.CreateMultiPartUploadOpts{StorageClass: storageClass}
-	resp, err := o.BlockStore.CreateMultiPartUpload(req.Context(), block.ObjectPointer{
+	codecReq, err := o.withRepositoryCodec(req)
+	if err != nil {
+		o.Log(req).WithError(err).Error("could not get repository compression")
+		_ = o.EncodeError(w, req, err, gatewayErrors.Codes.ToAPIErr(gatewayErrors.ErrInternalError))
+		return
+	}
+	resp, err := o.BlockStore.CreateMultiPartUpload(codecReq.Context(), block.ObjectPointer{
 		StorageNamespace: o.Repository.StorageNamespace,
 		IdentifierType:   block.IdentifierTypeRelative,
 		Identifier:       address,
@@ -124,7 +131,14 @@ func (controller *PostObject) HandleCompleteMultipartUpload(w http.ResponseWrite
 		return
 	}
 	checksum := strings.Split(resp.ETag, "-")[0]
-	err = o.finishUpload(req, checksum, objName, resp.ContentLength, true, multiPart.Metadata, multiPart.ContentType)
+	codecReq, err := o.withRepositoryCodec(req)
+	if err != nil {
+		o.Log(req).WithError(err).Error("could not get repository compression")
+		_ = o.EncodeError(w, req, err, gatewayErrors.Codes.ToAPIErr(gatewayErrors.ErrInternalError))
+		return
+	}
+	metadata := compress.MultipartEntryMetadata(codecReq.Context(), o.BlockStore, uploadID, multiPart.Metadata, resp.ContentLength)
+	err = o.finishUpload(req, checksum, objName, resp.ContentLength, true, metadata, multiPart.ContentType)
 	if errors.Is(err, graveler.ErrWriteToProtectedBranch) {
 		_ = o.EncodeError(w, req, err, gatewayErrors.Codes.ToAPIErr(gatewayErrors.ErrWriteToProtectedBranch))
 		return
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/block/compress"
 	"github.com/treeverse/lakefs/pkg/catalog"
 	gatewayErrors "github.com/treeverse/lakefs/pkg/gateway/errors"
 	"github.com/treeverse/lakefs/pkg/gateway/path"
@@ -168,19 +169,21 @@ func handleUploadPart(w http.ResponseWriter, req *http.Request, o *PathOperation
 			Identifier:       multiPart.PhysicalAddress,
 		}
 
+		// the source is read as its entry records
+		copyCtx := compress.WithEntry(req.Context(), ent.Metadata)
 		var resp *block.UploadPartResponse
 		if rang := req.Header.Get(CopySourceRangeHeader); rang != "" {
 			// if this is a copy part with a byte range:
 			parsedRange, parseErr := httputil.ParseRange(rang, ent.Size)
 			if parseErr != nil {
 				// invalid range will silently fall back to copying the entire object. ¯\_(ツ)_/¯
-				resp, err = o.BlockStore.UploadCopyPart(req.Context(), src, dst, uploadID, partNumber)
+				resp, err = o.BlockStore.UploadCopyPart(copyCtx, src, dst, uploadID, partNumber)
 			} else {
-				resp, err = o.BlockStore.UploadCopyPartRange(req.Context(), src, dst, uploadID, partNumber, parsedRange.StartOffset, parsedRange.EndOffset)
+				resp, err = o.BlockStore.UploadCopyPartRange(copyCtx, src, dst, uploadID, partNumber, parsedRange.StartOffset, parsedRange.EndOffset)
 			}
 		} else {
 			// normal copy part that accepts another object and no byte range:
-			resp, err = o.BlockStore.UploadCopyPart(req.Context(), src, dst, uploadID, partNumber)
+			resp, err = o.BlockStore.UploadCopyPart(copyCtx, src, dst, uploadID, partNumber)
 		}
 
 		if err != nil {
@@ -226,6 +229,13 @@ func (controller *PutObject) Handle(w http.ResponseWriter, req *http.Request, o
 		_ = o.EncodeError(w, req, err, gatewayErrors.Codes.ToAPIErr(gatewayErrors.ErrNoSuchBucket))
 		return
 	}
+	codecReq, err := o.withRepositoryCodec(req)
+	if err != nil {
+		o.Log(req).WithError(err).Error("could not get repository compression")
+		_ = o.EncodeError(w, req, err, gatewayErrors.Codes.ToAPIErr(gatewayErrors.ErrInternalError))
+		return
+	}
+	req = codecReq
 
 	query := req.URL.Query()
 
@@ -266,7 +276,7 @@ func handlePut(w http.ResponseWriter, req *http.Request, o *PathOperation) {
 	}
 
 	// write metadata
-	metadata := amzMetaAsMetadata(req)
+	metadata := compress.EntryMetadata(req.Context(), o.BlockStore, amzMetaAsMetadata(req), blob.Size)
 	contentType := req.Header.Get("Content-Type")
 	err = o.finishUpload(req, blob.Checksum, blob.PhysicalAddress, blob.Size, true, metadata, contentType)
 	if errors.Is(err, graveler.ErrWriteToProtectedBranch) {
@@ -340,3 +340,106 @@ func (c *committedManager) GetRangeIDByKey(ctx context.Context, ns graveler.Stor
 	}
 	return graveler.RangeID(r.ID), nil
 }
+
+func (c *committedManager) ListRanges(ctx context.Context, ns graveler.StorageNamespace, id graveler.MetaRangeID) ([]graveler.RangeID, error) {
+	it, err := c.metaRangeManager.NewMetaRangeIterator(ctx, ns, id)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var ids []graveler.RangeID
+	for it.NextRange() {
+		_, rng := it.Value()
+		ids = append(ids, graveler.RangeID(rng.ID))
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("list ranges of %s: %w", id, err)
+	}
+	return ids, nil
+}
+
+func (c *committedManager) CloneMetaRange(ctx context.Context, src, dst graveler.StorageNamespace, id graveler.MetaRangeID, rewrite graveler.ValueRewriteFunc) (graveler.MetaRangeID, error) {
+	it, err := c.metaRangeManager.NewMetaRangeIterator(ctx, src, id)
+	if err != nil {
+		return "", fmt.Errorf("get metarange ns=%s id=%s: %w", src, id, err)
+	}
+	defer it.Close()
+
+	mwWriter := c.metaRangeManager.NewWriter(ctx, dst, nil)
+	defer func() {
+		if err := mwWriter.Abort(); err != nil {
+			logging.FromContext(ctx).WithError(err).Error("Abort failed after CloneMetaRange")
+		}
+	}()
+	for it.NextRange() {
+		_, rng := it.Value()
+		if err := c.cloneRange(ctx, src, dst, rng, rewrite, mwWriter); err != nil {
+			return "", err
+		}
+	}
+	if err := it.Err(); err != nil {
+		return "", fmt.Errorf("clone metarange ns=%s id=%s: %w", src, id, err)
+	}
+	newID, err := mwWriter.Close(ctx)
+	if err != nil {
+		return "", fmt.Errorf("close writer ns=%s: %w", dst, err)
+	}
+	return *newID, nil
+}
+
+// cloneRange adds the range rng of src to the MetaRange written by writer in dst, passing every
+// record through rewrite exactly once.  Unchanged ranges are copied to dst under the same ID.
+// Records are held until the first change, and written once a change shows that the range must be
+// rewritten.
+func (c *committedManager) cloneRange(ctx context.Context, src, dst graveler.StorageNamespace, rng *Range, rewrite graveler.ValueRewriteFunc, writer MetaRangeWriter) error {
+	it, err := c.RangeManager.NewRangeIterator(ctx, Namespace(src), rng.ID)
+	if err != nil {
+		return fmt.Errorf("get range ns=%s id=%s: %w", src, rng.ID, err)
+	}
+	defer it.Close()
+	var (
+		unchanged []graveler.ValueRecord
+		changed   bool
+	)
+	for it.Next() {
+		rec := it.Value()
+		value, err := UnmarshalValue(rec.Value)
+		if err != nil {
+			return fmt.Errorf("unmarshal value of %s: %w", rec.Key, err)
+		}
+		record := graveler.ValueRecord{Key: graveler.Key(rec.Key.Copy()), Value: value}
+		newValue, err := rewrite(&record)
+		if err != nil {
+			return err
+		}
+		if newValue != nil && !changed {
+			changed = true
+			for _, r := range unchanged {
+				if err := writer.WriteRecord(r); err != nil {
+					return fmt.Errorf("write record: %w", err)
+				}
+			}
+			unchanged = nil
+		}
+		if !changed {
+			unchanged = append(unchanged, record)
+			continue
+		}
+		if newValue != nil {
+			record.Value = newValue
+		}
+		if err := writer.WriteRecord(record); err != nil {
+			return fmt.Errorf("write record: %w", err)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return fmt.Errorf("read range ns=%s id=%s: %w", src, rng.ID, err)
+	}
+	if changed {
+		return nil
+	}
+	if err := c.RangeManager.Copy(ctx, Namespace(src), Namespace(dst), rng.ID); err != nil {
+		return fmt.Errorf("copy range %s: %w", rng.ID, err)
+	}
+	return writer.WriteRange(*rng)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/graveler/committed"
 	"github.com/treeverse/lakefs/pkg/graveler/committed/mock"
+	"github.com/treeverse/lakefs/pkg/graveler/testutil"
 )
 
 func TestManager_WriteRange(t *testing.T) {
@@ -137,6 +138,70 @@ func TestManager_WriteMetaRange(t *testing.T) {
 	}
 }
 
+func TestManager_CloneMetaRange(t *testing.T) {
+	const (
+		src = graveler.StorageNamespace("src-ns")
+		dst = graveler.StorageNamespace("dst-ns")
+	)
+	ctx := context.Background()
+	metaRangeID := graveler.MetaRangeID("template")
+	clonedID := graveler.MetaRangeID("clone")
+
+	value := func(data string) *graveler.Value {
+		return &graveler.Value{Identity: []byte(data), Data: []byte(data)}
+	}
+	record := func(key, data string) committed.Record {
+		v, err := committed.MarshalValue(value(data))
+		require.NoError(t, err)
+		return committed.Record{Key: committed.Key(key), Value: v}
+	}
+	shared := &committed.Range{ID: "shared", MinKey: committed.Key("a"), MaxKey: committed.Key("b"), Count: 2}
+	rewritten := &committed.Range{ID: "rewritten", MinKey: committed.Key("c"), MaxKey: committed.Key("d"), Count: 2}
+
+	ctrl := gomock.NewController(t)
+	metarangeManager := mock.NewMockMetaRangeManager(ctrl)
+	rangeManager := mock.NewMockRangeManager(ctrl)
+	metarangeWriter := mock.NewMockMetaRangeWriter(ctrl)
+
+	metaRangeIt := testutil.NewFakeIterator().
+		AddRange(shared).
+		AddValueRecords(&graveler.ValueRecord{Key: graveler.Key("a")}, &graveler.ValueRecord{Key: graveler.Key("b")}).
+		AddRange(rewritten).
+		AddValueRecords(&graveler.ValueRecord{Key: graveler.Key("c")}, &graveler.ValueRecord{Key: graveler.Key("d")})
+	metarangeManager.EXPECT().NewMetaRangeIterator(ctx, src, metaRangeID).Return(metaRangeIt, nil)
+	metarangeManager.EXPECT().NewWriter(ctx, dst, nil).Return(metarangeWriter)
+
+	rangeManager.EXPECT().NewRangeIterator(ctx, committed.Namespace(src), shared.ID).
+		Return(testutil.NewCommittedValueIteratorFake([]committed.Record{record("a", "a"), record("b", "b")}), nil)
+	rangeManager.EXPECT().Copy(ctx, committed.Namespace(src), committed.Namespace(dst), shared.ID).Return(nil)
+	metarangeWriter.EXPECT().WriteRange(*shared).Return(nil)
+
+	rangeManager.EXPECT().NewRangeIterator(ctx, committed.Namespace(src), rewritten.ID).
+		Return(testutil.NewCommittedValueIteratorFake([]committed.Record{record("c", "c"), record("d", "d")}), nil)
+	// records before the first change are written once the range turns out to change
+	gomock.InOrder(
+		metarangeWriter.EXPECT().WriteRecord(graveler.ValueRecord{Key: graveler.Key("c"), Value: value("c")}).Return(nil),
+		metarangeWriter.EXPECT().WriteRecord(graveler.ValueRecord{Key: graveler.Key("d"), Value: value("new-d")}).Return(nil),
+	)
+
+	metarangeWriter.EXPECT().Close(ctx).Return(&clonedID, nil)
+	metarangeWriter.EXPECT().Abort().Return(nil)
+
+	sut := committed.NewCommittedManager(metarangeManager, rangeManager, params)
+	var rewrites []string
+	id, err := sut.CloneMetaRange(ctx, src, dst, metaRangeID, func(record *graveler.ValueRecord) (*graveler.Value, error) {
+		rewrites = append(rewrites, string(record.Key))
+		if string(record.Key) == "d" {
+			return value("new-d"), nil
+		}
+		return nil, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, clonedID, id)
+	// rewrite may copy objects, so it sees every record exactly once
+	require.Equal(t, []string{"a", "b", "c", "d"}, rewrites)
+}
+
 func min(x, y int) int {
 	if x < y {
 		return x
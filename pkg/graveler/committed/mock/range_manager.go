@@ -125,6 +125,20 @@ func (m *MockRangeManager) EXPECT() *MockRangeManagerMockRecorder {
 	return m.recorder
 }
 
+// Copy mocks base method.
+func (m *MockRangeManager) Copy(ctx context.Context, src, dst committed.Namespace, id committed.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Copy", ctx, src, dst, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Copy indicates an expected call of Copy.
+func (mr *MockRangeManagerMockRecorder) Copy(ctx, src, dst, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Copy", reflect.TypeOf((*MockRangeManager)(nil).Copy), ctx, src, dst, id)
+}
+
 // Exists mocks base method.
 func (m *MockRangeManager) Exists(ctx context.Context, ns committed.Namespace, id committed.ID) (bool, error) {
 	m.ctrl.T.Helper()
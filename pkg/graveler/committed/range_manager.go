@@ -62,6 +62,10 @@ type RangeManager interface {
 	// GetURI returns a URI from which to read the contents of id.  If id does not exist
 	// it may return a URI that resolves nowhere rather than an error.
 	GetURI(ctx context.Context, ns Namespace, id ID) (string, error)
+
+	// Copy makes the Range referenced by id in namespace src available under the same id in
+	// namespace dst.  Ranges are immutable, so nothing is copied if dst already holds id.
+	Copy(ctx context.Context, src, dst Namespace, id ID) error
 }
 
 // WriteResult is the result of a completed write of a Range
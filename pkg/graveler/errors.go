@@ -19,6 +19,7 @@ var (
 	ErrWriteToProtectedBranch       = wrapError(ErrProtectedBranch, "cannot write to protected branch")
 	ErrReadingFromStore             = errors.New("cannot read from store")
 	ErrCommitToProtectedBranch      = wrapError(ErrProtectedBranch, "cannot commit to protected branch")
+	ErrUnsignedCommit               = wrapError(ErrProtectedBranch, "protected branch requires signed commits")
 	ErrInvalidValue                 = fmt.Errorf("invalid value: %w", ErrInvalid)
 	ErrInvalidMergeBase             = fmt.Errorf("only 2 commits allowed in FindMergeBase: %w", ErrInvalidValue)
 	ErrNoCommitGeneration           = errors.New("no commit generation")
@@ -33,9 +34,14 @@ var (
 	ErrInvalidRepositoryID          = fmt.Errorf("repository id: %w", ErrInvalidValue)
 	ErrRequiredValue                = fmt.Errorf("required value: %w", ErrInvalid)
 	ErrCommitNotFound               = fmt.Errorf("commit %w", ErrNotFound)
+	ErrCommitSignatureNotFound      = fmt.Errorf("commit signature %w", ErrNotFound)
+	ErrCommitSignatureExists        = fmt.Errorf("commit already signed: %w", ErrNotUnique)
 	ErrCreateBranchNoCommit         = fmt.Errorf("can't create a branch without commit")
 	ErrRepositoryNotFound           = fmt.Errorf("repository %w", ErrNotFound)
 	ErrRepositoryInDeletion         = errors.New("repository in deletion")
+	ErrReadOnlyRepository           = wrapError(ErrUserVisible, "repository is read-only")
+	ErrArchivedRepository           = wrapError(ErrReadOnlyRepository, "repository is archived")
+	ErrInvalidAccessState           = fmt.Errorf("repository access state: %w", ErrInvalidValue)
 	ErrBranchNotFound               = fmt.Errorf("branch %w", ErrNotFound)
 	ErrTagNotFound                  = fmt.Errorf("tag %w", ErrNotFound)
 	ErrNoChanges                    = wrapError(ErrUserVisible, "no changes")
@@ -48,14 +54,19 @@ var (
 	ErrDirtyBranch                  = wrapError(ErrUserVisible, "uncommitted changes (dirty branch)")
 	ErrMetaRangeNotFound            = errors.New("metarange not found")
 	ErrLockNotAcquired              = errors.New("lock not acquired")
+	ErrBranchLocked                 = fmt.Errorf("branch held by a transaction: %w", ErrLockNotAcquired)
+	ErrTransactionNotFound          = fmt.Errorf("transaction %w", ErrNotFound)
+	ErrTransactionAborted           = wrapError(ErrUserVisible, "transaction aborted")
 	ErrRevertMergeNoParent          = wrapError(ErrUserVisible, "must specify 1-based parent number for reverting merge commit")
 	ErrCherryPickMergeNoParent      = wrapError(ErrUserVisible, "must specify 1-based parent number for cherry-picking merge commit")
+	ErrNotAncestor                  = wrapError(ErrUserVisible, "commit is not in the first-parent history of the branch")
 	ErrAddCommitNoParent            = errors.New("added commit must have a parent")
 	ErrMultipleParents              = errors.New("cannot have more than a single parent")
 	ErrParentOutOfRange             = errors.New("given commit does not have the given parent number")
 	ErrDereferenceCommitWithStaging = wrapError(ErrUserVisible, "reference to staging area with $ is not a commit")
 	ErrDeleteDefaultBranch          = wrapError(ErrUserVisible, "cannot delete repository default branch")
 	ErrCommitMetaRangeDirtyBranch   = wrapError(ErrUserVisible, "cannot use source MetaRange on a branch with uncommitted changes")
+	ErrBranchHeadMoved              = fmt.Errorf("branch head moved: %w", ErrPreconditionFailed)
 	ErrTooManyTries                 = errors.New("too many tries")
 	ErrSkipValueUpdate              = errors.New("skip value update")
 	ErrImport                       = wrapError(ErrUserVisible, "import error")
@@ -5,7 +5,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -14,6 +17,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/rs/xid"
+	"github.com/treeverse/lakefs/pkg/cache"
 	"github.com/treeverse/lakefs/pkg/ident"
 	"github.com/treeverse/lakefs/pkg/kv"
 	"github.com/treeverse/lakefs/pkg/logging"
@@ -64,11 +68,18 @@ const (
 	RefModTypeCaret  RefModType = '^'
 	RefModTypeAt     RefModType = '@'
 	RefModTypeDollar RefModType = '$'
+	// RefModTypeAsOf is the '@{<timestamp>}' modifier, resolving to the last first-parent ancestor committed at or
+	// before the timestamp
+	RefModTypeAsOf RefModType = '{'
+	// RefModTypeReflog is the '@{<n>}' modifier, resolving to the n'th previous head of a branch in its reflog
+	RefModTypeReflog RefModType = '#'
 )
 
 type RefModifier struct {
 	Type  RefModType
 	Value int
+	// Time is the timestamp of a RefModTypeAsOf modifier
+	Time time.Time
 }
 
 // RawRef is a parsed Ref that includes 'BaseRef' that holds the branch/tag/hash and a list of
@@ -246,6 +257,27 @@ type ImportStatus struct {
 	Error       error
 }
 
+// TransactionID represents a multi-branch commit transaction in the ref-store
+type TransactionID string
+
+// Transaction is a commit of multiple branches, possibly in different repositories.  Either all of its branches are
+// committed, or none are.
+type Transaction struct {
+	ID     TransactionID
+	Status TransactionStatus
+	// Deadline after which a pending transaction may be aborted
+	Deadline time.Time
+	Branches []TransactionBranch
+}
+
+// TransactionBranch is the commit of a single branch in a Transaction
+type TransactionBranch struct {
+	RepositoryID RepositoryID
+	BranchID     BranchID
+	CommitID     CommitID
+	SealedTokens []StagingToken
+}
+
 // StagingToken represents a namespace for writes to apply as uncommitted
 type StagingToken string
 
@@ -268,7 +300,60 @@ type Repository struct {
 
 type RepositoryMetadata map[string]string
 
-const MetadataKeyLastImportTimeStamp = ".lakefs.last.import.timestamp"
+const (
+	MetadataKeyLastImportTimeStamp = ".lakefs.last.import.timestamp"
+	// MetadataKeyCompression holds the codec that compresses data written
+	// to the repository.
+	MetadataKeyCompression = ".lakefs.compression"
+	// MetadataKeyAccessState holds the RepositoryAccessState of the
+	// repository.  Repositories without it are active.
+	MetadataKeyAccessState = ".lakefs.access.state"
+)
+
+// RepositoryAccessState controls which changes a repository accepts.  Read-only and archived
+// repositories reject every change to their data and refs; archived repositories are also hidden
+// from repository listings and skipped by garbage collection.
+type RepositoryAccessState string
+
+const (
+	RepositoryAccessStateActive   RepositoryAccessState = "active"
+	RepositoryAccessStateReadOnly RepositoryAccessState = "read-only"
+	RepositoryAccessStateArchived RepositoryAccessState = "archived"
+)
+
+func (s RepositoryAccessState) String() string {
+	return string(s)
+}
+
+// WriteError returns the error for changing a repository in state s, or nil if it accepts changes
+func (s RepositoryAccessState) WriteError() error {
+	switch s {
+	case RepositoryAccessStateReadOnly:
+		return ErrReadOnlyRepository
+	case RepositoryAccessStateArchived:
+		return ErrArchivedRepository
+	default:
+		return nil
+	}
+}
+
+// ParseRepositoryAccessState returns the RepositoryAccessState named s
+func ParseRepositoryAccessState(s string) (RepositoryAccessState, error) {
+	switch state := RepositoryAccessState(s); state {
+	case RepositoryAccessStateActive, RepositoryAccessStateReadOnly, RepositoryAccessStateArchived:
+		return state, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrInvalidAccessState, s)
+	}
+}
+
+// AccessStateFromMetadata returns the RepositoryAccessState recorded in repository metadata
+func AccessStateFromMetadata(metadata RepositoryMetadata) RepositoryAccessState {
+	if state, ok := metadata[MetadataKeyAccessState]; ok {
+		return RepositoryAccessState(state)
+	}
+	return RepositoryAccessStateActive
+}
 
 func NewRepository(storageNamespace StorageNamespace, defaultBranchID BranchID) Repository {
 	return Repository{
@@ -386,6 +471,9 @@ type Branch struct {
 	StagingToken StagingToken
 	// SealedTokens - Staging tokens are appended to the front, this allows building the diff iterator easily
 	SealedTokens []StagingToken
+	// Transaction - pending multi-branch commit that holds the branch: its head may not move until the transaction
+	// ends
+	Transaction TransactionID
 }
 
 // BranchRecord holds BranchID with the associated Branch data
@@ -394,9 +482,35 @@ type BranchRecord struct {
 	*Branch
 }
 
+// ReflogEntry records a movement of a branch head
+type ReflogEntry struct {
+	// ID orders the entries of a branch, newest first
+	ID           string
+	BranchID     BranchID
+	OldCommitID  CommitID
+	NewCommitID  CommitID
+	Operation    string
+	User         string
+	CreationDate time.Time
+}
+
+// CommitSignature is a signature over the ID of a commit by a key of its committer.  Signatures are verified when
+// they are added, and stored next to their commits.
+type CommitSignature struct {
+	CommitID CommitID
+	// KeyFingerprint is the SHA256 fingerprint of the committer's public key that signed the commit
+	KeyFingerprint string
+	// Signature is the signature over the commit ID, in the SSH wire format
+	Signature    []byte
+	CreationDate time.Time
+}
+
 // BranchUpdateFunc Used to pass validation call back to ref manager for UpdateBranch flow
 type BranchUpdateFunc func(*Branch) (*Branch, error)
 
+// TransactionUpdateFunc Used to pass validation call back to ref manager for TransactionUpdate flow
+type TransactionUpdateFunc func(*Transaction) (*Transaction, error)
+
 // ValueUpdateFunc Used to pass validation call back to staging manager for UpdateValue flow
 type ValueUpdateFunc func(*Value) (*Value, error)
 
@@ -485,6 +599,11 @@ type VersionController interface {
 	// CreateBareRepository stores a new Repository under RepositoryID with no initial branch or commit
 	CreateBareRepository(ctx context.Context, repositoryID RepositoryID, storageNamespace StorageNamespace, defaultBranchID BranchID) (*RepositoryRecord, error)
 
+	// CreateRepositoryFromTemplate stores a new Repository under RepositoryID whose default branch points at a single
+	// commit holding the content of commitID in template.  Values are cloned into the new storage namespace through
+	// rewrite, and the branch protection and garbage collection rules of template are copied.
+	CreateRepositoryFromTemplate(ctx context.Context, repositoryID RepositoryID, storageNamespace StorageNamespace, defaultBranchID BranchID, template *RepositoryRecord, commitID CommitID, params CommitParams, rewrite ValueRewriteFunc) (*RepositoryRecord, error)
+
 	// ListRepositories returns iterator to scan repositories
 	ListRepositories(ctx context.Context) (RepositoryIterator, error)
 
@@ -494,6 +613,14 @@ type VersionController interface {
 	// GetRepositoryMetadata returns repository user metadata
 	GetRepositoryMetadata(ctx context.Context, repositoryID RepositoryID) (RepositoryMetadata, error)
 
+	// SetRepositoryMetadata updates repository metadata using updateFunc
+	SetRepositoryMetadata(ctx context.Context, repository *RepositoryRecord, updateFunc RepoMetadataUpdateFunc) error
+
+	// GetRepositoryAccessState returns the access state recorded in the repository metadata.  It is cached: a change
+	// made by another lakeFS instance applies once the cached state expires, except to branch updates, which check
+	// the recorded state again.
+	GetRepositoryAccessState(ctx context.Context, repository *RepositoryRecord) (RepositoryAccessState, error)
+
 	// CreateBranch creates branch on repository pointing to ref
 	CreateBranch(ctx context.Context, repository *RepositoryRecord, branchID BranchID, ref Ref) (*Branch, error)
 
@@ -518,20 +645,44 @@ type VersionController interface {
 	// Log returns an iterator starting at commit ID up to repository root
 	Log(ctx context.Context, repository *RepositoryRecord, commitID CommitID, firstParent bool) (CommitIterator, error)
 
+	// SignCommit adds a signature to an existing commit.  A commit is signed at most once.  The signature must be
+	// verified by the caller.
+	SignCommit(ctx context.Context, repository *RepositoryRecord, signature CommitSignature) error
+
+	// GetCommitSignature returns the signature of a commit, or ErrCommitSignatureNotFound if it is not signed
+	GetCommitSignature(ctx context.Context, repository *RepositoryRecord, commitID CommitID) (*CommitSignature, error)
+
 	// ListBranches lists branches on repositories
 	ListBranches(ctx context.Context, repository *RepositoryRecord) (BranchIterator, error)
 
-	// DeleteBranch deletes branch from repository
+	// ListBranchReflog lists the movements of the head of a branch, newest first
+	ListBranchReflog(ctx context.Context, repository *RepositoryRecord, branchID BranchID) (ReflogIterator, error)
+
+	// DeleteBranch deletes branch from repository, unless a pending transaction holds it
 	DeleteBranch(ctx context.Context, repository *RepositoryRecord, branchID BranchID) error
 
 	// Commit the staged data and returns a commit ID that references that change
 	//   ErrNothingToCommit in case there is no data in stage
 	Commit(ctx context.Context, repository *RepositoryRecord, branchID BranchID, commitParams CommitParams) (CommitID, error)
 
+	// CommitChanges commits changes on top of parentID, which must be the head of branchID, and returns the ID of
+	// the new head.  The staging area of the branch is neither committed nor changed.  Changes with a nil Value
+	// delete their key.  It fails with ErrBranchHeadMoved if the head of the branch is no longer parentID, and with
+	// ErrNoChanges if changes leave the parent as is.
+	CommitChanges(ctx context.Context, repository *RepositoryRecord, branchID BranchID, parentID CommitID, changes ValueIterator, commitParams CommitParams) (CommitID, error)
+
+	// CommitBranches commits the staged data of multiple branches atomically, and returns their commit IDs in the
+	// same order.  Either all branches are committed, or none are.
+	CommitBranches(ctx context.Context, commits []BranchCommit) ([]CommitID, error)
+
 	// WriteMetaRangeByIterator accepts a ValueIterator and writes the entire iterator to a new MetaRange
 	// and returns the result ID.
 	WriteMetaRangeByIterator(ctx context.Context, repository *RepositoryRecord, it ValueIterator) (*MetaRangeID, error)
 
+	// ApplyMetaRangeChanges writes a new MetaRange holding metaRangeID with changes applied to it: changes with
+	// a nil Value delete their key.  Ranges that changes do not touch are reused.
+	ApplyMetaRangeChanges(ctx context.Context, repository *RepositoryRecord, metaRangeID MetaRangeID, changes ValueIterator) (*MetaRangeID, error)
+
 	// AddCommit creates a dangling (no referencing branch) commit in the repo from the pre-existing commit.
 	// Returns ErrMetaRangeNotFound if the referenced metaRangeID doesn't exist.
 	AddCommit(ctx context.Context, repository *RepositoryRecord, commit Commit) (CommitID, error)
@@ -563,6 +714,12 @@ type VersionController interface {
 	// CherryPick creates a patch to the commit given as 'ref', and applies it as a new commit on the given branch.
 	CherryPick(ctx context.Context, repository *RepositoryRecord, id BranchID, reference Ref, number *int, committer string) (CommitID, error)
 
+	// Squash replaces the commits on the branch after 'from' with a single commit, with the same content as the branch.
+	Squash(ctx context.Context, repository *RepositoryRecord, branchID BranchID, from Ref, commitParams CommitParams) (CommitID, error)
+
+	// Rewrite rebuilds the history of the branch without the commits given in 'exclude', and returns the new head.
+	Rewrite(ctx context.Context, repository *RepositoryRecord, branchID BranchID, exclude []Ref, committer string) (CommitID, error)
+
 	// Merge merges 'source' into 'destination' and returns the commit id for the created merge commit.
 	Merge(ctx context.Context, repository *RepositoryRecord, destination BranchID, source Ref, commitParams CommitParams, strategy string) (CommitID, error)
 
@@ -647,6 +804,8 @@ type Plumbing interface {
 	GetMetaRange(ctx context.Context, repository *RepositoryRecord, metaRangeID MetaRangeID) (MetaRangeAddress, error)
 	// GetRange returns information where rangeID is stored.
 	GetRange(ctx context.Context, repository *RepositoryRecord, rangeID RangeID) (RangeAddress, error)
+	// ListRanges returns the IDs of the ranges of metaRangeID.
+	ListRanges(ctx context.Context, repository *RepositoryRecord, metaRangeID MetaRangeID) ([]RangeID, error)
 	// WriteRange creates a new Range from the iterator values.
 	// Keeps Range closing logic, so might not flush all values to the range.
 	// Returns the created range info and in addition a list of records which were skipped due to out of order listing
@@ -664,13 +823,24 @@ type Dumper interface {
 	// DumpCommits iterates through all commits and dumps them in Graveler format
 	DumpCommits(ctx context.Context, repository *RepositoryRecord) (*MetaRangeID, error)
 
+	// DumpCommitRecords dumps the commits records in Graveler format
+	DumpCommitRecords(ctx context.Context, repository *RepositoryRecord, commits []*CommitRecord) (*MetaRangeID, error)
+
 	// DumpBranches iterates through all branches and dumps them in Graveler format
 	DumpBranches(ctx context.Context, repository *RepositoryRecord) (*MetaRangeID, error)
 
+	// DumpBranchRecords dumps the branches records in Graveler format
+	DumpBranchRecords(ctx context.Context, repository *RepositoryRecord, branches []*BranchRecord) (*MetaRangeID, error)
+
 	// DumpTags iterates through all tags and dumps them in Graveler format
 	DumpTags(ctx context.Context, repository *RepositoryRecord) (*MetaRangeID, error)
+
+	// DumpTagRecords dumps the tags records in Graveler format
+	DumpTagRecords(ctx context.Context, repository *RepositoryRecord, tags []*TagRecord) (*MetaRangeID, error)
 }
 
+// Loader loads dumped refs into a repository.  It does not check the access state of the repository: the replicator
+// loads refs into read-only replicas, and callers that restore refs on behalf of users check it themselves.
 type Loader interface {
 	// LoadCommits iterates through all commits in Graveler format and loads them into repositoryID
 	LoadCommits(ctx context.Context, repository *RepositoryRecord, metaRangeID MetaRangeID) error
@@ -680,6 +850,9 @@ type Loader interface {
 
 	// LoadTags iterates through all tags in Graveler format and loads them into repositoryID
 	LoadTags(ctx context.Context, repository *RepositoryRecord, metaRangeID MetaRangeID) error
+
+	// UnloadTag deletes tagID from repository without running hooks, so that LoadTags can load it again
+	UnloadTag(ctx context.Context, repository *RepositoryRecord, tagID TagID) error
 }
 
 // Internal structures used by Graveler
@@ -731,6 +904,14 @@ type BranchIterator interface {
 	Close()
 }
 
+type ReflogIterator interface {
+	Next() bool
+	SeekGE(id string)
+	Value() *ReflogEntry
+	Err() error
+	Close()
+}
+
 type TagIterator interface {
 	Next() bool
 	SeekGE(id TagID)
@@ -793,15 +974,23 @@ type RefManager interface {
 	// CreateBranch creates a branch with the given id and Branch metadata
 	CreateBranch(ctx context.Context, repository *RepositoryRecord, branchID BranchID, branch Branch) error
 
-	// SetBranch points the given BranchID at the given Branch metadata
-	SetBranch(ctx context.Context, repository *RepositoryRecord, branchID BranchID, branch Branch) error
+	// SetBranch points the given BranchID at the given Branch metadata, recording the move of its head as operation
+	SetBranch(ctx context.Context, repository *RepositoryRecord, branchID BranchID, branch Branch, operation string) error
 
-	// BranchUpdate Conditional set of branch with validation callback
-	BranchUpdate(ctx context.Context, repository *RepositoryRecord, branchID BranchID, f BranchUpdateFunc) error
+	// BranchUpdate Conditional set of branch with validation callback, recording the move of its head as operation
+	BranchUpdate(ctx context.Context, repository *RepositoryRecord, branchID BranchID, operation string, f BranchUpdateFunc) error
 
-	// DeleteBranch deletes the branch
+	// DeleteBranch deletes the branch and its reflog.  It returns ErrBranchLocked if a pending transaction holds the
+	// branch.
 	DeleteBranch(ctx context.Context, repository *RepositoryRecord, branchID BranchID) error
 
+	// ListBranchReflog lists the movements of the head of the branch recorded by SetBranch and BranchUpdate, newest
+	// first
+	ListBranchReflog(ctx context.Context, repository *RepositoryRecord, branchID BranchID) (ReflogIterator, error)
+
+	// PruneBranchReflog deletes the reflog entries of the branch created at or before t
+	PruneBranchReflog(ctx context.Context, repository *RepositoryRecord, branchID BranchID, t time.Time) error
+
 	// ListBranches lists branches
 	ListBranches(ctx context.Context, repository *RepositoryRecord) (BranchIterator, error)
 
@@ -809,6 +998,15 @@ type RefManager interface {
 	// GCBranchIterator temporary WA to support both DB and KV GC BranchIterator, which iterates over branches by order of commit ID
 	GCBranchIterator(ctx context.Context, repository *RepositoryRecord) (BranchIterator, error)
 
+	// CreateTransaction stores a new Transaction
+	CreateTransaction(ctx context.Context, transaction Transaction) error
+
+	// TransactionUpdate Conditional set of transaction with validation callback
+	TransactionUpdate(ctx context.Context, transactionID TransactionID, f TransactionUpdateFunc) error
+
+	// DeleteTransaction deletes the transaction
+	DeleteTransaction(ctx context.Context, transactionID TransactionID) error
+
 	// GetTag returns the Tag metadata object for the given TagID
 	GetTag(ctx context.Context, repository *RepositoryRecord, tagID TagID) (*CommitID, error)
 
@@ -834,6 +1032,13 @@ type RefManager interface {
 	// RemoveCommit deletes commit from store - used for repository cleanup
 	RemoveCommit(ctx context.Context, repository *RepositoryRecord, commitID CommitID) error
 
+	// AddCommitSignature stores the signature of a commit, failing with ErrCommitSignatureExists if it is already
+	// signed
+	AddCommitSignature(ctx context.Context, repository *RepositoryRecord, signature CommitSignature) error
+
+	// GetCommitSignature returns the signature of a commit, or ErrCommitSignatureNotFound
+	GetCommitSignature(ctx context.Context, repository *RepositoryRecord, commitID CommitID) (*CommitSignature, error)
+
 	// FindMergeBase returns the merge-base for the given CommitIDs
 	// see: https://git-scm.com/docs/git-merge-base
 	// and internally: https://github.com/treeverse/lakeFS/blob/09954804baeb36ada74fa17d8fdc13a38552394e/index/dag/commits.go
@@ -919,8 +1124,20 @@ type CommittedManager interface {
 
 	// GetRangeIDByKey returns the RangeID that contains the given key.
 	GetRangeIDByKey(ctx context.Context, ns StorageNamespace, id MetaRangeID, key Key) (RangeID, error)
+
+	// ListRanges returns the IDs of the ranges of the MetaRange with the given id.
+	ListRanges(ctx context.Context, ns StorageNamespace, id MetaRangeID) ([]RangeID, error)
+
+	// CloneMetaRange makes the MetaRange with the given id in src addressable from dst, passing every
+	// value through rewrite exactly once.  Ranges in which rewrite changes no value are copied to dst
+	// under the same ID; the others are rewritten in dst.  It returns the ID of the MetaRange in dst.
+	CloneMetaRange(ctx context.Context, src, dst StorageNamespace, id MetaRangeID, rewrite ValueRewriteFunc) (MetaRangeID, error)
 }
 
+// ValueRewriteFunc returns the value that replaces record when it is cloned into another storage
+// namespace, or nil to keep record unchanged.
+type ValueRewriteFunc func(record *ValueRecord) (*Value, error)
+
 // StagingManager manages entries in a staging area, denoted by a staging token
 type StagingManager interface {
 	// Get returns the value for the provided staging token and key
@@ -1021,6 +1238,8 @@ type Graveler struct {
 	StagingManager           StagingManager
 	protectedBranchesManager ProtectedBranchesManager
 	garbageCollectionManager GarbageCollectionManager
+	// accessStates caches the *cachedAccessState of each repository, keyed by accessStateKey
+	accessStates cache.Cache
 	// logger *without context* to be used for logging.  It should be
 	// avoided in favour of g.log(ctx) in any operation where context is
 	// available.
@@ -1035,6 +1254,7 @@ func NewGraveler(committedManager CommittedManager, stagingManager StagingManage
 		StagingManager:           stagingManager,
 		protectedBranchesManager: protectedBranchesManager,
 		garbageCollectionManager: gcManager,
+		accessStates:             cache.NewCache(accessStateCacheSize, accessStateCacheExpiry, cache.NewJitterFn(accessStateCacheJitter)),
 		logger:                   logging.ContextUnavailable().WithField("service_name", "graveler_graveler"),
 	}
 }
@@ -1075,6 +1295,84 @@ func (g *Graveler) CreateBareRepository(ctx context.Context, repositoryID Reposi
 	return repository, nil
 }
 
+func (g *Graveler) CreateRepositoryFromTemplate(ctx context.Context, repositoryID RepositoryID, storageNamespace StorageNamespace, defaultBranchID BranchID, template *RepositoryRecord, commitID CommitID, params CommitParams, rewrite ValueRewriteFunc) (*RepositoryRecord, error) {
+	_, err := g.RefManager.GetRepository(ctx, repositoryID)
+	if err == nil {
+		return nil, ErrNotUnique
+	}
+	if !errors.Is(err, ErrRepositoryNotFound) {
+		return nil, err
+	}
+
+	repo := NewRepository(storageNamespace, defaultBranchID)
+	repository := &RepositoryRecord{
+		RepositoryID: repositoryID,
+		Repository:   &repo,
+	}
+	// The repository is stored last, so a failure leaves the commit, branch and settings dangling - as with CreateRepository
+	if err := g.fillRepositoryFromTemplate(ctx, repository, template, commitID, params, rewrite); err != nil {
+		return nil, err
+	}
+	return g.RefManager.CreateBareRepository(ctx, repositoryID, repo)
+}
+
+// fillRepositoryFromTemplate adds the commit holding the content of commitID in template to repository, creates its
+// default branch at that commit and copies the settings of template.  repository need not be stored yet.
+func (g *Graveler) fillRepositoryFromTemplate(ctx context.Context, repository *RepositoryRecord, template *RepositoryRecord, commitID CommitID, params CommitParams, rewrite ValueRewriteFunc) error {
+	templateCommit, err := g.RefManager.GetCommit(ctx, template, commitID)
+	if err != nil {
+		return err
+	}
+	metaRangeID := templateCommit.MetaRangeID
+	if metaRangeID != "" {
+		metaRangeID, err = g.CommittedManager.CloneMetaRange(ctx, template.StorageNamespace, repository.StorageNamespace, metaRangeID, rewrite)
+		if err != nil {
+			return fmt.Errorf("clone metarange %s: %w", templateCommit.MetaRangeID, err)
+		}
+	}
+
+	commit := NewCommit()
+	commit.Committer = params.Committer
+	commit.Message = params.Message
+	commit.MetaRangeID = metaRangeID
+	commit.Metadata = params.Metadata
+	commit.Generation = 1
+	newCommitID, err := g.RefManager.AddCommit(ctx, repository, commit)
+	if err != nil {
+		return fmt.Errorf("add commit: %w", err)
+	}
+	err = g.RefManager.CreateBranch(ctx, repository, repository.DefaultBranchID, Branch{
+		CommitID:     newCommitID,
+		StagingToken: GenerateStagingToken(repository.RepositoryID, repository.DefaultBranchID),
+	})
+	if err != nil {
+		return fmt.Errorf("create branch %s: %w", repository.DefaultBranchID, err)
+	}
+	return g.copyRepositorySettings(ctx, template, repository)
+}
+
+// copyRepositorySettings copies the branch protection and garbage collection rules of template to repository
+func (g *Graveler) copyRepositorySettings(ctx context.Context, template, repository *RepositoryRecord) error {
+	protectionRules, err := g.protectedBranchesManager.GetRules(ctx, template)
+	if err != nil {
+		return fmt.Errorf("get branch protection rules: %w", err)
+	}
+	for pattern, blockedActions := range protectionRules.GetBranchPatternToBlockedActions() {
+		if err := g.protectedBranchesManager.Add(ctx, repository, pattern, blockedActions.GetValue()); err != nil {
+			return fmt.Errorf("add branch protection rule %s: %w", pattern, err)
+		}
+	}
+
+	gcRules, err := g.getGarbageCollectionRules(ctx, template)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get gc rules: %w", err)
+	}
+	return g.garbageCollectionManager.SaveRules(ctx, repository.StorageNamespace, gcRules)
+}
+
 func (g *Graveler) ListRepositories(ctx context.Context) (RepositoryIterator, error) {
 	return g.RefManager.ListRepositories(ctx)
 }
@@ -1087,6 +1385,108 @@ func (g *Graveler) GetRepositoryMetadata(ctx context.Context, repositoryID Repos
 	return g.RefManager.GetRepositoryMetadata(ctx, repositoryID)
 }
 
+func (g *Graveler) SetRepositoryMetadata(ctx context.Context, repository *RepositoryRecord, updateFunc RepoMetadataUpdateFunc) error {
+	var newMetadata RepositoryMetadata
+	err := g.retryRepoMetadataUpdate(ctx, repository, func(metadata RepositoryMetadata) (RepositoryMetadata, error) {
+		var err error
+		newMetadata, err = updateFunc(metadata)
+		return newMetadata, err
+	})
+	if err != nil || newMetadata == nil {
+		return err
+	}
+	// apply a change of access state on this instance at once
+	return g.storeAccessState(repository, AccessStateFromMetadata(newMetadata))
+}
+
+const (
+	accessStateCacheSize   = 10_000
+	accessStateCacheExpiry = 3 * time.Second
+	accessStateCacheJitter = time.Second
+)
+
+type accessStateKey struct {
+	RepositoryID RepositoryID
+	InstanceUID  string
+}
+
+func accessStateKeyOf(repository *RepositoryRecord) accessStateKey {
+	return accessStateKey{RepositoryID: repository.RepositoryID, InstanceUID: repository.InstanceUID}
+}
+
+// cachedAccessState holds the cached RepositoryAccessState of a repository.  It is updated in place when this
+// instance changes the state or reads it uncached.
+type cachedAccessState struct {
+	atomic.Value
+}
+
+// storeAccessState replaces the cached access state of repository.
+func (g *Graveler) storeAccessState(repository *RepositoryRecord, state RepositoryAccessState) error {
+	cached, err := g.accessStates.GetOrSet(accessStateKeyOf(repository), func() (interface{}, error) {
+		return &cachedAccessState{}, nil
+	})
+	if err != nil {
+		return err
+	}
+	cached.(*cachedAccessState).Store(state)
+	return nil
+}
+
+func (g *Graveler) GetRepositoryAccessState(ctx context.Context, repository *RepositoryRecord) (RepositoryAccessState, error) {
+	state, err := g.accessStates.GetOrSet(accessStateKeyOf(repository), func() (interface{}, error) {
+		metadata, err := g.RefManager.GetRepositoryMetadata(ctx, repository.RepositoryID)
+		if err != nil {
+			return nil, err
+		}
+		state := &cachedAccessState{}
+		state.Store(AccessStateFromMetadata(metadata))
+		return state, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return state.(*cachedAccessState).Load().(RepositoryAccessState), nil
+}
+
+// checkWritable returns an error if repository does not accept changes: it is read-only or archived.
+func (g *Graveler) checkWritable(ctx context.Context, repository *RepositoryRecord) error {
+	state, err := g.GetRepositoryAccessState(ctx, repository)
+	if err != nil {
+		return err
+	}
+	return state.WriteError()
+}
+
+// checkWritableUncached is checkWritable with the access state read from the repository metadata rather than the
+// cache, which it updates: a change made by another instance applies at once.
+func (g *Graveler) checkWritableUncached(ctx context.Context, repository *RepositoryRecord) error {
+	metadata, err := g.RefManager.GetRepositoryMetadata(ctx, repository.RepositoryID)
+	if err != nil {
+		return err
+	}
+	state := AccessStateFromMetadata(metadata)
+	if err := g.storeAccessState(repository, state); err != nil {
+		return err
+	}
+	return state.WriteError()
+}
+
+// branchUpdate is BranchUpdate of branchID by a change to repository.  The access state is checked again, uncached,
+// once f returns the updated branch, just before its conditional write: a change of state by another instance that
+// the cache does not hold yet still stops the update.
+func (g *Graveler) branchUpdate(ctx context.Context, repository *RepositoryRecord, branchID BranchID, operation string, f BranchUpdateFunc) error {
+	return g.RefManager.BranchUpdate(ctx, repository, branchID, operation, func(branch *Branch) (*Branch, error) {
+		newBranch, err := f(branch)
+		if err != nil || newBranch == nil {
+			return newBranch, err
+		}
+		if err := g.checkWritableUncached(ctx, repository); err != nil {
+			return nil, err
+		}
+		return newBranch, nil
+	})
+}
+
 func (g *Graveler) WriteRange(ctx context.Context, repository *RepositoryRecord, it ValueIterator) (*RangeInfo, error) {
 	return g.CommittedManager.WriteRange(ctx, repository.StorageNamespace, it)
 }
@@ -1100,7 +1500,10 @@ func (g *Graveler) StageObject(ctx context.Context, stagingToken string, object
 }
 
 func (g *Graveler) UpdateBranchToken(ctx context.Context, repository *RepositoryRecord, branchID, stagingToken string) error {
-	err := g.RefManager.BranchUpdate(ctx, repository, BranchID(branchID), func(branch *Branch) (*Branch, error) {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return err
+	}
+	err := g.branchUpdate(ctx, repository, BranchID(branchID), "update_branch_token", func(branch *Branch) (*Branch, error) {
 		isEmpty, err := g.isStagingEmpty(ctx, repository, branch)
 		if err != nil {
 			return nil, err
@@ -1122,16 +1525,133 @@ func (g *Graveler) WriteMetaRangeByIterator(ctx context.Context, repository *Rep
 	return g.CommittedManager.WriteMetaRangeByIterator(ctx, repository.StorageNamespace, it, nil)
 }
 
+func (g *Graveler) ApplyMetaRangeChanges(ctx context.Context, repository *RepositoryRecord, metaRangeID MetaRangeID, changes ValueIterator) (*MetaRangeID, error) {
+	newMetaRangeID, _, err := g.CommittedManager.Commit(ctx, repository.StorageNamespace, metaRangeID, changes)
+	if errors.Is(err, ErrNoChanges) {
+		return &metaRangeID, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &newMetaRangeID, nil
+}
+
 func (g *Graveler) GetCommit(ctx context.Context, repository *RepositoryRecord, commitID CommitID) (*Commit, error) {
 	return g.RefManager.GetCommit(ctx, repository, commitID)
 }
 
+func (g *Graveler) SignCommit(ctx context.Context, repository *RepositoryRecord, signature CommitSignature) error {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return err
+	}
+	if _, err := g.RefManager.GetCommit(ctx, repository, signature.CommitID); err != nil {
+		return err
+	}
+	return g.RefManager.AddCommitSignature(ctx, repository, signature)
+}
+
+func (g *Graveler) GetCommitSignature(ctx context.Context, repository *RepositoryRecord, commitID CommitID) (*CommitSignature, error) {
+	return g.RefManager.GetCommitSignature(ctx, repository, commitID)
+}
+
+// requiresSignedCommits returns whether branchID of repository only accepts signed commits
+func (g *Graveler) requiresSignedCommits(ctx context.Context, repository *RepositoryRecord, branchID BranchID) (bool, error) {
+	return g.protectedBranchesManager.IsBlocked(ctx, repository, branchID, BranchProtectionBlockedAction_UNSIGNED_COMMIT)
+}
+
+// checkUnsignedCommitAllowed fails with ErrUnsignedCommit if branchID requires signed commits.  Used by operations
+// that create commits on the server, which cannot be signed before they reach the branch.
+func (g *Graveler) checkUnsignedCommitAllowed(ctx context.Context, repository *RepositoryRecord, branchID BranchID) error {
+	requiresSigned, err := g.requiresSignedCommits(ctx, repository, branchID)
+	if err != nil {
+		return err
+	}
+	if requiresSigned {
+		return fmt.Errorf("branch %s: %w", branchID, ErrUnsignedCommit)
+	}
+	return nil
+}
+
+// checkCommitSigned fails with ErrUnsignedCommit if commitID is not signed
+func (g *Graveler) checkCommitSigned(ctx context.Context, repository *RepositoryRecord, commitID CommitID) error {
+	_, err := g.RefManager.GetCommitSignature(ctx, repository, commitID)
+	if errors.Is(err, ErrCommitSignatureNotFound) {
+		return fmt.Errorf("commit %s: %w", commitID, ErrUnsignedCommit)
+	}
+	return err
+}
+
+const (
+	reachedFromHead = 1 << iota
+	reachedFromBase
+)
+
+// checkNewCommitsSigned fails with ErrUnsignedCommit unless every commit reachable from headID that is not reachable
+// from baseID is signed: these are the commits that a branch at baseID gains when it moves to headID.  Commits are
+// visited from the highest generation down, so a commit is checked only after all of its descendants were visited,
+// and the walk stops once the remaining commits are all reachable from baseID.
+func (g *Graveler) checkNewCommitsSigned(ctx context.Context, repository *RepositoryRecord, headID, baseID CommitID) error {
+	if headID == baseID {
+		return nil
+	}
+	reached := map[CommitID]int{headID: reachedFromHead, baseID: reachedFromBase}
+	var queue []*CommitRecord
+	enqueue := func(commitID CommitID) error {
+		commit, err := g.RefManager.GetCommit(ctx, repository, commitID)
+		if err != nil {
+			return fmt.Errorf("get commit %s: %w", commitID, err)
+		}
+		queue = append(queue, &CommitRecord{CommitID: commitID, Commit: commit})
+		return nil
+	}
+	if err := enqueue(headID); err != nil {
+		return err
+	}
+	if err := enqueue(baseID); err != nil {
+		return err
+	}
+	for {
+		next := -1
+		onlyFromBase := true
+		for i, record := range queue {
+			if reached[record.CommitID]&reachedFromBase == 0 {
+				onlyFromBase = false
+			}
+			if next < 0 || record.Generation > queue[next].Generation {
+				next = i
+			}
+		}
+		if onlyFromBase {
+			return nil
+		}
+		record := queue[next]
+		queue = append(queue[:next], queue[next+1:]...)
+		flags := reached[record.CommitID]
+		if flags == reachedFromHead {
+			if err := g.checkCommitSigned(ctx, repository, record.CommitID); err != nil {
+				return err
+			}
+		}
+		for _, parent := range record.Parents {
+			if _, ok := reached[parent]; !ok {
+				if err := enqueue(parent); err != nil {
+					return err
+				}
+			}
+			reached[parent] |= flags
+		}
+	}
+}
+
 func GenerateStagingToken(repositoryID RepositoryID, branchID BranchID) StagingToken {
 	uid := uuid.New().String()
 	return StagingToken(fmt.Sprintf("%s-%s:%s", repositoryID, branchID, uid))
 }
 
 func (g *Graveler) CreateBranch(ctx context.Context, repository *RepositoryRecord, branchID BranchID, ref Ref) (*Branch, error) {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return nil, err
+	}
 	reference, err := g.Dereference(ctx, repository, ref)
 	if err != nil {
 		return nil, fmt.Errorf("source reference '%s': %w", ref, err)
@@ -1193,6 +1713,9 @@ func (g *Graveler) CreateBranch(ctx context.Context, repository *RepositoryRecor
 }
 
 func (g *Graveler) UpdateBranch(ctx context.Context, repository *RepositoryRecord, branchID BranchID, ref Ref) (*Branch, error) {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return nil, err
+	}
 	reference, err := g.Dereference(ctx, repository, ref)
 	if err != nil {
 		return nil, err
@@ -1200,6 +1723,11 @@ func (g *Graveler) UpdateBranch(ctx context.Context, repository *RepositoryRecor
 	if reference.ResolvedBranchModifier == ResolvedBranchModifierStaging {
 		return nil, fmt.Errorf("reference '%s': %w", ref, ErrDereferenceCommitWithStaging)
 	}
+	// a branch that requires signed commits may only be moved to signed history
+	requiresSigned, err := g.requiresSignedCommits(ctx, repository, branchID)
+	if err != nil {
+		return nil, err
+	}
 
 	err = g.prepareForCommitIDUpdate(ctx, repository, branchID, "update_branch")
 	if err != nil {
@@ -1208,7 +1736,7 @@ func (g *Graveler) UpdateBranch(ctx context.Context, repository *RepositoryRecor
 
 	var tokensToDrop []StagingToken
 	var newBranch *Branch
-	err = g.RefManager.BranchUpdate(ctx, repository, branchID, func(currBranch *Branch) (*Branch, error) {
+	err = g.branchUpdate(ctx, repository, branchID, "update_branch", func(currBranch *Branch) (*Branch, error) {
 		// TODO(Guys) return error only on conflicts, currently returns error for any changes on staging
 		empty, err := g.isSealedEmpty(ctx, repository, currBranch)
 		if err != nil {
@@ -1217,6 +1745,11 @@ func (g *Graveler) UpdateBranch(ctx context.Context, repository *RepositoryRecor
 		if !empty {
 			return nil, ErrDirtyBranch
 		}
+		if requiresSigned {
+			if err := g.checkNewCommitsSigned(ctx, repository, reference.CommitID, currBranch.CommitID); err != nil {
+				return nil, err
+			}
+		}
 
 		tokensToDrop = currBranch.SealedTokens
 		currBranch.SealedTokens = []StagingToken{}
@@ -1281,6 +1814,9 @@ func (g *Graveler) GetTag(ctx context.Context, repository *RepositoryRecord, tag
 }
 
 func (g *Graveler) CreateTag(ctx context.Context, repository *RepositoryRecord, tagID TagID, commitID CommitID) error {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return err
+	}
 	storageNamespace := repository.StorageNamespace
 
 	// Check that Tag doesn't exist before running hook - Non-Atomic operation
@@ -1331,6 +1867,9 @@ func (g *Graveler) CreateTag(ctx context.Context, repository *RepositoryRecord,
 }
 
 func (g *Graveler) DeleteTag(ctx context.Context, repository *RepositoryRecord, tagID TagID) error {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return err
+	}
 	storageNamespace := repository.StorageNamespace
 
 	// Sanity check that Tag exists before running hook.
@@ -1404,7 +1943,14 @@ func (g *Graveler) ListBranches(ctx context.Context, repository *RepositoryRecor
 	return g.RefManager.ListBranches(ctx, repository)
 }
 
+func (g *Graveler) ListBranchReflog(ctx context.Context, repository *RepositoryRecord, branchID BranchID) (ReflogIterator, error) {
+	return g.RefManager.ListBranchReflog(ctx, repository, branchID)
+}
+
 func (g *Graveler) DeleteBranch(ctx context.Context, repository *RepositoryRecord, branchID BranchID) error {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return err
+	}
 	if repository.DefaultBranchID == branchID {
 		return ErrDeleteDefaultBranch
 	}
@@ -1412,6 +1958,10 @@ func (g *Graveler) DeleteBranch(ctx context.Context, repository *RepositoryRecor
 	if err != nil {
 		return err
 	}
+	// a pending transaction is applying the sealed tokens of a held branch
+	if err := checkBranchNotHeld(branch); err != nil {
+		return err
+	}
 
 	commitID := branch.CommitID
 	storageNamespace := repository.StorageNamespace
@@ -1474,10 +2024,21 @@ func (g *Graveler) GetGarbageCollectionRules(ctx context.Context, repository *Re
 }
 
 func (g *Graveler) SetGarbageCollectionRules(ctx context.Context, repository *RepositoryRecord, rules *GarbageCollectionRules) error {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return err
+	}
 	return g.garbageCollectionManager.SaveRules(ctx, repository.StorageNamespace, rules)
 }
 
 func (g *Graveler) SaveGarbageCollectionCommits(ctx context.Context, repository *RepositoryRecord, previousRunID string) (*GarbageCollectionRunMetadata, error) {
+	// archived repositories are frozen: garbage collection must not remove their objects
+	state, err := g.GetRepositoryAccessState(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	if state == RepositoryAccessStateArchived {
+		return nil, ErrArchivedRepository
+	}
 	rules, err := g.getGarbageCollectionRules(ctx, repository)
 	if err != nil {
 		return nil, fmt.Errorf("get gc rules: %w", err)
@@ -1520,10 +2081,16 @@ func (g *Graveler) GetBranchProtectionRules(ctx context.Context, repository *Rep
 }
 
 func (g *Graveler) DeleteBranchProtectionRule(ctx context.Context, repository *RepositoryRecord, pattern string) error {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return err
+	}
 	return g.protectedBranchesManager.Delete(ctx, repository, pattern)
 }
 
 func (g *Graveler) CreateBranchProtectionRule(ctx context.Context, repository *RepositoryRecord, pattern string, blockedActions []BranchProtectionBlockedAction) error {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return err
+	}
 	return g.protectedBranchesManager.Add(ctx, repository, pattern, blockedActions)
 }
 
@@ -1605,6 +2172,9 @@ func (g *Graveler) GetRangeIDByKey(ctx context.Context, repository *RepositoryRe
 }
 
 func (g *Graveler) Set(ctx context.Context, repository *RepositoryRecord, branchID BranchID, key Key, value Value, opts ...SetOptionsFunc) error {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return err
+	}
 	isProtected, err := g.protectedBranchesManager.IsBlocked(ctx, repository, branchID, BranchProtectionBlockedAction_STAGING_WRITE)
 	if err != nil {
 		return err
@@ -1694,6 +2264,9 @@ func (g *Graveler) safeBranchWrite(ctx context.Context, log logging.Logger, repo
 }
 
 func (g *Graveler) Delete(ctx context.Context, repository *RepositoryRecord, branchID BranchID, key Key) error {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return err
+	}
 	isProtected, err := g.protectedBranchesManager.IsBlocked(ctx, repository, branchID, BranchProtectionBlockedAction_STAGING_WRITE)
 	if err != nil {
 		return err
@@ -1713,6 +2286,9 @@ func (g *Graveler) Delete(ctx context.Context, repository *RepositoryRecord, bra
 // DeleteBatch delete batch of keys. Keys length is limited to DeleteKeysMaxSize. Return error can be of type
 // 'multi-error' holds DeleteError with each key/error that failed as part of the batch.
 func (g *Graveler) DeleteBatch(ctx context.Context, repository *RepositoryRecord, branchID BranchID, keys []Key) error {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return err
+	}
 	isProtected, err := g.protectedBranchesManager.IsBlocked(ctx, repository, branchID, BranchProtectionBlockedAction_STAGING_WRITE)
 	if err != nil {
 		return err
@@ -1854,25 +2430,186 @@ func (g *Graveler) List(ctx context.Context, repository *RepositoryRecord, ref R
 	return listing, nil
 }
 
-func (g *Graveler) Commit(ctx context.Context, repository *RepositoryRecord, branchID BranchID, params CommitParams) (CommitID, error) {
-	var preRunID string
-	var commit Commit
-	var newCommitID CommitID
-	var storageNamespace StorageNamespace
-	var sealedToDrop []StagingToken
+// commitSnapshot is the state of a branch that a commit applies: the head commit and the sealed tokens to commit
+// on top of it, newest first.
+type commitSnapshot struct {
+	commitID CommitID
+	sealed   []StagingToken
+}
 
-	isProtected, err := g.protectedBranchesManager.IsBlocked(ctx, repository, branchID, BranchProtectionBlockedAction_COMMIT)
-	if err != nil {
-		return "", err
-	}
-	if isProtected {
-		return "", ErrCommitToProtectedBranch
+// errCommitRebase is returned from the commit branch update when the branch no longer matches the commit snapshot.
+var errCommitRebase = errors.New("branch changed during commit")
+
+// sealedTail returns the largest k such that the first k tokens of sealed are the last k tokens of current.  Commits
+// remove their sealed tokens from the end of the branch sealed tokens, so these are the tokens of sealed that were not
+// committed yet.
+func sealedTail(current, sealed []StagingToken) int {
+	for k := len(sealed); k > 0; k-- {
+		n := len(current) - k
+		if n >= 0 && stagingTokensEqual(current[n:], sealed[:k]) {
+			return k
+		}
 	}
-	storageNamespace = repository.StorageNamespace
+	return 0
+}
 
-	err = g.RefManager.BranchUpdate(ctx, repository, branchID, func(branch *Branch) (*Branch, error) {
-		if params.SourceMetaRange != nil {
-			empty, err := g.isStagingEmpty(ctx, repository, branch)
+func stagingTokensEqual(a, b []StagingToken) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// newCommitFromParams returns a new commit with params on top of parent.
+func newCommitFromParams(params CommitParams, parent CommitID) Commit {
+	commit := NewCommit()
+	if params.Date != nil {
+		commit.CreationDate = time.Unix(*params.Date, 0)
+	}
+	commit.Committer = params.Committer
+	commit.Message = params.Message
+	commit.Metadata = params.Metadata
+	if parent != "" {
+		commit.Parents = CommitParents{parent}
+	}
+	return commit
+}
+
+// applyCommitSnapshot fills the parent, generation and metarange of commit to commit snapshot.  The metarange is
+// sourceMetaRange if set, otherwise the sealed tokens of snapshot are applied to the metarange of its head.
+func (g *Graveler) applyCommitSnapshot(ctx context.Context, repository *RepositoryRecord, snapshot commitSnapshot, sourceMetaRange *MetaRangeID, commit *Commit) error {
+	var parentGeneration int
+	var branchMetaRangeID MetaRangeID
+	commit.Parents = nil
+	if snapshot.commitID != "" {
+		branchCommit, err := g.RefManager.GetCommit(ctx, repository, snapshot.commitID)
+		if err != nil {
+			return fmt.Errorf("get commit: %w", err)
+		}
+		branchMetaRangeID = branchCommit.MetaRangeID
+		parentGeneration = branchCommit.Generation
+		commit.Parents = CommitParents{snapshot.commitID}
+	}
+	commit.Generation = parentGeneration + 1
+	if sourceMetaRange != nil {
+		commit.MetaRangeID = *sourceMetaRange
+		return nil
+	}
+	changes, err := g.sealedTokensIterator(ctx, &Branch{SealedTokens: snapshot.sealed}, 0)
+	if err != nil {
+		return err
+	}
+	defer changes.Close()
+	// returns err if the commit is empty (no changes)
+	commit.MetaRangeID, _, err = g.CommittedManager.Commit(ctx, repository.StorageNamespace, branchMetaRangeID, changes)
+	if err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// checkCommitOverlap returns ErrConflictFound if a key changed between commits from and to is also changed by the
+// pending sealed tokens.  Keys changed by the committed sealed tokens are earlier writes to the same branch, and do
+// not conflict.
+func (g *Graveler) checkCommitOverlap(ctx context.Context, repository *RepositoryRecord, from, to CommitID, committed, pending []StagingToken) error {
+	fromMetaRangeID, err := g.commitMetaRangeID(ctx, repository, from)
+	if err != nil {
+		return err
+	}
+	toMetaRangeID, err := g.commitMetaRangeID(ctx, repository, to)
+	if err != nil {
+		return err
+	}
+	diffs, err := g.CommittedManager.Diff(ctx, repository.StorageNamespace, fromMetaRangeID, toMetaRangeID)
+	if err != nil {
+		return fmt.Errorf("diff moved head: %w", err)
+	}
+	defer diffs.Close()
+	pendingIt, err := g.sealedTokensIterator(ctx, &Branch{SealedTokens: pending}, 0)
+	if err != nil {
+		return err
+	}
+	defer pendingIt.Close()
+	var committedIt ValueIterator
+	if len(committed) > 0 {
+		committedIt, err = g.sealedTokensIterator(ctx, &Branch{SealedTokens: committed}, 0)
+		if err != nil {
+			return err
+		}
+		defer committedIt.Close()
+	}
+
+	hasDiff := diffs.Next()
+	hasCommitted := committedIt != nil && committedIt.Next()
+	for hasDiff && pendingIt.Next() {
+		key := pendingIt.Value().Key
+		for hasDiff && bytes.Compare(diffs.Value().Key, key) < 0 {
+			hasDiff = diffs.Next()
+		}
+		if !hasDiff || !bytes.Equal(diffs.Value().Key, key) {
+			continue
+		}
+		for hasCommitted && bytes.Compare(committedIt.Value().Key, key) < 0 {
+			hasCommitted = committedIt.Next()
+		}
+		if hasCommitted && bytes.Equal(committedIt.Value().Key, key) {
+			continue
+		}
+		return fmt.Errorf("key %s changed by a concurrent commit: %w", key, ErrConflictFound)
+	}
+	if err := diffs.Err(); err != nil {
+		return err
+	}
+	if err := pendingIt.Err(); err != nil {
+		return err
+	}
+	if committedIt != nil {
+		return committedIt.Err()
+	}
+	return nil
+}
+
+// commitMetaRangeID returns the metarange of commitID, or an empty metarange if commitID is empty
+func (g *Graveler) commitMetaRangeID(ctx context.Context, repository *RepositoryRecord, commitID CommitID) (MetaRangeID, error) {
+	if commitID == "" {
+		return "", nil
+	}
+	commit, err := g.RefManager.GetCommit(ctx, repository, commitID)
+	if err != nil {
+		return "", fmt.Errorf("get commit: %w", err)
+	}
+	return commit.MetaRangeID, nil
+}
+
+// Commit seals the staging area of branchID and commits it.  The new metarange is computed without holding the
+// branch, which is then updated only if its head did not move.  If another commit moved the head, the sealed tokens
+// it did not already commit are applied again on top of the new head, unless they change keys that the head move
+// also changed.
+func (g *Graveler) Commit(ctx context.Context, repository *RepositoryRecord, branchID BranchID, params CommitParams) (CommitID, error) {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return "", err
+	}
+	isProtected, err := g.protectedBranchesManager.IsBlocked(ctx, repository, branchID, BranchProtectionBlockedAction_COMMIT)
+	if err != nil {
+		return "", err
+	}
+	if isProtected {
+		return "", ErrCommitToProtectedBranch
+	}
+	if err := g.checkUnsignedCommitAllowed(ctx, repository, branchID); err != nil {
+		return "", err
+	}
+	storageNamespace := repository.StorageNamespace
+
+	var snapshot commitSnapshot
+	err = g.branchUpdate(ctx, repository, branchID, "commit", func(branch *Branch) (*Branch, error) {
+		if params.SourceMetaRange != nil {
+			empty, err := g.isStagingEmpty(ctx, repository, branch)
 			if err != nil {
 				return nil, fmt.Errorf("checking empty branch: %w", err)
 			}
@@ -1882,100 +2619,210 @@ func (g *Graveler) Commit(ctx context.Context, repository *RepositoryRecord, bra
 		}
 		branch.SealedTokens = append([]StagingToken{branch.StagingToken}, branch.SealedTokens...)
 		branch.StagingToken = GenerateStagingToken(repository.RepositoryID, branchID)
+		snapshot = commitSnapshot{commitID: branch.CommitID, sealed: branch.SealedTokens}
 		return branch, nil
 	})
 	if err != nil {
 		return "", err
 	}
 
-	err = g.retryBranchUpdate(ctx, repository, branchID, func(branch *Branch) (*Branch, error) {
-		// fill commit information - use for pre-commit and after adding the commit information used by commit
-		commit = NewCommit()
+	// fill commit information - use for pre-commit and after adding the commit information used by commit
+	commit := newCommitFromParams(params, snapshot.commitID)
 
-		if params.Date != nil {
-			commit.CreationDate = time.Unix(*params.Date, 0)
-		}
-		commit.Committer = params.Committer
-		commit.Message = params.Message
-		commit.Metadata = params.Metadata
-		if branch.CommitID != "" {
-			commit.Parents = CommitParents{branch.CommitID}
+	preRunID := g.hooks.NewRunID()
+	err = g.hooks.PreCommitHook(ctx, HookRecord{
+		RunID:            preRunID,
+		EventType:        EventTypePreCommit,
+		SourceRef:        branchID.Ref(),
+		RepositoryID:     repository.RepositoryID,
+		StorageNamespace: storageNamespace,
+		BranchID:         branchID,
+		Commit:           commit,
+	})
+	if err != nil {
+		return "", &HookAbortError{
+			EventType: EventTypePreCommit,
+			RunID:     preRunID,
+			Err:       err,
 		}
+	}
 
-		preRunID = g.hooks.NewRunID()
-		err = g.hooks.PreCommitHook(ctx, HookRecord{
-			RunID:            preRunID,
-			EventType:        EventTypePreCommit,
-			SourceRef:        branchID.Ref(),
-			RepositoryID:     repository.RepositoryID,
-			StorageNamespace: storageNamespace,
-			BranchID:         branchID,
-			Commit:           commit,
-		})
-		if err != nil {
-			return nil, &HookAbortError{
-				EventType: EventTypePreCommit,
-				RunID:     preRunID,
-				Err:       err,
-			}
+	var newCommitID CommitID
+	for rebases := 0; ; rebases++ {
+		if err := g.applyCommitSnapshot(ctx, repository, snapshot, params.SourceMetaRange, &commit); err != nil {
+			return "", err
 		}
 
-		var branchMetaRangeID MetaRangeID
-		var parentGeneration int
-		if branch.CommitID != "" {
-			branchCommit, err := g.RefManager.GetCommit(ctx, repository, branch.CommitID)
-			if err != nil {
-				return nil, fmt.Errorf("get commit: %w", err)
-			}
-			branchMetaRangeID = branchCommit.MetaRangeID
-			parentGeneration = branchCommit.Generation
-		}
-		commit.Generation = parentGeneration + 1
-		if params.SourceMetaRange != nil {
-			empty, err := g.isSealedEmpty(ctx, repository, branch)
-			if err != nil {
-				return nil, fmt.Errorf("checking empty sealed: %w", err)
+		var (
+			next         commitSnapshot
+			sealedToDrop []StagingToken
+		)
+		err = g.retryBranchUpdate(ctx, repository, branchID, func(branch *Branch) (*Branch, error) {
+			if err := checkBranchNotHeld(branch); err != nil {
+				return nil, err
 			}
-			if !empty {
-				return nil, ErrCommitMetaRangeDirtyBranch
+			var tail int
+			if params.SourceMetaRange != nil {
+				if branch.CommitID != snapshot.commitID {
+					next = commitSnapshot{commitID: branch.CommitID}
+					return nil, errCommitRebase
+				}
+				empty, err := g.isSealedEmpty(ctx, repository, branch)
+				if err != nil {
+					return nil, fmt.Errorf("checking empty sealed: %w", err)
+				}
+				if !empty {
+					return nil, ErrCommitMetaRangeDirtyBranch
+				}
+				tail = len(branch.SealedTokens)
+			} else {
+				tail = sealedTail(branch.SealedTokens, snapshot.sealed)
+				switch {
+				case branch.CommitID == snapshot.commitID && tail == len(snapshot.sealed):
+					// branch did not move: commit the snapshot
+				case branch.CommitID == snapshot.commitID:
+					// sealed tokens were replaced (e.g. by staging compaction): commit all of them
+					if len(branch.SealedTokens) == 0 {
+						return nil, ErrNoChanges
+					}
+					next = commitSnapshot{commitID: branch.CommitID, sealed: branch.SealedTokens}
+					return nil, errCommitRebase
+				case tail > 0:
+					// head moved: apply the tokens that were not committed on top of it
+					next = commitSnapshot{commitID: branch.CommitID, sealed: snapshot.sealed[:tail]}
+					return nil, errCommitRebase
+				default:
+					// head moved by a commit that included all of the snapshot
+					return nil, ErrNoChanges
+				}
 			}
-			commit.MetaRangeID = *params.SourceMetaRange
-		} else {
-			changes, err := g.sealedTokensIterator(ctx, branch, 0)
+
+			// add commit
+			newCommitID, err = g.RefManager.AddCommit(ctx, repository, commit)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("add commit: %w", err)
 			}
-			defer changes.Close()
-			// returns err if the commit is empty (no changes)
-			commit.MetaRangeID, _, err = g.CommittedManager.Commit(ctx, storageNamespace, branchMetaRangeID, changes)
-			if err != nil {
-				return nil, fmt.Errorf("commit: %w", err)
+
+			keep := len(branch.SealedTokens) - tail
+			sealedToDrop = branch.SealedTokens[keep:]
+			branch.CommitID = newCommitID
+			branch.SealedTokens = append(make([]StagingToken, 0, keep), branch.SealedTokens[:keep]...)
+			return branch, nil
+		}, "commit")
+		if errors.Is(err, errCommitRebase) && rebases < BranchUpdateMaxTries {
+			if params.SourceMetaRange == nil && next.commitID != snapshot.commitID {
+				// sealed tokens of the snapshot that are not pending were committed by the commit that moved the head
+				committed := snapshot.sealed[len(next.sealed):]
+				if err := g.checkCommitOverlap(ctx, repository, snapshot.commitID, next.commitID, committed, next.sealed); err != nil {
+					return "", err
+				}
 			}
+			g.log(ctx).WithField("branchID", branchID).
+				WithField("commitID", next.commitID).
+				Debug("Branch changed during commit, rebasing")
+			snapshot = next
+			continue
 		}
-		sealedToDrop = branch.SealedTokens
+		if errors.Is(err, errCommitRebase) {
+			return "", fmt.Errorf("commit: %w (last %s)", ErrTooManyTries, err)
+		}
+		if err != nil {
+			return "", err
+		}
+		g.dropTokens(ctx, sealedToDrop...)
+		break
+	}
 
-		// add commit
+	postRunID := g.hooks.NewRunID()
+	err = g.hooks.PostCommitHook(ctx, HookRecord{
+		EventType:        EventTypePostCommit,
+		RunID:            postRunID,
+		RepositoryID:     repository.RepositoryID,
+		StorageNamespace: storageNamespace,
+		SourceRef:        newCommitID.Ref(),
+		BranchID:         branchID,
+		Commit:           commit,
+		CommitID:         newCommitID,
+		PreRunID:         preRunID,
+	})
+	if err != nil {
+		g.log(ctx).WithError(err).
+			WithField("run_id", postRunID).
+			WithField("pre_run_id", preRunID).
+			Error("Post-commit hook failed")
+	}
+	return newCommitID, nil
+}
+
+func (g *Graveler) CommitChanges(ctx context.Context, repository *RepositoryRecord, branchID BranchID, parentID CommitID, changes ValueIterator, params CommitParams) (CommitID, error) {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return "", err
+	}
+	isProtected, err := g.protectedBranchesManager.IsBlocked(ctx, repository, branchID, BranchProtectionBlockedAction_COMMIT)
+	if err != nil {
+		return "", err
+	}
+	if isProtected {
+		return "", ErrCommitToProtectedBranch
+	}
+	if err := g.checkUnsignedCommitAllowed(ctx, repository, branchID); err != nil {
+		return "", err
+	}
+	parent, err := g.RefManager.GetCommit(ctx, repository, parentID)
+	if err != nil {
+		return "", fmt.Errorf("get commit: %w", err)
+	}
+	commit := newCommitFromParams(params, parentID)
+	commit.Generation = parent.Generation + 1
+
+	preRunID := g.hooks.NewRunID()
+	err = g.hooks.PreCommitHook(ctx, HookRecord{
+		RunID:            preRunID,
+		EventType:        EventTypePreCommit,
+		SourceRef:        branchID.Ref(),
+		RepositoryID:     repository.RepositoryID,
+		StorageNamespace: repository.StorageNamespace,
+		BranchID:         branchID,
+		Commit:           commit,
+	})
+	if err != nil {
+		return "", &HookAbortError{
+			EventType: EventTypePreCommit,
+			RunID:     preRunID,
+			Err:       err,
+		}
+	}
+
+	// returns ErrNoChanges if the changes leave the parent as is
+	commit.MetaRangeID, _, err = g.CommittedManager.Commit(ctx, repository.StorageNamespace, parent.MetaRangeID, changes)
+	if err != nil {
+		return "", fmt.Errorf("commit: %w", err)
+	}
+	var newCommitID CommitID
+	err = g.retryBranchUpdate(ctx, repository, branchID, func(branch *Branch) (*Branch, error) {
+		if err := checkBranchNotHeld(branch); err != nil {
+			return nil, err
+		}
+		if branch.CommitID != parentID {
+			return nil, ErrBranchHeadMoved
+		}
 		newCommitID, err = g.RefManager.AddCommit(ctx, repository, commit)
 		if err != nil {
 			return nil, fmt.Errorf("add commit: %w", err)
 		}
-
 		branch.CommitID = newCommitID
-		branch.SealedTokens = make([]StagingToken, 0)
 		return branch, nil
 	}, "commit")
 	if err != nil {
 		return "", err
 	}
 
-	g.dropTokens(ctx, sealedToDrop...)
-
 	postRunID := g.hooks.NewRunID()
 	err = g.hooks.PostCommitHook(ctx, HookRecord{
 		EventType:        EventTypePostCommit,
 		RunID:            postRunID,
 		RepositoryID:     repository.RepositoryID,
-		StorageNamespace: storageNamespace,
+		StorageNamespace: repository.StorageNamespace,
 		SourceRef:        newCommitID.Ref(),
 		BranchID:         branchID,
 		Commit:           commit,
@@ -1991,12 +2838,29 @@ func (g *Graveler) Commit(ctx context.Context, repository *RepositoryRecord, bra
 	return newCommitID, nil
 }
 
+// checkBranchNotHeld returns ErrBranchLocked if branch is held by a pending transaction.  Update functions check it
+// before adding a commit, so that an update which would be rejected leaves no commit behind.
+func checkBranchNotHeld(branch *Branch) error {
+	if branch.Transaction != "" {
+		return ErrBranchLocked
+	}
+	return nil
+}
+
 // retryBranchUpdate repeatedly attempts to BranchUpdate branchID of
-// repository using f.  If ErrPredicateFailed, it backs off and retries up
-// to BranchUpdateMaxTries times, and never sleeps than for more than
+// repository using f.  If ErrPredicateFailed or ErrBranchLocked, it backs
+// off and retries up to BranchUpdateMaxTries times, and never sleeps than for more than
 // BranchUpdateMaxInterval.  It returns the number of times it tried --
-// between 1 and BranchUpdateMaxTries.
+// between 1 and BranchUpdateMaxTries.  Each attempt is a branchUpdate.
 func (g *Graveler) retryBranchUpdate(ctx context.Context, repository *RepositoryRecord, branchID BranchID, f BranchUpdateFunc, operation string) error {
+	return g.retryBranchUpdateWith(ctx, repository, branchID, operation, func() error {
+		return g.branchUpdate(ctx, repository, branchID, operation, f)
+	})
+}
+
+// retryBranchUpdateWith retries update as retryBranchUpdate retries its
+// branch updates.
+func (g *Graveler) retryBranchUpdateWith(ctx context.Context, repository *RepositoryRecord, branchID BranchID, operation string, update func() error) error {
 	bo := backoff.NewExponentialBackOff()
 	bo.MaxInterval = BranchUpdateMaxInterval
 
@@ -2005,8 +2869,8 @@ func (g *Graveler) retryBranchUpdate(ctx context.Context, repository *Repository
 	err := backoff.Retry(func() error {
 		// TODO(eden) issue 3586 - if the branch commit id hasn't changed, update the fields instead of fail
 		tries += 1
-		err := g.RefManager.BranchUpdate(ctx, repository, branchID, f)
-		if errors.Is(err, kv.ErrPredicateFailed) && tries < BranchUpdateMaxTries {
+		err := update()
+		if (errors.Is(err, kv.ErrPredicateFailed) || errors.Is(err, ErrBranchLocked)) && tries < BranchUpdateMaxTries {
 			g.log(ctx).WithField("try", tries).
 				WithField("branchID", branchID).
 				Info("Retrying update branch")
@@ -2017,7 +2881,7 @@ func (g *Graveler) retryBranchUpdate(ctx context.Context, repository *Repository
 		}
 		return nil
 	}, bo)
-	if errors.Is(err, kv.ErrPredicateFailed) && tries >= BranchUpdateMaxTries {
+	if (errors.Is(err, kv.ErrPredicateFailed) || errors.Is(err, ErrBranchLocked)) && tries >= BranchUpdateMaxTries {
 		return fmt.Errorf("update branch: %w (last %s)", ErrTooManyTries, err)
 	}
 	return err
@@ -2052,6 +2916,9 @@ func CommitExists(ctx context.Context, repository *RepositoryRecord, commitID Co
 }
 
 func (g *Graveler) AddCommit(ctx context.Context, repository *RepositoryRecord, commit Commit) (CommitID, error) {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return "", err
+	}
 	// at least a single parent must exists
 	if len(commit.Parents) == 0 {
 		return "", ErrAddCommitNoParent
@@ -2148,6 +3015,9 @@ func (g *Graveler) dropTokens(ctx context.Context, tokens ...StagingToken) {
 }
 
 func (g *Graveler) Reset(ctx context.Context, repository *RepositoryRecord, branchID BranchID) error {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return err
+	}
 	isProtected, err := g.protectedBranchesManager.IsBlocked(ctx, repository, branchID, BranchProtectionBlockedAction_STAGING_WRITE)
 	if err != nil {
 		return err
@@ -2156,7 +3026,7 @@ func (g *Graveler) Reset(ctx context.Context, repository *RepositoryRecord, bran
 		return ErrWriteToProtectedBranch
 	}
 	tokensToDrop := make([]StagingToken, 0)
-	err = g.RefManager.BranchUpdate(ctx, repository, branchID, func(branch *Branch) (*Branch, error) {
+	err = g.branchUpdate(ctx, repository, branchID, "reset", func(branch *Branch) (*Branch, error) {
 		// Save current branch tokens for drop
 		tokensToDrop = append(tokensToDrop, branch.StagingToken)
 		tokensToDrop = append(tokensToDrop, branch.SealedTokens...)
@@ -2202,6 +3072,9 @@ func (g *Graveler) resetKey(ctx context.Context, repository *RepositoryRecord, b
 }
 
 func (g *Graveler) ResetKey(ctx context.Context, repository *RepositoryRecord, branchID BranchID, key Key) error {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return err
+	}
 	isProtected, err := g.protectedBranchesManager.IsBlocked(ctx, repository, branchID, BranchProtectionBlockedAction_STAGING_WRITE)
 	if err != nil {
 		return err
@@ -2238,6 +3111,9 @@ func (g *Graveler) ResetKey(ctx context.Context, repository *RepositoryRecord, b
 }
 
 func (g *Graveler) ResetPrefix(ctx context.Context, repository *RepositoryRecord, branchID BranchID, key Key) error {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return err
+	}
 	isProtected, err := g.protectedBranchesManager.IsBlocked(ctx, repository, branchID, BranchProtectionBlockedAction_STAGING_WRITE)
 	if err != nil {
 		return err
@@ -2249,7 +3125,7 @@ func (g *Graveler) ResetPrefix(ctx context.Context, repository *RepositoryRecord
 	newSealedTokens := make([]StagingToken, 0)
 	newStagingToken := GenerateStagingToken(repository.RepositoryID, branchID)
 
-	err = g.RefManager.BranchUpdate(ctx, repository, branchID, func(branch *Branch) (*Branch, error) {
+	err = g.branchUpdate(ctx, repository, branchID, "reset_prefix", func(branch *Branch) (*Branch, error) {
 		newSealedTokens = []StagingToken{branch.StagingToken}
 		newSealedTokens = append(newSealedTokens, branch.SealedTokens...)
 
@@ -2302,6 +3178,9 @@ type CommitIDAndSummary struct {
 // That is, try to apply the diff from C2 to C1 on the tip of the branch.
 // If the commit is a merge commit, 'parentNumber' is the parent number (1-based) relative to which the revert is done.
 func (g *Graveler) Revert(ctx context.Context, repository *RepositoryRecord, branchID BranchID, ref Ref, parentNumber int, commitParams CommitParams) (CommitID, error) {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return "", err
+	}
 	commitRecord, err := g.dereferenceCommit(ctx, repository, ref)
 	if err != nil {
 		return "", fmt.Errorf("get commit from ref %s: %w", ref, err)
@@ -2318,6 +3197,10 @@ func (g *Graveler) Revert(ctx context.Context, repository *RepositoryRecord, bra
 		parentNumber--
 	}
 
+	if err := g.checkUnsignedCommitAllowed(ctx, repository, branchID); err != nil {
+		return "", err
+	}
+
 	err = g.prepareForCommitIDUpdate(ctx, repository, branchID, "revert")
 	if err != nil {
 		return "", err
@@ -2325,7 +3208,10 @@ func (g *Graveler) Revert(ctx context.Context, repository *RepositoryRecord, bra
 
 	var commitID CommitID
 	var tokensToDrop []StagingToken
-	err = g.RefManager.BranchUpdate(ctx, repository, branchID, func(branch *Branch) (*Branch, error) {
+	err = g.branchUpdate(ctx, repository, branchID, "revert", func(branch *Branch) (*Branch, error) {
+		if err := checkBranchNotHeld(branch); err != nil {
+			return nil, err
+		}
 		if empty, err := g.isSealedEmpty(ctx, repository, branch); err != nil {
 			return nil, err
 		} else if !empty {
@@ -2379,6 +3265,9 @@ func (g *Graveler) Revert(ctx context.Context, repository *RepositoryRecord, bra
 // CherryPick creates a new commit on the given branch, with the changes from the given commit.
 // If the commit is a merge commit, 'parentNumber' is the parent number (1-based) relative to which the cherry-pick is done.
 func (g *Graveler) CherryPick(ctx context.Context, repository *RepositoryRecord, branchID BranchID, ref Ref, parentNumber *int, committer string) (CommitID, error) {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return "", err
+	}
 	commitRecord, err := g.dereferenceCommit(ctx, repository, ref)
 	if err != nil {
 		return "", fmt.Errorf("get commit from ref %s: %w", ref, err)
@@ -2398,6 +3287,10 @@ func (g *Graveler) CherryPick(ctx context.Context, repository *RepositoryRecord,
 	}
 	pn--
 
+	if err := g.checkUnsignedCommitAllowed(ctx, repository, branchID); err != nil {
+		return "", err
+	}
+
 	err = g.prepareForCommitIDUpdate(ctx, repository, branchID, "cherrypick")
 	if err != nil {
 		return "", err
@@ -2414,7 +3307,10 @@ func (g *Graveler) CherryPick(ctx context.Context, repository *RepositoryRecord,
 
 	var commitID CommitID
 	var tokensToDrop []StagingToken
-	err = g.RefManager.BranchUpdate(ctx, repository, branchID, func(branch *Branch) (*Branch, error) {
+	err = g.branchUpdate(ctx, repository, branchID, "cherry_pick", func(branch *Branch) (*Branch, error) {
+		if err := checkBranchNotHeld(branch); err != nil {
+			return nil, err
+		}
 		if empty, err := g.isSealedEmpty(ctx, repository, branch); err != nil {
 			return nil, err
 		} else if !empty {
@@ -2465,7 +3361,266 @@ func (g *Graveler) CherryPick(ctx context.Context, repository *RepositoryRecord,
 	return commitID, nil
 }
 
+// firstParentHistory returns the first-parent history of commitID, newest first, up to the first commit for which
+// done returns true.  It returns ErrNotAncestor if the history ends first.
+func (g *Graveler) firstParentHistory(ctx context.Context, repository *RepositoryRecord, commitID CommitID, done func(*CommitRecord) bool) ([]*CommitRecord, error) {
+	it, err := g.RefManager.Log(ctx, repository, commitID, true)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var history []*CommitRecord
+	for it.Next() {
+		c := it.Value()
+		history = append(history, c)
+		if done(c) {
+			return history, nil
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return nil, ErrNotAncestor
+}
+
+// Squash replaces the commits on the first-parent history of the branch after 'from' with a single new commit.  The
+// new commit has the metarange of the branch head, and 'from' as its parent.  Unless given, its message combines the
+// messages of the squashed commits.  Its metadata combines their metadata, overridden by the given metadata.
+func (g *Graveler) Squash(ctx context.Context, repository *RepositoryRecord, branchID BranchID, from Ref, commitParams CommitParams) (CommitID, error) {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return "", err
+	}
+	isProtected, err := g.protectedBranchesManager.IsBlocked(ctx, repository, branchID, BranchProtectionBlockedAction_COMMIT)
+	if err != nil {
+		return "", err
+	}
+	if isProtected {
+		return "", ErrCommitToProtectedBranch
+	}
+	if err := g.checkUnsignedCommitAllowed(ctx, repository, branchID); err != nil {
+		return "", err
+	}
+	fromCommit, err := g.dereferenceCommit(ctx, repository, from)
+	if err != nil {
+		return "", fmt.Errorf("get commit from ref %s: %w", from, err)
+	}
+
+	err = g.prepareForCommitIDUpdate(ctx, repository, branchID, "squash")
+	if err != nil {
+		return "", err
+	}
+
+	var commitID CommitID
+	var tokensToDrop []StagingToken
+	err = g.branchUpdate(ctx, repository, branchID, "squash", func(branch *Branch) (*Branch, error) {
+		if err := checkBranchNotHeld(branch); err != nil {
+			return nil, err
+		}
+		if empty, err := g.isSealedEmpty(ctx, repository, branch); err != nil {
+			return nil, err
+		} else if !empty {
+			return nil, fmt.Errorf("%s: %w", branchID, ErrDirtyBranch)
+		}
+		history, err := g.firstParentHistory(ctx, repository, branch.CommitID, func(c *CommitRecord) bool {
+			return c.CommitID == fromCommit.CommitID
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", from, err)
+		}
+		squashed := history[:len(history)-1]
+		if len(squashed) == 0 {
+			return nil, ErrNoChanges
+		}
+
+		commit := newCommitFromParams(commitParams, fromCommit.CommitID)
+		commit.MetaRangeID = squashed[0].MetaRangeID
+		commit.Generation = fromCommit.Generation + 1
+		commit.Metadata = make(Metadata)
+		messages := make([]string, 0, len(squashed))
+		for i := len(squashed) - 1; i >= 0; i-- {
+			messages = append(messages, squashed[i].Message)
+			for k, v := range squashed[i].Metadata {
+				commit.Metadata[k] = v
+			}
+		}
+		for k, v := range commitParams.Metadata {
+			commit.Metadata[k] = v
+		}
+		commit.Metadata["squash-head"] = string(branch.CommitID)
+		if commit.Message == "" {
+			commit.Message = strings.Join(messages, "\n\n")
+		}
+
+		commitID, err = g.RefManager.AddCommit(ctx, repository, commit)
+		if err != nil {
+			return nil, fmt.Errorf("add commit: %w", err)
+		}
+
+		tokensToDrop = branch.SealedTokens
+		branch.SealedTokens = []StagingToken{}
+		branch.CommitID = commitID
+		return branch, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("update branch: %w", err)
+	}
+
+	g.dropTokens(ctx, tokensToDrop...)
+	return commitID, nil
+}
+
+// Rewrite rebuilds the first-parent history of the branch without the given commits.  Every later commit is replayed
+// on top of the rewritten history by merging its changes, like CherryPick, and keeps its message, creation date and
+// any additional parents.  Excluding a commit that a later commit depends on fails with a conflict, as does a commit
+// to the branch while it is rewritten.
+func (g *Graveler) Rewrite(ctx context.Context, repository *RepositoryRecord, branchID BranchID, exclude []Ref, committer string) (CommitID, error) {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return "", err
+	}
+	if len(exclude) == 0 {
+		return "", fmt.Errorf("exclude: %w", ErrRequiredValue)
+	}
+	isProtected, err := g.protectedBranchesManager.IsBlocked(ctx, repository, branchID, BranchProtectionBlockedAction_COMMIT)
+	if err != nil {
+		return "", err
+	}
+	if isProtected {
+		return "", ErrCommitToProtectedBranch
+	}
+	if err := g.checkUnsignedCommitAllowed(ctx, repository, branchID); err != nil {
+		return "", err
+	}
+	excluded := make(map[CommitID]struct{}, len(exclude))
+	for _, ref := range exclude {
+		commitRecord, err := g.dereferenceCommit(ctx, repository, ref)
+		if err != nil {
+			return "", fmt.Errorf("get commit from ref %s: %w", ref, err)
+		}
+		excluded[commitRecord.CommitID] = struct{}{}
+	}
+
+	err = g.prepareForCommitIDUpdate(ctx, repository, branchID, "rewrite")
+	if err != nil {
+		return "", err
+	}
+
+	// replay the history outside the branch update: the update may be retried, and the new commits do not depend on
+	// anything but the head read here.
+	head, err := g.RefManager.GetBranch(ctx, repository, branchID)
+	if err != nil {
+		return "", fmt.Errorf("get branch: %w", err)
+	}
+	found := 0
+	history, err := g.firstParentHistory(ctx, repository, head.CommitID, func(c *CommitRecord) bool {
+		if _, ok := excluded[c.CommitID]; ok {
+			found++
+		}
+		return found == len(excluded)
+	})
+	if err != nil {
+		return "", fmt.Errorf("excluded commits: %w", err)
+	}
+	commitID, err := g.replayHistory(ctx, repository, history, excluded, committer)
+	if err != nil {
+		return "", err
+	}
+
+	var tokensToDrop []StagingToken
+	err = g.branchUpdate(ctx, repository, branchID, "rewrite", func(branch *Branch) (*Branch, error) {
+		if err := checkBranchNotHeld(branch); err != nil {
+			return nil, err
+		}
+		if empty, err := g.isSealedEmpty(ctx, repository, branch); err != nil {
+			return nil, err
+		} else if !empty {
+			return nil, fmt.Errorf("%s: %w", branchID, ErrDirtyBranch)
+		}
+		if branch.CommitID != head.CommitID {
+			return nil, fmt.Errorf("branch %s moved during rewrite: %w", branchID, ErrConflictFound)
+		}
+		tokensToDrop = branch.SealedTokens
+		branch.SealedTokens = []StagingToken{}
+		branch.CommitID = commitID
+		return branch, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("update branch: %w", err)
+	}
+
+	g.dropTokens(ctx, tokensToDrop...)
+	return commitID, nil
+}
+
+// replayHistory adds a new commit for every commit of history, newest first, that is not excluded, on top of the
+// parent of its oldest commit.  It returns the ID of the newest added commit.
+func (g *Graveler) replayHistory(ctx context.Context, repository *RepositoryRecord, history []*CommitRecord, excluded map[CommitID]struct{}, committer string) (CommitID, error) {
+	// the last commit of history is the oldest excluded commit
+	oldest := history[len(history)-1]
+	if len(oldest.Parents) == 0 {
+		return "", fmt.Errorf("exclude initial commit %s: %w", oldest.CommitID, ErrInvalidValue)
+	}
+	base, err := g.dereferenceCommit(ctx, repository, oldest.Parents[0].Ref())
+	if err != nil {
+		return "", fmt.Errorf("get commit from ref %s: %w", oldest.Parents[0], err)
+	}
+
+	commitID := base.CommitID
+	metaRangeID := base.MetaRangeID
+	generation := base.Generation
+	for i := len(history) - 2; i >= 0; i-- {
+		original := history[i]
+		if _, ok := excluded[original.CommitID]; ok {
+			continue
+		}
+		// merge the changes of the original commit from its first parent onto the rewritten history:
+		merged, err := g.CommittedManager.Merge(ctx, repository.StorageNamespace, metaRangeID, original.MetaRangeID, history[i+1].MetaRangeID, MergeStrategyNone)
+		switch {
+		case errors.Is(err, ErrNoChanges):
+			// the original commit changed nothing, keep the current metarange
+		case err != nil:
+			if !errors.Is(err, ErrUserVisible) {
+				err = fmt.Errorf("merge: %w", err)
+			}
+			return "", fmt.Errorf("replay commit %s: %w", original.CommitID, err)
+		default:
+			metaRangeID = merged
+		}
+		commit := NewCommit()
+		commit.Committer = committer
+		commit.Message = original.Message
+		commit.CreationDate = original.CreationDate
+		commit.MetaRangeID = metaRangeID
+		commit.Parents = append(CommitParents{commitID}, original.Parents[1:]...)
+		commit.Generation = generation + 1
+		for _, parent := range original.Parents[1:] {
+			parentCommit, err := g.dereferenceCommit(ctx, repository, parent.Ref())
+			if err != nil {
+				return "", fmt.Errorf("get commit from ref %s: %w", parent, err)
+			}
+			if parentCommit.Generation >= commit.Generation {
+				commit.Generation = parentCommit.Generation + 1
+			}
+		}
+		commit.Metadata = make(Metadata, len(original.Metadata)+2)
+		for k, v := range original.Metadata {
+			commit.Metadata[k] = v
+		}
+		commit.Metadata["rewrite-origin"] = string(original.CommitID)
+		commit.Metadata["rewrite-committer"] = original.Committer
+
+		commitID, err = g.RefManager.AddCommit(ctx, repository, commit)
+		if err != nil {
+			return "", fmt.Errorf("add commit: %w", err)
+		}
+		generation = commit.Generation
+	}
+	return commitID, nil
+}
+
 func (g *Graveler) Merge(ctx context.Context, repository *RepositoryRecord, destination BranchID, source Ref, commitParams CommitParams, strategy string) (CommitID, error) {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return "", err
+	}
 	var (
 		preRunID string
 		commit   Commit
@@ -2473,7 +3628,13 @@ func (g *Graveler) Merge(ctx context.Context, repository *RepositoryRecord, dest
 	)
 
 	storageNamespace := repository.StorageNamespace
-	err := g.prepareForCommitIDUpdate(ctx, repository, destination, "merge")
+	// a branch that requires signed commits only accepts signed history: the merge commit would be created unsigned
+	// on the server, so the branch is fast-forwarded to the source instead, after checking every commit it gains
+	requiresSigned, err := g.requiresSignedCommits(ctx, repository, destination)
+	if err != nil {
+		return "", err
+	}
+	err = g.prepareForCommitIDUpdate(ctx, repository, destination, "merge")
 	if err != nil {
 		return "", err
 	}
@@ -2496,6 +3657,9 @@ func (g *Graveler) Merge(ctx context.Context, repository *RepositoryRecord, dest
 	// or some other branch changing operation. If commit is in-progress, then staging area wasn't empty after we checked so not retrying is ok.
 	// If another commit/merge succeeded, then the user should decide whether to retry the merge.
 	err = g.retryBranchUpdate(ctx, repository, destination, func(branch *Branch) (*Branch, error) {
+		if err := checkBranchNotHeld(branch); err != nil {
+			return nil, err
+		}
 		empty, err := g.isSealedEmpty(ctx, repository, branch)
 		if err != nil {
 			return nil, fmt.Errorf("check if staging empty: %w", err)
@@ -2525,25 +3689,39 @@ func (g *Graveler) Merge(ctx context.Context, repository *RepositoryRecord, dest
 			return nil, ErrInvalidMergeStrategy
 		}
 
-		metaRangeID, err := g.CommittedManager.Merge(ctx, storageNamespace, toCommit.MetaRangeID, fromCommit.MetaRangeID, baseCommit.MetaRangeID, mergeStrategy)
-		if err != nil {
-			if !errors.Is(err, ErrUserVisible) {
-				err = fmt.Errorf("merge in CommitManager: %w", err)
+		if requiresSigned {
+			// commits are compared by identity, the merge base is found without its ID
+			switch {
+			case bytes.Equal(baseCommit.Identity(), fromCommit.Identity()):
+				return nil, ErrNoChanges
+			case !bytes.Equal(baseCommit.Identity(), toCommit.Identity()):
+				return nil, fmt.Errorf("branch %s: merge commit of %s: %w", destination, fromCommit.CommitID, ErrUnsignedCommit)
 			}
-			return nil, err
-		}
-		commit = NewCommit()
-		commit.Committer = commitParams.Committer
-		commit.Message = commitParams.Message
-		commit.MetaRangeID = metaRangeID
-		commit.Parents = []CommitID{toCommit.CommitID, fromCommit.CommitID}
-		if toCommit.Generation > fromCommit.Generation {
-			commit.Generation = toCommit.Generation + 1
+			if err := g.checkNewCommitsSigned(ctx, repository, fromCommit.CommitID, toCommit.CommitID); err != nil {
+				return nil, err
+			}
+			commit = *fromCommit.Commit
 		} else {
-			commit.Generation = fromCommit.Generation + 1
+			metaRangeID, err := g.CommittedManager.Merge(ctx, storageNamespace, toCommit.MetaRangeID, fromCommit.MetaRangeID, baseCommit.MetaRangeID, mergeStrategy)
+			if err != nil {
+				if !errors.Is(err, ErrUserVisible) {
+					err = fmt.Errorf("merge in CommitManager: %w", err)
+				}
+				return nil, err
+			}
+			commit = NewCommit()
+			commit.Committer = commitParams.Committer
+			commit.Message = commitParams.Message
+			commit.MetaRangeID = metaRangeID
+			commit.Parents = []CommitID{toCommit.CommitID, fromCommit.CommitID}
+			if toCommit.Generation > fromCommit.Generation {
+				commit.Generation = toCommit.Generation + 1
+			} else {
+				commit.Generation = fromCommit.Generation + 1
+			}
+			metadata[MergeStrategyMetadataKey] = mergeStrategyString[mergeStrategy]
+			commit.Metadata = metadata
 		}
-		metadata[MergeStrategyMetadataKey] = mergeStrategyString[mergeStrategy]
-		commit.Metadata = metadata
 		preRunID = g.hooks.NewRunID()
 		err = g.hooks.PreMergeHook(ctx, HookRecord{
 			EventType:        EventTypePreMerge,
@@ -2561,9 +3739,14 @@ func (g *Graveler) Merge(ctx context.Context, repository *RepositoryRecord, dest
 				Err:       err,
 			}
 		}
-		commitID, err = g.RefManager.AddCommit(ctx, repository, commit)
-		if err != nil {
-			return nil, fmt.Errorf("add commit: %w", err)
+		if requiresSigned {
+			// fast-forward to the signed source
+			commitID = fromCommit.CommitID
+		} else {
+			commitID, err = g.RefManager.AddCommit(ctx, repository, commit)
+			if err != nil {
+				return nil, fmt.Errorf("add commit: %w", err)
+			}
 		}
 
 		tokensToDrop = branch.SealedTokens
@@ -2627,6 +3810,9 @@ func (g *Graveler) retryRepoMetadataUpdate(ctx context.Context, repository *Repo
 }
 
 func (g *Graveler) Import(ctx context.Context, repository *RepositoryRecord, destination BranchID, source MetaRangeID, commitParams CommitParams, prefixes []Prefix) (CommitID, error) {
+	if err := g.checkWritable(ctx, repository); err != nil {
+		return "", err
+	}
 	var (
 		preRunID string
 		commit   Commit
@@ -2634,6 +3820,9 @@ func (g *Graveler) Import(ctx context.Context, repository *RepositoryRecord, des
 	)
 
 	storageNamespace := repository.StorageNamespace
+	if err := g.checkUnsignedCommitAllowed(ctx, repository, destination); err != nil {
+		return "", err
+	}
 	err := g.prepareForCommitIDUpdate(ctx, repository, destination, "import")
 	if err != nil {
 		return "", err
@@ -2644,6 +3833,9 @@ func (g *Graveler) Import(ctx context.Context, repository *RepositoryRecord, des
 	// or some other branch changing operation. If commit is in-progress, then staging area wasn't empty after we checked so not retrying is ok.
 	// If another commit/merge succeeded, then the user should decide whether to retry the merge.
 	err = g.retryBranchUpdate(ctx, repository, destination, func(branch *Branch) (*Branch, error) {
+		if err := checkBranchNotHeld(branch); err != nil {
+			return nil, err
+		}
 		empty, err := g.isSealedEmpty(ctx, repository, branch)
 		if err != nil {
 			return nil, fmt.Errorf("is staging empty %s: %w", destination, err)
@@ -2926,7 +4118,7 @@ func (g *Graveler) LoadBranches(ctx context.Context, repository *RepositoryRecor
 			CommitID:     CommitID(branch.CommitId),
 			StagingToken: GenerateStagingToken(repository.RepositoryID, branchID),
 			SealedTokens: make([]StagingToken, 0),
-		})
+		}, "restore_refs")
 		if err != nil {
 			return err
 		}
@@ -2959,6 +4151,10 @@ func (g *Graveler) LoadTags(ctx context.Context, repository *RepositoryRecord, m
 	return iter.Err()
 }
 
+func (g *Graveler) UnloadTag(ctx context.Context, repository *RepositoryRecord, tagID TagID) error {
+	return g.RefManager.DeleteTag(ctx, repository, tagID)
+}
+
 func (g *Graveler) GetMetaRange(ctx context.Context, repository *RepositoryRecord, metaRangeID MetaRangeID) (MetaRangeAddress, error) {
 	return g.CommittedManager.GetMetaRange(ctx, repository.StorageNamespace, metaRangeID)
 }
@@ -2967,12 +4163,29 @@ func (g *Graveler) GetRange(ctx context.Context, repository *RepositoryRecord, r
 	return g.CommittedManager.GetRange(ctx, repository.StorageNamespace, rangeID)
 }
 
+func (g *Graveler) ListRanges(ctx context.Context, repository *RepositoryRecord, metaRangeID MetaRangeID) ([]RangeID, error) {
+	return g.CommittedManager.ListRanges(ctx, repository.StorageNamespace, metaRangeID)
+}
+
 func (g *Graveler) DumpCommits(ctx context.Context, repository *RepositoryRecord) (*MetaRangeID, error) {
 	iter, err := g.RefManager.ListCommits(ctx, repository)
 	if err != nil {
 		return nil, err
 	}
 	defer iter.Close()
+	return g.dumpCommits(ctx, repository, iter)
+}
+
+func (g *Graveler) DumpCommitRecords(ctx context.Context, repository *RepositoryRecord, commits []*CommitRecord) (*MetaRangeID, error) {
+	records := make([]*CommitRecord, 0, len(commits))
+	records = append(records, commits...)
+	sort.SliceStable(records, func(i, j int) bool { return records[i].CommitID < records[j].CommitID })
+	iter := &commitRecordsIterator{records: dedupeSorted(records, func(r *CommitRecord) CommitID { return r.CommitID })}
+	defer iter.Close()
+	return g.dumpCommits(ctx, repository, iter)
+}
+
+func (g *Graveler) dumpCommits(ctx context.Context, repository *RepositoryRecord, iter CommitIterator) (*MetaRangeID, error) {
 	schema, err := serializeSchemaDefinition(&CommitData{})
 	if err != nil {
 		return nil, err
@@ -2993,6 +4206,19 @@ func (g *Graveler) DumpBranches(ctx context.Context, repository *RepositoryRecor
 		return nil, err
 	}
 	defer iter.Close()
+	return g.dumpBranches(ctx, repository, iter)
+}
+
+func (g *Graveler) DumpBranchRecords(ctx context.Context, repository *RepositoryRecord, branches []*BranchRecord) (*MetaRangeID, error) {
+	records := make([]*BranchRecord, 0, len(branches))
+	records = append(records, branches...)
+	sort.SliceStable(records, func(i, j int) bool { return records[i].BranchID < records[j].BranchID })
+	iter := &branchRecordsIterator{records: dedupeSorted(records, func(r *BranchRecord) BranchID { return r.BranchID })}
+	defer iter.Close()
+	return g.dumpBranches(ctx, repository, iter)
+}
+
+func (g *Graveler) dumpBranches(ctx context.Context, repository *RepositoryRecord, iter BranchIterator) (*MetaRangeID, error) {
 	schema, err := serializeSchemaDefinition(&BranchData{})
 	if err != nil {
 		return nil, err
@@ -3013,6 +4239,31 @@ func (g *Graveler) DumpTags(ctx context.Context, repository *RepositoryRecord) (
 		return nil, err
 	}
 	defer iter.Close()
+	return g.dumpTags(ctx, repository, iter)
+}
+
+func (g *Graveler) DumpTagRecords(ctx context.Context, repository *RepositoryRecord, tags []*TagRecord) (*MetaRangeID, error) {
+	records := make([]*TagRecord, 0, len(tags))
+	records = append(records, tags...)
+	sort.SliceStable(records, func(i, j int) bool { return records[i].TagID < records[j].TagID })
+	iter := &tagRecordsIterator{records: dedupeSorted(records, func(r *TagRecord) TagID { return r.TagID })}
+	defer iter.Close()
+	return g.dumpTags(ctx, repository, iter)
+}
+
+// dedupeSorted returns records, sorted by key, without records whose key equals that of the previous record.
+func dedupeSorted[T any, K comparable](records []T, key func(T) K) []T {
+	out := records[:0]
+	for i, r := range records {
+		if i > 0 && key(records[i-1]) == key(r) {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func (g *Graveler) dumpTags(ctx context.Context, repository *RepositoryRecord, iter TagIterator) (*MetaRangeID, error) {
 	schema, err := serializeSchemaDefinition(&TagData{})
 	if err != nil {
 		return nil, err
@@ -3247,6 +4498,108 @@ func (c *commitValueIterator) Close() {
 	c.src.Close()
 }
 
+// commitRecordsIterator iterates over commit records sorted by their ID.
+type commitRecordsIterator struct {
+	records []*CommitRecord
+	idx     int
+	value   *CommitRecord
+}
+
+func (c *commitRecordsIterator) Next() bool {
+	if c.idx >= len(c.records) {
+		c.value = nil
+		return false
+	}
+	c.value = c.records[c.idx]
+	c.idx++
+	return true
+}
+
+func (c *commitRecordsIterator) SeekGE(id CommitID) {
+	c.value = nil
+	c.idx = sort.Search(len(c.records), func(i int) bool {
+		return c.records[i].CommitID >= id
+	})
+}
+
+func (c *commitRecordsIterator) Value() *CommitRecord {
+	return c.value
+}
+
+func (c *commitRecordsIterator) Err() error {
+	return nil
+}
+
+func (c *commitRecordsIterator) Close() {}
+
+// branchRecordsIterator iterates over branch records sorted by their ID.
+type branchRecordsIterator struct {
+	records []*BranchRecord
+	idx     int
+	value   *BranchRecord
+}
+
+func (b *branchRecordsIterator) Next() bool {
+	if b.idx >= len(b.records) {
+		b.value = nil
+		return false
+	}
+	b.value = b.records[b.idx]
+	b.idx++
+	return true
+}
+
+func (b *branchRecordsIterator) SeekGE(id BranchID) {
+	b.value = nil
+	b.idx = sort.Search(len(b.records), func(i int) bool {
+		return b.records[i].BranchID >= id
+	})
+}
+
+func (b *branchRecordsIterator) Value() *BranchRecord {
+	return b.value
+}
+
+func (b *branchRecordsIterator) Err() error {
+	return nil
+}
+
+func (b *branchRecordsIterator) Close() {}
+
+// tagRecordsIterator iterates over tag records sorted by their ID.
+type tagRecordsIterator struct {
+	records []*TagRecord
+	idx     int
+	value   *TagRecord
+}
+
+func (t *tagRecordsIterator) Next() bool {
+	if t.idx >= len(t.records) {
+		t.value = nil
+		return false
+	}
+	t.value = t.records[t.idx]
+	t.idx++
+	return true
+}
+
+func (t *tagRecordsIterator) SeekGE(id TagID) {
+	t.value = nil
+	t.idx = sort.Search(len(t.records), func(i int) bool {
+		return t.records[i].TagID >= id
+	})
+}
+
+func (t *tagRecordsIterator) Value() *TagRecord {
+	return t.value
+}
+
+func (t *tagRecordsIterator) Err() error {
+	return nil
+}
+
+func (t *tagRecordsIterator) Close() {}
+
 type GarbageCollectionManager interface {
 	GetRules(ctx context.Context, storageNamespace StorageNamespace) (*GarbageCollectionRules, error)
 	SaveRules(ctx context.Context, storageNamespace StorageNamespace, rules *GarbageCollectionRules) error
@@ -368,6 +368,7 @@ func TestGravelerSet_Advanced(t *testing.T) {
 	// RefManager mock base setup
 	refMgr := mock.NewMockRefManager(ctrl)
 	refExpect := refMgr.EXPECT()
+	refExpect.GetRepositoryMetadata(gomock.Any(), gomock.Any()).AnyTimes().Return(nil, nil)
 	refExpectCommitNotFound := func() {
 		refExpect.ParseRef(gomock.Any()).Times(1).Return(graveler.RawRef{BaseRef: ""}, nil)
 		refExpect.ResolveRawRef(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(&graveler.ResolvedRef{
@@ -1076,12 +1077,12 @@ func TestGraveler_CreateBranch(t *testing.T) {
 
 func TestGraveler_UpdateBranch(t *testing.T) {
 	gravel := newGraveler(t, nil, &testutil.StagingFake{ValueIterator: testutil.NewValueIteratorFake([]graveler.ValueRecord{{Key: graveler.Key("foo/one"), Value: &graveler.Value{}}})},
-		&testutil.RefsFake{Branch: &graveler.Branch{}, UpdateErr: kv.ErrPredicateFailed}, nil, nil)
+		&testutil.RefsFake{Branch: &graveler.Branch{}, UpdateErr: kv.ErrPredicateFailed}, nil, testutil.NewProtectedBranchesManagerFake())
 	_, err := gravel.UpdateBranch(context.Background(), repository, "", "")
 	require.ErrorIs(t, err, graveler.ErrTooManyTries)
 
 	gravel = newGraveler(t, &testutil.CommittedFake{ValueIterator: testutil.NewValueIteratorFake([]graveler.ValueRecord{})}, &testutil.StagingFake{ValueIterator: testutil.NewValueIteratorFake([]graveler.ValueRecord{})},
-		&testutil.RefsFake{Branch: &graveler.Branch{StagingToken: "st1", CommitID: "commit1"}, Commits: map[graveler.CommitID]*graveler.Commit{"commit1": {}}}, nil, nil)
+		&testutil.RefsFake{Branch: &graveler.Branch{StagingToken: "st1", CommitID: "commit1"}, Commits: map[graveler.CommitID]*graveler.Commit{"commit1": {}}}, nil, testutil.NewProtectedBranchesManagerFake())
 	_, err = gravel.UpdateBranch(context.Background(), repository, "", "")
 	require.NoError(t, err)
 }
@@ -163,8 +163,8 @@ func TestGravelerMerge(t *testing.T) {
 	ctx := context.Background()
 
 	firstUpdateBranch := func(test *testutil.GravelerTest) {
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
 				branchTest := branch1
 				updatedBranch, err := f(&branchTest)
 				require.NoError(t, err)
@@ -187,6 +187,7 @@ func TestGravelerMerge(t *testing.T) {
 	}
 	t.Run("merge successful", func(t *testing.T) {
 		test := testutil.InitGravelerTest(t)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
 		firstUpdateBranch(test)
 		emptyStagingTokenCombo(test, 2)
 		test.RefManager.EXPECT().GetCommit(ctx, repository, commit1ID).Times(3).Return(&commit1, nil)
@@ -202,8 +203,8 @@ func TestGravelerMerge(t *testing.T) {
 			require.Equal(t, mr4ID, commit.MetaRangeID)
 			return commit4ID, nil
 		}).Times(1)
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
 				branchTest := &graveler.Branch{StagingToken: stagingToken4, CommitID: commit1ID, SealedTokens: []graveler.StagingToken{stagingToken1, stagingToken2, stagingToken3}}
 				updatedBranch, err := f(branchTest)
 				require.NoError(t, err)
@@ -225,8 +226,9 @@ func TestGravelerMerge(t *testing.T) {
 
 	t.Run("merge dirty destination while updating tokens", func(t *testing.T) {
 		test := testutil.InitGravelerTest(t)
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
 				branchTest := branch1
 				updatedBranch, err := f(&branchTest)
 				require.Error(t, err)
@@ -256,6 +258,7 @@ func TestGravelerMerge(t *testing.T) {
 
 	t.Run("merge successful with branchUpdate retry", func(t *testing.T) {
 		test := testutil.InitGravelerTest(t)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
 		firstUpdateBranch(test)
 		emptyStagingTokenCombo(test, 2)
 		test.RefManager.EXPECT().GetCommit(ctx, repository, commit1ID).Times(3).Return(&commit1, nil)
@@ -271,12 +274,12 @@ func TestGravelerMerge(t *testing.T) {
 			require.Equal(t, mr4ID, commit.MetaRangeID)
 			return commit4ID, nil
 		}).Times(1)
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
 				return kv.ErrPredicateFailed
 			}).Times(graveler.BranchUpdateMaxTries - 1)
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
 				branchTest := &graveler.Branch{StagingToken: stagingToken4, CommitID: commit1ID, SealedTokens: []graveler.StagingToken{stagingToken1, stagingToken2, stagingToken3}}
 				updatedBranch, err := f(branchTest)
 				require.NoError(t, err)
@@ -298,12 +301,13 @@ func TestGravelerMerge(t *testing.T) {
 
 	t.Run("merge fails due to BranchUpdate retries exhaustion", func(t *testing.T) {
 		test := testutil.InitGravelerTest(t)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
 		firstUpdateBranch(test)
 		emptyStagingTokenCombo(test, 1)
 		test.RefManager.EXPECT().GetCommit(ctx, repository, commit1ID).Times(1).Return(&commit1, nil)
 		test.CommittedManager.EXPECT().List(ctx, repository.StorageNamespace, mr1ID).Times(1).Return(testutils.NewFakeValueIterator(nil), nil)
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
 				return kv.ErrPredicateFailed
 			}).Times(graveler.BranchUpdateMaxTries)
 
@@ -318,8 +322,8 @@ func TestGravelerRevert(t *testing.T) {
 	ctx := context.Background()
 
 	firstUpdateBranch := func(test *testutil.GravelerTest) {
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
 				branchTest := branch1
 				updatedBranch, err := f(&branchTest)
 				require.NoError(t, err)
@@ -350,6 +354,7 @@ func TestGravelerRevert(t *testing.T) {
 	}
 	t.Run("revert successful", func(t *testing.T) {
 		test := testutil.InitGravelerTest(t)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
 		firstUpdateBranch(test)
 		emptyStagingTokenCombo(test, 2)
 		test.RefManager.EXPECT().GetCommit(ctx, repository, commit1ID).Times(3).Return(&commit1, nil)
@@ -367,8 +372,8 @@ func TestGravelerRevert(t *testing.T) {
 			require.Equal(t, mr3ID, commit.MetaRangeID)
 			return commit3ID, nil
 		}).Times(1)
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
 				branchTest := &graveler.Branch{StagingToken: stagingToken4, CommitID: commit1ID, SealedTokens: []graveler.StagingToken{stagingToken1, stagingToken2, stagingToken3}}
 				updatedBranch, err := f(branchTest)
 				require.NoError(t, err)
@@ -390,6 +395,7 @@ func TestGravelerRevert(t *testing.T) {
 
 	t.Run("revert dirty branch after token update", func(t *testing.T) {
 		test := testutil.InitGravelerTest(t)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
 		firstUpdateBranch(test)
 		emptyStagingTokenCombo(test, 1)
 		dirtyStagingTokenCombo(test)
@@ -399,8 +405,8 @@ func TestGravelerRevert(t *testing.T) {
 		test.RefManager.EXPECT().ParseRef(graveler.Ref(commit2ID)).Times(1).Return(rawRefCommit2, nil)
 		test.RefManager.EXPECT().ResolveRawRef(ctx, repository, rawRefCommit2).Times(1).Return(&graveler.ResolvedRef{Type: graveler.ReferenceTypeCommit, BranchRecord: graveler.BranchRecord{Branch: &graveler.Branch{CommitID: commit2ID}}}, nil)
 		test.RefManager.EXPECT().GetCommit(ctx, repository, commit2ID).Times(1).Return(&commit2, nil)
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
 				branchTest := &graveler.Branch{StagingToken: stagingToken4, CommitID: commit1ID, SealedTokens: []graveler.StagingToken{stagingToken1, stagingToken2, stagingToken3}}
 				updatedBranch, err := f(branchTest)
 				require.True(t, errors.Is(err, graveler.ErrDirtyBranch))
@@ -419,8 +425,8 @@ func TestGravelerCherryPick(t *testing.T) {
 	ctx := context.Background()
 
 	firstUpdateBranch := func(test *testutil.GravelerTest) {
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
 				branchTest := branch1
 				updatedBranch, err := f(&branchTest)
 				require.NoError(t, err)
@@ -443,6 +449,7 @@ func TestGravelerCherryPick(t *testing.T) {
 	}
 	t.Run("cherry-pick successful", func(t *testing.T) {
 		test := testutil.InitGravelerTest(t)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
 		firstUpdateBranch(test)
 		emptyStagingTokenCombo(test, 2)
 		test.RefManager.EXPECT().GetCommit(ctx, repository, commit1ID).Times(3).Return(&commit1, nil)
@@ -460,8 +467,8 @@ func TestGravelerCherryPick(t *testing.T) {
 			require.Equal(t, mr3ID, commit.MetaRangeID)
 			return commit3ID, nil
 		}).Times(1)
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
 				branchTest := &graveler.Branch{StagingToken: stagingToken4, CommitID: commit1ID, SealedTokens: []graveler.StagingToken{stagingToken1, stagingToken2, stagingToken3}}
 				updatedBranch, err := f(branchTest)
 				require.NoError(t, err)
@@ -489,9 +496,10 @@ func TestGravelerCommit_v2(t *testing.T) {
 		test := testutil.InitGravelerTest(t)
 		var updatedSealedBranch graveler.Branch
 		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_COMMIT).Return(false, nil)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
 
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
 				branchTest := branch1
 				updatedBranch, err := f(&branchTest)
 				updatedSealedBranch = *updatedBranch
@@ -502,8 +510,8 @@ func TestGravelerCommit_v2(t *testing.T) {
 				return nil
 			}).Times(1)
 
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
 				updatedBranch, err := f(&updatedSealedBranch)
 				require.NoError(t, err)
 				require.Equal(t, []graveler.StagingToken{}, updatedBranch.SealedTokens)
@@ -529,14 +537,13 @@ func TestGravelerCommit_v2(t *testing.T) {
 
 	t.Run("commit no changes", func(t *testing.T) {
 		test := testutil.InitGravelerTest(t)
-		var updatedSealedBranch graveler.Branch
 		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_COMMIT).Return(false, nil)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
 
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
 				branchTest := branch1
 				updatedBranch, err := f(&branchTest)
-				updatedSealedBranch = *updatedBranch
 				require.NoError(t, err)
 				require.Equal(t, []graveler.StagingToken{stagingToken1, stagingToken2, stagingToken3}, updatedBranch.SealedTokens)
 				require.NotEmpty(t, updatedBranch.StagingToken)
@@ -544,15 +551,6 @@ func TestGravelerCommit_v2(t *testing.T) {
 				return nil
 			}).Times(1)
 
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
-				updatedBranch, err := f(&updatedSealedBranch)
-				require.Error(t, err)
-				require.True(t, errors.Is(err, graveler.ErrNoChanges))
-				require.Nil(t, updatedBranch)
-				return err
-			}).Times(1).Return(graveler.ErrNoChanges)
-
 		test.RefManager.EXPECT().GetCommit(ctx, repository, commit1ID).Times(1).Return(&commit1, nil)
 		test.StagingManager.EXPECT().List(ctx, stagingToken1, gomock.Any()).Times(1).Return(testutils.NewFakeValueIterator([]*graveler.ValueRecord{}))
 		test.StagingManager.EXPECT().List(ctx, stagingToken2, gomock.Any()).Times(1).Return(testutils.NewFakeValueIterator([]*graveler.ValueRecord{}))
@@ -569,9 +567,10 @@ func TestGravelerCommit_v2(t *testing.T) {
 	t.Run("commit failed retryUpdateBranch", func(t *testing.T) {
 		test := testutil.InitGravelerTest(t)
 		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_COMMIT).Return(false, nil)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
 
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
 				branchTest := branch1
 				updatedBranch, err := f(&branchTest)
 				require.NoError(t, err)
@@ -581,7 +580,12 @@ func TestGravelerCommit_v2(t *testing.T) {
 				return nil
 			}).Times(1)
 
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).Times(graveler.BranchUpdateMaxTries).Return(kv.ErrPredicateFailed)
+		test.RefManager.EXPECT().GetCommit(ctx, repository, commit1ID).Times(1).Return(&commit1, nil)
+		test.StagingManager.EXPECT().List(ctx, stagingToken1, gomock.Any()).Times(1).Return(testutils.NewFakeValueIterator([]*graveler.ValueRecord{}))
+		test.StagingManager.EXPECT().List(ctx, stagingToken2, gomock.Any()).Times(1).Return(testutils.NewFakeValueIterator([]*graveler.ValueRecord{}))
+		test.StagingManager.EXPECT().List(ctx, stagingToken3, gomock.Any()).Times(1).Return(testutils.NewFakeValueIterator([]*graveler.ValueRecord{}))
+		test.CommittedManager.EXPECT().Commit(ctx, repository.StorageNamespace, mr1ID, gomock.Any()).Times(1).Return(mr2ID, graveler.DiffSummary{}, nil)
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).Times(graveler.BranchUpdateMaxTries).Return(kv.ErrPredicateFailed)
 
 		val, err := test.Sut.Commit(ctx, repository, branch1ID, graveler.CommitParams{})
 
@@ -589,14 +593,591 @@ func TestGravelerCommit_v2(t *testing.T) {
 		require.True(t, errors.Is(err, graveler.ErrTooManyTries))
 		require.Equal(t, val, graveler.CommitID(""))
 	})
+
+	t.Run("commit keeps newer sealed tokens", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		var updatedSealedBranch graveler.Branch
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_COMMIT).Return(false, nil)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
+
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+				branchTest := branch1
+				updatedBranch, err := f(&branchTest)
+				require.NoError(t, err)
+				updatedSealedBranch = *updatedBranch
+				return nil
+			}).Times(1)
+
+		test.RefManager.EXPECT().GetCommit(ctx, repository, commit1ID).Times(1).Return(&commit1, nil)
+		test.StagingManager.EXPECT().List(ctx, stagingToken1, gomock.Any()).Times(1).Return(testutils.NewFakeValueIterator([]*graveler.ValueRecord{}))
+		test.StagingManager.EXPECT().List(ctx, stagingToken2, gomock.Any()).Times(1).Return(testutils.NewFakeValueIterator([]*graveler.ValueRecord{}))
+		test.StagingManager.EXPECT().List(ctx, stagingToken3, gomock.Any()).Times(1).Return(testutils.NewFakeValueIterator([]*graveler.ValueRecord{}))
+		test.CommittedManager.EXPECT().Commit(ctx, repository.StorageNamespace, mr1ID, gomock.Any()).Times(1).Return(mr2ID, graveler.DiffSummary{}, nil)
+
+		// another commit sealed its staging token while the metarange was computed
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+				branchTest := updatedSealedBranch
+				branchTest.SealedTokens = append([]graveler.StagingToken{updatedSealedBranch.StagingToken}, updatedSealedBranch.SealedTokens...)
+				branchTest.StagingToken = stagingToken4
+				updatedBranch, err := f(&branchTest)
+				require.NoError(t, err)
+				require.Equal(t, commit2ID, updatedBranch.CommitID)
+				require.Equal(t, []graveler.StagingToken{updatedSealedBranch.StagingToken}, updatedBranch.SealedTokens)
+				return nil
+			}).Times(1)
+		test.RefManager.EXPECT().AddCommit(ctx, repository, gomock.Any()).DoAndReturn(func(ctx context.Context, repository *graveler.RepositoryRecord, commit graveler.Commit) (graveler.CommitID, error) {
+			require.Equal(t, mr2ID, commit.MetaRangeID)
+			require.Equal(t, graveler.CommitParents{commit1ID}, commit.Parents)
+			return commit2ID, nil
+		}).Times(1)
+		test.StagingManager.EXPECT().DropAsync(ctx, stagingToken1).Return(nil)
+		test.StagingManager.EXPECT().DropAsync(ctx, stagingToken2).Return(nil)
+		test.StagingManager.EXPECT().DropAsync(ctx, stagingToken3).Return(nil)
+
+		val, err := test.Sut.Commit(ctx, repository, branch1ID, graveler.CommitParams{})
+
+		require.NoError(t, err)
+		require.Equal(t, commit2ID, val)
+	})
+
+	t.Run("commit to branch held by transaction", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		var updatedSealedBranch graveler.Branch
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_COMMIT).Return(false, nil)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
+
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+				branchTest := branch1
+				updatedBranch, err := f(&branchTest)
+				require.NoError(t, err)
+				updatedSealedBranch = *updatedBranch
+				return nil
+			}).Times(1)
+		test.RefManager.EXPECT().GetCommit(ctx, repository, commit1ID).Times(1).Return(&commit1, nil)
+		test.StagingManager.EXPECT().List(ctx, gomock.Any(), gomock.Any()).Times(3).Return(testutils.NewFakeValueIterator([]*graveler.ValueRecord{}))
+		test.CommittedManager.EXPECT().Commit(ctx, repository.StorageNamespace, mr1ID, gomock.Any()).Times(1).Return(mr2ID, graveler.DiffSummary{}, nil)
+
+		// the branch is held by a transaction: no commit is added until it is released
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+				branchTest := updatedSealedBranch
+				branchTest.Transaction = "tx1"
+				_, err := f(&branchTest)
+				return err
+			}).Times(1)
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+				branchTest := updatedSealedBranch
+				_, err := f(&branchTest)
+				require.NoError(t, err)
+				return nil
+			}).Times(1)
+		test.RefManager.EXPECT().AddCommit(ctx, repository, gomock.Any()).Times(1).Return(commit2ID, nil)
+		test.StagingManager.EXPECT().DropAsync(ctx, gomock.Any()).Times(3).Return(nil)
+
+		val, err := test.Sut.Commit(ctx, repository, branch1ID, graveler.CommitParams{})
+
+		require.NoError(t, err)
+		require.Equal(t, commit2ID, val)
+	})
+
+	t.Run("commit after head moved", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		var updatedSealedBranch graveler.Branch
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_COMMIT).Return(false, nil)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
+
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+				branchTest := branch1
+				updatedBranch, err := f(&branchTest)
+				require.NoError(t, err)
+				updatedSealedBranch = *updatedBranch
+				return nil
+			}).Times(1)
+
+		test.RefManager.EXPECT().GetCommit(ctx, repository, commit1ID).Times(2).Return(&commit1, nil)
+		// both the pending and the committed sealed tokens wrote key1
+		test.StagingManager.EXPECT().List(ctx, stagingToken1, gomock.Any()).Times(3).DoAndReturn(func(context.Context, graveler.StagingToken, int) graveler.ValueIterator {
+			return testutils.NewFakeValueIterator([]*graveler.ValueRecord{{Key: key1, Value: value1}})
+		})
+		test.StagingManager.EXPECT().List(ctx, stagingToken2, gomock.Any()).Times(2).DoAndReturn(func(context.Context, graveler.StagingToken, int) graveler.ValueIterator {
+			return testutils.NewFakeValueIterator([]*graveler.ValueRecord{{Key: key1, Value: value2}})
+		})
+		test.StagingManager.EXPECT().List(ctx, stagingToken3, gomock.Any()).Times(2).DoAndReturn(func(context.Context, graveler.StagingToken, int) graveler.ValueIterator {
+			return testutils.NewFakeValueIterator([]*graveler.ValueRecord{})
+		})
+		test.CommittedManager.EXPECT().Commit(ctx, repository.StorageNamespace, mr1ID, gomock.Any()).Times(1).Return(mr2ID, graveler.DiffSummary{}, nil)
+
+		// an earlier commit of the older sealed tokens moved the head
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+				branchTest := updatedSealedBranch
+				branchTest.CommitID = commit3ID
+				branchTest.SealedTokens = []graveler.StagingToken{stagingToken1}
+				_, err := f(&branchTest)
+				require.Error(t, err)
+				return err
+			}).Times(1)
+
+		// the head moved only by the committed sealed tokens: no conflict
+		test.RefManager.EXPECT().GetCommit(ctx, repository, commit3ID).Times(2).Return(&commit3, nil)
+		test.CommittedManager.EXPECT().Diff(ctx, repository.StorageNamespace, mr1ID, mr3ID).Times(1).
+			Return(testutil.NewDiffIter([]graveler.Diff{{Key: key1, Type: graveler.DiffTypeAdded, Value: value2}}), nil)
+
+		// only the remaining sealed token is applied on the new head
+		test.CommittedManager.EXPECT().Commit(ctx, repository.StorageNamespace, mr3ID, gomock.Any()).Times(1).Return(mr4ID, graveler.DiffSummary{}, nil)
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+				branchTest := updatedSealedBranch
+				branchTest.CommitID = commit3ID
+				branchTest.SealedTokens = []graveler.StagingToken{stagingToken1}
+				updatedBranch, err := f(&branchTest)
+				require.NoError(t, err)
+				require.Equal(t, commit4ID, updatedBranch.CommitID)
+				require.Empty(t, updatedBranch.SealedTokens)
+				return nil
+			}).Times(1)
+		test.RefManager.EXPECT().AddCommit(ctx, repository, gomock.Any()).DoAndReturn(func(ctx context.Context, repository *graveler.RepositoryRecord, commit graveler.Commit) (graveler.CommitID, error) {
+			require.Equal(t, mr4ID, commit.MetaRangeID)
+			require.Equal(t, graveler.CommitParents{commit3ID}, commit.Parents)
+			return commit4ID, nil
+		}).Times(1)
+		test.StagingManager.EXPECT().DropAsync(ctx, stagingToken1).Return(nil)
+
+		val, err := test.Sut.Commit(ctx, repository, branch1ID, graveler.CommitParams{})
+
+		require.NoError(t, err)
+		require.Equal(t, commit4ID, val)
+	})
+
+	t.Run("commit after head moved with conflicting change", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		var updatedSealedBranch graveler.Branch
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_COMMIT).Return(false, nil)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
+
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+				branchTest := branch1
+				updatedBranch, err := f(&branchTest)
+				require.NoError(t, err)
+				updatedSealedBranch = *updatedBranch
+				return nil
+			}).Times(1)
+
+		test.RefManager.EXPECT().GetCommit(ctx, repository, commit1ID).Times(2).Return(&commit1, nil)
+		test.StagingManager.EXPECT().List(ctx, stagingToken1, gomock.Any()).Times(2).DoAndReturn(func(context.Context, graveler.StagingToken, int) graveler.ValueIterator {
+			return testutils.NewFakeValueIterator([]*graveler.ValueRecord{{Key: key1, Value: value1}})
+		})
+		test.StagingManager.EXPECT().List(ctx, stagingToken2, gomock.Any()).Times(2).DoAndReturn(func(context.Context, graveler.StagingToken, int) graveler.ValueIterator {
+			return testutils.NewFakeValueIterator([]*graveler.ValueRecord{})
+		})
+		test.StagingManager.EXPECT().List(ctx, stagingToken3, gomock.Any()).Times(2).DoAndReturn(func(context.Context, graveler.StagingToken, int) graveler.ValueIterator {
+			return testutils.NewFakeValueIterator([]*graveler.ValueRecord{})
+		})
+		test.CommittedManager.EXPECT().Commit(ctx, repository.StorageNamespace, mr1ID, gomock.Any()).Times(1).Return(mr2ID, graveler.DiffSummary{}, nil)
+
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+				branchTest := updatedSealedBranch
+				branchTest.CommitID = commit3ID
+				branchTest.SealedTokens = []graveler.StagingToken{stagingToken1}
+				_, err := f(&branchTest)
+				return err
+			}).Times(1)
+
+		// the head moved by a change to key1 that none of the committed sealed tokens wrote
+		test.RefManager.EXPECT().GetCommit(ctx, repository, commit3ID).Times(1).Return(&commit3, nil)
+		test.CommittedManager.EXPECT().Diff(ctx, repository.StorageNamespace, mr1ID, mr3ID).Times(1).
+			Return(testutil.NewDiffIter([]graveler.Diff{{Key: key1, Type: graveler.DiffTypeChanged, Value: value2}}), nil)
+
+		val, err := test.Sut.Commit(ctx, repository, branch1ID, graveler.CommitParams{})
+
+		require.ErrorIs(t, err, graveler.ErrConflictFound)
+		require.Equal(t, graveler.CommitID(""), val)
+	})
+
+	t.Run("commit included by another commit", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		var updatedSealedBranch graveler.Branch
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_COMMIT).Return(false, nil)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
+
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+				branchTest := branch1
+				updatedBranch, err := f(&branchTest)
+				require.NoError(t, err)
+				updatedSealedBranch = *updatedBranch
+				return nil
+			}).Times(1)
+
+		test.RefManager.EXPECT().GetCommit(ctx, repository, commit1ID).Times(1).Return(&commit1, nil)
+		test.StagingManager.EXPECT().List(ctx, stagingToken1, gomock.Any()).Times(1).Return(testutils.NewFakeValueIterator([]*graveler.ValueRecord{}))
+		test.StagingManager.EXPECT().List(ctx, stagingToken2, gomock.Any()).Times(1).Return(testutils.NewFakeValueIterator([]*graveler.ValueRecord{}))
+		test.StagingManager.EXPECT().List(ctx, stagingToken3, gomock.Any()).Times(1).Return(testutils.NewFakeValueIterator([]*graveler.ValueRecord{}))
+		test.CommittedManager.EXPECT().Commit(ctx, repository.StorageNamespace, mr1ID, gomock.Any()).Times(1).Return(mr2ID, graveler.DiffSummary{}, nil)
+
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+				branchTest := updatedSealedBranch
+				branchTest.CommitID = commit3ID
+				branchTest.SealedTokens = []graveler.StagingToken{}
+				_, err := f(&branchTest)
+				return err
+			}).Times(1)
+
+		val, err := test.Sut.Commit(ctx, repository, branch1ID, graveler.CommitParams{})
+
+		require.ErrorIs(t, err, graveler.ErrNoChanges)
+		require.Equal(t, graveler.CommitID(""), val)
+	})
+}
+
+func TestGravelerCommitBranches(t *testing.T) {
+	ctx := context.Background()
+	branch2 := graveler.Branch{
+		CommitID:     commit2ID,
+		StagingToken: stagingToken4,
+	}
+	commits := []graveler.BranchCommit{
+		{Repository: repository, BranchID: branch2ID, Params: graveler.CommitParams{Message: "second"}},
+		{Repository: repository, BranchID: branch1ID, Params: graveler.CommitParams{Message: "first"}},
+	}
+
+	// expectPrepare expects both branches to be held by the transaction, and returns the held branches
+	expectPrepare := func(t *testing.T, test *testutil.GravelerTest) map[graveler.BranchID]*graveler.Branch {
+		held := make(map[graveler.BranchID]*graveler.Branch)
+		for _, b := range []struct {
+			id     graveler.BranchID
+			branch graveler.Branch
+		}{{branch1ID, branch1}, {branch2ID, branch2}} {
+			b := b
+			test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, b.id, graveler.BranchProtectionBlockedAction_COMMIT).Return(false, nil)
+			test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, b.id, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
+			test.RefManager.EXPECT().BranchUpdate(ctx, repository, b.id, gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+					branchTest := b.branch
+					updatedBranch, err := f(&branchTest)
+					require.NoError(t, err)
+					require.NotEmpty(t, updatedBranch.Transaction)
+					require.Equal(t, b.branch.StagingToken, updatedBranch.SealedTokens[0])
+					held[b.id] = updatedBranch
+					return nil
+				}).Times(1)
+		}
+		test.RefManager.EXPECT().CreateTransaction(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, tx graveler.Transaction) error {
+				require.Equal(t, graveler.TransactionStatus_PENDING, tx.Status)
+				require.True(t, tx.Deadline.After(time.Now()))
+				return nil
+			}).Times(1)
+		test.RefManager.EXPECT().GetCommit(ctx, repository, commit1ID).Times(1).Return(&commit1, nil)
+		test.RefManager.EXPECT().GetCommit(ctx, repository, commit2ID).Times(1).Return(&commit2, nil)
+		for _, st := range []graveler.StagingToken{stagingToken1, stagingToken2, stagingToken3, stagingToken4} {
+			test.StagingManager.EXPECT().List(ctx, st, gomock.Any()).Times(1).Return(testutils.NewFakeValueIterator([]*graveler.ValueRecord{}))
+		}
+		return held
+	}
+
+	// expectRelease expects both held branches to be written back after their transaction ended
+	expectRelease := func(t *testing.T, test *testutil.GravelerTest, held map[graveler.BranchID]*graveler.Branch) {
+		for _, id := range []graveler.BranchID{branch1ID, branch2ID} {
+			id := id
+			test.RefManager.EXPECT().BranchUpdate(ctx, repository, id, gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+					// the ref manager resolves branches of ended transactions
+					branchTest := *held[id]
+					branchTest.Transaction = ""
+					_, err := f(&branchTest)
+					return err
+				}).Times(1)
+		}
+		test.RefManager.EXPECT().DeleteTransaction(ctx, gomock.Any()).Times(1).Return(nil)
+	}
+
+	t.Run("commit branches successful", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		held := expectPrepare(t, test)
+		test.CommittedManager.EXPECT().Commit(ctx, repository.StorageNamespace, mr1ID, gomock.Any()).Times(1).Return(mr3ID, graveler.DiffSummary{}, nil)
+		test.CommittedManager.EXPECT().Commit(ctx, repository.StorageNamespace, mr2ID, gomock.Any()).Times(1).Return(mr4ID, graveler.DiffSummary{}, nil)
+		test.RefManager.EXPECT().AddCommit(ctx, repository, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, commit graveler.Commit) (graveler.CommitID, error) {
+				return graveler.CommitID(commit.Message), nil
+			}).Times(2)
+		test.RefManager.EXPECT().TransactionUpdate(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, id graveler.TransactionID, f graveler.TransactionUpdateFunc) error {
+				tx, err := f(&graveler.Transaction{ID: id, Status: graveler.TransactionStatus_PENDING, Deadline: time.Now().Add(time.Minute)})
+				require.NoError(t, err)
+				require.Equal(t, graveler.TransactionStatus_COMMITTED, tx.Status)
+				require.Equal(t, []graveler.TransactionBranch{
+					{RepositoryID: repoID, BranchID: branch1ID, CommitID: "first", SealedTokens: held[branch1ID].SealedTokens},
+					{RepositoryID: repoID, BranchID: branch2ID, CommitID: "second", SealedTokens: held[branch2ID].SealedTokens},
+				}, tx.Branches)
+				return nil
+			}).Times(1)
+		expectRelease(t, test, held)
+		for _, st := range []graveler.StagingToken{stagingToken1, stagingToken2, stagingToken3, stagingToken4} {
+			test.StagingManager.EXPECT().DropAsync(ctx, st).Return(nil)
+		}
+
+		commitIDs, err := test.Sut.CommitBranches(ctx, commits)
+
+		require.NoError(t, err)
+		require.Equal(t, []graveler.CommitID{"second", "first"}, commitIDs)
+	})
+
+	t.Run("commit branches no changes", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		held := expectPrepare(t, test)
+		test.CommittedManager.EXPECT().Commit(ctx, repository.StorageNamespace, mr1ID, gomock.Any()).Times(1).Return(mr3ID, graveler.DiffSummary{}, nil)
+		test.RefManager.EXPECT().AddCommit(ctx, repository, gomock.Any()).Times(1).Return(graveler.CommitID("first"), nil)
+		test.CommittedManager.EXPECT().Commit(ctx, repository.StorageNamespace, mr2ID, gomock.Any()).Times(1).Return(graveler.MetaRangeID(""), graveler.DiffSummary{}, graveler.ErrNoChanges)
+		test.RefManager.EXPECT().TransactionUpdate(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, id graveler.TransactionID, f graveler.TransactionUpdateFunc) error {
+				tx, err := f(&graveler.Transaction{ID: id, Status: graveler.TransactionStatus_PENDING, Deadline: time.Now().Add(time.Minute)})
+				require.NoError(t, err)
+				require.Equal(t, graveler.TransactionStatus_ABORTED, tx.Status)
+				return nil
+			}).Times(1)
+		expectRelease(t, test, held)
+
+		commitIDs, err := test.Sut.CommitBranches(ctx, commits)
+
+		require.ErrorIs(t, err, graveler.ErrNoChanges)
+		require.Nil(t, commitIDs)
+	})
+
+	t.Run("commit branches aborted concurrently", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		held := expectPrepare(t, test)
+		test.CommittedManager.EXPECT().Commit(ctx, repository.StorageNamespace, mr1ID, gomock.Any()).Times(1).Return(mr3ID, graveler.DiffSummary{}, nil)
+		test.CommittedManager.EXPECT().Commit(ctx, repository.StorageNamespace, mr2ID, gomock.Any()).Times(1).Return(mr4ID, graveler.DiffSummary{}, nil)
+		test.RefManager.EXPECT().AddCommit(ctx, repository, gomock.Any()).Times(2).Return(graveler.CommitID("c"), nil)
+		test.RefManager.EXPECT().TransactionUpdate(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, id graveler.TransactionID, f graveler.TransactionUpdateFunc) error {
+				tx, err := f(&graveler.Transaction{ID: id, Status: graveler.TransactionStatus_ABORTED})
+				require.Nil(t, tx)
+				return err
+			}).Times(2)
+		expectRelease(t, test, held)
+
+		commitIDs, err := test.Sut.CommitBranches(ctx, commits)
+
+		require.ErrorIs(t, err, graveler.ErrTransactionAborted)
+		require.Nil(t, commitIDs)
+	})
+
+	t.Run("commit branches duplicate branch", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_COMMIT).Return(false, nil)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
+
+		commitIDs, err := test.Sut.CommitBranches(ctx, []graveler.BranchCommit{
+			{Repository: repository, BranchID: branch1ID},
+			{Repository: repository, BranchID: branch1ID},
+		})
+
+		require.ErrorIs(t, err, graveler.ErrInvalidValue)
+		require.Nil(t, commitIDs)
+	})
+}
+
+func TestGravelerSquashRewrite(t *testing.T) {
+	ctx := context.Background()
+	const commit5ID = graveler.CommitID("commit5")
+	// history of branch1, newest first: commit3 -> commit2 -> commit1 -> commit4
+	history := []*graveler.CommitRecord{
+		{CommitID: commit3ID, Commit: &graveler.Commit{Committer: "ingest", Message: "third", MetaRangeID: mr3ID, Parents: graveler.CommitParents{commit2ID}, Generation: 4, Metadata: graveler.Metadata{"a": "3"}}},
+		{CommitID: commit2ID, Commit: &graveler.Commit{Message: "second", MetaRangeID: mr2ID, Parents: graveler.CommitParents{commit1ID}, Generation: 3, Metadata: graveler.Metadata{"a": "2", "b": "2"}}},
+		{CommitID: commit1ID, Commit: &graveler.Commit{Message: "first", MetaRangeID: mr1ID, Parents: graveler.CommitParents{commit4ID}, Generation: 2}},
+		{CommitID: commit4ID, Commit: &graveler.Commit{MetaRangeID: mr4ID, Generation: 1}},
+	}
+	head := graveler.Branch{CommitID: commit3ID, StagingToken: stagingToken4}
+
+	expectCommitRef := func(test *testutil.GravelerTest, c *graveler.CommitRecord) {
+		rawRef := graveler.RawRef{BaseRef: string(c.CommitID)}
+		test.RefManager.EXPECT().ParseRef(graveler.Ref(c.CommitID)).Times(1).Return(rawRef, nil)
+		test.RefManager.EXPECT().ResolveRawRef(ctx, repository, rawRef).Times(1).Return(&graveler.ResolvedRef{Type: graveler.ReferenceTypeCommit, BranchRecord: graveler.BranchRecord{Branch: &graveler.Branch{CommitID: c.CommitID}}}, nil)
+		test.RefManager.EXPECT().GetCommit(ctx, repository, c.CommitID).Times(1).Return(c.Commit, nil)
+	}
+	// expectUpdate expects the branch to be sealed, and then updated by the operation
+	expectUpdate := func(t *testing.T, test *testutil.GravelerTest, expectedCommitID graveler.CommitID, expectedErr error) {
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_COMMIT).Return(false, nil)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).Times(1).Return(nil)
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+				branchTest := head
+				updatedBranch, err := f(&branchTest)
+				if expectedErr != nil {
+					require.ErrorIs(t, err, expectedErr)
+					return err
+				}
+				require.NoError(t, err)
+				require.Equal(t, expectedCommitID, updatedBranch.CommitID)
+				return nil
+			}).Times(1)
+		test.RefManager.EXPECT().Log(ctx, repository, commit3ID, true).Times(1).Return(testutil.NewFakeCommitIterator(history), nil)
+	}
+
+	t.Run("squash successful", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		expectCommitRef(test, history[2])
+		expectUpdate(t, test, commit5ID, nil)
+		test.RefManager.EXPECT().AddCommit(ctx, repository, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, commit graveler.Commit) (graveler.CommitID, error) {
+				require.Equal(t, "second\n\nthird", commit.Message)
+				require.Equal(t, mr3ID, commit.MetaRangeID)
+				require.Equal(t, graveler.CommitParents{commit1ID}, commit.Parents)
+				require.Equal(t, 3, commit.Generation)
+				require.Equal(t, graveler.Metadata{"a": "3", "b": "2", "c": "1", "squash-head": string(commit3ID)}, commit.Metadata)
+				return commit5ID, nil
+			}).Times(1)
+
+		val, err := test.Sut.Squash(ctx, repository, branch1ID, graveler.Ref(commit1ID), graveler.CommitParams{Metadata: graveler.Metadata{"c": "1"}})
+
+		require.NoError(t, err)
+		require.Equal(t, commit5ID, val)
+	})
+
+	t.Run("squash nothing", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		expectCommitRef(test, history[0])
+		expectUpdate(t, test, "", graveler.ErrNoChanges)
+
+		_, err := test.Sut.Squash(ctx, repository, branch1ID, graveler.Ref(commit3ID), graveler.CommitParams{})
+
+		require.ErrorIs(t, err, graveler.ErrNoChanges)
+	})
+
+	t.Run("squash not ancestor", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		expectCommitRef(test, &graveler.CommitRecord{CommitID: commit5ID, Commit: &commit3})
+		expectUpdate(t, test, "", graveler.ErrNotAncestor)
+
+		_, err := test.Sut.Squash(ctx, repository, branch1ID, graveler.Ref(commit5ID), graveler.CommitParams{})
+
+		require.ErrorIs(t, err, graveler.ErrNotAncestor)
+	})
+
+	// expectRewrite expects the branch to be sealed and its history read, and then, if the replay succeeds, the
+	// branch to be updated from moved (or head) to expectedCommitID
+	expectRewrite := func(t *testing.T, test *testutil.GravelerTest, moved *graveler.Branch, expectedCommitID graveler.CommitID, expectedErr error) {
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_COMMIT).Return(false, nil)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).Times(1).Return(nil)
+		test.RefManager.EXPECT().GetBranch(ctx, repository, branch1ID).Times(1).Return(&head, nil)
+		test.RefManager.EXPECT().Log(ctx, repository, commit3ID, true).Times(1).Return(testutil.NewFakeCommitIterator(history), nil)
+		if expectedCommitID == "" && moved == nil {
+			return
+		}
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+				branchTest := head
+				if moved != nil {
+					branchTest = *moved
+				}
+				updatedBranch, err := f(&branchTest)
+				if expectedErr != nil {
+					require.ErrorIs(t, err, expectedErr)
+					return err
+				}
+				require.NoError(t, err)
+				require.Equal(t, expectedCommitID, updatedBranch.CommitID)
+				return nil
+			}).Times(1)
+	}
+
+	t.Run("rewrite successful", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		expectCommitRef(test, history[1])
+		expectCommitRef(test, history[2])
+		expectRewrite(t, test, nil, commit5ID, nil)
+		test.CommittedManager.EXPECT().Merge(ctx, repository.StorageNamespace, mr1ID, mr3ID, mr2ID, graveler.MergeStrategyNone).Times(1).Return(mr4ID, nil)
+		test.RefManager.EXPECT().AddCommit(ctx, repository, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, commit graveler.Commit) (graveler.CommitID, error) {
+				require.Equal(t, "third", commit.Message)
+				require.Equal(t, "committer", commit.Committer)
+				require.Equal(t, mr4ID, commit.MetaRangeID)
+				require.Equal(t, graveler.CommitParents{commit1ID}, commit.Parents)
+				require.Equal(t, 3, commit.Generation)
+				require.Equal(t, graveler.Metadata{"a": "3", "rewrite-origin": string(commit3ID), "rewrite-committer": "ingest"}, commit.Metadata)
+				return commit5ID, nil
+			}).Times(1)
+
+		val, err := test.Sut.Rewrite(ctx, repository, branch1ID, []graveler.Ref{graveler.Ref(commit2ID)}, "committer")
+
+		require.NoError(t, err)
+		require.Equal(t, commit5ID, val)
+	})
+
+	t.Run("rewrite commit without changes", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		expectCommitRef(test, history[1])
+		expectCommitRef(test, history[2])
+		expectRewrite(t, test, nil, commit5ID, nil)
+		test.CommittedManager.EXPECT().Merge(ctx, repository.StorageNamespace, mr1ID, mr3ID, mr2ID, graveler.MergeStrategyNone).Times(1).Return(graveler.MetaRangeID(""), graveler.ErrNoChanges)
+		test.RefManager.EXPECT().AddCommit(ctx, repository, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, commit graveler.Commit) (graveler.CommitID, error) {
+				require.Equal(t, mr1ID, commit.MetaRangeID)
+				require.Equal(t, graveler.CommitParents{commit1ID}, commit.Parents)
+				return commit5ID, nil
+			}).Times(1)
+
+		val, err := test.Sut.Rewrite(ctx, repository, branch1ID, []graveler.Ref{graveler.Ref(commit2ID)}, "committer")
+
+		require.NoError(t, err)
+		require.Equal(t, commit5ID, val)
+	})
+
+	t.Run("rewrite branch moved", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		expectCommitRef(test, history[1])
+		expectCommitRef(test, history[2])
+		expectRewrite(t, test, &graveler.Branch{CommitID: commit5ID, StagingToken: stagingToken4}, "", graveler.ErrConflictFound)
+		test.CommittedManager.EXPECT().Merge(ctx, repository.StorageNamespace, mr1ID, mr3ID, mr2ID, graveler.MergeStrategyNone).Times(1).Return(mr4ID, nil)
+		test.RefManager.EXPECT().AddCommit(ctx, repository, gomock.Any()).Times(1).Return(graveler.CommitID("commit6"), nil)
+
+		_, err := test.Sut.Rewrite(ctx, repository, branch1ID, []graveler.Ref{graveler.Ref(commit2ID)}, "committer")
+
+		require.ErrorIs(t, err, graveler.ErrConflictFound)
+	})
+
+	t.Run("rewrite conflict", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		expectCommitRef(test, history[1])
+		expectCommitRef(test, history[2])
+		expectRewrite(t, test, nil, "", nil)
+		test.CommittedManager.EXPECT().Merge(ctx, repository.StorageNamespace, mr1ID, mr3ID, mr2ID, graveler.MergeStrategyNone).Times(1).Return(graveler.MetaRangeID(""), graveler.ErrConflictFound)
+
+		_, err := test.Sut.Rewrite(ctx, repository, branch1ID, []graveler.Ref{graveler.Ref(commit2ID)}, "committer")
+
+		require.ErrorIs(t, err, graveler.ErrConflictFound)
+	})
+
+	t.Run("rewrite not ancestor", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		expectCommitRef(test, history[1])
+		expectCommitRef(test, &graveler.CommitRecord{CommitID: commit5ID, Commit: &commit3})
+		expectRewrite(t, test, nil, "", nil)
+
+		_, err := test.Sut.Rewrite(ctx, repository, branch1ID, []graveler.Ref{graveler.Ref(commit2ID), graveler.Ref(commit5ID)}, "committer")
+
+		require.ErrorIs(t, err, graveler.ErrNotAncestor)
+	})
 }
 
 func TestGravelerImport(t *testing.T) {
 	ctx := context.Background()
 
 	firstUpdateBranch := func(test *testutil.GravelerTest) {
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
 				branchTest := branch1
 				updatedBranch, err := f(&branchTest)
 				require.NoError(t, err)
@@ -620,6 +1201,7 @@ func TestGravelerImport(t *testing.T) {
 
 	t.Run("import successful", func(t *testing.T) {
 		test := testutil.InitGravelerTest(t)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(false, nil)
 		firstUpdateBranch(test)
 		emptyStagingTokenCombo(test, 2)
 		test.RefManager.EXPECT().GetCommit(ctx, repository, commit1ID).Times(3).Return(&commit1, nil)
@@ -631,8 +1213,8 @@ func TestGravelerImport(t *testing.T) {
 			require.Equal(t, mr4ID, commit.MetaRangeID)
 			return commit4ID, nil
 		}).Times(1)
-		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any()).
-			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, f graveler.BranchUpdateFunc) error {
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
 				branchTest := &graveler.Branch{StagingToken: stagingToken4, CommitID: commit1ID, SealedTokens: []graveler.StagingToken{stagingToken1, stagingToken2, stagingToken3}}
 				updatedBranch, err := f(branchTest)
 				require.NoError(t, err)
@@ -652,3 +1234,208 @@ func TestGravelerImport(t *testing.T) {
 		require.Equal(t, commit4ID, graveler.CommitID(val.Ref()))
 	})
 }
+
+func TestGravelerSignedCommits(t *testing.T) {
+	ctx := context.Background()
+	signature := graveler.CommitSignature{CommitID: commit2ID, KeyFingerprint: "SHA256:fingerprint", Signature: []byte("signature")}
+	expectRefCommit2 := func(test *testutil.GravelerTest) {
+		test.RefManager.EXPECT().ParseRef(graveler.Ref(commit2ID)).Times(1).Return(rawRefCommit2, nil)
+		test.RefManager.EXPECT().ResolveRawRef(ctx, repository, rawRefCommit2).Times(1).Return(&graveler.ResolvedRef{Type: graveler.ReferenceTypeCommit, BranchRecord: graveler.BranchRecord{Branch: &graveler.Branch{CommitID: commit2ID}}}, nil)
+	}
+
+	t.Run("sign commit", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		test.RefManager.EXPECT().GetCommit(ctx, repository, commit2ID).Times(1).Return(&commit2, nil)
+		test.RefManager.EXPECT().AddCommitSignature(ctx, repository, signature).Times(1).Return(nil)
+
+		require.NoError(t, test.Sut.SignCommit(ctx, repository, signature))
+	})
+
+	t.Run("sign missing commit", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		test.RefManager.EXPECT().GetCommit(ctx, repository, commit2ID).Times(1).Return(nil, graveler.ErrCommitNotFound)
+
+		err := test.Sut.SignCommit(ctx, repository, signature)
+		require.ErrorIs(t, err, graveler.ErrCommitNotFound)
+	})
+
+	t.Run("commit requires signed commits", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_COMMIT).Return(false, nil)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(true, nil)
+
+		_, err := test.Sut.Commit(ctx, repository, branch1ID, graveler.CommitParams{})
+		require.ErrorIs(t, err, graveler.ErrUnsignedCommit)
+		require.ErrorIs(t, err, graveler.ErrProtectedBranch)
+	})
+
+	// the branch is at commit1, moving it to commit2 gains commit2 and its parent commit3
+	signedHistory := map[graveler.CommitID]*graveler.Commit{
+		commit1ID: {MetaRangeID: mr1ID, Generation: 2, Parents: []graveler.CommitID{commit4ID}},
+		commit2ID: {MetaRangeID: mr2ID, Generation: 3, Parents: []graveler.CommitID{commit3ID}},
+		commit3ID: {MetaRangeID: mr3ID, Generation: 2, Parents: []graveler.CommitID{commit4ID}},
+		commit4ID: {MetaRangeID: mr4ID, Generation: 1},
+	}
+	expectUpdateBranch := func(test *testutil.GravelerTest) {
+		expectRefCommit2(test)
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_UNSIGNED_COMMIT).Return(true, nil)
+		// seal the staging token, then move the branch
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).Times(1).Return(nil)
+		test.StagingManager.EXPECT().List(ctx, stagingToken1, gomock.Any()).AnyTimes().Return(testutils.NewFakeValueIterator(nil))
+		test.CommittedManager.EXPECT().List(ctx, repository.StorageNamespace, mr1ID).AnyTimes().Return(testutils.NewFakeValueIterator(nil), nil)
+		for commitID, commit := range signedHistory {
+			test.RefManager.EXPECT().GetCommit(ctx, repository, commitID).AnyTimes().Return(commit, nil)
+		}
+	}
+	moveBranch := func(test *testutil.GravelerTest) {
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+				_, err := f(&graveler.Branch{CommitID: commit1ID, StagingToken: stagingToken2, SealedTokens: []graveler.StagingToken{stagingToken1}})
+				return err
+			}).Times(1)
+	}
+
+	t.Run("update branch to unsigned commit", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		expectUpdateBranch(test)
+		moveBranch(test)
+		test.RefManager.EXPECT().GetCommitSignature(ctx, repository, commit2ID).Times(1).Return(nil, graveler.ErrCommitSignatureNotFound)
+
+		_, err := test.Sut.UpdateBranch(ctx, repository, branch1ID, graveler.Ref(commit2ID))
+		require.ErrorIs(t, err, graveler.ErrUnsignedCommit)
+	})
+
+	t.Run("update branch to signed commit with unsigned parent", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		expectUpdateBranch(test)
+		moveBranch(test)
+		test.RefManager.EXPECT().GetCommitSignature(ctx, repository, commit2ID).Times(1).Return(&signature, nil)
+		test.RefManager.EXPECT().GetCommitSignature(ctx, repository, commit3ID).Times(1).Return(nil, graveler.ErrCommitSignatureNotFound)
+
+		_, err := test.Sut.UpdateBranch(ctx, repository, branch1ID, graveler.Ref(commit2ID))
+		require.ErrorIs(t, err, graveler.ErrUnsignedCommit)
+	})
+
+	t.Run("update branch to signed commit", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		expectUpdateBranch(test)
+		moveBranch(test)
+		// commit1 and commit4 are already on the branch, only the commits it gains are checked
+		test.RefManager.EXPECT().GetCommitSignature(ctx, repository, commit2ID).Times(1).Return(&signature, nil)
+		test.RefManager.EXPECT().GetCommitSignature(ctx, repository, commit3ID).Times(1).Return(&signature, nil)
+		test.StagingManager.EXPECT().DropAsync(ctx, stagingToken1).Times(1)
+
+		branch, err := test.Sut.UpdateBranch(ctx, repository, branch1ID, graveler.Ref(commit2ID))
+		require.NoError(t, err)
+		require.Equal(t, commit2ID, branch.CommitID)
+	})
+}
+
+func TestGravelerRepositoryAccessState(t *testing.T) {
+	ctx := context.Background()
+	frozen := map[graveler.RepositoryAccessState]error{
+		graveler.RepositoryAccessStateReadOnly: graveler.ErrReadOnlyRepository,
+		graveler.RepositoryAccessStateArchived: graveler.ErrArchivedRepository,
+	}
+	for state, expectedErr := range frozen {
+		t.Run(state.String(), func(t *testing.T) {
+			test := testutil.InitGravelerTest(t)
+			test.RepositoryMetadata = graveler.RepositoryMetadata{graveler.MetadataKeyAccessState: state.String()}
+
+			err := test.Sut.Set(ctx, repository, branch1ID, key1, *value1)
+			require.ErrorIs(t, err, expectedErr)
+			err = test.Sut.Delete(ctx, repository, branch1ID, key1)
+			require.ErrorIs(t, err, expectedErr)
+			_, err = test.Sut.Commit(ctx, repository, branch1ID, graveler.CommitParams{})
+			require.ErrorIs(t, err, expectedErr)
+			_, err = test.Sut.Merge(ctx, repository, branch1ID, graveler.Ref(branch2ID), graveler.CommitParams{}, "")
+			require.ErrorIs(t, err, expectedErr)
+			_, err = test.Sut.CreateBranch(ctx, repository, branch2ID, graveler.Ref(commit1ID))
+			require.ErrorIs(t, err, expectedErr)
+			err = test.Sut.CreateTag(ctx, repository, "tag", commit1ID)
+			require.ErrorIs(t, err, expectedErr)
+			_, err = test.Sut.Import(ctx, repository, branch1ID, mr1ID, graveler.CommitParams{}, nil)
+			require.ErrorIs(t, err, expectedErr)
+			_, err = test.Sut.CommitBranches(ctx, []graveler.BranchCommit{{Repository: repository, BranchID: branch1ID}})
+			require.ErrorIs(t, err, expectedErr)
+			require.ErrorIs(t, err, graveler.ErrReadOnlyRepository)
+			err = test.Sut.CreateBranchProtectionRule(ctx, repository, "main", []graveler.BranchProtectionBlockedAction{graveler.BranchProtectionBlockedAction_COMMIT})
+			require.ErrorIs(t, err, expectedErr)
+			err = test.Sut.DeleteBranchProtectionRule(ctx, repository, "main")
+			require.ErrorIs(t, err, expectedErr)
+			err = test.Sut.SetGarbageCollectionRules(ctx, repository, &graveler.GarbageCollectionRules{})
+			require.ErrorIs(t, err, expectedErr)
+		})
+	}
+
+	t.Run("set state applies at once", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		state, err := test.Sut.GetRepositoryAccessState(ctx, repository)
+		require.NoError(t, err)
+		require.Equal(t, graveler.RepositoryAccessStateActive, state)
+
+		test.RefManager.EXPECT().SetRepositoryMetadata(ctx, repository, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, f graveler.RepoMetadataUpdateFunc) error {
+				_, err := f(graveler.RepositoryMetadata{})
+				return err
+			})
+		err = test.Sut.SetRepositoryMetadata(ctx, repository, func(metadata graveler.RepositoryMetadata) (graveler.RepositoryMetadata, error) {
+			metadata[graveler.MetadataKeyAccessState] = graveler.RepositoryAccessStateReadOnly.String()
+			return metadata, nil
+		})
+		require.NoError(t, err)
+
+		err = test.Sut.Set(ctx, repository, branch1ID, key1, *value1)
+		require.ErrorIs(t, err, graveler.ErrReadOnlyRepository)
+	})
+
+	t.Run("state set by another instance stops branch updates", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		state, err := test.Sut.GetRepositoryAccessState(ctx, repository)
+		require.NoError(t, err)
+		require.Equal(t, graveler.RepositoryAccessStateActive, state)
+
+		// the cached state is still active
+		test.RepositoryMetadata = graveler.RepositoryMetadata{graveler.MetadataKeyAccessState: graveler.RepositoryAccessStateReadOnly.String()}
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_STAGING_WRITE).Return(false, nil)
+		test.RefManager.EXPECT().BranchUpdate(ctx, repository, branch1ID, "reset", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, f graveler.BranchUpdateFunc) error {
+				_, err := f(&graveler.Branch{CommitID: commit1ID, StagingToken: "token"})
+				return err
+			})
+		err = test.Sut.Reset(ctx, repository, branch1ID)
+		require.ErrorIs(t, err, graveler.ErrReadOnlyRepository)
+
+		// the check updated the cache
+		state, err = test.Sut.GetRepositoryAccessState(ctx, repository)
+		require.NoError(t, err)
+		require.Equal(t, graveler.RepositoryAccessStateReadOnly, state)
+	})
+
+	t.Run("archived skips gc", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		test.RepositoryMetadata = graveler.RepositoryMetadata{graveler.MetadataKeyAccessState: graveler.RepositoryAccessStateArchived.String()}
+
+		_, err := test.Sut.SaveGarbageCollectionCommits(ctx, repository, "")
+		require.ErrorIs(t, err, graveler.ErrArchivedRepository)
+	})
+
+	t.Run("active", func(t *testing.T) {
+		test := testutil.InitGravelerTest(t)
+		test.RepositoryMetadata = graveler.RepositoryMetadata{graveler.MetadataKeyAccessState: graveler.RepositoryAccessStateActive.String()}
+		test.ProtectedBranchesManager.EXPECT().IsBlocked(ctx, repository, branch1ID, graveler.BranchProtectionBlockedAction_STAGING_WRITE).Return(true, nil)
+
+		err := test.Sut.Set(ctx, repository, branch1ID, key1, *value1)
+		require.ErrorIs(t, err, graveler.ErrWriteToProtectedBranch)
+	})
+}
+
+func TestParseRepositoryAccessState(t *testing.T) {
+	for _, s := range []string{"active", "read-only", "archived"} {
+		state, err := graveler.ParseRepositoryAccessState(s)
+		require.NoError(t, err)
+		require.Equal(t, s, state.String())
+	}
+	_, err := graveler.ParseRepositoryAccessState("frozen")
+	require.ErrorIs(t, err, graveler.ErrInvalidValue)
+}
@@ -7,6 +7,7 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	graveler "github.com/treeverse/lakefs/pkg/graveler"
@@ -200,6 +201,21 @@ func (mr *MockVersionControllerMockRecorder) AddCommit(ctx, repository, commit i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCommit", reflect.TypeOf((*MockVersionController)(nil).AddCommit), ctx, repository, commit)
 }
 
+// ApplyMetaRangeChanges mocks base method.
+func (m *MockVersionController) ApplyMetaRangeChanges(ctx context.Context, repository *graveler.RepositoryRecord, metaRangeID graveler.MetaRangeID, changes graveler.ValueIterator) (*graveler.MetaRangeID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyMetaRangeChanges", ctx, repository, metaRangeID, changes)
+	ret0, _ := ret[0].(*graveler.MetaRangeID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyMetaRangeChanges indicates an expected call of ApplyMetaRangeChanges.
+func (mr *MockVersionControllerMockRecorder) ApplyMetaRangeChanges(ctx, repository, metaRangeID, changes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyMetaRangeChanges", reflect.TypeOf((*MockVersionController)(nil).ApplyMetaRangeChanges), ctx, repository, metaRangeID, changes)
+}
+
 // CherryPick mocks base method.
 func (m *MockVersionController) CherryPick(ctx context.Context, repository *graveler.RepositoryRecord, id graveler.BranchID, reference graveler.Ref, number *int, committer string) (graveler.CommitID, error) {
 	m.ctrl.T.Helper()
@@ -230,6 +246,36 @@ func (mr *MockVersionControllerMockRecorder) Commit(ctx, repository, branchID, c
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockVersionController)(nil).Commit), ctx, repository, branchID, commitParams)
 }
 
+// CommitBranches mocks base method.
+func (m *MockVersionController) CommitBranches(ctx context.Context, commits []graveler.BranchCommit) ([]graveler.CommitID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitBranches", ctx, commits)
+	ret0, _ := ret[0].([]graveler.CommitID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CommitBranches indicates an expected call of CommitBranches.
+func (mr *MockVersionControllerMockRecorder) CommitBranches(ctx, commits interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitBranches", reflect.TypeOf((*MockVersionController)(nil).CommitBranches), ctx, commits)
+}
+
+// CommitChanges mocks base method.
+func (m *MockVersionController) CommitChanges(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, parentID graveler.CommitID, changes graveler.ValueIterator, commitParams graveler.CommitParams) (graveler.CommitID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitChanges", ctx, repository, branchID, parentID, changes, commitParams)
+	ret0, _ := ret[0].(graveler.CommitID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CommitChanges indicates an expected call of CommitChanges.
+func (mr *MockVersionControllerMockRecorder) CommitChanges(ctx, repository, branchID, parentID, changes, commitParams interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitChanges", reflect.TypeOf((*MockVersionController)(nil).CommitChanges), ctx, repository, branchID, parentID, changes, commitParams)
+}
+
 // Compare mocks base method.
 func (m *MockVersionController) Compare(ctx context.Context, repository *graveler.RepositoryRecord, left, right graveler.Ref) (graveler.DiffIterator, error) {
 	m.ctrl.T.Helper()
@@ -304,6 +350,21 @@ func (mr *MockVersionControllerMockRecorder) CreateRepository(ctx, repositoryID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRepository", reflect.TypeOf((*MockVersionController)(nil).CreateRepository), ctx, repositoryID, storageNamespace, branchID)
 }
 
+// CreateRepositoryFromTemplate mocks base method.
+func (m *MockVersionController) CreateRepositoryFromTemplate(ctx context.Context, repositoryID graveler.RepositoryID, storageNamespace graveler.StorageNamespace, defaultBranchID graveler.BranchID, template *graveler.RepositoryRecord, commitID graveler.CommitID, params graveler.CommitParams, rewrite graveler.ValueRewriteFunc) (*graveler.RepositoryRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRepositoryFromTemplate", ctx, repositoryID, storageNamespace, defaultBranchID, template, commitID, params, rewrite)
+	ret0, _ := ret[0].(*graveler.RepositoryRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRepositoryFromTemplate indicates an expected call of CreateRepositoryFromTemplate.
+func (mr *MockVersionControllerMockRecorder) CreateRepositoryFromTemplate(ctx, repositoryID, storageNamespace, defaultBranchID, template, commitID, params, rewrite interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRepositoryFromTemplate", reflect.TypeOf((*MockVersionController)(nil).CreateRepositoryFromTemplate), ctx, repositoryID, storageNamespace, defaultBranchID, template, commitID, params, rewrite)
+}
+
 // CreateTag mocks base method.
 func (m *MockVersionController) CreateTag(ctx context.Context, repository *graveler.RepositoryRecord, tagID graveler.TagID, commitID graveler.CommitID) error {
 	m.ctrl.T.Helper()
@@ -538,6 +599,21 @@ func (mr *MockVersionControllerMockRecorder) GetCommit(ctx, repository, commitID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommit", reflect.TypeOf((*MockVersionController)(nil).GetCommit), ctx, repository, commitID)
 }
 
+// GetCommitSignature mocks base method.
+func (m *MockVersionController) GetCommitSignature(ctx context.Context, repository *graveler.RepositoryRecord, commitID graveler.CommitID) (*graveler.CommitSignature, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommitSignature", ctx, repository, commitID)
+	ret0, _ := ret[0].(*graveler.CommitSignature)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommitSignature indicates an expected call of GetCommitSignature.
+func (mr *MockVersionControllerMockRecorder) GetCommitSignature(ctx, repository, commitID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommitSignature", reflect.TypeOf((*MockVersionController)(nil).GetCommitSignature), ctx, repository, commitID)
+}
+
 // GetGarbageCollectionRules mocks base method.
 func (m *MockVersionController) GetGarbageCollectionRules(ctx context.Context, repository *graveler.RepositoryRecord) (*graveler.GarbageCollectionRules, error) {
 	m.ctrl.T.Helper()
@@ -568,6 +644,21 @@ func (mr *MockVersionControllerMockRecorder) GetRepository(ctx, repositoryID int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepository", reflect.TypeOf((*MockVersionController)(nil).GetRepository), ctx, repositoryID)
 }
 
+// GetRepositoryAccessState mocks base method.
+func (m *MockVersionController) GetRepositoryAccessState(ctx context.Context, repository *graveler.RepositoryRecord) (graveler.RepositoryAccessState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRepositoryAccessState", ctx, repository)
+	ret0, _ := ret[0].(graveler.RepositoryAccessState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRepositoryAccessState indicates an expected call of GetRepositoryAccessState.
+func (mr *MockVersionControllerMockRecorder) GetRepositoryAccessState(ctx, repository interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepositoryAccessState", reflect.TypeOf((*MockVersionController)(nil).GetRepositoryAccessState), ctx, repository)
+}
+
 // GetRepositoryMetadata mocks base method.
 func (m *MockVersionController) GetRepositoryMetadata(ctx context.Context, repositoryID graveler.RepositoryID) (graveler.RepositoryMetadata, error) {
 	m.ctrl.T.Helper()
@@ -643,6 +734,21 @@ func (mr *MockVersionControllerMockRecorder) IsLinkAddressExpired(token interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsLinkAddressExpired", reflect.TypeOf((*MockVersionController)(nil).IsLinkAddressExpired), token)
 }
 
+// ListBranchReflog mocks base method.
+func (m *MockVersionController) ListBranchReflog(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID) (graveler.ReflogIterator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBranchReflog", ctx, repository, branchID)
+	ret0, _ := ret[0].(graveler.ReflogIterator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBranchReflog indicates an expected call of ListBranchReflog.
+func (mr *MockVersionControllerMockRecorder) ListBranchReflog(ctx, repository, branchID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBranchReflog", reflect.TypeOf((*MockVersionController)(nil).ListBranchReflog), ctx, repository, branchID)
+}
+
 // ListBranches mocks base method.
 func (m *MockVersionController) ListBranches(ctx context.Context, repository *graveler.RepositoryRecord) (graveler.BranchIterator, error) {
 	m.ctrl.T.Helper()
@@ -820,6 +926,21 @@ func (mr *MockVersionControllerMockRecorder) Revert(ctx, repository, branchID, r
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revert", reflect.TypeOf((*MockVersionController)(nil).Revert), ctx, repository, branchID, ref, parentNumber, commitParams)
 }
 
+// Rewrite mocks base method.
+func (m *MockVersionController) Rewrite(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, exclude []graveler.Ref, committer string) (graveler.CommitID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rewrite", ctx, repository, branchID, exclude, committer)
+	ret0, _ := ret[0].(graveler.CommitID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rewrite indicates an expected call of Rewrite.
+func (mr *MockVersionControllerMockRecorder) Rewrite(ctx, repository, branchID, exclude, committer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rewrite", reflect.TypeOf((*MockVersionController)(nil).Rewrite), ctx, repository, branchID, exclude, committer)
+}
+
 // SaveGarbageCollectionCommits mocks base method.
 func (m *MockVersionController) SaveGarbageCollectionCommits(ctx context.Context, repository *graveler.RepositoryRecord, previousRunID string) (*graveler.GarbageCollectionRunMetadata, error) {
 	m.ctrl.T.Helper()
@@ -875,6 +996,49 @@ func (mr *MockVersionControllerMockRecorder) SetLinkAddress(ctx, repository, tok
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLinkAddress", reflect.TypeOf((*MockVersionController)(nil).SetLinkAddress), ctx, repository, token)
 }
 
+// SetRepositoryMetadata mocks base method.
+func (m *MockVersionController) SetRepositoryMetadata(ctx context.Context, repository *graveler.RepositoryRecord, updateFunc graveler.RepoMetadataUpdateFunc) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRepositoryMetadata", ctx, repository, updateFunc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRepositoryMetadata indicates an expected call of SetRepositoryMetadata.
+func (mr *MockVersionControllerMockRecorder) SetRepositoryMetadata(ctx, repository, updateFunc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRepositoryMetadata", reflect.TypeOf((*MockVersionController)(nil).SetRepositoryMetadata), ctx, repository, updateFunc)
+}
+
+// SignCommit mocks base method.
+func (m *MockVersionController) SignCommit(ctx context.Context, repository *graveler.RepositoryRecord, signature graveler.CommitSignature) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignCommit", ctx, repository, signature)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SignCommit indicates an expected call of SignCommit.
+func (mr *MockVersionControllerMockRecorder) SignCommit(ctx, repository, signature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignCommit", reflect.TypeOf((*MockVersionController)(nil).SignCommit), ctx, repository, signature)
+}
+
+// Squash mocks base method.
+func (m *MockVersionController) Squash(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, from graveler.Ref, commitParams graveler.CommitParams) (graveler.CommitID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Squash", ctx, repository, branchID, from, commitParams)
+	ret0, _ := ret[0].(graveler.CommitID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Squash indicates an expected call of Squash.
+func (mr *MockVersionControllerMockRecorder) Squash(ctx, repository, branchID, from, commitParams interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Squash", reflect.TypeOf((*MockVersionController)(nil).Squash), ctx, repository, branchID, from, commitParams)
+}
+
 // UpdateBranch mocks base method.
 func (m *MockVersionController) UpdateBranch(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, ref graveler.Ref) (*graveler.Branch, error) {
 	m.ctrl.T.Helper()
@@ -972,6 +1136,21 @@ func (mr *MockPlumbingMockRecorder) GetRange(ctx, repository, rangeID interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRange", reflect.TypeOf((*MockPlumbing)(nil).GetRange), ctx, repository, rangeID)
 }
 
+// ListRanges mocks base method.
+func (m *MockPlumbing) ListRanges(ctx context.Context, repository *graveler.RepositoryRecord, metaRangeID graveler.MetaRangeID) ([]graveler.RangeID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRanges", ctx, repository, metaRangeID)
+	ret0, _ := ret[0].([]graveler.RangeID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRanges indicates an expected call of ListRanges.
+func (mr *MockPlumbingMockRecorder) ListRanges(ctx, repository, metaRangeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRanges", reflect.TypeOf((*MockPlumbing)(nil).ListRanges), ctx, repository, metaRangeID)
+}
+
 // StageObject mocks base method.
 func (m *MockPlumbing) StageObject(ctx context.Context, stagingToken string, object graveler.ValueRecord) error {
 	m.ctrl.T.Helper()
@@ -1053,6 +1232,21 @@ func (m *MockDumper) EXPECT() *MockDumperMockRecorder {
 	return m.recorder
 }
 
+// DumpBranchRecords mocks base method.
+func (m *MockDumper) DumpBranchRecords(ctx context.Context, repository *graveler.RepositoryRecord, branches []*graveler.BranchRecord) (*graveler.MetaRangeID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DumpBranchRecords", ctx, repository, branches)
+	ret0, _ := ret[0].(*graveler.MetaRangeID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DumpBranchRecords indicates an expected call of DumpBranchRecords.
+func (mr *MockDumperMockRecorder) DumpBranchRecords(ctx, repository, branches interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpBranchRecords", reflect.TypeOf((*MockDumper)(nil).DumpBranchRecords), ctx, repository, branches)
+}
+
 // DumpBranches mocks base method.
 func (m *MockDumper) DumpBranches(ctx context.Context, repository *graveler.RepositoryRecord) (*graveler.MetaRangeID, error) {
 	m.ctrl.T.Helper()
@@ -1068,6 +1262,21 @@ func (mr *MockDumperMockRecorder) DumpBranches(ctx, repository interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpBranches", reflect.TypeOf((*MockDumper)(nil).DumpBranches), ctx, repository)
 }
 
+// DumpCommitRecords mocks base method.
+func (m *MockDumper) DumpCommitRecords(ctx context.Context, repository *graveler.RepositoryRecord, commits []*graveler.CommitRecord) (*graveler.MetaRangeID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DumpCommitRecords", ctx, repository, commits)
+	ret0, _ := ret[0].(*graveler.MetaRangeID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DumpCommitRecords indicates an expected call of DumpCommitRecords.
+func (mr *MockDumperMockRecorder) DumpCommitRecords(ctx, repository, commits interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpCommitRecords", reflect.TypeOf((*MockDumper)(nil).DumpCommitRecords), ctx, repository, commits)
+}
+
 // DumpCommits mocks base method.
 func (m *MockDumper) DumpCommits(ctx context.Context, repository *graveler.RepositoryRecord) (*graveler.MetaRangeID, error) {
 	m.ctrl.T.Helper()
@@ -1083,6 +1292,21 @@ func (mr *MockDumperMockRecorder) DumpCommits(ctx, repository interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpCommits", reflect.TypeOf((*MockDumper)(nil).DumpCommits), ctx, repository)
 }
 
+// DumpTagRecords mocks base method.
+func (m *MockDumper) DumpTagRecords(ctx context.Context, repository *graveler.RepositoryRecord, tags []*graveler.TagRecord) (*graveler.MetaRangeID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DumpTagRecords", ctx, repository, tags)
+	ret0, _ := ret[0].(*graveler.MetaRangeID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DumpTagRecords indicates an expected call of DumpTagRecords.
+func (mr *MockDumperMockRecorder) DumpTagRecords(ctx, repository, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpTagRecords", reflect.TypeOf((*MockDumper)(nil).DumpTagRecords), ctx, repository, tags)
+}
+
 // DumpTags mocks base method.
 func (m *MockDumper) DumpTags(ctx context.Context, repository *graveler.RepositoryRecord) (*graveler.MetaRangeID, error) {
 	m.ctrl.T.Helper()
@@ -1163,6 +1387,20 @@ func (mr *MockLoaderMockRecorder) LoadTags(ctx, repository, metaRangeID interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadTags", reflect.TypeOf((*MockLoader)(nil).LoadTags), ctx, repository, metaRangeID)
 }
 
+// UnloadTag mocks base method.
+func (m *MockLoader) UnloadTag(ctx context.Context, repository *graveler.RepositoryRecord, tagID graveler.TagID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnloadTag", ctx, repository, tagID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnloadTag indicates an expected call of UnloadTag.
+func (mr *MockLoaderMockRecorder) UnloadTag(ctx, repository, tagID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnloadTag", reflect.TypeOf((*MockLoader)(nil).UnloadTag), ctx, repository, tagID)
+}
+
 // MockRepositoryIterator is a mock of RepositoryIterator interface.
 type MockRepositoryIterator struct {
 	ctrl     *gomock.Controller
@@ -1519,6 +1757,95 @@ func (mr *MockBranchIteratorMockRecorder) Value() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Value", reflect.TypeOf((*MockBranchIterator)(nil).Value))
 }
 
+// MockReflogIterator is a mock of ReflogIterator interface.
+type MockReflogIterator struct {
+	ctrl     *gomock.Controller
+	recorder *MockReflogIteratorMockRecorder
+}
+
+// MockReflogIteratorMockRecorder is the mock recorder for MockReflogIterator.
+type MockReflogIteratorMockRecorder struct {
+	mock *MockReflogIterator
+}
+
+// NewMockReflogIterator creates a new mock instance.
+func NewMockReflogIterator(ctrl *gomock.Controller) *MockReflogIterator {
+	mock := &MockReflogIterator{ctrl: ctrl}
+	mock.recorder = &MockReflogIteratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReflogIterator) EXPECT() *MockReflogIteratorMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockReflogIterator) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockReflogIteratorMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockReflogIterator)(nil).Close))
+}
+
+// Err mocks base method.
+func (m *MockReflogIterator) Err() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Err")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Err indicates an expected call of Err.
+func (mr *MockReflogIteratorMockRecorder) Err() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Err", reflect.TypeOf((*MockReflogIterator)(nil).Err))
+}
+
+// Next mocks base method.
+func (m *MockReflogIterator) Next() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Next")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Next indicates an expected call of Next.
+func (mr *MockReflogIteratorMockRecorder) Next() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Next", reflect.TypeOf((*MockReflogIterator)(nil).Next))
+}
+
+// SeekGE mocks base method.
+func (m *MockReflogIterator) SeekGE(id string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SeekGE", id)
+}
+
+// SeekGE indicates an expected call of SeekGE.
+func (mr *MockReflogIteratorMockRecorder) SeekGE(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SeekGE", reflect.TypeOf((*MockReflogIterator)(nil).SeekGE), id)
+}
+
+// Value mocks base method.
+func (m *MockReflogIterator) Value() *graveler.ReflogEntry {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Value")
+	ret0, _ := ret[0].(*graveler.ReflogEntry)
+	return ret0
+}
+
+// Value indicates an expected call of Value.
+func (mr *MockReflogIteratorMockRecorder) Value() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Value", reflect.TypeOf((*MockReflogIterator)(nil).Value))
+}
+
 // MockTagIterator is a mock of TagIterator interface.
 type MockTagIterator struct {
 	ctrl     *gomock.Controller
@@ -1824,18 +2151,32 @@ func (mr *MockRefManagerMockRecorder) AddCommit(ctx, repository, commit interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCommit", reflect.TypeOf((*MockRefManager)(nil).AddCommit), ctx, repository, commit)
 }
 
+// AddCommitSignature mocks base method.
+func (m *MockRefManager) AddCommitSignature(ctx context.Context, repository *graveler.RepositoryRecord, signature graveler.CommitSignature) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCommitSignature", ctx, repository, signature)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddCommitSignature indicates an expected call of AddCommitSignature.
+func (mr *MockRefManagerMockRecorder) AddCommitSignature(ctx, repository, signature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCommitSignature", reflect.TypeOf((*MockRefManager)(nil).AddCommitSignature), ctx, repository, signature)
+}
+
 // BranchUpdate mocks base method.
-func (m *MockRefManager) BranchUpdate(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, f graveler.BranchUpdateFunc) error {
+func (m *MockRefManager) BranchUpdate(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, operation string, f graveler.BranchUpdateFunc) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BranchUpdate", ctx, repository, branchID, f)
+	ret := m.ctrl.Call(m, "BranchUpdate", ctx, repository, branchID, operation, f)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // BranchUpdate indicates an expected call of BranchUpdate.
-func (mr *MockRefManagerMockRecorder) BranchUpdate(ctx, repository, branchID, f interface{}) *gomock.Call {
+func (mr *MockRefManagerMockRecorder) BranchUpdate(ctx, repository, branchID, operation, f interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BranchUpdate", reflect.TypeOf((*MockRefManager)(nil).BranchUpdate), ctx, repository, branchID, f)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BranchUpdate", reflect.TypeOf((*MockRefManager)(nil).BranchUpdate), ctx, repository, branchID, operation, f)
 }
 
 // CreateBareRepository mocks base method.
@@ -1896,6 +2237,20 @@ func (mr *MockRefManagerMockRecorder) CreateTag(ctx, repository, tagID, commitID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTag", reflect.TypeOf((*MockRefManager)(nil).CreateTag), ctx, repository, tagID, commitID)
 }
 
+// CreateTransaction mocks base method.
+func (m *MockRefManager) CreateTransaction(ctx context.Context, transaction graveler.Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransaction", ctx, transaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTransaction indicates an expected call of CreateTransaction.
+func (mr *MockRefManagerMockRecorder) CreateTransaction(ctx, transaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransaction", reflect.TypeOf((*MockRefManager)(nil).CreateTransaction), ctx, transaction)
+}
+
 // DeleteBranch mocks base method.
 func (m *MockRefManager) DeleteBranch(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID) error {
 	m.ctrl.T.Helper()
@@ -1966,6 +2321,20 @@ func (mr *MockRefManagerMockRecorder) DeleteTag(ctx, repository, tagID interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTag", reflect.TypeOf((*MockRefManager)(nil).DeleteTag), ctx, repository, tagID)
 }
 
+// DeleteTransaction mocks base method.
+func (m *MockRefManager) DeleteTransaction(ctx context.Context, transactionID graveler.TransactionID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTransaction", ctx, transactionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTransaction indicates an expected call of DeleteTransaction.
+func (mr *MockRefManagerMockRecorder) DeleteTransaction(ctx, transactionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTransaction", reflect.TypeOf((*MockRefManager)(nil).DeleteTransaction), ctx, transactionID)
+}
+
 // FindMergeBase mocks base method.
 func (m *MockRefManager) FindMergeBase(ctx context.Context, repository *graveler.RepositoryRecord, commitIDs ...graveler.CommitID) (*graveler.Commit, error) {
 	m.ctrl.T.Helper()
@@ -2046,6 +2415,21 @@ func (mr *MockRefManagerMockRecorder) GetCommit(ctx, repository, commitID interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommit", reflect.TypeOf((*MockRefManager)(nil).GetCommit), ctx, repository, commitID)
 }
 
+// GetCommitSignature mocks base method.
+func (m *MockRefManager) GetCommitSignature(ctx context.Context, repository *graveler.RepositoryRecord, commitID graveler.CommitID) (*graveler.CommitSignature, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommitSignature", ctx, repository, commitID)
+	ret0, _ := ret[0].(*graveler.CommitSignature)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommitSignature indicates an expected call of GetCommitSignature.
+func (mr *MockRefManagerMockRecorder) GetCommitSignature(ctx, repository, commitID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommitSignature", reflect.TypeOf((*MockRefManager)(nil).GetCommitSignature), ctx, repository, commitID)
+}
+
 // GetCommitByPrefix mocks base method.
 func (m *MockRefManager) GetCommitByPrefix(ctx context.Context, repository *graveler.RepositoryRecord, prefix graveler.CommitID) (*graveler.Commit, error) {
 	m.ctrl.T.Helper()
@@ -2121,6 +2505,21 @@ func (mr *MockRefManagerMockRecorder) IsLinkAddressExpired(token interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsLinkAddressExpired", reflect.TypeOf((*MockRefManager)(nil).IsLinkAddressExpired), token)
 }
 
+// ListBranchReflog mocks base method.
+func (m *MockRefManager) ListBranchReflog(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID) (graveler.ReflogIterator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBranchReflog", ctx, repository, branchID)
+	ret0, _ := ret[0].(graveler.ReflogIterator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBranchReflog indicates an expected call of ListBranchReflog.
+func (mr *MockRefManagerMockRecorder) ListBranchReflog(ctx, repository, branchID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBranchReflog", reflect.TypeOf((*MockRefManager)(nil).ListBranchReflog), ctx, repository, branchID)
+}
+
 // ListBranches mocks base method.
 func (m *MockRefManager) ListBranches(ctx context.Context, repository *graveler.RepositoryRecord) (graveler.BranchIterator, error) {
 	m.ctrl.T.Helper()
@@ -2226,6 +2625,20 @@ func (mr *MockRefManagerMockRecorder) ParseRef(ref interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseRef", reflect.TypeOf((*MockRefManager)(nil).ParseRef), ref)
 }
 
+// PruneBranchReflog mocks base method.
+func (m *MockRefManager) PruneBranchReflog(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, t time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneBranchReflog", ctx, repository, branchID, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PruneBranchReflog indicates an expected call of PruneBranchReflog.
+func (mr *MockRefManagerMockRecorder) PruneBranchReflog(ctx, repository, branchID, t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneBranchReflog", reflect.TypeOf((*MockRefManager)(nil).PruneBranchReflog), ctx, repository, branchID, t)
+}
+
 // RemoveCommit mocks base method.
 func (m *MockRefManager) RemoveCommit(ctx context.Context, repository *graveler.RepositoryRecord, commitID graveler.CommitID) error {
 	m.ctrl.T.Helper()
@@ -2256,17 +2669,17 @@ func (mr *MockRefManagerMockRecorder) ResolveRawRef(ctx, repository, rawRef inte
 }
 
 // SetBranch mocks base method.
-func (m *MockRefManager) SetBranch(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, branch graveler.Branch) error {
+func (m *MockRefManager) SetBranch(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, branch graveler.Branch, operation string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetBranch", ctx, repository, branchID, branch)
+	ret := m.ctrl.Call(m, "SetBranch", ctx, repository, branchID, branch, operation)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SetBranch indicates an expected call of SetBranch.
-func (mr *MockRefManagerMockRecorder) SetBranch(ctx, repository, branchID, branch interface{}) *gomock.Call {
+func (mr *MockRefManagerMockRecorder) SetBranch(ctx, repository, branchID, branch, operation interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBranch", reflect.TypeOf((*MockRefManager)(nil).SetBranch), ctx, repository, branchID, branch)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBranch", reflect.TypeOf((*MockRefManager)(nil).SetBranch), ctx, repository, branchID, branch, operation)
 }
 
 // SetLinkAddress mocks base method.
@@ -2297,6 +2710,20 @@ func (mr *MockRefManagerMockRecorder) SetRepositoryMetadata(ctx, repository, upd
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRepositoryMetadata", reflect.TypeOf((*MockRefManager)(nil).SetRepositoryMetadata), ctx, repository, updateFunc)
 }
 
+// TransactionUpdate mocks base method.
+func (m *MockRefManager) TransactionUpdate(ctx context.Context, transactionID graveler.TransactionID, f graveler.TransactionUpdateFunc) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransactionUpdate", ctx, transactionID, f)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransactionUpdate indicates an expected call of TransactionUpdate.
+func (mr *MockRefManagerMockRecorder) TransactionUpdate(ctx, transactionID, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransactionUpdate", reflect.TypeOf((*MockRefManager)(nil).TransactionUpdate), ctx, transactionID, f)
+}
+
 // VerifyLinkAddress mocks base method.
 func (m *MockRefManager) VerifyLinkAddress(ctx context.Context, repository *graveler.RepositoryRecord, token string) error {
 	m.ctrl.T.Helper()
@@ -2334,6 +2761,21 @@ func (m *MockCommittedManager) EXPECT() *MockCommittedManagerMockRecorder {
 	return m.recorder
 }
 
+// CloneMetaRange mocks base method.
+func (m *MockCommittedManager) CloneMetaRange(ctx context.Context, src, dst graveler.StorageNamespace, id graveler.MetaRangeID, rewrite graveler.ValueRewriteFunc) (graveler.MetaRangeID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloneMetaRange", ctx, src, dst, id, rewrite)
+	ret0, _ := ret[0].(graveler.MetaRangeID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloneMetaRange indicates an expected call of CloneMetaRange.
+func (mr *MockCommittedManagerMockRecorder) CloneMetaRange(ctx, src, dst, id, rewrite interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloneMetaRange", reflect.TypeOf((*MockCommittedManager)(nil).CloneMetaRange), ctx, src, dst, id, rewrite)
+}
+
 // Commit mocks base method.
 func (m *MockCommittedManager) Commit(ctx context.Context, ns graveler.StorageNamespace, baseMetaRangeID graveler.MetaRangeID, changes graveler.ValueIterator) (graveler.MetaRangeID, graveler.DiffSummary, error) {
 	m.ctrl.T.Helper()
@@ -2485,6 +2927,21 @@ func (mr *MockCommittedManagerMockRecorder) List(ctx, ns, rangeID interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCommittedManager)(nil).List), ctx, ns, rangeID)
 }
 
+// ListRanges mocks base method.
+func (m *MockCommittedManager) ListRanges(ctx context.Context, ns graveler.StorageNamespace, id graveler.MetaRangeID) ([]graveler.RangeID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRanges", ctx, ns, id)
+	ret0, _ := ret[0].([]graveler.RangeID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRanges indicates an expected call of ListRanges.
+func (mr *MockCommittedManagerMockRecorder) ListRanges(ctx, ns, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRanges", reflect.TypeOf((*MockCommittedManager)(nil).ListRanges), ctx, ns, id)
+}
+
 // Merge mocks base method.
 func (m *MockCommittedManager) Merge(ctx context.Context, ns graveler.StorageNamespace, destination, source, base graveler.MetaRangeID, strategy graveler.MergeStrategy) (graveler.MetaRangeID, error) {
 	m.ctrl.T.Helper()
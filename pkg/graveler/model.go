@@ -18,14 +18,20 @@ const (
 	addressesPrefix        = "link-addresses"
 	importsPrefix          = "imports"
 	repoMetadataPrefix     = "repo-metadata"
+	transactionsPrefix     = "transactions"
+	reflogPrefix           = "reflog"
+	signaturesPrefix       = "signatures"
 )
 
 //nolint:gochecknoinits
 func init() {
 	kv.MustRegisterType("graveler", "repos", (&RepositoryData{}).ProtoReflect().Type())
+	kv.MustRegisterType("graveler", "transactions", (&TransactionData{}).ProtoReflect().Type())
 	kv.MustRegisterType("*", "branches", (&BranchData{}).ProtoReflect().Type())
 	kv.MustRegisterType("*", "commits", (&CommitData{}).ProtoReflect().Type())
 	kv.MustRegisterType("*", "tags", (&TagData{}).ProtoReflect().Type())
+	kv.MustRegisterType("*", "reflog", (&BranchReflogData{}).ProtoReflect().Type())
+	kv.MustRegisterType("*", "signatures", (&CommitSignatureData{}).ProtoReflect().Type())
 	kv.MustRegisterType("*", "*", (&StagedEntryData{}).ProtoReflect().Type())
 }
 
@@ -61,10 +67,20 @@ func BranchPath(branchID BranchID) string {
 	return kv.FormatPath(branchesPrefix, branchID.String())
 }
 
+// BranchReflogPath - the path of a reflog entry of a branch.  With an empty id it is the prefix of the branch reflog
+func BranchReflogPath(branchID BranchID, id string) string {
+	return kv.FormatPath(reflogPrefix, branchID.String(), id)
+}
+
 func CommitPath(commitID CommitID) string {
 	return kv.FormatPath(commitsPrefix, commitID.String())
 }
 
+// CommitSignaturePath - the path of the signature of a commit, stored next to the commit as commits are immutable
+func CommitSignaturePath(commitID CommitID) string {
+	return kv.FormatPath(signaturesPrefix, commitID.String())
+}
+
 func SettingsPath(key string) string {
 	return kv.FormatPath(settingsPrefix, key)
 }
@@ -81,6 +97,11 @@ func RepoMetadataPath() string {
 	return repoMetadataPrefix
 }
 
+// TransactionPath - transactions are found under the common RepositoriesPartition, as they may span repositories
+func TransactionPath(transactionID TransactionID) string {
+	return kv.FormatPath(transactionsPrefix, string(transactionID))
+}
+
 func CommitFromProto(pb *CommitData) *Commit {
 	parents := make([]CommitID, 0)
 	for _, parent := range pb.Parents {
@@ -219,3 +240,89 @@ func ProtoFromRepositoryMetadata(metadata RepositoryMetadata) *RepoMetadata {
 		Metadata: metadata,
 	}
 }
+
+func TransactionFromProto(pb *TransactionData) *Transaction {
+	branches := make([]TransactionBranch, 0, len(pb.Branches))
+	for _, b := range pb.Branches {
+		sealedTokens := make([]StagingToken, 0, len(b.SealedTokens))
+		for _, st := range b.SealedTokens {
+			sealedTokens = append(sealedTokens, StagingToken(st))
+		}
+		branches = append(branches, TransactionBranch{
+			RepositoryID: RepositoryID(b.RepositoryId),
+			BranchID:     BranchID(b.BranchId),
+			CommitID:     CommitID(b.CommitId),
+			SealedTokens: sealedTokens,
+		})
+	}
+	return &Transaction{
+		ID:       TransactionID(pb.Id),
+		Status:   pb.Status,
+		Deadline: pb.Deadline.AsTime(),
+		Branches: branches,
+	}
+}
+
+func ProtoFromTransaction(tx *Transaction) *TransactionData {
+	branches := make([]*TransactionBranchData, 0, len(tx.Branches))
+	for _, b := range tx.Branches {
+		sealedTokens := make([]string, 0, len(b.SealedTokens))
+		for _, st := range b.SealedTokens {
+			sealedTokens = append(sealedTokens, st.String())
+		}
+		branches = append(branches, &TransactionBranchData{
+			RepositoryId: b.RepositoryID.String(),
+			BranchId:     b.BranchID.String(),
+			CommitId:     b.CommitID.String(),
+			SealedTokens: sealedTokens,
+		})
+	}
+	return &TransactionData{
+		Id:       string(tx.ID),
+		Status:   tx.Status,
+		Deadline: timestamppb.New(tx.Deadline),
+		Branches: branches,
+	}
+}
+
+func ReflogEntryFromProto(pb *BranchReflogData) *ReflogEntry {
+	return &ReflogEntry{
+		ID:           pb.Id,
+		BranchID:     BranchID(pb.BranchId),
+		OldCommitID:  CommitID(pb.OldCommitId),
+		NewCommitID:  CommitID(pb.NewCommitId),
+		Operation:    pb.Operation,
+		User:         pb.User,
+		CreationDate: pb.CreationDate.AsTime(),
+	}
+}
+
+func ProtoFromReflogEntry(entry *ReflogEntry) *BranchReflogData {
+	return &BranchReflogData{
+		Id:           entry.ID,
+		BranchId:     entry.BranchID.String(),
+		OldCommitId:  entry.OldCommitID.String(),
+		NewCommitId:  entry.NewCommitID.String(),
+		Operation:    entry.Operation,
+		User:         entry.User,
+		CreationDate: timestamppb.New(entry.CreationDate),
+	}
+}
+
+func CommitSignatureFromProto(pb *CommitSignatureData) *CommitSignature {
+	return &CommitSignature{
+		CommitID:       CommitID(pb.CommitId),
+		KeyFingerprint: pb.KeyFingerprint,
+		Signature:      pb.Signature,
+		CreationDate:   pb.CreationDate.AsTime(),
+	}
+}
+
+func ProtoFromCommitSignature(sig *CommitSignature) *CommitSignatureData {
+	return &CommitSignatureData{
+		CommitId:       sig.CommitID.String(),
+		KeyFingerprint: sig.KeyFingerprint,
+		Signature:      sig.Signature,
+		CreationDate:   timestamppb.New(sig.CreationDate),
+	}
+}
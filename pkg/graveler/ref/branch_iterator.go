@@ -20,6 +20,7 @@ type BranchSimpleIterator struct {
 	ctx           context.Context
 	store         kv.Store
 	itr           *kv.PrimaryIterator
+	repositoryID  graveler.RepositoryID
 	repoPartition string
 	value         *graveler.BranchRecord
 	err           error
@@ -37,6 +38,7 @@ func NewBranchSimpleIterator(ctx context.Context, store kv.Store, repo *graveler
 		ctx:           ctx,
 		store:         store,
 		itr:           it,
+		repositoryID:  repo.RepositoryID,
 		repoPartition: repoPartition,
 		value:         nil,
 		err:           nil,
@@ -62,9 +64,15 @@ func (bi *BranchSimpleIterator) Next() bool {
 		return false
 	}
 
+	branchID := graveler.BranchID(value.Id)
+	branch, err := resolveBranchTransaction(bi.ctx, bi.store, bi.repositoryID, branchID, branchFromProto(value))
+	if err != nil {
+		bi.err = err
+		return false
+	}
 	bi.value = &graveler.BranchRecord{
-		BranchID: graveler.BranchID(value.Id),
-		Branch:   branchFromProto(value),
+		BranchID: branchID,
+		Branch:   branch,
 	}
 	return true
 }
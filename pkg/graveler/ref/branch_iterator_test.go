@@ -29,7 +29,7 @@ func TestBranchSimpleIterator(t *testing.T) {
 
 	// prepare data
 	for _, b := range branches {
-		testutil.Must(t, r.SetBranch(ctx, repository, b, graveler.Branch{CommitID: "c1"}))
+		testutil.Must(t, r.SetBranch(ctx, repository, b, graveler.Branch{CommitID: "c1"}, "test"))
 	}
 
 	t.Run("listing all branches", func(t *testing.T) {
@@ -106,7 +106,7 @@ func TestBranchByCommitIterator(t *testing.T) {
 
 	// prepare data
 	for i, b := range branches {
-		testutil.Must(t, r.SetBranch(ctx, repository, b, graveler.Branch{CommitID: graveler.CommitID(branches[len(branches)-i-1])}))
+		testutil.Must(t, r.SetBranch(ctx, repository, b, graveler.Branch{CommitID: graveler.CommitID(branches[len(branches)-i-1])}, "test"))
 	}
 
 	t.Run("listing all branches", func(t *testing.T) {
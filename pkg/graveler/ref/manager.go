@@ -59,6 +59,7 @@ func branchFromProto(pb *graveler.BranchData) *graveler.Branch {
 		CommitID:     graveler.CommitID(pb.CommitId),
 		StagingToken: graveler.StagingToken(pb.StagingToken),
 		SealedTokens: sealedTokens,
+		Transaction:  graveler.TransactionID(pb.TransactionId),
 	}
 	return branch
 }
@@ -69,10 +70,11 @@ func protoFromBranch(branchID graveler.BranchID, b *graveler.Branch) *graveler.B
 		sealedTokens = append(sealedTokens, st.String())
 	}
 	branch := &graveler.BranchData{
-		Id:           branchID.String(),
-		CommitId:     b.CommitID.String(),
-		StagingToken: b.StagingToken.String(),
-		SealedTokens: sealedTokens,
+		Id:            branchID.String(),
+		CommitId:      b.CommitID.String(),
+		StagingToken:  b.StagingToken.String(),
+		SealedTokens:  sealedTokens,
+		TransactionId: string(b.Transaction),
 	}
 	return branch
 }
@@ -349,7 +351,8 @@ func (m *Manager) ResolveRawRef(ctx context.Context, repository *graveler.Reposi
 	return ResolveRawRef(ctx, m, m.addressProvider, repository, raw)
 }
 
-func (m *Manager) getBranchWithPredicate(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID) (*graveler.Branch, kv.Predicate, error) {
+// getStoredBranch returns the branch as it is stored, regardless of its transaction
+func (m *Manager) getStoredBranch(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID) (*graveler.Branch, kv.Predicate, error) {
 	key := fmt.Sprintf("GetBranch:%s:%s", repository.RepositoryID, branchID)
 	type branchPred struct {
 		*graveler.Branch
@@ -374,6 +377,18 @@ func (m *Manager) getBranchWithPredicate(ctx context.Context, repository *gravel
 	return branchWithPred.Branch, branchWithPred.Predicate, nil
 }
 
+func (m *Manager) getBranchWithPredicate(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID) (*graveler.Branch, kv.Predicate, error) {
+	stored, pred, err := m.getStoredBranch(ctx, repository, branchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	branch, err := resolveBranchTransaction(ctx, m.kvStore, repository.RepositoryID, branchID, stored)
+	if err != nil {
+		return nil, nil, err
+	}
+	return branch, pred, nil
+}
+
 func (m *Manager) GetBranch(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID) (*graveler.Branch, error) {
 	branch, _, err := m.getBranchWithPredicate(ctx, repository, branchID)
 	return branch, err
@@ -391,29 +406,147 @@ func (m *Manager) CreateBranch(ctx context.Context, repository *graveler.Reposit
 	return m.createBranch(ctx, graveler.RepoPartition(repository), branchID, branch)
 }
 
-func (m *Manager) SetBranch(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, branch graveler.Branch) error {
-	return kv.SetMsg(ctx, m.kvStore, graveler.RepoPartition(repository), []byte(graveler.BranchPath(branchID)), protoFromBranch(branchID, &branch))
+// SetBranch replaces branchID, or creates it if it does not exist, and records the move of its head as operation.  The
+// branch is replaced only if it did not change since it was read, so that the replacement cannot race with a
+// transaction that holds it.  It fails with ErrBranchLocked if the branch is held by a pending transaction, and with
+// ErrPredicateFailed if the branch changed while it was replaced.
+func (m *Manager) SetBranch(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, branch graveler.Branch, operation string) error {
+	var storedCommitID graveler.CommitID
+	stored, pred, err := m.getStoredBranch(ctx, repository, branchID)
+	switch {
+	case err == nil:
+		storedCommitID = stored.CommitID
+		resolved, err := resolveBranchTransaction(ctx, m.kvStore, repository.RepositoryID, branchID, stored)
+		if err != nil {
+			return err
+		}
+		if resolved.Transaction != "" {
+			return graveler.ErrBranchLocked
+		}
+	case errors.Is(err, graveler.ErrBranchNotFound):
+		// create only if the branch is still missing
+		pred = nil
+	default:
+		return err
+	}
+	err = kv.SetMsgIf(ctx, m.kvStore, graveler.RepoPartition(repository), []byte(graveler.BranchPath(branchID)), protoFromBranch(branchID, &branch), pred)
+	if errors.Is(err, kv.ErrPredicateFailed) {
+		return m.branchSetConflict(ctx, repository, branchID, err)
+	}
+	if err != nil {
+		return err
+	}
+	m.recordBranchMove(ctx, repository, branchID, storedCommitID, branch.CommitID, operation)
+	return nil
+}
+
+// branchSetConflict returns the error of a write of branchID that failed with err because the branch changed: if a
+// pending transaction now holds the branch it returns ErrBranchLocked, otherwise err.
+func (m *Manager) branchSetConflict(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, err error) error {
+	stored, _, getErr := m.getStoredBranch(ctx, repository, branchID)
+	if getErr != nil || stored.Transaction == "" {
+		return err
+	}
+	resolved, resolveErr := resolveBranchTransaction(ctx, m.kvStore, repository.RepositoryID, branchID, stored)
+	if resolveErr == nil && resolved.Transaction != "" {
+		return graveler.ErrBranchLocked
+	}
+	return err
+}
+
+// recordBranchMove adds a reflog entry for a move of the head of branchID made by operation that already happened.
+// Failing to record it does not fail the move.
+func (m *Manager) recordBranchMove(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, oldCommitID, newCommitID graveler.CommitID, operation string) {
+	if oldCommitID == "" {
+		// a new branch has no previous head
+		return
+	}
+	if err := m.addReflogEntry(ctx, repository, branchID, oldCommitID, newCommitID, operation); err != nil {
+		logging.FromContext(ctx).
+			WithFields(logging.Fields{"repository": repository.RepositoryID, "branch": branchID, "old_commit_id": oldCommitID, "new_commit_id": newCommitID}).
+			WithError(err).
+			Error("Failed to add reflog entry")
+	}
 }
 
-func (m *Manager) BranchUpdate(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, f graveler.BranchUpdateFunc) error {
-	b, pred, err := m.getBranchWithPredicate(ctx, repository, branchID)
+func (m *Manager) BranchUpdate(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, operation string, f graveler.BranchUpdateFunc) error {
+	stored, pred, err := m.getStoredBranch(ctx, repository, branchID)
 	if err != nil {
 		return err
 	}
+	// the head of a branch released by a committed transaction moves from the stored commit
+	storedCommitID := stored.CommitID
+	b, err := resolveBranchTransaction(ctx, m.kvStore, repository.RepositoryID, branchID, stored)
+	if err != nil {
+		return err
+	}
+	transaction := b.Transaction
+	sealedTokens := b.SealedTokens
+	commitID := b.CommitID
 	newBranch, err := f(b)
 	// return on error or nothing to update
 	if err != nil || newBranch == nil {
 		return err
 	}
-	return kv.SetMsgIf(ctx, m.kvStore, graveler.RepoPartition(repository), []byte(graveler.BranchPath(branchID)), protoFromBranch(branchID, newBranch), pred)
+	// a branch held by a pending transaction may only gain staged data until the transaction ends
+	if transaction != "" && (newBranch.Transaction != transaction || !keepsTransactionBranch(commitID, sealedTokens, newBranch)) {
+		return graveler.ErrBranchLocked
+	}
+	err = kv.SetMsgIf(ctx, m.kvStore, graveler.RepoPartition(repository), []byte(graveler.BranchPath(branchID)), protoFromBranch(branchID, newBranch), pred)
+	if err != nil {
+		return err
+	}
+	m.recordBranchMove(ctx, repository, branchID, storedCommitID, newBranch.CommitID, operation)
+	return nil
 }
 
+// DeleteBranch deletes branchID, unless a pending transaction holds it.  The store cannot delete conditionally, so
+// the branch is first held by a transaction of its own: no other transaction can hold it until it is deleted.  If
+// the deletion fails, that transaction is aborted, which releases the branch.
 func (m *Manager) DeleteBranch(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID) error {
-	_, err := m.GetBranch(ctx, repository, branchID)
+	stored, pred, err := m.getStoredBranch(ctx, repository, branchID)
+	if err != nil {
+		return err
+	}
+	branch, err := resolveBranchTransaction(ctx, m.kvStore, repository.RepositoryID, branchID, stored)
 	if err != nil {
 		return err
 	}
-	return m.kvStore.Delete(ctx, []byte(graveler.RepoPartition(repository)), []byte(graveler.BranchPath(branchID)))
+	if branch.Transaction != "" {
+		return graveler.ErrBranchLocked
+	}
+	tx := graveler.Transaction{
+		ID:       graveler.TransactionID(xid.New().String()),
+		Status:   graveler.TransactionStatus_PENDING,
+		Deadline: time.Now().Add(graveler.TransactionTimeout),
+	}
+	if err := m.CreateTransaction(ctx, tx); err != nil {
+		return err
+	}
+	branch.Transaction = tx.ID
+	err = kv.SetMsgIf(ctx, m.kvStore, graveler.RepoPartition(repository), []byte(graveler.BranchPath(branchID)), protoFromBranch(branchID, branch), pred)
+	if errors.Is(err, kv.ErrPredicateFailed) {
+		err = m.branchSetConflict(ctx, repository, branchID, err)
+	}
+	if err == nil {
+		err = m.kvStore.Delete(ctx, []byte(graveler.RepoPartition(repository)), []byte(graveler.BranchPath(branchID)))
+	}
+	if err != nil {
+		// failing to abort leaves the branch held until the transaction expires
+		_ = m.TransactionUpdate(ctx, tx.ID, func(tx *graveler.Transaction) (*graveler.Transaction, error) {
+			tx.Status = graveler.TransactionStatus_ABORTED
+			return tx, nil
+		})
+		return err
+	}
+	if err := m.DeleteTransaction(ctx, tx.ID); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("transaction_id", tx.ID).Warn("Failed to delete transaction")
+	}
+	return m.deleteBranchReflog(ctx, repository, branchID)
+}
+
+func (m *Manager) ListBranchReflog(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID) (graveler.ReflogIterator, error) {
+	return NewReflogIterator(ctx, m.kvStore, repository, branchID)
 }
 
 func (m *Manager) ListBranches(ctx context.Context, repository *graveler.RepositoryRecord) (graveler.BranchIterator, error) {
@@ -567,6 +700,28 @@ func (m *Manager) RemoveCommit(ctx context.Context, repository *graveler.Reposit
 	return m.kvStore.Delete(ctx, []byte(graveler.RepoPartition(repository)), []byte(commitKey))
 }
 
+func (m *Manager) AddCommitSignature(ctx context.Context, repository *graveler.RepositoryRecord, signature graveler.CommitSignature) error {
+	signatureKey := graveler.CommitSignaturePath(signature.CommitID)
+	err := kv.SetMsgIf(ctx, m.kvStore, graveler.RepoPartition(repository), []byte(signatureKey), graveler.ProtoFromCommitSignature(&signature), nil)
+	if errors.Is(err, kv.ErrPredicateFailed) {
+		return graveler.ErrCommitSignatureExists
+	}
+	return err
+}
+
+func (m *Manager) GetCommitSignature(ctx context.Context, repository *graveler.RepositoryRecord, commitID graveler.CommitID) (*graveler.CommitSignature, error) {
+	signatureKey := graveler.CommitSignaturePath(commitID)
+	data := graveler.CommitSignatureData{}
+	_, err := kv.GetMsg(ctx, m.kvStore, graveler.RepoPartition(repository), []byte(signatureKey), &data)
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil, graveler.ErrCommitSignatureNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return graveler.CommitSignatureFromProto(&data), nil
+}
+
 func (m *Manager) FindMergeBase(ctx context.Context, repository *graveler.RepositoryRecord, commitIDs ...graveler.CommitID) (*graveler.Commit, error) {
 	const allowedCommitsToCompare = 2
 	if len(commitIDs) != allowedCommitsToCompare {
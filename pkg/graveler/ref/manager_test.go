@@ -386,7 +386,7 @@ func TestManager_SetBranch(t *testing.T) {
 
 	testutil.Must(t, r.SetBranch(context.Background(), repository, "branch2", graveler.Branch{
 		CommitID: "c2",
-	}))
+	}, "test"))
 
 	b, err := r.GetBranch(context.Background(), repository, "branch2")
 	if err != nil {
@@ -400,7 +400,7 @@ func TestManager_SetBranch(t *testing.T) {
 	// overwrite
 	testutil.Must(t, r.SetBranch(context.Background(), repository, "branch2", graveler.Branch{
 		CommitID: "c3",
-	}))
+	}, "test"))
 
 	b, err = r.GetBranch(context.Background(), repository, "branch2")
 	if err != nil {
@@ -451,7 +451,7 @@ func TestManager_BranchUpdate(t *testing.T) {
 				b := graveler.Branch{
 					CommitID: "Another commit during validation",
 				}
-				_ = r.SetBranch(ctx, repository, branchID, b)
+				_ = r.SetBranch(ctx, repository, branchID, b, "test")
 				return &b, nil
 			},
 			err:            kv.ErrPredicateFailed,
@@ -470,9 +470,9 @@ func TestManager_BranchUpdate(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			testutil.Must(t, r.SetBranch(context.Background(), repository, branchID, graveler.Branch{
 				CommitID: commitID1,
-			}))
+			}, "test"))
 
-			err := r.BranchUpdate(ctx, repository, branchID, tt.f)
+			err := r.BranchUpdate(ctx, repository, branchID, "test", tt.f)
 			require.ErrorIs(t, err, tt.err)
 
 			b, err := r.GetBranch(context.Background(), repository, branchID)
@@ -494,7 +494,7 @@ func TestManager_DeleteBranch(t *testing.T) {
 
 	testutil.Must(t, r.SetBranch(ctx, repository, "branch2", graveler.Branch{
 		CommitID: "c2",
-	}))
+	}, "test"))
 
 	testutil.Must(t, r.DeleteBranch(ctx, repository, "branch2"))
 
@@ -504,6 +504,64 @@ func TestManager_DeleteBranch(t *testing.T) {
 	}
 }
 
+func TestManager_ListBranchReflog(t *testing.T) {
+	r, _ := testRefManager(t)
+	ctx := context.Background()
+	repository, err := r.CreateRepository(ctx, "repo1", graveler.Repository{
+		StorageNamespace: "s3://",
+		CreationDate:     time.Now(),
+		DefaultBranchID:  "main",
+	})
+	testutil.Must(t, err)
+
+	// creation is not recorded, every move of the head is
+	testutil.Must(t, r.SetBranch(ctx, repository, "branch1", graveler.Branch{CommitID: "c1"}, "create"))
+	testutil.Must(t, r.SetBranch(graveler.WithReflogUser(ctx, "user1"), repository, "branch1", graveler.Branch{CommitID: "c2"}, "restore_refs"))
+	testutil.Must(t, r.SetBranch(ctx, repository, "branch1", graveler.Branch{CommitID: "c2", StagingToken: "token"}, "update_branch_token"))
+	testutil.Must(t, r.BranchUpdate(ctx, repository, "branch1", "commit", func(b *graveler.Branch) (*graveler.Branch, error) {
+		b.CommitID = "c3"
+		return b, nil
+	}))
+
+	listReflog := func(branchID graveler.BranchID) []*graveler.ReflogEntry {
+		t.Helper()
+		it, err := r.ListBranchReflog(ctx, repository, branchID)
+		testutil.Must(t, err)
+		defer it.Close()
+		var entries []*graveler.ReflogEntry
+		for it.Next() {
+			entries = append(entries, it.Value())
+		}
+		testutil.Must(t, it.Err())
+		return entries
+	}
+
+	entries := listReflog("branch1")
+	require.Len(t, entries, 2)
+	require.Equal(t, graveler.CommitID("c2"), entries[0].OldCommitID)
+	require.Equal(t, graveler.CommitID("c3"), entries[0].NewCommitID)
+	require.Equal(t, graveler.CommitID("c1"), entries[1].OldCommitID)
+	require.Equal(t, graveler.CommitID("c2"), entries[1].NewCommitID)
+	require.Equal(t, "commit", entries[0].Operation)
+	require.Equal(t, "restore_refs", entries[1].Operation)
+	require.Equal(t, "user1", entries[1].User)
+	require.Less(t, entries[0].ID, entries[1].ID)
+
+	// pruning keeps the entries created after the threshold
+	testutil.Must(t, r.PruneBranchReflog(ctx, repository, "branch1", entries[1].CreationDate))
+	entries = listReflog("branch1")
+	require.Len(t, entries, 1)
+	require.Equal(t, graveler.CommitID("c3"), entries[0].NewCommitID)
+	testutil.Must(t, r.PruneBranchReflog(ctx, repository, "branch1", time.Now()))
+	require.Empty(t, listReflog("branch1"))
+
+	// deleting the branch deletes its reflog
+	testutil.Must(t, r.SetBranch(ctx, repository, "branch1", graveler.Branch{CommitID: "c4"}, "update_branch"))
+	require.Len(t, listReflog("branch1"), 1)
+	testutil.Must(t, r.DeleteBranch(ctx, repository, "branch1"))
+	require.Empty(t, listReflog("branch1"))
+}
+
 func TestManager_ListBranches(t *testing.T) {
 	r, _ := testRefManager(t)
 	repository, err := r.CreateRepository(context.Background(), "repo1", graveler.Repository{
@@ -516,7 +574,7 @@ func TestManager_ListBranches(t *testing.T) {
 	for _, b := range []graveler.BranchID{"a", "aa", "c", "b", "z", "f"} {
 		testutil.Must(t, r.SetBranch(context.Background(), repository, b, graveler.Branch{
 			CommitID: "c2",
-		}))
+		}, "test"))
 	}
 
 	iter, err := r.ListBranches(context.Background(), repository)
@@ -716,6 +774,49 @@ func TestManager_AddCommit(t *testing.T) {
 	}
 }
 
+func TestManager_CommitSignature(t *testing.T) {
+	r, _ := testRefManager(t)
+	ctx := context.Background()
+	repository, err := r.CreateRepository(ctx, "repo1", graveler.Repository{
+		StorageNamespace: "s3://",
+		CreationDate:     time.Now(),
+		DefaultBranchID:  "main",
+	})
+	require.NoError(t, err)
+	commitID, err := r.AddCommit(ctx, repository, graveler.Commit{
+		Committer:   "user1",
+		Message:     "message1",
+		MetaRangeID: "deadbeef123",
+	})
+	require.NoError(t, err)
+
+	_, err = r.GetCommitSignature(ctx, repository, commitID)
+	require.ErrorIs(t, err, graveler.ErrCommitSignatureNotFound)
+
+	signature := graveler.CommitSignature{
+		CommitID:       commitID,
+		KeyFingerprint: "SHA256:fingerprint",
+		Signature:      []byte("signature"),
+		CreationDate:   time.Now().UTC().Truncate(time.Second),
+	}
+	require.NoError(t, r.AddCommitSignature(ctx, repository, signature))
+	got, err := r.GetCommitSignature(ctx, repository, commitID)
+	require.NoError(t, err)
+	require.Equal(t, signature, *got)
+
+	// a commit is signed at most once
+	err = r.AddCommitSignature(ctx, repository, graveler.CommitSignature{CommitID: commitID, KeyFingerprint: "SHA256:other", Signature: []byte("other")})
+	require.ErrorIs(t, err, graveler.ErrCommitSignatureExists)
+	got, err = r.GetCommitSignature(ctx, repository, commitID)
+	require.NoError(t, err)
+	require.Equal(t, signature, *got)
+
+	// the commit itself is unchanged
+	commit, err := r.GetCommit(ctx, repository, commitID)
+	require.NoError(t, err)
+	require.Equal(t, "message1", commit.Message)
+}
+
 func TestManager_Log(t *testing.T) {
 	r, _ := testRefManager(t)
 	repository, err := r.CreateRepository(context.Background(), "repo1", graveler.Repository{
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/treeverse/lakefs/pkg/graveler"
 )
@@ -25,6 +27,9 @@ func parseRefModifier(buf string) (graveler.RefModifier, error) {
 			return graveler.RefModifier{}, graveler.ErrInvalidRef
 		}
 	case '@':
+		if strings.HasPrefix(buf, "@{") && strings.HasSuffix(buf, "}") {
+			return parseBracesModifier(buf[2 : len(buf)-1])
+		}
 		typ = graveler.RefModTypeAt
 		if len(buf) > 1 {
 			return graveler.RefModifier{}, graveler.ErrInvalidRef
@@ -46,6 +51,29 @@ func parseRefModifier(buf string) (graveler.RefModifier, error) {
 	}, nil
 }
 
+// parseBracesModifier parses the number of a '@{<n>}' reflog modifier, or the RFC3339 timestamp of a '@{<timestamp>}'
+// modifier
+func parseBracesModifier(value string) (graveler.RefModifier, error) {
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < 0 {
+			return graveler.RefModifier{}, fmt.Errorf("negative reflog index %d: %w", n, graveler.ErrInvalidRef)
+		}
+		return graveler.RefModifier{
+			Type:  graveler.RefModTypeReflog,
+			Value: n,
+		}, nil
+	}
+	timestamp := value
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return graveler.RefModifier{}, fmt.Errorf("could not parse timestamp %s: %w", timestamp, graveler.ErrInvalidRef)
+	}
+	return graveler.RefModifier{
+		Type: graveler.RefModTypeAsOf,
+		Time: t,
+	}, nil
+}
+
 func ParseRef(r graveler.Ref) (graveler.RawRef, error) {
 	ref := string(r)
 	parts := modifiersRegexp.FindAllString(ref, -1)
@@ -3,6 +3,7 @@ package ref_test
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/graveler/ref"
@@ -122,6 +123,63 @@ func TestParseRef(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:  "branch_as_of",
+			Input: "main@{2024-03-01T00:00:00Z}~2",
+			Expected: graveler.RawRef{
+				BaseRef: "main",
+				Modifiers: []graveler.RefModifier{
+					{
+						Type: graveler.RefModTypeAsOf,
+						Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+					},
+					{
+						Type:  graveler.RefModTypeTilde,
+						Value: 2,
+					},
+				},
+			},
+		},
+		{
+			Name:  "branch_as_of_offset",
+			Input: "main@{2024-03-01T02:00:00+02:00}",
+			Expected: graveler.RawRef{
+				BaseRef: "main",
+				Modifiers: []graveler.RefModifier{
+					{
+						Type: graveler.RefModTypeAsOf,
+						Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+					},
+				},
+			},
+		},
+		{
+			Name:        "branch_invalid_as_of",
+			Input:       "main@{yesterday}",
+			ExpectedErr: graveler.ErrInvalidRef,
+		},
+		{
+			Name:  "branch_reflog",
+			Input: "main@{1}~2",
+			Expected: graveler.RawRef{
+				BaseRef: "main",
+				Modifiers: []graveler.RefModifier{
+					{
+						Type:  graveler.RefModTypeReflog,
+						Value: 1,
+					},
+					{
+						Type:  graveler.RefModTypeTilde,
+						Value: 2,
+					},
+				},
+			},
+		},
+		{
+			Name:        "branch_negative_reflog",
+			Input:       "main@{-1}",
+			ExpectedErr: graveler.ErrInvalidRef,
+		},
 		{
 			Name:        "no_base",
 			Input:       "^^^3",
@@ -164,6 +222,10 @@ func TestParseRef(t *testing.T) {
 					t.Fatalf("unexpected modifier at index %d: expected value %d got %d",
 						i, cas.Expected.Modifiers[i].Value, m.Value)
 				}
+				if !m.Time.Equal(cas.Expected.Modifiers[i].Time) {
+					t.Fatalf("unexpected modifier at index %d: expected time %s got %s",
+						i, cas.Expected.Modifiers[i].Time, m.Time)
+				}
 			}
 		})
 	}
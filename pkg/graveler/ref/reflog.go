@@ -0,0 +1,158 @@
+package ref
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/kv"
+)
+
+// newReflogID returns an ID that orders reflog entries created at t before entries created earlier
+func newReflogID(t time.Time) string {
+	return fmt.Sprintf("%016x", uint64(math.MaxInt64-t.UnixNano()))
+}
+
+// addReflogEntry records that operation moved the head of branchID from oldCommitID to newCommitID, as made by the
+// user of ctx
+func (m *Manager) addReflogEntry(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, oldCommitID, newCommitID graveler.CommitID, operation string) error {
+	if oldCommitID == newCommitID {
+		return nil
+	}
+	now := time.Now()
+	entry := &graveler.ReflogEntry{
+		ID:           newReflogID(now),
+		BranchID:     branchID,
+		OldCommitID:  oldCommitID,
+		NewCommitID:  newCommitID,
+		Operation:    operation,
+		User:         graveler.ReflogUserFromContext(ctx),
+		CreationDate: now,
+	}
+	return kv.SetMsg(ctx, m.kvStore, graveler.RepoPartition(repository), []byte(graveler.BranchReflogPath(branchID, entry.ID)), graveler.ProtoFromReflogEntry(entry))
+}
+
+func (m *Manager) deleteBranchReflog(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID) error {
+	it, err := NewReflogIterator(ctx, m.kvStore, repository, branchID)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	return m.deleteReflogEntries(ctx, repository, branchID, it)
+}
+
+// PruneBranchReflog deletes the reflog entries of branchID created at or before t
+func (m *Manager) PruneBranchReflog(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, t time.Time) error {
+	it, err := NewReflogIterator(ctx, m.kvStore, repository, branchID)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	// entries are ordered newest first: the entries created at or before t are the tail
+	it.SeekGE(newReflogID(t))
+	return m.deleteReflogEntries(ctx, repository, branchID, it)
+}
+
+func (m *Manager) deleteReflogEntries(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, it *ReflogIterator) error {
+	repoPartition := []byte(graveler.RepoPartition(repository))
+	for it.Next() {
+		err := m.kvStore.Delete(ctx, repoPartition, []byte(graveler.BranchReflogPath(branchID, it.Value().ID)))
+		if err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// ReflogIterator iterates over the reflog entries of a branch, newest first
+type ReflogIterator struct {
+	ctx           context.Context
+	it            kv.MessageIterator
+	err           error
+	value         *graveler.ReflogEntry
+	repoPartition string
+	branchID      graveler.BranchID
+	store         kv.Store
+	closed        bool
+}
+
+func NewReflogIterator(ctx context.Context, store kv.Store, repo *graveler.RepositoryRecord, branchID graveler.BranchID) (*ReflogIterator, error) {
+	repoPartition := graveler.RepoPartition(repo)
+	it, err := kv.NewPrimaryIterator(ctx, store, (&graveler.BranchReflogData{}).ProtoReflect().Type(),
+		repoPartition,
+		[]byte(graveler.BranchReflogPath(branchID, "")), kv.IteratorOptionsFrom([]byte("")))
+	if err != nil {
+		return nil, err
+	}
+	return &ReflogIterator{
+		ctx:           ctx,
+		it:            it,
+		store:         store,
+		repoPartition: repoPartition,
+		branchID:      branchID,
+		closed:        false,
+	}, nil
+}
+
+func (i *ReflogIterator) Next() bool {
+	if i.Err() != nil || i.closed {
+		return false
+	}
+	if !i.it.Next() {
+		i.value = nil
+		return false
+	}
+	e := i.it.Entry()
+	if e == nil {
+		i.err = graveler.ErrReadingFromStore
+		return false
+	}
+	entry, ok := e.Value.(*graveler.BranchReflogData)
+	if !ok {
+		i.err = graveler.ErrReadingFromStore
+		return false
+	}
+	i.value = graveler.ReflogEntryFromProto(entry)
+	return true
+}
+
+func (i *ReflogIterator) SeekGE(id string) {
+	if i.Err() != nil {
+		return
+	}
+	i.Close()
+	it, err := kv.NewPrimaryIterator(i.ctx, i.store, (&graveler.BranchReflogData{}).ProtoReflect().Type(),
+		i.repoPartition,
+		[]byte(graveler.BranchReflogPath(i.branchID, "")), kv.IteratorOptionsFrom([]byte(graveler.BranchReflogPath(i.branchID, id))))
+	i.it = it
+	i.err = err
+	i.value = nil
+	i.closed = err != nil
+}
+
+func (i *ReflogIterator) Value() *graveler.ReflogEntry {
+	if i.Err() != nil {
+		return nil
+	}
+	return i.value
+}
+
+func (i *ReflogIterator) Err() error {
+	if i.err != nil {
+		return i.err
+	}
+	if !i.closed {
+		return i.it.Err()
+	}
+	return nil
+}
+
+func (i *ReflogIterator) Close() {
+	if i.closed {
+		return
+	}
+	i.it.Close()
+	i.closed = true
+}
@@ -3,7 +3,9 @@ package ref
 import (
 	"context"
 	"errors"
+	"fmt"
 	"regexp"
+	"time"
 
 	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/ident"
@@ -14,6 +16,7 @@ type Store interface {
 	GetTag(ctx context.Context, repository *graveler.RepositoryRecord, tagID graveler.TagID) (*graveler.CommitID, error)
 	GetCommitByPrefix(ctx context.Context, repository *graveler.RepositoryRecord, prefix graveler.CommitID) (*graveler.Commit, error)
 	GetCommit(ctx context.Context, repository *graveler.RepositoryRecord, prefix graveler.CommitID) (*graveler.Commit, error)
+	ListBranchReflog(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID) (graveler.ReflogIterator, error)
 }
 
 type revResolverFunc func(context.Context, Store, ident.AddressProvider, *graveler.RepositoryRecord, string) (*graveler.ResolvedRef, error)
@@ -55,7 +58,7 @@ func ResolveRawRef(ctx context.Context, store Store, addressProvider ident.Addre
 		return rr, nil
 	}
 	baseCommit := rr.CommitID
-	for _, mod := range rawRef.Modifiers {
+	for i, mod := range rawRef.Modifiers {
 		// lastly, apply modifier
 		switch mod.Type {
 		case graveler.RefModTypeAt:
@@ -101,6 +104,20 @@ func ResolveRawRef(ctx context.Context, store Store, addressProvider ident.Addre
 				}
 				baseCommit = commit.Parents[0]
 			}
+		case graveler.RefModTypeReflog:
+			// the reflog belongs to the branch, so it applies only to the branch itself
+			if rr.Type != graveler.ReferenceTypeBranch || i != 0 {
+				return nil, graveler.ErrInvalidRef
+			}
+			baseCommit, err = resolveReflog(ctx, store, repository, rr.BranchID, baseCommit, mod.Value)
+			if err != nil {
+				return nil, err
+			}
+		case graveler.RefModTypeAsOf:
+			baseCommit, err = resolveAsOf(ctx, store, repository, baseCommit, mod.Time)
+			if err != nil {
+				return nil, err
+			}
 		case graveler.RefModTypeCaret:
 			if mod.Value == 0 {
 				// ^0 = the commit itself
@@ -132,6 +149,45 @@ func ResolveRawRef(ctx context.Context, store Store, addressProvider ident.Addre
 	}, nil
 }
 
+// resolveAsOf walks the first-parent history of commitID back to the last commit created at or before t.  Each step
+// moves to a lower generation, so the walk ends at the first commit of the repository at the latest.
+func resolveAsOf(ctx context.Context, store Store, repository *graveler.RepositoryRecord, commitID graveler.CommitID, t time.Time) (graveler.CommitID, error) {
+	for {
+		commit, err := store.GetCommit(ctx, repository, commitID)
+		if err != nil {
+			return "", err
+		}
+		if !commit.CreationDate.After(t) {
+			return commitID, nil
+		}
+		if len(commit.Parents) == 0 {
+			return "", fmt.Errorf("no commit at or before %s: %w", t.Format(time.RFC3339), graveler.ErrNotFound)
+		}
+		commitID = commit.Parents[0]
+	}
+}
+
+// resolveReflog returns the n'th previous head of branchID, whose current head is commitID
+func resolveReflog(ctx context.Context, store Store, repository *graveler.RepositoryRecord, branchID graveler.BranchID, commitID graveler.CommitID, n int) (graveler.CommitID, error) {
+	if n == 0 {
+		return commitID, nil
+	}
+	it, err := store.ListBranchReflog(ctx, repository, branchID)
+	if err != nil {
+		return "", err
+	}
+	defer it.Close()
+	for i := 1; it.Next(); i++ {
+		if i == n {
+			return it.Value().OldCommitID, nil
+		}
+	}
+	if err := it.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("reflog of branch %s has fewer than %d entries: %w", branchID, n, graveler.ErrNotFound)
+}
+
 func revResolveCommitPrefix(ctx context.Context, store Store, addressProvider ident.AddressProvider, repository *graveler.RepositoryRecord, rev string) (*graveler.ResolvedRef, error) {
 	if !isAHash(rev) {
 		return nil, nil
@@ -3,6 +3,7 @@ package ref_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -99,13 +100,13 @@ func TestResolveRawRef(t *testing.T) {
 	testutil.Must(t, r.SetBranch(ctx, repository, "branch1", graveler.Branch{
 		CommitID:     branch1CommitID,
 		StagingToken: "token1",
-	}))
+	}, "test"))
 
 	branch2CommitID := commitLog[16]
 	testutil.Must(t, r.SetBranch(ctx, repository, "branch2", graveler.Branch{
 		CommitID:     branch2CommitID,
 		StagingToken: "token2",
-	}))
+	}, "test"))
 
 	tagCommitID := commitLog[9]
 	testutil.Must(t, r.CreateTag(ctx, repository, "v1.0", tagCommitID))
@@ -118,7 +119,7 @@ func TestResolveRawRef(t *testing.T) {
 	testutil.Must(t, r.SetBranch(ctx, repository, graveler.BranchID(branch3Name), graveler.Branch{
 		CommitID:     branch3CommitID,
 		StagingToken: "token3",
-	}))
+	}, "test"))
 
 	tag2Name := string(commitLog[6])[:10]
 	tag2CommitID := commitLog[8]
@@ -236,6 +237,41 @@ func TestResolveRawRef(t *testing.T) {
 			Ref:              graveler.Ref(commitCommitID[:5] + "~2"),
 			ExpectedCommitID: commitLog[13],
 		},
+		{
+			Name:             "branch_as_of",
+			Ref:              graveler.Ref("branch1@{2020-12-01T15:10:00Z}"),
+			ExpectedCommitID: commitLog[9],
+		},
+		{
+			Name:             "branch_as_of_between_commits",
+			Ref:              graveler.Ref("branch1@{2020-12-01T15:10:30Z}"),
+			ExpectedCommitID: commitLog[9],
+		},
+		{
+			Name:             "branch_as_of_offset",
+			Ref:              graveler.Ref("branch1@{2020-12-01T17:10:00+02:00}"),
+			ExpectedCommitID: commitLog[9],
+		},
+		{
+			Name:             "branch_as_of_after_head",
+			Ref:              graveler.Ref("branch1@{2021-01-01T00:00:00Z}"),
+			ExpectedCommitID: branch1CommitID,
+		},
+		{
+			Name:             "branch_as_of_with_modifier",
+			Ref:              graveler.Ref("branch1@{2020-12-01T15:10:00Z}~1"),
+			ExpectedCommitID: commitLog[10],
+		},
+		{
+			Name:             "tag_as_of",
+			Ref:              graveler.Ref("v1.0@{2020-12-01T15:05:00Z}"),
+			ExpectedCommitID: commitLog[14],
+		},
+		{
+			Name:        "branch_as_of_before_history",
+			Ref:         graveler.Ref("branch1@{2020-12-01T14:00:00Z}"),
+			ExpectedErr: graveler.ErrNotFound,
+		},
 		{
 			Name:        "commit_prefix_with_modifier_too_big",
 			Ref:         graveler.Ref(commitCommitID + "~200"),
@@ -430,3 +466,65 @@ func resolveRef(ctx context.Context, store ref.Store, addressProvider ident.Addr
 	}
 	return ref.ResolveRawRef(ctx, store, addressProvider, repository, rawRef)
 }
+
+func TestResolveRawRef_Reflog(t *testing.T) {
+	r, _ := testRefManager(t)
+	ctx := context.Background()
+	repository, err := r.CreateRepository(ctx, "repo1", graveler.Repository{
+		StorageNamespace: "s3://",
+		CreationDate:     time.Now(),
+		DefaultBranchID:  "main",
+	})
+	testutil.Must(t, err)
+
+	// move branch1 through three commits, each a child of the previous one
+	commitIDs := make([]graveler.CommitID, 3)
+	parents := graveler.CommitParents{}
+	for i := range commitIDs {
+		commitIDs[i], err = r.AddCommit(ctx, repository, graveler.Commit{
+			Committer:    "user1",
+			Message:      fmt.Sprintf("message%d", i),
+			MetaRangeID:  "deadbeef123",
+			CreationDate: time.Now(),
+			Parents:      parents,
+		})
+		testutil.Must(t, err)
+		parents = graveler.CommitParents{commitIDs[i]}
+		testutil.Must(t, r.SetBranch(ctx, repository, "branch1", graveler.Branch{
+			CommitID:     commitIDs[i],
+			StagingToken: "token1",
+		}, "test"))
+	}
+
+	table := []struct {
+		Name             string
+		Ref              graveler.Ref
+		ExpectedCommitID graveler.CommitID
+		ExpectedErr      error
+	}{
+		{Name: "current", Ref: "branch1@{0}", ExpectedCommitID: commitIDs[2]},
+		{Name: "previous", Ref: "branch1@{1}", ExpectedCommitID: commitIDs[1]},
+		{Name: "oldest", Ref: "branch1@{2}", ExpectedCommitID: commitIDs[0]},
+		{Name: "too_old", Ref: "branch1@{3}", ExpectedErr: graveler.ErrNotFound},
+		{Name: "with_modifier", Ref: "branch1@{1}~1", ExpectedCommitID: commitIDs[0]},
+		{Name: "tag", Ref: graveler.Ref(commitIDs[0] + "@{1}"), ExpectedErr: graveler.ErrInvalidRef},
+		{Name: "not_first_modifier", Ref: "branch1~1@{1}", ExpectedErr: graveler.ErrInvalidRef},
+	}
+	for _, cas := range table {
+		t.Run(cas.Name, func(t *testing.T) {
+			rawRef, err := r.ParseRef(cas.Ref)
+			testutil.Must(t, err)
+			resolvedRef, err := r.ResolveRawRef(ctx, repository, rawRef)
+			if cas.ExpectedErr != nil {
+				if !errors.Is(err, cas.ExpectedErr) {
+					t.Fatalf("expected error %v while resolving '%s', got: %v", cas.ExpectedErr, cas.Ref, err)
+				}
+				return
+			}
+			testutil.Must(t, err)
+			if resolvedRef.CommitID != cas.ExpectedCommitID {
+				t.Fatalf("got unexpected commit ID: '%s', expected: '%s'", resolvedRef.CommitID, cas.ExpectedCommitID)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package ref
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+func getTransaction(ctx context.Context, store kv.Store, transactionID graveler.TransactionID) (*graveler.Transaction, kv.Predicate, error) {
+	data := graveler.TransactionData{}
+	pred, err := kv.GetMsg(ctx, store, graveler.RepositoriesPartition(), []byte(graveler.TransactionPath(transactionID)), &data)
+	if errors.Is(err, kv.ErrNotFound) {
+		err = graveler.ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return graveler.TransactionFromProto(&data), pred, nil
+}
+
+// endedTransaction returns the transaction, aborting it first if it is pending past its deadline.
+func endedTransaction(ctx context.Context, store kv.Store, transactionID graveler.TransactionID) (*graveler.Transaction, error) {
+	for {
+		tx, pred, err := getTransaction(ctx, store, transactionID)
+		if err != nil {
+			return nil, err
+		}
+		if tx.Status != graveler.TransactionStatus_PENDING || time.Now().Before(tx.Deadline) {
+			return tx, nil
+		}
+		tx.Status = graveler.TransactionStatus_ABORTED
+		err = kv.SetMsgIf(ctx, store, graveler.RepositoriesPartition(), []byte(graveler.TransactionPath(transactionID)), graveler.ProtoFromTransaction(tx), pred)
+		if err == nil {
+			logging.FromContext(ctx).WithField("transaction_id", transactionID).Warn("Aborted expired transaction")
+			return tx, nil
+		}
+		if !errors.Is(err, kv.ErrPredicateFailed) {
+			return nil, err
+		}
+		// transaction ended concurrently, read it again
+	}
+}
+
+// resolveBranchTransaction returns branch as it is once its transaction ends.  If the transaction was committed the
+// branch points to its commit, and no longer holds the sealed tokens that the commit includes.  The returned branch is
+// a copy when it differs from branch.
+func resolveBranchTransaction(ctx context.Context, store kv.Store, repositoryID graveler.RepositoryID, branchID graveler.BranchID, branch *graveler.Branch) (*graveler.Branch, error) {
+	if branch.Transaction == "" {
+		return branch, nil
+	}
+	tx, err := endedTransaction(ctx, store, branch.Transaction)
+	if errors.Is(err, graveler.ErrTransactionNotFound) {
+		// transactions are deleted only after all of their branches were released
+		logging.FromContext(ctx).
+			WithFields(logging.Fields{"transaction_id": branch.Transaction, "repository": repositoryID, "branch": branchID}).
+			Warn("Branch held by a missing transaction")
+		resolved := *branch
+		resolved.Transaction = ""
+		return &resolved, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tx.Status == graveler.TransactionStatus_PENDING {
+		return branch, nil
+	}
+	resolved := *branch
+	resolved.Transaction = ""
+	if tx.Status != graveler.TransactionStatus_COMMITTED {
+		return &resolved, nil
+	}
+	for _, b := range tx.Branches {
+		if b.RepositoryID != repositoryID || b.BranchID != branchID {
+			continue
+		}
+		committed := make(map[graveler.StagingToken]struct{}, len(b.SealedTokens))
+		for _, st := range b.SealedTokens {
+			committed[st] = struct{}{}
+		}
+		resolved.CommitID = b.CommitID
+		resolved.SealedTokens = make([]graveler.StagingToken, 0, len(branch.SealedTokens))
+		for _, st := range branch.SealedTokens {
+			if _, ok := committed[st]; !ok {
+				resolved.SealedTokens = append(resolved.SealedTokens, st)
+			}
+		}
+	}
+	return &resolved, nil
+}
+
+// keepsTransactionBranch returns true if newBranch may replace a branch with commitID and sealedTokens that is held by
+// a pending transaction: the head may not move, and the sealed tokens of the transaction may not be removed.
+func keepsTransactionBranch(commitID graveler.CommitID, sealedTokens []graveler.StagingToken, newBranch *graveler.Branch) bool {
+	if newBranch.CommitID != commitID || len(newBranch.SealedTokens) < len(sealedTokens) {
+		return false
+	}
+	tail := newBranch.SealedTokens[len(newBranch.SealedTokens)-len(sealedTokens):]
+	for i := range sealedTokens {
+		if tail[i] != sealedTokens[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Manager) CreateTransaction(ctx context.Context, transaction graveler.Transaction) error {
+	err := kv.SetMsgIf(ctx, m.kvStore, graveler.RepositoriesPartition(), []byte(graveler.TransactionPath(transaction.ID)), graveler.ProtoFromTransaction(&transaction), nil)
+	if errors.Is(err, kv.ErrPredicateFailed) {
+		err = graveler.ErrNotUnique
+	}
+	return err
+}
+
+func (m *Manager) TransactionUpdate(ctx context.Context, transactionID graveler.TransactionID, f graveler.TransactionUpdateFunc) error {
+	tx, pred, err := getTransaction(ctx, m.kvStore, transactionID)
+	if err != nil {
+		return err
+	}
+	newTx, err := f(tx)
+	// return on error or nothing to update
+	if err != nil || newTx == nil {
+		return err
+	}
+	return kv.SetMsgIf(ctx, m.kvStore, graveler.RepositoriesPartition(), []byte(graveler.TransactionPath(transactionID)), graveler.ProtoFromTransaction(newTx), pred)
+}
+
+func (m *Manager) DeleteTransaction(ctx context.Context, transactionID graveler.TransactionID) error {
+	return m.kvStore.Delete(ctx, []byte(graveler.RepositoriesPartition()), []byte(graveler.TransactionPath(transactionID)))
+}
@@ -0,0 +1,316 @@
+package ref_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/batch"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/graveler/ref"
+	"github.com/treeverse/lakefs/pkg/ident"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/kv/kvtest"
+	"github.com/treeverse/lakefs/pkg/testutil"
+	"go.uber.org/ratelimit"
+)
+
+func TestManager_Transaction(t *testing.T) {
+	ctx := context.Background()
+	r, _ := testRefManager(t)
+	const transactionID = "tx1"
+
+	testutil.MustDo(t, "create transaction", r.CreateTransaction(ctx, graveler.Transaction{
+		ID:       transactionID,
+		Status:   graveler.TransactionStatus_PENDING,
+		Deadline: time.Now().Add(time.Minute),
+	}))
+	err := r.CreateTransaction(ctx, graveler.Transaction{ID: transactionID})
+	require.ErrorIs(t, err, graveler.ErrNotUnique)
+
+	err = r.TransactionUpdate(ctx, transactionID, func(tx *graveler.Transaction) (*graveler.Transaction, error) {
+		require.Equal(t, graveler.TransactionStatus_PENDING, tx.Status)
+		tx.Status = graveler.TransactionStatus_ABORTED
+		return tx, nil
+	})
+	require.NoError(t, err)
+	err = r.TransactionUpdate(ctx, transactionID, func(tx *graveler.Transaction) (*graveler.Transaction, error) {
+		require.Equal(t, graveler.TransactionStatus_ABORTED, tx.Status)
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	testutil.MustDo(t, "delete transaction", r.DeleteTransaction(ctx, transactionID))
+	err = r.TransactionUpdate(ctx, transactionID, func(tx *graveler.Transaction) (*graveler.Transaction, error) {
+		return tx, nil
+	})
+	require.ErrorIs(t, err, graveler.ErrTransactionNotFound)
+}
+
+func TestManager_TransactionBranch(t *testing.T) {
+	ctx := context.Background()
+	r, _ := testRefManager(t)
+	repository, err := r.CreateRepository(ctx, "repo1", graveler.Repository{
+		StorageNamespace: "s3://",
+		CreationDate:     time.Now(),
+		DefaultBranchID:  "main",
+	})
+	testutil.Must(t, err)
+	heldBranch := graveler.Branch{
+		CommitID:     "c1",
+		StagingToken: "st3",
+		SealedTokens: []graveler.StagingToken{"st2", "st1"},
+	}
+
+	tests := []struct {
+		name           string
+		status         graveler.TransactionStatus
+		deadline       time.Duration
+		missing        bool
+		expectedBranch graveler.Branch
+		expectedStatus graveler.TransactionStatus
+	}{
+		{
+			name:           "pending",
+			status:         graveler.TransactionStatus_PENDING,
+			deadline:       time.Minute,
+			expectedBranch: heldBranch,
+			expectedStatus: graveler.TransactionStatus_PENDING,
+		},
+		{
+			name:     "committed",
+			status:   graveler.TransactionStatus_COMMITTED,
+			deadline: time.Minute,
+			expectedBranch: graveler.Branch{
+				CommitID:     "c2",
+				StagingToken: "st3",
+				SealedTokens: []graveler.StagingToken{"st2"},
+			},
+			expectedStatus: graveler.TransactionStatus_COMMITTED,
+		},
+		{
+			name:     "aborted",
+			status:   graveler.TransactionStatus_ABORTED,
+			deadline: time.Minute,
+			expectedBranch: graveler.Branch{
+				CommitID:     "c1",
+				StagingToken: "st3",
+				SealedTokens: []graveler.StagingToken{"st2", "st1"},
+			},
+			expectedStatus: graveler.TransactionStatus_ABORTED,
+		},
+		{
+			name:     "expired",
+			status:   graveler.TransactionStatus_PENDING,
+			deadline: -time.Minute,
+			expectedBranch: graveler.Branch{
+				CommitID:     "c1",
+				StagingToken: "st3",
+				SealedTokens: []graveler.StagingToken{"st2", "st1"},
+			},
+			expectedStatus: graveler.TransactionStatus_ABORTED,
+		},
+		{
+			name:    "missing",
+			missing: true,
+			expectedBranch: graveler.Branch{
+				CommitID:     "c1",
+				StagingToken: "st3",
+				SealedTokens: []graveler.StagingToken{"st2", "st1"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactionID := graveler.TransactionID("tx-" + tt.name)
+			branchID := graveler.BranchID("branch-" + tt.name)
+			if !tt.missing {
+				testutil.MustDo(t, "create transaction", r.CreateTransaction(ctx, graveler.Transaction{
+					ID:       transactionID,
+					Status:   tt.status,
+					Deadline: time.Now().Add(tt.deadline),
+					Branches: []graveler.TransactionBranch{{
+						RepositoryID: repository.RepositoryID,
+						BranchID:     branchID,
+						CommitID:     "c2",
+						SealedTokens: []graveler.StagingToken{"st1"},
+					}},
+				}))
+			}
+			branch := heldBranch
+			branch.Transaction = transactionID
+			testutil.MustDo(t, "set branch", r.SetBranch(ctx, repository, branchID, branch, "test"))
+			pending := !tt.missing && tt.expectedStatus == graveler.TransactionStatus_PENDING
+			if pending {
+				tt.expectedBranch.Transaction = transactionID
+			}
+
+			b, err := r.GetBranch(ctx, repository, branchID)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedBranch, *b)
+
+			if !tt.missing {
+				err = r.TransactionUpdate(ctx, transactionID, func(tx *graveler.Transaction) (*graveler.Transaction, error) {
+					require.Equal(t, tt.expectedStatus, tx.Status)
+					return nil, nil
+				})
+				require.NoError(t, err)
+			}
+
+			// a held branch may gain sealed tokens, but its head and sealed tokens may not change
+			err = r.BranchUpdate(ctx, repository, branchID, "test", func(b *graveler.Branch) (*graveler.Branch, error) {
+				b.SealedTokens = append([]graveler.StagingToken{b.StagingToken}, b.SealedTokens...)
+				b.StagingToken = "st4"
+				return b, nil
+			})
+			require.NoError(t, err)
+			// setting a held branch is rejected as well
+			err = r.SetBranch(ctx, repository, branchID, graveler.Branch{CommitID: "c4", StagingToken: "st5"}, "test")
+			if pending {
+				require.ErrorIs(t, err, graveler.ErrBranchLocked)
+			} else {
+				require.NoError(t, err)
+			}
+			err = r.BranchUpdate(ctx, repository, branchID, "test", func(b *graveler.Branch) (*graveler.Branch, error) {
+				b.CommitID = "c3"
+				b.SealedTokens = nil
+				return b, nil
+			})
+			if pending {
+				require.ErrorIs(t, err, graveler.ErrBranchLocked)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+// holdingStore runs hold once, right before the first conditional write of key
+type holdingStore struct {
+	kv.Store
+	key  []byte
+	hold func()
+}
+
+func (s *holdingStore) SetIf(ctx context.Context, partitionKey, key, value []byte, valuePredicate kv.Predicate) error {
+	if hold := s.hold; hold != nil && bytes.Equal(key, s.key) {
+		s.hold = nil
+		hold()
+	}
+	return s.Store.SetIf(ctx, partitionKey, key, value, valuePredicate)
+}
+
+func TestManager_SetBranchHeldConcurrently(t *testing.T) {
+	ctx := context.Background()
+	const (
+		branchID      = "branch1"
+		transactionID = "tx1"
+	)
+	store := &holdingStore{
+		Store: kvtest.GetStore(ctx, t),
+		key:   []byte(graveler.BranchPath(branchID)),
+	}
+	r := ref.NewRefManager(ref.ManagerConfig{
+		Executor:              batch.NopExecutor(),
+		KVStore:               store,
+		KVStoreLimited:        kv.NewStoreLimiter(store, ratelimit.NewUnlimited()),
+		AddressProvider:       ident.NewHexAddressProvider(),
+		RepositoryCacheConfig: testRepoCacheConfig,
+		CommitCacheConfig:     testCommitCacheConfig,
+	})
+	repository, err := r.CreateRepository(ctx, "repo1", graveler.Repository{
+		StorageNamespace: "s3://",
+		CreationDate:     time.Now(),
+		DefaultBranchID:  "main",
+	})
+	testutil.Must(t, err)
+	testutil.MustDo(t, "set branch", r.SetBranch(ctx, repository, branchID, graveler.Branch{CommitID: "c1", StagingToken: "st1"}, "test"))
+
+	// a transaction holds the branch after SetBranch read it and before it writes it
+	store.hold = func() {
+		testutil.MustDo(t, "create transaction", r.CreateTransaction(ctx, graveler.Transaction{
+			ID:       transactionID,
+			Status:   graveler.TransactionStatus_PENDING,
+			Deadline: time.Now().Add(time.Minute),
+		}))
+		testutil.MustDo(t, "hold branch", r.BranchUpdate(ctx, repository, branchID, "test", func(b *graveler.Branch) (*graveler.Branch, error) {
+			b.Transaction = transactionID
+			return b, nil
+		}))
+	}
+	err = r.SetBranch(ctx, repository, branchID, graveler.Branch{CommitID: "c2", StagingToken: "st2"}, "test")
+	require.ErrorIs(t, err, graveler.ErrBranchLocked)
+
+	b, err := r.GetBranch(ctx, repository, branchID)
+	require.NoError(t, err)
+	require.Equal(t, graveler.Branch{CommitID: "c1", StagingToken: "st1", Transaction: transactionID}, *b)
+
+	// a branch created between the read and the write is not replaced either
+	store.key = []byte(graveler.BranchPath("branch2"))
+	store.hold = func() {
+		testutil.MustDo(t, "create branch", r.CreateBranch(ctx, repository, "branch2", graveler.Branch{CommitID: "c1", StagingToken: "st3"}))
+	}
+	err = r.SetBranch(ctx, repository, "branch2", graveler.Branch{CommitID: "c2", StagingToken: "st4"}, "test")
+	require.ErrorIs(t, err, kv.ErrPredicateFailed)
+}
+
+func TestManager_DeleteBranchHeld(t *testing.T) {
+	ctx := context.Background()
+	const (
+		branchID      = "branch1"
+		transactionID = "tx1"
+	)
+	store := &holdingStore{
+		Store: kvtest.GetStore(ctx, t),
+		key:   []byte(graveler.BranchPath(branchID)),
+	}
+	r := ref.NewRefManager(ref.ManagerConfig{
+		Executor:              batch.NopExecutor(),
+		KVStore:               store,
+		KVStoreLimited:        kv.NewStoreLimiter(store, ratelimit.NewUnlimited()),
+		AddressProvider:       ident.NewHexAddressProvider(),
+		RepositoryCacheConfig: testRepoCacheConfig,
+		CommitCacheConfig:     testCommitCacheConfig,
+	})
+	repository, err := r.CreateRepository(ctx, "repo1", graveler.Repository{
+		StorageNamespace: "s3://",
+		CreationDate:     time.Now(),
+		DefaultBranchID:  "main",
+	})
+	testutil.Must(t, err)
+	testutil.MustDo(t, "set branch", r.SetBranch(ctx, repository, branchID, graveler.Branch{CommitID: "c1", StagingToken: "st1"}, "test"))
+	testutil.MustDo(t, "create transaction", r.CreateTransaction(ctx, graveler.Transaction{
+		ID:       transactionID,
+		Status:   graveler.TransactionStatus_PENDING,
+		Deadline: time.Now().Add(time.Minute),
+	}))
+	hold := func() {
+		testutil.MustDo(t, "hold branch", r.BranchUpdate(ctx, repository, branchID, "test", func(b *graveler.Branch) (*graveler.Branch, error) {
+			b.Transaction = transactionID
+			return b, nil
+		}))
+	}
+
+	// a transaction holds the branch after DeleteBranch read it and before it holds it itself
+	store.hold = hold
+	err = r.DeleteBranch(ctx, repository, branchID)
+	require.ErrorIs(t, err, graveler.ErrBranchLocked)
+	b, err := r.GetBranch(ctx, repository, branchID)
+	require.NoError(t, err)
+	require.Equal(t, graveler.Branch{CommitID: "c1", StagingToken: "st1", Transaction: transactionID}, *b)
+
+	// a held branch is not deleted
+	err = r.DeleteBranch(ctx, repository, branchID)
+	require.ErrorIs(t, err, graveler.ErrBranchLocked)
+
+	// once the transaction ends, the branch is deleted
+	testutil.MustDo(t, "abort transaction", r.TransactionUpdate(ctx, transactionID, func(tx *graveler.Transaction) (*graveler.Transaction, error) {
+		tx.Status = graveler.TransactionStatus_ABORTED
+		return tx, nil
+	}))
+	require.NoError(t, r.DeleteBranch(ctx, repository, branchID))
+	_, err = r.GetBranch(ctx, repository, branchID)
+	require.ErrorIs(t, err, graveler.ErrBranchNotFound)
+}
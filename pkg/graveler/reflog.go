@@ -0,0 +1,18 @@
+package graveler
+
+import (
+	"context"
+)
+
+type reflogUserContextKey struct{}
+
+// WithReflogUser returns a context with which branch head movements are recorded in the reflog as made by user
+func WithReflogUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, reflogUserContextKey{}, user)
+}
+
+// ReflogUserFromContext returns the user to record in the reflog for branch head movements made with ctx
+func ReflogUserFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(reflogUserContextKey{}).(string)
+	return user
+}
@@ -187,7 +187,7 @@ func (m *GarbageCollectionManager) SaveGarbageCollectionCommits(ctx context.Cont
 		refManager: m.refManager,
 		repository: repository,
 	}
-	branchIterator, err := m.refManager.GCBranchIterator(ctx, repository)
+	branchIterator, err := m.reflogBranchIterator(ctx, repository, rules)
 	if err != nil {
 		return "", err
 	}
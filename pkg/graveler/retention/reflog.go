@@ -0,0 +1,123 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/graveler"
+)
+
+// reflogBranchIterator returns the branches of repository ordered by commit ID, together with the previous heads of
+// each branch that left it within the retention period of the branch.  GC treats the previous heads as heads of their
+// branch, so that their commits stay live for as long as their reflog entries are retained.  Reflog entries older
+// than the retention period no longer keep anything live and are deleted.
+func (m *GarbageCollectionManager) reflogBranchIterator(ctx context.Context, repository *graveler.RepositoryRecord, rules *graveler.GarbageCollectionRules) (graveler.BranchIterator, error) {
+	branchIterator, err := m.refManager.GCBranchIterator(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	defer branchIterator.Close()
+	var branches []*graveler.BranchRecord
+	for branchIterator.Next() {
+		branches = append(branches, branchIterator.Value())
+	}
+	if err := branchIterator.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	records := append([]*graveler.BranchRecord{}, branches...)
+	for _, branch := range branches {
+		retentionDays := rules.DefaultRetentionDays
+		if branchRetentionDays, ok := rules.BranchRetentionDays[string(branch.BranchID)]; ok {
+			retentionDays = branchRetentionDays
+		}
+		threshold := now.AddDate(0, 0, -int(retentionDays))
+		previousHeads, err := m.reflogHeadsAfter(ctx, repository, branch.BranchID, threshold)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.refManager.PruneBranchReflog(ctx, repository, branch.BranchID, threshold); err != nil {
+			return nil, fmt.Errorf("prune reflog of branch %s: %w", branch.BranchID, err)
+		}
+		for _, commitID := range previousHeads {
+			records = append(records, &graveler.BranchRecord{
+				BranchID: branch.BranchID,
+				Branch:   &graveler.Branch{CommitID: commitID},
+			})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].CommitID < records[j].CommitID
+	})
+	return newBranchRecordIterator(records), nil
+}
+
+// reflogHeadsAfter returns the previous heads of branchID whose reflog entries were created after threshold
+func (m *GarbageCollectionManager) reflogHeadsAfter(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, threshold time.Time) ([]graveler.CommitID, error) {
+	it, err := m.refManager.ListBranchReflog(ctx, repository, branchID)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	seen := make(map[graveler.CommitID]struct{})
+	var res []graveler.CommitID
+	for it.Next() {
+		entry := it.Value()
+		if !entry.CreationDate.After(threshold) {
+			// entries are ordered newest first
+			break
+		}
+		if _, ok := seen[entry.OldCommitID]; ok {
+			continue
+		}
+		seen[entry.OldCommitID] = struct{}{}
+		res = append(res, entry.OldCommitID)
+	}
+	return res, it.Err()
+}
+
+// branchRecordIterator iterates over branch records in memory, in their order
+type branchRecordIterator struct {
+	records []*graveler.BranchRecord
+	next    int
+	from    graveler.BranchID
+	value   *graveler.BranchRecord
+}
+
+func newBranchRecordIterator(records []*graveler.BranchRecord) *branchRecordIterator {
+	return &branchRecordIterator{records: records}
+}
+
+func (i *branchRecordIterator) Next() bool {
+	for i.next < len(i.records) {
+		record := i.records[i.next]
+		i.next++
+		if record.BranchID >= i.from {
+			i.value = record
+			return true
+		}
+	}
+	i.value = nil
+	return false
+}
+
+// SeekGE restarts the iteration over the records of the branches whose ID is greater than or equal to id.  The
+// records are not ordered by branch ID, so they are still returned in their order.
+func (i *branchRecordIterator) SeekGE(id graveler.BranchID) {
+	i.next = 0
+	i.from = id
+	i.value = nil
+}
+
+func (i *branchRecordIterator) Value() *graveler.BranchRecord {
+	return i.value
+}
+
+func (i *branchRecordIterator) Err() error {
+	return nil
+}
+
+func (i *branchRecordIterator) Close() {}
@@ -0,0 +1,95 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	"github.com/treeverse/lakefs/pkg/block/mem"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/graveler/testutil"
+)
+
+func TestReflogBranchIterator(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	refManager := &testutil.RefsFake{
+		ListBranchesRes: testutil.NewFakeBranchIterator([]*graveler.BranchRecord{
+			{BranchID: "main", Branch: &graveler.Branch{CommitID: "c"}},
+			{BranchID: "dev", Branch: &graveler.Branch{CommitID: "f"}},
+		}),
+		Reflog: map[graveler.BranchID][]*graveler.ReflogEntry{
+			"main": {
+				{OldCommitID: "e", NewCommitID: "c", CreationDate: now.AddDate(0, 0, -1)},
+				{OldCommitID: "a", NewCommitID: "e", CreationDate: now.AddDate(0, 0, -2)},
+				{OldCommitID: "e", NewCommitID: "a", CreationDate: now.AddDate(0, 0, -3)},
+				// older than the retention of main
+				{OldCommitID: "b", NewCommitID: "e", CreationDate: now.AddDate(0, 0, -10)},
+			},
+			"dev": {
+				// within the retention of dev
+				{OldCommitID: "d", NewCommitID: "f", CreationDate: now.AddDate(0, 0, -10)},
+			},
+		},
+	}
+	rules := &graveler.GarbageCollectionRules{
+		DefaultRetentionDays: 5,
+		BranchRetentionDays:  map[string]int32{"dev": 20},
+	}
+	gc := NewGarbageCollectionManager(mem.New(ctx), refManager, "prefix")
+	it, err := gc.reflogBranchIterator(ctx, &graveler.RepositoryRecord{RepositoryID: "repo"}, rules)
+	if err != nil {
+		t.Fatalf("failed to create reflog branch iterator: %v", err)
+	}
+	defer it.Close()
+	var got []string
+	for it.Next() {
+		got = append(got, string(it.Value().BranchID)+":"+string(it.Value().CommitID))
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"main:a", "main:c", "dev:d", "main:e", "dev:f"}
+	if diff := deep.Equal(got, expected); diff != nil {
+		t.Fatalf("unexpected branch records: %v", diff)
+	}
+	// the entries older than the retention of their branch are pruned
+	if l := len(refManager.Reflog["main"]); l != 3 {
+		t.Fatalf("main reflog has %d entries after GC, expected 3", l)
+	}
+	if l := len(refManager.Reflog["dev"]); l != 1 {
+		t.Fatalf("dev reflog has %d entries after GC, expected 1", l)
+	}
+}
+
+func TestBranchRecordIterator_SeekGE(t *testing.T) {
+	it := newBranchRecordIterator([]*graveler.BranchRecord{
+		{BranchID: "main", Branch: &graveler.Branch{CommitID: "a"}},
+		{BranchID: "dev", Branch: &graveler.Branch{CommitID: "b"}},
+		{BranchID: "main", Branch: &graveler.Branch{CommitID: "c"}},
+		{BranchID: "feature", Branch: &graveler.Branch{CommitID: "d"}},
+	})
+	collect := func() []string {
+		var got []string
+		for it.Next() {
+			got = append(got, string(it.Value().BranchID)+":"+string(it.Value().CommitID))
+		}
+		return got
+	}
+	if diff := deep.Equal(collect(), []string{"main:a", "dev:b", "main:c", "feature:d"}); diff != nil {
+		t.Fatalf("unexpected branch records: %v", diff)
+	}
+	it.SeekGE("e")
+	if diff := deep.Equal(collect(), []string{"main:a", "main:c", "feature:d"}); diff != nil {
+		t.Fatalf("unexpected branch records after seek to e: %v", diff)
+	}
+	it.SeekGE("g")
+	if diff := deep.Equal(collect(), []string{"main:a", "main:c"}); diff != nil {
+		t.Fatalf("unexpected branch records after seek to g: %v", diff)
+	}
+	it.SeekGE("z")
+	if it.Next() {
+		t.Fatalf("unexpected branch record after seek past the end: %v", it.Value())
+	}
+}
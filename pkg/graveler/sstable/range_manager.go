@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto"
 	"fmt"
+	"io"
 
 	"github.com/cockroachdb/pebble"
 	"github.com/cockroachdb/pebble/sstable"
@@ -172,6 +173,31 @@ func (m *RangeManager) GetURI(ctx context.Context, ns committed.Namespace, id co
 	return m.fs.GetRemoteURI(ctx, string(ns), string(id))
 }
 
+func (m *RangeManager) Copy(ctx context.Context, src, dst committed.Namespace, id committed.ID) error {
+	exists, err := m.fs.Exists(ctx, string(dst), string(id))
+	if err != nil {
+		return fmt.Errorf("check range %s in %s: %w", id, dst, err)
+	}
+	if exists {
+		return nil
+	}
+	in, err := m.fs.Open(ctx, string(src), string(id))
+	if err != nil {
+		return fmt.Errorf("open range %s in %s: %w", id, src, err)
+	}
+	defer m.execAndLog(ctx, in.Close, "close range")
+
+	out, err := m.fs.Create(ctx, string(dst))
+	if err != nil {
+		return fmt.Errorf("create range %s in %s: %w", id, dst, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		m.execAndLog(ctx, func() error { return out.Abort(ctx) }, "abort range copy")
+		return fmt.Errorf("copy range %s: %w", id, err)
+	}
+	return out.Store(ctx, string(id))
+}
+
 func (m *RangeManager) execAndLog(ctx context.Context, f func() error, msg string) {
 	if err := f(); err != nil {
 		logging.FromContext(ctx).WithError(err).Error(msg)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto"
 	"errors"
+	"io"
 	"sort"
 	"testing"
 
@@ -180,3 +181,43 @@ func TestGetWriterRangeID(t *testing.T) {
 		require.Equal(t, expectedID, result.RangeID, "Range ID should be kept the same based on the content")
 	}
 }
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+	const (
+		src     = "src-ns"
+		dst     = "dst-ns"
+		rangeID = "some-id"
+	)
+	content := []byte("range-content")
+
+	t.Run("copy", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := fsMock.NewMockFS(ctrl)
+		sut := sstable.NewPebbleSSTableRangeManagerWithNewReader(nil, &NoCache{}, mockFS, crypto.SHA256)
+
+		in := fsMock.NewMockFile(ctrl)
+		out := fsMock.NewMockStoredFile(ctrl)
+		mockFS.EXPECT().Exists(ctx, dst, rangeID).Return(false, nil)
+		mockFS.EXPECT().Open(ctx, src, rangeID).Return(in, nil)
+		mockFS.EXPECT().Create(ctx, dst).Return(out, nil)
+		in.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			return copy(p, content), io.EOF
+		})
+		in.EXPECT().Close().Return(nil)
+		out.EXPECT().Write(content).Return(len(content), nil)
+		out.EXPECT().Store(ctx, rangeID).Return(nil)
+
+		require.NoError(t, sut.Copy(ctx, src, dst, rangeID))
+	})
+
+	t.Run("exists", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockFS := fsMock.NewMockFS(ctrl)
+		sut := sstable.NewPebbleSSTableRangeManagerWithNewReader(nil, &NoCache{}, mockFS, crypto.SHA256)
+
+		mockFS.EXPECT().Exists(ctx, dst, rangeID).Return(true, nil)
+
+		require.NoError(t, sut.Copy(ctx, src, dst, rangeID))
+	})
+}
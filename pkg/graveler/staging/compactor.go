@@ -0,0 +1,222 @@
+package staging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/logging"
+)
+
+var (
+	sealedTokensGauge = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "graveler_staging_sealed_tokens",
+			Help: "Number of sealed staging tokens on a branch, as last seen by the staging compactor.",
+		},
+		[]string{"repository", "branch"},
+	)
+	compactionsCounter = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "graveler_staging_compactions",
+			Help: "Number of staging compactions performed by result.",
+		},
+		[]string{"result"},
+	)
+)
+
+// ErrCompactionConflict is returned when the sealed tokens of a branch changed while being compacted.
+var ErrCompactionConflict = errors.New("sealed tokens changed during compaction")
+
+const (
+	compactionResultSuccess  = "success"
+	compactionResultConflict = "conflict"
+	compactionResultError    = "error"
+)
+
+type CompactorParams struct {
+	// Interval between compaction cycles over all branches.
+	Interval time.Duration
+	// SealedTokensThreshold is the minimal number of sealed tokens on a branch that triggers its compaction.
+	SealedTokensThreshold int
+}
+
+// Compactor merges the sealed staging tokens of a branch into a single sealed staging token.  Sealed tokens
+// accumulate on every commit attempt and reset, and every read from the branch staging area merges all of them.
+type Compactor struct {
+	refManager graveler.RefManager
+	manager    *Manager
+	params     CompactorParams
+}
+
+func NewCompactor(refManager graveler.RefManager, manager *Manager, params CompactorParams) *Compactor {
+	return &Compactor{
+		refManager: refManager,
+		manager:    manager,
+		params:     params,
+	}
+}
+
+func (c *Compactor) log(ctx context.Context) logging.Logger {
+	return logging.FromContext(ctx).WithField("service_name", "staging_compactor")
+}
+
+// Run compacts all branches every Interval until ctx is done.
+func (c *Compactor) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.params.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.CompactAll(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				c.log(ctx).WithError(err).Error("Staging compaction failed")
+			}
+		}
+	}
+}
+
+// CompactAll compacts every branch of every repository that passed the sealed tokens threshold.
+func (c *Compactor) CompactAll(ctx context.Context) error {
+	repos, err := c.refManager.ListRepositories(ctx)
+	if err != nil {
+		return fmt.Errorf("list repositories: %w", err)
+	}
+	defer repos.Close()
+	for repos.Next() {
+		repository := repos.Value()
+		if repository.State != graveler.RepositoryState_ACTIVE {
+			continue
+		}
+		if err := c.compactRepository(ctx, repository); err != nil {
+			return fmt.Errorf("repository %s: %w", repository.RepositoryID, err)
+		}
+	}
+	return repos.Err()
+}
+
+func (c *Compactor) compactRepository(ctx context.Context, repository *graveler.RepositoryRecord) error {
+	branches, err := c.refManager.ListBranches(ctx, repository)
+	if err != nil {
+		return fmt.Errorf("list branches: %w", err)
+	}
+	defer branches.Close()
+	for branches.Next() {
+		b := branches.Value()
+		sealedTokensGauge.WithLabelValues(repository.RepositoryID.String(), b.BranchID.String()).Set(float64(len(b.SealedTokens)))
+		if len(b.SealedTokens) < c.params.SealedTokensThreshold {
+			continue
+		}
+		_, err := c.CompactBranch(ctx, repository, b.BranchID)
+		if err != nil && !errors.Is(err, ErrCompactionConflict) && !errors.Is(err, graveler.ErrNotFound) {
+			return fmt.Errorf("branch %s: %w", b.BranchID, err)
+		}
+	}
+	return branches.Err()
+}
+
+// CompactBranch merges the sealed tokens of branchID into a single new sealed token, and returns the new token.  It
+// returns an empty token if the branch has fewer sealed tokens than the threshold, and ErrCompactionConflict if the
+// sealed tokens changed concurrently in a way that prevents replacing them, or are held by a multi-branch commit.
+func (c *Compactor) CompactBranch(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID) (graveler.StagingToken, error) {
+	b, err := c.refManager.GetBranch(ctx, repository, branchID)
+	if err != nil {
+		return "", err
+	}
+	sealed := b.SealedTokens
+	if len(sealed) < 2 || len(sealed) < c.params.SealedTokensThreshold {
+		return "", nil
+	}
+	if b.Transaction != "" {
+		// a multi-branch commit owns the sealed tokens until it ends
+		return "", ErrCompactionConflict
+	}
+	log := c.log(ctx).WithFields(logging.Fields{
+		"repository":    repository.RepositoryID,
+		"branch":        branchID,
+		"sealed_tokens": len(sealed),
+	})
+
+	compacted := graveler.GenerateStagingToken(repository.RepositoryID, branchID)
+	if err := c.merge(ctx, compacted, sealed); err != nil {
+		compactionsCounter.WithLabelValues(compactionResultError).Inc()
+		c.drop(ctx, compacted)
+		return "", fmt.Errorf("merge sealed tokens: %w", err)
+	}
+
+	err = c.refManager.BranchUpdate(ctx, repository, branchID, "compact", func(branch *graveler.Branch) (*graveler.Branch, error) {
+		// New sealed tokens are added at the front: the compacted tokens must still be the tail.
+		n := len(branch.SealedTokens) - len(sealed)
+		if n < 0 || !equalTokens(branch.SealedTokens[n:], sealed) {
+			return nil, ErrCompactionConflict
+		}
+		branch.SealedTokens = append(branch.SealedTokens[:n:n], compacted)
+		return branch, nil
+	})
+	if errors.Is(err, kv.ErrPredicateFailed) || errors.Is(err, graveler.ErrBranchLocked) {
+		err = ErrCompactionConflict
+	}
+	if err != nil {
+		result := compactionResultError
+		if errors.Is(err, ErrCompactionConflict) {
+			result = compactionResultConflict
+		}
+		compactionsCounter.WithLabelValues(result).Inc()
+		c.drop(ctx, compacted)
+		return "", err
+	}
+	compactionsCounter.WithLabelValues(compactionResultSuccess).Inc()
+	log.WithField("staging_token", compacted).Info("Compacted sealed tokens")
+	c.drop(ctx, sealed...)
+	return compacted, nil
+}
+
+// merge writes the combined content of sealed tokens, including tombstones, to token st.  Sealed tokens are ordered
+// from newest to oldest, so the newest value of each key wins.
+func (c *Compactor) merge(ctx context.Context, st graveler.StagingToken, sealed []graveler.StagingToken) error {
+	iterators := make([]graveler.ValueIterator, 0, len(sealed))
+	for _, token := range sealed {
+		iterators = append(iterators, NewStagingIterator(ctx, c.manager.kvStoreLimited, token, 0))
+	}
+	it := graveler.NewCombinedIterator(iterators...)
+	defer it.Close()
+	partition := graveler.StagingTokenPartition(st)
+	for it.Next() {
+		record := it.Value()
+		value := record.Value
+		if value == nil {
+			// Tombstone handling
+			value = new(graveler.Value)
+		}
+		if err := kv.SetMsg(ctx, c.manager.kvStoreLimited, partition, record.Key, graveler.ProtoFromStagedEntry(record.Key, value)); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+func (c *Compactor) drop(ctx context.Context, tokens ...graveler.StagingToken) {
+	for _, st := range tokens {
+		if err := c.manager.DropAsync(ctx, st); err != nil {
+			c.log(ctx).WithError(err).WithField("staging_token", st).Error("Failed to drop staging token")
+		}
+	}
+}
+
+func equalTokens(a, b []graveler.StagingToken) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
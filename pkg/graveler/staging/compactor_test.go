@@ -0,0 +1,180 @@
+package staging_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/batch"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/graveler/ref"
+	"github.com/treeverse/lakefs/pkg/graveler/staging"
+	"github.com/treeverse/lakefs/pkg/ident"
+	"github.com/treeverse/lakefs/pkg/kv"
+	"github.com/treeverse/lakefs/pkg/kv/kvtest"
+	"go.uber.org/ratelimit"
+)
+
+type compactorTest struct {
+	ctx        context.Context
+	refManager graveler.RefManager
+	manager    *staging.Manager
+	compactor  *staging.Compactor
+	repository *graveler.RepositoryRecord
+}
+
+func newCompactorTest(t *testing.T, threshold int) *compactorTest {
+	t.Helper()
+	ctx := context.Background()
+	store := kvtest.GetStore(ctx, t)
+	storeLimited := kv.NewStoreLimiter(store, ratelimit.NewUnlimited())
+	refManager := ref.NewRefManager(ref.ManagerConfig{
+		Executor:              batch.NopExecutor(),
+		KVStore:               store,
+		KVStoreLimited:        storeLimited,
+		AddressProvider:       ident.NewHexAddressProvider(),
+		RepositoryCacheConfig: ref.CacheConfig{Size: 100, Expiry: time.Millisecond},
+		CommitCacheConfig:     ref.CacheConfig{Size: 100, Expiry: time.Millisecond},
+	})
+	repository, err := refManager.CreateRepository(ctx, "repo", graveler.Repository{
+		StorageNamespace: "mem://repo",
+		CreationDate:     time.Now(),
+		DefaultBranchID:  "main",
+	})
+	require.NoError(t, err)
+	manager := staging.NewManager(ctx, store, storeLimited, false, nil)
+	return &compactorTest{
+		ctx:        ctx,
+		refManager: refManager,
+		manager:    manager,
+		compactor:  staging.NewCompactor(refManager, manager, staging.CompactorParams{Interval: time.Minute, SealedTokensThreshold: threshold}),
+		repository: repository,
+	}
+}
+
+// setSealed stores each of sealed as a sealed token on branch main, newest first.  Each token is a map of key to
+// value identity, where an empty identity is a tombstone.
+func (c *compactorTest) setSealed(t *testing.T, sealed ...map[string]string) []graveler.StagingToken {
+	t.Helper()
+	b, err := c.refManager.GetBranch(c.ctx, c.repository, "main")
+	require.NoError(t, err)
+	tokens := make([]graveler.StagingToken, 0, len(sealed))
+	for i, entries := range sealed {
+		st := graveler.StagingToken(fmt.Sprintf("sealed-%d", i))
+		for k, identity := range entries {
+			var value *graveler.Value
+			if identity != "" {
+				value = newTestValue(identity, identity)
+			}
+			require.NoError(t, c.manager.Set(c.ctx, st, graveler.Key(k), value, false))
+		}
+		tokens = append(tokens, st)
+	}
+	b.SealedTokens = tokens
+	require.NoError(t, c.refManager.SetBranch(c.ctx, c.repository, "main", *b, "test"))
+	return tokens
+}
+
+func (c *compactorTest) readToken(t *testing.T, st graveler.StagingToken) map[string]string {
+	t.Helper()
+	it := c.manager.List(c.ctx, st, 0)
+	defer it.Close()
+	entries := make(map[string]string)
+	for it.Next() {
+		record := it.Value()
+		identity := ""
+		if record.Value != nil {
+			identity = string(record.Value.Identity)
+		}
+		entries[record.Key.String()] = identity
+	}
+	require.NoError(t, it.Err())
+	return entries
+}
+
+func TestCompactBranch(t *testing.T) {
+	c := newCompactorTest(t, 2)
+	c.setSealed(t,
+		map[string]string{"a": "a2", "b": ""},
+		map[string]string{"a": "a1", "c": ""},
+		map[string]string{"b": "b0", "c": "c0", "d": "d0"},
+	)
+
+	compacted, err := c.compactor.CompactBranch(c.ctx, c.repository, "main")
+	require.NoError(t, err)
+	require.NotEmpty(t, compacted)
+
+	b, err := c.refManager.GetBranch(c.ctx, c.repository, "main")
+	require.NoError(t, err)
+	require.Equal(t, []graveler.StagingToken{compacted}, b.SealedTokens)
+	require.Equal(t, map[string]string{"a": "a2", "b": "", "c": "", "d": "d0"}, c.readToken(t, compacted))
+}
+
+func TestCompactBranch_BelowThreshold(t *testing.T) {
+	c := newCompactorTest(t, 4)
+	sealed := c.setSealed(t, map[string]string{"a": "a1"}, map[string]string{"a": "a0"}, map[string]string{"b": "b0"})
+
+	compacted, err := c.compactor.CompactBranch(c.ctx, c.repository, "main")
+	require.NoError(t, err)
+	require.Empty(t, compacted)
+
+	b, err := c.refManager.GetBranch(c.ctx, c.repository, "main")
+	require.NoError(t, err)
+	require.Equal(t, sealed, b.SealedTokens)
+}
+
+func TestCompactAll_KeepsNewSealedTokens(t *testing.T) {
+	c := newCompactorTest(t, 2)
+	c.setSealed(t, map[string]string{"a": "a1"}, map[string]string{"a": "a0"})
+	require.NoError(t, c.compactor.CompactAll(c.ctx))
+
+	// seal another token in front of the compacted one, as a commit would
+	b, err := c.refManager.GetBranch(c.ctx, c.repository, "main")
+	require.NoError(t, err)
+	require.Len(t, b.SealedTokens, 1)
+	compacted := b.SealedTokens[0]
+	require.NoError(t, c.manager.Set(c.ctx, "newer", graveler.Key("a"), nil, false))
+	b.SealedTokens = append([]graveler.StagingToken{"newer"}, b.SealedTokens...)
+	require.NoError(t, c.refManager.SetBranch(c.ctx, c.repository, "main", *b, "test"))
+
+	recompacted, err := c.compactor.CompactBranch(c.ctx, c.repository, "main")
+	require.NoError(t, err)
+	require.NotEqual(t, compacted, recompacted)
+	require.Equal(t, map[string]string{"a": ""}, c.readToken(t, recompacted))
+}
+
+// racingRefManager runs race before every branch update.
+type racingRefManager struct {
+	graveler.RefManager
+	race func()
+}
+
+func (r *racingRefManager) BranchUpdate(ctx context.Context, repository *graveler.RepositoryRecord, branchID graveler.BranchID, operation string, f graveler.BranchUpdateFunc) error {
+	r.race()
+	return r.RefManager.BranchUpdate(ctx, repository, branchID, operation, f)
+}
+
+func TestCompactBranch_Conflict(t *testing.T) {
+	c := newCompactorTest(t, 2)
+	c.setSealed(t, map[string]string{"a": "a1"}, map[string]string{"a": "a0"})
+
+	// a commit clears the sealed tokens while they are being compacted
+	refManager := &racingRefManager{RefManager: c.refManager, race: func() {
+		b, err := c.refManager.GetBranch(c.ctx, c.repository, "main")
+		require.NoError(t, err)
+		b.SealedTokens = nil
+		require.NoError(t, c.refManager.SetBranch(c.ctx, c.repository, "main", *b, "test"))
+	}}
+	compactor := staging.NewCompactor(refManager, c.manager, staging.CompactorParams{Interval: time.Minute, SealedTokensThreshold: 2})
+	_, err := compactor.CompactBranch(c.ctx, c.repository, "main")
+	if !errors.Is(err, staging.ErrCompactionConflict) {
+		t.Fatalf("got error %v, expected %s", err, staging.ErrCompactionConflict)
+	}
+
+	b, err := c.refManager.GetBranch(c.ctx, c.repository, "main")
+	require.NoError(t, err)
+	require.Empty(t, b.SealedTokens)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/treeverse/lakefs/pkg/graveler"
 	"github.com/treeverse/lakefs/pkg/graveler/committed"
@@ -33,6 +34,17 @@ func (c *CommittedFake) GetRangeIDByKey(_ context.Context, _ graveler.StorageNam
 	panic("implement me")
 }
 
+func (c *CommittedFake) ListRanges(_ context.Context, _ graveler.StorageNamespace, _ graveler.MetaRangeID) ([]graveler.RangeID, error) {
+	panic("implement me")
+}
+
+func (c *CommittedFake) CloneMetaRange(_ context.Context, _, _ graveler.StorageNamespace, _ graveler.MetaRangeID, _ graveler.ValueRewriteFunc) (graveler.MetaRangeID, error) {
+	if c.Err != nil {
+		return "", c.Err
+	}
+	return c.MetaRangeID, nil
+}
+
 type MetaRangeFake struct {
 	id graveler.MetaRangeID
 }
@@ -252,6 +264,9 @@ type RefsFake struct {
 	Commits             map[graveler.CommitID]*graveler.Commit
 	StagingToken        graveler.StagingToken
 	SealedTokens        []graveler.StagingToken
+	Reflog              map[graveler.BranchID][]*graveler.ReflogEntry
+	Signatures          map[graveler.CommitID]*graveler.CommitSignature
+	RepositoryMetadata  graveler.RepositoryMetadata
 }
 
 func (m *RefsFake) CreateBranch(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, branch graveler.Branch) error {
@@ -346,11 +361,11 @@ func (m *RefsFake) GetBranch(context.Context, *graveler.RepositoryRecord, gravel
 	return m.Branch, m.Err
 }
 
-func (m *RefsFake) SetBranch(context.Context, *graveler.RepositoryRecord, graveler.BranchID, graveler.Branch) error {
+func (m *RefsFake) SetBranch(context.Context, *graveler.RepositoryRecord, graveler.BranchID, graveler.Branch, string) error {
 	return nil
 }
 
-func (m *RefsFake) BranchUpdate(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, update graveler.BranchUpdateFunc) error {
+func (m *RefsFake) BranchUpdate(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.BranchID, _ string, update graveler.BranchUpdateFunc) error {
 	_, err := update(m.Branch)
 	if m.UpdateErr != nil {
 		return m.UpdateErr
@@ -362,10 +377,37 @@ func (m *RefsFake) DeleteBranch(context.Context, *graveler.RepositoryRecord, gra
 	return nil
 }
 
+func (m *RefsFake) CreateTransaction(context.Context, graveler.Transaction) error {
+	return nil
+}
+
+func (m *RefsFake) TransactionUpdate(context.Context, graveler.TransactionID, graveler.TransactionUpdateFunc) error {
+	return nil
+}
+
+func (m *RefsFake) DeleteTransaction(context.Context, graveler.TransactionID) error {
+	return nil
+}
+
 func (m *RefsFake) ListBranches(context.Context, *graveler.RepositoryRecord) (graveler.BranchIterator, error) {
 	return m.ListBranchesRes, nil
 }
 
+func (m *RefsFake) ListBranchReflog(_ context.Context, _ *graveler.RepositoryRecord, branchID graveler.BranchID) (graveler.ReflogIterator, error) {
+	return NewFakeReflogIterator(m.Reflog[branchID]), nil
+}
+
+func (m *RefsFake) PruneBranchReflog(_ context.Context, _ *graveler.RepositoryRecord, branchID graveler.BranchID, t time.Time) error {
+	var kept []*graveler.ReflogEntry
+	for _, entry := range m.Reflog[branchID] {
+		if entry.CreationDate.After(t) {
+			kept = append(kept, entry)
+		}
+	}
+	m.Reflog[branchID] = kept
+	return nil
+}
+
 func (m *RefsFake) GCBranchIterator(context.Context, *graveler.RepositoryRecord) (graveler.BranchIterator, error) {
 	return m.ListBranchesRes, nil
 }
@@ -398,6 +440,24 @@ func (m *RefsFake) GetCommitByPrefix(_ context.Context, _ *graveler.RepositoryRe
 	return &graveler.Commit{}, nil
 }
 
+func (m *RefsFake) AddCommitSignature(_ context.Context, _ *graveler.RepositoryRecord, signature graveler.CommitSignature) error {
+	if _, ok := m.Signatures[signature.CommitID]; ok {
+		return graveler.ErrCommitSignatureExists
+	}
+	if m.Signatures == nil {
+		m.Signatures = make(map[graveler.CommitID]*graveler.CommitSignature)
+	}
+	m.Signatures[signature.CommitID] = &signature
+	return nil
+}
+
+func (m *RefsFake) GetCommitSignature(_ context.Context, _ *graveler.RepositoryRecord, commitID graveler.CommitID) (*graveler.CommitSignature, error) {
+	if signature, ok := m.Signatures[commitID]; ok {
+		return signature, nil
+	}
+	return nil, graveler.ErrCommitSignatureNotFound
+}
+
 func (m *RefsFake) AddCommit(_ context.Context, _ *graveler.RepositoryRecord, commit graveler.Commit) (graveler.CommitID, error) {
 	if m.CommitErr != nil {
 		return "", m.CommitErr
@@ -828,6 +888,43 @@ func (m *FakeBranchIterator) Err() error {
 
 func (m *FakeBranchIterator) Close() {}
 
+type FakeReflogIterator struct {
+	Data  []*graveler.ReflogEntry
+	Index int
+}
+
+func NewFakeReflogIterator(data []*graveler.ReflogEntry) *FakeReflogIterator {
+	return &FakeReflogIterator{Data: data, Index: -1}
+}
+
+func (m *FakeReflogIterator) Next() bool {
+	if m.Index >= len(m.Data) {
+		return false
+	}
+	m.Index++
+	return m.Index < len(m.Data)
+}
+
+func (m *FakeReflogIterator) SeekGE(id string) {
+	m.Index = len(m.Data)
+	for i, item := range m.Data {
+		if item.ID >= id {
+			m.Index = i - 1
+			return
+		}
+	}
+}
+
+func (m *FakeReflogIterator) Value() *graveler.ReflogEntry {
+	return m.Data[m.Index]
+}
+
+func (m *FakeReflogIterator) Err() error {
+	return nil
+}
+
+func (m *FakeReflogIterator) Close() {}
+
 type FakeCommitIterator struct {
 	Data  []*graveler.CommitRecord
 	Index int
@@ -884,8 +981,7 @@ func (p ProtectedBranchesManagerFake) IsBlocked(_ context.Context, _ *graveler.R
 }
 
 func (m *RefsFake) GetRepositoryMetadata(_ context.Context, _ graveler.RepositoryID) (graveler.RepositoryMetadata, error) {
-	// TODO implement me
-	panic("implement me")
+	return m.RepositoryMetadata, nil
 }
 
 func (m *RefsFake) SetRepositoryMetadata(_ context.Context, _ *graveler.RepositoryRecord, _ graveler.RepoMetadataUpdateFunc) error {
@@ -1,6 +1,7 @@
 package testutil
 
 import (
+	"context"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -18,6 +19,8 @@ type GravelerTest struct {
 	GarbageCollectionManager *mock.MockGarbageCollectionManager
 	KVStore                  *kvmock.MockStore
 	Sut                      *graveler.Graveler
+	// RepositoryMetadata is returned for every repository, it holds the repository access state
+	RepositoryMetadata graveler.RepositoryMetadata
 }
 
 func InitGravelerTest(t *testing.T) *GravelerTest {
@@ -33,6 +36,11 @@ func InitGravelerTest(t *testing.T) *GravelerTest {
 		KVStore:                  kvmock.NewMockStore(ctrl),
 	}
 
+	test.RefManager.EXPECT().GetRepositoryMetadata(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, graveler.RepositoryID) (graveler.RepositoryMetadata, error) {
+			return test.RepositoryMetadata, nil
+		}).AnyTimes()
+
 	test.Sut = graveler.NewGraveler(test.CommittedManager, test.StagingManager, test.RefManager, test.GarbageCollectionManager, test.ProtectedBranchesManager)
 
 	return test
@@ -0,0 +1,245 @@
+package graveler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/treeverse/lakefs/pkg/kv"
+)
+
+// TransactionTimeout is how long a multi-branch commit may hold its branches.  After that anyone may abort it.
+const TransactionTimeout = 5 * time.Minute
+
+// BranchCommit is the commit of a single branch in CommitBranches
+type BranchCommit struct {
+	Repository *RepositoryRecord
+	BranchID   BranchID
+	Params     CommitParams
+}
+
+// transactionBranch tracks the commit of a single branch in a transaction
+type transactionBranch struct {
+	BranchCommit
+	// held is true once the branch is held by the transaction
+	held     bool
+	snapshot commitSnapshot
+	commit   Commit
+	commitID CommitID
+	preRunID string
+}
+
+func (b *transactionBranch) String() string {
+	return fmt.Sprintf("%s/%s", b.Repository.RepositoryID, b.BranchID)
+}
+
+// CommitBranches commits multiple branches, possibly of different repositories, atomically: either all branch heads
+// advance or none do.  It runs a two-phase protocol over a Transaction in the ref-store:
+//   - Prepare: every branch is sealed and held by the transaction, so that its head cannot move.  Then its commit is
+//     computed and added, after running its pre-commit hook.
+//   - Commit: the transaction is marked committed.  From this point on, branches held by the transaction are resolved
+//     to point at its commits.  The branches are then released.
+//
+// If any step before the commit fails, the transaction is aborted and all branches are released unchanged.  The
+// returned commit IDs are ordered as commits.
+func (g *Graveler) CommitBranches(ctx context.Context, commits []BranchCommit) ([]CommitID, error) {
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("commits: %w", ErrRequiredValue)
+	}
+	branches := make([]*transactionBranch, 0, len(commits))
+	seen := make(map[string]struct{}, len(commits))
+	for _, c := range commits {
+		b := &transactionBranch{BranchCommit: c}
+		if c.Params.SourceMetaRange != nil {
+			return nil, fmt.Errorf("branch %s source metarange: %w", b, ErrInvalidValue)
+		}
+		if _, ok := seen[b.String()]; ok {
+			return nil, fmt.Errorf("branch %s appears more than once: %w", b, ErrInvalidValue)
+		}
+		seen[b.String()] = struct{}{}
+		if err := g.checkWritable(ctx, c.Repository); err != nil {
+			return nil, fmt.Errorf("branch %s: %w", b, err)
+		}
+		isProtected, err := g.protectedBranchesManager.IsBlocked(ctx, c.Repository, c.BranchID, BranchProtectionBlockedAction_COMMIT)
+		if err != nil {
+			return nil, err
+		}
+		if isProtected {
+			return nil, fmt.Errorf("branch %s: %w", b, ErrCommitToProtectedBranch)
+		}
+		if err := g.checkUnsignedCommitAllowed(ctx, c.Repository, c.BranchID); err != nil {
+			return nil, err
+		}
+		branches = append(branches, b)
+	}
+
+	// hold branches in a consistent order, so that concurrent transactions cannot hold each other's branches
+	ordered := make([]*transactionBranch, len(branches))
+	copy(ordered, branches)
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].String() < ordered[j].String()
+	})
+
+	tx := Transaction{
+		ID:       TransactionID(xid.New().String()),
+		Status:   TransactionStatus_PENDING,
+		Deadline: time.Now().Add(TransactionTimeout),
+	}
+	if err := g.RefManager.CreateTransaction(ctx, tx); err != nil {
+		return nil, fmt.Errorf("create transaction: %w", err)
+	}
+	log := g.log(ctx).WithField("transaction_id", tx.ID)
+
+	err := g.prepareTransaction(ctx, tx.ID, ordered)
+	if err == nil {
+		err = g.RefManager.TransactionUpdate(ctx, tx.ID, func(tx *Transaction) (*Transaction, error) {
+			if tx.Status != TransactionStatus_PENDING || time.Now().After(tx.Deadline) {
+				return nil, ErrTransactionAborted
+			}
+			tx.Status = TransactionStatus_COMMITTED
+			tx.Branches = make([]TransactionBranch, 0, len(ordered))
+			for _, b := range ordered {
+				tx.Branches = append(tx.Branches, TransactionBranch{
+					RepositoryID: b.Repository.RepositoryID,
+					BranchID:     b.BranchID,
+					CommitID:     b.commitID,
+					SealedTokens: b.snapshot.sealed,
+				})
+			}
+			return tx, nil
+		})
+		if errors.Is(err, kv.ErrPredicateFailed) {
+			// only an abort may update a pending transaction concurrently
+			err = ErrTransactionAborted
+		}
+	}
+	if err != nil {
+		err2 := g.RefManager.TransactionUpdate(ctx, tx.ID, func(tx *Transaction) (*Transaction, error) {
+			if tx.Status != TransactionStatus_PENDING {
+				return nil, nil
+			}
+			tx.Status = TransactionStatus_ABORTED
+			return tx, nil
+		})
+		if err2 != nil {
+			// branches are released once the transaction passes its deadline
+			log.WithError(err2).Error("Failed to abort transaction")
+			return nil, err
+		}
+		g.releaseTransaction(ctx, tx.ID, ordered)
+		return nil, err
+	}
+
+	g.releaseTransaction(ctx, tx.ID, ordered)
+
+	commitIDs := make([]CommitID, 0, len(branches))
+	for _, b := range branches {
+		g.dropTokens(ctx, b.snapshot.sealed...)
+		commitIDs = append(commitIDs, b.commitID)
+
+		postRunID := g.hooks.NewRunID()
+		err := g.hooks.PostCommitHook(ctx, HookRecord{
+			EventType:        EventTypePostCommit,
+			RunID:            postRunID,
+			RepositoryID:     b.Repository.RepositoryID,
+			StorageNamespace: b.Repository.StorageNamespace,
+			SourceRef:        b.commitID.Ref(),
+			BranchID:         b.BranchID,
+			Commit:           b.commit,
+			CommitID:         b.commitID,
+			PreRunID:         b.preRunID,
+		})
+		if err != nil {
+			log.WithError(err).
+				WithField("run_id", postRunID).
+				WithField("pre_run_id", b.preRunID).
+				WithField("branch", b.String()).
+				Error("Post-commit hook failed")
+		}
+	}
+	return commitIDs, nil
+}
+
+// prepareTransaction holds all branches by transactionID and adds their commits.
+func (g *Graveler) prepareTransaction(ctx context.Context, transactionID TransactionID, branches []*transactionBranch) error {
+	for _, b := range branches {
+		err := g.retryBranchUpdate(ctx, b.Repository, b.BranchID, func(branch *Branch) (*Branch, error) {
+			if branch.Transaction != "" {
+				return nil, ErrBranchLocked
+			}
+			branch.SealedTokens = append([]StagingToken{branch.StagingToken}, branch.SealedTokens...)
+			branch.StagingToken = GenerateStagingToken(b.Repository.RepositoryID, b.BranchID)
+			branch.Transaction = transactionID
+			b.snapshot = commitSnapshot{commitID: branch.CommitID, sealed: branch.SealedTokens}
+			return branch, nil
+		}, "commit_branches")
+		if err != nil {
+			return fmt.Errorf("branch %s: %w", b, err)
+		}
+		b.held = true
+	}
+
+	for _, b := range branches {
+		b.commit = newCommitFromParams(b.Params, b.snapshot.commitID)
+		b.preRunID = g.hooks.NewRunID()
+		err := g.hooks.PreCommitHook(ctx, HookRecord{
+			RunID:            b.preRunID,
+			EventType:        EventTypePreCommit,
+			SourceRef:        b.BranchID.Ref(),
+			RepositoryID:     b.Repository.RepositoryID,
+			StorageNamespace: b.Repository.StorageNamespace,
+			BranchID:         b.BranchID,
+			Commit:           b.commit,
+		})
+		if err != nil {
+			return &HookAbortError{
+				EventType: EventTypePreCommit,
+				RunID:     b.preRunID,
+				Err:       err,
+			}
+		}
+		if err := g.applyCommitSnapshot(ctx, b.Repository, b.snapshot, nil, &b.commit); err != nil {
+			return fmt.Errorf("branch %s: %w", b, err)
+		}
+		b.commitID, err = g.RefManager.AddCommit(ctx, b.Repository, b.commit)
+		if err != nil {
+			return fmt.Errorf("branch %s add commit: %w", b, err)
+		}
+	}
+	return nil
+}
+
+// releaseTransaction releases the branches held by ended transaction transactionID, and deletes it.  The ref manager
+// resolves branches held by an ended transaction, so writing them back releases them.  Branches that fail to release
+// here are released by their next update.
+func (g *Graveler) releaseTransaction(ctx context.Context, transactionID TransactionID, branches []*transactionBranch) {
+	log := g.log(ctx).WithField("transaction_id", transactionID)
+	released := true
+	for _, b := range branches {
+		if !b.held {
+			continue
+		}
+		// releasing a branch is not a change: it succeeds also once the repository stops accepting changes
+		err := g.retryBranchUpdateWith(ctx, b.Repository, b.BranchID, "commit_branches", func() error {
+			return g.RefManager.BranchUpdate(ctx, b.Repository, b.BranchID, "commit_branches", func(branch *Branch) (*Branch, error) {
+				if branch.Transaction == transactionID {
+					return nil, ErrBranchLocked
+				}
+				return branch, nil
+			})
+		})
+		if err != nil {
+			log.WithError(err).WithField("branch", b.String()).Warn("Failed to release branch")
+			released = false
+		}
+	}
+	if !released {
+		return
+	}
+	if err := g.RefManager.DeleteTransaction(ctx, transactionID); err != nil {
+		log.WithError(err).Warn("Failed to delete transaction")
+	}
+}
@@ -0,0 +1,298 @@
+// Package iceberg serves the Iceberg REST catalog protocol over lakeFS repositories.
+//
+// The first two levels of every namespace are a repository and a ref, e.g. the namespace "repo.main.db" is the
+// directory "db" on branch "main" of repository "repo".  Table metadata files and the pointer to the current one are
+// objects on the branch and every table commit is a lakeFS commit, so branching a repository branches all of its
+// tables at once.
+package iceberg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/treeverse/lakefs/pkg/auth"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/catalog"
+	"github.com/treeverse/lakefs/pkg/graveler"
+	"github.com/treeverse/lakefs/pkg/logging"
+	"github.com/treeverse/lakefs/pkg/permissions"
+	"github.com/treeverse/lakefs/pkg/upload"
+)
+
+var (
+	ErrBadRequest        = errors.New("bad request")
+	ErrNoSuchNamespace   = errors.New("namespace does not exist")
+	ErrNoSuchTable       = errors.New("table does not exist")
+	ErrAlreadyExists     = errors.New("already exists")
+	ErrNamespaceNotEmpty = errors.New("namespace is not empty")
+	ErrCommitFailed      = errors.New("commit failed")
+	ErrNotAuthorized     = errors.New("not authorized")
+	ErrForbidden         = errors.New("insufficient permissions")
+)
+
+// namespaceSeparator separates namespace levels in request paths and parameters
+const namespaceSeparator = "\x1f"
+
+// Handler serves the Iceberg REST catalog API.  Requests must be authenticated before they reach the handler, with
+// the user set on the request context.
+type Handler struct {
+	catalog      catalog.Interface
+	adapter      block.Adapter
+	pathProvider upload.PathProvider
+	authorizer   auth.Authorizer
+	logger       logging.Logger
+	router       chi.Router
+}
+
+func NewHandler(c catalog.Interface, adapter block.Adapter, pathProvider upload.PathProvider, authorizer auth.Authorizer, logger logging.Logger) *Handler {
+	h := &Handler{
+		catalog:      c,
+		adapter:      adapter,
+		pathProvider: pathProvider,
+		authorizer:   authorizer,
+		logger:       logger,
+	}
+	r := chi.NewRouter()
+	r.Get("/v1/config", h.getConfig)
+	r.Get("/v1/namespaces", h.listNamespaces)
+	r.Post("/v1/namespaces", h.createNamespace)
+	r.Get("/v1/namespaces/{namespace}", h.loadNamespace)
+	r.Head("/v1/namespaces/{namespace}", h.namespaceExists)
+	r.Delete("/v1/namespaces/{namespace}", h.dropNamespace)
+	r.Post("/v1/namespaces/{namespace}/properties", h.updateNamespaceProperties)
+	r.Get("/v1/namespaces/{namespace}/tables", h.listTables)
+	r.Post("/v1/namespaces/{namespace}/tables", h.createTable)
+	r.Get("/v1/namespaces/{namespace}/tables/{table}", h.loadTable)
+	r.Head("/v1/namespaces/{namespace}/tables/{table}", h.tableExists)
+	r.Post("/v1/namespaces/{namespace}/tables/{table}", h.commitTable)
+	r.Delete("/v1/namespaces/{namespace}/tables/{table}", h.dropTable)
+	r.Post("/v1/tables/rename", h.renameTable)
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		h.writeError(w, r, fmt.Errorf("%w: unsupported endpoint %s %s", ErrBadRequest, r.Method, r.URL.Path))
+	})
+	h.router = r
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.router.ServeHTTP(w, r)
+}
+
+type errorModel struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    int    `json:"code"`
+}
+
+type errorResponse struct {
+	Error errorModel `json:"error"`
+}
+
+func errorTypeAndCode(err error) (string, int) {
+	switch {
+	case errors.Is(err, ErrBadRequest):
+		return "BadRequestException", http.StatusBadRequest
+	case errors.Is(err, ErrNotAuthorized):
+		return "NotAuthorizedException", http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden):
+		return "ForbiddenException", http.StatusForbidden
+	case errors.Is(err, ErrNoSuchNamespace):
+		return "NoSuchNamespaceException", http.StatusNotFound
+	case errors.Is(err, ErrNoSuchTable):
+		return "NoSuchTableException", http.StatusNotFound
+	case errors.Is(err, ErrAlreadyExists):
+		return "AlreadyExistsException", http.StatusConflict
+	case errors.Is(err, ErrNamespaceNotEmpty):
+		return "NamespaceNotEmptyException", http.StatusConflict
+	case errors.Is(err, ErrCommitFailed), errors.Is(err, graveler.ErrPreconditionFailed):
+		return "CommitFailedException", http.StatusConflict
+	case errors.Is(err, graveler.ErrNotFound):
+		return "NoSuchNamespaceException", http.StatusNotFound
+	case errors.Is(err, graveler.ErrInvalidValue), errors.Is(err, graveler.ErrInvalid):
+		return "BadRequestException", http.StatusBadRequest
+	default:
+		return "ServerError", http.StatusInternalServerError
+	}
+}
+
+// WriteError writes err as an Iceberg REST catalog error response
+func WriteError(w http.ResponseWriter, err error) {
+	errType, code := errorTypeAndCode(err)
+	writeJSON(w, code, errorResponse{Error: errorModel{Message: err.Error(), Type: errType, Code: code}})
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if _, code := errorTypeAndCode(err); code == http.StatusInternalServerError {
+		h.logger.WithContext(r.Context()).WithError(err).Error("Iceberg catalog request failed")
+	}
+	WriteError(w, err)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("%w: %s", ErrBadRequest, err)
+	}
+	return nil
+}
+
+// authorize checks that the user of the request has all perms
+func (h *Handler) authorize(ctx context.Context, perms ...permissions.Permission) error {
+	user, err := auth.GetUser(ctx)
+	if err != nil {
+		return ErrNotAuthorized
+	}
+	node := permissions.Node{Type: permissions.NodeTypeAnd}
+	for _, p := range perms {
+		node.Nodes = append(node.Nodes, permissions.Node{Permission: p})
+	}
+	resp, err := h.authorizer.Authorize(ctx, &auth.AuthorizationRequest{
+		Username:            user.Username,
+		RequiredPermissions: node,
+	})
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("%w: %s", ErrForbidden, resp.Error)
+	}
+	if !resp.Allowed {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// namespace is a parsed Iceberg namespace: a repository, a ref and the directory levels under it
+type namespace struct {
+	levels []string
+}
+
+func parseNamespace(s string) (namespace, error) {
+	if s == "" {
+		return namespace{}, nil
+	}
+	levels := strings.Split(s, namespaceSeparator)
+	for _, level := range levels {
+		if level == "" || strings.Contains(level, "/") {
+			return namespace{}, fmt.Errorf("%w: invalid namespace level %q", ErrBadRequest, level)
+		}
+	}
+	return namespace{levels: levels}, nil
+}
+
+func namespaceParam(r *http.Request) (namespace, error) {
+	s, err := url.PathUnescape(chi.URLParam(r, "namespace"))
+	if err != nil {
+		return namespace{}, fmt.Errorf("%w: %s", ErrBadRequest, err)
+	}
+	ns, err := parseNamespace(s)
+	if err != nil {
+		return namespace{}, err
+	}
+	if len(ns.levels) == 0 {
+		return namespace{}, fmt.Errorf("%w: empty namespace", ErrBadRequest)
+	}
+	return ns, nil
+}
+
+func (n namespace) repository() string {
+	return n.levels[0]
+}
+
+func (n namespace) ref() string {
+	return n.levels[1]
+}
+
+// hasDirectory reports whether the namespace is a directory under a ref, rather than a repository or a ref
+func (n namespace) hasDirectory() bool {
+	return len(n.levels) > 2
+}
+
+// path returns the directory of the namespace, with a trailing slash unless it is the root of the ref
+func (n namespace) path() string {
+	if !n.hasDirectory() {
+		return ""
+	}
+	return strings.Join(n.levels[2:], "/") + "/"
+}
+
+func (n namespace) child(name string) namespace {
+	levels := make([]string, len(n.levels), len(n.levels)+1)
+	copy(levels, n.levels)
+	return namespace{levels: append(levels, name)}
+}
+
+func (n namespace) String() string {
+	return strings.Join(n.levels, ".")
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, graveler.ErrNotFound)
+}
+
+// requireBranch verifies the ref of the namespace is a branch, as only branches can be written
+func (h *Handler) requireBranch(ctx context.Context, ns namespace) error {
+	exists, err := h.catalog.BranchExists(ctx, ns.repository(), ns.ref())
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s is not a branch of %s", ErrBadRequest, ns.ref(), ns.repository())
+	}
+	return nil
+}
+
+// commitMaxTries is the number of times an operation is attempted while other commits move the head of its branch
+const commitMaxTries = 5
+
+// commitChanges commits the object changes of an operation on the branch of the namespace.  build computes the
+// changes from the objects of the head commit of the branch, which they are committed on top of.  Only the changes
+// that build returns are committed, and the changes of the operation are never staged.  If another commit moves the
+// head first, the changes are computed again from the new head.
+func (h *Handler) commitChanges(ctx context.Context, ns namespace, message string, metadata catalog.Metadata, build func(head string) ([]catalog.EntryChange, error)) error {
+	user, err := auth.GetUser(ctx)
+	if err != nil {
+		return ErrNotAuthorized
+	}
+	for try := 1; ; try++ {
+		head, err := h.catalog.GetBranchReference(ctx, ns.repository(), ns.ref())
+		if err != nil {
+			return err
+		}
+		changes, err := build(head)
+		if err != nil {
+			return err
+		}
+		_, err = h.catalog.CommitChanges(ctx, ns.repository(), ns.ref(), head, changes, message, user.Username, metadata)
+		switch {
+		case errors.Is(err, graveler.ErrNoChanges):
+			// the head already holds the objects of the operation
+			return nil
+		case errors.Is(err, graveler.ErrBranchHeadMoved) && try < commitMaxTries:
+			continue
+		case errors.Is(err, graveler.ErrBranchHeadMoved):
+			return fmt.Errorf("%w: branch %s kept moving", ErrCommitFailed, ns.ref())
+		}
+		return err
+	}
+}
+
+func (h *Handler) getConfig(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"defaults":  map[string]string{},
+		"overrides": map[string]string{},
+	})
+}
@@ -0,0 +1,436 @@
+package iceberg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Metadata is an Iceberg table metadata document.  It is handled as generic JSON so that fields this catalog does
+// not know about are kept as written by the engines.
+type Metadata map[string]interface{}
+
+// TableUpdate is an update of the table metadata in a table commit, e.g. {"action": "add-snapshot", ...}
+type TableUpdate map[string]interface{}
+
+// TableRequirement is a requirement of a table commit, e.g. {"type": "assert-current-schema-id", ...}
+type TableRequirement map[string]interface{}
+
+const (
+	formatVersion      = 2
+	noSnapshotID       = -1
+	lastAdded          = -1
+	initialPartitionID = 999
+	mainBranch         = "main"
+)
+
+// decodeJSON decodes JSON keeping numbers as json.Number, as snapshot IDs do not fit in a float64
+func decodeJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case float64:
+		return int64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func (m Metadata) int64(key string) int64 {
+	i, _ := toInt64(m[key])
+	return i
+}
+
+func (m Metadata) list(key string) []interface{} {
+	l, _ := m[key].([]interface{})
+	return l
+}
+
+func (m Metadata) object(key string) map[string]interface{} {
+	o, ok := m[key].(map[string]interface{})
+	if !ok {
+		o = make(map[string]interface{})
+		m[key] = o
+	}
+	return o
+}
+
+// maxID returns the maximal value of the key in the objects of l, or def if there is none
+func maxID(l []interface{}, key string, def int64) int64 {
+	res := def
+	for _, v := range l {
+		if o, ok := v.(map[string]interface{}); ok {
+			if id, ok := toInt64(o[key]); ok && id > res {
+				res = id
+			}
+		}
+	}
+	return res
+}
+
+func hasID(l []interface{}, key string, id int64) bool {
+	for _, v := range l {
+		if o, ok := v.(map[string]interface{}); ok {
+			if i, ok := toInt64(o[key]); ok && i == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// maxFieldID returns the maximal field ID in a schema, including nested list, map and struct fields
+func maxFieldID(v interface{}) int64 {
+	var res int64
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			switch k {
+			case "id", "element-id", "key-id", "value-id":
+				if id, ok := toInt64(child); ok && id > res {
+					res = id
+				}
+			default:
+				if id := maxFieldID(child); id > res {
+					res = id
+				}
+			}
+		}
+	case []interface{}:
+		for _, child := range t {
+			if id := maxFieldID(child); id > res {
+				res = id
+			}
+		}
+	}
+	return res
+}
+
+// newMetadata returns the metadata of a table with no schema, specs or snapshots
+func newMetadata(location string, now time.Time) Metadata {
+	return Metadata{
+		"format-version":        formatVersion,
+		"table-uuid":            uuid.New().String(),
+		"location":              location,
+		"last-sequence-number":  0,
+		"last-updated-ms":       now.UnixMilli(),
+		"last-column-id":        0,
+		"current-schema-id":     0,
+		"schemas":               []interface{}{},
+		"default-spec-id":       0,
+		"partition-specs":       []interface{}{},
+		"last-partition-id":     initialPartitionID,
+		"default-sort-order-id": 0,
+		"sort-orders":           []interface{}{},
+		"properties":            map[string]interface{}{},
+		"current-snapshot-id":   noSnapshotID,
+		"refs":                  map[string]interface{}{},
+		"snapshots":             []interface{}{},
+		"snapshot-log":          []interface{}{},
+		"metadata-log":          []interface{}{},
+	}
+}
+
+// createTableUpdates returns the updates that set up a new table from a create table request
+func createTableUpdates(req *createTableRequest) []TableUpdate {
+	spec := req.PartitionSpec
+	if spec == nil {
+		spec = map[string]interface{}{"fields": []interface{}{}}
+	}
+	order := req.WriteOrder
+	if order == nil {
+		order = map[string]interface{}{"fields": []interface{}{}}
+	}
+	properties := make(map[string]interface{}, len(req.Properties))
+	for k, v := range req.Properties {
+		properties[k] = v
+	}
+	return []TableUpdate{
+		{"action": "add-schema", "schema": req.Schema},
+		{"action": "set-current-schema", "schema-id": lastAdded},
+		{"action": "add-spec", "spec": spec},
+		{"action": "set-default-spec", "spec-id": lastAdded},
+		{"action": "add-sort-order", "sort-order": order},
+		{"action": "set-default-sort-order", "sort-order-id": lastAdded},
+		{"action": "set-properties", "updates": properties},
+	}
+}
+
+// checkRequirements verifies the requirements of a commit against the current metadata of the table, which is nil
+// if the table does not exist.
+func checkRequirements(m Metadata, requirements []TableRequirement) error {
+	for _, req := range requirements {
+		reqType, _ := req["type"].(string)
+		if reqType == "assert-create" {
+			if m != nil {
+				return fmt.Errorf("%w: table already exists", ErrCommitFailed)
+			}
+			continue
+		}
+		if m == nil {
+			return fmt.Errorf("%w: %s: table does not exist", ErrCommitFailed, reqType)
+		}
+		var ok bool
+		switch reqType {
+		case "assert-table-uuid":
+			ok = req["uuid"] == m["table-uuid"]
+		case "assert-ref-snapshot-id":
+			ok = refSnapshotMatches(m, req)
+		case "assert-last-assigned-field-id":
+			ok = int64Equal(req["last-assigned-field-id"], m["last-column-id"])
+		case "assert-current-schema-id":
+			ok = int64Equal(req["current-schema-id"], m["current-schema-id"])
+		case "assert-last-assigned-partition-id":
+			ok = int64Equal(req["last-assigned-partition-id"], m["last-partition-id"])
+		case "assert-default-spec-id":
+			ok = int64Equal(req["default-spec-id"], m["default-spec-id"])
+		case "assert-default-sort-order-id":
+			ok = int64Equal(req["default-sort-order-id"], m["default-sort-order-id"])
+		default:
+			return fmt.Errorf("%w: unknown requirement %s", ErrBadRequest, reqType)
+		}
+		if !ok {
+			return fmt.Errorf("%w: requirement %s failed", ErrCommitFailed, reqType)
+		}
+	}
+	return nil
+}
+
+func int64Equal(a, b interface{}) bool {
+	x, ok := toInt64(a)
+	if !ok {
+		return false
+	}
+	y, ok := toInt64(b)
+	return ok && x == y
+}
+
+// refSnapshotMatches checks that a ref points to a snapshot, or does not exist if the snapshot ID is null
+func refSnapshotMatches(m Metadata, req TableRequirement) bool {
+	name, _ := req["ref"].(string)
+	var current interface{}
+	if ref, ok := m.object("refs")[name].(map[string]interface{}); ok {
+		current = ref["snapshot-id"]
+	} else if name == mainBranch && m.int64("current-snapshot-id") != noSnapshotID {
+		current = m["current-snapshot-id"]
+	}
+	if req["snapshot-id"] == nil {
+		return current == nil
+	}
+	return int64Equal(req["snapshot-id"], current)
+}
+
+// updater applies updates to metadata, tracking the IDs of the last added schema, spec and sort order for the
+// updates that refer to them as -1.
+type updater struct {
+	m               Metadata
+	now             time.Time
+	lastSchemaID    *int64
+	lastSpecID      *int64
+	lastSortOrderID *int64
+}
+
+func applyUpdates(m Metadata, updates []TableUpdate, now time.Time) error {
+	u := &updater{m: m, now: now}
+	for _, update := range updates {
+		if err := u.apply(update); err != nil {
+			return err
+		}
+	}
+	m["last-updated-ms"] = now.UnixMilli()
+	return nil
+}
+
+func (u *updater) apply(update TableUpdate) error {
+	m := u.m
+	action, _ := update["action"].(string)
+	switch action {
+	case "assign-uuid":
+		m["table-uuid"] = update["uuid"]
+	case "upgrade-format-version":
+		m["format-version"] = update["format-version"]
+	case "add-schema":
+		schema, ok := update["schema"].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%w: add-schema without schema", ErrBadRequest)
+		}
+		id := maxID(m.list("schemas"), "schema-id", -1) + 1
+		schema["schema-id"] = id
+		m["schemas"] = append(m.list("schemas"), schema)
+		lastColumnID := m.int64("last-column-id")
+		if id, ok := toInt64(update["last-column-id"]); ok && id > lastColumnID {
+			lastColumnID = id
+		}
+		if id := maxFieldID(schema["fields"]); id > lastColumnID {
+			lastColumnID = id
+		}
+		m["last-column-id"] = lastColumnID
+		u.lastSchemaID = &id
+	case "set-current-schema":
+		id, err := u.resolveID(update["schema-id"], u.lastSchemaID, "schemas", "schema-id")
+		if err != nil {
+			return err
+		}
+		m["current-schema-id"] = id
+	case "add-spec":
+		spec, ok := update["spec"].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%w: add-spec without spec", ErrBadRequest)
+		}
+		id := maxID(m.list("partition-specs"), "spec-id", -1) + 1
+		spec["spec-id"] = id
+		m["partition-specs"] = append(m.list("partition-specs"), spec)
+		fields, _ := spec["fields"].([]interface{})
+		m["last-partition-id"] = maxID(fields, "field-id", m.int64("last-partition-id"))
+		u.lastSpecID = &id
+	case "set-default-spec":
+		id, err := u.resolveID(update["spec-id"], u.lastSpecID, "partition-specs", "spec-id")
+		if err != nil {
+			return err
+		}
+		m["default-spec-id"] = id
+	case "add-sort-order":
+		order, ok := update["sort-order"].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%w: add-sort-order without sort-order", ErrBadRequest)
+		}
+		// the unsorted order is always order 0
+		var id int64
+		if fields, _ := order["fields"].([]interface{}); len(fields) > 0 {
+			id = maxID(m.list("sort-orders"), "order-id", 0) + 1
+		}
+		order["order-id"] = id
+		if !hasID(m.list("sort-orders"), "order-id", id) {
+			m["sort-orders"] = append(m.list("sort-orders"), order)
+		}
+		u.lastSortOrderID = &id
+	case "set-default-sort-order":
+		id, err := u.resolveID(update["sort-order-id"], u.lastSortOrderID, "sort-orders", "order-id")
+		if err != nil {
+			return err
+		}
+		m["default-sort-order-id"] = id
+	case "add-snapshot":
+		snapshot, ok := update["snapshot"].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%w: add-snapshot without snapshot", ErrBadRequest)
+		}
+		m["snapshots"] = append(m.list("snapshots"), snapshot)
+		if seq, ok := toInt64(snapshot["sequence-number"]); ok && seq > m.int64("last-sequence-number") {
+			m["last-sequence-number"] = seq
+		}
+	case "set-snapshot-ref":
+		return u.setSnapshotRef(update)
+	case "remove-snapshots":
+		ids, _ := update["snapshot-ids"].([]interface{})
+		removed := make(map[int64]struct{}, len(ids))
+		for _, v := range ids {
+			if id, ok := toInt64(v); ok {
+				removed[id] = struct{}{}
+			}
+		}
+		snapshots := make([]interface{}, 0, len(m.list("snapshots")))
+		for _, v := range m.list("snapshots") {
+			s, _ := v.(map[string]interface{})
+			id, _ := toInt64(s["snapshot-id"])
+			if _, ok := removed[id]; !ok {
+				snapshots = append(snapshots, v)
+			}
+		}
+		m["snapshots"] = snapshots
+	case "remove-snapshot-ref":
+		name, _ := update["ref-name"].(string)
+		delete(m.object("refs"), name)
+		if name == mainBranch {
+			m["current-snapshot-id"] = noSnapshotID
+		}
+	case "set-location":
+		m["location"] = update["location"]
+	case "set-properties":
+		updates, _ := update["updates"].(map[string]interface{})
+		properties := m.object("properties")
+		for k, v := range updates {
+			properties[k] = v
+		}
+	case "remove-properties":
+		removals, _ := update["removals"].([]interface{})
+		properties := m.object("properties")
+		for _, k := range removals {
+			if key, ok := k.(string); ok {
+				delete(properties, key)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown update action %s", ErrBadRequest, action)
+	}
+	return nil
+}
+
+// resolveID returns the ID an update sets, resolving -1 to the last added ID and verifying the ID exists
+func (u *updater) resolveID(v interface{}, last *int64, listKey, idKey string) (int64, error) {
+	id, ok := toInt64(v)
+	if !ok {
+		return 0, fmt.Errorf("%w: missing %s", ErrBadRequest, idKey)
+	}
+	if id == lastAdded {
+		if last == nil {
+			return 0, fmt.Errorf("%w: no %s was added", ErrBadRequest, idKey)
+		}
+		id = *last
+	}
+	if !hasID(u.m.list(listKey), idKey, id) {
+		return 0, fmt.Errorf("%w: unknown %s %d", ErrBadRequest, idKey, id)
+	}
+	return id, nil
+}
+
+func (u *updater) setSnapshotRef(update TableUpdate) error {
+	m := u.m
+	name, _ := update["ref-name"].(string)
+	id, ok := toInt64(update["snapshot-id"])
+	if name == "" || !ok {
+		return fmt.Errorf("%w: set-snapshot-ref without ref-name or snapshot-id", ErrBadRequest)
+	}
+	var snapshot map[string]interface{}
+	for _, v := range m.list("snapshots") {
+		if s, _ := v.(map[string]interface{}); s != nil && int64Equal(s["snapshot-id"], id) {
+			snapshot = s
+		}
+	}
+	if snapshot == nil {
+		return fmt.Errorf("%w: unknown snapshot %d", ErrBadRequest, id)
+	}
+	ref := make(map[string]interface{})
+	for k, v := range update {
+		if k != "action" && k != "ref-name" {
+			ref[k] = v
+		}
+	}
+	m.object("refs")[name] = ref
+	if name == mainBranch {
+		m["current-snapshot-id"] = id
+		timestamp, ok := toInt64(snapshot["timestamp-ms"])
+		if !ok {
+			timestamp = u.now.UnixMilli()
+		}
+		m["snapshot-log"] = append(m.list("snapshot-log"), map[string]interface{}{
+			"timestamp-ms": timestamp,
+			"snapshot-id":  id,
+		})
+	}
+	return nil
+}
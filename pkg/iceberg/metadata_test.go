@@ -0,0 +1,114 @@
+package iceberg
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCreateTableMetadata(t *testing.T) {
+	m := newMetadata("s3://repo/main/db/t", time.Now())
+	req := &createTableRequest{
+		Schema: map[string]interface{}{
+			"type": "struct",
+			"fields": []interface{}{
+				map[string]interface{}{"id": 1, "name": "id", "type": "long"},
+				map[string]interface{}{"id": 2, "name": "tags", "type": map[string]interface{}{
+					"type": "list", "element-id": 3, "element": "string",
+				}},
+			},
+		},
+		PartitionSpec: map[string]interface{}{
+			"fields": []interface{}{map[string]interface{}{"field-id": 1000, "source-id": 1, "transform": "identity"}},
+		},
+		Properties: map[string]string{"owner": "data"},
+	}
+	if err := applyUpdates(m, createTableUpdates(req), time.Now()); err != nil {
+		t.Fatalf("applyUpdates() failed: %s", err)
+	}
+	if m.int64("last-column-id") != 3 {
+		t.Errorf("last-column-id = %v, expected 3", m["last-column-id"])
+	}
+	if m.int64("last-partition-id") != 1000 {
+		t.Errorf("last-partition-id = %v, expected 1000", m["last-partition-id"])
+	}
+	if m.int64("current-schema-id") != 0 || m.int64("default-spec-id") != 0 || m.int64("default-sort-order-id") != 0 {
+		t.Errorf("current IDs = %v, %v, %v, expected 0", m["current-schema-id"], m["default-spec-id"], m["default-sort-order-id"])
+	}
+	if m.object("properties")["owner"] != "data" {
+		t.Errorf("properties = %v", m["properties"])
+	}
+}
+
+func TestApplyUpdates(t *testing.T) {
+	m := newMetadata("s3://repo/main/db/t", time.Now())
+	snapshot := func(id int64) TableUpdate {
+		return TableUpdate{"action": "add-snapshot", "snapshot": map[string]interface{}{"snapshot-id": id, "sequence-number": id, "timestamp-ms": 1000 * id}}
+	}
+	updates := []TableUpdate{
+		snapshot(1),
+		{"action": "set-snapshot-ref", "ref-name": "main", "type": "branch", "snapshot-id": int64(1)},
+		snapshot(2),
+		{"action": "set-snapshot-ref", "ref-name": "main", "type": "branch", "snapshot-id": int64(2)},
+		{"action": "set-snapshot-ref", "ref-name": "audit", "type": "tag", "snapshot-id": int64(1)},
+		{"action": "remove-snapshot-ref", "ref-name": "audit"},
+		{"action": "set-properties", "updates": map[string]interface{}{"a": "1", "b": "2"}},
+		{"action": "remove-properties", "removals": []interface{}{"a"}},
+	}
+	if err := applyUpdates(m, updates, time.Now()); err != nil {
+		t.Fatalf("applyUpdates() failed: %s", err)
+	}
+	if m.int64("current-snapshot-id") != 2 || m.int64("last-sequence-number") != 2 {
+		t.Errorf("current snapshot = %v, last sequence number = %v, expected 2", m["current-snapshot-id"], m["last-sequence-number"])
+	}
+	if l := len(m.list("snapshot-log")); l != 2 {
+		t.Errorf("snapshot log length = %d, expected 2", l)
+	}
+	if _, ok := m.object("refs")["audit"]; ok {
+		t.Errorf("refs = %v, expected audit to be removed", m["refs"])
+	}
+	if properties := m.object("properties"); len(properties) != 1 || properties["b"] != "2" {
+		t.Errorf("properties = %v, expected only b", properties)
+	}
+
+	err := applyUpdates(m, []TableUpdate{{"action": "set-snapshot-ref", "ref-name": "main", "snapshot-id": int64(3)}}, time.Now())
+	if !errors.Is(err, ErrBadRequest) {
+		t.Errorf("set unknown snapshot err = %v, expected %s", err, ErrBadRequest)
+	}
+	err = applyUpdates(m, []TableUpdate{{"action": "set-current-schema", "schema-id": int64(lastAdded)}}, time.Now())
+	if !errors.Is(err, ErrBadRequest) {
+		t.Errorf("set last added schema with no added schema err = %v, expected %s", err, ErrBadRequest)
+	}
+}
+
+func TestCheckRequirements(t *testing.T) {
+	m := newMetadata("s3://repo/main/db/t", time.Now())
+	m["table-uuid"] = "uuid"
+	m["current-snapshot-id"] = int64(5)
+	tests := []struct {
+		name        string
+		metadata    Metadata
+		req         TableRequirement
+		expectedErr error
+	}{
+		{name: "create", req: TableRequirement{"type": "assert-create"}},
+		{name: "create existing", metadata: m, req: TableRequirement{"type": "assert-create"}, expectedErr: ErrCommitFailed},
+		{name: "missing table", req: TableRequirement{"type": "assert-table-uuid", "uuid": "uuid"}, expectedErr: ErrCommitFailed},
+		{name: "uuid", metadata: m, req: TableRequirement{"type": "assert-table-uuid", "uuid": "uuid"}},
+		{name: "other uuid", metadata: m, req: TableRequirement{"type": "assert-table-uuid", "uuid": "other"}, expectedErr: ErrCommitFailed},
+		{name: "main snapshot", metadata: m, req: TableRequirement{"type": "assert-ref-snapshot-id", "ref": "main", "snapshot-id": int64(5)}},
+		{name: "main changed", metadata: m, req: TableRequirement{"type": "assert-ref-snapshot-id", "ref": "main", "snapshot-id": int64(4)}, expectedErr: ErrCommitFailed},
+		{name: "new ref", metadata: m, req: TableRequirement{"type": "assert-ref-snapshot-id", "ref": "dev", "snapshot-id": nil}},
+		{name: "schema", metadata: m, req: TableRequirement{"type": "assert-current-schema-id", "current-schema-id": int64(0)}},
+		{name: "schema changed", metadata: m, req: TableRequirement{"type": "assert-current-schema-id", "current-schema-id": int64(1)}, expectedErr: ErrCommitFailed},
+		{name: "unknown", metadata: m, req: TableRequirement{"type": "assert-unknown"}, expectedErr: ErrBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRequirements(tt.metadata, []TableRequirement{tt.req})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("checkRequirements() err = %v, expected %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,396 @@
+package iceberg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/treeverse/lakefs/pkg/catalog"
+	"github.com/treeverse/lakefs/pkg/permissions"
+)
+
+// namespaceMarker is the object that marks a directory as a namespace and holds its properties
+const namespaceMarker = "_lakefs_iceberg_namespace.json"
+
+type createNamespaceRequest struct {
+	Namespace  []string          `json:"namespace"`
+	Properties map[string]string `json:"properties"`
+}
+
+type namespaceResponse struct {
+	Namespace  []string          `json:"namespace"`
+	Properties map[string]string `json:"properties"`
+}
+
+type updateNamespacePropertiesRequest struct {
+	Removals []string          `json:"removals"`
+	Updates  map[string]string `json:"updates"`
+}
+
+type updateNamespacePropertiesResponse struct {
+	Updated []string `json:"updated"`
+	Removed []string `json:"removed"`
+	Missing []string `json:"missing"`
+}
+
+func (n namespace) markerPath() string {
+	return n.path() + namespaceMarker
+}
+
+// namespaceProperties returns the properties of a namespace, failing with ErrNoSuchNamespace if it does not exist.
+// Repositories and refs are namespaces with no properties.
+func (h *Handler) namespaceProperties(ctx context.Context, ns namespace) (map[string]string, error) {
+	return h.namespacePropertiesAt(ctx, ns, ns.ref())
+}
+
+// namespacePropertiesAt returns the properties of a namespace as namespaceProperties does, reading them from ref
+// rather than from the ref of the namespace.
+func (h *Handler) namespacePropertiesAt(ctx context.Context, ns namespace, ref string) (map[string]string, error) {
+	switch {
+	case len(ns.levels) == 1:
+		if _, err := h.catalog.GetRepository(ctx, ns.repository()); err != nil {
+			return nil, h.namespaceError(err, ns)
+		}
+		return map[string]string{}, nil
+	case !ns.hasDirectory():
+		if _, err := h.catalog.GetCommit(ctx, ns.repository(), ns.ref()); err != nil {
+			return nil, h.namespaceError(err, ns)
+		}
+		return map[string]string{}, nil
+	}
+	data, err := h.readObject(ctx, ns.repository(), ref, ns.markerPath())
+	if err != nil {
+		return nil, h.namespaceError(err, ns)
+	}
+	properties := make(map[string]string)
+	if err := json.Unmarshal(data, &properties); err != nil {
+		return nil, fmt.Errorf("namespace %s properties: %w", ns, err)
+	}
+	return properties, nil
+}
+
+func (h *Handler) namespaceError(err error, ns namespace) error {
+	if isNotFound(err) {
+		return fmt.Errorf("%w: %s", ErrNoSuchNamespace, ns)
+	}
+	return err
+}
+
+func (h *Handler) authorizeNamespaceRead(ctx context.Context, ns namespace) error {
+	switch {
+	case len(ns.levels) == 0:
+		return h.authorize(ctx, permissions.Permission{Action: permissions.ListRepositoriesAction, Resource: permissions.All})
+	case len(ns.levels) == 1:
+		return h.authorize(ctx, permissions.Permission{Action: permissions.ReadRepositoryAction, Resource: permissions.RepoArn(ns.repository())})
+	default:
+		return h.authorize(ctx, permissions.Permission{Action: permissions.ListObjectsAction, Resource: permissions.RepoArn(ns.repository())})
+	}
+}
+
+// childNamespaces lists the namespaces directly under parent: repositories, the branches of a repository or the
+// directories with a namespace marker under a ref.
+func (h *Handler) childNamespaces(ctx context.Context, parent namespace) ([]namespace, error) {
+	var children []namespace
+	switch len(parent.levels) {
+	case 0:
+		after := ""
+		for {
+			repos, hasMore, err := h.catalog.ListRepositories(ctx, catalog.ListRepositoriesLimitMax, "", after, false)
+			if err != nil {
+				return nil, err
+			}
+			for _, repo := range repos {
+				children = append(children, parent.child(repo.Name))
+			}
+			if !hasMore || len(repos) == 0 {
+				return children, nil
+			}
+			after = repos[len(repos)-1].Name
+		}
+	case 1:
+		after := ""
+		for {
+			branches, hasMore, err := h.catalog.ListBranches(ctx, parent.repository(), "", catalog.ListBranchesLimitMax, after)
+			if err != nil {
+				return nil, h.namespaceError(err, parent)
+			}
+			for _, branch := range branches {
+				children = append(children, parent.child(branch.Name))
+			}
+			if !hasMore || len(branches) == 0 {
+				return children, nil
+			}
+			after = branches[len(branches)-1].Name
+		}
+	}
+	entries, err := h.listObjects(ctx, parent.repository(), parent.ref(), parent.path(), catalog.DefaultPathDelimiter)
+	if err != nil {
+		return nil, h.namespaceError(err, parent)
+	}
+	for _, entry := range entries {
+		if !entry.CommonLevel {
+			continue
+		}
+		child := parent.child(strings.TrimSuffix(strings.TrimPrefix(entry.Path, parent.path()), catalog.DefaultPathDelimiter))
+		exists, err := h.objectExists(ctx, child.repository(), child.ref(), child.markerPath())
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			children = append(children, child)
+		}
+	}
+	return children, nil
+}
+
+func (h *Handler) listNamespaces(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	parent, err := parseNamespace(r.URL.Query().Get("parent"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if err := h.authorizeNamespaceRead(ctx, parent); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if parent.hasDirectory() {
+		if _, err := h.namespaceProperties(ctx, parent); err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+	}
+	children, err := h.childNamespaces(ctx, parent)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	namespaces := make([][]string, 0, len(children))
+	for _, child := range children {
+		namespaces = append(namespaces, child.levels)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"namespaces": namespaces})
+}
+
+func (h *Handler) createNamespace(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req createNamespaceRequest
+	if err := readJSON(r, &req); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	ns, err := parseNamespace(strings.Join(req.Namespace, namespaceSeparator))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if !ns.hasDirectory() {
+		h.writeError(w, r, fmt.Errorf("%w: namespace %s must be under a repository and a branch", ErrBadRequest, ns))
+		return
+	}
+	if err := h.authorize(ctx,
+		permissions.Permission{Action: permissions.WriteObjectAction, Resource: permissions.ObjectArn(ns.repository(), ns.markerPath())},
+		permissions.Permission{Action: permissions.CreateCommitAction, Resource: permissions.BranchArn(ns.repository(), ns.ref())},
+	); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if err := h.requireBranch(ctx, ns); err != nil {
+		h.writeError(w, r, h.namespaceError(err, ns))
+		return
+	}
+	properties := req.Properties
+	if properties == nil {
+		properties = map[string]string{}
+	}
+	data, err := json.Marshal(properties)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	parent := namespace{levels: ns.levels[:len(ns.levels)-1]}
+	err = h.commitChanges(ctx, ns, "Iceberg: create namespace "+ns.String(), catalog.Metadata{
+		"iceberg_operation":  "create-namespace",
+		"iceberg_identifier": ns.String(),
+	}, func(head string) ([]catalog.EntryChange, error) {
+		if _, err := h.namespacePropertiesAt(ctx, parent, head); err != nil {
+			return nil, err
+		}
+		exists, err := h.objectExists(ctx, ns.repository(), head, ns.markerPath())
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, fmt.Errorf("%w: namespace %s", ErrAlreadyExists, ns)
+		}
+		entry, err := h.newObjectEntry(ctx, ns.repository(), ns.markerPath(), "application/json", data)
+		if err != nil {
+			return nil, err
+		}
+		return []catalog.EntryChange{{Path: entry.Path, Entry: entry}}, nil
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, namespaceResponse{Namespace: ns.levels, Properties: properties})
+}
+
+func (h *Handler) loadNamespace(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ns, err := namespaceParam(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if err := h.authorizeNamespaceRead(ctx, ns); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	properties, err := h.namespaceProperties(ctx, ns)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, namespaceResponse{Namespace: ns.levels, Properties: properties})
+}
+
+func (h *Handler) namespaceExists(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ns, err := namespaceParam(r)
+	if err == nil {
+		err = h.authorizeNamespaceRead(ctx, ns)
+	}
+	if err == nil {
+		_, err = h.namespaceProperties(ctx, ns)
+	}
+	if err != nil {
+		_, code := errorTypeAndCode(err)
+		w.WriteHeader(code)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) dropNamespace(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ns, err := namespaceParam(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if !ns.hasDirectory() {
+		h.writeError(w, r, fmt.Errorf("%w: cannot drop repository or ref namespace %s", ErrBadRequest, ns))
+		return
+	}
+	if err := h.authorize(ctx,
+		permissions.Permission{Action: permissions.DeleteObjectAction, Resource: permissions.ObjectArn(ns.repository(), ns.markerPath())},
+		permissions.Permission{Action: permissions.CreateCommitAction, Resource: permissions.BranchArn(ns.repository(), ns.ref())},
+	); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if err := h.requireBranch(ctx, ns); err != nil {
+		h.writeError(w, r, h.namespaceError(err, ns))
+		return
+	}
+	err = h.commitChanges(ctx, ns, "Iceberg: drop namespace "+ns.String(), catalog.Metadata{
+		"iceberg_operation":  "drop-namespace",
+		"iceberg_identifier": ns.String(),
+	}, func(head string) ([]catalog.EntryChange, error) {
+		if _, err := h.namespacePropertiesAt(ctx, ns, head); err != nil {
+			return nil, err
+		}
+		entries, _, err := h.catalog.ListEntries(ctx, ns.repository(), head, ns.path(), "", "", 2) //nolint:gomnd
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.Path != ns.markerPath() {
+				return nil, fmt.Errorf("%w: %s", ErrNamespaceNotEmpty, ns)
+			}
+		}
+		return []catalog.EntryChange{{Path: ns.markerPath()}}, nil
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) updateNamespaceProperties(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ns, err := namespaceParam(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	var req updateNamespacePropertiesRequest
+	if err := readJSON(r, &req); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if !ns.hasDirectory() {
+		h.writeError(w, r, fmt.Errorf("%w: repository and ref namespace %s have no properties", ErrBadRequest, ns))
+		return
+	}
+	if err := h.authorize(ctx,
+		permissions.Permission{Action: permissions.WriteObjectAction, Resource: permissions.ObjectArn(ns.repository(), ns.markerPath())},
+		permissions.Permission{Action: permissions.CreateCommitAction, Resource: permissions.BranchArn(ns.repository(), ns.ref())},
+	); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if err := h.requireBranch(ctx, ns); err != nil {
+		h.writeError(w, r, h.namespaceError(err, ns))
+		return
+	}
+	for _, key := range req.Removals {
+		if _, ok := req.Updates[key]; ok {
+			h.writeError(w, r, fmt.Errorf("%w: property %s is both updated and removed", ErrBadRequest, key))
+			return
+		}
+	}
+	var resp updateNamespacePropertiesResponse
+	err = h.commitChanges(ctx, ns, "Iceberg: update namespace properties "+ns.String(), catalog.Metadata{
+		"iceberg_operation":  "update-namespace-properties",
+		"iceberg_identifier": ns.String(),
+	}, func(head string) ([]catalog.EntryChange, error) {
+		properties, err := h.namespacePropertiesAt(ctx, ns, head)
+		if err != nil {
+			return nil, err
+		}
+		resp = updateNamespacePropertiesResponse{Updated: []string{}, Removed: []string{}, Missing: []string{}}
+		for _, key := range req.Removals {
+			if _, ok := properties[key]; ok {
+				delete(properties, key)
+				resp.Removed = append(resp.Removed, key)
+			} else {
+				resp.Missing = append(resp.Missing, key)
+			}
+		}
+		for key, value := range req.Updates {
+			properties[key] = value
+			resp.Updated = append(resp.Updated, key)
+		}
+		sort.Strings(resp.Updated)
+		data, err := json.Marshal(properties)
+		if err != nil {
+			return nil, err
+		}
+		entry, err := h.newObjectEntry(ctx, ns.repository(), ns.markerPath(), "application/json", data)
+		if err != nil {
+			return nil, err
+		}
+		return []catalog.EntryChange{{Path: entry.Path, Entry: entry}}, nil
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
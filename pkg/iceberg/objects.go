@@ -0,0 +1,85 @@
+package iceberg
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/catalog"
+	"github.com/treeverse/lakefs/pkg/upload"
+)
+
+// objectExists reports whether path exists on ref
+func (h *Handler) objectExists(ctx context.Context, repository, ref, path string) (bool, error) {
+	_, err := h.catalog.GetEntry(ctx, repository, ref, path, catalog.GetEntryParams{})
+	if isNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (h *Handler) readObject(ctx context.Context, repository, ref, path string) ([]byte, error) {
+	repo, err := h.catalog.GetRepository(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := h.catalog.GetEntry(ctx, repository, ref, path, catalog.GetEntryParams{})
+	if err != nil {
+		return nil, err
+	}
+	reader, err := h.adapter.Get(ctx, block.ObjectPointer{
+		StorageNamespace: repo.StorageNamespace,
+		IdentifierType:   entry.AddressType.ToIdentifierType(),
+		Identifier:       entry.PhysicalAddress,
+	}, entry.Size)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+	return io.ReadAll(reader)
+}
+
+// newObjectEntry uploads data to the storage namespace of repository and returns the entry of path that holds it.
+// The entry is not staged: it is committed together with the other changes of an operation by commitChanges.
+func (h *Handler) newObjectEntry(ctx context.Context, repository, path, contentType string, data []byte) (*catalog.DBEntry, error) {
+	repo, err := h.catalog.GetRepository(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := upload.WriteBlob(ctx, h.adapter, repo.StorageNamespace, h.pathProvider.NewPath(), bytes.NewReader(data), int64(len(data)), block.PutOpts{})
+	if err != nil {
+		return nil, err
+	}
+	entry := catalog.NewDBEntryBuilder().
+		Path(path).
+		PhysicalAddress(blob.PhysicalAddress).
+		CreationDate(time.Now()).
+		Size(blob.Size).
+		Checksum(blob.Checksum).
+		ContentType(contentType).
+		AddressType(catalog.AddressTypeRelative).
+		Build()
+	return &entry, nil
+}
+
+// listObjects lists all entries under prefix on ref.  With a delimiter it returns the common prefixes under prefix
+// as entries with CommonLevel set.
+func (h *Handler) listObjects(ctx context.Context, repository, ref, prefix, delimiter string) ([]*catalog.DBEntry, error) {
+	var (
+		res   []*catalog.DBEntry
+		after string
+	)
+	for {
+		entries, hasMore, err := h.catalog.ListEntries(ctx, repository, ref, prefix, after, delimiter, catalog.ListEntriesLimitMax)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, entries...)
+		if !hasMore || len(entries) == 0 {
+			return res, nil
+		}
+		after = entries[len(entries)-1].Path
+	}
+}
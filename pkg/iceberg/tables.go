@@ -0,0 +1,593 @@
+package iceberg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/treeverse/lakefs/pkg/catalog"
+	"github.com/treeverse/lakefs/pkg/permissions"
+)
+
+const (
+	metadataDir     = "metadata/"
+	versionHintFile = "version-hint.text"
+
+	// deleteBatchSize is the number of entries deleted by each call to DeleteEntries
+	deleteBatchSize = 1000
+)
+
+type tableIdentifier struct {
+	Namespace []string `json:"namespace"`
+	Name      string   `json:"name"`
+}
+
+type createTableRequest struct {
+	Name          string                 `json:"name"`
+	Location      string                 `json:"location"`
+	Schema        map[string]interface{} `json:"schema"`
+	PartitionSpec map[string]interface{} `json:"partition-spec"`
+	WriteOrder    map[string]interface{} `json:"write-order"`
+	StageCreate   bool                   `json:"stage-create"`
+	Properties    map[string]string      `json:"properties"`
+}
+
+type commitTableRequest struct {
+	Identifier   *tableIdentifier   `json:"identifier"`
+	Requirements []TableRequirement `json:"requirements"`
+	Updates      []TableUpdate      `json:"updates"`
+}
+
+type renameTableRequest struct {
+	Source      tableIdentifier `json:"source"`
+	Destination tableIdentifier `json:"destination"`
+}
+
+type loadTableResponse struct {
+	MetadataLocation string            `json:"metadata-location,omitempty"`
+	Metadata         Metadata          `json:"metadata"`
+	Config           map[string]string `json:"config,omitempty"`
+}
+
+// table is an Iceberg table in a namespace under a ref.  Its metadata files are metadata/vN.metadata.json and
+// metadata/version-hint.text holds the version of the current one.
+type table struct {
+	ns   namespace
+	name string
+}
+
+func newTable(ns namespace, name string) (table, error) {
+	if !ns.hasDirectory() {
+		return table{}, fmt.Errorf("%w: tables must be in a namespace under a repository and a ref, got %s", ErrBadRequest, ns)
+	}
+	if name == "" || strings.Contains(name, "/") {
+		return table{}, fmt.Errorf("%w: invalid table name %q", ErrBadRequest, name)
+	}
+	return table{ns: ns, name: name}, nil
+}
+
+func tableParam(r *http.Request) (table, error) {
+	ns, err := namespaceParam(r)
+	if err != nil {
+		return table{}, err
+	}
+	name, err := url.PathUnescape(chi.URLParam(r, "table"))
+	if err != nil {
+		return table{}, fmt.Errorf("%w: %s", ErrBadRequest, err)
+	}
+	return newTable(ns, name)
+}
+
+func (t table) repository() string {
+	return t.ns.repository()
+}
+
+func (t table) ref() string {
+	return t.ns.ref()
+}
+
+// path returns the directory of the table, with a trailing slash
+func (t table) path() string {
+	return t.ns.path() + t.name + "/"
+}
+
+func (t table) hintPath() string {
+	return t.path() + metadataDir + versionHintFile
+}
+
+func (t table) metadataPath(version int) string {
+	return t.path() + metadataDir + "v" + strconv.Itoa(version) + ".metadata.json"
+}
+
+// location returns the location of the table through the lakeFS S3 gateway
+func (t table) location() string {
+	return "s3://" + t.repository() + "/" + t.ref() + "/" + strings.TrimSuffix(t.path(), "/")
+}
+
+func (t table) metadataLocation(version int) string {
+	return "s3://" + t.repository() + "/" + t.ref() + "/" + t.metadataPath(version)
+}
+
+func (t table) String() string {
+	return t.ns.String() + "." + t.name
+}
+
+func (t table) objectPermission(action, path string) permissions.Permission {
+	return permissions.Permission{Action: action, Resource: permissions.ObjectArn(t.repository(), path)}
+}
+
+func (t table) commitPermission() permissions.Permission {
+	return permissions.Permission{Action: permissions.CreateCommitAction, Resource: permissions.BranchArn(t.repository(), t.ref())}
+}
+
+// loadMetadata returns the current metadata of the table and its version, failing with ErrNoSuchTable if the table
+// does not exist.  The location of the returned metadata is the location of the table on the ref it was loaded from.
+func (h *Handler) loadMetadata(ctx context.Context, t table) (Metadata, int, error) {
+	return h.loadMetadataAt(ctx, t, t.ref())
+}
+
+// loadMetadataAt returns the metadata of the table as loadMetadata does, reading it from ref rather than from the ref
+// of the table.
+func (h *Handler) loadMetadataAt(ctx context.Context, t table, ref string) (Metadata, int, error) {
+	hint, err := h.readObject(ctx, t.repository(), ref, t.hintPath())
+	if isNotFound(err) {
+		return nil, 0, fmt.Errorf("%w: %s", ErrNoSuchTable, t)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(string(hint)))
+	if err != nil {
+		return nil, 0, fmt.Errorf("table %s version hint: %w", t, err)
+	}
+	data, err := h.readObject(ctx, t.repository(), ref, t.metadataPath(version))
+	if err != nil {
+		return nil, 0, fmt.Errorf("table %s metadata version %d: %w", t, version, err)
+	}
+	var m Metadata
+	if err := decodeJSON(data, &m); err != nil {
+		return nil, 0, fmt.Errorf("table %s metadata version %d: %w", t, version, err)
+	}
+	m["location"] = t.location()
+	return m, version, nil
+}
+
+// metadataChanges uploads the next version of the table metadata and returns the changes that add it on top of head
+// and point the version hint at it, failing with ErrCommitFailed if head already holds that version.
+func (h *Handler) metadataChanges(ctx context.Context, t table, head string, m Metadata, version int) ([]catalog.EntryChange, error) {
+	exists, err := h.objectExists(ctx, t.repository(), head, t.metadataPath(version))
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("%w: table %s metadata version %d already exists", ErrCommitFailed, t, version)
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	metadataEntry, err := h.newObjectEntry(ctx, t.repository(), t.metadataPath(version), "application/json", data)
+	if err != nil {
+		return nil, err
+	}
+	hintEntry, err := h.newObjectEntry(ctx, t.repository(), t.hintPath(), "text/plain", []byte(strconv.Itoa(version)))
+	if err != nil {
+		return nil, err
+	}
+	return []catalog.EntryChange{
+		{Path: metadataEntry.Path, Entry: metadataEntry},
+		{Path: hintEntry.Path, Entry: hintEntry},
+	}, nil
+}
+
+// updateTable applies a table commit on top of the head of the branch of the table and commits the new metadata
+func (h *Handler) updateTable(ctx context.Context, t table, operation string, requirements []TableRequirement, updates []TableUpdate) (*loadTableResponse, error) {
+	if err := h.requireBranch(ctx, t.ns); err != nil {
+		return nil, err
+	}
+	for _, update := range updates {
+		if update["action"] == "set-location" && update["location"] != t.location() {
+			return nil, fmt.Errorf("%w: the location of table %s is determined by its namespace", ErrBadRequest, t)
+		}
+	}
+	var resp *loadTableResponse
+	err := h.commitChanges(ctx, t.ns, fmt.Sprintf("Iceberg: %s table %s", operation, t), catalog.Metadata{
+		"iceberg_operation":  operation,
+		"iceberg_identifier": t.String(),
+	}, func(head string) ([]catalog.EntryChange, error) {
+		if _, err := h.namespacePropertiesAt(ctx, t.ns, head); err != nil {
+			return nil, err
+		}
+		current, version, err := h.loadMetadataAt(ctx, t, head)
+		if err != nil && !errors.Is(err, ErrNoSuchTable) {
+			return nil, err
+		}
+		if err := checkRequirements(current, requirements); err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		m := current
+		if m == nil {
+			if !hasRequirement(requirements, "assert-create") {
+				return nil, fmt.Errorf("%w: %s", ErrNoSuchTable, t)
+			}
+			m = newMetadata(t.location(), now)
+		} else {
+			m["metadata-log"] = append(m.list("metadata-log"), map[string]interface{}{
+				"timestamp-ms":  m["last-updated-ms"],
+				"metadata-file": t.metadataLocation(version),
+			})
+		}
+		if err := applyUpdates(m, updates, now); err != nil {
+			return nil, err
+		}
+		version++
+		changes, err := h.metadataChanges(ctx, t, head, m, version)
+		if err != nil {
+			return nil, err
+		}
+		staged, err := h.stagedTableChanges(ctx, t, changes)
+		if err != nil {
+			return nil, err
+		}
+		resp = &loadTableResponse{MetadataLocation: t.metadataLocation(version), Metadata: m}
+		return append(changes, staged...), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// stagedTableChanges returns the changes staged on the branch of the table under its directory, except for the paths
+// of metadataChanges.  Engines write data files, manifests and manifest lists through the S3 gateway, so they are
+// committed together with the metadata that refers to them.
+func (h *Handler) stagedTableChanges(ctx context.Context, t table, metadataChanges []catalog.EntryChange) ([]catalog.EntryChange, error) {
+	skip := make(map[string]struct{}, len(metadataChanges))
+	for _, change := range metadataChanges {
+		skip[change.Path] = struct{}{}
+	}
+	var (
+		changes []catalog.EntryChange
+		after   string
+	)
+	for {
+		diffs, hasMore, err := h.catalog.DiffUncommitted(ctx, t.repository(), t.ref(), t.path(), "", catalog.DiffLimitMax, after)
+		if err != nil {
+			return nil, err
+		}
+		for _, diff := range diffs {
+			if _, ok := skip[diff.Path]; ok {
+				continue
+			}
+			change := catalog.EntryChange{Path: diff.Path}
+			if diff.Type != catalog.DifferenceTypeRemoved {
+				entry := diff.DBEntry
+				change.Entry = &entry
+			}
+			changes = append(changes, change)
+		}
+		if !hasMore || len(diffs) == 0 {
+			return changes, nil
+		}
+		after = diffs[len(diffs)-1].Path
+	}
+}
+
+func hasRequirement(requirements []TableRequirement, reqType string) bool {
+	for _, req := range requirements {
+		if req["type"] == reqType {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) listTables(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ns, err := namespaceParam(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if err := h.authorizeNamespaceRead(ctx, ns); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	identifiers := []tableIdentifier{}
+	if !ns.hasDirectory() {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"identifiers": identifiers})
+		return
+	}
+	if _, err := h.namespaceProperties(ctx, ns); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	entries, err := h.listObjects(ctx, ns.repository(), ns.ref(), ns.path(), catalog.DefaultPathDelimiter)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	for _, entry := range entries {
+		if !entry.CommonLevel {
+			continue
+		}
+		t := table{ns: ns, name: strings.TrimSuffix(strings.TrimPrefix(entry.Path, ns.path()), catalog.DefaultPathDelimiter)}
+		exists, err := h.objectExists(ctx, t.repository(), t.ref(), t.hintPath())
+		if err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+		if exists {
+			identifiers = append(identifiers, tableIdentifier{Namespace: ns.levels, Name: t.name})
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"identifiers": identifiers})
+}
+
+func (h *Handler) createTable(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ns, err := namespaceParam(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	var req createTableRequest
+	if err := readJSON(r, &req); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	t, err := newTable(ns, req.Name)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if req.Schema == nil {
+		h.writeError(w, r, fmt.Errorf("%w: missing schema", ErrBadRequest))
+		return
+	}
+	if req.Location != "" && strings.TrimSuffix(req.Location, "/") != t.location() {
+		h.writeError(w, r, fmt.Errorf("%w: the location of table %s is %s", ErrBadRequest, t, t.location()))
+		return
+	}
+	if err := h.authorize(ctx, t.objectPermission(permissions.WriteObjectAction, t.hintPath()), t.commitPermission()); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	updates := createTableUpdates(&req)
+	if req.StageCreate {
+		// a staged create only returns the metadata, the table is created by a commit that asserts it does not exist
+		if err := h.requireBranch(ctx, ns); err != nil {
+			h.writeError(w, r, h.namespaceError(err, ns))
+			return
+		}
+		if _, _, err := h.loadMetadata(ctx, t); err == nil {
+			h.writeError(w, r, fmt.Errorf("%w: table %s", ErrAlreadyExists, t))
+			return
+		}
+		m := newMetadata(t.location(), time.Now())
+		if err := applyUpdates(m, updates, time.Now()); err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, loadTableResponse{Metadata: m})
+		return
+	}
+	resp, err := h.updateTable(ctx, t, "create", []TableRequirement{{"type": "assert-create"}}, updates)
+	if errors.Is(err, ErrCommitFailed) {
+		err = fmt.Errorf("%w: table %s", ErrAlreadyExists, t)
+	}
+	if err != nil {
+		h.writeError(w, r, h.namespaceError(err, ns))
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) loadTable(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	t, err := tableParam(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if err := h.authorize(ctx, t.objectPermission(permissions.ReadObjectAction, t.hintPath())); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if _, err := h.namespaceProperties(ctx, t.ns); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	m, version, err := h.loadMetadata(ctx, t)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, loadTableResponse{MetadataLocation: t.metadataLocation(version), Metadata: m})
+}
+
+func (h *Handler) tableExists(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	t, err := tableParam(r)
+	if err == nil {
+		err = h.authorize(ctx, t.objectPermission(permissions.ReadObjectAction, t.hintPath()))
+	}
+	if err == nil {
+		var exists bool
+		exists, err = h.objectExists(ctx, t.repository(), t.ref(), t.hintPath())
+		if err == nil && !exists {
+			err = ErrNoSuchTable
+		}
+	}
+	if err != nil {
+		_, code := errorTypeAndCode(err)
+		w.WriteHeader(code)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) commitTable(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	t, err := tableParam(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	var req commitTableRequest
+	if err := readJSON(r, &req); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if err := h.authorize(ctx, t.objectPermission(permissions.WriteObjectAction, t.hintPath()), t.commitPermission()); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	resp, err := h.updateTable(ctx, t, "update", req.Requirements, req.Updates)
+	if err != nil {
+		h.writeError(w, r, h.namespaceError(err, t.ns))
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// deleteTableChanges returns the changes that delete the metadata of the table on ref, or all of its objects when
+// purging
+func (h *Handler) deleteTableChanges(ctx context.Context, t table, ref string, purge bool) ([]catalog.EntryChange, error) {
+	prefix := t.path()
+	if !purge {
+		prefix += metadataDir
+	}
+	entries, err := h.listObjects(ctx, t.repository(), ref, prefix, "")
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]catalog.EntryChange, 0, len(entries))
+	for _, entry := range entries {
+		changes = append(changes, catalog.EntryChange{Path: entry.Path})
+	}
+	return changes, nil
+}
+
+func (h *Handler) dropTable(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	t, err := tableParam(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	purge, _ := strconv.ParseBool(r.URL.Query().Get("purgeRequested"))
+	if err := h.authorize(ctx, t.objectPermission(permissions.DeleteObjectAction, t.path()), t.commitPermission()); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if err := h.requireBranch(ctx, t.ns); err != nil {
+		h.writeError(w, r, h.namespaceError(err, t.ns))
+		return
+	}
+	err = h.commitChanges(ctx, t.ns, "Iceberg: drop table "+t.String(), catalog.Metadata{
+		"iceberg_operation":  "drop",
+		"iceberg_identifier": t.String(),
+	}, func(head string) ([]catalog.EntryChange, error) {
+		if _, _, err := h.loadMetadataAt(ctx, t, head); err != nil {
+			return nil, err
+		}
+		return h.deleteTableChanges(ctx, t, head, purge)
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// renameTable moves the metadata of a table to another name in the same repository and ref.  Data files stay in
+// place: table metadata refers to them by their full location.
+func (h *Handler) renameTable(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req renameTableRequest
+	if err := readJSON(r, &req); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	src, err := identifierTable(req.Source)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	dst, err := identifierTable(req.Destination)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if src.repository() != dst.repository() || src.ref() != dst.ref() {
+		h.writeError(w, r, fmt.Errorf("%w: cannot rename table %s to another repository or ref", ErrBadRequest, src))
+		return
+	}
+	if err := h.authorize(ctx,
+		src.objectPermission(permissions.ReadObjectAction, src.path()),
+		src.objectPermission(permissions.DeleteObjectAction, src.path()),
+		dst.objectPermission(permissions.WriteObjectAction, dst.path()),
+		src.commitPermission(),
+	); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if err := h.requireBranch(ctx, src.ns); err != nil {
+		h.writeError(w, r, h.namespaceError(err, src.ns))
+		return
+	}
+	err = h.commitChanges(ctx, src.ns, fmt.Sprintf("Iceberg: rename table %s to %s", src, dst), catalog.Metadata{
+		"iceberg_operation":  "rename",
+		"iceberg_identifier": dst.String(),
+	}, func(head string) ([]catalog.EntryChange, error) {
+		if _, _, err := h.loadMetadataAt(ctx, src, head); err != nil {
+			return nil, err
+		}
+		if _, err := h.namespacePropertiesAt(ctx, dst.ns, head); err != nil {
+			return nil, err
+		}
+		exists, err := h.objectExists(ctx, dst.repository(), head, dst.hintPath())
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, fmt.Errorf("%w: table %s", ErrAlreadyExists, dst)
+		}
+		changes, err := h.deleteTableChanges(ctx, src, head, false)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := h.listObjects(ctx, src.repository(), head, src.path()+metadataDir, "")
+		if err != nil {
+			return nil, err
+		}
+		// the metadata objects of the destination are the objects of the source
+		for _, entry := range entries {
+			entry.Path = dst.path() + strings.TrimPrefix(entry.Path, src.path())
+			changes = append(changes, catalog.EntryChange{Path: entry.Path, Entry: entry})
+		}
+		return changes, nil
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func identifierTable(id tableIdentifier) (table, error) {
+	ns, err := parseNamespace(strings.Join(id.Namespace, namespaceSeparator))
+	if err != nil {
+		return table{}, err
+	}
+	return newTable(ns, id.Name)
+}
@@ -0,0 +1,187 @@
+package inventory_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/ingest/inventory"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// fakeAdapter serves the objects of a map by their full address
+type fakeAdapter struct {
+	block.Adapter
+	objects map[string][]byte
+}
+
+func (a *fakeAdapter) Get(_ context.Context, obj block.ObjectPointer, _ int64) (io.ReadCloser, error) {
+	data, ok := a.objects[obj.Identifier]
+	if !ok {
+		return nil, block.ErrDataNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func md5Hex(data []byte) string {
+	sum := md5.Sum(data) //nolint:gosec
+	return hex.EncodeToString(sum[:])
+}
+
+func readAll(t *testing.T, adapter block.Adapter, kind inventory.Kind, manifestURL string) ([]block.InventoryObject, error) {
+	t.Helper()
+	m, err := inventory.Load(context.Background(), adapter, kind, manifestURL)
+	if err != nil {
+		return nil, err
+	}
+	it := m.Iterator()
+	var res []block.InventoryObject
+	for it.Next() {
+		res = append(res, *it.Get())
+	}
+	return res, it.Err()
+}
+
+func TestAzureInventory(t *testing.T) {
+	lastModified := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	data := []byte(`Name,Last-Modified,Content-Length,Content-MD5,Deleted
+container/a/one,2023-01-01T10:00:00Z,1,md5one,false
+container/a/gone,2023-01-01T10:00:00Z,2,md5gone,true
+`)
+	manifest := func(count int) []byte {
+		return []byte(`{
+  "destinationContainer": "inventory",
+  "endpoint": "https://account.blob.core.windows.net",
+  "files": [{"blob": "2023/01/01/rule/1.csv"}],
+  "ruleDefinition": {"format": "csv"},
+  "summary": {"objectCount": ` + strconv.Itoa(count) + `}
+}`)
+	}
+	for _, tt := range []struct {
+		name  string
+		count int
+		err   error
+	}{
+		{name: "objects", count: 2},
+		{name: "record count mismatch", count: 3, err: inventory.ErrRecordCount},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			m := manifest(tt.count)
+			adapter := &fakeAdapter{objects: map[string][]byte{
+				"https://account.blob.core.windows.net/inventory/2023/01/01/rule/manifest.json":     m,
+				"https://account.blob.core.windows.net/inventory/2023/01/01/rule/manifest.checksum": []byte(md5Hex(m)),
+				"https://account.blob.core.windows.net/inventory/2023/01/01/rule/1.csv":             data,
+			}}
+			objects, err := readAll(t, adapter, inventory.KindAzure, "https://account.blob.core.windows.net/inventory/2023/01/01/rule/manifest.json")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("read inventory err=%v, expected %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			expected := []block.InventoryObject{
+				{Bucket: "container", Key: "a/one", Size: 1, LastModified: &lastModified, Checksum: "md5one", PhysicalAddress: "https://account.blob.core.windows.net/container/a/one"},
+			}
+			if diff := deep.Equal(objects, expected); diff != nil {
+				t.Errorf("objects diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestGCSInventory(t *testing.T) {
+	lastModified := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	data := []byte(`bucket,name,size,updated,md5Hash,timeDeleted
+bucket,a/one,1,2023-01-01T10:00:00Z,md5one,
+bucket,a/gone,2,2023-01-01T10:00:00Z,md5gone,2023-01-02T10:00:00Z
+`)
+	adapter := &fakeAdapter{objects: map[string][]byte{
+		"gs://inventory/report/manifest.json": []byte(`{
+  "records_processed": 2,
+  "shard_count": 1,
+  "report_shards_file_names": ["shard_0.csv"]
+}`),
+		"gs://inventory/report/shard_0.csv": data,
+	}}
+	objects, err := readAll(t, adapter, inventory.KindGCS, "gs://inventory/report/manifest.json")
+	if err != nil {
+		t.Fatalf("read inventory: %s", err)
+	}
+	expected := []block.InventoryObject{
+		{Bucket: "bucket", Key: "a/one", Size: 1, LastModified: &lastModified, Checksum: "md5one", PhysicalAddress: "gs://bucket/a/one"},
+	}
+	if diff := deep.Equal(objects, expected); diff != nil {
+		t.Errorf("objects diff: %s", diff)
+	}
+}
+
+// parquetRecord is a record of a GCS inventory in parquet format
+type parquetRecord struct {
+	Bucket      string  `parquet:"name=bucket, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Name        string  `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Size        int64   `parquet:"name=size, type=INT64"`
+	Updated     int64   `parquet:"name=updated, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	MD5Hash     string  `parquet:"name=md5Hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	TimeDeleted *string `parquet:"name=timeDeleted, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+}
+
+func TestGCSParquetInventory(t *testing.T) {
+	lastModified := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	deleted := "2023-01-02T10:00:00Z"
+	var buf bytes.Buffer
+	pw, err := writer.NewParquetWriterFromWriter(&buf, new(parquetRecord), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const numObjects = 2000
+	for i := 0; i < numObjects; i++ {
+		record := &parquetRecord{Bucket: "bucket", Name: fmt.Sprintf("a/%04d", i), Size: int64(i), Updated: lastModified.UnixMilli(), MD5Hash: "md5"}
+		if i%2 == 1 {
+			record.TimeDeleted = &deleted
+		}
+		if err := pw.Write(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pw.WriteStop(); err != nil {
+		t.Fatal(err)
+	}
+	adapter := &fakeAdapter{objects: map[string][]byte{
+		"gs://inventory/report/manifest.json": []byte(`{
+  "records_processed": ` + strconv.Itoa(numObjects) + `,
+  "shard_count": 1,
+  "report_shards_file_names": ["shard_0.parquet"]
+}`),
+		"gs://inventory/report/shard_0.parquet": buf.Bytes(),
+	}}
+	objects, err := readAll(t, adapter, inventory.KindGCS, "gs://inventory/report/manifest.json")
+	if err != nil {
+		t.Fatalf("read inventory: %s", err)
+	}
+	if len(objects) != numObjects/2 {
+		t.Fatalf("read %d objects, expected %d", len(objects), numObjects/2)
+	}
+	expected := block.InventoryObject{Bucket: "bucket", Key: "a/0002", Size: 2, LastModified: &lastModified, Checksum: "md5", PhysicalAddress: "gs://bucket/a/0002"}
+	if diff := deep.Equal(objects[1], expected); diff != nil {
+		t.Errorf("object diff: %s", diff)
+	}
+}
+
+func TestUnknownManifest(t *testing.T) {
+	adapter := &fakeAdapter{objects: map[string][]byte{
+		"gs://inventory/manifest.json": []byte(`{"destinationContainer": "inventory"}`),
+	}}
+	_, err := readAll(t, adapter, inventory.KindGCS, "gs://inventory/manifest.json")
+	if !errors.Is(err, inventory.ErrUnknownManifest) {
+		t.Fatalf("read inventory err=%v, expected %s", err, inventory.ErrUnknownManifest)
+	}
+}
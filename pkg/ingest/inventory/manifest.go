@@ -0,0 +1,241 @@
+// Package inventory reads the inventory reports generated by GCS Storage
+// Insights and Azure Blob Inventory through a block adapter, so that the GCS
+// and Azure adapters can generate a block.Inventory from them.  S3 Inventory
+// reports are read by the S3 adapter.
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/treeverse/lakefs/pkg/block"
+)
+
+var (
+	ErrUnknownManifest   = errors.New("unknown inventory manifest")
+	ErrUnsupportedFormat = errors.New("unsupported inventory file format")
+	ErrChecksumMismatch  = errors.New("inventory checksum mismatch")
+	ErrRecordCount       = errors.New("inventory record count mismatch")
+	ErrMissingColumn     = errors.New("required inventory column missing")
+	ErrInvalidValue      = errors.New("invalid inventory value")
+)
+
+// Kind is the object store that generated an inventory
+type Kind string
+
+const (
+	KindGCS   Kind = "gcs"
+	KindAzure Kind = "azure"
+)
+
+// FileFormat is the format of the files of an inventory
+type FileFormat string
+
+const (
+	FormatCSV     FileFormat = "csv"
+	FormatParquet FileFormat = "parquet"
+)
+
+// File is a single file of an inventory
+type File struct {
+	// Address is the full address of the file
+	Address string
+}
+
+// Manifest describes an inventory: the files that hold it and how to read
+// them.  It is the block.Inventory of the inventory.
+type Manifest struct {
+	URL    string
+	Kind   Kind
+	Format FileFormat
+	Files  []File
+	// RecordCount is the number of records in all files, or -1 if the manifest does not hold it
+	RecordCount int64
+	// endpoint is the URL of the Azure storage account of the inventory
+	endpoint string
+	ctx      context.Context
+	adapter  block.Adapter
+}
+
+// azureManifest is the manifest.json of an Azure Blob Inventory run
+type azureManifest struct {
+	DestinationContainer string `json:"destinationContainer"`
+	Endpoint             string `json:"endpoint"`
+	Files                []struct {
+		Blob string `json:"blob"`
+	} `json:"files"`
+	RuleDefinition struct {
+		Format string `json:"format"`
+	} `json:"ruleDefinition"`
+	Summary struct {
+		ObjectCount *int64 `json:"objectCount"`
+	} `json:"summary"`
+}
+
+// gcsManifest is the manifest of a GCS Storage Insights inventory report
+type gcsManifest struct {
+	RecordsProcessed      *int64   `json:"records_processed"`
+	ShardCount            int      `json:"shard_count"`
+	ReportShardsFileNames []string `json:"report_shards_file_names"`
+}
+
+// manifestFields are the fields that identify the manifest of each kind of inventory
+var manifestFields = map[Kind]string{
+	KindAzure: "destinationContainer",
+	KindGCS:   "report_shards_file_names",
+}
+
+// Load reads the manifest at manifestURL of an inventory of the given kind
+// and verifies it: the manifest checksum of Azure inventories and the shard
+// count of GCS inventories.  The files of the inventory are read through
+// adapter.
+func Load(ctx context.Context, adapter block.Adapter, kind Kind, manifestURL string) (*Manifest, error) {
+	data, err := readAll(ctx, adapter, manifestURL)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest %s: %w", manifestURL, err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", ErrUnknownManifest, manifestURL, err)
+	}
+	if fields[manifestFields[kind]] == nil {
+		return nil, fmt.Errorf("%w: %s is not a %s inventory manifest", ErrUnknownManifest, manifestURL, kind)
+	}
+	var m *Manifest
+	switch kind {
+	case KindAzure:
+		m, err = loadAzureManifest(data)
+	case KindGCS:
+		m, err = loadGCSManifest(data, manifestURL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", manifestURL, err)
+	}
+	m.URL = manifestURL
+	m.ctx = ctx
+	m.adapter = adapter
+	if m.Kind == KindAzure {
+		if err := verifyManifestChecksum(ctx, adapter, manifestURL, data); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *Manifest) Iterator() block.InventoryIterator {
+	return newIterator(m)
+}
+
+// SourceName returns an empty name: GCS and Azure manifests do not name the
+// source bucket, their records do.
+func (m *Manifest) SourceName() string {
+	return ""
+}
+
+func (m *Manifest) InventoryURL() string {
+	return m.URL
+}
+
+func loadAzureManifest(data []byte) (*Manifest, error) {
+	var am azureManifest
+	if err := json.Unmarshal(data, &am); err != nil {
+		return nil, err
+	}
+	m := &Manifest{
+		Kind:        KindAzure,
+		RecordCount: -1,
+		endpoint:    strings.TrimSuffix(am.Endpoint, "/"),
+	}
+	switch strings.ToLower(am.RuleDefinition.Format) {
+	case "csv":
+		m.Format = FormatCSV
+	case "parquet":
+		m.Format = FormatParquet
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, am.RuleDefinition.Format)
+	}
+	if am.Summary.ObjectCount != nil {
+		m.RecordCount = *am.Summary.ObjectCount
+	}
+	for _, f := range am.Files {
+		m.Files = append(m.Files, File{Address: m.endpoint + "/" + am.DestinationContainer + "/" + f.Blob})
+	}
+	return m, nil
+}
+
+func loadGCSManifest(data []byte, manifestURL string) (*Manifest, error) {
+	var gm gcsManifest
+	if err := json.Unmarshal(data, &gm); err != nil {
+		return nil, err
+	}
+	if gm.ShardCount != len(gm.ReportShardsFileNames) {
+		return nil, fmt.Errorf("%w: %d shards of %d", ErrChecksumMismatch, len(gm.ReportShardsFileNames), gm.ShardCount)
+	}
+	m := &Manifest{
+		Kind:        KindGCS,
+		RecordCount: -1,
+	}
+	if gm.RecordsProcessed != nil {
+		m.RecordCount = *gm.RecordsProcessed
+	}
+	// shards are stored next to the manifest
+	dir := manifestURL[:strings.LastIndex(manifestURL, "/")+1]
+	for _, name := range gm.ReportShardsFileNames {
+		format := FormatCSV
+		if path.Ext(name) == ".parquet" {
+			format = FormatParquet
+		}
+		if m.Format != "" && m.Format != format {
+			return nil, fmt.Errorf("%w: mixed shard formats", ErrUnsupportedFormat)
+		}
+		m.Format = format
+		m.Files = append(m.Files, File{Address: dir + path.Base(name)})
+	}
+	return m, nil
+}
+
+// verifyManifestChecksum compares the checksum of the manifest data with
+// the checksum file stored next to it, named like the manifest with a
+// ".checksum" extension.  The checksum file holds a hex encoded MD5 or
+// SHA-256 checksum.
+func verifyManifestChecksum(ctx context.Context, adapter block.Adapter, manifestURL string, data []byte) error {
+	checksumURL := strings.TrimSuffix(manifestURL, path.Ext(manifestURL)) + ".checksum"
+	checksum, err := readAll(ctx, adapter, checksumURL)
+	if err != nil {
+		return fmt.Errorf("read manifest checksum %s: %w", checksumURL, err)
+	}
+	expected := strings.ToLower(string(bytes.TrimSpace(checksum)))
+	var actual string
+	if len(expected) == sha256.Size*2 {
+		sum := sha256.Sum256(data)
+		actual = hex.EncodeToString(sum[:])
+	} else {
+		sum := md5.Sum(data) //nolint:gosec
+		actual = hex.EncodeToString(sum[:])
+	}
+	if actual != expected {
+		return fmt.Errorf("%w: manifest %s", ErrChecksumMismatch, manifestURL)
+	}
+	return nil
+}
+
+func readAll(ctx context.Context, adapter block.Adapter, address string) ([]byte, error) {
+	reader, err := adapter.Get(ctx, block.ObjectPointer{
+		Identifier:     address,
+		IdentifierType: block.IdentifierTypeFull,
+	}, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,512 @@
+package inventory
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/spf13/cast"
+	"github.com/treeverse/lakefs/pkg/block"
+	"github.com/treeverse/lakefs/pkg/cmdutils"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+const parquetBatchSize = 1024
+
+// row is a single inventory record, keyed by normalized column name
+type row map[string]interface{}
+
+// rowReader reads the rows of a single inventory file
+type rowReader interface {
+	// Next returns the next row, or io.EOF after the last one
+	Next() (row, error)
+	Close() error
+}
+
+// Iterator iterates over the objects of an inventory, file by file.  Each
+// file is downloaded to a temporary file that is removed as soon as it is
+// opened.  Objects that are not current - deleted objects and non-current
+// versions - are skipped.
+type Iterator struct {
+	manifest    *Manifest
+	fileIdx     int
+	rows        rowReader
+	value       *block.InventoryObject
+	records     int64
+	err         error
+	filesRead   *cmdutils.Progress
+	recordsRead *cmdutils.Progress
+}
+
+func newIterator(m *Manifest) *Iterator {
+	filesRead := cmdutils.NewActiveProgress("Inventory Files Read", cmdutils.Bar)
+	filesRead.SetTotal(int64(len(m.Files)))
+	recordsRead := cmdutils.NewActiveProgress("Inventory Records Read", cmdutils.Spinner)
+	if m.RecordCount >= 0 {
+		recordsRead = cmdutils.NewActiveProgress("Inventory Records Read", cmdutils.Bar)
+		recordsRead.SetTotal(m.RecordCount)
+	}
+	return &Iterator{
+		manifest:    m,
+		filesRead:   filesRead,
+		recordsRead: recordsRead,
+	}
+}
+
+func (it *Iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for {
+		if it.rows == nil {
+			if it.fileIdx >= len(it.manifest.Files) {
+				it.value = nil
+				it.filesRead.SetCompleted(true)
+				it.recordsRead.SetCompleted(true)
+				if it.manifest.RecordCount >= 0 && it.records != it.manifest.RecordCount {
+					it.err = fmt.Errorf("%w: read %d records of %d", ErrRecordCount, it.records, it.manifest.RecordCount)
+				}
+				return false
+			}
+			it.rows, it.err = it.openFile(it.manifest.Files[it.fileIdx])
+			if it.err != nil {
+				return false
+			}
+			it.fileIdx++
+		}
+		r, err := it.rows.Next()
+		if errors.Is(err, io.EOF) {
+			it.err = it.closeFile()
+			if it.err != nil {
+				return false
+			}
+			it.filesRead.Incr()
+			continue
+		}
+		if err != nil {
+			it.fail(err)
+			return false
+		}
+		it.records++
+		it.recordsRead.Incr()
+		obj, err := it.manifest.object(r)
+		if err != nil {
+			it.fail(err)
+			return false
+		}
+		if obj != nil {
+			it.value = obj
+			return true
+		}
+	}
+}
+
+// fail stops the iteration on an error reading the current file
+func (it *Iterator) fail(err error) {
+	it.err = fmt.Errorf("%s: %w", it.manifest.Files[it.fileIdx-1].Address, err)
+	_ = it.closeFile()
+}
+
+func (it *Iterator) Get() *block.InventoryObject {
+	return it.value
+}
+
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+func (it *Iterator) Progress() []*cmdutils.Progress {
+	return []*cmdutils.Progress{it.filesRead, it.recordsRead}
+}
+
+func (it *Iterator) closeFile() error {
+	if it.rows == nil {
+		return nil
+	}
+	err := it.rows.Close()
+	it.rows = nil
+	return err
+}
+
+// openFile downloads an inventory file to a temporary file and opens a
+// reader of its rows.  The temporary file is removed once opened, so that
+// it is released when the reader is closed.
+func (it *Iterator) openFile(file File) (rowReader, error) {
+	f, err := os.CreateTemp("", "inventory-*")
+	if err != nil {
+		return nil, err
+	}
+	_ = os.Remove(f.Name())
+	rows, err := it.downloadFile(f, file)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("%s: %w", file.Address, err)
+	}
+	return rows, nil
+}
+
+func (it *Iterator) downloadFile(f *os.File, file File) (rowReader, error) {
+	reader, err := it.manifest.adapter.Get(it.manifest.ctx, block.ObjectPointer{
+		Identifier:     file.Address,
+		IdentifierType: block.IdentifierTypeFull,
+	}, -1)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(f, reader)
+	_ = reader.Close()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	switch it.manifest.Format {
+	case FormatCSV:
+		return newCSVRows(f)
+	case FormatParquet:
+		return newParquetRows(f)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, it.manifest.Format)
+	}
+}
+
+// normalizeColumn maps the column names used by the different inventories
+// ("LastModifiedDate", "Last-Modified", "md5Hash") to a single form.
+func normalizeColumn(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return sb.String()
+}
+
+type csvRows struct {
+	file    *os.File
+	gz      *gzip.Reader
+	reader  *csv.Reader
+	columns []string
+}
+
+// newCSVRows reads a CSV file, optionally gzip compressed, that starts with
+// a header row.
+func newCSVRows(f *os.File) (*csvRows, error) {
+	rows := &csvRows{file: f}
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		rows.gz = gz
+		r = gz
+	}
+	rows.reader = csv.NewReader(r)
+	rows.reader.FieldsPerRecord = -1
+	header, err := rows.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return rows, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range header {
+		rows.columns = append(rows.columns, normalizeColumn(column))
+	}
+	return rows, nil
+}
+
+func (r *csvRows) Next() (row, error) {
+	record, err := r.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	res := make(row, len(record))
+	for i, v := range record {
+		if i < len(r.columns) {
+			res[r.columns[i]] = v
+		}
+	}
+	return res, nil
+}
+
+func (r *csvRows) Close() error {
+	if r.gz != nil {
+		_ = r.gz.Close()
+	}
+	return r.file.Close()
+}
+
+type parquetRows struct {
+	reader  *reader.ParquetReader
+	columns map[string]string
+	batch   []row
+	nextRow int64
+}
+
+// parquetFile is a parquet source of a temporary file that was already
+// removed: it opens the column readers of the parquet reader as independent
+// readers of the same file, rather than by its name.
+type parquetFile struct {
+	*io.SectionReader
+	file *os.File
+}
+
+func newParquetFile(f *os.File) (*parquetFile, error) {
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return &parquetFile{SectionReader: io.NewSectionReader(f, 0, stat.Size()), file: f}, nil
+}
+
+func (p *parquetFile) Open(string) (source.ParquetFile, error) {
+	// readers opened from the file share it, only the original closes it
+	return &parquetFile{SectionReader: io.NewSectionReader(p.file, 0, p.Size())}, nil
+}
+
+func (p *parquetFile) Create(string) (source.ParquetFile, error) {
+	return nil, ErrUnsupportedFormat
+}
+
+func (p *parquetFile) Write([]byte) (int, error) {
+	return 0, ErrUnsupportedFormat
+}
+
+func (p *parquetFile) Close() error {
+	if p.file == nil {
+		return nil
+	}
+	return p.file.Close()
+}
+
+func newParquetRows(f *os.File) (*parquetRows, error) {
+	pf, err := newParquetFile(f)
+	if err != nil {
+		return nil, err
+	}
+	pr, err := reader.NewParquetReader(pf, nil, 4) //nolint: gomnd
+	if err != nil {
+		return nil, err
+	}
+	// map the paths of leaf columns to their normalized names
+	columns := make(map[string]string)
+	for i, info := range pr.SchemaHandler.Infos {
+		if i == 0 || pr.SchemaHandler.SchemaElements[i].GetNumChildren() > 0 {
+			continue
+		}
+		columns[pr.SchemaHandler.IndexMap[int32(i)]] = normalizeColumn(info.ExName)
+	}
+	return &parquetRows{reader: pr, columns: columns}, nil
+}
+
+func (r *parquetRows) Next() (row, error) {
+	if len(r.batch) == 0 {
+		num := r.reader.GetNumRows() - r.nextRow
+		if num <= 0 {
+			return nil, io.EOF
+		}
+		if num > parquetBatchSize {
+			num = parquetBatchSize
+		}
+		r.nextRow += num
+		r.batch = make([]row, num)
+		for i := range r.batch {
+			r.batch[i] = make(row, len(r.columns))
+		}
+		for path, column := range r.columns {
+			values, _, _, err := r.reader.ReadColumnByPath(path, num)
+			if err != nil {
+				return nil, fmt.Errorf("read parquet column %s: %w", column, err)
+			}
+			for i, v := range values {
+				if i < len(r.batch) && v != nil {
+					r.batch[i][column] = v
+				}
+			}
+		}
+	}
+	res := r.batch[0]
+	r.batch = r.batch[1:]
+	return res, nil
+}
+
+func (r *parquetRows) Close() error {
+	r.reader.ReadStop()
+	return r.reader.PFile.Close()
+}
+
+// columns of each inventory kind, normalized
+const (
+	columnBucket           = "bucket"
+	columnName             = "name"
+	columnSize             = "size"
+	columnContentLength    = "contentlength"
+	columnLastModified     = "lastmodified"
+	columnUpdated          = "updated"
+	columnETag             = "etag"
+	columnContentMD5       = "contentmd5"
+	columnMD5Hash          = "md5hash"
+	columnDeleted          = "deleted"
+	columnIsCurrentVersion = "iscurrentversion"
+	columnTimeDeleted      = "timedeleted"
+)
+
+// object converts an inventory row to an object, or returns nil if the row
+// is not of a current object.
+func (m *Manifest) object(r row) (*block.InventoryObject, error) {
+	switch m.Kind {
+	case KindAzure:
+		return azureObject(r, m.endpoint)
+	case KindGCS:
+		return gcsObject(r)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownManifest, m.Kind)
+	}
+}
+
+func azureObject(r row, endpoint string) (*block.InventoryObject, error) {
+	if deleted, ok, err := boolColumn(r, columnDeleted); err != nil || (ok && deleted) {
+		return nil, err
+	}
+	if current, ok, err := boolColumn(r, columnIsCurrentVersion); err != nil || (ok && !current) {
+		return nil, err
+	}
+	name, err := requiredString(r, columnName)
+	if err != nil {
+		return nil, err
+	}
+	// the name of a blob in an inventory starts with its container
+	container, blob, found := strings.Cut(name, "/")
+	if !found {
+		return nil, fmt.Errorf("%w: blob name %s", ErrInvalidValue, name)
+	}
+	obj := &block.InventoryObject{Bucket: container, Key: blob}
+	checksumColumn := columnETag
+	if _, ok := value(r, columnETag); !ok {
+		checksumColumn = columnContentMD5
+	}
+	if err := setCommon(obj, r, columnContentLength, columnLastModified, checksumColumn); err != nil {
+		return nil, err
+	}
+	obj.PhysicalAddress = endpoint + "/" + name
+	return obj, nil
+}
+
+func gcsObject(r row) (*block.InventoryObject, error) {
+	if _, deleted := value(r, columnTimeDeleted); deleted {
+		return nil, nil
+	}
+	obj := &block.InventoryObject{}
+	var err error
+	if obj.Bucket, err = requiredString(r, columnBucket); err != nil {
+		return nil, err
+	}
+	if obj.Key, err = requiredString(r, columnName); err != nil {
+		return nil, err
+	}
+	checksumColumn := columnETag
+	if _, ok := value(r, columnETag); !ok {
+		checksumColumn = columnMD5Hash
+	}
+	if err := setCommon(obj, r, columnSize, columnUpdated, checksumColumn); err != nil {
+		return nil, err
+	}
+	obj.PhysicalAddress = "gs://" + obj.Bucket + "/" + obj.Key
+	return obj, nil
+}
+
+// setCommon sets the size, last modified time and checksum of obj from the
+// named columns, all optional.
+func setCommon(obj *block.InventoryObject, r row, sizeColumn, lastModifiedColumn, checksumColumn string) error {
+	if v, ok := value(r, sizeColumn); ok {
+		size, err := cast.ToInt64E(v)
+		if err != nil {
+			return fmt.Errorf("%w: %s %v", ErrInvalidValue, sizeColumn, v)
+		}
+		obj.Size = size
+	}
+	if v, ok := value(r, lastModifiedColumn); ok {
+		t, err := parseTime(v)
+		if err != nil {
+			return fmt.Errorf("%w: %s %v", ErrInvalidValue, lastModifiedColumn, v)
+		}
+		obj.LastModified = &t
+	}
+	if v, ok := value(r, checksumColumn); ok {
+		obj.Checksum = strings.Trim(cast.ToString(v), `"`)
+	}
+	return nil
+}
+
+// value returns the value of a column, or false if it is missing or empty
+func value(r row, column string) (interface{}, bool) {
+	v, ok := r[column]
+	if !ok || v == nil {
+		return nil, false
+	}
+	if s, isString := v.(string); isString && s == "" {
+		return nil, false
+	}
+	return v, true
+}
+
+func requiredString(r row, column string) (string, error) {
+	v, ok := value(r, column)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrMissingColumn, column)
+	}
+	return cast.ToString(v), nil
+}
+
+func boolColumn(r row, column string) (bool, bool, error) {
+	v, ok := value(r, column)
+	if !ok {
+		return false, false, nil
+	}
+	b, err := cast.ToBoolE(v)
+	if err != nil {
+		return false, false, fmt.Errorf("%w: %s %v", ErrInvalidValue, column, v)
+	}
+	return b, true, nil
+}
+
+// timeLayouts are the textual time formats used by inventories
+var timeLayouts = []string{time.RFC3339Nano, time.RFC1123, http.TimeFormat, "2006-01-02 15:04:05.999999999 MST"}
+
+// parseTime parses an inventory timestamp: a time, a textual time or a
+// number of milliseconds (or microseconds) since the epoch.
+func parseTime(v interface{}) (time.Time, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case string:
+		for _, layout := range timeLayouts {
+			if res, err := time.Parse(layout, t); err == nil {
+				return res, nil
+			}
+		}
+		return time.Time{}, ErrInvalidValue
+	}
+	n, err := cast.ToInt64E(v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	const maxMillis = 1e14
+	if n > maxMillis {
+		return time.UnixMicro(n), nil
+	}
+	return time.UnixMilli(n), nil
+}
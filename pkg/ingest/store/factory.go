@@ -59,14 +59,14 @@ func NewFactory(params params.AdapterConfig) *WalkerFactory {
 	return &WalkerFactory{params: params}
 }
 
-func (f *WalkerFactory) buildS3Walker(opts WalkerOptions) (*s3.Walker, error) {
+func (f *WalkerFactory) buildS3Walker(cfg params.AdapterConfig, opts WalkerOptions) (*s3.Walker, error) {
 	var sess *session.Session
-	if f.params != nil {
-		s3params, err := f.params.BlockstoreS3Params()
+	if cfg != nil {
+		s3params, err := cfg.BlockstoreS3Params()
 		if err != nil {
 			return nil, err
 		}
-		sess, err = factory.BuildS3Client(s3params.AwsConfig, s3params.WebIdentity, s3params.SkipVerifyCertificateTestOnly)
+		sess, err = factory.BuildS3Client(s3params.AwsConfigFor(opts.StorageURI), s3params.WebIdentity, s3params.SkipVerifyCertificateTestOnly)
 		if err != nil {
 			return nil, err
 		}
@@ -80,10 +80,10 @@ func (f *WalkerFactory) buildS3Walker(opts WalkerOptions) (*s3.Walker, error) {
 	return s3.NewS3Walker(sess), nil
 }
 
-func (f *WalkerFactory) buildGCSWalker(ctx context.Context) (*gs.GCSWalker, error) {
+func (f *WalkerFactory) buildGCSWalker(ctx context.Context, cfg params.AdapterConfig) (*gs.GCSWalker, error) {
 	var svc *storage.Client
-	if f.params != nil {
-		gsParams, err := f.params.BlockstoreGSParams()
+	if cfg != nil {
+		gsParams, err := cfg.BlockstoreGSParams()
 		if err != nil {
 			return nil, err
 		}
@@ -101,16 +101,16 @@ func (f *WalkerFactory) buildGCSWalker(ctx context.Context) (*gs.GCSWalker, erro
 	return gs.NewGCSWalker(svc), nil
 }
 
-func (f *WalkerFactory) buildAzureWalker(importURL *url.URL, skipOutOfOrder bool) (block.Walker, error) {
+func (f *WalkerFactory) buildAzureWalker(cfg params.AdapterConfig, importURL *url.URL, skipOutOfOrder bool) (block.Walker, error) {
 	storageAccount, err := azure.ExtractStorageAccount(importURL)
 	if err != nil {
 		return nil, err
 	}
 
 	var azureParams params.Azure
-	if f.params != nil {
+	if cfg != nil {
 		// server settings
-		azureParams, err = f.params.BlockstoreAzureParams()
+		azureParams, err = cfg.BlockstoreAzureParams()
 		if err != nil {
 			return nil, err
 		}
@@ -145,31 +145,66 @@ func isHierarchicalNamespaceEnabled(u *url.URL) bool {
 	return len(n) == importURLParts && n[1] == "adls"
 }
 
+// adapterConfigFor returns the configuration of the adapter that serves
+// storageURI: the named adapter of the same type with the longest matching
+// namespace prefix, or the default one.
+func (f *WalkerFactory) adapterConfigFor(storageURI *url.URL) (params.AdapterConfig, error) {
+	if f.params == nil {
+		return nil, nil
+	}
+	storageType, err := block.GetStorageType(storageURI)
+	if err != nil {
+		// no named adapter serves an unknown storage type
+		return f.params, nil
+	}
+	adapters, err := f.params.BlockstoreAdapters()
+	if err != nil {
+		return nil, err
+	}
+	var routes block.NamespaceRoutes[params.AdapterConfig]
+	for _, a := range adapters {
+		if a.Config.BlockstoreType() != storageType.BlockstoreType() {
+			continue
+		}
+		for _, prefix := range a.NamespacePrefixes {
+			routes.Add(prefix, a.Config)
+		}
+	}
+	if cfg, ok := routes.Route(storageURI.String()); ok {
+		return cfg, nil
+	}
+	return f.params, nil
+}
+
 func (f *WalkerFactory) GetWalker(ctx context.Context, opts WalkerOptions) (*WalkerWrapper, error) {
 	uri, err := url.Parse(opts.StorageURI)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse storage URI %s: %w", uri, err)
 	}
 
+	cfg, err := f.adapterConfigFor(uri)
+	if err != nil {
+		return nil, err
+	}
 	var walker block.Walker
 	switch uri.Scheme {
 	case "s3":
-		walker, err = f.buildS3Walker(opts)
+		walker, err = f.buildS3Walker(cfg, opts)
 		if err != nil {
 			return nil, fmt.Errorf("creating s3 walker: %w", err)
 		}
 	case "gs":
-		walker, err = f.buildGCSWalker(ctx)
+		walker, err = f.buildGCSWalker(ctx, cfg)
 		if err != nil {
 			return nil, fmt.Errorf("creating gs walker: %w", err)
 		}
 	case "http", "https":
-		walker, err = f.buildAzureWalker(uri, opts.SkipOutOfOrder)
+		walker, err = f.buildAzureWalker(cfg, uri, opts.SkipOutOfOrder)
 		if err != nil {
 			return nil, fmt.Errorf("creating Azure walker: %w", err)
 		}
 	case "local":
-		walker, err = f.buildLocalWalker()
+		walker, err = f.buildLocalWalker(cfg)
 		if err != nil {
 			return nil, fmt.Errorf("creating local walker: %w", err)
 		}
@@ -179,14 +214,14 @@ func (f *WalkerFactory) GetWalker(ctx context.Context, opts WalkerOptions) (*Wal
 	return NewWrapper(walker, uri), nil
 }
 
-func (f *WalkerFactory) buildLocalWalker() (*local.Walker, error) {
+func (f *WalkerFactory) buildLocalWalker(cfg params.AdapterConfig) (*local.Walker, error) {
 	var (
 		localParams params.Local
 		err         error
 	)
 
-	if f.params != nil {
-		localParams, err = f.params.BlockstoreLocalParams()
+	if cfg != nil {
+		localParams, err = cfg.BlockstoreLocalParams()
 		if err != nil {
 			return nil, err
 		}
@@ -2,9 +2,13 @@ package local
 
 import (
 	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
 	"fmt"
+	"io"
 	"io/fs"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -188,12 +192,35 @@ func Undo(c Changes) Changes {
 // is an immutable set so any changes found resulted from changes in the local directory
 // left is an object channel which contains results from a remote source. rightPath is the local directory to diff with
 func DiffLocalWithHead(left <-chan apigen.ObjectStats, rightPath string) (Changes, error) {
-	// left should be the base commit
+	return DiffLocalWithRemote(left, rightPath, DiffOptions{})
+}
+
+// DiffOptions control how DiffLocalWithRemote and DiffRemotes compare objects
+type DiffOptions struct {
+	// Filter selects the paths to compare, all paths are compared when it is nil
+	Filter *PathFilter
+	// Checksum compares files of the same size by checksum rather than by modification time
+	Checksum bool
+}
+
+// DiffLocalWithRemote checks changes between a local directory and remote objects, as changes to apply to the remote
+// objects to make them the same as the local directory.
+// left is an object channel which contains results from a remote source. rightPath is the local directory to diff with
+func DiffLocalWithRemote(left <-chan apigen.ObjectStats, rightPath string, opts DiffOptions) (Changes, error) {
 	changes := make([]*Change, 0)
 	var (
 		currentRemoteFile apigen.ObjectStats
 		hasMore           bool
 	)
+	// nextRemoteFile reads the next remote object selected by the filter
+	nextRemoteFile := func() (apigen.ObjectStats, bool) {
+		for o := range left {
+			if opts.Filter.Match(o.Path) {
+				return o, true
+			}
+		}
+		return apigen.ObjectStats{}, false
+	}
 	err := filepath.Walk(rightPath, func(path string, info fs.FileInfo, err error) error {
 		if info.IsDir() || diffShouldIgnore(info.Name()) {
 			return nil
@@ -201,12 +228,13 @@ func DiffLocalWithHead(left <-chan apigen.ObjectStats, rightPath string) (Change
 		localPath := strings.TrimPrefix(path, rightPath)
 		localPath = strings.TrimPrefix(localPath, string(filepath.Separator))
 		localPath = filepath.ToSlash(localPath) // normalize to use "/" always
+		if !opts.Filter.Match(localPath) {
+			return nil
+		}
 
-		localBytes := info.Size()
-		localMtime := info.ModTime().Unix()
 		for {
 			if currentRemoteFile.Path == "" {
-				if currentRemoteFile, hasMore = <-left; !hasMore {
+				if currentRemoteFile, hasMore = nextRemoteFile(); !hasMore {
 					// nothing left on the left side, we definitely added stuff!
 					changes = append(changes, &Change{ChangeSourceLocal, localPath, ChangeTypeAdded})
 					break
@@ -217,11 +245,11 @@ func DiffLocalWithHead(left <-chan apigen.ObjectStats, rightPath string) (Change
 				changes = append(changes, &Change{ChangeSourceLocal, currentRemoteFile.Path, ChangeTypeRemoved})
 				currentRemoteFile.Path = ""
 			case currentRemoteFile.Path == localPath:
-				remoteMtime, err := getMtimeFromStats(currentRemoteFile)
+				modified, err := localFileModified(path, info, currentRemoteFile, opts.Checksum)
 				if err != nil {
 					return err
 				}
-				if localBytes != swag.Int64Value(currentRemoteFile.SizeBytes) || localMtime != remoteMtime {
+				if modified {
 					// we made a change!
 					changes = append(changes, &Change{ChangeSourceLocal, localPath, ChangeTypeModified})
 				}
@@ -242,12 +270,79 @@ func DiffLocalWithHead(left <-chan apigen.ObjectStats, rightPath string) (Change
 	if currentRemoteFile.Path != "" {
 		changes = append(changes, &Change{ChangeSourceLocal, currentRemoteFile.Path, ChangeTypeRemoved})
 	}
-	for currentRemoteFile = range left {
+	for currentRemoteFile, hasMore = nextRemoteFile(); hasMore; currentRemoteFile, hasMore = nextRemoteFile() {
 		changes = append(changes, &Change{ChangeSourceLocal, currentRemoteFile.Path, ChangeTypeRemoved})
 	}
 	return changes, nil
 }
 
+// localFileModified compares a local file with the remote object at the same path
+func localFileModified(path string, info fs.FileInfo, remote apigen.ObjectStats, checksum bool) (bool, error) {
+	if info.Size() != swag.Int64Value(remote.SizeBytes) {
+		return true, nil
+	}
+	if checksum {
+		localChecksum, err := fileChecksum(path)
+		if err != nil {
+			return false, err
+		}
+		return localChecksum != remote.Checksum, nil
+	}
+	remoteMtime, err := getMtimeFromStats(remote)
+	if err != nil {
+		return false, err
+	}
+	return info.ModTime().Unix() != remoteMtime, nil
+}
+
+// fileChecksum returns the checksum lakeFS computes for an object uploaded with the content of the file at path.
+// Objects uploaded in parts have a different checksum.
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	h := md5.New() //nolint:gosec
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// DiffRemotes checks changes between the objects of two remote sources, as changes to apply to the right objects to
+// make them the same as the left objects.  Objects of the same size are compared by checksum.
+func DiffRemotes(left, right <-chan apigen.ObjectStats, opts DiffOptions) Changes {
+	next := func(objects <-chan apigen.ObjectStats) (apigen.ObjectStats, bool) {
+		for o := range objects {
+			if opts.Filter.Match(o.Path) {
+				return o, true
+			}
+		}
+		return apigen.ObjectStats{}, false
+	}
+	changes := make([]*Change, 0)
+	leftObject, hasLeft := next(left)
+	rightObject, hasRight := next(right)
+	for hasLeft || hasRight {
+		switch {
+		case !hasRight || (hasLeft && leftObject.Path < rightObject.Path):
+			changes = append(changes, &Change{ChangeSourceRemote, leftObject.Path, ChangeTypeAdded})
+			leftObject, hasLeft = next(left)
+		case !hasLeft || rightObject.Path < leftObject.Path:
+			changes = append(changes, &Change{ChangeSourceRemote, rightObject.Path, ChangeTypeRemoved})
+			rightObject, hasRight = next(right)
+		default:
+			if swag.Int64Value(leftObject.SizeBytes) != swag.Int64Value(rightObject.SizeBytes) || leftObject.Checksum != rightObject.Checksum {
+				changes = append(changes, &Change{ChangeSourceRemote, leftObject.Path, ChangeTypeModified})
+			}
+			leftObject, hasLeft = next(left)
+			rightObject, hasRight = next(right)
+		}
+	}
+	return changes
+}
+
 // ListRemote - Lists objects from a remote uri and inserts them into the objects channel
 func ListRemote(ctx context.Context, client apigen.ClientWithResponsesInterface, loc *uri.URI, objects chan<- apigen.ObjectStats) error {
 	hasMore := true
@@ -136,6 +136,117 @@ func TestDiffLocal(t *testing.T) {
 	}
 }
 
+func TestDiffLocalWithRemote(t *testing.T) {
+	const localPath = "testdata/localdiff/t1"
+	cases := []struct {
+		Name       string
+		Include    []string
+		Exclude    []string
+		Checksum   bool
+		RemoteList []apigen.ObjectStats
+		Expected   []*local.Change
+	}{
+		{
+			Name:     "checksum_same",
+			Checksum: true,
+			RemoteList: []apigen.ObjectStats{
+				{Path: "sub/f.txt", SizeBytes: swag.Int64(3), Mtime: 1690957665, Checksum: "acbd18db4cc2f85cedef654fccc4a4d8"},
+				{Path: "sub/folder/f.txt", SizeBytes: swag.Int64(6), Mtime: 1690957665, Checksum: "a7b4042faa80dd6f8916917061c85885"},
+			},
+			Expected: []*local.Change{},
+		},
+		{
+			Name:     "checksum_modified",
+			Checksum: true,
+			RemoteList: []apigen.ObjectStats{
+				{Path: "sub/f.txt", SizeBytes: swag.Int64(3), Mtime: diffTestCorrectTime, Checksum: "acbd18db4cc2f85cedef654fccc4a4d8"},
+				{Path: "sub/folder/f.txt", SizeBytes: swag.Int64(6), Mtime: diffTestCorrectTime, Checksum: "0123456789abcdef0123456789abcdef"},
+			},
+			Expected: []*local.Change{
+				{Path: "sub/folder/f.txt", Type: local.ChangeTypeModified},
+			},
+		},
+		{
+			Name:    "include",
+			Include: []string{"sub/folder/**"},
+			RemoteList: []apigen.ObjectStats{
+				{Path: "sub/g.txt", SizeBytes: swag.Int64(3), Mtime: diffTestCorrectTime},
+				{Path: "sub/folder/g.txt", SizeBytes: swag.Int64(3), Mtime: diffTestCorrectTime},
+			},
+			Expected: []*local.Change{
+				{Path: "sub/folder/f.txt", Type: local.ChangeTypeAdded},
+				{Path: "sub/folder/g.txt", Type: local.ChangeTypeRemoved},
+			},
+		},
+		{
+			Name:    "exclude",
+			Exclude: []string{"sub/*.txt"},
+			RemoteList: []apigen.ObjectStats{
+				{Path: "sub/g.txt", SizeBytes: swag.Int64(3), Mtime: diffTestCorrectTime},
+				{Path: "tub/g.txt", SizeBytes: swag.Int64(3), Mtime: diffTestCorrectTime},
+			},
+			Expected: []*local.Change{
+				{Path: "sub/folder/f.txt", Type: local.ChangeTypeAdded},
+				{Path: "tub/g.txt", Type: local.ChangeTypeRemoved},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			fixTime(t, localPath)
+			filter, err := local.NewPathFilter(tt.Include, tt.Exclude)
+			require.NoError(t, err)
+			lc := make(chan apigen.ObjectStats, len(tt.RemoteList))
+			makeChan(lc, tt.RemoteList)
+			changes, err := local.DiffLocalWithRemote(lc, localPath, local.DiffOptions{
+				Filter:   filter,
+				Checksum: tt.Checksum,
+			})
+			require.NoError(t, err)
+			require.Len(t, changes, len(tt.Expected), "changes: %v", changes)
+			for i, c := range changes {
+				require.Equal(t, tt.Expected[i].Path, c.Path, "wrong path")
+				require.Equal(t, tt.Expected[i].Type, c.Type, "wrong type")
+			}
+		})
+	}
+}
+
+func TestDiffRemotes(t *testing.T) {
+	left := []apigen.ObjectStats{
+		{Path: "a", SizeBytes: swag.Int64(1), Checksum: "1"},
+		{Path: "b", SizeBytes: swag.Int64(1), Checksum: "1"},
+		{Path: "c", SizeBytes: swag.Int64(1), Checksum: "1"},
+		{Path: "d.tmp", SizeBytes: swag.Int64(1), Checksum: "1"},
+		{Path: "e", SizeBytes: swag.Int64(1), Checksum: "1"},
+	}
+	right := []apigen.ObjectStats{
+		{Path: "b", SizeBytes: swag.Int64(1), Checksum: "1"},
+		{Path: "c", SizeBytes: swag.Int64(1), Checksum: "2"},
+		{Path: "c2", SizeBytes: swag.Int64(1), Checksum: "1"},
+		{Path: "e", SizeBytes: swag.Int64(2), Checksum: "1"},
+		{Path: "f.tmp", SizeBytes: swag.Int64(1), Checksum: "1"},
+		{Path: "g", SizeBytes: swag.Int64(1), Checksum: "1"},
+	}
+	filter, err := local.NewPathFilter(nil, []string{"*.tmp"})
+	require.NoError(t, err)
+	lc := make(chan apigen.ObjectStats, len(left))
+	makeChan(lc, left)
+	rc := make(chan apigen.ObjectStats, len(right))
+	makeChan(rc, right)
+
+	changes := local.DiffRemotes(lc, rc, local.DiffOptions{Filter: filter})
+	expected := []*local.Change{
+		{Source: local.ChangeSourceRemote, Path: "a", Type: local.ChangeTypeAdded},
+		{Source: local.ChangeSourceRemote, Path: "c", Type: local.ChangeTypeModified},
+		{Source: local.ChangeSourceRemote, Path: "c2", Type: local.ChangeTypeRemoved},
+		{Source: local.ChangeSourceRemote, Path: "e", Type: local.ChangeTypeModified},
+		{Source: local.ChangeSourceRemote, Path: "g", Type: local.ChangeTypeRemoved},
+	}
+	require.Equal(t, local.Changes(expected), changes)
+}
+
 func makeChan[T any](c chan<- T, l []T) {
 	for _, o := range l {
 		c <- o
@@ -0,0 +1,64 @@
+package local
+
+import (
+	"fmt"
+
+	"github.com/gobwas/glob"
+	"github.com/treeverse/lakefs/pkg/uri"
+)
+
+// PathFilter selects paths by glob patterns.  A path is selected if it matches any of the include patterns, or there
+// are none, and none of the exclude patterns.  Patterns match paths relative to the synced directory or prefix: '*'
+// does not match the path separator and '**' does.
+type PathFilter struct {
+	include []glob.Glob
+	exclude []glob.Glob
+}
+
+func NewPathFilter(include, exclude []string) (*PathFilter, error) {
+	var (
+		f   PathFilter
+		err error
+	)
+	f.include, err = compilePatterns(include)
+	if err != nil {
+		return nil, err
+	}
+	f.exclude, err = compilePatterns(exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func compilePatterns(patterns []string) ([]glob.Glob, error) {
+	res := make([]glob.Glob, 0, len(patterns))
+	for _, pattern := range patterns {
+		g, err := glob.Compile(pattern, []rune(uri.PathSeparator)[0])
+		if err != nil {
+			return nil, fmt.Errorf("pattern %s: %w", pattern, err)
+		}
+		res = append(res, g)
+	}
+	return res, nil
+}
+
+// Match reports whether the filter selects path.  A nil filter selects all paths.
+func (f *PathFilter) Match(path string) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.include) > 0 && !matchAny(f.include, path) {
+		return false
+	}
+	return !matchAny(f.exclude, path)
+}
+
+func matchAny(globs []glob.Glob, path string) bool {
+	for _, g := range globs {
+		if g.Match(path) {
+			return true
+		}
+	}
+	return false
+}
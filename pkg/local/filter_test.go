@@ -0,0 +1,45 @@
+package local_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/local"
+)
+
+func TestPathFilter(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Include  []string
+		Exclude  []string
+		Path     string
+		Expected bool
+	}{
+		{Name: "no_patterns", Path: "a/b.csv", Expected: true},
+		{Name: "include_match", Include: []string{"a/*.csv"}, Path: "a/b.csv", Expected: true},
+		{Name: "include_no_match", Include: []string{"a/*.csv"}, Path: "a/b.json", Expected: false},
+		{Name: "include_star_no_separator", Include: []string{"*.csv"}, Path: "a/b.csv", Expected: false},
+		{Name: "include_super_star", Include: []string{"**.csv"}, Path: "a/b.csv", Expected: true},
+		{Name: "exclude_match", Exclude: []string{"**/_SUCCESS"}, Path: "a/_SUCCESS", Expected: false},
+		{Name: "exclude_no_match", Exclude: []string{"**/_SUCCESS"}, Path: "a/b.csv", Expected: true},
+		{Name: "include_and_exclude", Include: []string{"a/**"}, Exclude: []string{"a/tmp/**"}, Path: "a/tmp/b.csv", Expected: false},
+		{Name: "any_include", Include: []string{"b/**", "a/**"}, Path: "a/b.csv", Expected: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			f, err := local.NewPathFilter(tt.Include, tt.Exclude)
+			require.NoError(t, err)
+			require.Equal(t, tt.Expected, f.Match(tt.Path))
+		})
+	}
+}
+
+func TestPathFilter_Nil(t *testing.T) {
+	var f *local.PathFilter
+	require.True(t, f.Match("a/b.csv"))
+}
+
+func TestPathFilter_InvalidPattern(t *testing.T) {
+	_, err := local.NewPathFilter([]string{"a/[b"}, nil)
+	require.Error(t, err)
+}
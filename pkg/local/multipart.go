@@ -0,0 +1,257 @@
+package local
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/treeverse/lakefs/pkg/api/apigen"
+	"github.com/treeverse/lakefs/pkg/api/helpers"
+	"github.com/treeverse/lakefs/pkg/uri"
+)
+
+const (
+	// DefaultMultipartPartSize is the size of the parts of resumable uploads, and the size from which files are
+	// uploaded that way.
+	DefaultMultipartPartSize = 64 * 1024 * 1024
+
+	// maxMultipartParts is the number of parts an upload may have: larger files are uploaded in larger parts.
+	maxMultipartParts = 10000
+
+	multipartStateFileMode = 0o600
+)
+
+// MultipartUploadState is the state of a resumable upload of a file, saved after each uploaded part.
+type MultipartUploadState struct {
+	// Path is the destination path of the upload
+	Path string `json:"path"`
+	// Size and Mtime are those of the uploaded file: an upload of a file that changed is not resumed
+	Size            int64  `json:"size"`
+	Mtime           int64  `json:"mtime"`
+	PartSize        int64  `json:"part_size"`
+	UploadID        string `json:"upload_id"`
+	PhysicalAddress string `json:"physical_address"`
+	// Parts holds the ETag of each uploaded part by its number
+	Parts map[int]string `json:"parts"`
+}
+
+// MultipartStateStore keeps the state of resumable uploads in a directory, a file per destination object.
+type MultipartStateStore struct {
+	dir string
+}
+
+func NewMultipartStateStore(dir string) *MultipartStateStore {
+	return &MultipartStateStore{dir: dir}
+}
+
+func (m *MultipartStateStore) filename(remote *uri.URI, path string) string {
+	h := sha256.Sum256([]byte(remote.Repository + "\x00" + remote.Ref + "\x00" + path))
+	return filepath.Join(m.dir, hex.EncodeToString(h[:])+".json")
+}
+
+// Load returns the state of the upload to path on remote, or nil if there is none.
+func (m *MultipartStateStore) Load(remote *uri.URI, path string) (*MultipartUploadState, error) {
+	data, err := os.ReadFile(m.filename(remote, path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state MultipartUploadState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("upload state of %s: %w", path, err)
+	}
+	if state.Path != path {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// Save replaces the state of the upload atomically, so an interrupted save leaves the previous state.
+func (m *MultipartStateStore) Save(remote *uri.URI, state *MultipartUploadState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, DefaultDirectoryMask); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(m.dir, "upload-*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), multipartStateFileMode)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), m.filename(remote, state.Path))
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
+
+// Delete removes the state of the upload to path on remote.
+func (m *MultipartStateStore) Delete(remote *uri.URI, path string) error {
+	err := os.Remove(m.filename(remote, path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// multipartPartSize returns the size of the parts of an upload of size bytes
+func (s *SyncManager) multipartPartSize(size int64) int64 {
+	partSize := s.partSize
+	if minPartSize := (size + maxMultipartParts - 1) / maxMultipartParts; partSize < minPartSize {
+		partSize = minPartSize
+	}
+	return partSize
+}
+
+// uploadMultipart uploads f to dest in parts, resuming an upload interrupted by an earlier run.  The state of the
+// upload is saved after each part.  It is kept if the upload fails, unless the upload can no longer be completed.
+func (s *SyncManager) uploadMultipart(ctx context.Context, remote *uri.URI, dest string, f *os.File, fileStat os.FileInfo, b *ProgressUpdater, metadata map[string]string) error {
+	size := fileStat.Size()
+	partSize := s.multipartPartSize(size)
+	state, err := s.resumeMultipart(ctx, remote, dest, size, fileStat.ModTime().Unix(), partSize)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		resp, err := s.client.CreateMultipartUploadWithResponse(ctx, remote.Repository, remote.Ref, &apigen.CreateMultipartUploadParams{
+			Path: dest,
+		})
+		if err != nil {
+			return err
+		}
+		if resp.JSON201 == nil {
+			return helpers.ResponseAsError(resp)
+		}
+		state = &MultipartUploadState{
+			Path:            dest,
+			Size:            size,
+			Mtime:           fileStat.ModTime().Unix(),
+			PartSize:        partSize,
+			UploadID:        resp.JSON201.UploadId,
+			PhysicalAddress: resp.JSON201.PhysicalAddress,
+			Parts:           make(map[int]string),
+		}
+		if err := s.multipartStates.Save(remote, state); err != nil {
+			return err
+		}
+	}
+
+	numParts := int((size + partSize - 1) / partSize)
+	for partNumber := 1; partNumber <= numParts; partNumber++ {
+		offset := int64(partNumber-1) * partSize
+		length := partSize
+		if offset+length > size {
+			length = size - offset
+		}
+		if _, ok := state.Parts[partNumber]; ok {
+			b.Increment(length)
+			continue
+		}
+		resp, err := s.client.UploadMultipartPartWithBodyWithResponse(ctx, remote.Repository, remote.Ref, state.UploadID, partNumber,
+			&apigen.UploadMultipartPartParams{
+				Path:            dest,
+				PhysicalAddress: state.PhysicalAddress,
+			}, "application/octet-stream", b.PartReader(io.NewSectionReader(f, offset, length)),
+			func(_ context.Context, req *http.Request) error {
+				req.ContentLength = length
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+		if resp.JSON200 == nil {
+			return helpers.ResponseAsError(resp)
+		}
+		state.Parts[partNumber] = resp.JSON200.Etag
+		if err := s.multipartStates.Save(remote, state); err != nil {
+			return err
+		}
+	}
+
+	parts := make([]apigen.MultipartUploadPart, 0, len(state.Parts))
+	for partNumber, etag := range state.Parts {
+		parts = append(parts, apigen.MultipartUploadPart{PartNumber: partNumber, Etag: etag})
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].PartNumber < parts[j].PartNumber
+	})
+	resp, err := s.client.CompleteMultipartUploadWithResponse(ctx, remote.Repository, remote.Ref, state.UploadID,
+		&apigen.CompleteMultipartUploadParams{
+			Path:            dest,
+			PhysicalAddress: state.PhysicalAddress,
+		}, apigen.CompleteMultipartUploadJSONRequestBody{
+			Parts:        parts,
+			UserMetadata: &apigen.MultipartUploadCompletion_UserMetadata{AdditionalProperties: metadata},
+		})
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() == http.StatusBadRequest {
+		// the physical address expired or the parts were lost: start over on the next run
+		_ = s.multipartStates.Delete(remote, dest)
+	}
+	if resp.JSON200 == nil {
+		return helpers.ResponseAsError(resp)
+	}
+	return s.multipartStates.Delete(remote, dest)
+}
+
+// resumeMultipart returns the saved state of an upload of the file to dest, holding only the parts the server still
+// has.  It returns nil, after aborting the saved upload, if the file changed or the upload can no longer be resumed.
+func (s *SyncManager) resumeMultipart(ctx context.Context, remote *uri.URI, dest string, size, mtime, partSize int64) (*MultipartUploadState, error) {
+	state, err := s.multipartStates.Load(remote, dest)
+	if err != nil || state == nil {
+		return nil, err
+	}
+	if state.Size == size && state.Mtime == mtime && state.PartSize == partSize {
+		resp, err := s.client.ListMultipartUploadPartsWithResponse(ctx, remote.Repository, remote.Ref, state.UploadID, &apigen.ListMultipartUploadPartsParams{
+			Path:            dest,
+			PhysicalAddress: state.PhysicalAddress,
+		})
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case resp.JSON200 != nil:
+			listed := make(map[int]string, len(resp.JSON200.Parts))
+			for _, part := range resp.JSON200.Parts {
+				listed[part.PartNumber] = part.Etag
+			}
+			for partNumber, etag := range state.Parts {
+				if listed[partNumber] != etag {
+					delete(state.Parts, partNumber)
+				}
+			}
+			return state, nil
+		case resp.StatusCode() == http.StatusBadRequest:
+			// the blockstore cannot list parts, trust the saved ones
+			return state, nil
+		}
+	}
+	_, _ = s.client.AbortMultipartUploadWithResponse(ctx, remote.Repository, remote.Ref, state.UploadID, &apigen.AbortMultipartUploadParams{
+		Path:            dest,
+		PhysicalAddress: state.PhysicalAddress,
+	})
+	return nil, s.multipartStates.Delete(remote, dest)
+}
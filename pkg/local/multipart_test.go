@@ -0,0 +1,204 @@
+package local_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/api/apigen"
+	"github.com/treeverse/lakefs/pkg/local"
+	"github.com/treeverse/lakefs/pkg/uri"
+)
+
+func TestMultipartStateStore(t *testing.T) {
+	store := local.NewMultipartStateStore(filepath.Join(t.TempDir(), "state"))
+	remote := &uri.URI{Repository: repo, Ref: ref}
+
+	state, err := store.Load(remote, uPath)
+	require.NoError(t, err)
+	require.Nil(t, state)
+
+	saved := &local.MultipartUploadState{
+		Path:            uPath,
+		Size:            10,
+		Mtime:           1234,
+		PartSize:        4,
+		UploadID:        "upload",
+		PhysicalAddress: "data/address",
+		Parts:           map[int]string{1: "etag1"},
+	}
+	require.NoError(t, store.Save(remote, saved))
+	state, err = store.Load(remote, uPath)
+	require.NoError(t, err)
+	require.Equal(t, saved, state)
+
+	// the state belongs to a single ref
+	state, err = store.Load(&uri.URI{Repository: repo, Ref: head}, uPath)
+	require.NoError(t, err)
+	require.Nil(t, state)
+
+	require.NoError(t, store.Delete(remote, uPath))
+	require.NoError(t, store.Delete(remote, uPath))
+	state, err = store.Load(remote, uPath)
+	require.NoError(t, err)
+	require.Nil(t, state)
+}
+
+// multipartServer serves the multipart upload API of a single upload that already holds listedParts
+type multipartServer struct {
+	mu            sync.Mutex
+	listedParts   map[int]string
+	uploadedParts map[int]string
+	completed     []apigen.MultipartUploadPart
+	created       bool
+	aborted       bool
+}
+
+func (m *multipartServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	const prefix = "/api/v1/repositories/" + repo + "/branches/" + ref + "/staging/multipart"
+	if !strings.HasPrefix(r.URL.Path, prefix) || r.URL.Query().Get("path") != uPath {
+		http.NotFound(w, r)
+		return
+	}
+	segments := strings.Split(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodPost:
+		m.created = true
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(apigen.MultipartUpload{UploadId: "new-upload", PhysicalAddress: "data/new"})
+	case len(segments) == 2 && r.Method == http.MethodGet:
+		parts := make([]apigen.MultipartUploadPart, 0, len(m.listedParts))
+		for partNumber, etag := range m.listedParts {
+			parts = append(parts, apigen.MultipartUploadPart{PartNumber: partNumber, Etag: etag})
+		}
+		_ = json.NewEncoder(w).Encode(apigen.MultipartUploadPartList{Parts: parts})
+	case len(segments) == 2 && r.Method == http.MethodPut:
+		var completion apigen.MultipartUploadCompletion
+		_ = json.NewDecoder(r.Body).Decode(&completion)
+		m.completed = completion.Parts
+		_ = json.NewEncoder(w).Encode(apigen.ObjectStats{Path: uPath})
+	case len(segments) == 2 && r.Method == http.MethodDelete:
+		m.aborted = true
+		w.WriteHeader(http.StatusNoContent)
+	case len(segments) == 4 && segments[2] == "parts" && r.Method == http.MethodPut:
+		partNumber, _ := strconv.Atoi(segments[3])
+		data, _ := io.ReadAll(r.Body)
+		etag := fmt.Sprintf("etag-%d-%s", partNumber, data)
+		m.uploadedParts[partNumber] = etag
+		_ = json.NewEncoder(w).Encode(apigen.MultipartUploadPart{PartNumber: partNumber, Etag: etag})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestSyncManager_ResumeMultipartUpload(t *testing.T) {
+	const partSize = 4
+	rootPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(rootPath, uPath), []byte("0123456789"), 0o644))
+	fileStat, err := os.Stat(filepath.Join(rootPath, uPath))
+	require.NoError(t, err)
+	remote := &uri.URI{Repository: repo, Ref: ref}
+
+	cases := []struct {
+		name         string
+		savedState   *local.MultipartUploadState
+		listedParts  map[int]string
+		expectCreate bool
+		expectAbort  bool
+		expectUpload []int
+	}{
+		{
+			name:         "no saved upload",
+			expectCreate: true,
+			expectUpload: []int{1, 2, 3},
+		},
+		{
+			name: "resume",
+			savedState: &local.MultipartUploadState{
+				Path:            uPath,
+				Size:            fileStat.Size(),
+				Mtime:           fileStat.ModTime().Unix(),
+				PartSize:        partSize,
+				UploadID:        "saved-upload",
+				PhysicalAddress: "data/saved",
+				Parts:           map[int]string{1: "saved-1", 2: "lost-2"},
+			},
+			listedParts:  map[int]string{1: "saved-1"},
+			expectUpload: []int{2, 3},
+		},
+		{
+			name: "file changed",
+			savedState: &local.MultipartUploadState{
+				Path:            uPath,
+				Size:            fileStat.Size() + 1,
+				Mtime:           fileStat.ModTime().Unix(),
+				PartSize:        partSize,
+				UploadID:        "saved-upload",
+				PhysicalAddress: "data/saved",
+				Parts:           map[int]string{1: "saved-1"},
+			},
+			listedParts:  map[int]string{1: "saved-1"},
+			expectCreate: true,
+			expectAbort:  true,
+			expectUpload: []int{1, 2, 3},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			store := local.NewMultipartStateStore(t.TempDir())
+			if tt.savedState != nil {
+				require.NoError(t, store.Save(remote, tt.savedState))
+			}
+			handler := &multipartServer{listedParts: tt.listedParts, uploadedParts: make(map[int]string)}
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			client, err := apigen.NewClientWithResponses(server.URL + "/api/v1")
+			require.NoError(t, err)
+
+			changes := make(chan *local.Change, 1)
+			changes <- &local.Change{Source: local.ChangeSourceLocal, Path: uPath, Type: local.ChangeTypeAdded}
+			close(changes)
+			s := local.NewSyncManager(context.Background(), client, 1, false).WithResumableUploads(store, partSize)
+			require.NoError(t, s.Apply(rootPath, remote, changes))
+
+			require.Equal(t, tt.expectCreate, handler.created)
+			require.Equal(t, tt.expectAbort, handler.aborted)
+			uploaded := make([]int, 0, len(handler.uploadedParts))
+			for partNumber := 1; partNumber <= 3; partNumber++ {
+				if _, ok := handler.uploadedParts[partNumber]; ok {
+					uploaded = append(uploaded, partNumber)
+				}
+			}
+			require.Equal(t, tt.expectUpload, uploaded)
+
+			// the completion holds every part, uploaded now or before
+			require.Len(t, handler.completed, 3)
+			for i, part := range handler.completed {
+				require.Equal(t, i+1, part.PartNumber)
+				etag, ok := handler.uploadedParts[part.PartNumber]
+				if !ok {
+					etag = tt.listedParts[part.PartNumber]
+				}
+				require.Equal(t, etag, part.Etag)
+			}
+			require.Equal(t, "etag-3-89", handler.completed[2].Etag)
+
+			state, err := store.Load(remote, uPath)
+			require.NoError(t, err)
+			require.Nil(t, state, "state of a completed upload")
+		})
+	}
+}
@@ -18,13 +18,17 @@ const (
 type ProgressUpdaterReader struct {
 	r io.Reader
 	t *progress.Tracker
+	// part is set when r is one part of the transfer, which does not end with it
+	part bool
 }
 
 func (pu *ProgressUpdaterReader) Read(p []byte) (n int, err error) {
 	n, err = pu.r.Read(p)
 	pu.t.Increment(int64(n))
 	if err == io.EOF {
-		pu.t.MarkAsDone()
+		if !pu.part {
+			pu.t.MarkAsDone()
+		}
 	} else if err != nil {
 		pu.t.IncrementWithError(int64(n))
 	}
@@ -42,6 +46,20 @@ func (p *ProgressUpdater) Reader(reader io.Reader) io.Reader {
 	}
 }
 
+// PartReader is like Reader for reading one part of the transfer: the transfer is not done when reader ends.
+func (p *ProgressUpdater) PartReader(reader io.Reader) io.Reader {
+	return &ProgressUpdaterReader{
+		r:    reader,
+		t:    p.t,
+		part: true,
+	}
+}
+
+// Increment reports n bytes transferred without reading them, as for parts transferred by an earlier run.
+func (p *ProgressUpdater) Increment(n int64) {
+	p.t.Increment(n)
+}
+
 func (p *ProgressUpdater) Done() {
 	p.t.MarkAsDone()
 }
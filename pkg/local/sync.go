@@ -44,6 +44,7 @@ func getMtimeFromStats(stats apigen.ObjectStats) (int64, error) {
 type Tasks struct {
 	Downloaded uint64
 	Uploaded   uint64
+	Copied     uint64
 	Removed    uint64
 }
 
@@ -55,6 +56,9 @@ type SyncManager struct {
 	maxParallelism int
 	presign        bool
 	tasks          Tasks
+	// multipartStates is set to upload files of at least partSize bytes in resumable multipart uploads
+	multipartStates *MultipartStateStore
+	partSize        int64
 }
 
 func NewSyncManager(ctx context.Context, client *apigen.ClientWithResponses, maxParallelism int, presign bool) *SyncManager {
@@ -68,9 +72,60 @@ func NewSyncManager(ctx context.Context, client *apigen.ClientWithResponses, max
 	}
 }
 
+// WithResumableUploads makes uploads of files of at least partSize bytes multipart uploads in parts of partSize
+// bytes, whose state is kept in states: an interrupted upload resumes from its last uploaded part.  Parts are
+// uploaded through the lakeFS server, also when uploads are pre-signed.
+func (s *SyncManager) WithResumableUploads(states *MultipartStateStore, partSize int64) *SyncManager {
+	s.multipartStates = states
+	s.partSize = partSize
+	return s
+}
+
 // Sync - sync changes between remote and local directory given the Changes channel.
 // For each change, will apply download, upload or delete according to the change type and change source
 func (s *SyncManager) Sync(rootPath string, remote *uri.URI, changeSet <-chan *Change) error {
+	if err := s.Apply(rootPath, remote, changeSet); err != nil {
+		return err
+	}
+	_, err := fileutil.PruneEmptyDirectories(rootPath)
+	return err
+}
+
+// Apply - like Sync, but leaves directories that become empty in place
+func (s *SyncManager) Apply(rootPath string, remote *uri.URI, changeSet <-chan *Change) error {
+	return s.run(changeSet, func(ctx context.Context, change *Change) error {
+		return s.apply(ctx, rootPath, remote, change)
+	})
+}
+
+// CopyRemote - sync changes from source to destination, both remote, given the Changes channel.
+// Added and modified objects are copied on the server from source to destination, removed objects are deleted from
+// destination.
+func (s *SyncManager) CopyRemote(source, destination *uri.URI, changeSet <-chan *Change) error {
+	return s.run(changeSet, func(ctx context.Context, change *Change) (err error) {
+		switch change.Type {
+		case ChangeTypeAdded, ChangeTypeModified:
+			err = s.copyRemote(ctx, source, destination, change)
+			if err != nil {
+				err = fmt.Errorf("copy %s failed: %w", change.Path, err)
+			}
+			return err
+		case ChangeTypeRemoved:
+			err = s.deleteRemote(ctx, destination, change)
+			if err != nil {
+				err = fmt.Errorf("delete remote %s failed: %w", change.Path, err)
+			}
+			return err
+		case ChangeTypeConflict:
+			return ErrConflict
+		default:
+			panic("invalid change type")
+		}
+	})
+}
+
+// run applies each change of changeSet with up to maxParallelism concurrent workers
+func (s *SyncManager) run(changeSet <-chan *Change, apply func(ctx context.Context, change *Change) error) error {
 	s.progressBar.Start()
 	defer s.progressBar.Stop()
 
@@ -82,15 +137,10 @@ func (s *SyncManager) Sync(rootPath string, remote *uri.URI, changeSet <-chan *C
 			return err
 		}
 		wg.Go(func() error {
-			return s.apply(ctx, rootPath, remote, c)
+			return apply(ctx, c)
 		})
 	}
-
-	if err := wg.Wait(); err != nil {
-		return err
-	}
-	_, err := fileutil.PruneEmptyDirectories(rootPath)
-	return err
+	return wg.Wait()
 }
 
 func (s *SyncManager) apply(ctx context.Context, rootPath string, remote *uri.URI, change *Change) (err error) {
@@ -276,6 +326,10 @@ func (s *SyncManager) upload(ctx context.Context, rootPath string, remote *uri.U
 	metadata := map[string]string{
 		ClientMtimeMetadataKey: strconv.FormatInt(fileStat.ModTime().Unix(), 10),
 	}
+	if s.multipartStates != nil && fileStat.Size() >= s.partSize {
+		err = s.uploadMultipart(ctx, remote, dest, f, fileStat, b, metadata)
+		return err
+	}
 	reader := fileWrapper{
 		file:   f,
 		reader: b.Reader(f),
@@ -334,6 +388,32 @@ func (s *SyncManager) deleteRemote(ctx context.Context, remote *uri.URI, change
 	return
 }
 
+func (s *SyncManager) copyRemote(ctx context.Context, source, destination *uri.URI, change *Change) (err error) {
+	b := s.progressBar.AddSpinner(fmt.Sprintf("copy %s", change.Path))
+	defer func() {
+		if err != nil {
+			b.Error()
+		} else {
+			atomic.AddUint64(&s.tasks.Copied, 1)
+			b.Done()
+		}
+	}()
+	resp, err := s.client.CopyObjectWithResponse(ctx, destination.Repository, destination.Ref, &apigen.CopyObjectParams{
+		DestPath: filepath.ToSlash(filepath.Join(destination.GetPath(), change.Path)),
+	}, apigen.CopyObjectJSONRequestBody{
+		SrcPath:       filepath.ToSlash(filepath.Join(source.GetPath(), change.Path)),
+		SrcRef:        swag.String(source.Ref),
+		SrcRepository: swag.String(source.Repository),
+	})
+	if err != nil {
+		return
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return fmt.Errorf("could not copy object: HTTP %d: %w", resp.StatusCode(), helpers.ErrRequestFailed)
+	}
+	return
+}
+
 func (s *SyncManager) Summary() Tasks {
 	return s.tasks
 }
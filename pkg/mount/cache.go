@@ -0,0 +1,73 @@
+package mount
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const cacheDirMode = 0o700
+
+// blockCache keeps blocks of object data as files of a local directory.  Blocks are keyed by the physical address and
+// the checksum of their object, so a cache directory can be shared by mounts of different refs: an object is only
+// the same object if it is stored at the same address, checksums of different objects may be equal.
+type blockCache struct {
+	dir string
+}
+
+func newBlockCache(dir string) *blockCache {
+	return &blockCache{dir: dir}
+}
+
+func blockKey(physicalAddress, checksum string, size, blockSize, index int64) string {
+	h := sha256.New()
+	for _, s := range []string{physicalAddress, checksum, strconv.FormatInt(size, 10), strconv.FormatInt(blockSize, 10), strconv.FormatInt(index, 10)} {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *blockCache) path(key string) string {
+	// spread blocks over subdirectories to keep directories small
+	return filepath.Join(c.dir, key[:2], key)
+}
+
+func (c *blockCache) get(key string) ([]byte, bool) {
+	if c.dir == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// put caches data under key.  Failing to cache is not an error, the block is read again the next time.
+func (c *blockCache) put(key string, data []byte) {
+	if c.dir == "" {
+		return
+	}
+	blockPath := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(blockPath), cacheDirMode); err != nil {
+		return
+	}
+	// write to a temporary file and rename it, so readers never see a partial block
+	f, err := os.CreateTemp(filepath.Dir(blockPath), key+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), blockPath)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+}
@@ -0,0 +1,492 @@
+// Package mount exposes a lakeFS ref as a read-only filesystem.  FS implements io/fs on top of the lakeFS API, and
+// Mount serves it through FUSE.
+package mount
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/treeverse/lakefs/pkg/api/apigen"
+	"github.com/treeverse/lakefs/pkg/api/apiutil"
+	"github.com/treeverse/lakefs/pkg/api/helpers"
+	"github.com/treeverse/lakefs/pkg/uri"
+)
+
+const (
+	DefaultBlockSize = 4 * 1024 * 1024
+
+	listObjectsAmount = 1000
+	fileMode          = 0o444
+	dirMode           = 0o555
+)
+
+var (
+	ErrReadFailed   = errors.New("read failed")
+	ErrNotSupported = errors.New("mount is not supported")
+)
+
+// Server serves a mounted FS
+type Server interface {
+	// Wait returns when the FS is unmounted
+	Wait()
+	Unmount() error
+}
+
+// Client is the part of the lakeFS API that FS uses
+type Client interface {
+	ListObjectsWithResponse(ctx context.Context, repository string, ref string, params *apigen.ListObjectsParams, reqEditors ...apigen.RequestEditorFn) (*apigen.ListObjectsResponse, error)
+	StatObjectWithResponse(ctx context.Context, repository string, ref string, params *apigen.StatObjectParams, reqEditors ...apigen.RequestEditorFn) (*apigen.StatObjectResponse, error)
+	GetObject(ctx context.Context, repository string, ref string, params *apigen.GetObjectParams, reqEditors ...apigen.RequestEditorFn) (*http.Response, error)
+}
+
+type Options struct {
+	// Presign reads object data through pre-signed URLs, directly from the object store
+	Presign bool
+	// HTTPClient reads pre-signed URLs, http.DefaultClient if nil
+	HTTPClient *http.Client
+	// BlockSize is the size of the blocks in which object data is read and cached, DefaultBlockSize if zero
+	BlockSize int64
+	// CacheDir is the directory of the block cache, blocks are not cached if empty
+	CacheDir string
+}
+
+// FS is a read-only filesystem of the objects of a lakeFS ref.  Directories are the prefixes of object paths up to
+// each "/".  The ref should be a commit ID: listings and attributes are cached, as if the ref never changes.
+type FS struct {
+	ctx        context.Context
+	client     Client
+	repository string
+	ref        string
+	opts       Options
+	cache      *blockCache
+
+	mu    sync.Mutex
+	stats map[string]*fileInfo
+	dirs  map[string][]fs.DirEntry
+}
+
+func NewFS(ctx context.Context, client Client, repository, ref string, opts Options) *FS {
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	if opts.BlockSize <= 0 {
+		opts.BlockSize = DefaultBlockSize
+	}
+	return &FS{
+		ctx:        ctx,
+		client:     client,
+		repository: repository,
+		ref:        ref,
+		opts:       opts,
+		cache:      newBlockCache(opts.CacheDir),
+		stats:      make(map[string]*fileInfo),
+		dirs:       make(map[string][]fs.DirEntry),
+	}
+}
+
+// fileInfo describes a file or a directory of FS, as both fs.FileInfo and fs.DirEntry
+type fileInfo struct {
+	name            string
+	size            int64
+	mtime           time.Time
+	dir             bool
+	checksum        string
+	physicalAddress string
+}
+
+func (i *fileInfo) Name() string       { return i.name }
+func (i *fileInfo) Size() int64        { return i.size }
+func (i *fileInfo) ModTime() time.Time { return i.mtime }
+func (i *fileInfo) IsDir() bool        { return i.dir }
+func (i *fileInfo) Sys() any           { return nil }
+func (i *fileInfo) Type() fs.FileMode  { return i.Mode().Type() }
+
+func (i *fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | dirMode
+	}
+	return fileMode
+}
+
+func (i *fileInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+func newObjectInfo(stats apigen.ObjectStats) *fileInfo {
+	return &fileInfo{
+		name:            path.Base(stats.Path),
+		size:            swag.Int64Value(stats.SizeBytes),
+		mtime:           time.Unix(stats.Mtime, 0),
+		checksum:        stats.Checksum,
+		physicalAddress: stats.PhysicalAddress,
+	}
+}
+
+func newDirInfo(name string) *fileInfo {
+	return &fileInfo{
+		name: path.Base(name),
+		dir:  true,
+	}
+}
+
+// objectPrefix returns the prefix of the objects under the directory name
+func objectPrefix(name string) string {
+	if name == "." {
+		return ""
+	}
+	return name + uri.PathSeparator
+}
+
+func (f *FS) Open(name string) (fs.File, error) {
+	return f.open(f.ctx, name)
+}
+
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	info, err := f.stat(f.ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return f.readDir(f.ctx, name)
+}
+
+func (f *FS) open(ctx context.Context, name string) (fs.File, error) {
+	info, err := f.stat(ctx, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errors.Unwrap(err)}
+	}
+	if info.dir {
+		return &dir{fsys: f, name: name, info: info}, nil
+	}
+	return &file{fsys: f, name: name, info: info}, nil
+}
+
+func (f *FS) stat(ctx context.Context, name string) (*fileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return newDirInfo(name), nil
+	}
+	f.mu.Lock()
+	info, ok := f.stats[name]
+	f.mu.Unlock()
+	if ok {
+		if info == nil {
+			return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+		}
+		return info, nil
+	}
+
+	info, err := f.statObject(ctx, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	f.mu.Lock()
+	f.stats[name] = info
+	f.mu.Unlock()
+	if info == nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return info, nil
+}
+
+// statObject returns the info of the object at name, or of the directory name if there are objects under it, or nil
+// if there are neither
+func (f *FS) statObject(ctx context.Context, name string) (*fileInfo, error) {
+	resp, err := f.client.StatObjectWithResponse(ctx, f.repository, f.ref, &apigen.StatObjectParams{Path: name})
+	if err != nil {
+		return nil, err
+	}
+	if resp.JSON200 != nil {
+		return newObjectInfo(*resp.JSON200), nil
+	}
+	if resp.StatusCode() != http.StatusNotFound {
+		return nil, helpers.ResponseAsError(resp)
+	}
+	prefix := apigen.PaginationPrefix(objectPrefix(name))
+	listResp, err := f.client.ListObjectsWithResponse(ctx, f.repository, f.ref, &apigen.ListObjectsParams{
+		Prefix: &prefix,
+		Amount: apiutil.Ptr(apigen.PaginationAmount(1)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if listResp.JSON200 == nil {
+		return nil, helpers.ResponseAsError(listResp)
+	}
+	if len(listResp.JSON200.Results) == 0 {
+		return nil, nil
+	}
+	return newDirInfo(name), nil
+}
+
+func (f *FS) readDir(ctx context.Context, name string) ([]fs.DirEntry, error) {
+	info, err := f.stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	f.mu.Lock()
+	entries, ok := f.dirs[name]
+	f.mu.Unlock()
+	if ok {
+		// callers may modify the entries they get
+		return append([]fs.DirEntry(nil), entries...), nil
+	}
+
+	entries, err = f.listDir(ctx, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	f.mu.Lock()
+	f.dirs[name] = entries
+	for _, entry := range entries {
+		f.stats[path.Join(name, entry.Name())] = entry.(*fileInfo)
+	}
+	f.mu.Unlock()
+	return append([]fs.DirEntry(nil), entries...), nil
+}
+
+// listDir lists the objects and the directories directly under the directory name, sorted by name.  A directory hides
+// an object of the same name.
+func (f *FS) listDir(ctx context.Context, name string) ([]fs.DirEntry, error) {
+	prefix := objectPrefix(name)
+	delimiter := apigen.PaginationDelimiter(uri.PathSeparator)
+	entries := make(map[string]*fileInfo)
+	var after string
+	for {
+		resp, err := f.client.ListObjectsWithResponse(ctx, f.repository, f.ref, &apigen.ListObjectsParams{
+			Prefix:    (*apigen.PaginationPrefix)(&prefix),
+			Delimiter: &delimiter,
+			After:     (*apigen.PaginationAfter)(&after),
+			Amount:    apiutil.Ptr(apigen.PaginationAmount(listObjectsAmount)),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if resp.JSON200 == nil {
+			return nil, helpers.ResponseAsError(resp)
+		}
+		for _, result := range resp.JSON200.Results {
+			entryName := strings.TrimPrefix(result.Path, prefix)
+			if strings.HasSuffix(entryName, uri.PathSeparator) {
+				entryName = strings.TrimSuffix(entryName, uri.PathSeparator)
+				if entryName != "" && !strings.Contains(entryName, uri.PathSeparator) {
+					entries[entryName] = newDirInfo(entryName)
+				}
+				continue
+			}
+			if entryName == "" || strings.Contains(entryName, uri.PathSeparator) {
+				continue
+			}
+			if _, ok := entries[entryName]; !ok {
+				entries[entryName] = newObjectInfo(result)
+			}
+		}
+		if !resp.JSON200.Pagination.HasMore {
+			break
+		}
+		after = resp.JSON200.Pagination.NextOffset
+	}
+	res := make([]fs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		res = append(res, entry)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name() < res[j].Name()
+	})
+	return res, nil
+}
+
+// readAt reads the data of the file name, described by info, at offset off into p
+func (f *FS) readAt(ctx context.Context, name string, info *fileInfo, p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	n := 0
+	for n < len(p) && off < info.size {
+		index := off / f.opts.BlockSize
+		data, err := f.readBlock(ctx, name, info, index)
+		if err != nil {
+			return n, &fs.PathError{Op: "read", Path: name, Err: err}
+		}
+		copied := copy(p[n:], data[off-index*f.opts.BlockSize:])
+		n += copied
+		off += int64(copied)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// readBlock returns block index of the file name, from the cache or from lakeFS
+func (f *FS) readBlock(ctx context.Context, name string, info *fileInfo, index int64) ([]byte, error) {
+	key := blockKey(info.physicalAddress, info.checksum, info.size, f.opts.BlockSize, index)
+	if data, ok := f.cache.get(key); ok {
+		return data, nil
+	}
+	start := index * f.opts.BlockSize
+	end := start + f.opts.BlockSize
+	if end > info.size {
+		end = info.size
+	}
+	data, err := f.readRange(ctx, name, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != end-start {
+		return nil, fmt.Errorf("read %d bytes of %d at %d: %w", len(data), end-start, start, ErrReadFailed)
+	}
+	f.cache.put(key, data)
+	return data, nil
+}
+
+// readRange reads the bytes [start, end) of the object at name
+func (f *FS) readRange(ctx context.Context, name string, start, end int64) ([]byte, error) {
+	rangeHeader := fmt.Sprintf("bytes=%d-%d", start, end-1)
+	var resp *http.Response
+	if f.opts.Presign {
+		statResp, err := f.client.StatObjectWithResponse(ctx, f.repository, f.ref, &apigen.StatObjectParams{
+			Path:    name,
+			Presign: swag.Bool(true),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if statResp.JSON200 == nil {
+			return nil, helpers.ResponseAsError(statResp)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, statResp.JSON200.PhysicalAddress, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Range", rangeHeader)
+		resp, err = f.opts.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		resp, err = f.client.GetObject(ctx, f.repository, f.ref, &apigen.GetObjectParams{
+			Path:  name,
+			Range: swag.String(rangeHeader),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+	case resp.StatusCode == http.StatusOK && start == 0:
+		// the whole object, of which the range is a prefix
+	default:
+		return nil, fmt.Errorf("range %s: HTTP %d: %w", rangeHeader, resp.StatusCode, ErrReadFailed)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, end-start))
+}
+
+// file is an open file of FS
+type file struct {
+	fsys   *FS
+	name   string
+	info   *fileInfo
+	mu     sync.Mutex
+	offset int64
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *file) Close() error               { return nil }
+
+func (f *file) Read(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.offset >= f.info.size {
+		return 0, io.EOF
+	}
+	n, err := f.fsys.readAt(f.fsys.ctx, f.name, f.info, p, f.offset)
+	f.offset += int64(n)
+	if errors.Is(err, io.EOF) && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (f *file) ReadAt(p []byte, off int64) (int, error) {
+	return f.fsys.readAt(f.fsys.ctx, f.name, f.info, p, off)
+}
+
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.info.size
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// dir is an open directory of FS
+type dir struct {
+	fsys    *FS
+	name    string
+	info    *fileInfo
+	entries []fs.DirEntry
+	read    bool
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dir) Close() error               { return nil }
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		entries, err := d.fsys.readDir(d.fsys.ctx, d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries = entries
+		d.read = true
+	}
+	if n <= 0 {
+		res := d.entries
+		d.entries = nil
+		return res, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	res := d.entries[:n]
+	d.entries = d.entries[n:]
+	return res, nil
+}
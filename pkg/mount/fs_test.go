@@ -0,0 +1,282 @@
+package mount_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/api/apigen"
+	"github.com/treeverse/lakefs/pkg/mount"
+)
+
+const (
+	testRepository = "repo"
+	testRef        = "c0ffee"
+	testMtime      = 1700000000
+	testBlockSize  = 1024
+)
+
+// fakeClient serves objects from memory, as the lakeFS API of a single ref
+type fakeClient struct {
+	objects map[string]string
+	// checksums replaces the checksums of objects
+	checksums map[string]string
+	// presignURL is the base URL of pre-signed object addresses
+	presignURL string
+	gets       int64
+}
+
+func newFakeClient(objects map[string]string) *fakeClient {
+	return &fakeClient{objects: objects}
+}
+
+func checksum(content string) string {
+	h := md5.Sum([]byte(content)) //nolint:gosec
+	return hex.EncodeToString(h[:])
+}
+
+func httpResponse(statusCode int) *http.Response {
+	return &http.Response{StatusCode: statusCode, Status: http.StatusText(statusCode)}
+}
+
+func (c *fakeClient) stats(path string) apigen.ObjectStats {
+	content := c.objects[path]
+	sum, ok := c.checksums[path]
+	if !ok {
+		sum = checksum(content)
+	}
+	return apigen.ObjectStats{
+		Path:            path,
+		PathType:        "object",
+		PhysicalAddress: "mem://storage/" + path,
+		SizeBytes:       swag.Int64(int64(len(content))),
+		Mtime:           testMtime,
+		Checksum:        sum,
+	}
+}
+
+func (c *fakeClient) ListObjectsWithResponse(_ context.Context, repository string, ref string, params *apigen.ListObjectsParams, _ ...apigen.RequestEditorFn) (*apigen.ListObjectsResponse, error) {
+	if repository != testRepository || ref != testRef {
+		return &apigen.ListObjectsResponse{HTTPResponse: httpResponse(http.StatusNotFound)}, nil
+	}
+	prefix := string(swag.StringValue((*string)(params.Prefix)))
+	delimiter := string(swag.StringValue((*string)(params.Delimiter)))
+	after := string(swag.StringValue((*string)(params.After)))
+	amount := 1000
+	if params.Amount != nil {
+		amount = int(*params.Amount)
+	}
+	paths := make([]string, 0, len(c.objects))
+	for p := range c.objects {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	list := &apigen.ObjectStatsList{}
+	for _, p := range paths {
+		if !strings.HasPrefix(p, prefix) || p <= after {
+			continue
+		}
+		stats := c.stats(p)
+		if delimiter != "" {
+			if i := strings.Index(p[len(prefix):], delimiter); i >= 0 {
+				commonPrefix := p[:len(prefix)+i+len(delimiter)]
+				if commonPrefix <= after || (len(list.Results) > 0 && list.Results[len(list.Results)-1].Path == commonPrefix) {
+					continue
+				}
+				stats = apigen.ObjectStats{Path: commonPrefix, PathType: "common_prefix"}
+			}
+		}
+		if len(list.Results) == amount {
+			list.Pagination.HasMore = true
+			list.Pagination.NextOffset = list.Results[len(list.Results)-1].Path
+			break
+		}
+		list.Results = append(list.Results, stats)
+	}
+	return &apigen.ListObjectsResponse{HTTPResponse: httpResponse(http.StatusOK), JSON200: list}, nil
+}
+
+func (c *fakeClient) StatObjectWithResponse(_ context.Context, repository string, ref string, params *apigen.StatObjectParams, _ ...apigen.RequestEditorFn) (*apigen.StatObjectResponse, error) {
+	if _, ok := c.objects[params.Path]; !ok || repository != testRepository || ref != testRef {
+		return &apigen.StatObjectResponse{HTTPResponse: httpResponse(http.StatusNotFound)}, nil
+	}
+	stats := c.stats(params.Path)
+	if swag.BoolValue(params.Presign) {
+		stats.PhysicalAddress = c.presignURL + "/" + params.Path
+	}
+	return &apigen.StatObjectResponse{HTTPResponse: httpResponse(http.StatusOK), JSON200: &stats}, nil
+}
+
+func (c *fakeClient) GetObject(_ context.Context, repository string, ref string, params *apigen.GetObjectParams, _ ...apigen.RequestEditorFn) (*http.Response, error) {
+	atomic.AddInt64(&c.gets, 1)
+	content, ok := c.objects[params.Path]
+	if !ok || repository != testRepository || ref != testRef {
+		resp := httpResponse(http.StatusNotFound)
+		resp.Body = io.NopCloser(strings.NewReader(""))
+		return resp, nil
+	}
+	var start, end int
+	_, err := fmt.Sscanf(swag.StringValue(params.Range), "bytes=%d-%d", &start, &end)
+	if err != nil {
+		return nil, err
+	}
+	resp := httpResponse(http.StatusPartialContent)
+	resp.Body = io.NopCloser(strings.NewReader(content[start : end+1]))
+	return resp, nil
+}
+
+func testObjects() map[string]string {
+	var large strings.Builder
+	for i := 0; large.Len() < 5*testBlockSize+100; i++ {
+		large.WriteString(strconv.Itoa(i))
+		large.WriteByte('\n')
+	}
+	return map[string]string{
+		"a.txt":             "hello",
+		"dir/b.csv":         "x,y\n1,2\n",
+		"dir/sub/large.txt": large.String(),
+		"dir/sub/empty":     "",
+		"marker/":           "",
+		"z":                 "last",
+	}
+}
+
+func TestFS(t *testing.T) {
+	client := newFakeClient(testObjects())
+	fsys := mount.NewFS(context.Background(), client, testRepository, testRef, mount.Options{BlockSize: testBlockSize})
+	err := fstest.TestFS(fsys, "a.txt", "dir/b.csv", "dir/sub/large.txt", "dir/sub/empty", "marker", "z")
+	require.NoError(t, err)
+
+	info, err := fs.Stat(fsys, "dir/sub/large.txt")
+	require.NoError(t, err)
+	require.Equal(t, int64(len(client.objects["dir/sub/large.txt"])), info.Size())
+	require.Equal(t, time.Unix(testMtime, 0), info.ModTime())
+	require.Equal(t, fs.FileMode(0o444), info.Mode())
+
+	_, err = fs.Stat(fsys, "dir/missing")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	entries, err := fs.ReadDir(fsys, "marker")
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestFS_ReadAt(t *testing.T) {
+	client := newFakeClient(testObjects())
+	content := client.objects["dir/sub/large.txt"]
+	fsys := mount.NewFS(context.Background(), client, testRepository, testRef, mount.Options{BlockSize: testBlockSize})
+	f, err := fsys.Open("dir/sub/large.txt")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	r, ok := f.(io.ReaderAt)
+	require.True(t, ok, "file does not implement io.ReaderAt")
+
+	cases := []struct {
+		name   string
+		offset int64
+		length int
+	}{
+		{name: "within_block", offset: 10, length: 100},
+		{name: "across_blocks", offset: testBlockSize - 10, length: 2*testBlockSize + 20},
+		{name: "to_end", offset: int64(len(content)) - 50, length: 50},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			p := make([]byte, tt.length)
+			n, err := r.ReadAt(p, tt.offset)
+			require.NoError(t, err)
+			require.Equal(t, tt.length, n)
+			require.Equal(t, content[tt.offset:tt.offset+int64(tt.length)], string(p))
+		})
+	}
+
+	t.Run("past_end", func(t *testing.T) {
+		p := make([]byte, 100)
+		n, err := r.ReadAt(p, int64(len(content))-10)
+		require.ErrorIs(t, err, io.EOF)
+		require.Equal(t, 10, n)
+		require.Equal(t, content[len(content)-10:], string(p[:n]))
+	})
+}
+
+func TestFS_BlockCache(t *testing.T) {
+	client := newFakeClient(testObjects())
+	cacheDir := t.TempDir()
+	opts := mount.Options{BlockSize: testBlockSize, CacheDir: cacheDir}
+	fsys := mount.NewFS(context.Background(), client, testRepository, testRef, opts)
+
+	content, err := fs.ReadFile(fsys, "dir/sub/large.txt")
+	require.NoError(t, err)
+	require.Equal(t, client.objects["dir/sub/large.txt"], string(content))
+	gets := atomic.LoadInt64(&client.gets)
+	require.Equal(t, int64(6), gets, "one read per block")
+
+	// blocks are read from the cache, also by another mount
+	for _, fsys := range []fs.FS{fsys, mount.NewFS(context.Background(), client, testRepository, testRef, opts)} {
+		content, err = fs.ReadFile(fsys, "dir/sub/large.txt")
+		require.NoError(t, err)
+		require.Equal(t, client.objects["dir/sub/large.txt"], string(content))
+		require.Equal(t, gets, atomic.LoadInt64(&client.gets))
+	}
+}
+
+func TestFS_BlockCacheSameChecksum(t *testing.T) {
+	client := newFakeClient(map[string]string{
+		"one": "first",
+		"two": "other",
+	})
+	// objects stored at different addresses are different even if their checksums are equal
+	client.checksums = map[string]string{"one": "same", "two": "same"}
+	fsys := mount.NewFS(context.Background(), client, testRepository, testRef, mount.Options{
+		BlockSize: testBlockSize,
+		CacheDir:  t.TempDir(),
+	})
+	for _, name := range []string{"one", "two"} {
+		content, err := fs.ReadFile(fsys, name)
+		require.NoError(t, err)
+		require.Equal(t, client.objects[name], string(content))
+	}
+	require.Equal(t, int64(2), atomic.LoadInt64(&client.gets))
+}
+
+func TestFS_Presign(t *testing.T) {
+	client := newFakeClient(testObjects())
+	var requests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		content, ok := client.objects[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, "", time.Unix(testMtime, 0), bytes.NewReader([]byte(content)))
+	}))
+	defer server.Close()
+	client.presignURL = server.URL
+
+	fsys := mount.NewFS(context.Background(), client, testRepository, testRef, mount.Options{
+		BlockSize:  testBlockSize,
+		Presign:    true,
+		HTTPClient: server.Client(),
+	})
+	content, err := fs.ReadFile(fsys, "dir/sub/large.txt")
+	require.NoError(t, err)
+	require.Equal(t, client.objects["dir/sub/large.txt"], string(content))
+	require.Zero(t, atomic.LoadInt64(&client.gets))
+	require.Equal(t, int64(6), atomic.LoadInt64(&requests))
+}
@@ -0,0 +1,139 @@
+//go:build linux || darwin
+
+package mount
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"syscall"
+	"time"
+
+	fusefs "github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// attrTimeout is how long the kernel caches attributes and lookups, the mounted ref does not change
+const attrTimeout = time.Hour
+
+// fuseNode is a file or a directory of FS served through FUSE
+type fuseNode struct {
+	fusefs.Inode
+	fsys *FS
+	name string
+}
+
+var (
+	_ fusefs.NodeLookuper  = (*fuseNode)(nil)
+	_ fusefs.NodeReaddirer = (*fuseNode)(nil)
+	_ fusefs.NodeGetattrer = (*fuseNode)(nil)
+	_ fusefs.NodeOpener    = (*fuseNode)(nil)
+	_ fusefs.NodeReader    = (*fuseNode)(nil)
+)
+
+// Mount serves fsys read-only through FUSE at mountpoint, until it is unmounted
+func Mount(fsys *FS, mountpoint string) (Server, error) {
+	timeout := attrTimeout
+	root := &fuseNode{fsys: fsys, name: "."}
+	return fusefs.Mount(mountpoint, root, &fusefs.Options{
+		MountOptions: fuse.MountOptions{
+			FsName: "lakefs://" + fsys.repository + "/" + fsys.ref,
+			Name:   "lakefs",
+			// the kernel checks permissions by the modes of files and directories
+			Options: []string{"ro", "default_permissions"},
+		},
+		AttrTimeout:  &timeout,
+		EntryTimeout: &timeout,
+	})
+}
+
+func toErrno(err error) syscall.Errno {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, fs.ErrNotExist):
+		return syscall.ENOENT
+	case errors.Is(err, fs.ErrInvalid):
+		return syscall.EINVAL
+	case errors.Is(err, context.Canceled):
+		return syscall.EINTR
+	default:
+		return syscall.EIO
+	}
+}
+
+func setAttr(info *fileInfo, out *fuse.Attr) {
+	if info.dir {
+		out.Mode = syscall.S_IFDIR | dirMode
+		out.Nlink = 2
+	} else {
+		out.Mode = syscall.S_IFREG | fileMode
+		out.Nlink = 1
+		out.Size = uint64(info.size)
+		out.Blocks = (out.Size + 511) / 512
+	}
+	mtime := info.mtime
+	out.SetTimes(&mtime, &mtime, &mtime)
+}
+
+func (n *fuseNode) Lookup(ctx context.Context, name string, out *fuse.EntryOut) (*fusefs.Inode, syscall.Errno) {
+	childName := path.Join(n.name, name)
+	info, err := n.fsys.stat(ctx, childName)
+	if err != nil {
+		return nil, toErrno(err)
+	}
+	setAttr(info, &out.Attr)
+	mode := uint32(syscall.S_IFREG)
+	if info.dir {
+		mode = syscall.S_IFDIR
+	}
+	child := &fuseNode{fsys: n.fsys, name: childName}
+	return n.NewInode(ctx, child, fusefs.StableAttr{Mode: mode}), 0
+}
+
+func (n *fuseNode) Readdir(ctx context.Context) (fusefs.DirStream, syscall.Errno) {
+	entries, err := n.fsys.readDir(ctx, n.name)
+	if err != nil {
+		return nil, toErrno(err)
+	}
+	list := make([]fuse.DirEntry, len(entries))
+	for i, entry := range entries {
+		mode := uint32(syscall.S_IFREG)
+		if entry.IsDir() {
+			mode = syscall.S_IFDIR
+		}
+		list[i] = fuse.DirEntry{Name: entry.Name(), Mode: mode}
+	}
+	return fusefs.NewListDirStream(list), 0
+}
+
+func (n *fuseNode) Getattr(ctx context.Context, _ fusefs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	info, err := n.fsys.stat(ctx, n.name)
+	if err != nil {
+		return toErrno(err)
+	}
+	setAttr(info, &out.Attr)
+	return 0
+}
+
+func (n *fuseNode) Open(_ context.Context, flags uint32) (fusefs.FileHandle, uint32, syscall.Errno) {
+	if flags&(syscall.O_WRONLY|syscall.O_RDWR|syscall.O_APPEND|syscall.O_TRUNC) != 0 {
+		return nil, 0, syscall.EROFS
+	}
+	// data of the mounted ref does not change, the kernel may keep it cached
+	return nil, fuse.FOPEN_KEEP_CACHE, 0
+}
+
+func (n *fuseNode) Read(ctx context.Context, _ fusefs.FileHandle, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	info, err := n.fsys.stat(ctx, n.name)
+	if err != nil {
+		return nil, toErrno(err)
+	}
+	count, err := n.fsys.readAt(ctx, n.name, info, dest, off)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, toErrno(err)
+	}
+	return fuse.ReadResultData(dest[:count]), 0
+}
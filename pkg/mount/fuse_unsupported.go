@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+
+package mount
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Mount serves fsys read-only through FUSE at mountpoint, until it is unmounted.  FUSE is not supported on this
+// platform.
+func Mount(_ *FS, _ string) (Server, error) {
+	return nil, fmt.Errorf("%s: %w", runtime.GOOS, ErrNotSupported)
+}
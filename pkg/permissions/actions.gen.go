@@ -9,6 +9,7 @@ var Actions = []string{
 	"fs:ImportFromStorage",
 	"fs:ImportCancel",
 	"fs:DeleteRepository",
+	"fs:UpdateRepositoryState",
 	"fs:ListRepositories",
 	"fs:ReadObject",
 	"fs:WriteObject",
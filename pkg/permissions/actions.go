@@ -20,6 +20,7 @@ const (
 	ImportFromStorageAction                   = "fs:ImportFromStorage"
 	ImportCancelAction                        = "fs:ImportCancel"
 	DeleteRepositoryAction                    = "fs:DeleteRepository"
+	UpdateRepositoryStateAction               = "fs:UpdateRepositoryState"
 	ListRepositoriesAction                    = "fs:ListRepositories"
 	ReadObjectAction                          = "fs:ReadObject"
 	WriteObjectAction                         = "fs:WriteObject"
@@ -96,3 +96,15 @@ func RegisterDeltaLakeDiffPlugin(ds *Service, pid plugins.PluginIdentity, handsh
 	}
 	ds.registerDiffClient("delta", props)
 }
+
+// tableDiffPluginRegistration returns a registration of a plugin of diffType.  The TableDiffer GRPC API does not depend
+// on the table format, so plugins of any table format can use the Delta Lake diff plugin client.
+func tableDiffPluginRegistration(diffType string) registrationFunc {
+	return func(ds *Service, pid plugins.PluginIdentity, handshake plugins.PluginHandshake) {
+		ds.registerDiffClient(diffType, internal.HCPluginProperties{
+			ID:        pid,
+			Handshake: handshake,
+			P:         DeltaDiffGRPCPlugin{},
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package tablediff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HudiDiffType = "hudi"
+
+	hudiMetaDir            = ".hoodie"
+	hudiPropertiesFilename = "hoodie.properties"
+	hudiInstantTimeLayout  = "20060102150405"
+	hudiInstantMillisLen   = len(hudiInstantTimeLayout) + 3
+)
+
+// hudiCommitActions are the actions of completed instants that hold commit metadata
+var hudiCommitActions = map[string]struct{}{
+	"commit":        {},
+	"deltacommit":   {},
+	"replacecommit": {},
+}
+
+// hudiTimelineActions are the actions of completed instants on the timeline of a table
+var hudiTimelineActions = map[string]struct{}{
+	"commit":        {},
+	"deltacommit":   {},
+	"replacecommit": {},
+	"clean":         {},
+	"rollback":      {},
+	"savepoint":     {},
+	"restore":       {},
+}
+
+// HudiDiffer is the built-in differ of Apache Hudi tables.  It compares the completed instants on the timelines of
+// the table at the two refs.
+type HudiDiffer struct{}
+
+type hudiWriteStat struct {
+	NumWrites       int64 `json:"numWrites"`
+	NumDeletes      int64 `json:"numDeletes"`
+	NumInserts      int64 `json:"numInserts"`
+	NumUpdateWrites int64 `json:"numUpdateWrites"`
+}
+
+type hudiCommitMetadata struct {
+	OperationType         string                     `json:"operationType"`
+	PartitionToWriteStats map[string][]hudiWriteStat `json:"partitionToWriteStats"`
+}
+
+type hudiInstant struct {
+	time   string
+	action string
+}
+
+func (d *HudiDiffer) Diff(ctx context.Context, ps Params) (Response, error) {
+	return diffHistories(ctx, ps, hudiHistory)
+}
+
+// hudiHistory returns the completed instants of the active timeline of the table, newest first
+func hudiHistory(ctx context.Context, reader TableReader, repo string, tablePath RefPath) ([]DiffEntry, error) {
+	names, err := listDir(ctx, reader, repo, tablePath, hudiMetaDir)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		instants []hudiInstant
+		found    bool
+	)
+	for _, name := range names {
+		if name == hudiPropertiesFilename {
+			found = true
+			continue
+		}
+		// requested and inflight instants are named "<time>.<action>.requested" and "<time>.<action>.inflight"
+		instantTime, action, ok := strings.Cut(name, ".")
+		if !ok {
+			continue
+		}
+		if _, ok := hudiTimelineActions[action]; !ok {
+			continue
+		}
+		if _, err := strconv.ParseUint(instantTime, 10, 64); err != nil {
+			continue
+		}
+		instants = append(instants, hudiInstant{time: instantTime, action: action})
+	}
+	if !found {
+		return nil, ErrTableNotFound
+	}
+	sort.Slice(instants, func(i, j int) bool {
+		return instants[i].time < instants[j].time
+	})
+
+	history := make([]DiffEntry, 0, len(instants))
+	for i := len(instants) - 1; i >= 0; i-- {
+		entry, err := hudiDiffEntry(ctx, reader, repo, tablePath, instants[i], i == 0)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, entry)
+	}
+	return history, nil
+}
+
+func hudiDiffEntry(ctx context.Context, reader TableReader, repo string, tablePath RefPath, instant hudiInstant, first bool) (DiffEntry, error) {
+	entry := DiffEntry{
+		ID:               instant.time,
+		Timestamp:        parseHudiInstantTime(instant.time),
+		Operation:        strings.ToUpper(instant.action),
+		OperationContent: map[string]string{"action": instant.action},
+		OperationType:    OpTypeUnknown,
+	}
+	if _, ok := hudiCommitActions[instant.action]; ok {
+		metadata, err := readHudiCommitMetadata(ctx, reader, repo, tablePath, instant)
+		if err != nil {
+			return DiffEntry{}, err
+		}
+		if metadata.OperationType != "" {
+			entry.Operation = metadata.OperationType
+		}
+		var stats hudiWriteStat
+		for _, partitionStats := range metadata.PartitionToWriteStats {
+			for _, s := range partitionStats {
+				stats.NumWrites += s.NumWrites
+				stats.NumDeletes += s.NumDeletes
+				stats.NumInserts += s.NumInserts
+				stats.NumUpdateWrites += s.NumUpdateWrites
+			}
+		}
+		entry.OperationContent["partitions"] = strconv.Itoa(len(metadata.PartitionToWriteStats))
+		entry.OperationContent["numWrites"] = strconv.FormatInt(stats.NumWrites, 10)
+		entry.OperationContent["numDeletes"] = strconv.FormatInt(stats.NumDeletes, 10)
+		entry.OperationContent["numInserts"] = strconv.FormatInt(stats.NumInserts, 10)
+		entry.OperationContent["numUpdateWrites"] = strconv.FormatInt(stats.NumUpdateWrites, 10)
+		entry.OperationType = OpTypeUpdate
+		if strings.HasPrefix(metadata.OperationType, "DELETE") {
+			entry.OperationType = OpTypeDelete
+		}
+	}
+	if first {
+		entry.OperationType = OpTypeCreate
+	}
+	return entry, nil
+}
+
+func readHudiCommitMetadata(ctx context.Context, reader TableReader, repo string, tablePath RefPath, instant hudiInstant) (*hudiCommitMetadata, error) {
+	p := path.Join(tablePath.Path, hudiMetaDir, instant.time+"."+instant.action)
+	r, err := reader.GetObject(ctx, repo, tablePath.Ref, p)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var metadata hudiCommitMetadata
+	if len(data) == 0 {
+		// commits that write nothing may have empty metadata
+		return &metadata, nil
+	}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("parse hudi commit metadata %s: %w", p, err)
+	}
+	return &metadata, nil
+}
+
+// parseHudiInstantTime parses an instant time of seconds ("yyyyMMddHHmmss") or milliseconds ("yyyyMMddHHmmssSSS")
+// precision, or returns the zero time if it is not a valid instant time.
+func parseHudiInstantTime(instantTime string) time.Time {
+	if len(instantTime) < len(hudiInstantTimeLayout) {
+		return time.Time{}
+	}
+	t, err := time.Parse(hudiInstantTimeLayout, instantTime[:len(hudiInstantTimeLayout)])
+	if err != nil {
+		return time.Time{}
+	}
+	if len(instantTime) == hudiInstantMillisLen {
+		millis, err := strconv.Atoi(instantTime[len(hudiInstantTimeLayout):])
+		if err == nil {
+			t = t.Add(time.Duration(millis) * time.Millisecond)
+		}
+	}
+	return t
+}
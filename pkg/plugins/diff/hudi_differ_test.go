@@ -0,0 +1,84 @@
+package tablediff
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHudiDiffer_Diff(t *testing.T) {
+	insert := `{"operationType": "INSERT", "partitionToWriteStats": {"p1": [{"numWrites": 5, "numInserts": 5}]}}`
+	upsert := `{"operationType": "UPSERT", "partitionToWriteStats": {"p1": [{"numWrites": 2, "numUpdateWrites": 2}], "p2": [{"numWrites": 1, "numInserts": 1}]}}`
+	deleteCommit := `{"operationType": "DELETE", "partitionToWriteStats": {"p1": [{"numDeletes": 3}]}}`
+	reader := memTableReader{
+		"left": {
+			"tables/h/.hoodie/hoodie.properties":     "hoodie.table.name=h",
+			"tables/h/.hoodie/20230101100000.commit": insert,
+		},
+		"right": {
+			"tables/h/.hoodie/hoodie.properties":                    "hoodie.table.name=h",
+			"tables/h/.hoodie/20230101100000.commit":                insert,
+			"tables/h/.hoodie/20230101110000123.deltacommit":        upsert,
+			"tables/h/.hoodie/20230101120000.commit":                deleteCommit,
+			"tables/h/.hoodie/20230101130000.clean":                 "",
+			"tables/h/.hoodie/20230101140000.commit.requested":      "",
+			"tables/h/.hoodie/20230101140000.commit.inflight":       "",
+			"tables/h/.hoodie/archived/20221231100000.commit":       insert,
+			"tables/h/p1/file.parquet":                              "",
+			"tables/new/.hoodie/hoodie.properties":                  "hoodie.table.name=new",
+			"tables/new/.hoodie/20230101100000.commit":              insert,
+			"tables/new/.hoodie/20230101100000.commit.requested":    "",
+			"tables/other/.hoodie/20230101100000.commit.no-options": "",
+		},
+	}
+	d := &HudiDiffer{}
+
+	t.Run("changed", func(t *testing.T) {
+		res, err := d.Diff(context.Background(), diffParams(reader, "tables/h/"))
+		if err != nil {
+			t.Fatalf("Diff() failed: %s", err)
+		}
+		if res.DiffType != DiffTypeChanged {
+			t.Errorf("Diff() type = %s, expected %s", res.DiffType, DiffTypeChanged)
+		}
+		if ids := strings.Join(diffIDs(res.Diffs), ","); ids != "20230101130000,20230101120000,20230101110000123" {
+			t.Fatalf("Diff() IDs = %s", ids)
+		}
+		clean, deleted, upserted := res.Diffs[0], res.Diffs[1], res.Diffs[2]
+		if clean.Operation != "CLEAN" || clean.OperationType != OpTypeUnknown {
+			t.Errorf("Diff() clean operation = %s (%s)", clean.Operation, clean.OperationType)
+		}
+		if deleted.Operation != "DELETE" || deleted.OperationType != OpTypeDelete || deleted.OperationContent["numDeletes"] != "3" {
+			t.Errorf("Diff() delete operation = %s (%s) %v", deleted.Operation, deleted.OperationType, deleted.OperationContent)
+		}
+		if upserted.Operation != "UPSERT" || upserted.OperationType != OpTypeUpdate ||
+			upserted.OperationContent["numWrites"] != "3" || upserted.OperationContent["partitions"] != "2" {
+			t.Errorf("Diff() upsert operation = %s (%s) %v", upserted.Operation, upserted.OperationType, upserted.OperationContent)
+		}
+		expectedTime := time.Date(2023, 1, 1, 11, 0, 0, int(123*time.Millisecond), time.UTC)
+		if !upserted.Timestamp.Equal(expectedTime) {
+			t.Errorf("Diff() upsert timestamp = %s, expected %s", upserted.Timestamp, expectedTime)
+		}
+	})
+
+	t.Run("created", func(t *testing.T) {
+		res, err := d.Diff(context.Background(), diffParams(reader, "tables/new"))
+		if err != nil {
+			t.Fatalf("Diff() failed: %s", err)
+		}
+		if res.DiffType != DiffTypeCreated {
+			t.Errorf("Diff() type = %s, expected %s", res.DiffType, DiffTypeCreated)
+		}
+		if len(res.Diffs) != 1 || res.Diffs[0].OperationType != OpTypeCreate {
+			t.Errorf("Diff() diffs = %+v, expected a single create", res.Diffs)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := d.Diff(context.Background(), diffParams(reader, "tables/other"))
+		if err != ErrTableNotFound {
+			t.Fatalf("Diff() err = %v, expected %s", err, ErrTableNotFound)
+		}
+	})
+}
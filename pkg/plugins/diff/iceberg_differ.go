@@ -0,0 +1,183 @@
+package tablediff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	IcebergDiffType = "iceberg"
+
+	icebergMetadataDir         = "metadata"
+	icebergMetadataSuffix      = ".metadata.json"
+	icebergVersionHintFilename = "version-hint.text"
+	icebergOperationKey        = "operation"
+	icebergNoSnapshotID        = -1
+)
+
+// IcebergDiffer is the built-in differ of Apache Iceberg tables.  It compares the snapshots in the current metadata
+// files of the table at the two refs.
+type IcebergDiffer struct{}
+
+type icebergSnapshot struct {
+	SnapshotID       int64             `json:"snapshot-id"`
+	ParentSnapshotID *int64            `json:"parent-snapshot-id"`
+	TimestampMS      int64             `json:"timestamp-ms"`
+	Summary          map[string]string `json:"summary"`
+}
+
+type icebergMetadata struct {
+	CurrentSnapshotID *int64            `json:"current-snapshot-id"`
+	Snapshots         []icebergSnapshot `json:"snapshots"`
+}
+
+func (d *IcebergDiffer) Diff(ctx context.Context, ps Params) (Response, error) {
+	return diffHistories(ctx, ps, icebergHistory)
+}
+
+// icebergHistory returns the ancestry of the current snapshot of the table, newest first
+func icebergHistory(ctx context.Context, reader TableReader, repo string, tablePath RefPath) ([]DiffEntry, error) {
+	metadataPath, err := icebergCurrentMetadataPath(ctx, reader, repo, tablePath)
+	if err != nil {
+		return nil, err
+	}
+	r, err := reader.GetObject(ctx, repo, tablePath.Ref, metadataPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var metadata icebergMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("parse iceberg metadata %s: %w", metadataPath, err)
+	}
+
+	snapshots := make(map[int64]icebergSnapshot, len(metadata.Snapshots))
+	for _, s := range metadata.Snapshots {
+		snapshots[s.SnapshotID] = s
+	}
+	history := make([]DiffEntry, 0)
+	if metadata.CurrentSnapshotID == nil {
+		return history, nil
+	}
+	for id := *metadata.CurrentSnapshotID; id != icebergNoSnapshotID; {
+		// snapshots expire, so the ancestry may end before the first snapshot
+		s, ok := snapshots[id]
+		if !ok {
+			break
+		}
+		history = append(history, icebergDiffEntry(s))
+		if s.ParentSnapshotID == nil {
+			break
+		}
+		id = *s.ParentSnapshotID
+	}
+	return history, nil
+}
+
+func icebergDiffEntry(s icebergSnapshot) DiffEntry {
+	operation := s.Summary[icebergOperationKey]
+	content := make(map[string]string, len(s.Summary))
+	for k, v := range s.Summary {
+		if k != icebergOperationKey {
+			content[k] = v
+		}
+	}
+	var opType string
+	switch {
+	case s.ParentSnapshotID == nil:
+		opType = OpTypeCreate
+	case operation == "delete":
+		opType = OpTypeDelete
+	case operation == "append", operation == "overwrite", operation == "replace":
+		opType = OpTypeUpdate
+	default:
+		opType = OpTypeUnknown
+	}
+	return DiffEntry{
+		ID:               strconv.FormatInt(s.SnapshotID, 10),
+		Timestamp:        time.UnixMilli(s.TimestampMS),
+		Operation:        operation,
+		OperationContent: content,
+		OperationType:    opType,
+	}
+}
+
+// icebergCurrentMetadataPath returns the path of the current metadata file of the table: the version in the version
+// hint file if there is one, otherwise the highest version of the metadata files.
+func icebergCurrentMetadataPath(ctx context.Context, reader TableReader, repo string, tablePath RefPath) (string, error) {
+	names, err := listDir(ctx, reader, repo, tablePath, icebergMetadataDir)
+	if err != nil {
+		return "", err
+	}
+	metadataDir := path.Join(tablePath.Path, icebergMetadataDir)
+	current := ""
+	currentVersion := -1
+	hasVersionHint := false
+	for _, name := range names {
+		if name == icebergVersionHintFilename {
+			hasVersionHint = true
+		}
+		version, ok := icebergMetadataVersion(name)
+		if ok && version > currentVersion {
+			current, currentVersion = name, version
+		}
+	}
+	if current == "" {
+		return "", ErrTableNotFound
+	}
+	if hasVersionHint {
+		hint, err := readVersionHint(ctx, reader, repo, tablePath.Ref, path.Join(metadataDir, icebergVersionHintFilename))
+		if err != nil {
+			return "", err
+		}
+		name := "v" + hint + icebergMetadataSuffix
+		for _, n := range names {
+			if n == name {
+				return path.Join(metadataDir, name), nil
+			}
+		}
+	}
+	return path.Join(metadataDir, current), nil
+}
+
+// icebergMetadataVersion parses the version of a metadata file named "v<version>.metadata.json" (Hadoop tables) or
+// "<version>-<uuid>.metadata.json" (catalog tables)
+func icebergMetadataVersion(name string) (int, bool) {
+	if !strings.HasSuffix(name, icebergMetadataSuffix) {
+		return 0, false
+	}
+	v := strings.TrimSuffix(name, icebergMetadataSuffix)
+	if strings.HasPrefix(v, "v") {
+		v = v[1:]
+	} else if i := strings.Index(v, "-"); i >= 0 {
+		v = v[:i]
+	}
+	version, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+func readVersionHint(ctx context.Context, reader TableReader, repo, ref, p string) (string, error) {
+	r, err := reader.GetObject(ctx, repo, ref, p)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = r.Close() }()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
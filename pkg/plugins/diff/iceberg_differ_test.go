@@ -0,0 +1,96 @@
+package tablediff
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	icebergMetadataV1 = `{
+  "format-version": 2,
+  "current-snapshot-id": 1,
+  "snapshots": [
+    {"snapshot-id": 1, "timestamp-ms": 1672531200000, "summary": {"operation": "append", "added-records": "10"}}
+  ]
+}`
+	icebergMetadataV3 = `{
+  "format-version": 2,
+  "current-snapshot-id": 3,
+  "snapshots": [
+    {"snapshot-id": 1, "timestamp-ms": 1672531200000, "summary": {"operation": "append", "added-records": "10"}},
+    {"snapshot-id": 2, "parent-snapshot-id": 1, "timestamp-ms": 1672534800000, "summary": {"operation": "overwrite"}},
+    {"snapshot-id": 3, "parent-snapshot-id": 2, "timestamp-ms": 1672538400000, "summary": {"operation": "delete", "deleted-records": "4"}}
+  ]
+}`
+)
+
+func TestIcebergDiffer_Diff(t *testing.T) {
+	reader := memTableReader{
+		"left": {
+			"tables/t/metadata/v1.metadata.json":  icebergMetadataV1,
+			"tables/t/metadata/version-hint.text": "1\n",
+		},
+		"right": {
+			"tables/t/metadata/v1.metadata.json": icebergMetadataV1,
+			"tables/t/metadata/v3.metadata.json": icebergMetadataV3,
+			// an uncommitted metadata file that the version hint does not point to
+			"tables/t/metadata/v4.metadata.json":            `{"current-snapshot-id": 4}`,
+			"tables/t/metadata/version-hint.text":           "3",
+			"tables/t/data/file.parquet":                    "",
+			"tables/catalog/metadata/00000-a.metadata.json": icebergMetadataV1,
+			"tables/catalog/metadata/00001-b.metadata.json": icebergMetadataV3,
+		},
+	}
+	d := &IcebergDiffer{}
+
+	t.Run("changed", func(t *testing.T) {
+		res, err := d.Diff(context.Background(), diffParams(reader, "tables/t"))
+		if err != nil {
+			t.Fatalf("Diff() failed: %s", err)
+		}
+		if res.DiffType != DiffTypeChanged {
+			t.Errorf("Diff() type = %s, expected %s", res.DiffType, DiffTypeChanged)
+		}
+		if ids := strings.Join(diffIDs(res.Diffs), ","); ids != "3,2" {
+			t.Fatalf("Diff() IDs = %s, expected 3,2", ids)
+		}
+		deleteEntry := res.Diffs[0]
+		if deleteEntry.Operation != "delete" || deleteEntry.OperationType != OpTypeDelete {
+			t.Errorf("Diff() operation = %s (%s), expected delete (%s)", deleteEntry.Operation, deleteEntry.OperationType, OpTypeDelete)
+		}
+		if deleteEntry.OperationContent["deleted-records"] != "4" {
+			t.Errorf("Diff() content = %v, expected deleted-records", deleteEntry.OperationContent)
+		}
+		if !deleteEntry.Timestamp.Equal(time.UnixMilli(1672538400000)) {
+			t.Errorf("Diff() timestamp = %s", deleteEntry.Timestamp)
+		}
+		if res.Diffs[1].OperationType != OpTypeUpdate {
+			t.Errorf("Diff() overwrite operation type = %s, expected %s", res.Diffs[1].OperationType, OpTypeUpdate)
+		}
+	})
+
+	t.Run("created", func(t *testing.T) {
+		res, err := d.Diff(context.Background(), diffParams(reader, "tables/catalog"))
+		if err != nil {
+			t.Fatalf("Diff() failed: %s", err)
+		}
+		if res.DiffType != DiffTypeCreated {
+			t.Errorf("Diff() type = %s, expected %s", res.DiffType, DiffTypeCreated)
+		}
+		if ids := strings.Join(diffIDs(res.Diffs), ","); ids != "3,2,1" {
+			t.Fatalf("Diff() IDs = %s, expected 3,2,1", ids)
+		}
+		if res.Diffs[2].OperationType != OpTypeCreate {
+			t.Errorf("Diff() first snapshot operation type = %s, expected %s", res.Diffs[2].OperationType, OpTypeCreate)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := d.Diff(context.Background(), diffParams(reader, "tables/none"))
+		if err != ErrTableNotFound {
+			t.Fatalf("Diff() err = %v, expected %s", err, ErrTableNotFound)
+		}
+	})
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
@@ -21,6 +23,7 @@ var (
 	ErrTableNotFound = errors.New("table not found")
 	ErrLoadingPlugin = errors.New("failed to load diff plugin")
 	ErrFailedDiff    = errors.New("failed to run diff")
+	ErrNoTableReader = errors.New("no table reader")
 )
 
 const (
@@ -91,6 +94,16 @@ type Params struct {
 	TablePaths TablePaths
 	S3Creds    S3Creds
 	Repo       string
+	// Reader reads the table files for the built-in differs.  Plugins read them through the S3 gateway using S3Creds.
+	Reader TableReader
+}
+
+// TableReader reads the files of a table at a ref.
+type TableReader interface {
+	// ListObjects returns the paths of all objects under prefix at ref, sorted.
+	ListObjects(ctx context.Context, repository, ref, prefix string) ([]string, error)
+	// GetObject returns the content of the object at path at ref.
+	GetObject(ctx context.Context, repository, ref, path string) (io.ReadCloser, error)
 }
 
 type Differ interface {
@@ -101,22 +114,31 @@ type Differ interface {
 // After initializing a Service, the CloseClients method should be called at some point to close gracefully all
 // remaining plugins.
 type Service struct {
-	pluginHandler  internal.Handler[Differ, internal.HCPluginProperties]
+	pluginHandler internal.Handler[Differ, internal.HCPluginProperties]
+	// builtinDiffers are the in-process differs, used for diff types with no registered plugin
+	builtinDiffers map[string]Differ
 	closeFunctions map[string]func()
 	l              sync.Mutex
 }
 
 func (s *Service) RunDiff(ctx context.Context, diffType string, diffParams Params) (Response, error) {
-	d, closeClient, err := s.pluginHandler.LoadPluginClient(diffType)
-	if err != nil {
-		logging.FromContext(ctx).WithError(err).
-			WithField("type", diffType).
-			WithField("params", fmt.Sprintf("%+v", diffParams)).
-			Error("failed to load the plugin client")
-		return Response{}, ErrLoadingPlugin
-	}
-	if closeClient != nil {
-		s.appendClosingFunction(diffType, closeClient)
+	d, ok := s.builtinDiffers[diffType]
+	if !ok {
+		var (
+			closeClient func()
+			err         error
+		)
+		d, closeClient, err = s.pluginHandler.LoadPluginClient(diffType)
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).
+				WithField("type", diffType).
+				WithField("params", fmt.Sprintf("%+v", diffParams)).
+				Error("failed to load the plugin client")
+			return Response{}, ErrLoadingPlugin
+		}
+		if closeClient != nil {
+			s.appendClosingFunction(diffType, closeClient)
+		}
 	}
 
 	diffResponse, err := d.Diff(ctx, diffParams)
@@ -141,9 +163,18 @@ func (s *Service) Close() {
 }
 
 func (s *Service) registerDiffClient(diffType string, props internal.HCPluginProperties) {
+	// a plugin replaces the built-in differ of its diff type
+	delete(s.builtinDiffers, diffType)
 	s.pluginHandler.RegisterPlugin(diffType, props)
 }
 
+func (s *Service) registerBuiltinDiffer(diffType string, d Differ) {
+	if s.builtinDiffers == nil {
+		s.builtinDiffers = make(map[string]Differ)
+	}
+	s.builtinDiffers[diffType] = d
+}
+
 func (s *Service) appendClosingFunction(diffType string, f func()) {
 	s.l.Lock()
 	defer s.l.Unlock()
@@ -152,8 +183,20 @@ func (s *Service) appendClosingFunction(diffType string, f func()) {
 	}
 }
 
+// IsBuiltin reports whether diffType is run by a built-in differ rather than by a plugin.
+func (s *Service) IsBuiltin(diffType string) bool {
+	_, ok := s.builtinDiffers[diffType]
+	return ok
+}
+
+// EnabledDiffs returns the diff types that can be run, sorted: the registered plugins and the built-in differs.
 func (s *Service) EnabledDiffs() []string {
-	return s.pluginHandler.Plugins()
+	diffs := s.pluginHandler.Plugins()
+	for diffType := range s.builtinDiffers {
+		diffs = append(diffs, diffType)
+	}
+	sort.Strings(diffs)
+	return diffs
 }
 
 // NewService is used to initialize a new Differ service. The returned function is a closing function for the service.
@@ -167,11 +210,19 @@ func NewService(diffProps config.DiffProps, pluginProps config.Plugins) (*Servic
 }
 
 func registerPlugins(service *Service, diffProps config.DiffProps, pluginProps config.Plugins) {
+	service.registerBuiltinDiffer(IcebergDiffType, &IcebergDiffer{})
+	service.registerBuiltinDiffer(HudiDiffType, &HudiDiffer{})
 	registerDefaultPlugins(service, pluginProps.DefaultPath)
 
 	if diffProps.Delta.PluginName != "" {
 		registerPlugin(service, pluginProps, RegisterDeltaLakeDiffPlugin, "delta", diffProps.Delta.PluginName)
 	}
+	if diffProps.Iceberg.PluginName != "" {
+		registerPlugin(service, pluginProps, tableDiffPluginRegistration(IcebergDiffType), IcebergDiffType, diffProps.Iceberg.PluginName)
+	}
+	if diffProps.Hudi.PluginName != "" {
+		registerPlugin(service, pluginProps, tableDiffPluginRegistration(HudiDiffType), HudiDiffType, diffProps.Hudi.PluginName)
+	}
 }
 
 func registerDefaultPlugins(service *Service, pluginsPath string) {
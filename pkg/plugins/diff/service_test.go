@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"path/filepath"
+	"reflect"
 	"strconv"
 	"testing"
 
@@ -138,3 +139,26 @@ func Test_registerPlugins(t *testing.T) {
 		})
 	}
 }
+
+func TestService_BuiltinDiffers(t *testing.T) {
+	service, closeService := NewService(config.DiffProps{}, config.Plugins{DefaultPath: t.TempDir()})
+	defer closeService()
+	if diffs := service.EnabledDiffs(); !reflect.DeepEqual(diffs, []string{HudiDiffType, IcebergDiffType}) {
+		t.Errorf("EnabledDiffs() = %v, expected built-in hudi and iceberg", diffs)
+	}
+	if !service.IsBuiltin(IcebergDiffType) || !service.IsBuiltin(HudiDiffType) {
+		t.Error("expected built-in iceberg and hudi differs")
+	}
+
+	// a configured plugin replaces the built-in differ
+	service, closePluginService := NewService(config.DiffProps{
+		Iceberg: config.TableDiffPlugin{PluginName: "my-iceberg"},
+	}, config.Plugins{DefaultPath: t.TempDir()})
+	defer closePluginService()
+	if service.IsBuiltin(IcebergDiffType) {
+		t.Error("expected iceberg diff plugin to replace the built-in differ")
+	}
+	if diffs := service.EnabledDiffs(); !reflect.DeepEqual(diffs, []string{HudiDiffType, IcebergDiffType}) {
+		t.Errorf("EnabledDiffs() = %v, expected hudi and iceberg", diffs)
+	}
+}
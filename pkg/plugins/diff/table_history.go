@@ -0,0 +1,66 @@
+package tablediff
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+)
+
+// historyLoader returns the history of operations of the table at a ref, newest first, or ErrTableNotFound if there
+// is no table at the ref.
+type historyLoader func(ctx context.Context, reader TableReader, repo string, tablePath RefPath) ([]DiffEntry, error)
+
+// diffHistories compares the histories of a table at two refs, the way the Delta Lake diff plugin does:
+//  1. If the table is found only on the right ref, it was created, and its whole history is returned.
+//  2. If the table is found only on the left ref, it was dropped.
+//  3. Otherwise, the operations of the right table are returned, newest first, until reaching an operation that is
+//     also in the history of the left table.
+func diffHistories(ctx context.Context, ps Params, load historyLoader) (Response, error) {
+	if ps.Reader == nil {
+		return Response{}, ErrNoTableReader
+	}
+	left, leftErr := load(ctx, ps.Reader, ps.Repo, ps.TablePaths.Left)
+	right, rightErr := load(ctx, ps.Reader, ps.Repo, ps.TablePaths.Right)
+	switch {
+	case leftErr == nil && rightErr == nil:
+	case errors.Is(leftErr, ErrTableNotFound) && rightErr == nil:
+		return Response{DiffType: DiffTypeCreated, Diffs: right}, nil
+	case leftErr == nil && errors.Is(rightErr, ErrTableNotFound):
+		return Response{DiffType: DiffTypeDropped, Diffs: []DiffEntry{}}, nil
+	case leftErr != nil:
+		return Response{}, leftErr
+	default:
+		return Response{}, rightErr
+	}
+
+	leftIDs := make(map[string]struct{}, len(left))
+	for _, entry := range left {
+		leftIDs[entry.ID] = struct{}{}
+	}
+	diffs := make([]DiffEntry, 0)
+	for _, entry := range right {
+		if _, ok := leftIDs[entry.ID]; ok {
+			break
+		}
+		diffs = append(diffs, entry)
+	}
+	return Response{DiffType: DiffTypeChanged, Diffs: diffs}, nil
+}
+
+// listDir returns the names of the objects directly under dir at a ref
+func listDir(ctx context.Context, reader TableReader, repo string, tablePath RefPath, dir string) ([]string, error) {
+	prefix := path.Join(tablePath.Path, dir) + "/"
+	paths, err := reader.ListObjects(ctx, repo, tablePath.Ref, prefix)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(paths))
+	for _, p := range paths {
+		name := strings.TrimPrefix(p, prefix)
+		if name != "" && !strings.Contains(name, "/") {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
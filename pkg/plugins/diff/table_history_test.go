@@ -0,0 +1,141 @@
+package tablediff
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var errObjectNotFound = errors.New("object not found")
+
+// memTableReader reads tables from objects in memory, keyed by ref and then by path
+type memTableReader map[string]map[string]string
+
+func (m memTableReader) ListObjects(_ context.Context, _, ref, prefix string) ([]string, error) {
+	var paths []string
+	for p := range m[ref] {
+		if strings.HasPrefix(p, prefix) {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func (m memTableReader) GetObject(_ context.Context, _, ref, path string) (io.ReadCloser, error) {
+	data, ok := m[ref][path]
+	if !ok {
+		return nil, errObjectNotFound
+	}
+	return io.NopCloser(bytes.NewReader([]byte(data))), nil
+}
+
+func diffParams(reader TableReader, tablePath string) Params {
+	return Params{
+		TablePaths: TablePaths{
+			Left:  RefPath{Ref: "left", Path: tablePath},
+			Right: RefPath{Ref: "right", Path: tablePath},
+		},
+		Repo:   "repo",
+		Reader: reader,
+	}
+}
+
+func diffIDs(entries []DiffEntry) []string {
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestDiffHistories(t *testing.T) {
+	history := func(ids ...string) []DiffEntry {
+		entries := make([]DiffEntry, 0, len(ids))
+		for _, id := range ids {
+			entries = append(entries, DiffEntry{ID: id})
+		}
+		return entries
+	}
+	errLoad := errors.New("load failed")
+	tests := []struct {
+		name         string
+		histories    map[string][]DiffEntry
+		errs         map[string]error
+		expectedType string
+		expectedIDs  []string
+		expectedErr  error
+	}{
+		{
+			name:         "changed",
+			histories:    map[string][]DiffEntry{"left": history("2", "1"), "right": history("4", "3", "2", "1")},
+			expectedType: DiffTypeChanged,
+			expectedIDs:  []string{"4", "3"},
+		},
+		{
+			name:         "no changes",
+			histories:    map[string][]DiffEntry{"left": history("2", "1"), "right": history("2", "1")},
+			expectedType: DiffTypeChanged,
+			expectedIDs:  []string{},
+		},
+		{
+			name:         "created",
+			histories:    map[string][]DiffEntry{"right": history("2", "1")},
+			errs:         map[string]error{"left": ErrTableNotFound},
+			expectedType: DiffTypeCreated,
+			expectedIDs:  []string{"2", "1"},
+		},
+		{
+			name:         "dropped",
+			histories:    map[string][]DiffEntry{"left": history("2", "1")},
+			errs:         map[string]error{"right": ErrTableNotFound},
+			expectedType: DiffTypeDropped,
+			expectedIDs:  []string{},
+		},
+		{
+			name:        "not found",
+			errs:        map[string]error{"left": ErrTableNotFound, "right": ErrTableNotFound},
+			expectedErr: ErrTableNotFound,
+		},
+		{
+			name:        "load failure",
+			histories:   map[string][]DiffEntry{"left": history("1")},
+			errs:        map[string]error{"right": errLoad},
+			expectedErr: errLoad,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			load := func(_ context.Context, _ TableReader, _ string, tablePath RefPath) ([]DiffEntry, error) {
+				if err := tt.errs[tablePath.Ref]; err != nil {
+					return nil, err
+				}
+				return tt.histories[tablePath.Ref], nil
+			}
+			res, err := diffHistories(context.Background(), diffParams(memTableReader{}, "table"), load)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("diffHistories() err = %v, expected %v", err, tt.expectedErr)
+			}
+			if tt.expectedErr != nil {
+				return
+			}
+			if res.DiffType != tt.expectedType {
+				t.Errorf("diffHistories() type = %s, expected %s", res.DiffType, tt.expectedType)
+			}
+			if ids := diffIDs(res.Diffs); strings.Join(ids, ",") != strings.Join(tt.expectedIDs, ",") {
+				t.Errorf("diffHistories() IDs = %v, expected %v", ids, tt.expectedIDs)
+			}
+		})
+	}
+
+	t.Run("no reader", func(t *testing.T) {
+		_, err := diffHistories(context.Background(), Params{}, nil)
+		if !errors.Is(err, ErrNoTableReader) {
+			t.Fatalf("diffHistories() err = %v, expected %s", err, ErrNoTableReader)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+// Package signature signs and verifies lakeFS commits using SSH keys.
+//
+// A commit is signed by signing its ID: the ID is the content address of the commit's identity fields (committer,
+// message, metarange, creation date, metadata and parents), so a signature over it covers the commit and, through
+// its parents, all of its history.
+package signature
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// commitNamespace prefixes signed commit payloads, so that a commit signature is never valid for any other use of
+// the same key
+const commitNamespace = "lakefs-commit-signature-v1\x00"
+
+var (
+	ErrUnsupportedKeyType = errors.New("unsupported key type")
+	ErrInvalidSignature   = errors.New("invalid signature")
+)
+
+// supportedKeyTypes are the public key types that may sign commits
+var supportedKeyTypes = map[string]struct{}{
+	ssh.KeyAlgoED25519:  {},
+	ssh.KeyAlgoECDSA256: {},
+	ssh.KeyAlgoECDSA384: {},
+	ssh.KeyAlgoECDSA521: {},
+	ssh.KeyAlgoRSA:      {},
+}
+
+// weakSignatureFormats are signature algorithms that are not accepted, even for supported key types
+var weakSignatureFormats = map[string]struct{}{
+	ssh.KeyAlgoRSA: {}, // RSA with SHA-1
+}
+
+// ParsePublicKey parses a public key in the OpenSSH authorized_keys format, e.g. the content of id_ed25519.pub
+func ParsePublicKey(authorizedKey string) (ssh.PublicKey, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+	if _, ok := supportedKeyTypes[key.Type()]; !ok {
+		return nil, fmt.Errorf("%s: %w", key.Type(), ErrUnsupportedKeyType)
+	}
+	return key, nil
+}
+
+// Fingerprint returns the SHA256 fingerprint of key, in the format of ssh-keygen -l
+func Fingerprint(key ssh.PublicKey) string {
+	return ssh.FingerprintSHA256(key)
+}
+
+func commitPayload(commitID string) []byte {
+	return []byte(commitNamespace + commitID)
+}
+
+// SignCommit signs commitID with signer, returning the signature in the SSH wire format
+func SignCommit(signer ssh.Signer, commitID string) ([]byte, error) {
+	if _, ok := supportedKeyTypes[signer.PublicKey().Type()]; !ok {
+		return nil, fmt.Errorf("%s: %w", signer.PublicKey().Type(), ErrUnsupportedKeyType)
+	}
+	var (
+		sig *ssh.Signature
+		err error
+	)
+	if algorithmSigner, ok := signer.(ssh.AlgorithmSigner); ok && signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		sig, err = algorithmSigner.SignWithAlgorithm(rand.Reader, commitPayload(commitID), ssh.KeyAlgoRSASHA256)
+	} else {
+		sig, err = signer.Sign(rand.Reader, commitPayload(commitID))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sign commit %s: %w", commitID, err)
+	}
+	return ssh.Marshal(sig), nil
+}
+
+// VerifyCommit verifies that signature is a signature of commitID by key
+func VerifyCommit(key ssh.PublicKey, commitID string, signature []byte) error {
+	var sig ssh.Signature
+	if err := ssh.Unmarshal(signature, &sig); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSignature, err)
+	}
+	if _, ok := weakSignatureFormats[sig.Format]; ok {
+		return fmt.Errorf("%w: weak signature algorithm %s", ErrInvalidSignature, sig.Format)
+	}
+	if err := key.Verify(commitPayload(commitID), &sig); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSignature, err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package signature_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/treeverse/lakefs/pkg/signature"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	testCommitID  = "5c3d4a7d3d4d60f2d1b0f8c26a3bc0e5b2d2f31a9c0c1e0f0b4be5a1d3b0f4c1"
+	otherCommitID = "d3b0f4c15c3d4a7d3d4d60f2d1b0f8c26a3bc0e5b2d2f31a9c0c1e0f0b4be5a1"
+)
+
+func newSigner(t *testing.T, keyType string) ssh.Signer {
+	t.Helper()
+	var (
+		key interface{}
+		err error
+	)
+	switch keyType {
+	case "ed25519":
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	case "ecdsa":
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "rsa":
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	}
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	return signer
+}
+
+func TestSignVerifyCommit(t *testing.T) {
+	for _, keyType := range []string{"ed25519", "ecdsa", "rsa"} {
+		t.Run(keyType, func(t *testing.T) {
+			signer := newSigner(t, keyType)
+			publicKey, err := signature.ParsePublicKey(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+			require.NoError(t, err)
+			require.Equal(t, ssh.FingerprintSHA256(signer.PublicKey()), signature.Fingerprint(publicKey))
+
+			sig, err := signature.SignCommit(signer, testCommitID)
+			require.NoError(t, err)
+			require.NoError(t, signature.VerifyCommit(publicKey, testCommitID, sig))
+
+			err = signature.VerifyCommit(publicKey, otherCommitID, sig)
+			require.ErrorIs(t, err, signature.ErrInvalidSignature)
+
+			otherKey := newSigner(t, keyType).PublicKey()
+			err = signature.VerifyCommit(otherKey, testCommitID, sig)
+			require.ErrorIs(t, err, signature.ErrInvalidSignature)
+
+			err = signature.VerifyCommit(publicKey, testCommitID, []byte("not a signature"))
+			require.ErrorIs(t, err, signature.ErrInvalidSignature)
+		})
+	}
+}
+
+func TestVerifyCommit_WeakSignature(t *testing.T) {
+	signer := newSigner(t, "rsa").(ssh.AlgorithmSigner)
+	sig, err := signature.SignCommit(signer, testCommitID)
+	require.NoError(t, err)
+	var parsed ssh.Signature
+	require.NoError(t, ssh.Unmarshal(sig, &parsed))
+	require.Equal(t, ssh.KeyAlgoRSASHA256, parsed.Format)
+
+	// a signature over the right payload, using SHA-1
+	weak, err := signer.SignWithAlgorithm(rand.Reader, []byte("lakefs-commit-signature-v1\x00"+testCommitID), ssh.KeyAlgoRSA)
+	require.NoError(t, err)
+	require.NoError(t, signer.PublicKey().Verify([]byte("lakefs-commit-signature-v1\x00"+testCommitID), weak))
+	err = signature.VerifyCommit(signer.PublicKey(), testCommitID, ssh.Marshal(weak))
+	require.ErrorIs(t, err, signature.ErrInvalidSignature)
+}
+
+func TestParsePublicKey(t *testing.T) {
+	_, err := signature.ParsePublicKey("not a key")
+	require.Error(t, err)
+
+	// a security key backed ed25519 key, which cannot sign commits
+	const skKey = "sk-ssh-ed25519@openssh.com AAAAGnNrLXNzaC1lZDI1NTE5QG9wZW5zc2guY29tAAAAIEX/dQ0v4127bEo8eeG1EV0ApO2lWbSnN6RXusIaYiMbAAAABHNzaDo= user@host"
+	_, err = signature.ParsePublicKey(skKey)
+	require.ErrorIs(t, err, signature.ErrUnsupportedKeyType)
+}
@@ -48,6 +48,14 @@ func TestParse(t *testing.T) {
 				Path:       strp("baz/path@withappendix.foo"),
 			},
 		},
+		{
+			Input: "lakefs://foo/main@{2024-03-01T00:00:00+02:00}/baz/path",
+			Expected: &uri.URI{
+				Repository: "foo",
+				Ref:        "main@{2024-03-01T00:00:00+02:00}",
+				Path:       strp("baz/path"),
+			},
+		},
 		{
 			Input: "lakefs://fo-o/bar/baz/path@withappendix.foo",
 			Expected: &uri.URI{
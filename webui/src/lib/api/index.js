@@ -985,8 +985,9 @@ class Setup {
 }
 
 class Config {
-    async getStorageConfig() {
-        const response = await apiRequest('/config/storage', {
+    async getStorageConfig(repository = null) {
+        const query = repository ? `?${qs({repository})}` : '';
+        const response = await apiRequest(`/config/storage${query}`, {
             method: 'GET',
         });
         let cfg;
@@ -998,6 +999,8 @@ class Config {
                     cfg.warnings.push(`Block adapter ${cfg.blockstore_type} not usable in production`)
                 }
                 return cfg;
+            case 404:
+                throw new NotFoundError('Repository not found');
             case 409:
                 throw new Error('Conflict');
             default:
@@ -37,9 +37,11 @@ export const RepositoryPageLayout = ({ activePage, children, fluid = "sm" }) =>
     const [dismissedChecklistForRepo, setDismissedChecklistForRepo] =
         useLocalStorage(`dismissedChecklistForRepo`, false);
     const [configRes, setConfigRes] = useState(null);
+    const { repo } = useRefs();
+    const repoId = repo ? repo.id : null;
     const { response } = useAPI(() => {
-        return config.getStorageConfig();
-    }, []);
+        return config.getStorageConfig(repoId);
+    }, [repoId]);
 
     const dismissChecklist = useCallback(() => {
         setShowChecklist(false);
@@ -63,6 +63,22 @@ const ACLPermission: React.FC<ACLPermissionButtonProps> = ({initialValue, onSele
         </Dropdown>);
 };
 
+type ACLRepositoriesProps = {
+    repositories?: string[];
+    onEdit: () => unknown;
+}
+
+const ACLRepositories: React.FC<ACLRepositoriesProps> = ({repositories, onEdit}) => {
+    const text = (repositories && repositories.length > 0) ? repositories.join(', ') : 'All repositories';
+    return (<>
+        {text}{' '}
+        <Button variant="link" size="sm" onClick={onEdit}>Edit</Button>
+    </>);
+};
+
+const parseRepositories = (text: string) =>
+    text.split(',').map(r => r.trim()).filter(r => r !== '');
+
 const getACLMaybe = async (groupId: string) => {
     try {
         return await auth.getACL(groupId);
@@ -79,6 +95,7 @@ const GroupsContainer = () => {
     const [deleteError, setDeleteError] = useState(null);
     const [putACLError, setPutACLError] = useState(null);
     const [showCreate, setShowCreate] = useState(false);
+    const [editRepositoriesGroup, setEditRepositoriesGroup] = useState(null);
     const [refresh, setRefresh] = useState(false);
 
     const router = useRouter();
@@ -97,7 +114,7 @@ const GroupsContainer = () => {
 
     if (error) return <AlertError error={error}/>;
     if (loading) return <Loading/>;
-    const headers = simplified ? ['', 'Group ID', 'Permission', 'Created At'] : ['', 'Group ID', 'Created At'];
+    const headers = simplified ? ['', 'Group ID', 'Permission', 'Repositories', 'Created At'] : ['', 'Group ID', 'Created At'];
 
     return (
         <>
@@ -152,6 +169,21 @@ const GroupsContainer = () => {
                 validationFunction={disallowPercentSign(INVALID_GROUP_NAME_ERROR_MESSAGE)}
             />
 
+            <EntityActionModal
+                show={!!editRepositoriesGroup}
+                onHide={() => setEditRepositoriesGroup(null)}
+                onAction={text => {
+                    return auth.putACL(editRepositoriesGroup.id, {...editRepositoriesGroup.acl, repositories: parseRepositories(text)})
+                        .then(() => {
+                            setEditRepositoriesGroup(null);
+                            setRefresh(!refresh);
+                        });
+                }}
+                title={editRepositoriesGroup ? `Repositories of group ${editRepositoriesGroup.id}` : ''}
+                placeholder="Comma-separated repository names or patterns (e.g. 'team-a-*'), empty for all"
+                actionName={"Save"}
+            />
+
             <DataTable
                 results={results}
                 headers={headers}
@@ -170,6 +202,8 @@ const GroupsContainer = () => {
                             ((permission) => auth.putACL(group.id, {...group.acl, permission})
                                 .then(() => setPutACLError(null), (e) => setPutACLError(e)))
                         }/> : <></>)
+                    simplified && elements.push(group.acl ? <ACLRepositories repositories={group.acl.repositories}
+                        onEdit={() => setEditRepositoriesGroup(group)}/> : <></>)
                     elements.push(<FormattedDate dateValue={group.creation_date}/>)
 
                     return elements;